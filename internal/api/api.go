@@ -39,9 +39,11 @@ func OptWithTLS(tls *tls.Config) OptFunc {
 
 // Type implements the Bento HTTP API.
 type Type struct {
-	conf         Config
-	endpoints    map[string]string
-	endpointsMut sync.Mutex
+	conf          Config
+	version       string
+	endpoints     map[string]string
+	endpointSpecs map[string]EndpointSpec
+	endpointsMut  sync.Mutex
 
 	ctx    context.Context
 	cancel func()
@@ -83,12 +85,14 @@ func New(
 	}
 
 	t := &Type{
-		conf:      conf,
-		endpoints: map[string]string{},
-		handlers:  map[string]http.HandlerFunc{},
-		mux:       gMux,
-		server:    server,
-		log:       log,
+		conf:          conf,
+		version:       version,
+		endpoints:     map[string]string{},
+		endpointSpecs: map[string]EndpointSpec{},
+		handlers:      map[string]http.HandlerFunc{},
+		mux:           gMux,
+		server:        server,
+		log:           log,
 	}
 	t.ctx, t.cancel = context.WithCancel(context.Background())
 
@@ -201,6 +205,7 @@ func New(
 	t.RegisterEndpoint("/ping", "Ping me.", handlePing)
 	t.RegisterEndpoint("/version", "Returns the service version.", handleVersion)
 	t.RegisterEndpoint("/endpoints", "Returns this map of endpoints.", handleEndpoints)
+	t.RegisterEndpoint("/openapi.json", "Returns an OpenAPI 3 description of the endpoints of this API.", t.handleOpenAPI)
 
 	// If we want to expose a stats endpoint we register the endpoints.
 	if wHandlerFunc := stats.HandlerFunc(); wHandlerFunc != nil {
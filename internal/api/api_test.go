@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Jeffail/gabs/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -183,3 +184,63 @@ func TestAPIBasicAuth(t *testing.T) {
 		}(tc))
 	}
 }
+
+func TestAPIOpenAPI(t *testing.T) {
+	conf := api.NewConfig()
+	s, err := api.New("1.2.3", "", conf, nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	s.RegisterEndpoint("/foo/{id}", "Does foo things.", func(w http.ResponseWriter, r *http.Request) {})
+	s.RegisterEndpoint("/bar", "Does bar things.", func(w http.ResponseWriter, r *http.Request) {})
+	s.RegisterEndpointSpec("/bar", api.EndpointSpec{
+		Operations: map[string]api.OpenAPIOperation{
+			"POST": {
+				Summary: "Post a bar.",
+				RequestBody: &api.OpenAPIRequestBody{
+					Content: api.JSONContent(api.SchemaRef("bar")),
+				},
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {Description: "OK"},
+				},
+			},
+		},
+		Schemas: map[string]any{
+			"bar": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"labels": map[string]any{
+						"type": "object",
+						"patternProperties": map[string]any{
+							".": map[string]any{"type": "string"},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	request, _ := http.NewRequest("GET", "/openapi.json", http.NoBody)
+	response := httptest.NewRecorder()
+	s.Handler().ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	doc, err := gabs.ParseJSON(response.Body.Bytes())
+	require.NoError(t, err)
+
+	assert.Equal(t, "3.0.3", doc.S("openapi").Data())
+	assert.Equal(t, "1.2.3", doc.S("info", "version").Data())
+	assert.Equal(t, "Returns the service version.", doc.S("paths", "/version", "get", "summary").Data())
+
+	assert.Equal(t, "Does foo things.", doc.S("paths", "/foo/{id}", "get", "summary").Data())
+	assert.Equal(t, "id", doc.S("paths", "/foo/{id}", "get", "parameters", "0", "name").Data())
+	assert.Equal(t, "path", doc.S("paths", "/foo/{id}", "get", "parameters", "0", "in").Data())
+
+	assert.False(t, doc.Exists("paths", "/bar", "get"))
+	assert.Equal(t, "Post a bar.", doc.S("paths", "/bar", "post", "summary").Data())
+	assert.Equal(t, "Does bar things.", doc.S("paths", "/bar", "post", "description").Data())
+	assert.Equal(t, "#/components/schemas/bar", doc.S("paths", "/bar", "post", "requestBody", "content", "application/json", "schema", "$ref").Data())
+	assert.Equal(t, "object", doc.S("components", "schemas", "bar", "type").Data())
+	assert.False(t, doc.Exists("components", "schemas", "bar", "properties", "labels", "patternProperties"))
+	assert.Equal(t, "string", doc.S("components", "schemas", "bar", "properties", "labels", "additionalProperties", "type").Data())
+}
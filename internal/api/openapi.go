@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// OpenAPISchemaRef is the prefix used for referencing schemas registered
+// within the components section of the generated OpenAPI document.
+const OpenAPISchemaRef = "#/components/schemas/"
+
+// OpenAPIParameter describes a single parameter of an operation.
+type OpenAPIParameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Schema      any    `json:"schema,omitempty"`
+}
+
+// OpenAPIMediaType describes the schema of a request or response body for a
+// given content type.
+type OpenAPIMediaType struct {
+	Schema any `json:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody describes the body of a request.
+type OpenAPIRequestBody struct {
+	Description string                      `json:"description,omitempty"`
+	Required    bool                        `json:"required,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes a single response of an operation.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIOperation describes a single HTTP method of an endpoint.
+type OpenAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// EndpointSpec documents the operations supported by an endpoint, along with
+// any schemas that those operations reference via OpenAPISchemaRef.
+type EndpointSpec struct {
+	// Operations keyed by their HTTP method, e.g. GET, POST, etc.
+	Operations map[string]OpenAPIOperation
+
+	// Schemas that are referenced by the operations, these are added to the
+	// components section of the OpenAPI document.
+	Schemas map[string]any
+}
+
+// SpecReg is an optional interface implemented by API registries that are able
+// to document registered endpoints as an OpenAPI description.
+type SpecReg interface {
+	RegisterEndpointSpec(path string, spec EndpointSpec)
+}
+
+// JSONContent is a convenience function for creating a content map with a
+// single application/json media type of a given schema.
+func JSONContent(schema any) map[string]OpenAPIMediaType {
+	return map[string]OpenAPIMediaType{
+		"application/json": {Schema: schema},
+	}
+}
+
+// SchemaRef returns a JSON schema object that references a schema registered
+// within the components section of the OpenAPI document.
+func SchemaRef(name string) map[string]any {
+	return map[string]any{"$ref": OpenAPISchemaRef + name}
+}
+
+//------------------------------------------------------------------------------
+
+// RegisterEndpointSpec adds documentation for the operations of a path, which
+// is used in place of the description provided to RegisterEndpoint when
+// generating the OpenAPI description of the API.
+func (t *Type) RegisterEndpointSpec(path string, spec EndpointSpec) {
+	t.endpointsMut.Lock()
+	defer t.endpointsMut.Unlock()
+
+	t.endpointSpecs[path] = spec
+}
+
+// pathParameters extracts mux style path variables (e.g. `{id}`) from a path
+// and returns them as required OpenAPI path parameters.
+func pathParameters(path string) (params []OpenAPIParameter) {
+	for _, seg := range strings.Split(path, "/") {
+		if len(seg) > 2 && seg[0] == '{' && seg[len(seg)-1] == '}' {
+			name, _, _ := strings.Cut(seg[1:len(seg)-1], ":")
+			params = append(params, OpenAPIParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   map[string]any{"type": "string"},
+			})
+		}
+	}
+	return
+}
+
+// openAPISchema converts a JSON schema into the subset supported by OpenAPI
+// 3.0 schema objects. Map fields are described by JSON schema with
+// patternProperties, which OpenAPI 3.0 does not support, and so these are
+// converted into additionalProperties.
+func openAPISchema(schema any) any {
+	switch t := schema.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(t))
+		for k, v := range t {
+			if k == "patternProperties" {
+				if props, ok := v.(map[string]any); ok && len(props) == 1 {
+					for _, p := range props {
+						res["additionalProperties"] = openAPISchema(p)
+					}
+					continue
+				}
+			}
+			res[k] = openAPISchema(v)
+		}
+		return res
+	case []any:
+		res := make([]any, len(t))
+		for i, v := range t {
+			res[i] = openAPISchema(v)
+		}
+		return res
+	}
+	return schema
+}
+
+// OpenAPI generates an OpenAPI 3 document describing all registered endpoints.
+// Endpoints without an explicit spec are documented as a GET operation with
+// the description they were registered with.
+func (t *Type) OpenAPI() map[string]any {
+	t.endpointsMut.Lock()
+	defer t.endpointsMut.Unlock()
+
+	paths := map[string]any{}
+	schemas := map[string]any{}
+
+	for path, desc := range t.endpoints {
+		params := pathParameters(path)
+
+		spec, exists := t.endpointSpecs[path]
+		if !exists {
+			paths[path] = map[string]any{
+				"get": OpenAPIOperation{
+					Summary:    desc,
+					Parameters: params,
+					Responses: map[string]OpenAPIResponse{
+						"200": {Description: "OK"},
+					},
+				},
+			}
+			continue
+		}
+
+		item := map[string]any{}
+		if len(params) > 0 {
+			item["parameters"] = params
+		}
+		for method, op := range spec.Operations {
+			if op.Description == "" {
+				op.Description = desc
+			}
+			item[strings.ToLower(method)] = op
+		}
+		paths[path] = item
+
+		for k, v := range spec.Schemas {
+			schemas[k] = openAPISchema(v)
+		}
+	}
+
+	version := t.version
+	if version == "" {
+		version = "unknown"
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Bento HTTP API",
+			"version": version,
+		},
+		"paths": paths,
+	}
+	if t.conf.RootPath != "" {
+		// Endpoints are served both from the root and behind the root path.
+		doc["servers"] = []any{
+			map[string]any{"url": "/"},
+			map[string]any{"url": t.conf.RootPath},
+		}
+	}
+	if len(schemas) > 0 {
+		doc["components"] = map[string]any{
+			"schemas": schemas,
+		}
+	}
+	return doc
+}
+
+func (t *Type) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	resBytes, err := json.Marshal(t.OpenAPI())
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resBytes)
+}
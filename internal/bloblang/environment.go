@@ -1,6 +1,8 @@
 package bloblang
 
 import (
+	"fmt"
+
 	"github.com/warpstreamlabs/bento/internal/bloblang/field"
 	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
 	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
@@ -77,6 +79,40 @@ func (e *Environment) NewMapping(blobl string) (*mapping.Executor, error) {
 	return exec, nil
 }
 
+// FormatMapping parses a Bloblang mapping using the Environment and, if it is
+// valid, returns it in a canonical format with comments and line breaks
+// preserved.
+//
+// When a parsing error occurs the error will be the type *parser.Error, which
+// gives access to the line and column where the error occurred, as well as a
+// method for creating a well formatted error message.
+func (e *Environment) FormatMapping(blobl string) (string, error) {
+	pCtx := e.pCtx.Deactivated()
+	if _, err := parser.ParseMapping(pCtx, blobl); err != nil {
+		return "", err
+	}
+	formatted := parser.FormatMapping(blobl)
+	if _, err := parser.ParseMapping(pCtx, formatted); err != nil {
+		return "", fmt.Errorf("formatted mapping is invalid, this is a bug: %w", err)
+	}
+	return formatted, nil
+}
+
+// CheckMappingTypes parses a Bloblang mapping using the Environment and
+// performs static type checking on it, where the input document is of the
+// provided type, returning any definite type errors or unreachable branches.
+//
+// When a parsing error occurs the error will be the type *parser.Error, which
+// gives access to the line and column where the error occurred, as well as a
+// method for creating a well formatted error message.
+func (e *Environment) CheckMappingTypes(blobl string, input query.StaticType) ([]mapping.TypeIssue, error) {
+	exec, err := parser.ParseMapping(e.pCtx.Deactivated(), blobl)
+	if err != nil {
+		return nil, err
+	}
+	return exec.CheckTypes(input), nil
+}
+
 // Deactivated returns a version of the environment where constructors are
 // disabled for all functions and methods, allowing mappings to be parsed and
 // validated but not executed.
@@ -141,6 +177,25 @@ func (e *Environment) WithDisabledImports() *Environment {
 	return &env
 }
 
+// WithDisabledOptimisations returns a version of the environment where parsed
+// mappings are executed exactly as they were written, which is useful when the
+// execution of individual statements is traced.
+func (e *Environment) WithDisabledOptimisations() *Environment {
+	env := *e
+	env.pCtx = env.pCtx.DisabledOptimisations()
+	return &env
+}
+
+// WithBranchCoverage returns a version of the environment where the branches of
+// match and if expressions, and root-level if statements, of parsed mappings
+// and fields are recorded by the provided coverage, along with the number of
+// times each branch is taken.
+func (e *Environment) WithBranchCoverage(c *parser.BranchCoverage) *Environment {
+	env := *e
+	env.pCtx = env.pCtx.WithBranchCoverage(c)
+	return &env
+}
+
 // WithCustomImporter returns a version of the environment where file imports
 // are done exclusively through a provided closure function, which takes an
 // import path (relative or absolute).
@@ -26,6 +26,11 @@ type AssignmentContext struct {
 	Vars  map[string]any
 	Meta  metaMsg
 	Value *any
+
+	// When true values assigned to the root are shallow copied rather than
+	// deeply copied, and therefore objects and arrays along the path of an
+	// assignment must be copied before they are mutated.
+	copyOnWrite bool
 }
 
 // Assignment represents a way of assigning a queried value to something within
@@ -166,15 +171,26 @@ func findTheNonObject(gObj *gabs.Container, allowArray bool, paths ...string) (c
 // Apply a value to the target JSON path.
 func (j *JSONAssignment) Apply(val any, ctx AssignmentContext) error {
 	_, deleted := val.(value.Delete)
-	if !deleted {
-		val = value.IClone(val)
-	}
 	if len(j.path) == 0 {
+		var copied any
+		if ctx.copyOnWrite {
+			copied = shallowCopy(val)
+		}
+		if copied != nil {
+			val = copied
+		} else if !deleted {
+			val = value.IClone(val)
+		}
 		*ctx.Value = val
 		return nil
 	}
+	if !deleted {
+		val = value.IClone(val)
+	}
 	if _, isNothing := (*ctx.Value).(value.Nothing); isNothing || *ctx.Value == nil {
 		*ctx.Value = map[string]any{}
+	} else if ctx.copyOnWrite && !copyPathContainers(*ctx.Value, j.path) {
+		*ctx.Value = value.IClone(*ctx.Value)
 	}
 
 	gObj := gabs.Wrap(*ctx.Value)
@@ -44,8 +44,13 @@ type Executor struct {
 	input      []rune
 	maps       map[string]query.Function
 	statements []Statement
+	params     *query.Params
 
 	maxMapStacks int
+
+	// The index of the statement from which assignments are copy-on-write
+	// when mapping a new message, or -1 if they are not.
+	copyOnWriteFrom int
 }
 
 const defaultMaxMapStacks = 5000
@@ -61,6 +66,8 @@ func NewExecutor(annotation string, input []rune, maps map[string]query.Function
 		maps:         maps,
 		statements:   statements,
 		maxMapStacks: defaultMaxMapStacks,
+
+		copyOnWriteFrom: -1,
 	}
 }
 
@@ -68,7 +75,35 @@ func NewExecutor(annotation string, input []rune, maps map[string]query.Function
 // execution of this mapping matches this number of recursive map calls the
 // mapping will error out.
 func (e *Executor) SetMaxMapRecursion(m int) {
+	e.setMaxMapStacks(m, map[*Executor]struct{}{})
+}
+
+func (e *Executor) setMaxMapStacks(m int, seen map[*Executor]struct{}) {
+	if _, exists := seen[e]; exists {
+		return
+	}
+	seen[e] = struct{}{}
 	e.maxMapStacks = m
+	for _, v := range e.maps {
+		if child, ok := v.(*Executor); ok {
+			child.setMaxMapStacks(m, seen)
+		}
+	}
+}
+
+// SetParams declares the parameters of a map, which allows it to be called as
+// a function or method with arguments that are bound to variables.
+func (e *Executor) SetParams(params query.Params) {
+	e.params = &params
+}
+
+// Params returns the parameters declared by a map, and a boolean indicating
+// whether the map is able to be called as a function or method.
+func (e *Executor) Params() (query.Params, bool) {
+	if e.params == nil {
+		return query.Params{}, false
+	}
+	return *e.params, true
 }
 
 // Annotation returns a string annotation that describes the mapping executor.
@@ -155,7 +190,12 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 
 	vars := map[string]any{}
 
-	for _, stmt := range e.statements {
+	// Copy-on-write assignments are only enabled when mapping a new message,
+	// which allows the result to share values with the input document as long
+	// as it is marked as read-only.
+	copyOnWrite := appendTo == nil && e.copyOnWriteFrom >= 0
+
+	for i, stmt := range e.statements {
 		err := stmt.Execute(query.FunctionContext{
 			Maps:     e.maps,
 			Vars:     vars,
@@ -168,6 +208,8 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 				Vars:  vars,
 				Meta:  newPart,
 				Value: &newValue,
+
+				copyOnWrite: copyOnWrite && i >= e.copyOnWriteFrom,
 			},
 		)
 		if err != nil {
@@ -200,7 +242,11 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 		case []byte:
 			newPart.SetBytes(t)
 		default:
-			newPart.SetStructuredMut(newValue)
+			if copyOnWrite {
+				newPart.SetStructured(newValue)
+			} else {
+				newPart.SetStructuredMut(newValue)
+			}
 		}
 	}
 	return newPart, nil
@@ -239,6 +285,29 @@ func (e *Executor) Exec(ctx query.FunctionContext) (any, error) {
 		return nil, &errStacks{annotation: e.annotation, maxStacks: e.maxMapStacks}
 	}
 
+	if e.params != nil && ctx.Vars != nil {
+		// Parameters that are omitted, which happens when a map is executed
+		// with the apply method, are populated with their defaults. The
+		// variables are copied so that defaults never leak into the scope of
+		// the caller.
+		var vars map[string]any
+		for _, def := range e.params.Definitions {
+			if _, exists := ctx.Vars[def.Name]; exists || def.DefaultValue == nil {
+				continue
+			}
+			if vars == nil {
+				vars = make(map[string]any, len(ctx.Vars)+len(e.params.Definitions))
+				for k, v := range ctx.Vars {
+					vars[k] = v
+				}
+			}
+			vars[def.Name] = *def.DefaultValue
+		}
+		if vars != nil {
+			ctx.Vars = vars
+		}
+	}
+
 	var newObj any = value.Nothing(nil)
 	ctx.NewValue = &newObj
 
@@ -613,3 +613,52 @@ func TestQueries(t *testing.T) {
 		})
 	}
 }
+
+func TestRootLevelIfBranches(t *testing.T) {
+	newStmt := func(conds ...query.Function) *RootLevelIfStatement {
+		stmt := NewRootLevelIfStatement(nil)
+		for _, c := range conds {
+			stmt.Add(c, NewSingleStatement(nil, NewJSONAssignment("foo"), query.NewLiteralFunction("", "bar")))
+		}
+		return stmt
+	}
+
+	tests := map[string]struct {
+		stmt     *RootLevelIfStatement
+		branches []string
+		taken    []string
+	}{
+		"if none": {
+			stmt:     newStmt(query.NewLiteralFunction("", false)),
+			branches: []string{"if", "none"},
+			taken:    []string{"none"},
+		},
+		"if else if else": {
+			stmt:     newStmt(query.NewLiteralFunction("", false), query.NewLiteralFunction("", true), nil),
+			branches: []string{"if", "else if 1", "else"},
+			taken:    []string{"else if 1"},
+		},
+		"if else": {
+			stmt:     newStmt(query.NewLiteralFunction("", false), nil),
+			branches: []string{"if", "else"},
+			taken:    []string{"else"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.branches, test.stmt.Branches())
+
+			var taken []string
+			test.stmt.OnBranch(func(branch string) {
+				taken = append(taken, branch)
+			})
+
+			exec := NewExecutor("", nil, nil, test.stmt)
+			_, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+			require.NoError(t, err)
+			assert.Equal(t, test.taken, taken)
+		})
+	}
+}
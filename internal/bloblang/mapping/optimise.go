@@ -0,0 +1,230 @@
+package mapping
+
+import (
+	"strconv"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+)
+
+// Optimise rewrites the executor, and any maps that it declares, into a form
+// that is cheaper to execute. Constant folding and path fusion are performed by
+// the query package as functions are constructed, this stage is concerned with
+// the statements of a mapping:
+//
+// Assignments of literal values to variables or the root of the document that
+// are always overwritten by the following statement are removed.
+//
+// When the root of a new message is assigned from the input document (e.g.
+// `root = this`) the document is no longer deeply copied. Instead, subsequent
+// assignments copy only the objects and arrays along their target paths, and
+// the resulting message is marked as read-only so that further mutations copy
+// it lazily.
+//
+// The result of executing an optimised mapping, or the error returned by it, is
+// identical to that of the original mapping.
+func (e *Executor) Optimise() {
+	e.optimise(map[*Executor]struct{}{})
+}
+
+func (e *Executor) optimise(seen map[*Executor]struct{}) {
+	if _, exists := seen[e]; exists {
+		return
+	}
+	seen[e] = struct{}{}
+
+	for _, v := range e.maps {
+		if child, ok := v.(*Executor); ok {
+			child.optimise(seen)
+		}
+	}
+
+	tCtx := query.TargetsContext{Maps: e.maps}
+	e.statements = eliminateDeadAssignments(tCtx, e.statements)
+
+	e.copyOnWriteFrom = -1
+	if capturesRoot(tCtx, e.statements) {
+		return
+	}
+	for i, stmt := range e.statements {
+		if s, ok := stmt.(*SingleStatement); ok && isRootAssignment(s) && query.IsContextReference(s.query) {
+			e.copyOnWriteFrom = i
+			return
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func isRootAssignment(s *SingleStatement) bool {
+	j, ok := s.assignment.(*JSONAssignment)
+	return ok && len(j.path) == 0
+}
+
+// deadAssignmentTarget returns the target of a statement when it is a
+// candidate for elimination, which is when it assigns a literal value to a
+// variable or the root of the document, neither of which can fail.
+func deadAssignmentTarget(stmt Statement) (TargetPath, bool) {
+	s, ok := stmt.(*SingleStatement)
+	if !ok {
+		return TargetPath{}, false
+	}
+	if _, isLit := s.query.(*query.Literal); !isLit {
+		return TargetPath{}, false
+	}
+	switch t := s.assignment.(type) {
+	case *VarAssignment:
+		return t.Target(), true
+	case *JSONAssignment:
+		if len(t.path) == 0 {
+			return t.Target(), true
+		}
+	}
+	return TargetPath{}, false
+}
+
+// overwrites returns true if a statement always replaces the value of a target
+// without reading it first.
+func overwrites(tCtx query.TargetsContext, stmt Statement, target TargetPath) bool {
+	s, ok := stmt.(*SingleStatement)
+	if !ok || query.MayReturnNothing(s.query) || !targetsEqual(s.assignment.Target(), target) {
+		return false
+	}
+	_, queryTargets := s.query.QueryTargets(tCtx)
+	for _, t := range queryTargets {
+		switch target.Type {
+		case TargetVariable:
+			if t.Type == query.TargetVariable && len(t.Path) > 0 && t.Path[0] == target.Path[0] {
+				return false
+			}
+		case TargetValue:
+			if t.Type == query.TargetRoot {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func targetsEqual(a, b TargetPath) bool {
+	if a.Type != b.Type || len(a.Path) != len(b.Path) {
+		return false
+	}
+	for i := range a.Path {
+		if a.Path[i] != b.Path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// eliminateDeadAssignments removes statements that assign a literal value to a
+// variable or the root of the document, where the following statement always
+// overwrites that same target without reading it.
+func eliminateDeadAssignments(tCtx query.TargetsContext, statements []Statement) []Statement {
+	optimised := make([]Statement, 0, len(statements))
+	for i, stmt := range statements {
+		if r, ok := stmt.(*RootLevelIfStatement); ok {
+			for j, p := range r.pairs {
+				r.pairs[j].statements = eliminateDeadAssignments(tCtx, p.statements)
+			}
+		}
+		if target, ok := deadAssignmentTarget(stmt); ok && i+1 < len(statements) && overwrites(tCtx, statements[i+1], target) {
+			continue
+		}
+		optimised = append(optimised, stmt)
+	}
+	return optimised
+}
+
+// capturesRoot returns true if any statement assigns a variable from a query
+// that references the root of the new document, in which case the variable
+// might hold a reference to values that would otherwise be mutated in place.
+func capturesRoot(tCtx query.TargetsContext, statements []Statement) bool {
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *SingleStatement:
+			if _, isVar := t.assignment.(*VarAssignment); !isVar {
+				continue
+			}
+			_, queryTargets := t.query.QueryTargets(tCtx)
+			for _, p := range queryTargets {
+				if p.Type == query.TargetRoot {
+					return true
+				}
+			}
+		case *RootLevelIfStatement:
+			for _, p := range t.pairs {
+				if capturesRoot(tCtx, p.statements) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+// copyPathContainers replaces the objects and arrays along a target path
+// (excluding the root value) with shallow copies, such that setting or deleting
+// the path no longer mutates values that might be shared with the input
+// document. Returns false if the path could not be copied, in which case the
+// value must be deeply copied instead.
+func copyPathContainers(root any, path []string) bool {
+	current := root
+	for i := 0; i < len(path)-1; i++ {
+		seg := path[i]
+		switch t := current.(type) {
+		case map[string]any:
+			child, exists := t[seg]
+			if !exists {
+				return true
+			}
+			if child = shallowCopy(child); child == nil {
+				return true
+			}
+			t[seg] = child
+			current = child
+		case []any:
+			if seg == "-" {
+				// A new value is appended and therefore remaining containers
+				// are fresh.
+				return true
+			}
+			index, err := strconv.Atoi(seg)
+			if err != nil {
+				return false
+			}
+			if index < 0 || index >= len(t) {
+				return true
+			}
+			child := shallowCopy(t[index])
+			if child == nil {
+				return true
+			}
+			t[index] = child
+			current = child
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+// shallowCopy returns a copy of an object or array where the values are
+// shared, or nil if the value is neither.
+func shallowCopy(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		newMap := make(map[string]any, len(t))
+		for k, v := range t {
+			newMap[k] = v
+		}
+		return newMap
+	case []any:
+		newSlice := make([]any, len(t))
+		copy(newSlice, t)
+		return newSlice
+	}
+	return nil
+}
@@ -62,8 +62,9 @@ type rootLevelIfStatementPair struct {
 }
 
 type RootLevelIfStatement struct {
-	input []rune
-	pairs []rootLevelIfStatementPair
+	input    []rune
+	pairs    []rootLevelIfStatementPair
+	onBranch func(branch string)
 }
 
 func NewRootLevelIfStatement(input []rune) *RootLevelIfStatement {
@@ -106,27 +107,77 @@ func (r *RootLevelIfStatement) Input() []rune {
 	return r.input
 }
 
+// Branches returns a description of each branch that can be taken by the
+// statement, in the same format as statement traces, including "none" when it
+// is possible that no branch is taken.
+func (r *RootLevelIfStatement) Branches() []string {
+	branches := make([]string, 0, len(r.pairs)+1)
+	for i := range r.pairs {
+		branches = append(branches, r.branchDescription(i))
+	}
+	if len(r.pairs) == 0 || r.pairs[len(r.pairs)-1].query != nil {
+		branches = append(branches, r.branchDescription(-1))
+	}
+	return branches
+}
+
+// OnBranch sets a closure to be called with the description of each branch
+// taken by the statement as it is executed.
+func (r *RootLevelIfStatement) OnBranch(fn func(branch string)) {
+	r.onBranch = fn
+}
+
 func (r *RootLevelIfStatement) Execute(fnContext query.FunctionContext, asContext AssignmentContext) error {
+	i, err := r.selectBranch(fnContext)
+	if err != nil {
+		return err
+	}
+	if r.onBranch != nil {
+		r.onBranch(r.branchDescription(i))
+	}
+	if i < 0 {
+		return nil
+	}
+	for _, stmt := range r.pairs[i].statements {
+		if err := stmt.Execute(fnContext, asContext); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// branchDescription returns a description of the branch at an index, where a
+// negative index describes no branch being taken.
+func (r *RootLevelIfStatement) branchDescription(i int) string {
+	switch {
+	case i < 0:
+		return "none"
+	case i == 0:
+		return "if"
+	case r.pairs[i].query == nil:
+		return "else"
+	}
+	return fmt.Sprintf("else if %v", i)
+}
+
+// selectBranch returns the index of the first branch where the condition
+// passes, or -1 if no branch should be taken.
+func (r *RootLevelIfStatement) selectBranch(fnContext query.FunctionContext) (int, error) {
 	for i, p := range r.pairs {
 		if p.query != nil {
 			queryVal, err := p.query.Exec(fnContext)
 			if err != nil {
-				return fmt.Errorf("failed to check if condition %v: %w", i+1, err)
+				return -1, fmt.Errorf("failed to check if condition %v: %w", i+1, err)
 			}
 			queryRes, isBool := queryVal.(bool)
 			if !isBool {
-				return fmt.Errorf("%v resolved to a non-boolean value %v (%T)", p.query.Annotation(), queryVal, queryVal)
+				return -1, fmt.Errorf("%v resolved to a non-boolean value %v (%T)", p.query.Annotation(), queryVal, queryVal)
 			}
 			if !queryRes {
 				continue
 			}
 		}
-		for _, stmt := range p.statements {
-			if err := stmt.Execute(fnContext, asContext); err != nil {
-				return err
-			}
-		}
-		return nil
+		return i, nil
 	}
-	return nil
+	return -1, nil
 }
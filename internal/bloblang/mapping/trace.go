@@ -0,0 +1,118 @@
+package mapping
+
+import (
+	"strings"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// StatementTrace describes the execution of a single statement of a mapping.
+type StatementTrace struct {
+	Line   int
+	Column int
+
+	// The first line of the statement as it was written in the mapping.
+	Statement string
+
+	// Conditional is true when the statement is a root-level if statement,
+	// which has no value.
+	Conditional bool
+
+	// The value that the right-hand side of an assignment resolved to, which
+	// is value.Nothing when the assignment was skipped.
+	Value any
+
+	// The variables that were set after the statement was executed.
+	Vars map[string]any
+
+	// The branches of match and if expressions taken during the statement.
+	Branches []query.BranchTrace
+
+	// The time spent executing the statement, which for root-level if
+	// statements only includes the time spent checking conditions.
+	Duration time.Duration
+
+	Err error
+}
+
+// ExecOntoTraced executes the mapping onto a provided assignment context in
+// the same way as ExecOnto, but also returns a trace of each statement that was
+// executed. Tracing is only performed by this method and therefore adds no
+// overhead to regular executions.
+func (e *Executor) ExecOntoTraced(ctx query.FunctionContext, onto AssignmentContext) ([]StatementTrace, error) {
+	var traces []StatementTrace
+	for _, stmt := range e.statements {
+		if err := e.traceStatement(ctx, onto, stmt, &traces); err != nil {
+			return traces, formatExecErr(err, e.input, stmt.Input())
+		}
+	}
+	return traces, nil
+}
+
+func (e *Executor) traceStatement(ctx query.FunctionContext, onto AssignmentContext, stmt Statement, traces *[]StatementTrace) error {
+	var trace StatementTrace
+	if len(e.input) > 0 && len(stmt.Input()) > 0 {
+		trace.Line, trace.Column = LineAndColOf(e.input, stmt.Input())
+	}
+	trace.Statement, _, _ = strings.Cut(string(stmt.Input()), "\n")
+	trace.Statement = strings.TrimSpace(trace.Statement)
+
+	ctx = ctx.WithBranchTracer(func(b query.BranchTrace) {
+		trace.Branches = append(trace.Branches, b)
+	})
+
+	var err error
+	var branch []Statement
+
+	start := time.Now()
+	switch t := stmt.(type) {
+	case *SingleStatement:
+		var res any
+		if res, err = t.query.Exec(ctx); err == nil {
+			trace.Value = res
+			if _, isNothing := res.(value.Nothing); !isNothing {
+				err = t.assignment.Apply(res, onto)
+			}
+		}
+	case *RootLevelIfStatement:
+		trace.Conditional = true
+		var i int
+		if i, err = t.selectBranch(ctx); err == nil {
+			if i >= 0 {
+				branch = t.pairs[i].statements
+			}
+			desc := t.branchDescription(i)
+			if t.onBranch != nil {
+				t.onBranch(desc)
+			}
+			trace.Branches = append(trace.Branches, query.BranchTrace{
+				Expression: "if statement",
+				Branch:     desc,
+			})
+		}
+	default:
+		err = stmt.Execute(ctx, onto)
+	}
+	trace.Duration = time.Since(start)
+
+	if len(onto.Vars) > 0 {
+		trace.Vars = make(map[string]any, len(onto.Vars))
+		for k, v := range onto.Vars {
+			trace.Vars[k] = v
+		}
+	}
+	trace.Err = err
+	*traces = append(*traces, trace)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range branch {
+		if err := e.traceStatement(ctx, onto, s, traces); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package mapping
+
+import (
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+)
+
+// TypeIssue describes a problem found during static type checking of a
+// mapping, along with the position of the statement it was found within.
+type TypeIssue struct {
+	Line   int
+	Column int
+	query.TypeIssue
+}
+
+// CheckTypes performs static type checking of the mapping, where the input
+// document is of a given type, and returns any definite type errors or
+// unreachable branches found. The check is conservative in that issues are
+// only reported when they will occur regardless of the contents of the input.
+func (e *Executor) CheckTypes(input query.StaticType) []TypeIssue {
+	ctx := query.NewTypeContext(input)
+
+	var issues []TypeIssue
+	for _, stmt := range e.statements {
+		issues = append(issues, e.checkStatementTypes(ctx, stmt)...)
+	}
+	return issues
+}
+
+func (e *Executor) issuesAt(stmt Statement, from []query.TypeIssue) []TypeIssue {
+	if len(from) == 0 {
+		return nil
+	}
+	line, col := LineAndColOf(e.input, stmt.Input())
+	issues := make([]TypeIssue, len(from))
+	for i, issue := range from {
+		issues[i] = TypeIssue{Line: line, Column: col, TypeIssue: issue}
+	}
+	return issues
+}
+
+func (e *Executor) checkStatementTypes(ctx query.TypeContext, stmt Statement) []TypeIssue {
+	switch t := stmt.(type) {
+	case *SingleStatement:
+		stmtCtx := ctx.WithNewIssues()
+
+		valueType := query.InferType(stmtCtx, t.query)
+		if v, ok := t.assignment.(*VarAssignment); ok {
+			ctx.SetVar(v.name, valueType)
+		}
+		return e.issuesAt(stmt, stmtCtx.Issues())
+
+	case *RootLevelIfStatement:
+		var issues []TypeIssue
+
+		condCtx := ctx.WithNewIssues()
+
+		var branches []query.TypeContext
+		exhaustive := false
+		for i, p := range t.pairs {
+			if exhaustive {
+				condCtx.Unreachablef("branch %v of if statement is unreachable as a previous condition is always true", i+1)
+				continue
+			}
+			if p.query != nil {
+				alwaysTrue, alwaysFalse := query.CheckCondition(condCtx, p.query)
+				if alwaysFalse {
+					condCtx.Unreachablef("branch %v of if statement is unreachable as its condition is always false", i+1)
+					continue
+				}
+				exhaustive = alwaysTrue
+			} else {
+				exhaustive = true
+			}
+
+			branchCtx := ctx.CloneVars()
+			for _, s := range p.statements {
+				issues = append(issues, e.checkStatementTypes(branchCtx, s)...)
+			}
+			branches = append(branches, branchCtx)
+		}
+		ctx.MergeVars(exhaustive, branches...)
+		return append(e.issuesAt(stmt, condCtx.Issues()), issues...)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
 	"github.com/warpstreamlabs/bento/internal/bloblang/query"
 	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/value"
 )
 
 func TestMappings(t *testing.T) {
@@ -269,3 +270,71 @@ func TestMappingParallelExecution(t *testing.T) {
 		})
 	}
 }
+
+func TestMappingTrace(t *testing.T) {
+	m, err := GlobalEnvironment().NewMapping(`let kind = match this.type {
+  "a" => "first"
+  "b" => "second"
+  _ => "other"
+}
+root.kind = $kind
+if this.count > 5 {
+  root.size = "big"
+} else {
+  root.size = if this.count > 2 { "medium" } else { "small" }
+}
+root.nope = deleted()`)
+	require.NoError(t, err)
+
+	var result any = value.Nothing(nil)
+	vars := map[string]any{}
+	input := any(map[string]any{"type": "b", "count": 3})
+	traces, err := m.ExecOntoTraced(query.FunctionContext{
+		Vars:     vars,
+		MsgBatch: message.QuickBatch(nil),
+		NewValue: &result,
+	}.WithValue(input), mapping.AssignmentContext{
+		Vars:  vars,
+		Value: &result,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"kind": "second", "size": "medium"}, result)
+
+	type simpleTrace struct {
+		Line      int
+		Statement string
+		Value     any
+		Vars      map[string]any
+		Branches  []query.BranchTrace
+	}
+	var simpleTraces []simpleTrace
+	for _, trace := range traces {
+		assert.NoError(t, trace.Err)
+		simpleTraces = append(simpleTraces, simpleTrace{
+			Line:      trace.Line,
+			Statement: trace.Statement,
+			Value:     trace.Value,
+			Vars:      trace.Vars,
+			Branches:  trace.Branches,
+		})
+	}
+
+	kindVars := map[string]any{"kind": "second"}
+	assert.Equal(t, []simpleTrace{
+		{
+			Line: 1, Statement: `let kind = match this.type {`, Value: "second", Vars: kindVars,
+			Branches: []query.BranchTrace{{Expression: "match expression", Branch: "case 1"}},
+		},
+		{Line: 6, Statement: `root.kind = $kind`, Value: "second", Vars: kindVars},
+		{
+			Line: 7, Statement: `if this.count > 5 {`, Vars: kindVars,
+			Branches: []query.BranchTrace{{Expression: "if statement", Branch: "else"}},
+		},
+		{
+			Line: 10, Statement: `root.size = if this.count > 2 { "medium" } else { "small" }`, Value: "medium", Vars: kindVars,
+			Branches: []query.BranchTrace{{Expression: "if expression", Branch: "if"}},
+		},
+		{Line: 12, Statement: `root.nope = deleted()`, Value: value.Delete(nil), Vars: kindVars},
+	}, simpleTraces)
+}
@@ -16,6 +16,17 @@ type Context struct {
 	Methods      *query.MethodSet
 	namedContext *namedContext
 	importer     Importer
+
+	// Maps defined so far within the mapping being parsed, which allows
+	// parameterised maps to be called as functions and methods.
+	maps map[string]query.Function
+
+	disableOptimisations bool
+
+	// Optionally records the branches of parsed expressions and statements,
+	// along with the mapping source they were parsed from.
+	coverage       *BranchCoverage
+	coverageSource coverageSource
 }
 
 // EmptyContext returns a parser context with no functions, methods or import
@@ -98,9 +109,37 @@ func (pCtx Context) Deactivated() Context {
 	nextCtx := pCtx
 	nextCtx.Functions = pCtx.Functions.Deactivated()
 	nextCtx.Methods = pCtx.Methods.Deactivated()
+	nextCtx.disableOptimisations = true
+	return nextCtx
+}
+
+// DisabledOptimisations returns a version of the parser context where parsed
+// mappings are executed exactly as they were written, which is useful when the
+// statements of a mapping are inspected or traced.
+func (pCtx Context) DisabledOptimisations() Context {
+	nextCtx := pCtx
+	nextCtx.Methods = pCtx.Methods.WithoutFolding()
+	nextCtx.disableOptimisations = true
 	return nextCtx
 }
 
+// WithBranchCoverage returns a version of the parser context where the branches
+// of match and if expressions, and root-level if statements, of parsed mappings
+// and field interpolations are recorded by the provided coverage, which also
+// counts the branches taken during execution.
+func (pCtx Context) WithBranchCoverage(c *BranchCoverage) Context {
+	nextCtx := pCtx
+	nextCtx.coverage = c
+	return nextCtx
+}
+
+// withCoverageSource returns a version of the parser context where recorded
+// branches are located within the provided mapping source.
+func (pCtx Context) withCoverageSource(content []rune, importPath string) Context {
+	pCtx.coverageSource = coverageSource{content: content, importPath: importPath}
+	return pCtx
+}
+
 // CustomImporter returns a version of the parser context where file imports are
 // done exclusively through a provided closure function, which takes an import
 // path (relative or absolute).
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+)
+
+// CoveredBranch identifies a single branch of a match or if expression, or of
+// a root-level if statement, within a parsed mapping.
+type CoveredBranch struct {
+	// The full contents of the mapping, or imported file, that contains the
+	// branch.
+	Mapping string
+
+	// The path of the imported file that contains the branch, or empty when the
+	// branch is within a mapping that was parsed directly.
+	Import string
+
+	Line   int
+	Column int
+
+	// The expression that the branch belongs to, e.g. "match expression".
+	Expression string
+
+	// A description of the branch, e.g. "case 1", or "none" when no branch is
+	// taken.
+	Branch string
+}
+
+// BranchCoverage records the branches of all match and if expressions, and
+// root-level if statements, of mappings parsed with it, along with the number
+// of times each branch is taken during execution. Branches of mappings that are
+// parsed more than once are recorded together.
+type BranchCoverage struct {
+	mut      sync.Mutex
+	branches map[CoveredBranch]*int64
+}
+
+// NewBranchCoverage creates an empty branch coverage record.
+func NewBranchCoverage() *BranchCoverage {
+	return &BranchCoverage{
+		branches: map[CoveredBranch]*int64{},
+	}
+}
+
+// Hits returns each branch recorded along with the number of times it has been
+// taken.
+func (b *BranchCoverage) Hits() map[CoveredBranch]int64 {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	hits := make(map[CoveredBranch]int64, len(b.branches))
+	for k, v := range b.branches {
+		hits[k] = atomic.LoadInt64(v)
+	}
+	return hits
+}
+
+func (b *BranchCoverage) register(source coverageSource, input []rune, expression string, branches []string) func(branch string) {
+	line, col := mapping.LineAndColOf(source.content, input)
+	counters := make(map[string]*int64, len(branches))
+
+	b.mut.Lock()
+	for _, branch := range branches {
+		k := CoveredBranch{
+			Mapping:    string(source.content),
+			Import:     source.importPath,
+			Line:       line,
+			Column:     col,
+			Expression: expression,
+			Branch:     branch,
+		}
+		c, exists := b.branches[k]
+		if !exists {
+			c = new(int64)
+			b.branches[k] = c
+		}
+		counters[branch] = c
+	}
+	b.mut.Unlock()
+
+	return func(branch string) {
+		if c := counters[branch]; c != nil {
+			atomic.AddInt64(c, 1)
+		}
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type coverageSource struct {
+	content    []rune
+	importPath string
+}
+
+// coverExpression registers the branches of a match or if expression parsed
+// from an input with the branch coverage of the context, if any.
+func (pCtx Context) coverExpression(input []rune, fn query.Function) {
+	if pCtx.coverage == nil || len(pCtx.coverageSource.content) == 0 {
+		return
+	}
+	branches := query.Branches(fn)
+	if len(branches) == 0 {
+		return
+	}
+	query.OnBranch(fn, pCtx.coverage.register(pCtx.coverageSource, input, fn.Annotation(), branches))
+}
+
+// coverStatement registers the branches of a root-level if statement parsed
+// from an input with the branch coverage of the context, if any.
+func (pCtx Context) coverStatement(input []rune, stmt *mapping.RootLevelIfStatement) {
+	if pCtx.coverage == nil || len(pCtx.coverageSource.content) == 0 {
+		return
+	}
+	stmt.OnBranch(pCtx.coverage.register(pCtx.coverageSource, input, "if statement", stmt.Branches()))
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func TestBranchCoverage(t *testing.T) {
+	imported := `map kind {
+  root = if this > 10 { "big" } else { "small" }
+}`
+
+	mapping := `import "kinds.blobl"
+
+root.name = match this.name {
+  "foo" => "is foo"
+  _ => "not foo"
+}
+if this.n > 1 {
+  root.kind = this.n.apply("kind")
+}`
+
+	coverage := NewBranchCoverage()
+	pCtx := GlobalContext().
+		CustomImporter(func(name string) ([]byte, error) {
+			return []byte(imported), nil
+		}).
+		WithBranchCoverage(coverage)
+
+	// Parsing a mapping more than once should not duplicate branches.
+	for i := 0; i < 2; i++ {
+		exec, err := ParseMapping(pCtx, mapping)
+		require.Nil(t, err)
+
+		for _, input := range []string{`{"name":"foo","n":20}`, `{"name":"bar","n":0}`} {
+			_, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(input)}))
+			require.NoError(t, err)
+		}
+	}
+
+	field, err := ParseField(pCtx, `${! if this.n > 1 { "many" } }`)
+	require.Nil(t, err)
+
+	_, ferr := field.String(0, message.QuickBatch([][]byte{[]byte(`{"n":0}`)}))
+	require.NoError(t, ferr)
+
+	hits := map[CoveredBranch]int64{}
+	for k, v := range coverage.Hits() {
+		k.Mapping = ""
+		hits[k] = v
+	}
+
+	assert.Equal(t, map[CoveredBranch]int64{
+		{Line: 3, Column: 13, Expression: "match expression", Branch: "case 0"}: 2,
+		{Line: 3, Column: 13, Expression: "match expression", Branch: "case 1"}: 2,
+		{Line: 7, Column: 1, Expression: "if statement", Branch: "if"}:          2,
+		{Line: 7, Column: 1, Expression: "if statement", Branch: "none"}:        2,
+
+		{Import: "kinds.blobl", Line: 2, Column: 10, Expression: "if expression", Branch: "if"}:   2,
+		{Import: "kinds.blobl", Line: 2, Column: 10, Expression: "if expression", Branch: "else"}: 0,
+
+		{Line: 1, Column: 5, Expression: "if expression", Branch: "if"}:   0,
+		{Line: 1, Column: 5, Expression: "if expression", Branch: "none"}: 1,
+	}, hits)
+}
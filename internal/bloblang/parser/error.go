@@ -153,20 +153,21 @@ func (e *Error) Add(from *Error) {
 // ErrorAtPosition returns a human readable error string including the line and
 // character position of the error.
 func (e *Error) ErrorAtPosition(input []rune) string {
-	importErr, isImport := e.Err.(*ImportError)
+	line, char := LineAndColOf(input, e.Input)
+	return fmt.Sprintf("line %v char %v: %v", line, char, e.ErrorWithoutPosition())
+}
 
-	var errStr string
-	if isImport {
-		errStr = fmt.Sprintf(
+// ErrorWithoutPosition returns a human readable error string without the
+// position of the error, which is useful when the position is conveyed
+// separately.
+func (e *Error) ErrorWithoutPosition() string {
+	if importErr, isImport := e.Err.(*ImportError); isImport {
+		return fmt.Sprintf(
 			"failed to parse import '%v': %v", importErr.filepath,
 			importErr.perr.ErrorAtPosition(importErr.content),
 		)
-	} else {
-		errStr = e.errorMsg(false)
 	}
-
-	line, char := LineAndColOf(input, e.Input)
-	return fmt.Sprintf("line %v char %v: %v", line, char, errStr)
+	return e.errorMsg(false)
 }
 
 // ErrorAtChar returns a human readable error string including the character
@@ -59,6 +59,9 @@ var escapedBlock = func() Func[field.Resolver] {
 func parseFieldResolvers(pCtx Context, expr string) ([]field.Resolver, *Error) {
 	var resolvers []field.Resolver
 
+	remaining := []rune(expr)
+	pCtx = pCtx.withCoverageSource(remaining, "")
+
 	p := OneOf(
 		escapedBlock,
 		aFunction(pCtx),
@@ -66,7 +69,6 @@ func parseFieldResolvers(pCtx Context, expr string) ([]field.Resolver, *Error) {
 		intoStaticResolver(NotChar('$')),
 	)
 
-	remaining := []rune(expr)
 	for len(remaining) > 0 {
 		res := p(remaining)
 		if res.Err != nil {
@@ -0,0 +1,321 @@
+package parser
+
+import (
+	"strings"
+)
+
+type formatTokenType int
+
+const (
+	formatTokenWord formatTokenType = iota
+	formatTokenString
+	formatTokenComment
+	formatTokenNewline
+	formatTokenOperator
+	formatTokenOpen
+	formatTokenClose
+	formatTokenDot
+	formatTokenComma
+	formatTokenColon
+	formatTokenNot
+)
+
+type formatToken struct {
+	typ   formatTokenType
+	value string
+	unary bool
+}
+
+var formatOperators = []string{
+	"->", "=>", "==", "!=", ">=", "<=", "&&", "||",
+	"=", "+", "-", "*", "/", "%", "<", ">", "|",
+}
+
+var formatKeywords = map[string]struct{}{
+	"if":    {},
+	"else":  {},
+	"match": {},
+}
+
+func isFormatWordRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func tokenizeMapping(input []rune) []formatToken {
+	var tokens []formatToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '\n':
+			tokens = append(tokens, formatToken{typ: formatTokenNewline})
+			i++
+		case c == '#':
+			j := i
+			for j < len(input) && input[j] != '\n' {
+				j++
+			}
+			tokens = append(tokens, formatToken{typ: formatTokenComment, value: strings.TrimRight(string(input[i:j]), " \t\r")})
+			i = j
+		case c == '"':
+			j := i + 1
+			if strings.HasPrefix(string(input[i:min(i+3, len(input))]), `"""`) {
+				j = i + 3
+				for j < len(input) && !strings.HasPrefix(string(input[j:min(j+3, len(input))]), `"""`) {
+					j++
+				}
+				j = min(j+3, len(input))
+			} else {
+				for j < len(input) && input[j] != '"' && input[j] != '\n' {
+					if input[j] == '\\' {
+						j++
+					}
+					j++
+				}
+				j = min(j+1, len(input))
+			}
+			tokens = append(tokens, formatToken{typ: formatTokenString, value: string(input[i:j])})
+			i = j
+		case c == '$' || c == '@' || isFormatWordRune(c):
+			j := i + 1
+			if c == '@' && j < len(input) && input[j] == '"' {
+				for j++; j < len(input) && input[j] != '"' && input[j] != '\n'; j++ {
+					if input[j] == '\\' {
+						j++
+					}
+				}
+				j = min(j+1, len(input))
+			} else {
+				for j < len(input) && isFormatWordRune(input[j]) {
+					j++
+				}
+			}
+			tokens = append(tokens, formatToken{typ: formatTokenWord, value: string(input[i:j])})
+			i = j
+		case c == '(' || c == '[' || c == '{':
+			tokens = append(tokens, formatToken{typ: formatTokenOpen, value: string(c)})
+			i++
+		case c == ')' || c == ']' || c == '}':
+			tokens = append(tokens, formatToken{typ: formatTokenClose, value: string(c)})
+			i++
+		case c == '.':
+			tokens = append(tokens, formatToken{typ: formatTokenDot, value: "."})
+			i++
+		case c == ',':
+			tokens = append(tokens, formatToken{typ: formatTokenComma, value: ","})
+			i++
+		case c == ':':
+			tokens = append(tokens, formatToken{typ: formatTokenColon, value: ":"})
+			i++
+		default:
+			op := string(c)
+			for _, o := range formatOperators {
+				if strings.HasPrefix(string(input[i:min(i+len(o), len(input))]), o) {
+					op = o
+					break
+				}
+			}
+			if op == "!" {
+				tokens = append(tokens, formatToken{typ: formatTokenNot, value: op, unary: true})
+			} else {
+				tokens = append(tokens, formatToken{typ: formatTokenOperator, value: op})
+			}
+			i += len([]rune(op))
+		}
+	}
+
+	// Determine which minus operators are unary, which is the case when they
+	// don't follow an operand.
+	var prev *formatToken
+	for i := range tokens {
+		t := &tokens[i]
+		if t.typ == formatTokenComment {
+			continue
+		}
+		if t.typ == formatTokenOperator && t.value == "-" {
+			t.unary = prev == nil || !isFormatOperand(*prev)
+		}
+		prev = t
+	}
+	return tokens
+}
+
+// isFormatOperand returns true if a token is able to end an operand, and
+// therefore a following minus is a binary operator.
+func isFormatOperand(t formatToken) bool {
+	switch t.typ {
+	case formatTokenWord:
+		_, isKeyword := formatKeywords[t.value]
+		return !isKeyword
+	case formatTokenString, formatTokenClose:
+		return true
+	}
+	return false
+}
+
+type formatBracket struct {
+	value string
+	line  int
+}
+
+// FormatMapping reformats a Bloblang mapping into a canonical form where
+// comments and line breaks are preserved, but whitespace between tokens and
+// the indentation of each line is normalised. Consecutive empty lines are
+// collapsed into one, leading and trailing empty lines are removed, and a
+// non-empty result always ends with a line break.
+//
+// The mapping is not validated and therefore should be parsed before it is
+// formatted.
+func FormatMapping(mapping string) string {
+	tokens := tokenizeMapping([]rune(mapping))
+
+	var lines [][]formatToken
+	var current []formatToken
+	for _, t := range tokens {
+		if t.typ == formatTokenNewline {
+			lines = append(lines, current)
+			current = nil
+			continue
+		}
+		current = append(current, t)
+	}
+	lines = append(lines, current)
+
+	var b strings.Builder
+	var stack []formatBracket
+	var prevLine []formatToken
+
+	pendingBlank, written := false, false
+	for lineNum, line := range lines {
+		if len(line) == 0 {
+			pendingBlank = written
+			continue
+		}
+		if written {
+			b.WriteString("\n")
+			if pendingBlank {
+				b.WriteString("\n")
+			}
+		}
+		pendingBlank, written = false, true
+
+		// Closing brackets at the beginning of a line reduce its indentation.
+		depthStack := stack
+		for _, t := range line {
+			if t.typ != formatTokenClose || len(depthStack) == 0 {
+				break
+			}
+			depthStack = depthStack[:len(depthStack)-1]
+		}
+
+		indent := 0
+		lastLine := -1
+		for _, br := range depthStack {
+			if br.line != lastLine {
+				indent++
+				lastLine = br.line
+			}
+		}
+		if isFormatContinuation(prevLine, line) {
+			indent++
+		}
+		b.WriteString(strings.Repeat("  ", indent))
+
+		for i, t := range line {
+			if i > 0 && formatSpaceBetween(line[i-1], t, stack) {
+				b.WriteString(" ")
+			}
+			b.WriteString(t.value)
+
+			switch t.typ {
+			case formatTokenOpen:
+				stack = append(stack, formatBracket{value: t.value, line: lineNum})
+			case formatTokenClose:
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			}
+		}
+		prevLine = line
+	}
+	if written {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func lastSignificantToken(line []formatToken) (formatToken, bool) {
+	for i := len(line) - 1; i >= 0; i-- {
+		if line[i].typ != formatTokenComment {
+			return line[i], true
+		}
+	}
+	return formatToken{}, false
+}
+
+// isFormatContinuation returns true when a line continues the expression of
+// the previous line, either by beginning with a method or operator, or by
+// following a line that ends with an operator.
+func isFormatContinuation(prevLine, line []formatToken) bool {
+	switch first := line[0]; first.typ {
+	case formatTokenDot:
+		return true
+	case formatTokenOperator:
+		if !first.unary {
+			return true
+		}
+	}
+	if last, ok := lastSignificantToken(prevLine); ok {
+		return last.typ == formatTokenOperator && !last.unary
+	}
+	return false
+}
+
+func formatSpaceBetween(prev, next formatToken, stack []formatBracket) bool {
+	if next.typ == formatTokenComment {
+		return true
+	}
+
+	var innermost string
+	if len(stack) > 0 {
+		innermost = stack[len(stack)-1].value
+	}
+
+	switch next.typ {
+	case formatTokenComma, formatTokenDot:
+		return false
+	case formatTokenClose:
+		return next.value == "}" && !(prev.typ == formatTokenOpen && prev.value == "{")
+	case formatTokenColon:
+		return false
+	}
+
+	switch prev.typ {
+	case formatTokenDot, formatTokenNot:
+		return false
+	case formatTokenOpen:
+		return prev.value == "{"
+	case formatTokenColon:
+		// Slices are not spaced, e.g. [1:3]
+		return innermost != "["
+	case formatTokenComma:
+		return true
+	case formatTokenOperator:
+		return !prev.unary
+	}
+
+	if next.typ == formatTokenOpen {
+		switch next.value {
+		case "(":
+			if prev.typ == formatTokenWord {
+				_, isKeyword := formatKeywords[prev.value]
+				return isKeyword
+			}
+			return prev.typ != formatTokenClose
+		case "[":
+			return !isFormatOperand(prev)
+		}
+	}
+	return true
+}
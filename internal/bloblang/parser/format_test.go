@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatMapping(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		output string
+	}{
+		"operators and assignments": {
+			input:  `root.foo   =   this.bar+5*this.baz`,
+			output: `root.foo = this.bar + 5 * this.baz`,
+		},
+		"unary operators": {
+			input:  `root.foo = - 1 + this.x-3 && ! this.y`,
+			output: `root.foo = -1 + this.x - 3 && !this.y`,
+		},
+		"calls and arguments": {
+			input:  `root.foo = this.thing.replace_all( old:"a",new: "b" ).uppercase( )`,
+			output: `root.foo = this.thing.replace_all(old: "a", new: "b").uppercase()`,
+		},
+		"indexes and slices": {
+			input:  `root.foo = this.items [0].value.slice(1) + this.message[ 1 : -1 ]`,
+			output: `root.foo = this.items[0].value.slice(1) + this.message[1:-1]`,
+		},
+		"literals": {
+			input:  `root.foo = {"a":1,"b":[1,2, 3 ], "c": {}, "d": [ ]}`,
+			output: `root.foo = { "a": 1, "b": [1, 2, 3], "c": {}, "d": [] }`,
+		},
+		"comments": {
+			input: `
+  # a comment
+root.foo = this.bar    # trailing comment
+#no space`,
+			output: `# a comment
+root.foo = this.bar # trailing comment
+#no space`,
+		},
+		"empty lines": {
+			input: `
+
+root.foo = this.foo
+
+
+root.bar = this.bar
+
+`,
+			output: `root.foo = this.foo
+
+root.bar = this.bar`,
+		},
+		"blocks": {
+			input: `root.foo = if this.x>5 {
+"big"
+    } else if !this.y {
+   "small"
+}
+root.bar = match this.x {
+  "a"=>1
+    _ => -2
+}`,
+			output: `root.foo = if this.x > 5 {
+  "big"
+} else if !this.y {
+  "small"
+}
+root.bar = match this.x {
+  "a" => 1
+  _ => -2
+}`,
+		},
+		"maps and lambdas": {
+			input: `map foo(a, b = 2) {
+root = $a+$b
+root.things = this.things.map_each(ele->ele.uppercase()).filter(t -> t.(v -> v != "x"))
+}`,
+			output: `map foo(a, b = 2) {
+  root = $a + $b
+  root.things = this.things.map_each(ele -> ele.uppercase()).filter(t -> t.(v -> v != "x"))
+}`,
+		},
+		"multiple brackets on a line": {
+			input: `root = this.foo.merge({
+      "a": [
+ 1,
+2
+    ]
+})`,
+			output: `root = this.foo.merge({
+  "a": [
+    1,
+    2
+  ]
+})`,
+		},
+		"operator continuation": {
+			input: `root = this.a +
+this.b`,
+			output: `root = this.a +
+  this.b`,
+		},
+		"strings are preserved": {
+			input: `root.foo = "a  # b"+"""
+  hello   world
+"""
+root.bar = this."foo  bar".baz`,
+			output: `root.foo = "a  # b" + """
+  hello   world
+"""
+root.bar = this."foo  bar".baz`,
+		},
+		"metadata and variables": {
+			input:  `meta foo = @bar.or( @"baz buz" ) | $qux`,
+			output: `meta foo = @bar.or(@"baz buz") | $qux`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			formatted := FormatMapping(test.input)
+			assert.Equal(t, test.output+"\n", formatted)
+			assert.Equal(t, formatted, FormatMapping(formatted), "formatting is not idempotent")
+
+			_, perr := ParseMapping(GlobalContext(), formatted)
+			require.Nil(t, perr)
+		})
+	}
+}
@@ -18,13 +18,14 @@ import (
 // messages.
 func ParseMapping(pCtx Context, expr string) (*mapping.Executor, *Error) {
 	in := []rune(expr)
+	pCtx = pCtx.withCoverageSource(in, "")
 
 	resDirectImport := singleRootImport(pCtx)(in)
 	if resDirectImport.Err != nil && resDirectImport.Err.IsFatal() {
 		return nil, resDirectImport.Err
 	}
 	if resDirectImport.Err == nil && len(resDirectImport.Remaining) == 0 {
-		return resDirectImport.Payload, nil
+		return optimiseMapping(pCtx, resDirectImport.Payload), nil
 	}
 
 	resExe := parseExecutor(pCtx)(in)
@@ -37,7 +38,14 @@ func ParseMapping(pCtx Context, expr string) (*mapping.Executor, *Error) {
 	if res.Err != nil {
 		return nil, res.Err
 	}
-	return res.Payload, nil
+	return optimiseMapping(pCtx, res.Payload), nil
+}
+
+func optimiseMapping(pCtx Context, exec *mapping.Executor) *mapping.Executor {
+	if !pCtx.disableOptimisations {
+		exec.Optimise()
+	}
+	return exec
 }
 
 //------------------------------------------------------------------------------
@@ -67,6 +75,7 @@ func parseExecutor(pCtx Context) Func[*mapping.Executor] {
 		maps := map[string]query.Function{}
 		statements := []mapping.Statement{}
 
+		pCtx.maps = maps
 		statementPattern := mappingStatement(pCtx, true, maps)
 
 		res := statementPattern(DiscardedWhitespaceNewlineComments(input).Remaining)
@@ -125,9 +134,8 @@ func singleRootImport(pCtx Context) Func[*mapping.Executor] {
 			return Fail[*mapping.Executor](NewFatalError(input, fmt.Errorf("failed to read import: %w", err)), input)
 		}
 
-		nextCtx := pCtx.WithImporterRelativeToFile(fpath)
-
 		importContent := []rune(string(contents))
+		nextCtx := pCtx.WithImporterRelativeToFile(fpath).withCoverageSource(importContent, fpath)
 		execRes := parseExecutor(nextCtx)(importContent)
 		if execRes.Err != nil {
 			return Fail[*mapping.Executor](NewFatalError(input, NewImportError(fpath, importContent, execRes.Err)), input)
@@ -214,9 +222,8 @@ func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 			return Fail[string](NewFatalError(input, fmt.Errorf("failed to read import: %w", err)), input)
 		}
 
-		nextCtx := pCtx.WithImporterRelativeToFile(fpath)
-
 		importContent := []rune(string(contents))
+		nextCtx := pCtx.WithImporterRelativeToFile(fpath).withCoverageSource(importContent, fpath)
 		execRes := parseExecutor(nextCtx)(importContent)
 		if execRes.Err != nil {
 			return Fail[string](NewFatalError(input, NewImportError(fpath, importContent, execRes.Err)), input)
@@ -245,8 +252,92 @@ func importParser(pCtx Context, maps map[string]query.Function) Func[string] {
 	}
 }
 
-func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
+type mapParam struct {
+	name         string
+	defaultValue query.Function
+}
+
+func mapParamParser(pCtx Context) Func[mapParam] {
 	p := Sequence(
+		FuncAsAny(Expect(varNameParser, "parameter name")),
+		FuncAsAny(OptionalPtr(TakeOnly(3, Sequence(
+			FuncAsAny(Discard(SpacesAndTabs)),
+			FuncAsAny(charEquals),
+			FuncAsAny(Discard(SpacesAndTabs)),
+			FuncAsAny(MustBe(Expect(literalValueParser(pCtx), "default value"))),
+		)))),
+	)
+
+	return func(input []rune) Result[mapParam] {
+		res := p(input)
+		if res.Err != nil {
+			return Fail[mapParam](res.Err, input)
+		}
+
+		param := mapParam{name: res.Payload[0].(string)}
+		if v := res.Payload[1].(*any); v != nil {
+			param.defaultValue = (*v).(query.Function)
+		}
+		return Success(param, res.Remaining)
+	}
+}
+
+func mapParamsParser(pCtx Context) Func[[]mapParam] {
+	return DelimitedPattern(
+		Sequence(charBracketOpen, DiscardedWhitespaceNewlineComments),
+		mapParamParser(pCtx),
+		MustBe(Expect(Sequence(Discard(SpacesAndTabs), charComma, DiscardedWhitespaceNewlineComments), "comma")),
+		MustBe(Expect(Sequence(DiscardedWhitespaceNewlineComments, charBracketClose), "closing bracket")),
+	)
+}
+
+func newMapParams(pCtx Context, name string, params []mapParam) (query.Params, error) {
+	if _, err := pCtx.Functions.Params(name); err == nil {
+		return query.Params{}, fmt.Errorf("map name %v collides with an existing function", name)
+	}
+	if _, err := pCtx.Methods.Params(name); err == nil {
+		return query.Params{}, fmt.Errorf("map name %v collides with an existing method", name)
+	}
+
+	seen := map[string]struct{}{}
+	seenDefault := false
+
+	p := query.NewParams()
+	for _, param := range params {
+		if _, exists := seen[param.name]; exists {
+			return query.Params{}, fmt.Errorf("duplicate parameter name: %v", param.name)
+		}
+		seen[param.name] = struct{}{}
+
+		def := query.ParamAny(param.name, "")
+		if param.defaultValue != nil {
+			lit, isLit := param.defaultValue.(*query.Literal)
+			if !isLit {
+				return query.Params{}, fmt.Errorf("default value of parameter %v must be a static literal", param.name)
+			}
+			def = def.Default(lit.Value)
+			seenDefault = true
+		} else if seenDefault {
+			return query.Params{}, fmt.Errorf("parameter %v without a default value cannot follow parameters with default values", param.name)
+		}
+		p = p.Add(def)
+	}
+	return p, nil
+}
+
+// mapParams returns the parameters of a map that has been defined prior to the
+// current position of the parser, if that map is able to be called as a
+// function or method.
+func (pCtx Context) mapParams(name string) (query.Params, bool) {
+	exec, ok := pCtx.maps[name].(*mapping.Executor)
+	if !ok {
+		return query.Params{}, false
+	}
+	return exec.Params()
+}
+
+func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
+	headerPattern := Sequence(
 		FuncAsAny(Term("map")),
 		FuncAsAny(SpacesAndTabs),
 		// Prevents a missing path from being captured by the next parser
@@ -259,28 +350,30 @@ func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
 				"map name",
 			),
 		)),
+		FuncAsAny(OptionalPtr(mapParamsParser(pCtx))),
 		FuncAsAny(SpacesAndTabs),
-		FuncAsAny(DelimitedPattern(
-			Sequence(
-				charSquigOpen,
-				DiscardedWhitespaceNewlineComments,
-			),
-			// Prevent imports, maps and metadata assignments.
-			mappingStatement(pCtx, false, nil),
-			Sequence(
-				Discard(SpacesAndTabs),
-				NewlineAllowComment,
-				DiscardedWhitespaceNewlineComments,
-			),
-			Sequence(
-				DiscardedWhitespaceNewlineComments,
-				charSquigClose,
-			),
-		)),
+	)
+
+	bodyPattern := DelimitedPattern(
+		Sequence(
+			charSquigOpen,
+			DiscardedWhitespaceNewlineComments,
+		),
+		// Prevent imports, maps and metadata assignments.
+		mappingStatement(pCtx, false, nil),
+		Sequence(
+			Discard(SpacesAndTabs),
+			NewlineAllowComment,
+			DiscardedWhitespaceNewlineComments,
+		),
+		Sequence(
+			DiscardedWhitespaceNewlineComments,
+			charSquigClose,
+		),
 	)
 
 	return func(input []rune) Result[string] {
-		res := p(input)
+		res := headerPattern(input)
 		if res.Err != nil {
 			return Fail[string](res.Err, input)
 		}
@@ -294,14 +387,38 @@ func mapParser(pCtx Context, maps map[string]query.Function) Func[string] {
 
 		seqSlice := res.Payload
 		ident := seqSlice[2].(string)
-		stmtSlice := seqSlice[4].([]mapping.Statement)
 
 		if _, exists := maps[ident]; exists {
 			return Fail[string](NewFatalError(input, fmt.Errorf("map name collision: %v", ident)), input)
 		}
 
-		maps[ident] = mapping.NewExecutor("map "+ident, input, maps, stmtSlice...)
-		return Success(ident, res.Remaining)
+		var params *query.Params
+		if paramsSlice := seqSlice[3].(*[]mapParam); paramsSlice != nil {
+			p, err := newMapParams(pCtx, ident, *paramsSlice)
+			if err != nil {
+				return Fail[string](NewFatalError(input, err), input)
+			}
+			params = &p
+
+			// Register the signature of the map before parsing its body so
+			// that it is able to call itself recursively.
+			signature := mapping.NewExecutor("map "+ident, input, maps)
+			signature.SetParams(p)
+			maps[ident] = signature
+		}
+
+		bodyRes := bodyPattern(res.Remaining)
+		if bodyRes.Err != nil {
+			delete(maps, ident)
+			return Fail[string](bodyRes.Err, input)
+		}
+
+		exec := mapping.NewExecutor("map "+ident, input, maps, bodyRes.Payload...)
+		if params != nil {
+			exec.SetParams(*params)
+		}
+		maps[ident] = exec
+		return Success(ident, bodyRes.Remaining)
 	}
 }
 
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
 	"github.com/warpstreamlabs/bento/internal/message"
 )
 
@@ -587,6 +589,242 @@ from "%v"`, directMapFile),
 	}
 }
 
+func TestMappingParameterisedMaps(t *testing.T) {
+	dir := t.TempDir()
+
+	libFile := filepath.Join(dir, "lib.blobl")
+	require.NoError(t, os.WriteFile(libFile, []byte(`map mask(field, keep_last = 4) {
+  let value = this.get($field).string()
+  let cut = $value.length() - $keep_last
+  root = this.without($field).merge({ $field: "*".repeat($cut) + $value.slice($cut) })
+}`), 0o777))
+
+	tests := map[string]struct {
+		mapping string
+		input   string
+		output  string
+	}{
+		"method with args": {
+			mapping: `map greet(greeting, punctuation) {
+  root = $greeting + " " + this.name + $punctuation
+}
+root.a = this.doc.greet("hello", "!")
+root.b = this.doc.greet(punctuation: "?", greeting: "hey")`,
+			input:  `{"doc":{"name":"bob"}}`,
+			output: `{"a":"hello bob!","b":"hey bob?"}`,
+		},
+		"function uses current context": {
+			mapping: `map full_name(sep = " ") {
+  root = this.first + $sep + this.last
+}
+root.a = full_name()
+root.b = full_name("_")`,
+			input:  `{"first":"foo","last":"bar"}`,
+			output: `{"a":"foo bar","b":"foo_bar"}`,
+		},
+		"args resolved in caller context": {
+			mapping: `map add(n) {
+  root = this + $n
+}
+root = this.a.add(this.b)`,
+			input:  `{"a":3,"b":4}`,
+			output: `7`,
+		},
+		"variables are isolated": {
+			mapping: `map foo(a) {
+  root = [ $a, $b | "nope" ]
+}
+let b = "outer"
+root = null.foo("inner")`,
+			input:  `{}`,
+			output: `["inner","nope"]`,
+		},
+		"null and structured defaults": {
+			mapping: `map foo(a = null, b = ["x", "y"], c = {"z": 1}) {
+  root = [ $a, $b, $c ]
+}
+root = foo()`,
+			input:  `{}`,
+			output: `[null,["x","y"],{"z":1}]`,
+		},
+		"recursion": {
+			mapping: `map fact(n) {
+  root = if $n <= 1 { 1 } else { $n * fact($n - 1) }
+}
+root = fact(this.n)`,
+			input:  `{"n":5}`,
+			output: `120`,
+		},
+		"recursion as method": {
+			mapping: `map depth(acc = 0) {
+  root = if this.child != null { this.child.depth($acc + 1) } else { $acc }
+}
+root = this.depth()`,
+			input:  `{"child":{"child":{"child":{}}}}`,
+			output: `3`,
+		},
+		"calls across imports": {
+			mapping: fmt.Sprintf(`import "%v"
+root = this.mask("card")`, libFile),
+			input:  `{"card":"1234567890"}`,
+			output: `{"card":"******7890"}`,
+		},
+		"calls across imports with defaults overridden": {
+			mapping: fmt.Sprintf(`import "%v"
+root = this.mask(field: "card", keep_last: 2)`, libFile),
+			input:  `{"card":"1234567890"}`,
+			output: `{"card":"********90"}`,
+		},
+		"parameterised map can be applied": {
+			mapping: `map foo(a = "default") {
+  root = this.name + " " + $a
+}
+root = this.apply("foo")`,
+			input:  `{"name":"bob"}`,
+			output: `bob default`,
+		},
+		"applied defaults are isolated from the caller": {
+			mapping: `map foo(a = "default") {
+  root = $a
+}
+let a = "outer"
+root.applied = this.apply("foo")
+root.outer = $a`,
+			input:  `{}`,
+			output: `{"applied":"default","outer":"outer"}`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			exec, perr := ParseMapping(GlobalContext(), test.mapping)
+			require.Nil(t, perr)
+
+			resPart, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(test.input)}))
+			require.NoError(t, err)
+			assert.Equal(t, test.output, string(resPart.AsBytes()))
+		})
+	}
+}
+
+func TestMappingParameterisedMapErrors(t *testing.T) {
+	tests := map[string]struct {
+		mapping     string
+		errContains string
+	}{
+		"too many args": {
+			mapping: `map foo(a) { root = $a }
+root = foo(1, 2)`,
+			errContains: "wrong number of arguments, expected 1, got 2",
+		},
+		"missing args": {
+			mapping: `map foo(a, b = 2) { root = $a }
+root = this.foo()`,
+			errContains: "missing parameter: a",
+		},
+		"unknown named arg": {
+			mapping: `map foo(a) { root = $a }
+root = foo(b: 1)`,
+			errContains: "unknown parameter b, did you mean a?",
+		},
+		"duplicate parameters": {
+			mapping:     `map foo(a, a) { root = $a }`,
+			errContains: "duplicate parameter name: a",
+		},
+		"required after default": {
+			mapping:     `map foo(a = 1, b) { root = $a }`,
+			errContains: "parameter b without a default value cannot follow parameters with default values",
+		},
+		"dynamic default": {
+			mapping:     `map foo(a = [ this.foo ]) { root = $a }`,
+			errContains: "default value of parameter a must be a static literal",
+		},
+		"collides with function": {
+			mapping:     `map uuid_v4() { root = "nope" }`,
+			errContains: "map name uuid_v4 collides with an existing function",
+		},
+		"collides with method": {
+			mapping:     `map uppercase() { root = "nope" }`,
+			errContains: "map name uppercase collides with an existing method",
+		},
+		"called before definition": {
+			mapping: `root = foo(1)
+map foo(a) { root = $a }`,
+			errContains: "unrecognised function 'foo'",
+		},
+		"plain map is not callable": {
+			mapping: `map foo { root = this }
+root = this.foo()`,
+			errContains: "unrecognised method 'foo'",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseMapping(GlobalContext(), test.mapping)
+			require.NotNil(t, err)
+			assert.Contains(t, err.ErrorAtPosition([]rune(test.mapping)), test.errContains)
+		})
+	}
+}
+
+func TestMappingParameterisedMapRecursionLimit(t *testing.T) {
+	exec, perr := ParseMapping(GlobalContext(), `map forever(n) {
+  root = forever($n + 1)
+}
+root = forever(0)`)
+	require.Nil(t, perr)
+
+	exec.SetMaxMapRecursion(10)
+
+	_, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "entering map forever exceeded maximum allowed stacks of 10")
+}
+
+func TestMappingParameterisedMapDefaultsDoNotLeak(t *testing.T) {
+	exec, perr := ParseMapping(GlobalContext(), `map foo(a = "default", b = "other") {
+  root = [ $a, $b ]
+}`)
+	require.Nil(t, perr)
+
+	vars := map[string]any{"b": "set"}
+	res, err := exec.Maps()["foo"].Exec(query.FunctionContext{
+		Maps:     exec.Maps(),
+		Vars:     vars,
+		MsgBatch: message.QuickBatch(nil),
+	}.WithValue(map[string]any{}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"default", "set"}, res)
+	assert.Equal(t, map[string]any{"b": "set"}, vars)
+}
+
+func TestMappingParameterisedMapTargets(t *testing.T) {
+	exec, perr := ParseMapping(GlobalContext(), `map depth(acc = 0) {
+  root = if this.child != null { this.child.depth($acc + this.inc) } else { $acc }
+}
+root = this.doc.depth(this.start)`)
+	require.Nil(t, perr)
+
+	_, targets := exec.QueryTargets(query.TargetsContext{
+		Maps: map[string]query.Function{},
+	})
+
+	var paths []string
+	for _, t := range targets {
+		if t.Type == query.TargetValue {
+			paths = append(paths, strings.Join(t.Path, "."))
+		}
+	}
+	assert.Contains(t, paths, "start")
+	assert.Contains(t, paths, "doc")
+	assert.Contains(t, paths, "doc.child")
+	assert.Contains(t, paths, "doc.inc")
+}
+
 func BenchmarkMappingParser(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, err := ParseMapping(GlobalContext(), `
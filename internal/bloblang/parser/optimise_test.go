@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func TestMappingOptimisations(t *testing.T) {
+	input := `{"name":"foo","n":2,"doc":{"a":{"b":"c"}},"arr":[{"x":1},{"x":2}]}`
+
+	tests := map[string]struct {
+		mapping string
+		output  string
+		err     string
+	}{
+		"root this with mutations": {
+			mapping: `root = this
+root.doc.a.b = "changed"
+root.doc.a.d = "new"
+root.name = deleted()`,
+			output: `{"arr":[{"x":1},{"x":2}],"doc":{"a":{"b":"changed","d":"new"}},"n":2}`,
+		},
+		"root this with array mutations": {
+			mapping: `root = this
+root.arr.0.x = 10
+root.arr."-" = {"x":3}
+root.arr.1.x = deleted()`,
+			output: `{"arr":[{"x":10},{},{"x":3}],"doc":{"a":{"b":"c"}},"n":2,"name":"foo"}`,
+		},
+		"root this with wildcard deletes": {
+			mapping: `root = this
+root.arr."*".x = deleted()`,
+			output: `{"arr":[{"x":1},{"x":2}],"doc":{"a":{"b":"c"}},"n":2,"name":"foo"}`,
+		},
+		"root this field with mutations": {
+			mapping: `root = this.doc
+root.a.b = this.name
+root.copy = root.a`,
+			output: `{"a":{"b":"foo"},"copy":{"b":"foo"}}`,
+		},
+		"root this reads after mutations": {
+			mapping: `root = this
+root.doc.a.b = "changed"
+root.original = this.doc.a.b
+root.mutated = root.doc.a.b`,
+			output: `{"arr":[{"x":1},{"x":2}],"doc":{"a":{"b":"changed"}},"mutated":"changed","n":2,"name":"foo","original":"c"}`,
+		},
+		"root captured by variable": {
+			mapping: `root = this
+let r = root.doc
+root.doc.a.b = "changed"
+root.captured = $r.a.b`,
+			output: `{"arr":[{"x":1},{"x":2}],"captured":"changed","doc":{"a":{"b":"changed"}},"n":2,"name":"foo"}`,
+		},
+		"root this mutation error": {
+			mapping: `root = this
+root.name.foo = "bar"`,
+			err: "failed assignment (line 2): unable to set target path name.foo as the value of name was a non-object type (string)",
+		},
+		"overwritten variable": {
+			mapping: `let x = "default"
+let x = this.name
+root.x = $x`,
+			output: `{"x":"foo"}`,
+		},
+		"overwritten variable reading itself": {
+			mapping: `let x = "default"
+let x = $x + this.name
+root.x = $x`,
+			output: `{"x":"defaultfoo"}`,
+		},
+		"overwritten variable deleted": {
+			mapping: `let x = "default"
+let x = deleted()
+root.x = $x | "missing"`,
+			output: `{"x":"missing"}`,
+		},
+		"overwritten root that might be nothing": {
+			mapping: `root = "default"
+root = match this.n {
+  1 => "one"
+}`,
+			output: `default`,
+		},
+		"overwritten root in if statement": {
+			mapping: `if this.n == 2 {
+  root = "default"
+  root = this.name
+}`,
+			output: `foo`,
+		},
+		"folded methods": {
+			mapping: `root.a = "foo".uppercase()
+root.b = ["c", "a", "b"].sort().join(",")
+root.c = 5.string() + this.name
+root.d = !true`,
+			output: `{"a":"FOO","b":"a,b,c","c":"5foo","d":false}`,
+		},
+		"folded method errors at execution": {
+			mapping: `root.a = this.name
+root.b = "foo".number()`,
+			err: `failed assignment (line 2): string literal: strconv.ParseFloat: parsing "foo": invalid syntax`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			for _, pCtx := range []Context{GlobalContext(), GlobalContext().DisabledOptimisations()} {
+				exec, perr := ParseMapping(pCtx, test.mapping)
+				require.Nil(t, perr)
+
+				msg := message.QuickBatch([][]byte{[]byte(input)})
+				_, err := msg.Get(0).AsStructured()
+				require.NoError(t, err)
+
+				res, err := exec.MapPart(0, msg)
+				if test.err != "" {
+					require.EqualError(t, err, test.err)
+				} else {
+					require.NoError(t, err)
+					assert.Equal(t, test.output, string(res.AsBytes()))
+
+					// Mutating the result must never modify the input.
+					if v, _ := res.AsStructuredMut(); v != nil {
+						obj, _ := v.(map[string]any)
+						for _, child := range obj {
+							if childObj, ok := child.(map[string]any); ok {
+								childObj["mutated"] = true
+							}
+						}
+					}
+				}
+
+				expectedInput, err := message.NewPart([]byte(input)).AsStructured()
+				require.NoError(t, err)
+
+				inputV, err := msg.Get(0).AsStructured()
+				require.NoError(t, err)
+				assert.Equal(t, expectedInput, inputV)
+			}
+		})
+	}
+}
+
+func BenchmarkMappingOptimisations(b *testing.B) {
+	input := []byte(`{"id":"abc","user":{"name":"foo","age":20,"address":{"city":"bar","street":"baz"}},"tags":["a","b","c"],"items":[{"price":1.5},{"price":2.5}],"meta":{"source":"test","version":2}}`)
+
+	mappings := map[string]string{
+		"root_this_mutations": `root = this
+root.user.address.city = this.user.address.city.uppercase()
+root.processed = true
+root.tags = deleted()`,
+		"constant_folding": `root.a = "foo".uppercase() + this.id
+root.b = ["c", "a", "b"].sort().join(",")
+root.c = "a,b,c".split(",").length() * "2".number()`,
+		"path_access": `root.city = this.user.address.city
+root.street = this.user.address.street
+root.source = this.meta.source
+root.version = this.meta.version`,
+		"dead_assignments": `let name = "unknown"
+let name = this.user.name
+root = "empty"
+root = this.user`,
+	}
+
+	for name, m := range mappings {
+		msg := message.QuickBatch([][]byte{input})
+		_, err := msg.Get(0).AsStructured()
+		require.NoError(b, err)
+
+		for _, opt := range []struct {
+			name string
+			pCtx Context
+		}{
+			{name: "optimised", pCtx: GlobalContext()},
+			{name: "unoptimised", pCtx: GlobalContext().DisabledOptimisations()},
+		} {
+			exec, err := ParseMapping(opt.pCtx, m)
+			require.Nil(b, err)
+
+			b.Run(name+"/"+opt.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					res, err := exec.MapPart(0, msg)
+					require.NoError(b, err)
+					require.NotNil(b, res)
+				}
+			})
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/warpstreamlabs/bento/internal/bloblang/query"
-	"github.com/warpstreamlabs/bento/internal/value"
 )
 
 func matchCaseParser(pCtx Context) Func[query.MatchCase] {
@@ -39,26 +38,17 @@ func matchCaseParser(pCtx Context) Func[query.MatchCase] {
 			return Fail[query.MatchCase](res.Err, input)
 		}
 
-		var caseFn query.Function
+		var matchCase query.MatchCase
 
 		if p := res.Payload[0]; p == nil {
-			caseFn = query.NewLiteralFunction("", true)
+			matchCase = query.NewMatchCase(query.NewLiteralFunction("", true), res.Payload[2])
 		} else if lit, isLiteral := p.(*query.Literal); isLiteral {
-			caseFn = query.ClosureFunction("case statement", func(ctx query.FunctionContext) (any, error) {
-				v := ctx.Value()
-				if v == nil {
-					return false, nil
-				}
-				return value.ICompare(*v, lit.Value), nil
-			}, nil)
+			matchCase = query.NewMatchValueCase(lit, res.Payload[2])
 		} else {
-			caseFn = p
+			matchCase = query.NewMatchCase(p, res.Payload[2])
 		}
 
-		return Success(
-			query.NewMatchCase(caseFn, res.Payload[2]),
-			res.Remaining,
-		)
+		return Success(matchCase, res.Remaining)
 	}
 }
 
@@ -100,7 +90,9 @@ func matchExpressionParser(pCtx Context) Func[query.Function] {
 
 		cases := seqSlice[4].([]query.MatchCase)
 
-		return Success(query.NewMatchFunction(contextFn, cases...), res.Remaining)
+		fn := query.NewMatchFunction(contextFn, cases...)
+		pCtx.coverExpression(input, fn)
+		return Success(fn, res.Remaining)
 	}
 }
 
@@ -179,7 +171,9 @@ func ifExpressionParser(pCtx Context) Func[query.Function] {
 			elseFn = res.Payload[5]
 		}
 
-		return Success(query.NewIfFunction(queryFn, ifFn, elseIfs, elseFn), res.Remaining)
+		fn := query.NewIfFunction(queryFn, ifFn, elseIfs, elseFn)
+		pCtx.coverExpression(input, fn)
+		return Success(fn, res.Remaining)
 	}
 }
 
@@ -452,6 +452,14 @@ func methodParser(fn query.Function, pCtx Context) Func[query.Function] {
 		seqSlice := res.Payload
 
 		targetMethod := seqSlice[0].(string)
+		if params, isMap := pCtx.mapParams(targetMethod); isMap {
+			parsedParams, err := extractArgsParserResult(params, seqSlice[1].([]any))
+			if err != nil {
+				return Fail[query.Function](NewFatalError(res.Remaining, err), input)
+			}
+			return Success(query.NewMapCall(targetMethod, fn, parsedParams), res.Remaining)
+		}
+
 		params, err := pCtx.Methods.Params(targetMethod)
 		if err != nil {
 			return Fail[query.Function](NewFatalError(res.Remaining, err), input)
@@ -482,6 +490,14 @@ func functionParser(pCtx Context) Func[query.Function] {
 		seqSlice := res.Payload
 
 		targetFunc := seqSlice[0].(string)
+		if params, isMap := pCtx.mapParams(targetFunc); isMap {
+			parsedParams, err := extractArgsParserResult(params, seqSlice[1].([]any))
+			if err != nil {
+				return Fail[query.Function](NewFatalError(res.Remaining, err), input)
+			}
+			return Success(query.NewMapCall(targetFunc, nil, parsedParams), res.Remaining)
+		}
+
 		params, err := pCtx.Functions.Params(targetFunc)
 		if err != nil {
 			return Fail[query.Function](NewFatalError(res.Remaining, err), input)
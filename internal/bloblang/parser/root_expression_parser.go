@@ -104,6 +104,7 @@ func rootLevelIfExpressionParser(pCtx Context) Func[mapping.Statement] {
 		if seqSlice = res.Payload; seqSlice != nil {
 			stmt.Add(nil, seqSlice[3].([]mapping.Statement)...)
 		}
+		pCtx.coverStatement(input, stmt)
 		return Success[mapping.Statement](stmt, res.Remaining)
 	}
 }
@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+)
+
+func TestMappingTypeCheck(t *testing.T) {
+	inputSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string"},
+			"age":  map[string]any{"type": "integer"},
+			"tags": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+			"address": map[string]any{"$ref": "#/definitions/address"},
+		},
+		"required": []any{"name", "age", "tags", "address"},
+		"definitions": map[string]any{
+			"address": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"city": map[string]any{"type": "string"},
+				},
+				"required": []any{"city"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		mapping string
+		schema  map[string]any
+		issues  []mapping.TypeIssue
+	}{
+		"no issues": {
+			mapping: `root.name = this.name.uppercase()
+root.age = this.age + 1
+root.tags = this.tags.join(",")`,
+			schema: inputSchema,
+		},
+		"unknown input": {
+			mapping: `root.name = this.name.uppercase() + this.age`,
+		},
+		"method on wrong literal type": {
+			mapping: `root.foo = 10.uppercase()`,
+			issues: []mapping.TypeIssue{
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Message: "number literal: expected string or bytes value, got number"}},
+			},
+		},
+		"method on wrong input type": {
+			mapping: `root.foo = "bar"
+root.age = this.age.uppercase()`,
+			schema: inputSchema,
+			issues: []mapping.TypeIssue{
+				{Line: 2, Column: 1, TypeIssue: query.TypeIssue{Message: "field `this.age`: expected string or bytes value, got number"}},
+			},
+		},
+		"method on nested ref type": {
+			mapping: `root.city = this.address.city.floor()`,
+			schema:  inputSchema,
+			issues: []mapping.TypeIssue{
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Message: "field `this.address.city`: expected number value, got string"}},
+			},
+		},
+		"optional field may be null": {
+			mapping: `root.nick = this.nickname.uppercase()`,
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"nickname": map[string]any{"type": "number"},
+				},
+			},
+			issues: []mapping.TypeIssue{
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Message: "field `this.nickname`: expected string or bytes value, got number or null"}},
+			},
+		},
+		"method chain return types": {
+			mapping: `root.foo = this.name.length().uppercase()`,
+			issues: []mapping.TypeIssue{
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Message: "method length: expected string or bytes value, got number"}},
+			},
+		},
+		"arithmetic mismatch": {
+			mapping: `root.foo = this.name - 5`,
+			schema:  inputSchema,
+			issues: []mapping.TypeIssue{
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Message: "cannot subtract types string (from field `this.name`) and number (from number literal)"}},
+			},
+		},
+		"variable types": {
+			mapping: `let n = this.name
+root.foo = $n.round()`,
+			schema: inputSchema,
+			issues: []mapping.TypeIssue{
+				{Line: 2, Column: 1, TypeIssue: query.TypeIssue{Message: "variable n: expected number value, got string"}},
+			},
+		},
+		"variable types from if branches": {
+			mapping: `if this.age > 10 {
+  let n = "foo"
+} else {
+  let n = 10
+}
+root.foo = $n.round()`,
+			schema: inputSchema,
+		},
+		"variable types from exhaustive if branches": {
+			mapping: `if this.age > 10 {
+  let n = "foo"
+} else {
+  let n = "bar"
+}
+root.foo = $n.round()`,
+			schema: inputSchema,
+			issues: []mapping.TypeIssue{
+				{Line: 6, Column: 1, TypeIssue: query.TypeIssue{Message: "variable n: expected number value, got string"}},
+			},
+		},
+		"match unreachable cases": {
+			mapping: `root.foo = match this.name {
+  "foo" => 1
+  10 => 2
+  _ => 3
+  "bar" => 4
+}`,
+			schema: inputSchema,
+			issues: []mapping.TypeIssue{
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Unreachable: true, Message: "match case 1 is unreachable as number values are never compared with values of type string"}},
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Unreachable: true, Message: "match case 3 is unreachable as a previous case always matches"}},
+			},
+		},
+		"match result type": {
+			mapping: `root.foo = match this.name {
+  "foo" => 1
+  _ => 2
+}.uppercase()`,
+			schema: inputSchema,
+			issues: []mapping.TypeIssue{
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Message: "match expression: expected string or bytes value, got number"}},
+			},
+		},
+		"if statement unreachable branches": {
+			mapping: `root = this
+if false {
+  root.foo = "a"
+} else if true {
+  root.foo = "b"
+} else {
+  root.foo = "c"
+}`,
+			issues: []mapping.TypeIssue{
+				{Line: 2, Column: 1, TypeIssue: query.TypeIssue{Unreachable: true, Message: "branch 1 of if statement is unreachable as its condition is always false"}},
+				{Line: 2, Column: 1, TypeIssue: query.TypeIssue{Unreachable: true, Message: "branch 3 of if statement is unreachable as a previous condition is always true"}},
+			},
+		},
+		"if statement non-boolean condition": {
+			mapping: `if this.name {
+  root.foo = "a"
+}`,
+			schema: inputSchema,
+			issues: []mapping.TypeIssue{
+				{Line: 1, Column: 1, TypeIssue: query.TypeIssue{Message: "field `this.name` resolves to a non-boolean value of type string"}},
+			},
+		},
+		"catch broadens types": {
+			mapping: `root.foo = this.name.number().catch("nope").uppercase()`,
+			schema:  inputSchema,
+		},
+	}
+
+	pCtx := GlobalContext().Deactivated()
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			exec, err := ParseMapping(pCtx, test.mapping)
+			require.Nil(t, err)
+
+			var input query.StaticType
+			if test.schema != nil {
+				input = query.StaticTypeFromJSONSchema(test.schema)
+			}
+			assert.Equal(t, test.issues, exec.CheckTypes(input))
+		})
+	}
+}
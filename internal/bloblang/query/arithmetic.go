@@ -58,7 +58,20 @@ func (o ArithmeticOperator) String() string {
 
 type arithmeticOpFunc[T any] func(lhs, rhs Function, l, r any) (T, error)
 
-func arithmeticFunc[T any](lhs, rhs Function, op arithmeticOpFunc[T]) (Function, error) {
+// arithmeticFunction is an operation that combines the results of two query
+// functions.
+type arithmeticFunction struct {
+	Function
+	op  ArithmeticOperator
+	lhs Function
+	rhs Function
+}
+
+func newArithmeticFunction(op ArithmeticOperator, lhs, rhs, fn Function) Function {
+	return &arithmeticFunction{Function: fn, op: op, lhs: lhs, rhs: rhs}
+}
+
+func arithmeticFunc[T any](arithOp ArithmeticOperator, lhs, rhs Function, op arithmeticOpFunc[T]) (Function, error) {
 	annotation := rhs.Annotation()
 
 	var litL, litR *Literal
@@ -73,7 +86,7 @@ func arithmeticFunc[T any](lhs, rhs Function, op arithmeticOpFunc[T]) (Function,
 		}
 	}
 
-	return ClosureFunction(annotation, func(ctx FunctionContext) (any, error) {
+	fn := ClosureFunction(annotation, func(ctx FunctionContext) (any, error) {
 		var err error
 		var leftV, rightV any
 		if leftV, err = lhs.Exec(ctx); err == nil {
@@ -83,7 +96,8 @@ func arithmeticFunc[T any](lhs, rhs Function, op arithmeticOpFunc[T]) (Function,
 			return nil, err
 		}
 		return op(lhs, rhs, leftV, rightV)
-	}, aggregateTargetPaths(lhs, rhs)), nil
+	}, aggregateTargetPaths(lhs, rhs))
+	return newArithmeticFunction(arithOp, lhs, rhs, fn), nil
 }
 
 //------------------------------------------------------------------------------
@@ -392,7 +406,7 @@ func compareOp(op ArithmeticOperator) (arithmeticOpFunc[bool], bool) {
 }
 
 func boolOr(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
+	fn := ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -413,10 +427,11 @@ func boolOr(lhs, rhs Function) Function {
 		}
 		return b, nil
 	}, aggregateTargetPaths(lhs, rhs))
+	return newArithmeticFunction(ArithmeticOr, lhs, rhs, fn)
 }
 
 func boolAnd(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
+	fn := ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -437,16 +452,18 @@ func boolAnd(lhs, rhs Function) Function {
 		}
 		return b, nil
 	}, aggregateTargetPaths(lhs, rhs))
+	return newArithmeticFunction(ArithmeticAnd, lhs, rhs, fn)
 }
 
 func coalesce(lhs, rhs Function) Function {
-	return ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
+	fn := ClosureFunction(rhs.Annotation(), func(ctx FunctionContext) (any, error) {
 		lhsV, err := lhs.Exec(ctx)
 		if err == nil && !value.IIsNull(lhsV) {
 			return lhsV, nil
 		}
 		return rhs.Exec(ctx)
 	}, aggregateTargetPaths(lhs, rhs))
+	return newArithmeticFunction(ArithmeticPipe, lhs, rhs, fn)
 }
 
 // NewArithmeticExpression creates a single query function from a list of child
@@ -467,7 +484,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isProd := prodOp(op); isProd {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(op, leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
 		} else if op == ArithmeticPipe {
@@ -487,7 +504,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isSum := sumOp(op); isSum {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(op, leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
 		} else {
@@ -505,7 +522,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 	for i, op := range ops {
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		if opFunc, isCompare := compareOp(op); isCompare {
-			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
+			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(op, leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
 		} else {
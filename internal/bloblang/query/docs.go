@@ -1,5 +1,9 @@
 package query
 
+import (
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
 // ExampleSpec provides a mapping example and some input/output results to
 // display.
 type ExampleSpec struct {
@@ -92,6 +96,10 @@ type FunctionSpec struct {
 
 	// Version is the Bento version this component was introduced.
 	Version string `json:"version,omitempty"`
+
+	// ReturnTypes optionally declares the types of value that the function
+	// may return, which is used for static type checking of mappings.
+	ReturnTypes []value.Type `json:"return_types,omitempty"`
 }
 
 // NewFunctionSpec creates a new function spec.
@@ -137,6 +145,12 @@ func (s FunctionSpec) Param(def ParamDefinition) FunctionSpec {
 	return s
 }
 
+// Returns declares the types of value that the function may return.
+func (s FunctionSpec) Returns(types ...value.Type) FunctionSpec {
+	s.ReturnTypes = types
+	return s
+}
+
 // NewDeprecatedFunctionSpec creates a new function spec that is deprecated.
 func NewDeprecatedFunctionSpec(name, description string, examples ...ExampleSpec) FunctionSpec {
 	description = `:::caution DEPRECATED
@@ -214,6 +228,14 @@ type MethodSpec struct {
 
 	// Version is the Bento version this component was introduced.
 	Version string `json:"version,omitempty"`
+
+	// TargetTypes optionally declares the types of value that the method can
+	// be executed upon, which is used for static type checking of mappings.
+	TargetTypes []value.Type `json:"target_types,omitempty"`
+
+	// ReturnTypes optionally declares the types of value that the method may
+	// return, which is used for static type checking of mappings.
+	ReturnTypes []value.Type `json:"return_types,omitempty"`
 }
 
 // NewMethodSpec creates a new method spec.
@@ -279,6 +301,18 @@ func (m MethodSpec) Param(def ParamDefinition) MethodSpec {
 	return m
 }
 
+// OnTypes declares the types of value that the method can be executed upon.
+func (m MethodSpec) OnTypes(types ...value.Type) MethodSpec {
+	m.TargetTypes = types
+	return m
+}
+
+// Returns declares the types of value that the method may return.
+func (m MethodSpec) Returns(types ...value.Type) MethodSpec {
+	m.ReturnTypes = types
+	return m
+}
+
 // VariadicParams configures the method spec to allow variadic parameters.
 func (m MethodSpec) VariadicParams() MethodSpec {
 	m.Params = VariadicParams()
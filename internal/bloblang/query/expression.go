@@ -9,8 +9,9 @@ import (
 // MatchCase represents a single match case of a match expression, where a case
 // query is checked and, if true, the underlying query is executed and returned.
 type MatchCase struct {
-	caseFn  Function
-	queryFn Function
+	caseFn    Function
+	caseValue *Literal
+	queryFn   Function
 }
 
 // NewMatchCase creates a single match case of a match expression, where a case
@@ -22,9 +23,34 @@ func NewMatchCase(caseFn, queryFn Function) MatchCase {
 	}
 }
 
+// NewMatchValueCase creates a single match case of a match expression, where
+// the case matches when the context value is equal to a literal value.
+func NewMatchValueCase(lit *Literal, queryFn Function) MatchCase {
+	return MatchCase{
+		caseFn: ClosureFunction("case statement", func(ctx FunctionContext) (any, error) {
+			v := ctx.Value()
+			if v == nil {
+				return false, nil
+			}
+			return value.ICompare(*v, lit.Value), nil
+		}, nil),
+		caseValue: lit,
+		queryFn:   queryFn,
+	}
+}
+
+// matchFunction is a match expression.
+type matchFunction struct {
+	Function
+	contextFn Function
+	cases     []MatchCase
+	onBranch  func(branch string)
+}
+
 // NewMatchFunction takes a contextual mapping and a list of MatchCases, when
 // the function is executed.
 func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
+	explicitContextFn := contextFn
 	if contextFn == nil {
 		contextFn = ClosureFunction("this", func(ctx FunctionContext) (any, error) {
 			var value any
@@ -34,7 +60,8 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 			return value, nil
 		}, nil)
 	}
-	return ClosureFunction("match expression", func(ctx FunctionContext) (any, error) {
+	m := &matchFunction{contextFn: explicitContextFn, cases: cases}
+	m.Function = ClosureFunction("match expression", func(ctx FunctionContext) (any, error) {
 		ctxVal, err := contextFn.Exec(ctx)
 		if err != nil {
 			return nil, err
@@ -46,9 +73,11 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 				return nil, fmt.Errorf("failed to check match case %v: %w", i, err)
 			}
 			if matched, _ := caseVal.(bool); matched {
+				takeBranch(ctx, m.onBranch, "match expression", "case", i)
 				return c.queryFn.Exec(caseCtx)
 			}
 		}
+		takeBranch(ctx, m.onBranch, "match expression", "none", -1)
 		return value.Nothing(nil), nil
 	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
 		contextCtx, contextTargets := contextFn.QueryTargets(ctx)
@@ -67,6 +96,7 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 		targets = append(targets, contextTargets...)
 		return ctx, targets
 	})
+	return m
 }
 
 // ElseIf represents an else-if block in an if expression.
@@ -75,6 +105,17 @@ type ElseIf struct {
 	MapFn   Function
 }
 
+// ifFunction is a logical if expression.
+type ifFunction struct {
+	Function
+	queryFn Function
+	ifFn    Function
+	elseIfs []ElseIf
+	elseFn  Function
+
+	onBranch func(branch string)
+}
+
 // NewIfFunction creates a logical if expression from a query which should
 // return a boolean value. If the returned boolean is true then the ifFn is
 // executed and returned, otherwise elseFn is executed and returned.
@@ -86,7 +127,8 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 		allFns = append(allFns, eIf.QueryFn, eIf.MapFn)
 	}
 
-	return ClosureFunction("if expression", func(ctx FunctionContext) (any, error) {
+	f := &ifFunction{queryFn: queryFn, ifFn: ifFn, elseIfs: elseIfs, elseFn: elseFn}
+	f.Function = ClosureFunction("if expression", func(ctx FunctionContext) (any, error) {
 		queryVal, err := queryFn.Exec(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check if condition: %w", err)
@@ -106,6 +148,7 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 			}
 		}
 		if queryRes {
+			takeBranch(ctx, f.onBranch, "if expression", "if", -1)
 			return ifFn.Exec(ctx)
 		}
 
@@ -124,15 +167,69 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 				}
 			}
 			if queryRes {
+				takeBranch(ctx, f.onBranch, "if expression", "else if", i+1)
 				return eFn.MapFn.Exec(ctx)
 			}
 		}
 
 		if elseFn != nil {
+			takeBranch(ctx, f.onBranch, "if expression", "else", -1)
 			return elseFn.Exec(ctx)
 		}
+		takeBranch(ctx, f.onBranch, "if expression", "none", -1)
 		return value.Nothing(nil), nil
 	}, aggregateTargetPaths(allFns...))
+	return f
+}
+
+// Branches returns a description of each branch that can be taken by a match
+// or if expression, in the same format as branch traces, including "none" when
+// it is possible that no branch is taken. Returns nil when the function is
+// neither a match nor an if expression.
+func Branches(fn Function) []string {
+	var branches []string
+	switch t := fn.(type) {
+	case *matchFunction:
+		catchAll := false
+		for i, c := range t.cases {
+			branches = append(branches, branchDescription("case", i))
+			if lit, ok := c.caseFn.(*Literal); ok && c.caseValue == nil {
+				if b, _ := lit.Value.(bool); b {
+					catchAll = true
+					break
+				}
+			}
+		}
+		if !catchAll {
+			branches = append(branches, "none")
+		}
+	case *ifFunction:
+		branches = append(branches, "if")
+		for i := range t.elseIfs {
+			branches = append(branches, branchDescription("else if", i+1))
+		}
+		if t.elseFn != nil {
+			branches = append(branches, "else")
+		} else {
+			branches = append(branches, "none")
+		}
+	}
+	return branches
+}
+
+// OnBranch sets a closure to be called with the description of each branch
+// taken by a match or if expression as it is executed. Returns false when the
+// function is neither a match nor an if expression.
+func OnBranch(fn Function, onBranch func(branch string)) bool {
+	switch t := fn.(type) {
+	case *matchFunction:
+		t.onBranch = onBranch
+	case *ifFunction:
+		t.onBranch = onBranch
+	default:
+		return false
+	}
+	return true
 }
 
 // NewNamedContextFunction wraps a function and ensures that when the function
@@ -443,3 +443,85 @@ func TestExpressionTargets(t *testing.T) {
 		})
 	}
 }
+
+func TestExpressionBranches(t *testing.T) {
+	tests := map[string]struct {
+		input    Function
+		value    any
+		branches []string
+		taken    []string
+	}{
+		"match without catch all": {
+			input: NewMatchFunction(
+				nil,
+				NewMatchValueCase(NewLiteralFunction("", "foo"), NewLiteralFunction("", "a")),
+				NewMatchValueCase(NewLiteralFunction("", "bar"), NewLiteralFunction("", "b")),
+			),
+			value:    "bar",
+			branches: []string{"case 0", "case 1", "none"},
+			taken:    []string{"case 1"},
+		},
+		"match with catch all": {
+			input: NewMatchFunction(
+				nil,
+				NewMatchValueCase(NewLiteralFunction("", "foo"), NewLiteralFunction("", "a")),
+				NewMatchCase(NewLiteralFunction("", true), NewLiteralFunction("", "b")),
+			),
+			value:    "baz",
+			branches: []string{"case 0", "case 1"},
+			taken:    []string{"case 1"},
+		},
+		"match with true value case": {
+			input: NewMatchFunction(
+				nil,
+				NewMatchValueCase(NewLiteralFunction("", true), NewLiteralFunction("", "a")),
+			),
+			value:    "baz",
+			branches: []string{"case 0", "none"},
+			taken:    []string{"none"},
+		},
+		"if else if": {
+			input: NewIfFunction(
+				NewLiteralFunction("", false),
+				NewLiteralFunction("", "foo"),
+				[]ElseIf{
+					{QueryFn: NewLiteralFunction("", true), MapFn: NewLiteralFunction("", "bar")},
+				},
+				nil,
+			),
+			branches: []string{"if", "else if 1", "none"},
+			taken:    []string{"else if 1"},
+		},
+		"if else": {
+			input: NewIfFunction(
+				NewLiteralFunction("", false),
+				NewLiteralFunction("", "foo"),
+				nil,
+				NewLiteralFunction("", "bar"),
+			),
+			branches: []string{"if", "else"},
+			taken:    []string{"else"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.branches, Branches(test.input))
+
+			var taken []string
+			require.True(t, OnBranch(test.input, func(branch string) {
+				taken = append(taken, branch)
+			}))
+
+			_, err := test.input.Exec(FunctionContext{
+				Maps: map[string]Function{},
+			}.WithValue(test.value))
+			require.NoError(t, err)
+			assert.Equal(t, test.taken, taken)
+		})
+	}
+
+	assert.Nil(t, Branches(NewFieldFunction("foo")))
+	assert.False(t, OnBranch(NewFieldFunction("foo"), func(string) {}))
+}
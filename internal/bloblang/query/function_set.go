@@ -74,7 +74,7 @@ func (f *FunctionSet) Init(name string, args *ParsedParams) (Function, error) {
 		return nil, badFunctionErr(name)
 	}
 	if f.disableCtors {
-		return disabledFunction(name), nil
+		return disabledFunction(details.spec, args), nil
 	}
 	return wrapCtorWithDynamicArgs(name, args, details.ctor)
 }
@@ -178,10 +178,22 @@ func FunctionDocs() []FunctionSpec {
 
 //------------------------------------------------------------------------------
 
-func disabledFunction(name string) Function {
-	return ClosureFunction("function "+name, func(ctx FunctionContext) (any, error) {
-		return nil, errors.New("this function has been disabled")
-	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) { return ctx, nil })
+// disabledFunctionFunction is a function that cannot be executed, but retains
+// its spec and arguments so that it can be statically analysed.
+type disabledFunctionFunction struct {
+	Function
+	spec FunctionSpec
+	args *ParsedParams
+}
+
+func disabledFunction(spec FunctionSpec, args *ParsedParams) Function {
+	return &disabledFunctionFunction{
+		Function: ClosureFunction("function "+spec.Name, func(ctx FunctionContext) (any, error) {
+			return nil, errors.New("this function has been disabled")
+		}, func(ctx TargetsContext) (TargetsContext, []TargetPath) { return ctx, nil }),
+		spec: spec,
+		args: args,
+	}
 }
 
 func wrapCtorWithDynamicArgs(name string, args *ParsedParams, fn FunctionCtor) (Function, error) {
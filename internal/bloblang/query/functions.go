@@ -71,10 +71,7 @@ func (f *fieldFunction) Exec(ctx FunctionContext) (any, error) {
 			return ctx, fmt.Errorf("named context %v was not found", f.namedContext)
 		}
 	}
-	if len(f.path) == 0 {
-		return target, nil
-	}
-	return gabs.Wrap(target).S(f.path...).Data(), nil
+	return getPath(target, f.path), nil
 }
 
 func (f *fieldFunction) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
@@ -188,7 +185,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root = if batch_index() > 0 { deleted() }`,
 		),
-	),
+	).Returns(value.TNumber),
 	func(ctx FunctionContext) (any, error) {
 		return int64(ctx.Index), nil
 	},
@@ -203,7 +200,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.foo = batch_size()`,
 		),
-	),
+	).Returns(value.TNumber),
 	func(ctx FunctionContext) (any, error) {
 		return int64(ctx.MsgBatch.Len()), nil
 	},
@@ -220,7 +217,7 @@ var _ = registerSimpleFunction(
 			`{"foo":"bar"}`,
 			`{"doc":"{\"foo\":\"bar\"}"}`,
 		),
-	),
+	).Returns(value.TBytes),
 	func(ctx FunctionContext) (any, error) {
 		return ctx.MsgBatch.Get(ctx.Index).AsBytes(), nil
 	},
@@ -359,7 +356,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.doc.status = if errored() { 400 } else { 200 }`,
 		),
-	),
+	).Returns(value.TBool),
 	func(ctx FunctionContext) (any, error) {
 		return ctx.MsgBatch.Get(ctx.Index).ErrorGet() != nil, nil
 	},
@@ -378,7 +375,7 @@ root.c = range(0, -this.max, -2)`,
 			`{"max":10}`,
 			`{"a":[0,1,2,3,4,5,6,7,8,9],"b":[0,2,4,6,8],"c":[0,-2,-4,-6,-8]}`,
 		),
-	).
+	).Returns(value.TArray).
 		Param(ParamInt64("start", "The start value.")).
 		Param(ParamInt64("stop", "The stop value.")).
 		Param(ParamInt64("step", "The step value.").Default(1)),
@@ -690,7 +687,7 @@ root.sixth = random_int(seed:timestamp_unix_nano(), max:20)
 			`root.first = random_int(timestamp_unix_nano())`,
 			`root.second = random_int(timestamp_unix_nano(), 5, 20)`,
 		),
-	).
+	).Returns(value.TNumber).
 		Param(ParamQuery(
 			"seed",
 			"A seed to use, if a query is provided it will only be resolved once during the lifetime of the mapping.",
@@ -761,7 +758,7 @@ var _ = registerFunction(
 		NewExampleSpec("",
 			`root.received_at = now().ts_format("Mon Jan 2 15:04:05 -0700 MST 2006", "UTC")`,
 		),
-	),
+	).Returns(value.TString),
 	func(args *ParsedParams) (Function, error) {
 		return ClosureFunction("function now", func(_ FunctionContext) (any, error) {
 			return time.Now().Format(time.RFC3339Nano), nil
@@ -776,7 +773,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix()`,
 		),
-	),
+	).Returns(value.TNumber),
 	func(_ FunctionContext) (any, error) {
 		return time.Now().Unix(), nil
 	},
@@ -789,7 +786,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix_milli()`,
 		),
-	),
+	).Returns(value.TNumber),
 	func(_ FunctionContext) (any, error) {
 		return time.Now().UnixMilli(), nil
 	},
@@ -802,7 +799,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix_micro()`,
 		),
-	),
+	).Returns(value.TNumber),
 	func(_ FunctionContext) (any, error) {
 		return time.Now().UnixMicro(), nil
 	},
@@ -815,7 +812,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix_nano()`,
 		),
-	),
+	).Returns(value.TNumber),
 	func(_ FunctionContext) (any, error) {
 		return time.Now().UnixNano(), nil
 	},
@@ -858,7 +855,7 @@ var _ = registerSimpleFunction(
 		FunctionCategoryGeneral, "uuid_v4",
 		"Generates a new RFC-4122 UUID each time it is invoked and prints a string representation.",
 		NewExampleSpec("", `root.id = uuid_v4()`),
-	),
+	).Returns(value.TString),
 	func(_ FunctionContext) (any, error) {
 		u4, err := uuid.NewV4()
 		if err != nil {
@@ -932,9 +929,15 @@ var _ = registerFunction(
 	},
 )
 
+// varFunction returns the value of a variable.
+type varFunction struct {
+	Function
+	name string
+}
+
 // NewVarFunction creates a new variable function.
 func NewVarFunction(name string) Function {
-	return ClosureFunction("variable "+name, func(ctx FunctionContext) (any, error) {
+	fn := ClosureFunction("variable "+name, func(ctx FunctionContext) (any, error) {
 		if ctx.Vars == nil {
 			return nil, errors.New("variables were undefined")
 		}
@@ -949,4 +952,5 @@ func NewVarFunction(name string) Function {
 		ctx = ctx.WithValues(paths)
 		return ctx, paths
 	})
+	return &varFunction{Function: fn, name: name}
 }
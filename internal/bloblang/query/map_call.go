@@ -0,0 +1,77 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NewMapCall creates a function that executes a parameterised map with a given
+// name, where the arguments are bound to variables within the map. When a
+// target is provided the map is executed with the result of the target as its
+// context (as a method), otherwise the current context is used (as a
+// function).
+//
+// The map is resolved from the function context at execution time, which
+// allows maps to call themselves recursively.
+func NewMapCall(name string, target Function, args *ParsedParams) Function {
+	return ClosureFunction("map "+name, func(ctx FunctionContext) (any, error) {
+		if ctx.Maps == nil {
+			return nil, errors.New("no maps were found")
+		}
+		m, ok := ctx.Maps[name]
+		if !ok {
+			return nil, fmt.Errorf("map %v was not found", name)
+		}
+
+		// Arguments are resolved within the context of the caller.
+		resolved, err := args.ResolveDynamic(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if target != nil {
+			res, err := target.Exec(ctx)
+			if err != nil {
+				return nil, err
+			}
+			ctx = ctx.WithValue(res)
+		}
+
+		// The arguments of the map are the only variables visible within it,
+		// variables declared by the caller are not inherited.
+		ctx.Vars = make(map[string]any, len(resolved.values))
+		for i, def := range resolved.source.Definitions {
+			if i < len(resolved.values) {
+				ctx.Vars[def.Name] = resolved.values[i]
+			}
+		}
+		return m.Exec(ctx)
+	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
+		var targets []TargetPath
+		for _, fn := range args.dynamic() {
+			_, argTargets := fn.QueryTargets(ctx)
+			targets = append(targets, argTargets...)
+		}
+
+		mapCtx := ctx
+		if target != nil {
+			var targetPaths []TargetPath
+			mapCtx, targetPaths = target.QueryTargets(ctx)
+			targets = append(targets, targetPaths...)
+			mapCtx = mapCtx.WithValues(targetPaths).WithValuesAsContext()
+		}
+
+		mapFn, ok := ctx.Maps[name]
+		if !ok {
+			return mapCtx, targets
+		}
+
+		var recursive bool
+		if mapCtx, recursive = mapCtx.WithMapCall(name); recursive {
+			return mapCtx, targets
+		}
+
+		returnCtx, mapTargets := mapFn.QueryTargets(mapCtx)
+		return returnCtx, append(targets, mapTargets...)
+	})
+}
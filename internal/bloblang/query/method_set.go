@@ -9,13 +9,18 @@ import (
 type methodDetails struct {
 	ctor MethodCtor
 	spec MethodSpec
+
+	// Whether instantiations of the method with a literal target and static
+	// arguments can be folded into a literal of the result.
+	foldable bool
 }
 
 // MethodSet contains an explicit set of methods to be available in a Bloblang
 // query.
 type MethodSet struct {
-	disableCtors bool
-	methods      map[string]methodDetails
+	disableCtors   bool
+	disableFolding bool
+	methods        map[string]methodDetails
 }
 
 // NewMethodSet creates a method set without any methods in it.
@@ -68,9 +73,13 @@ func (m *MethodSet) Init(name string, target Function, args *ParsedParams) (Func
 		return nil, badMethodErr(name)
 	}
 	if m.disableCtors {
-		return disabledMethod(name), nil
+		return disabledMethod(details.spec, target, args), nil
+	}
+	fn, err := wrapMethodCtorWithDynamicArgs(name, target, args, details.ctor)
+	if err != nil || !details.foldable || m.disableFolding {
+		return fn, err
 	}
-	return wrapMethodCtorWithDynamicArgs(name, target, args, details.ctor)
+	return foldMethod(fn, target, args), nil
 }
 
 // Without creates a clone of the method set that can be mutated in isolation,
@@ -87,7 +96,7 @@ func (m *MethodSet) Without(methods ...string) *MethodSet {
 			details[k] = v
 		}
 	}
-	return &MethodSet{disableCtors: m.disableCtors, methods: details}
+	return &MethodSet{disableCtors: m.disableCtors, disableFolding: m.disableFolding, methods: details}
 }
 
 // OnlyPure creates a clone of the methods set that can be mutated in isolation,
@@ -115,6 +124,18 @@ func (m *MethodSet) Deactivated() *MethodSet {
 	return &newSet
 }
 
+// WithoutFolding returns a version of the method set where methods with a
+// literal target and static arguments are no longer folded into literals of
+// their results.
+//
+// The underlying register of methods is shared with the target set in the same
+// way as Deactivated.
+func (m *MethodSet) WithoutFolding() *MethodSet {
+	newSet := *m
+	newSet.disableFolding = true
+	return &newSet
+}
+
 //------------------------------------------------------------------------------
 
 // AllMethods is a set containing every single method declared by this package,
@@ -127,6 +148,11 @@ func registerMethod(spec MethodSpec, ctor MethodCtor) struct{} {
 	}); err != nil {
 		panic(err)
 	}
+	if _, isContextual := contextualMethods[spec.Name]; !isContextual && !spec.Impure {
+		details := AllMethods.methods[spec.Name]
+		details.foldable = true
+		AllMethods.methods[spec.Name] = details
+	}
 	return struct{}{}
 }
 
@@ -151,10 +177,24 @@ func MethodDocs() []MethodSpec {
 
 //------------------------------------------------------------------------------
 
-func disabledMethod(name string) Function {
-	return ClosureFunction("method "+name, func(ctx FunctionContext) (any, error) {
-		return nil, errors.New("this method has been disabled")
-	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) { return ctx, nil })
+// disabledMethodFunction is a method that cannot be executed, but retains its
+// spec, target and arguments so that it can be statically analysed.
+type disabledMethodFunction struct {
+	Function
+	spec   MethodSpec
+	target Function
+	args   *ParsedParams
+}
+
+func disabledMethod(spec MethodSpec, target Function, args *ParsedParams) Function {
+	return &disabledMethodFunction{
+		Function: ClosureFunction("method "+spec.Name, func(ctx FunctionContext) (any, error) {
+			return nil, errors.New("this method has been disabled")
+		}, func(ctx TargetsContext) (TargetsContext, []TargetPath) { return ctx, nil }),
+		spec:   spec,
+		target: target,
+		args:   args,
+	}
 }
 
 func wrapMethodCtorWithDynamicArgs(name string, target Function, args *ParsedParams, fn MethodCtor) (Function, error) {
@@ -106,7 +106,7 @@ var _ = registerSimpleMethod(
 //------------------------------------------------------------------------------
 
 var _ = registerMethod(
-	NewMethodSpec("bool", "").InCategory(
+	NewMethodSpec("bool", "").Returns(value.TBool).InCategory(
 		MethodCategoryCoercion,
 		"Attempt to parse a value into a boolean. An optional argument can be provided, in which case if the value cannot be parsed the argument will be returned instead. If the value is a number then any non-zero value will resolve to `true`, if the value is a string then any of the following values are considered valid: `1, t, T, TRUE, true, True, 0, f, F, FALSE`.",
 		NewExampleSpec("",
@@ -284,7 +284,7 @@ func (g *getMethod) Exec(ctx FunctionContext) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	return gabs.Wrap(v).S(g.path...).Data(), nil
+	return getPath(v, g.path), nil
 }
 
 func (g *getMethod) QueryTargets(ctx TargetsContext) (TargetsContext, []TargetPath) {
@@ -668,7 +668,7 @@ var _ = registerSimpleMethod(
 var _ = registerMethod(
 	NewMethodSpec(
 		"number", "",
-	).InCategory(
+	).Returns(value.TNumber).InCategory(
 		MethodCategoryCoercion,
 		"Attempt to parse a value into a number. An optional argument can be provided, in which case if the value cannot be parsed into a number the argument will be returned instead.",
 		NewExampleSpec("",
@@ -732,7 +732,7 @@ func orMethod(fn Function, args *ParsedParams) (Function, error) {
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"type", "",
-	).InCategory(
+	).Returns(value.TString).InCategory(
 		MethodCategoryCoercion,
 		"Returns the type of a value as a string, providing one of the following values: `string`, `bytes`, `number`, `bool`, `timestamp`, `array`, `object` or `null`.",
 		NewExampleSpec("",
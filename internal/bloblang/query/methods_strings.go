@@ -40,7 +40,7 @@ import (
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"bytes", "",
-	).InCategory(
+	).Returns(value.TBytes).InCategory(
 		MethodCategoryCoercion,
 		"Marshal a value into a byte array. If the value is already a byte array it is unchanged.",
 		NewExampleSpec("",
@@ -61,7 +61,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"capitalize", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Takes a string value and returns a copy with all Unicode letters that begin words mapped to their Unicode title case.",
 		NewExampleSpec("",
@@ -493,7 +493,7 @@ root.decrypted = this.value.decode("hex").decrypt_aes("ctr", $key, $vector).stri
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"escape_html", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes, value.TTimestamp).Returns(value.TString).InCategory(
 		MethodCategoryStrings,
 		"Escapes a string so that special characters like `<` to become `&lt;`. It escapes only five such characters: `<`, `>`, `&`, `'` and `\"` so that it can be safely placed within an HTML entity.",
 		NewExampleSpec("",
@@ -514,7 +514,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"index_of", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TNumber).InCategory(
 		MethodCategoryStrings,
 		"Returns the starting index of the argument substring in a string target, or `-1` if the target doesn't contain the argument.",
 		NewExampleSpec("",
@@ -550,7 +550,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"unescape_html", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes, value.TTimestamp).Returns(value.TString).InCategory(
 		MethodCategoryStrings,
 		"Unescapes a string so that entities like `&lt;` become `<`. It unescapes a larger range of entities than `escape_html` escapes. For example, `&aacute;` unescapes to `á`, as does `&#225;` and `&xE1;`.",
 		NewExampleSpec("",
@@ -571,7 +571,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"escape_url_query", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes, value.TTimestamp).Returns(value.TString).InCategory(
 		MethodCategoryStrings,
 		"Escapes a string so that it can be safely placed within a URL query.",
 		NewExampleSpec("",
@@ -689,7 +689,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"has_prefix", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TBool).InCategory(
 		MethodCategoryStrings,
 		"Checks whether a string has a prefix argument and returns a bool.",
 		NewExampleSpec("",
@@ -722,7 +722,7 @@ root.t2 = this.v2.has_prefix("foo")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"has_suffix", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TBool).InCategory(
 		MethodCategoryStrings,
 		"Checks whether a string has a suffix argument and returns a bool.",
 		NewExampleSpec("",
@@ -901,7 +901,7 @@ root.h2 = this.value.hash(algorithm: "crc32", polynomial: "Koopman").encode("hex
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"join", "",
-	).InCategory(
+	).OnTypes(value.TArray).Returns(value.TString).InCategory(
 		MethodCategoryObjectAndArray,
 		"Join an array of strings with an optional delimiter into a single string.",
 		NewExampleSpec("",
@@ -950,7 +950,7 @@ root.joined_numbers = this.numbers.map_each(this.string()).join(",")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"uppercase", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Convert a string value into uppercase.",
 		NewExampleSpec("",
@@ -978,7 +978,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"lowercase", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Convert a string value into lowercase.",
 		NewExampleSpec("",
@@ -1375,7 +1375,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"reverse", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Returns the target string in reverse order.",
 		NewExampleSpec("",
@@ -1416,7 +1416,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"quote", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes, value.TTimestamp).Returns(value.TString).InCategory(
 		MethodCategoryStrings,
 		"Quotes a target string using escape sequences (`\\t`, `\\n`, `\\xFF`, `\\u0100`) for control characters and non-printable characters.",
 		NewExampleSpec("",
@@ -1465,7 +1465,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"replace_all", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Replaces all occurrences of the first argument in a target string with the second argument.",
 		NewExampleSpec("",
@@ -1799,7 +1799,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"re_match", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TBool).InCategory(
 		MethodCategoryRegexp,
 		"Checks whether a regular expression matches against any part of a string and returns a boolean.",
 		NewExampleSpec("",
@@ -1893,7 +1893,7 @@ func reReplaceAllImpl(args *ParsedParams) (simpleMethod, error) {
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"string", "",
-	).InCategory(
+	).Returns(value.TString).InCategory(
 		MethodCategoryCoercion,
 		"Marshal a value into a string. If the value is already a string it is unchanged.",
 		NewExampleSpec("",
@@ -1967,7 +1967,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"trim", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Remove all leading and trailing characters from a string that are contained within an argument cutset. If no arguments are provided then whitespace is removed.",
 		NewExampleSpec("",
@@ -2003,7 +2003,7 @@ root.description = this.description.trim()`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"trim_prefix", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Remove the provided leading prefix substring from a string. If the string does not have the prefix substring, it is returned unchanged.",
 		NewExampleSpec("",
@@ -2035,7 +2035,7 @@ root.description = this.description.trim_prefix("foobar_")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"trim_suffix", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Remove the provided trailing suffix substring from a string. If the string does not have the suffix substring, it is returned unchanged.",
 		NewExampleSpec("",
@@ -2067,7 +2067,7 @@ root.description = this.description.trim_suffix("_foobar")`,
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"repeat", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes).Returns(value.TString, value.TBytes).InCategory(
 		MethodCategoryStrings,
 		"Repeats the input string `count` times and returns the concatenated result.",
 		NewExampleSpec("",
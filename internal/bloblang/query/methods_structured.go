@@ -178,7 +178,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"contains", "",
-	).InCategory(
+	).Returns(value.TBool).InCategory(
 		MethodCategoryObjectAndArray,
 		"Checks whether an array contains an element matching the argument, or an object contains a value matching the argument, and returns a boolean result. Numerical comparisons are made irrespective of the representation type (float versus integer).",
 		NewExampleSpec("",
@@ -809,7 +809,7 @@ var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"keys",
 		"Returns the keys of an object as an array.",
-	).InCategory(
+	).OnTypes(value.TObject).Returns(value.TArray).InCategory(
 		MethodCategoryObjectAndArray, "",
 		NewExampleSpec("",
 			`root.foo_keys = this.foo.keys()`,
@@ -869,7 +869,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"length", "",
-	).InCategory(
+	).OnTypes(value.TString, value.TBytes, value.TArray, value.TObject).Returns(value.TNumber).InCategory(
 		MethodCategoryStrings, "Returns the length of a string.",
 		NewExampleSpec("",
 			`root.foo_len = this.foo.length()`,
@@ -1519,7 +1519,7 @@ func sliceMethod(args *ParsedParams) (simpleMethod, error) {
 var _ = registerMethod(
 	NewMethodSpec(
 		"sum", "",
-	).InCategory(
+	).OnTypes(value.TNumber, value.TArray).Returns(value.TNumber).InCategory(
 		MethodCategoryObjectAndArray,
 		"Sum the numerical values of an array.",
 		NewExampleSpec("",
@@ -1666,7 +1666,7 @@ func uniqueMethod(args *ParsedParams) (simpleMethod, error) {
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"values", "",
-	).InCategory(
+	).OnTypes(value.TObject).Returns(value.TArray).InCategory(
 		MethodCategoryObjectAndArray,
 		"Returns the values of an object as an array. The order of the resulting array will be random.",
 		NewExampleSpec("",
@@ -0,0 +1,99 @@
+package query
+
+import (
+	"strconv"
+
+	"github.com/Jeffail/gabs/v2"
+
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// Methods registered by this package that are not foldable into literals even
+// when their target and arguments are static, as their results depend on the
+// context of the execution.
+var contextualMethods = map[string]struct{}{
+	"apply":    {},
+	"from":     {},
+	"from_all": {},
+}
+
+// foldMethod attempts to resolve a method with a literal target and static
+// arguments into a literal of its result. If the method cannot be folded, or
+// its execution fails, the method is returned unchanged so that errors are
+// reported at execution time as they would be otherwise.
+func foldMethod(fn, target Function, args *ParsedParams) Function {
+	if _, isLit := target.(*Literal); !isLit {
+		return fn
+	}
+	if len(args.dynamic()) > 0 {
+		return fn
+	}
+	for _, v := range args.Raw() {
+		// Query arguments are executed with a context provided by the method,
+		// which might include values from the wider execution.
+		if _, isFn := v.(Function); isFn {
+			return fn
+		}
+	}
+
+	res, err := fn.Exec(FunctionContext{})
+	if err != nil {
+		return fn
+	}
+	switch res.(type) {
+	case value.Nothing, value.Delete:
+		return fn
+	}
+	return NewLiteralFunction(fn.Annotation(), res)
+}
+
+// getPath returns the value found at a path within a structured value, with
+// the same semantics as a gabs search. Paths that only traverse objects are
+// walked without allocating.
+func getPath(v any, path []string) any {
+	for i, seg := range path {
+		switch t := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = t[seg]; !ok {
+				return nil
+			}
+		case []any:
+			if seg == "*" {
+				return gabs.Wrap(v).S(path[i:]...).Data()
+			}
+			index, err := strconv.Atoi(seg)
+			if err != nil || index < 0 || index >= len(t) {
+				return nil
+			}
+			v = t[index]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// MayReturnNothing returns false when a function is known to never return
+// value.Nothing, which means assignments of its result are never skipped.
+func MayReturnNothing(fn Function) bool {
+	switch t := fn.(type) {
+	case *Literal:
+		_, isNothing := t.Value.(value.Nothing)
+		return isNothing
+	case *fieldFunction, *varFunction, *mapLiteral, *arrayLiteral:
+		return false
+	case *getMethod:
+		return MayReturnNothing(t.fn)
+	case *arithmeticFunction:
+		return t.op == ArithmeticPipe
+	}
+	return true
+}
+
+// IsContextReference returns true if the function returns the context of the
+// query (`this`), or a field of it, without modification.
+func IsContextReference(fn Function) bool {
+	f, ok := fn.(*fieldFunction)
+	return ok && !f.fromRoot && f.namedContext == ""
+}
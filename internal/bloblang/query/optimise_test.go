@@ -0,0 +1,101 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPathMatchesGabs(t *testing.T) {
+	doc := map[string]any{
+		"a": map[string]any{"b": "c", "nil": nil},
+		"arr": []any{
+			map[string]any{"x": 1},
+			map[string]any{"x": 2, "y": []any{"z"}},
+		},
+		"str": "foo",
+	}
+
+	for _, path := range [][]string{
+		nil,
+		{"a"},
+		{"a", "b"},
+		{"a", "nil"},
+		{"a", "nil", "foo"},
+		{"a", "missing"},
+		{"a", "b", "c"},
+		{"arr", "0", "x"},
+		{"arr", "1", "y", "0"},
+		{"arr", "2"},
+		{"arr", "-1"},
+		{"arr", "foo"},
+		{"arr", "*", "x"},
+		{"arr", "*"},
+		{"str", "foo"},
+	} {
+		assert.Equal(t, gabs.Wrap(doc).S(path...).Data(), getPath(doc, path), "%v", path)
+	}
+}
+
+func TestMethodFolding(t *testing.T) {
+	tests := map[string]struct {
+		target Function
+		method string
+		args   []any
+		folded bool
+	}{
+		"literal target": {
+			target: NewLiteralFunction("", "foo"),
+			method: "uppercase",
+			folded: true,
+		},
+		"literal target with args": {
+			target: NewLiteralFunction("", "foo,bar"),
+			method: "split",
+			args:   []any{","},
+			folded: true,
+		},
+		"dynamic target": {
+			target: NewFieldFunction("foo"),
+			method: "uppercase",
+		},
+		"dynamic args": {
+			target: NewLiteralFunction("", "foo,bar"),
+			method: "split",
+			args:   []any{NewFieldFunction("delim")},
+		},
+		"failed execution": {
+			target: NewLiteralFunction("", "foo"),
+			method: "number",
+		},
+		"contextual method": {
+			target: NewLiteralFunction("", "foo"),
+			method: "from_all",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			fn, err := InitMethodHelper(test.method, test.target, test.args...)
+			require.NoError(t, err)
+
+			_, isLit := fn.(*Literal)
+			assert.Equal(t, test.folded, isLit)
+
+			params, err := AllMethods.Params(test.method)
+			require.NoError(t, err)
+
+			parsedArgs, err := params.PopulateNameless(test.args...)
+			require.NoError(t, err)
+
+			fn, err = AllMethods.WithoutFolding().Init(test.method, test.target, parsedArgs)
+			require.NoError(t, err)
+
+			_, isLit = fn.(*Literal)
+			assert.False(t, isLit)
+		})
+	}
+}
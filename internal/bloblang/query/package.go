@@ -60,6 +60,51 @@ type FunctionContext struct {
 
 	// Used to track how many maps we've entered.
 	stackCount int
+
+	// Optionally receives the branches taken by expressions, which is only
+	// set when tracing an execution.
+	branchTracer func(BranchTrace)
+}
+
+// BranchTrace describes which branch of a match or if expression was taken
+// during a traced execution.
+type BranchTrace struct {
+	// The expression that the branch belongs to, e.g. "match expression".
+	Expression string
+
+	// A description of the branch that was taken, e.g. "case 1", or "none"
+	// when no branch was taken.
+	Branch string
+}
+
+// WithBranchTracer returns a FunctionContext where the branches taken by
+// match and if expressions are reported to the provided closure.
+func (ctx FunctionContext) WithBranchTracer(fn func(BranchTrace)) FunctionContext {
+	ctx.branchTracer = fn
+	return ctx
+}
+
+// takeBranch reports a branch taken by an expression to the branch hook of the
+// expression, when set, and the branch tracer, when tracing is enabled, where a
+// non-negative index is appended to the branch description.
+func takeBranch(ctx FunctionContext, onBranch func(string), expression, branch string, index int) {
+	if onBranch == nil && ctx.branchTracer == nil {
+		return
+	}
+	branch = branchDescription(branch, index)
+	if onBranch != nil {
+		onBranch(branch)
+	}
+	if ctx.branchTracer != nil {
+		ctx.branchTracer(BranchTrace{Expression: expression, Branch: branch})
+	}
+}
+
+func branchDescription(branch string, index int) string {
+	if index >= 0 {
+		return fmt.Sprintf("%v %v", branch, index)
+	}
+	return branch
 }
 
 type namedContextValue struct {
@@ -74,6 +74,12 @@ type TargetsContext struct {
 	mainContext   []TargetPath
 	prevContext   *prevContextPath
 	namedContext  *namedContextPath
+	mapCalls      *mapCallPath
+}
+
+type mapCallPath struct {
+	name string
+	next *mapCallPath
 }
 
 type prevContextPath struct {
@@ -157,3 +163,16 @@ func (ctx TargetsContext) PopContext() TargetsContext {
 	}
 	return ctx
 }
+
+// WithMapCall returns a targets context that records a call into a map with a
+// given name, and a boolean indicating whether that map is already being
+// called, in which case the map is recursive and should not be walked again.
+func (ctx TargetsContext) WithMapCall(name string) (TargetsContext, bool) { //nolint: gocritic // Ignore unnamedResult false positive
+	for current := ctx.mapCalls; current != nil; current = current.next {
+		if current.name == name {
+			return ctx, true
+		}
+	}
+	ctx.mapCalls = &mapCallPath{name: name, next: ctx.mapCalls}
+	return ctx, false
+}
@@ -0,0 +1,792 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// StaticType describes what can be determined about the value that a query
+// resolves to without executing it. The zero value describes a value of an
+// unknown type.
+type StaticType struct {
+	types []value.Type
+
+	// An optional JSON schema describing the structure of the value, along
+	// with the root of the schema it belongs to in order to resolve refs.
+	schema map[string]any
+	root   map[string]any
+}
+
+// NewStaticType creates a static type describing a value that could be any of
+// the provided types. If no types are provided then the type is unknown.
+func NewStaticType(types ...value.Type) StaticType {
+	var s StaticType
+	for _, t := range types {
+		s.types = addType(s.types, t)
+	}
+	return s
+}
+
+// StaticTypeFromJSONSchema creates a static type from a JSON schema, allowing
+// the types of fields accessed from the value to be inferred.
+func StaticTypeFromJSONSchema(schema map[string]any) StaticType {
+	return staticTypeFromSchema(schema, schema)
+}
+
+// IsKnown returns true if the types that the value could be are known.
+func (s StaticType) IsKnown() bool {
+	return len(s.types) > 0
+}
+
+// Types returns the types that the value could be, or nil if unknown.
+func (s StaticType) Types() []value.Type {
+	return s.types
+}
+
+// String returns a human readable description of the type.
+func (s StaticType) String() string {
+	if !s.IsKnown() {
+		return string(value.TUnknown)
+	}
+	strs := make([]string, len(s.types))
+	for i, t := range s.types {
+		strs[i] = string(t)
+	}
+	return strings.Join(strs, " or ")
+}
+
+func normaliseType(t value.Type) value.Type {
+	switch t {
+	case value.TInt, value.TFloat:
+		return value.TNumber
+	}
+	return t
+}
+
+func addType(types []value.Type, t value.Type) []value.Type {
+	t = normaliseType(t)
+	for _, e := range types {
+		if e == t {
+			return types
+		}
+	}
+	return append(types, t)
+}
+
+func (s StaticType) has(t value.Type) bool {
+	t = normaliseType(t)
+	for _, e := range s.types {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// canBeAnyOf returns true if the type is unknown or could be any of the
+// provided types.
+func (s StaticType) canBeAnyOf(types ...value.Type) bool {
+	if !s.IsKnown() {
+		return true
+	}
+	for _, t := range types {
+		if s.has(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// union returns a type that could be either of two types, which is unknown if
+// either type is unknown.
+func (s StaticType) union(o StaticType) StaticType {
+	if !s.IsKnown() || !o.IsKnown() {
+		return StaticType{}
+	}
+	res := StaticType{types: append([]value.Type{}, s.types...)}
+	for _, t := range o.types {
+		res.types = addType(res.types, t)
+	}
+	if s.schema != nil && o.schema == nil {
+		res.schema, res.root = s.schema, s.root
+	} else if o.schema != nil && s.schema == nil {
+		res.schema, res.root = o.schema, o.root
+	}
+	return res
+}
+
+// without returns the type with the provided types removed, which is unknown
+// if no types remain.
+func (s StaticType) without(types ...value.Type) StaticType {
+	res := s
+	res.types = nil
+	for _, t := range s.types {
+		remove := false
+		for _, r := range types {
+			if t == normaliseType(r) {
+				remove = true
+			}
+		}
+		if !remove {
+			res.types = append(res.types, t)
+		}
+	}
+	if len(res.types) == 0 {
+		return StaticType{}
+	}
+	return res
+}
+
+// field returns the type of the value obtained by walking a path of the value.
+func (s StaticType) field(path ...string) StaticType {
+	for _, p := range path {
+		if s = s.child(p); !s.IsKnown() {
+			return s
+		}
+	}
+	return s
+}
+
+func (s StaticType) child(key string) StaticType {
+	if !s.IsKnown() {
+		return StaticType{}
+	}
+
+	var res StaticType
+	for _, t := range s.types {
+		var next StaticType
+		switch t {
+		case value.TObject:
+			if next = s.objectChild(key); !next.IsKnown() {
+				return StaticType{}
+			}
+		case value.TArray:
+			if _, err := strconv.Atoi(key); err != nil {
+				next = NewStaticType(value.TNull)
+			} else if next = s.arrayElement(); !next.IsKnown() {
+				return StaticType{}
+			} else {
+				// The index may be out of bounds.
+				next = next.union(NewStaticType(value.TNull))
+			}
+		default:
+			// Walking a path of a non-structured value results in null.
+			next = NewStaticType(value.TNull)
+		}
+		if !res.IsKnown() {
+			res = next
+		} else {
+			res = res.union(next)
+		}
+	}
+	return res
+}
+
+func (s StaticType) objectChild(key string) StaticType {
+	if s.schema == nil {
+		return StaticType{}
+	}
+	if props, ok := s.schema["properties"].(map[string]any); ok {
+		if prop, ok := props[key].(map[string]any); ok {
+			t := staticTypeFromSchema(prop, s.root)
+			if !isRequired(s.schema, key) {
+				t = t.union(NewStaticType(value.TNull))
+			}
+			return t
+		}
+	}
+	switch t := s.schema["additionalProperties"].(type) {
+	case bool:
+		if !t {
+			return NewStaticType(value.TNull)
+		}
+	case map[string]any:
+		return staticTypeFromSchema(t, s.root).union(NewStaticType(value.TNull))
+	}
+	return StaticType{}
+}
+
+func (s StaticType) arrayElement() StaticType {
+	if s.schema == nil {
+		return StaticType{}
+	}
+	if items, ok := s.schema["items"].(map[string]any); ok {
+		return staticTypeFromSchema(items, s.root)
+	}
+	return StaticType{}
+}
+
+func isRequired(schema map[string]any, key string) bool {
+	required, _ := schema["required"].([]any)
+	for _, r := range required {
+		if r == key {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveSchemaRef(schema, root map[string]any) map[string]any {
+	for i := 0; i < 10; i++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		path, ok := strings.CutPrefix(ref, "#/")
+		if !ok {
+			return nil
+		}
+		var target any = root
+		for _, seg := range strings.Split(path, "/") {
+			obj, isObj := target.(map[string]any)
+			if !isObj {
+				return nil
+			}
+			target = obj[seg]
+		}
+		if schema, ok = target.(map[string]any); !ok {
+			return nil
+		}
+	}
+	return nil
+}
+
+func staticTypeFromSchema(schema, root map[string]any) StaticType {
+	if schema = resolveSchemaRef(schema, root); schema == nil {
+		return StaticType{}
+	}
+
+	for _, k := range []string{"anyOf", "oneOf"} {
+		if options, ok := schema[k].([]any); ok {
+			var res StaticType
+			for i, o := range options {
+				oSchema, ok := o.(map[string]any)
+				if !ok {
+					return StaticType{}
+				}
+				oType := staticTypeFromSchema(oSchema, root)
+				if i == 0 {
+					res = oType
+				} else {
+					res = res.union(oType)
+				}
+			}
+			return res
+		}
+	}
+
+	var typeNames []any
+	switch t := schema["type"].(type) {
+	case string:
+		typeNames = []any{t}
+	case []any:
+		typeNames = t
+	default:
+		if _, hasProps := schema["properties"]; hasProps {
+			typeNames = []any{"object"}
+		}
+	}
+
+	res := StaticType{schema: schema, root: root}
+	for _, n := range typeNames {
+		switch n {
+		case "string":
+			res.types = addType(res.types, value.TString)
+		case "integer", "number":
+			res.types = addType(res.types, value.TNumber)
+		case "boolean":
+			res.types = addType(res.types, value.TBool)
+		case "object":
+			res.types = addType(res.types, value.TObject)
+		case "array":
+			res.types = addType(res.types, value.TArray)
+		case "null":
+			res.types = addType(res.types, value.TNull)
+		default:
+			return StaticType{}
+		}
+	}
+	if !res.IsKnown() {
+		return StaticType{}
+	}
+	return res
+}
+
+//------------------------------------------------------------------------------
+
+// TypeIssue describes a problem found during static type checking.
+type TypeIssue struct {
+	// Unreachable indicates that the issue is a branch that can never be
+	// taken, as opposed to a type error that will always occur.
+	Unreachable bool
+
+	Message string
+}
+
+// TypeContext carries what is known about the types of values available to
+// a query during static type checking.
+type TypeContext struct {
+	value  StaticType
+	named  map[string]StaticType
+	vars   map[string]StaticType
+	issues *[]TypeIssue
+}
+
+// NewTypeContext creates a type context where the context value is of a given
+// type.
+func NewTypeContext(v StaticType) TypeContext {
+	return TypeContext{
+		value:  v,
+		named:  map[string]StaticType{},
+		vars:   map[string]StaticType{},
+		issues: &[]TypeIssue{},
+	}
+}
+
+// WithValue returns a type context where the context value is of a new type.
+func (ctx TypeContext) WithValue(v StaticType) TypeContext {
+	ctx.value = v
+	return ctx
+}
+
+func (ctx TypeContext) withNamed(name string, v StaticType) TypeContext {
+	named := make(map[string]StaticType, len(ctx.named)+1)
+	for k, v := range ctx.named {
+		named[k] = v
+	}
+	named[name] = v
+	ctx.named = named
+	return ctx
+}
+
+// SetVar sets the type of a variable.
+func (ctx TypeContext) SetVar(name string, v StaticType) {
+	ctx.vars[name] = v
+}
+
+// CloneVars returns a type context where variables can be modified without
+// impacting the original context, this is used for checking conditional
+// branches.
+func (ctx TypeContext) CloneVars() TypeContext {
+	vars := make(map[string]StaticType, len(ctx.vars))
+	for k, v := range ctx.vars {
+		vars[k] = v
+	}
+	ctx.vars = vars
+	return ctx
+}
+
+// MergeVars updates the variables of the context with those of a number of
+// branches, where a variable may have been set by any one of them. When
+// exhaustive is false it's also possible that none of the branches were taken.
+func (ctx TypeContext) MergeVars(exhaustive bool, branches ...TypeContext) {
+	names := map[string]struct{}{}
+	for _, b := range branches {
+		for k := range b.vars {
+			names[k] = struct{}{}
+		}
+	}
+	for k := range names {
+		var merged StaticType
+		existing, exists := ctx.vars[k]
+		for i, b := range branches {
+			bt, bExists := b.vars[k]
+			if !bExists {
+				merged = StaticType{}
+				break
+			}
+			if i == 0 {
+				merged = bt
+			} else {
+				merged = merged.union(bt)
+			}
+		}
+		if !exhaustive {
+			if exists {
+				merged = merged.union(existing)
+			} else {
+				merged = StaticType{}
+			}
+		}
+		ctx.vars[k] = merged
+	}
+}
+
+// WithNewIssues returns a type context where issues are reported to a new list
+// separate from the original context.
+func (ctx TypeContext) WithNewIssues() TypeContext {
+	ctx.issues = &[]TypeIssue{}
+	return ctx
+}
+
+// Issues returns all issues reported within the context.
+func (ctx TypeContext) Issues() []TypeIssue {
+	return *ctx.issues
+}
+
+// Errorf reports a type error that will always occur.
+func (ctx TypeContext) Errorf(format string, args ...any) {
+	*ctx.issues = append(*ctx.issues, TypeIssue{Message: fmt.Sprintf(format, args...)})
+}
+
+// Unreachablef reports a branch that can never be taken.
+func (ctx TypeContext) Unreachablef(format string, args ...any) {
+	*ctx.issues = append(*ctx.issues, TypeIssue{Unreachable: true, Message: fmt.Sprintf(format, args...)})
+}
+
+//------------------------------------------------------------------------------
+
+func literalType(v any) StaticType {
+	switch v.(type) {
+	case value.Delete, value.Nothing:
+		return StaticType{}
+	}
+	return NewStaticType(value.ITypeOf(v))
+}
+
+// CheckCondition reports issues with a query used as the condition of an if
+// statement, and returns whether the condition is known to always or never
+// pass.
+func CheckCondition(ctx TypeContext, fn Function) (alwaysTrue, alwaysFalse bool) {
+	if lit, ok := fn.(*Literal); ok {
+		if b, isBool := lit.Value.(bool); isBool {
+			return b, !b
+		}
+	}
+	t := InferType(ctx, fn)
+	if !t.canBeAnyOf(value.TBool, value.TNull) {
+		ctx.Errorf("%v resolves to a non-boolean value of type %v", fn.Annotation(), t)
+	}
+	return false, false
+}
+
+// InferType walks a query function and attempts to infer the type of value
+// that it resolves to, reporting any type errors that will definitely occur
+// or branches that can never be taken to the type context.
+func InferType(ctx TypeContext, fn Function) StaticType {
+	switch t := fn.(type) {
+	case *Literal:
+		return literalType(t.Value)
+
+	case *mapLiteral:
+		for _, kv := range t.keyValues {
+			for _, v := range kv {
+				if vFn, ok := v.(Function); ok {
+					_ = InferType(ctx, vFn)
+				}
+			}
+		}
+		return NewStaticType(value.TObject)
+
+	case *arrayLiteral:
+		for _, v := range t.values {
+			if vFn, ok := v.(Function); ok {
+				_ = InferType(ctx, vFn)
+			}
+		}
+		return NewStaticType(value.TArray)
+
+	case *fieldFunction:
+		if t.fromRoot {
+			return StaticType{}
+		}
+		base := ctx.value
+		if t.namedContext != "" {
+			var exists bool
+			if base, exists = ctx.named[t.namedContext]; !exists {
+				return StaticType{}
+			}
+		}
+		return base.field(t.path...)
+
+	case *getMethod:
+		return InferType(ctx, t.fn).field(t.path...)
+
+	case *varFunction:
+		return ctx.vars[t.name]
+
+	case *NamedContextFunction:
+		return InferType(ctx.withNamed(t.name, ctx.value), t.fn)
+
+	case *notMethod:
+		target := InferType(ctx, t.fn)
+		if !target.canBeAnyOf(value.TBool) {
+			ctx.Errorf("%v: expected bool value, got %v", t.fn.Annotation(), target)
+		}
+		return NewStaticType(value.TBool)
+
+	case *filterMethod:
+		return inferIterable(ctx, t.target)
+
+	case *mapEachMethod:
+		return inferIterable(ctx, t.target)
+
+	case *arithmeticFunction:
+		return inferArithmetic(ctx, t)
+
+	case *matchFunction:
+		return inferMatch(ctx, t)
+
+	case *ifFunction:
+		return inferIf(ctx, t)
+
+	case *disabledMethodFunction:
+		return inferMethod(ctx, t)
+
+	case *disabledFunctionFunction:
+		checkParams(ctx, "function "+t.spec.Name, t.spec.Params, t.args)
+		return NewStaticType(t.spec.ReturnTypes...)
+	}
+	return StaticType{}
+}
+
+func inferIterable(ctx TypeContext, target Function) StaticType {
+	t := InferType(ctx, target)
+	if !t.IsKnown() {
+		return t
+	}
+	if !t.canBeAnyOf(value.TArray, value.TObject) {
+		ctx.Errorf("%v: expected array or object value, got %v", target.Annotation(), t)
+		return StaticType{}
+	}
+	var res []value.Type
+	for _, rt := range []value.Type{value.TArray, value.TObject} {
+		if t.has(rt) {
+			res = append(res, rt)
+		}
+	}
+	return NewStaticType(res...)
+}
+
+func inferArithmetic(ctx TypeContext, a *arithmeticFunction) StaticType {
+	lhs, rhs := InferType(ctx, a.lhs), InferType(ctx, a.rhs)
+
+	mismatch := func() {
+		ctx.Errorf("cannot %v types %v (from %v) and %v (from %v)", a.op.String(), lhs, a.lhs.Annotation(), rhs, a.rhs.Annotation())
+	}
+
+	switch a.op {
+	case ArithmeticAdd:
+		numeric := lhs.canBeAnyOf(value.TNumber) && rhs.canBeAnyOf(value.TNumber)
+		textual := lhs.canBeAnyOf(value.TString, value.TBytes) && rhs.canBeAnyOf(value.TString, value.TBytes, value.TTimestamp)
+		if !numeric && !textual {
+			mismatch()
+			return StaticType{}
+		}
+		if !lhs.IsKnown() {
+			return StaticType{}
+		}
+		var res []value.Type
+		if numeric {
+			res = append(res, value.TNumber)
+		}
+		if textual {
+			res = append(res, value.TString)
+		}
+		return NewStaticType(res...)
+	case ArithmeticSub, ArithmeticMul, ArithmeticDiv, ArithmeticMod:
+		if !lhs.canBeAnyOf(value.TNumber) || !rhs.canBeAnyOf(value.TNumber) {
+			mismatch()
+			return StaticType{}
+		}
+		return NewStaticType(value.TNumber)
+	case ArithmeticEq, ArithmeticNeq:
+		return NewStaticType(value.TBool)
+	case ArithmeticGt, ArithmeticGte, ArithmeticLt, ArithmeticLte:
+		ordered := []value.Type{value.TNumber, value.TString, value.TBytes, value.TTimestamp}
+		if !lhs.canBeAnyOf(ordered...) || !rhs.canBeAnyOf(ordered...) {
+			mismatch()
+		}
+		return NewStaticType(value.TBool)
+	case ArithmeticAnd, ArithmeticOr:
+		for _, side := range []struct {
+			fn Function
+			t  StaticType
+		}{{a.lhs, lhs}, {a.rhs, rhs}} {
+			if !side.t.canBeAnyOf(value.TBool, value.TNumber) {
+				ctx.Errorf("%v: expected bool value, got %v", side.fn.Annotation(), side.t)
+			}
+		}
+		return NewStaticType(value.TBool)
+	case ArithmeticPipe:
+		if !lhs.IsKnown() {
+			return StaticType{}
+		}
+		if !lhs.canBeAnyOf(value.TNull) {
+			ctx.Unreachablef("right hand side of coalesce is unreachable as %v is never null", a.lhs.Annotation())
+			return lhs
+		}
+		return lhs.without(value.TNull).union(rhs)
+	}
+	return StaticType{}
+}
+
+func inferMatch(ctx TypeContext, m *matchFunction) StaticType {
+	matchValue := ctx.value
+	if m.contextFn != nil {
+		matchValue = InferType(ctx, m.contextFn)
+	}
+	caseCtx := ctx.WithValue(matchValue)
+
+	var res StaticType
+	resSet, exhaustive := false, false
+	for i, c := range m.cases {
+		if exhaustive {
+			ctx.Unreachablef("match case %v is unreachable as a previous case always matches", i)
+			continue
+		}
+
+		reachable := true
+		if c.caseValue != nil {
+			vType := literalType(c.caseValue.Value)
+			if vType.IsKnown() && !matchValue.canBeAnyOf(vType.types...) {
+				ctx.Unreachablef("match case %v is unreachable as %v values are never compared with values of type %v", i, vType, matchValue)
+				reachable = false
+			}
+		} else if lit, ok := c.caseFn.(*Literal); ok {
+			if b, isBool := lit.Value.(bool); isBool {
+				exhaustive = b
+				if !b {
+					ctx.Unreachablef("match case %v is unreachable as it is always false", i)
+					reachable = false
+				}
+			}
+		} else if cType := InferType(caseCtx, c.caseFn); !cType.canBeAnyOf(value.TBool) {
+			ctx.Unreachablef("match case %v is unreachable as %v resolves to a non-boolean value of type %v", i, c.caseFn.Annotation(), cType)
+			reachable = false
+		}
+
+		qType := InferType(caseCtx, c.queryFn)
+		if !reachable {
+			continue
+		}
+		if !resSet {
+			res, resSet = qType, true
+		} else {
+			res = res.union(qType)
+		}
+	}
+	if !exhaustive {
+		// When no case matches the assignment is skipped.
+		return StaticType{}
+	}
+	return res
+}
+
+func inferIf(ctx TypeContext, f *ifFunction) StaticType {
+	conditions := []Function{f.queryFn}
+	branches := []Function{f.ifFn}
+	for _, e := range f.elseIfs {
+		conditions = append(conditions, e.QueryFn)
+		branches = append(branches, e.MapFn)
+	}
+
+	var res StaticType
+	resSet := false
+	exhaustive := false
+	for i, cond := range conditions {
+		if exhaustive {
+			ctx.Unreachablef("branch %v of if expression is unreachable as a previous condition is always true", i+1)
+			continue
+		}
+		alwaysTrue, alwaysFalse := CheckCondition(ctx, cond)
+		bType := InferType(ctx, branches[i])
+		if alwaysFalse {
+			ctx.Unreachablef("branch %v of if expression is unreachable as its condition is always false", i+1)
+			continue
+		}
+		if !resSet {
+			res, resSet = bType, true
+		} else {
+			res = res.union(bType)
+		}
+		exhaustive = alwaysTrue
+	}
+
+	if f.elseFn != nil {
+		eType := InferType(ctx, f.elseFn)
+		if exhaustive {
+			ctx.Unreachablef("else branch of if expression is unreachable as a previous condition is always true")
+		} else if !resSet {
+			res = eType
+		} else {
+			res = res.union(eType)
+		}
+		return res
+	}
+	if !exhaustive {
+		return StaticType{}
+	}
+	return res
+}
+
+func inferMethod(ctx TypeContext, m *disabledMethodFunction) StaticType {
+	target := InferType(ctx, m.target)
+	checkParams(ctx, "method "+m.spec.Name, m.spec.Params, m.args)
+
+	switch m.spec.Name {
+	case "catch":
+		// Errors are caught and replaced with the argument.
+		if fn, err := m.args.FieldQuery("fallback"); err == nil {
+			return target.union(InferType(ctx, fn))
+		}
+		return StaticType{}
+	case "or":
+		if fn, err := m.args.FieldQuery("fallback"); err == nil && target.IsKnown() {
+			if !target.canBeAnyOf(value.TNull) {
+				return target
+			}
+			return target.without(value.TNull).union(InferType(ctx, fn))
+		}
+		return StaticType{}
+	case "not_null":
+		if target.IsKnown() && !target.without(value.TNull).IsKnown() {
+			ctx.Errorf("%v: value is always null", m.target.Annotation())
+			return StaticType{}
+		}
+		return target.without(value.TNull)
+	}
+
+	if len(m.spec.TargetTypes) > 0 && !target.canBeAnyOf(m.spec.TargetTypes...) {
+		ctx.Errorf("%v: expected %v value, got %v", m.target.Annotation(), NewStaticType(m.spec.TargetTypes...), target)
+		return StaticType{}
+	}
+	return NewStaticType(m.spec.ReturnTypes...)
+}
+
+func paramAcceptedTypes(t value.Type) []value.Type {
+	switch t {
+	case value.TInt, value.TFloat, value.TNumber:
+		return []value.Type{value.TNumber}
+	case value.TString:
+		return []value.Type{value.TString, value.TBytes}
+	case value.TBool:
+		return []value.Type{value.TBool, value.TNumber}
+	case value.TArray, value.TObject:
+		return []value.Type{t}
+	}
+	return nil
+}
+
+func checkParams(ctx TypeContext, name string, params Params, args *ParsedParams) {
+	if args == nil {
+		return
+	}
+	for i, v := range args.values {
+		fn, isFn := v.(Function)
+		if !isFn {
+			continue
+		}
+		argType := InferType(ctx, fn)
+		if params.Variadic || i >= len(params.Definitions) {
+			continue
+		}
+		def := params.Definitions[i]
+		if accepted := paramAcceptedTypes(def.ValueType); len(accepted) > 0 && !argType.canBeAnyOf(accepted...) {
+			ctx.Errorf("%v: param %v: expected %v value, got %v", name, def.Name, NewStaticType(def.ValueType), argType)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticTypeFromJSONSchema(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id": map[string]any{
+				"anyOf": []any{
+					map[string]any{"type": "string"},
+					map[string]any{"type": "integer"},
+				},
+			},
+			"labels": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+			"closed": map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+			},
+			"items": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"$ref": "#/$defs/item"},
+			},
+			"nullable": map[string]any{"type": []any{"boolean", "null"}},
+		},
+		"required": []any{"id", "labels", "closed", "items", "nullable"},
+		"$defs": map[string]any{
+			"item": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"price": map[string]any{"type": "number"},
+				},
+				"required": []any{"price"},
+			},
+		},
+	}
+
+	sType := StaticTypeFromJSONSchema(schema)
+
+	tests := []struct {
+		path     []string
+		expected string
+	}{
+		{path: nil, expected: "object"},
+		{path: []string{"id"}, expected: "string or number"},
+		{path: []string{"labels", "foo"}, expected: "string or null"},
+		{path: []string{"closed", "foo"}, expected: "null"},
+		{path: []string{"items", "0", "price"}, expected: "number or null"},
+		{path: []string{"items", "foo"}, expected: "null"},
+		{path: []string{"nullable"}, expected: "bool or null"},
+		{path: []string{"id", "foo"}, expected: "null"},
+		{path: []string{"unknown"}, expected: "unknown"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, sType.field(test.path...).String(), "%v", test.path)
+	}
+}
+
+func TestInferTypeArithmetic(t *testing.T) {
+	ctx := NewTypeContext(StaticType{})
+
+	fn, err := NewArithmeticExpression(
+		[]Function{NewFieldFunction("foo"), NewLiteralFunction("", int64(5))},
+		[]ArithmeticOperator{ArithmeticGt},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "bool", InferType(ctx, fn).String())
+	assert.Empty(t, ctx.Issues())
+
+	fn, err = NewArithmeticExpression(
+		[]Function{NewFieldFunction("foo"), NewLiteralFunction("", true)},
+		[]ArithmeticOperator{ArithmeticMul},
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "unknown", InferType(ctx, fn).String())
+	assert.Equal(t, []TypeIssue{
+		{Message: "cannot multiply types unknown (from field `this.foo`) and bool (from bool literal)"},
+	}, ctx.Issues())
+}
@@ -14,6 +14,7 @@ type Environment struct {
 	outputs    *OutputSet
 	processors *ProcessorSet
 	rateLimits *RateLimitSet
+	locks      *LockSet
 	metrics    *MetricsSet
 	tracers    *TracerSet
 
@@ -29,6 +30,7 @@ func NewEnvironment() *Environment {
 		outputs:    &OutputSet{},
 		processors: &ProcessorSet{},
 		rateLimits: &RateLimitSet{},
+		locks:      &LockSet{},
 		metrics:    &MetricsSet{},
 		tracers:    &TracerSet{},
 		scanners:   &ScannerSet{},
@@ -57,6 +59,9 @@ func (e *Environment) Clone() *Environment {
 	for _, v := range e.rateLimits.specs {
 		_ = newEnv.rateLimits.Add(v.constructor, v.spec)
 	}
+	for _, v := range e.locks.specs {
+		_ = newEnv.locks.Add(v.constructor, v.spec)
+	}
 	for _, v := range e.metrics.specs {
 		_ = newEnv.metrics.Add(v.constructor, v.spec)
 	}
@@ -87,6 +92,8 @@ func (e *Environment) GetDocs(name string, ctype docs.Type) (docs.ComponentSpec,
 		spec, ok = e.processors.DocsFor(name)
 	case docs.TypeRateLimit:
 		spec, ok = e.rateLimits.DocsFor(name)
+	case docs.TypeLock:
+		spec, ok = e.locks.DocsFor(name)
 	case docs.TypeMetrics:
 		spec, ok = e.metrics.DocsFor(name)
 	case docs.TypeTracer:
@@ -98,6 +105,24 @@ func (e *Environment) GetDocs(name string, ctype docs.Type) (docs.ComponentSpec,
 	return spec, ok
 }
 
+// JSONSchemaDefinitions returns a map of component types to JSON schema
+// definitions describing all plugins of the environment, where component
+// fields reference each other with the provided prefix.
+func (e *Environment) JSONSchemaDefinitions(refPrefix string) map[string]any {
+	return map[string]any{
+		string(docs.TypeInput):     docs.ComponentJSONSchema(docs.TypeInput, e.InputDocs(), refPrefix),
+		string(docs.TypeBuffer):    docs.ComponentJSONSchema(docs.TypeBuffer, e.BufferDocs(), refPrefix),
+		string(docs.TypeCache):     docs.ComponentJSONSchema(docs.TypeCache, e.CacheDocs(), refPrefix),
+		string(docs.TypeProcessor): docs.ComponentJSONSchema(docs.TypeProcessor, e.ProcessorDocs(), refPrefix),
+		string(docs.TypeRateLimit): docs.ComponentJSONSchema(docs.TypeRateLimit, e.RateLimitDocs(), refPrefix),
+		string(docs.TypeLock):      docs.ComponentJSONSchema(docs.TypeLock, e.LockDocs(), refPrefix),
+		string(docs.TypeOutput):    docs.ComponentJSONSchema(docs.TypeOutput, e.OutputDocs(), refPrefix),
+		string(docs.TypeMetrics):   docs.ComponentJSONSchema(docs.TypeMetrics, e.MetricsDocs(), refPrefix),
+		string(docs.TypeTracer):    docs.ComponentJSONSchema(docs.TypeTracer, e.TracersDocs(), refPrefix),
+		string(docs.TypeScanner):   docs.ComponentJSONSchema(docs.TypeScanner, e.ScannerDocs(), refPrefix),
+	}
+}
+
 // GlobalEnvironment contains service-wide singleton bundles.
 var GlobalEnvironment = &Environment{
 	buffers:    AllBuffers,
@@ -106,6 +131,7 @@ var GlobalEnvironment = &Environment{
 	outputs:    AllOutputs,
 	processors: AllProcessors,
 	rateLimits: AllRateLimits,
+	locks:      AllLocks,
 	metrics:    AllMetrics,
 	tracers:    AllTracers,
 	scanners:   AllScanners,
@@ -21,6 +21,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
@@ -30,6 +31,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/log"
 	"github.com/warpstreamlabs/bento/internal/message"
 	"github.com/warpstreamlabs/bento/internal/pipeline"
+	"github.com/warpstreamlabs/bento/internal/transaction"
 )
 
 var (
@@ -52,6 +54,7 @@ type NewManagement interface {
 	Metrics() metrics.Type
 	Logger() log.Modular
 	Tracer() trace.TracerProvider
+	MemoryBudget() *transaction.Budget
 	FS() ifs.FS
 	Environment() *Environment
 	BloblEnvironment() *bloblang.Environment
@@ -64,6 +67,7 @@ type NewManagement interface {
 	NewProcessor(conf processor.Config) (processor.V1, error)
 	NewOutput(conf output.Config, pipelines ...processor.PipelineConstructorFunc) (output.Streamed, error)
 	NewRateLimit(conf ratelimit.Config) (ratelimit.V1, error)
+	NewLock(conf lock.Config) (lock.V1, error)
 	NewScanner(conf scanner.Config) (scanner.Creator, error)
 	NewPipeline(conf pipeline.Config) (processor.Pipeline, error)
 
@@ -92,6 +96,11 @@ type NewManagement interface {
 	StoreRateLimit(ctx context.Context, name string, conf ratelimit.Config) error
 	RemoveRateLimit(ctx context.Context, name string) error
 
+	ProbeLock(name string) bool
+	AccessLock(ctx context.Context, name string, fn func(lock.V1)) error
+	StoreLock(ctx context.Context, name string, conf lock.Config) error
+	RemoveLock(ctx context.Context, name string) error
+
 	GetPipe(name string) (<-chan message.Transaction, error)
 	SetPipe(name string, t <-chan message.Transaction)
 	UnsetPipe(name string, t <-chan message.Transaction)
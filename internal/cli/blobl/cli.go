@@ -57,9 +57,15 @@ Find out more about Bloblang at: {{.DocumentationURL}}/guides/bloblang/about`)[1
 				Usage: "Set the buffer size for document lines.",
 				Value: bufio.MaxScanTokenSize,
 			},
+			&cli.BoolFlag{
+				Name:  "trace",
+				Usage: "print a trace of each statement executed to stderr, including the values resolved, variables set, branches taken and time spent.",
+			},
 		},
 		Action: run,
 		Subcommands: []*cli.Command{
+			fmtCliCommand(opts),
+			lspCliCommand(opts),
 			{
 				Name:    "server",
 				Aliases: []string{"playground"},
@@ -114,6 +120,7 @@ func run(c *cli.Context) error {
 	t := max(c.Int("threads"), 1)
 	raw := c.Bool("raw")
 	pretty := c.Bool("pretty")
+	trace := c.Bool("trace")
 	file := c.String("file")
 	m := c.Args().First()
 
@@ -131,6 +138,9 @@ func run(c *cli.Context) error {
 	}
 
 	bEnv := bloblang.NewEnvironment().WithImporterRelativeToFile(file)
+	if trace {
+		bEnv = bEnv.WithDisabledOptimisations()
+	}
 	exec, err := bEnv.NewMapping(m)
 	if err != nil {
 		if perr, ok := err.(*parser.Error); ok {
@@ -177,7 +187,15 @@ func run(c *cli.Context) error {
 					return
 				}
 
-				resultStr, err := execCache.executeBloblangMapping(exec, raw, pretty, input)
+				var resultStr string
+				var err error
+				if trace {
+					var entries []traceEntry
+					resultStr, entries, err = execCache.traceBloblangMapping(exec, raw, pretty, input)
+					fmt.Fprint(os.Stderr, formatTrace(entries))
+				} else {
+					resultStr, err = execCache.executeBloblangMapping(exec, raw, pretty, input)
+				}
 				if err != nil {
 					fmt.Fprintln(os.Stderr, red(fmt.Sprintf("failed to execute map: %v", err)))
 					continue
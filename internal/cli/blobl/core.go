@@ -1,6 +1,7 @@
 package blobl
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -16,9 +17,102 @@ import (
 
 // executionResult represents the result of executing a Bloblang mapping.
 type executionResult struct {
-	Result       any `json:"result"`
-	ParseError   any `json:"parse_error"`
-	MappingError any `json:"mapping_error"`
+	Result       any          `json:"result"`
+	ParseError   any          `json:"parse_error"`
+	MappingError any          `json:"mapping_error"`
+	Trace        []traceEntry `json:"trace,omitempty"`
+}
+
+// traceEntry describes the execution of a single statement of a mapping.
+type traceEntry struct {
+	Line      int            `json:"line"`
+	Column    int            `json:"column"`
+	Statement string         `json:"statement"`
+	Value     string         `json:"value,omitempty"`
+	Vars      map[string]any `json:"vars,omitempty"`
+	Branches  []traceBranch  `json:"branches,omitempty"`
+	Duration  string         `json:"duration"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// traceBranch describes a branch of a match or if expression that was taken.
+type traceBranch struct {
+	Expression string `json:"expression"`
+	Branch     string `json:"branch"`
+}
+
+// traceValueString returns a human readable representation of a value
+// resolved by a statement.
+func traceValueString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case value.Delete:
+		return "deleted()"
+	case value.Nothing:
+		return "nothing (assignment skipped)"
+	case []byte:
+		v = string(t)
+	}
+	b, err := json.Marshal(value.ISanitize(v))
+	if err != nil {
+		return value.IToString(v)
+	}
+	return string(b)
+}
+
+func newTraceEntries(traces []mapping.StatementTrace) []traceEntry {
+	entries := make([]traceEntry, 0, len(traces))
+	for _, t := range traces {
+		entry := traceEntry{
+			Line:      t.Line,
+			Column:    t.Column,
+			Statement: t.Statement,
+			Duration:  t.Duration.String(),
+		}
+		if !t.Conditional && t.Err == nil {
+			entry.Value = traceValueString(t.Value)
+		}
+		if len(t.Vars) > 0 {
+			entry.Vars = make(map[string]any, len(t.Vars))
+			for k, v := range t.Vars {
+				entry.Vars[k] = value.ISanitize(v)
+			}
+		}
+		for _, b := range t.Branches {
+			entry.Branches = append(entry.Branches, traceBranch{
+				Expression: b.Expression,
+				Branch:     b.Branch,
+			})
+		}
+		if t.Err != nil {
+			entry.Error = t.Err.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// formatTrace returns a human readable representation of a mapping trace.
+func formatTrace(entries []traceEntry) string {
+	var buf strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "line %v: %v (%v)\n", e.Line, e.Statement, e.Duration)
+		for _, b := range e.Branches {
+			fmt.Fprintf(&buf, "  branch: %v took %v\n", b.Expression, b.Branch)
+		}
+		if e.Value != "" {
+			fmt.Fprintf(&buf, "  value: %v\n", e.Value)
+		}
+		if len(e.Vars) > 0 {
+			varBytes, _ := json.Marshal(e.Vars)
+			fmt.Fprintf(&buf, "  vars: %s\n", varBytes)
+		}
+		if e.Error != "" {
+			fmt.Fprintf(&buf, "  error: %v\n", e.Error)
+		}
+	}
+	return buf.String()
 }
 
 // execCache is used to execute Bloblang mappings with cached state.
@@ -67,6 +161,19 @@ type Walker interface {
 // executeBloblangMapping runs a compiled Bloblang mapping executor against input data.
 // It supports both raw and structured input, and optionally pretty-prints output.
 func (e *execCache) executeBloblangMapping(exec *mapping.Executor, rawInput, prettyOutput bool, input []byte) (string, error) {
+	return e.execute(exec, rawInput, prettyOutput, input, nil)
+}
+
+// traceBloblangMapping runs a compiled Bloblang mapping executor against input
+// data in the same way as executeBloblangMapping, and also returns a trace of
+// each statement executed.
+func (e *execCache) traceBloblangMapping(exec *mapping.Executor, rawInput, prettyOutput bool, input []byte) (string, []traceEntry, error) {
+	var traces []mapping.StatementTrace
+	res, err := e.execute(exec, rawInput, prettyOutput, input, &traces)
+	return res, newTraceEntries(traces), err
+}
+
+func (e *execCache) execute(exec *mapping.Executor, rawInput, prettyOutput bool, input []byte, traces *[]mapping.StatementTrace) (string, error) {
 	e.msg.Get(0).SetBytes(input)
 
 	var valuePtr *any
@@ -98,17 +205,25 @@ func (e *execCache) executeBloblangMapping(exec *mapping.Executor, rawInput, pre
 	}
 
 	var result any = value.Nothing(nil)
-	err := exec.ExecOnto(query.FunctionContext{
+	fnCtx := query.FunctionContext{
 		Maps:     exec.Maps(),
 		Vars:     e.vars,
 		MsgBatch: e.msg,
 		NewMeta:  e.msg.Get(0),
 		NewValue: &result,
-	}.WithValueFunc(lazyValue), mapping.AssignmentContext{
+	}.WithValueFunc(lazyValue)
+	asCtx := mapping.AssignmentContext{
 		Vars:  e.vars,
 		Meta:  e.msg.Get(0),
 		Value: &result,
-	})
+	}
+
+	var err error
+	if traces != nil {
+		*traces, err = exec.ExecOntoTraced(fnCtx, asCtx)
+	} else {
+		err = exec.ExecOnto(fnCtx, asCtx)
+	}
 
 	if err != nil {
 		var ctxErr query.ErrNoContext
@@ -155,8 +270,9 @@ func (e *execCache) executeBloblangMapping(exec *mapping.Executor, rawInput, pre
 }
 
 // evaluateMapping compiles and executes a Bloblang mapping string against a JSON input string.
-// Returns an executionResult containing with the output or error details.
-func evaluateMapping(env *bloblang.Environment, input, mapping string) *executionResult {
+// Returns an executionResult containing with the output or error details, and a trace of the
+// execution when trace is true.
+func evaluateMapping(env *bloblang.Environment, input, mapping string, trace bool) *executionResult {
 	result := &executionResult{
 		Result:       nil,
 		ParseError:   nil,
@@ -173,7 +289,11 @@ func evaluateMapping(env *bloblang.Environment, input, mapping string) *executio
 		return result
 	}
 
-	exec, err := env.WithoutFunctions("env", "file").NewMapping(mapping)
+	env = env.WithoutFunctions("env", "file")
+	if trace {
+		env = env.WithDisabledOptimisations()
+	}
+	exec, err := env.NewMapping(mapping)
 	if err != nil {
 		if perr, ok := err.(*parser.Error); ok {
 			result.ParseError = fmt.Sprintf("failed to parse mapping: %v", perr.ErrorAtPositionStructured("", []rune(mapping)))
@@ -184,7 +304,13 @@ func evaluateMapping(env *bloblang.Environment, input, mapping string) *executio
 	}
 
 	execCache := newExecCache()
-	output, err := execCache.executeBloblangMapping(exec, false, true, []byte(input))
+
+	var output string
+	if trace {
+		output, result.Trace, err = execCache.traceBloblangMapping(exec, false, true, []byte(input))
+	} else {
+		output, err = execCache.executeBloblangMapping(exec, false, true, []byte(input))
+	}
 	if err != nil {
 		result.MappingError = fmt.Sprintf("execution error: %v", err.Error())
 	} else {
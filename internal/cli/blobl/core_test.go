@@ -0,0 +1,67 @@
+package blobl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+)
+
+func TestEvaluateMappingTrace(t *testing.T) {
+	mapping := `let name = this.name.uppercase()
+root.greeting = match $name {
+  "BENTO" => "hello " + $name
+  _ => "who?"
+}
+root.missing = this.missing.not_null()`
+
+	res := evaluateMapping(bloblang.GlobalEnvironment(), `{"name":"bento"}`, mapping, false)
+	assert.Nil(t, res.Trace)
+
+	res = evaluateMapping(bloblang.GlobalEnvironment(), `{"name":"bento"}`, mapping, true)
+	require.NotNil(t, res.MappingError)
+	require.Len(t, res.Trace, 3)
+
+	for i := range res.Trace {
+		assert.NotEmpty(t, res.Trace[i].Duration)
+		res.Trace[i].Duration = ""
+	}
+	assert.Equal(t, []traceEntry{
+		{
+			Line:      1,
+			Column:    1,
+			Statement: "let name = this.name.uppercase()",
+			Value:     `"BENTO"`,
+			Vars:      map[string]any{"name": "BENTO"},
+		},
+		{
+			Line:      2,
+			Column:    1,
+			Statement: "root.greeting = match $name {",
+			Value:     `"hello BENTO"`,
+			Vars:      map[string]any{"name": "BENTO"},
+			Branches:  []traceBranch{{Expression: "match expression", Branch: "case 0"}},
+		},
+		{
+			Line:      6,
+			Column:    1,
+			Statement: "root.missing = this.missing.not_null()",
+			Vars:      map[string]any{"name": "BENTO"},
+			Error:     "field `this.missing`: value is null",
+		},
+	}, res.Trace)
+
+	assert.Equal(t, `line 1: let name = this.name.uppercase() ()
+  value: "BENTO"
+  vars: {"name":"BENTO"}
+line 2: root.greeting = match $name { ()
+  branch: match expression took case 0
+  value: "hello BENTO"
+  vars: {"name":"BENTO"}
+line 6: root.missing = this.missing.not_null() ()
+  vars: {"name":"BENTO"}
+  error: field `+"`this.missing`"+`: value is null
+`, formatTrace(res.Trace))
+}
@@ -0,0 +1,260 @@
+package blobl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/cli/common"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	ifilepath "github.com/warpstreamlabs/bento/internal/filepath"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+)
+
+func fmtCliCommand(opts *common.CLIOpts) *cli.Command {
+	return &cli.Command{
+		Name:  "fmt",
+		Usage: "Format Bloblang mappings and the mappings within YAML configs.",
+		Description: opts.ExecTemplate(`
+Rewrites .blobl files, and Bloblang fields within {{.ProductName}} YAML configs,
+in a canonical format. Whitespace between tokens and indentation are
+normalised, whereas comments and line breaks are preserved:
+
+  {{.BinaryName}} blobl fmt ./mapping.blobl ./config.yaml
+  {{.BinaryName}} blobl fmt --check ./configs/...
+
+If a path ends with '...' then {{.ProductName}} will walk the target and format
+any files with the .blobl, .yaml or .yml extension. When no paths are provided
+a mapping is read from stdin and the formatted result is written to stdout.
+
+With --check files are not modified, instead the paths of any files that are
+not formatted are printed and the command exits with a status code 1, which is
+useful in CI pipelines.`)[1:],
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "check",
+				Value: false,
+				Usage: "Print the paths of files that are not formatted rather than rewriting them, and exit with a status code 1 if there are any.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			f := &mappingFormatter{
+				env:      bloblang.GlobalEnvironment(),
+				confSpec: opts.MainConfigSpecCtor(),
+				prov:     bundle.GlobalEnvironment,
+			}
+			if code := f.run(c.Args().Slice(), c.Bool("check"), os.Stdin, os.Stdout, os.Stderr); code != 0 {
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+}
+
+// mappingFormatter formats Bloblang mappings, either from .blobl files or from
+// the Bloblang fields of YAML configs.
+type mappingFormatter struct {
+	env      *bloblang.Environment
+	confSpec docs.FieldSpecs
+	prov     docs.Provider
+}
+
+func (f *mappingFormatter) run(paths []string, check bool, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(paths) == 0 {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprint(stderr, red(fmt.Sprintf("failed to read stdin: %v\n", err)))
+			return 1
+		}
+		formatted, err := f.env.FormatMapping(string(input))
+		if err != nil {
+			fmt.Fprint(stderr, red(fmt.Sprintf("<stdin>%v\n", formatMappingError([]rune(string(input)), err))))
+			return 1
+		}
+		if check {
+			if formatted != string(input) {
+				fmt.Fprintln(stdout, "<stdin>")
+				return 1
+			}
+			return 0
+		}
+		_, _ = io.WriteString(stdout, formatted)
+		return 0
+	}
+
+	targets, err := ifilepath.GlobsAndSuperPaths(ifs.OS(), paths, "blobl", "yaml", "yml")
+	if err != nil {
+		fmt.Fprintf(stderr, "Format paths error: %v\n", err)
+		return 1
+	}
+
+	exitCode := 0
+	for _, target := range targets {
+		if !f.formatFile(target, check, stdout, stderr) {
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+func (f *mappingFormatter) formatFile(path string, check bool, stdout, stderr io.Writer) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprint(stderr, red(fmt.Sprintf("%v: failed to read file: %v\n", path, err)))
+		return false
+	}
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprint(stderr, red(fmt.Sprintf("%v: failed to read file: %v\n", path, err)))
+		return false
+	}
+
+	var formatted string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var errs []error
+		if formatted, errs = f.formatYAML(string(rawBytes)); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprint(stderr, red(fmt.Sprintf("%v%v\n", path, err)))
+			}
+			return false
+		}
+	default:
+		if formatted, err = f.env.WithImporterRelativeToFile(path).FormatMapping(string(rawBytes)); err != nil {
+			fmt.Fprint(stderr, red(fmt.Sprintf("%v%v\n", path, formatMappingError([]rune(string(rawBytes)), err))))
+			return false
+		}
+	}
+
+	if formatted == string(rawBytes) {
+		return true
+	}
+	if check {
+		fmt.Fprintln(stdout, path)
+		return false
+	}
+	if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+		fmt.Fprint(stderr, red(fmt.Sprintf("%v: failed to write file: %v\n", path, err)))
+		return false
+	}
+	return true
+}
+
+// formatMappingError returns an error message prefixed with the line and column
+// of the mapping at which the error occurred, if known.
+func formatMappingError(input []rune, err error) string {
+	var pErr *parser.Error
+	if errors.As(err, &pErr) {
+		line, col := parser.LineAndColOf(input, pErr.Input)
+		return fmt.Sprintf("(%v,%v) %v", line, col, pErr.ErrorWithoutPosition())
+	}
+	return ": " + err.Error()
+}
+
+// formatYAML formats the Bloblang fields of a YAML config. Only the text of
+// the fields is modified and therefore the rest of the config, including
+// comments, is left unchanged.
+//
+// Mappings within folded block scalars are left unformatted, as are mappings
+// within flow scalars where the formatted result would span multiple lines.
+func (f *mappingFormatter) formatYAML(text string) (string, []error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &node); err != nil {
+		return "", []error{fmt.Errorf(": failed to parse config: %w", err)}
+	}
+
+	d := newLSPDocument("", "yaml", text, f.confSpec, f.prov)
+
+	// Regions are rewritten in reverse order so that the positions of those
+	// remaining are unaffected.
+	regions := append([]*mappingRegion{}, d.regions...)
+	sort.SliceStable(regions, func(i, j int) bool {
+		if regions[i].line == regions[j].line {
+			return regions[i].col > regions[j].col
+		}
+		return regions[i].line > regions[j].line
+	})
+
+	lines := append([]string{}, d.lines...)
+
+	var errs []error
+	changed := false
+	for _, r := range regions {
+		formatted, err := f.env.FormatMapping(string(r.text))
+		if err != nil {
+			var pErr *parser.Error
+			if errors.As(err, &pErr) {
+				line, col := r.toDocument(r.position(len(r.text) - len(pErr.Input)))
+				errs = append(errs, fmt.Errorf("(%v,%v) %v", line+1, col+1, pErr.ErrorWithoutPosition()))
+			} else {
+				errs = append(errs, fmt.Errorf("(%v,1) %w", r.line+1, err))
+			}
+			continue
+		}
+		formatted = strings.TrimSuffix(formatted, "\n")
+		if formatted == strings.TrimRight(string(r.text), "\n") {
+			continue
+		}
+
+		if r.block {
+			if r.style != yaml.LiteralStyle {
+				continue
+			}
+			var newLines []string
+			for _, l := range strings.Split(formatted, "\n") {
+				if l != "" {
+					l = strings.Repeat(" ", r.indent) + l
+				}
+				newLines = append(newLines, l)
+			}
+			n := len(strings.Split(strings.TrimRight(string(r.text), "\n"), "\n"))
+			lines = append(lines[:r.line], append(newLines, lines[r.line+n:]...)...)
+			changed = true
+			continue
+		}
+
+		start := r.col
+		if r.style == yaml.DoubleQuotedStyle || r.style == yaml.SingleQuotedStyle {
+			start--
+		}
+		original, err := encodeYAMLScalar(string(r.text), r.style)
+		if err != nil {
+			continue
+		}
+		replacement, err := encodeYAMLScalar(formatted, r.style)
+		if err != nil || strings.Contains(replacement, "\n") {
+			continue
+		}
+		lineRunes := []rune(lines[r.line])
+		if start < 0 || start+len([]rune(original)) > len(lineRunes) || string(lineRunes[start:start+len([]rune(original))]) != original {
+			continue
+		}
+		lines[r.line] = string(lineRunes[:start]) + replacement + string(lineRunes[start+len([]rune(original)):])
+		changed = true
+	}
+	if len(errs) > 0 {
+		return "", errs
+	}
+	if !changed {
+		return text, nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func encodeYAMLScalar(value string, style yaml.Style) (string, error) {
+	b, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Style: style, Value: value})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
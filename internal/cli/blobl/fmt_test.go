@@ -0,0 +1,158 @@
+package blobl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+)
+
+func newTestFormatter() *mappingFormatter {
+	confSpec, prov := testConfigDocs()
+	return &mappingFormatter{
+		env:      bloblang.GlobalEnvironment(),
+		confSpec: confSpec,
+		prov:     prov,
+	}
+}
+
+func TestFormatStdin(t *testing.T) {
+	f := newTestFormatter()
+
+	var stdout, stderr bytes.Buffer
+	code := f.run(nil, false, strings.NewReader("root.foo   = this.bar+1\n\n\n# done\n"), &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "root.foo = this.bar + 1\n\n# done\n", stdout.String())
+
+	stdout.Reset()
+	code = f.run(nil, true, strings.NewReader("root.foo = this.bar + 1\n"), &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Empty(t, stdout.String())
+
+	code = f.run(nil, true, strings.NewReader("root.foo = this.bar+1\n"), &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "<stdin>\n", stdout.String())
+
+	stdout.Reset()
+	code = f.run(nil, false, strings.NewReader("root.foo = this.bar +\nroot.baz = nope"), &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "<stdin>(")
+}
+
+func TestFormatBloblFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	goodPath := filepath.Join(dir, "good.blobl")
+	badPath := filepath.Join(dir, "bad.blobl")
+	libPath := filepath.Join(dir, "lib.blobl")
+	require.NoError(t, os.WriteFile(goodPath, []byte("root.foo = this.bar.uppercase()\n"), 0o644))
+	require.NoError(t, os.WriteFile(badPath, []byte("import \"./lib.blobl\"\nroot.foo = this.bar.apply( \"thing\" )\n"), 0o644))
+	require.NoError(t, os.WriteFile(libPath, []byte("map thing {\nroot = this+1\n}"), 0o644))
+
+	f := newTestFormatter()
+
+	var stdout, stderr bytes.Buffer
+	code := f.run([]string{dir + "/..."}, true, nil, &stdout, &stderr)
+	assert.Equal(t, 1, code, stderr.String())
+	assert.Empty(t, stderr.String())
+	assert.ElementsMatch(t, []string{badPath, libPath}, strings.Fields(stdout.String()))
+
+	// Nothing is modified in check mode.
+	b, err := os.ReadFile(libPath)
+	require.NoError(t, err)
+	assert.Equal(t, "map thing {\nroot = this+1\n}", string(b))
+
+	stdout.Reset()
+	code = f.run([]string{dir + "/..."}, false, nil, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Empty(t, stdout.String())
+
+	for path, exp := range map[string]string{
+		goodPath: "root.foo = this.bar.uppercase()\n",
+		badPath:  "import \"./lib.blobl\"\nroot.foo = this.bar.apply(\"thing\")\n",
+		libPath:  "map thing {\n  root = this + 1\n}\n",
+	} {
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, exp, string(b), path)
+	}
+
+	code = f.run([]string{dir + "/..."}, true, nil, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Empty(t, stdout.String())
+}
+
+func TestFormatYAML(t *testing.T) {
+	f := newTestFormatter()
+
+	input := `pipeline:
+  processors:
+    # Comments are kept
+    - mapping: |
+        root.foo  =  this.foo   # uppercase later
+        root.bar = if this.x>1 {
+        "big"
+        }
+
+    - mapping: 'root = this.thing.replace_all( "a","b" )' # inline
+    - branch:
+        request_map: root = this.id
+        processors:
+          - mapping: "root = [ 1,2 ]"
+        result_map: >
+          root.folded = this.x+1
+    - mapping: |-
+        root = this
+`
+
+	formatted, errs := f.formatYAML(input)
+	require.Empty(t, errs)
+	assert.Equal(t, `pipeline:
+  processors:
+    # Comments are kept
+    - mapping: |
+        root.foo = this.foo # uppercase later
+        root.bar = if this.x > 1 {
+          "big"
+        }
+
+    - mapping: 'root = this.thing.replace_all("a", "b")' # inline
+    - branch:
+        request_map: root = this.id
+        processors:
+          - mapping: "root = [1, 2]"
+        result_map: >
+          root.folded = this.x+1
+    - mapping: |-
+        root = this
+`, formatted)
+
+	reformatted, errs := f.formatYAML(formatted)
+	require.Empty(t, errs)
+	assert.Equal(t, formatted, reformatted)
+}
+
+func TestFormatYAMLErrors(t *testing.T) {
+	f := newTestFormatter()
+
+	_, errs := f.formatYAML(`pipeline:
+  processors:
+    - mapping: |
+        root.foo = this.foo
+        root.bar = nope()
+`)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "(5,")
+	assert.Contains(t, errs[0].Error(), "unrecognised function 'nope'")
+
+	_, errs = f.formatYAML("pipeline: [\n")
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "failed to parse config")
+}
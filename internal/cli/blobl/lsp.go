@@ -0,0 +1,407 @@
+package blobl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/cli/common"
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+// LSP error codes as defined by the JSON-RPC and language server protocol
+// specifications.
+const (
+	lspParseError     = -32700
+	lspInvalidRequest = -32600
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+)
+
+type lspRequest struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type lspResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+	Error   *lspError        `json:"error,omitempty"`
+}
+
+type lspNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspTextDocumentPosition struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position lspPosition `json:"position"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type lspCompletionItem struct {
+	Label         string            `json:"label"`
+	Kind          int               `json:"kind"`
+	Detail        string            `json:"detail,omitempty"`
+	Documentation *lspMarkupContent `json:"documentation,omitempty"`
+}
+
+type lspSignatureInformation struct {
+	Label         string                    `json:"label"`
+	Documentation *lspMarkupContent         `json:"documentation,omitempty"`
+	Parameters    []lspParameterInformation `json:"parameters"`
+}
+
+type lspParameterInformation struct {
+	Label         string `json:"label"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// Completion item kinds as defined by the language server protocol.
+const (
+	lspCompletionMethod   = 2
+	lspCompletionFunction = 3
+	lspCompletionVariable = 6
+	lspCompletionKeyword  = 14
+)
+
+//------------------------------------------------------------------------------
+
+func lspCliCommand(opts *common.CLIOpts) *cli.Command {
+	return &cli.Command{
+		Name:  "lsp",
+		Usage: "Run a Bloblang language server over stdio.",
+		Description: opts.ExecTemplate(`
+Runs a language server that communicates over stdin and stdout using the
+Language Server Protocol, providing diagnostics, completion, signature help,
+hover documentation and go-to-definition of maps to editors. Both .blobl files
+and mappings embedded within {{.ProductName}} YAML configs are supported:
+
+  {{.BinaryName}} blobl lsp`)[1:],
+		Action: func(c *cli.Context) error {
+			return newLSPServer(bloblang.GlobalEnvironment().Deactivated(), opts.MainConfigSpecCtor(), bundle.GlobalEnvironment).
+				serve(os.Stdin, os.Stdout)
+		},
+	}
+}
+
+// lspServer implements a Bloblang language server. Requests are processed
+// sequentially in the order that they are received.
+type lspServer struct {
+	env      *bloblang.Environment
+	confSpec docs.FieldSpecs
+	prov     docs.Provider
+
+	functions     map[string]query.FunctionSpec
+	methods       map[string]query.MethodSpec
+	functionNames []string
+	methodNames   []string
+
+	rootPath string
+	docs     map[string]*lspDocument
+	out      io.Writer
+}
+
+func newLSPServer(env *bloblang.Environment, confSpec docs.FieldSpecs, prov docs.Provider) *lspServer {
+	s := &lspServer{
+		env:       env,
+		confSpec:  confSpec,
+		prov:      prov,
+		functions: map[string]query.FunctionSpec{},
+		methods:   map[string]query.MethodSpec{},
+		docs:      map[string]*lspDocument{},
+	}
+	env.WalkFunctions(func(name string, spec query.FunctionSpec) {
+		if spec.Status == query.StatusHidden {
+			return
+		}
+		s.functions[name] = spec
+		s.functionNames = append(s.functionNames, name)
+	})
+	env.WalkMethods(func(name string, spec query.MethodSpec) {
+		if spec.Status == query.StatusHidden {
+			return
+		}
+		s.methods[name] = spec
+		s.methodNames = append(s.methodNames, name)
+	})
+	sort.Strings(s.functionNames)
+	sort.Strings(s.methodNames)
+	return s
+}
+
+func (s *lspServer) serve(in io.Reader, out io.Writer) error {
+	s.out = out
+
+	r := bufio.NewReader(in)
+	for {
+		body, err := readLSPMessage(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var req lspRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.writeMessage(lspResponse{
+				JSONRPC: "2.0",
+				Error:   &lspError{Code: lspParseError, Message: err.Error()},
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, rErr := s.handle(req)
+		if req.ID == nil {
+			continue
+		}
+		if err := s.writeMessage(lspResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Result:  result,
+			Error:   rErr,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	contentLength := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if line == "" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to read message header: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed message header: %v", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(k), "Content-Length") {
+			if contentLength, err = strconv.Atoi(strings.TrimSpace(v)); err != nil {
+				return nil, fmt.Errorf("malformed content length: %w", err)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return nil, errors.New("message is missing a content length header")
+	}
+	body := make([]byte, contentLength)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
+
+func (s *lspServer) writeMessage(v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = s.out.Write(body)
+	return err
+}
+
+func (s *lspServer) handle(req lspRequest) (any, *lspError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			RootURI string `json:"rootUri"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+		}
+		s.rootPath = uriToPath(params.RootURI)
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": 1, // Full
+				"completionProvider": map[string]any{
+					"triggerCharacters": []string{".", "$"},
+				},
+				"signatureHelpProvider": map[string]any{
+					"triggerCharacters": []string{"(", ","},
+				},
+				"hoverProvider":      true,
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]any{
+				"name": "blobl-lsp",
+			},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI        string `json:"uri"`
+				LanguageID string `json:"languageId"`
+				Text       string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+		}
+		s.updateDocument(params.TextDocument.URI, params.TextDocument.LanguageID, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		var languageID string
+		if d, exists := s.docs[params.TextDocument.URI]; exists {
+			languageID = d.languageID
+		}
+		s.updateDocument(params.TextDocument.URI, languageID, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		return nil, nil
+	case "textDocument/didClose":
+		var params lspTextDocumentPosition
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+		}
+		delete(s.docs, params.TextDocument.URI)
+		s.publishDiagnostics(params.TextDocument.URI, []lspDiagnostic{})
+		return nil, nil
+	case "textDocument/completion":
+		return s.withPosition(req, s.completion)
+	case "textDocument/hover":
+		return s.withPosition(req, s.hover)
+	case "textDocument/signatureHelp":
+		return s.withPosition(req, s.signatureHelp)
+	case "textDocument/definition":
+		return s.withPosition(req, s.definition)
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	}
+	if req.ID == nil {
+		// Unknown notifications are ignored.
+		return nil, nil
+	}
+	if req.Method == "" {
+		return nil, &lspError{Code: lspInvalidRequest, Message: "request method is empty"}
+	}
+	return nil, &lspError{Code: lspMethodNotFound, Message: fmt.Sprintf("method %v is not supported", req.Method)}
+}
+
+func (s *lspServer) withPosition(req lspRequest, fn func(d *lspDocument, pos lspPosition) any) (any, *lspError) {
+	var params lspTextDocumentPosition
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, &lspError{Code: lspInvalidParams, Message: err.Error()}
+	}
+	d, exists := s.docs[params.TextDocument.URI]
+	if !exists {
+		return nil, nil
+	}
+	return fn(d, params.Position), nil
+}
+
+func (s *lspServer) updateDocument(uri, languageID, text string) {
+	d := newLSPDocument(uri, languageID, text, s.confSpec, s.prov)
+	s.docs[uri] = d
+	s.publishDiagnostics(uri, s.diagnostics(d))
+}
+
+func (s *lspServer) publishDiagnostics(uri string, diags []lspDiagnostic) {
+	_ = s.writeMessage(lspNotification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params: map[string]any{
+			"uri":         uri,
+			"diagnostics": diags,
+		},
+	})
+}
+
+//------------------------------------------------------------------------------
+
+func uriToPath(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
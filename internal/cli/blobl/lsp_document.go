@@ -0,0 +1,838 @@
+package blobl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+// lspDocument is a text document opened by the client, which is either a
+// Bloblang mapping or a YAML config containing zero or more mappings.
+type lspDocument struct {
+	uri        string
+	path       string
+	languageID string
+	isYAML     bool
+	lines      []string
+	regions    []*mappingRegion
+}
+
+// mappingRegion is a Bloblang mapping within a document. Positions within a
+// region are zero indexed lines and rune columns of the mapping text.
+type mappingRegion struct {
+	text  []rune
+	lines [][]rune
+
+	// The line and column of the document where the mapping begins.
+	line, col int
+
+	// When block is true each line of the mapping is found on successive lines
+	// of the document and indented by a number of characters, otherwise the
+	// mapping is inline and begins at line, col.
+	block  bool
+	indent int
+
+	// The style of the YAML scalar containing the mapping, if any.
+	style yaml.Style
+}
+
+func newMappingRegion(text string, line, col int, block bool, indent int) *mappingRegion {
+	r := &mappingRegion{
+		text:   []rune(text),
+		line:   line,
+		col:    col,
+		block:  block,
+		indent: indent,
+	}
+	for _, l := range strings.Split(text, "\n") {
+		r.lines = append(r.lines, []rune(l))
+	}
+	return r
+}
+
+// toDocument converts a position within the mapping to a position within the
+// document.
+func (r *mappingRegion) toDocument(line, col int) (docLine, docCol int) { //nolint: gocritic // Ignore unnamedResult false positive
+	if r.block {
+		return r.line + line, r.indent + col
+	}
+	if line == 0 {
+		return r.line, r.col + col
+	}
+	return r.line + line, col
+}
+
+// fromDocument converts a position within the document to a position within
+// the mapping, returning false if the position is outside of the mapping.
+func (r *mappingRegion) fromDocument(docLine, docCol int) (line, col int, ok bool) { //nolint: gocritic // Ignore unnamedResult false positive
+	if r.block {
+		line = docLine - r.line
+		if line < 0 || line >= len(r.lines) {
+			return 0, 0, false
+		}
+		if col = docCol - r.indent; col < 0 {
+			if len(r.lines[line]) > 0 {
+				return 0, 0, false
+			}
+			col = 0
+		}
+		if col > len(r.lines[line]) {
+			col = len(r.lines[line])
+		}
+		return line, col, true
+	}
+	if docLine != r.line || docCol < r.col || docCol > r.col+len(r.lines[0]) {
+		return 0, 0, false
+	}
+	return 0, docCol - r.col, true
+}
+
+// offset returns the rune offset within the mapping text of a position.
+func (r *mappingRegion) offset(line, col int) int {
+	off := 0
+	for i := 0; i < line && i < len(r.lines); i++ {
+		off += len(r.lines[i]) + 1
+	}
+	if line < len(r.lines) && col > len(r.lines[line]) {
+		col = len(r.lines[line])
+	}
+	return off + col
+}
+
+// position returns the line and column of a rune offset within the mapping
+// text.
+func (r *mappingRegion) position(offset int) (line, col int) { //nolint: gocritic // Ignore unnamedResult false positive
+	for i := 0; i < offset && i < len(r.text); i++ {
+		if r.text[i] == '\n' {
+			line++
+			col = 0
+		} else {
+			col++
+		}
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+
+func newLSPDocument(uri, languageID, text string, confSpec docs.FieldSpecs, prov docs.Provider) *lspDocument {
+	d := &lspDocument{
+		uri:        uri,
+		path:       uriToPath(uri),
+		languageID: languageID,
+	}
+	for _, l := range strings.Split(text, "\n") {
+		d.lines = append(d.lines, strings.TrimSuffix(l, "\r"))
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(d.path)); {
+	case languageID == "yaml", ext == ".yaml", ext == ".yml":
+		d.isYAML = true
+	}
+	if !d.isYAML {
+		d.regions = []*mappingRegion{newMappingRegion(strings.Join(d.lines, "\n"), 0, 0, true, 0)}
+		return d
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &node); err != nil {
+		return d
+	}
+
+	// Walking stops at the first component that cannot be inferred, in which
+	// case we still offer the mappings found up to that point.
+	_ = confSpec.WalkYAML(&node, prov, func(c docs.WalkedYAMLComponent) error {
+		spec, exists := prov.GetDocs(c.Name, c.ComponentType)
+		if !exists {
+			return nil
+		}
+		for i := 0; i < len(c.Conf.Content)-1; i += 2 {
+			if c.Conf.Content[i].Value == c.Name {
+				d.collectYAMLMappings(spec.Config, c.Conf.Content[i+1])
+			}
+		}
+		return nil
+	})
+	return d
+}
+
+func (d *lspDocument) collectYAMLMappings(f docs.FieldSpec, node *yaml.Node) {
+	if _, isCore := f.Type.IsCoreComponent(); isCore {
+		// Child components are walked separately.
+		return
+	}
+
+	switch f.Kind {
+	case docs.Kind2DArray, docs.KindArray:
+		inner := f
+		inner.Kind = docs.KindScalar
+		if f.Kind == docs.Kind2DArray {
+			inner.Kind = docs.KindArray
+		}
+		if node.Kind == yaml.SequenceNode {
+			for _, c := range node.Content {
+				d.collectYAMLMappings(inner, c)
+			}
+		}
+		return
+	case docs.KindMap:
+		inner := f
+		inner.Kind = docs.KindScalar
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				d.collectYAMLMappings(inner, node.Content[i])
+			}
+		}
+		return
+	}
+
+	if f.Bloblang && node.Kind == yaml.ScalarNode {
+		if r := d.scalarRegion(node); r != nil {
+			d.regions = append(d.regions, r)
+		}
+		return
+	}
+
+	if len(f.Children) > 0 && node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			for _, child := range f.Children {
+				if child.Name == node.Content[i].Value {
+					d.collectYAMLMappings(child, node.Content[i+1])
+				}
+			}
+		}
+	}
+}
+
+func (d *lspDocument) scalarRegion(node *yaml.Node) *mappingRegion {
+	if node.Value == "" {
+		return nil
+	}
+	r := d.scalarRegionPosition(node)
+	if !d.matchesSource(r) {
+		// Folded line breaks and escape sequences mean that the value of the
+		// scalar differs from its source, and therefore positions within the
+		// mapping cannot be mapped to the document.
+		return nil
+	}
+	r.style = node.Style
+	return r
+}
+
+// matchesSource returns true if each line of a region is found verbatim at
+// its position within the document.
+func (d *lspDocument) matchesSource(r *mappingRegion) bool {
+	if !r.block && len(r.lines) > 1 {
+		return false
+	}
+	for i, l := range r.lines {
+		if len(l) == 0 {
+			continue
+		}
+		docLine, docCol := r.toDocument(i, 0)
+		if docLine >= len(d.lines) {
+			return false
+		}
+		src := []rune(d.lines[docLine])
+		if docCol+len(l) > len(src) || string(src[docCol:docCol+len(l)]) != string(l) {
+			return false
+		}
+		if r.block && strings.TrimLeft(string(src[:docCol]), " \t") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *lspDocument) scalarRegionPosition(node *yaml.Node) *mappingRegion {
+	switch node.Style {
+	case yaml.LiteralStyle, yaml.FoldedStyle:
+		// Block scalars begin on the line following the indicator, and are
+		// indented by the amount of the first non-empty line.
+		indent := 0
+		for i := node.Line; i < len(d.lines); i++ {
+			if trimmed := strings.TrimLeft(d.lines[i], " \t"); trimmed != "" {
+				indent = len([]rune(d.lines[i])) - len([]rune(trimmed))
+				break
+			}
+		}
+		return newMappingRegion(node.Value, node.Line, 0, true, indent)
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		return newMappingRegion(node.Value, node.Line-1, node.Column, false, 0)
+	}
+	return newMappingRegion(node.Value, node.Line-1, node.Column-1, false, 0)
+}
+
+// region returns the mapping region found at a document position along with
+// the rune offset of the position within the mapping.
+func (d *lspDocument) region(pos lspPosition) (*mappingRegion, int, bool) {
+	line, col := d.fromLSP(pos)
+	for _, r := range d.regions {
+		if rLine, rCol, ok := r.fromDocument(line, col); ok {
+			return r, r.offset(rLine, rCol), true
+		}
+	}
+	return nil, 0, false
+}
+
+// toLSP converts a line and rune column of the document into a position with a
+// UTF-16 encoded character offset.
+func (d *lspDocument) toLSP(line, col int) lspPosition {
+	return lspPosition{Line: line, Character: utf16Len(d.lineRunes(line), col)}
+}
+
+// fromLSP converts a position with a UTF-16 encoded character offset to a line
+// and rune column of the document.
+func (d *lspDocument) fromLSP(pos lspPosition) (line, col int) { //nolint: gocritic // Ignore unnamedResult false positive
+	runes := d.lineRunes(pos.Line)
+	units := 0
+	for col < len(runes) && units < pos.Character {
+		units += utf16.RuneLen(runes[col])
+		col++
+	}
+	return pos.Line, col
+}
+
+func (d *lspDocument) lineRunes(line int) []rune {
+	if line < 0 || line >= len(d.lines) {
+		return nil
+	}
+	return []rune(d.lines[line])
+}
+
+func (d *lspDocument) regionRange(r *mappingRegion, start, end int) lspRange {
+	sLine, sCol := r.toDocument(r.position(start))
+	eLine, eCol := r.toDocument(r.position(end))
+	return lspRange{Start: d.toLSP(sLine, sCol), End: d.toLSP(eLine, eCol)}
+}
+
+func utf16Len(runes []rune, n int) int {
+	if n > len(runes) {
+		n = len(runes)
+	}
+	units := 0
+	for _, r := range runes[:n] {
+		if l := utf16.RuneLen(r); l > 0 {
+			units += l
+		} else {
+			units++
+		}
+	}
+	return units
+}
+
+//------------------------------------------------------------------------------
+
+func (s *lspServer) diagnostics(d *lspDocument) []lspDiagnostic {
+	env := s.env
+	if !d.isYAML && d.path != "" {
+		env = env.WithImporterRelativeToFile(d.path)
+	}
+
+	diags := []lspDiagnostic{}
+	for _, r := range d.regions {
+		_, err := env.NewMapping(string(r.text))
+		if err == nil {
+			continue
+		}
+
+		diag := lspDiagnostic{
+			Severity: 1, // Error
+			Source:   "bloblang",
+			Message:  err.Error(),
+		}
+
+		var pErr *parser.Error
+		if errors.As(err, &pErr) {
+			diag.Message = pErr.ErrorWithoutPosition()
+			start := len(r.text) - len(pErr.Input)
+			end := start
+			if end < len(r.text) && r.text[end] != '\n' {
+				end++
+			}
+			diag.Range = d.regionRange(r, start, end)
+		} else {
+			diag.Range = d.regionRange(r, 0, 0)
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+//------------------------------------------------------------------------------
+
+var (
+	mapDefinitionRegexp = regexp.MustCompile(`(?m)^[ \t]*map[ \t]+([a-zA-Z0-9_]+)(\([^)]*\))?`)
+	importRegexp        = regexp.MustCompile(`(?m)^[ \t]*import[ \t]+"((?:[^"\\]|\\.)*)"`)
+	letRegexp           = regexp.MustCompile(`(?m)^[ \t]*let[ \t]+([a-zA-Z0-9_]+)`)
+)
+
+var bloblangKeywords = []string{"root", "this", "let", "map", "import", "if", "else", "match", "meta", "from"}
+
+// mapDefinition describes a map declared within a mapping, or within a file
+// imported by the mapping.
+type mapDefinition struct {
+	name     string
+	params   []string
+	callable bool
+	location lspLocation
+}
+
+func (m mapDefinition) signature() string {
+	if !m.callable {
+		return m.name
+	}
+	return m.name + "(" + strings.Join(m.params, ", ") + ")"
+}
+
+// newMapDefinition creates a map definition from a match of
+// mapDefinitionRegexp at the given submatch indexes of a text.
+func newMapDefinition(text string, idx []int) mapDefinition {
+	m := mapDefinition{name: text[idx[2]:idx[3]]}
+	if idx[4] >= 0 {
+		m.callable = true
+		for _, p := range strings.Split(strings.Trim(text[idx[4]:idx[5]], "()"), ",") {
+			if p = strings.Join(strings.Fields(p), " "); p != "" {
+				m.params = append(m.params, p)
+			}
+		}
+	}
+	return m
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// runeOffset converts a byte offset of a string into a rune offset.
+func runeOffset(str string, byteOffset int) int {
+	return len([]rune(str[:byteOffset]))
+}
+
+// importDir returns the directory that relative imports of a document are
+// resolved from.
+func (s *lspServer) importDir(d *lspDocument) string {
+	if !d.isYAML && d.path != "" {
+		return filepath.Dir(d.path)
+	}
+	if s.rootPath != "" {
+		return s.rootPath
+	}
+	wd, _ := os.Getwd()
+	return wd
+}
+
+func (s *lspServer) readFile(path string) (string, error) {
+	for _, d := range s.docs {
+		if d.path == path && !d.isYAML {
+			return strings.Join(d.lines, "\n"), nil
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// maps returns all maps that are visible to a mapping region, including those
+// declared within imported files.
+func (s *lspServer) maps(d *lspDocument, r *mappingRegion) []mapDefinition {
+	text := string(r.text)
+
+	var defs []mapDefinition
+	for _, idx := range mapDefinitionRegexp.FindAllStringSubmatchIndex(text, -1) {
+		m := newMapDefinition(text, idx)
+		m.location = lspLocation{
+			URI:   d.uri,
+			Range: d.regionRange(r, runeOffset(text, idx[2]), runeOffset(text, idx[3])),
+		}
+		defs = append(defs, m)
+	}
+
+	seen := map[string]struct{}{}
+	for _, match := range importRegexp.FindAllStringSubmatch(text, -1) {
+		defs = append(defs, s.importedMaps(resolveImport(s.importDir(d), match[1]), seen)...)
+	}
+	return defs
+}
+
+func (s *lspServer) importedMaps(path string, seen map[string]struct{}) []mapDefinition {
+	if _, exists := seen[path]; exists {
+		return nil
+	}
+	seen[path] = struct{}{}
+
+	text, err := s.readFile(path)
+	if err != nil {
+		return nil
+	}
+
+	// Imported files are treated as documents consisting of a single mapping.
+	fileDoc := newLSPDocument(pathToURI(path), "", text, nil, nil)
+	r := fileDoc.regions[0]
+
+	var defs []mapDefinition
+	for _, idx := range mapDefinitionRegexp.FindAllStringSubmatchIndex(text, -1) {
+		m := newMapDefinition(text, idx)
+		m.location = lspLocation{
+			URI:   fileDoc.uri,
+			Range: fileDoc.regionRange(r, runeOffset(text, idx[2]), runeOffset(text, idx[3])),
+		}
+		defs = append(defs, m)
+	}
+	for _, match := range importRegexp.FindAllStringSubmatch(text, -1) {
+		defs = append(defs, s.importedMaps(resolveImport(filepath.Dir(path), match[1]), seen)...)
+	}
+	return defs
+}
+
+func resolveImport(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func findMap(defs []mapDefinition, name string) (mapDefinition, bool) {
+	for _, m := range defs {
+		if m.name == name {
+			return m, true
+		}
+	}
+	return mapDefinition{}, false
+}
+
+// wordAt returns the bounds of an identifier at a rune offset.
+func wordAt(text []rune, offset int) (start, end int) { //nolint: gocritic // Ignore unnamedResult false positive
+	start, end = offset, offset
+	for start > 0 && isIdentRune(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isIdentRune(text[end]) {
+		end++
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+
+func functionSignature(name string, params query.Params) string {
+	if params.Variadic {
+		return name + "(...)"
+	}
+	var args []string
+	for _, p := range params.Definitions {
+		args = append(args, p.Name)
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func paramsMarkdown(params query.Params) string {
+	if len(params.Definitions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n**Parameters**\n")
+	for _, p := range params.Definitions {
+		fmt.Fprintf(&b, "\n- `%v` <%v>", p.Name, p.ValueType)
+		if def := p.PrettyDefault(); def != "" {
+			fmt.Fprintf(&b, " (default `%v`)", def)
+		}
+		if p.Description != "" {
+			b.WriteString(" " + p.Description)
+		}
+	}
+	return b.String()
+}
+
+func methodDescription(spec query.MethodSpec) string {
+	if spec.Description != "" {
+		return spec.Description
+	}
+	for _, c := range spec.Categories {
+		if c.Description != "" {
+			return c.Description
+		}
+	}
+	return ""
+}
+
+func (s *lspServer) functionMarkdown(spec query.FunctionSpec) *lspMarkupContent {
+	return &lspMarkupContent{
+		Kind:  "markdown",
+		Value: "```coffee\n" + functionSignature(spec.Name, spec.Params) + "\n```\n\n" + strings.TrimSpace(spec.Description) + paramsMarkdown(spec.Params),
+	}
+}
+
+func (s *lspServer) methodMarkdown(spec query.MethodSpec) *lspMarkupContent {
+	return &lspMarkupContent{
+		Kind:  "markdown",
+		Value: "```coffee\n." + functionSignature(spec.Name, spec.Params) + "\n```\n\n" + strings.TrimSpace(methodDescription(spec)) + paramsMarkdown(spec.Params),
+	}
+}
+
+func mapMarkdown(m mapDefinition) *lspMarkupContent {
+	return &lspMarkupContent{
+		Kind:  "markdown",
+		Value: "```coffee\nmap " + m.signature() + "\n```",
+	}
+}
+
+func (s *lspServer) completion(d *lspDocument, pos lspPosition) any {
+	r, off, ok := d.region(pos)
+	if !ok {
+		return nil
+	}
+
+	start, _ := wordAt(r.text, off)
+	prefix := string(r.text[start:off])
+
+	items := []lspCompletionItem{}
+	var prev rune
+	if start > 0 {
+		prev = r.text[start-1]
+	}
+
+	switch prev {
+	case '$':
+		seen := map[string]struct{}{}
+		for _, match := range letRegexp.FindAllStringSubmatch(string(r.text), -1) {
+			if _, exists := seen[match[1]]; exists || !strings.HasPrefix(match[1], prefix) {
+				continue
+			}
+			seen[match[1]] = struct{}{}
+			items = append(items, lspCompletionItem{Label: match[1], Kind: lspCompletionVariable})
+		}
+		return items
+	case '@':
+		return items
+	}
+
+	isMethod := prev == '.'
+	for _, m := range s.maps(d, r) {
+		if m.callable && strings.HasPrefix(m.name, prefix) {
+			items = append(items, lspCompletionItem{
+				Label:         m.name,
+				Kind:          lspCompletionFunction,
+				Detail:        "map " + m.signature(),
+				Documentation: mapMarkdown(m),
+			})
+		}
+	}
+
+	if isMethod {
+		for _, name := range s.methodNames {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			spec := s.methods[name]
+			items = append(items, lspCompletionItem{
+				Label:         name,
+				Kind:          lspCompletionMethod,
+				Detail:        functionSignature(name, spec.Params),
+				Documentation: s.methodMarkdown(spec),
+			})
+		}
+		return items
+	}
+
+	for _, name := range s.functionNames {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		spec := s.functions[name]
+		items = append(items, lspCompletionItem{
+			Label:         name,
+			Kind:          lspCompletionFunction,
+			Detail:        functionSignature(name, spec.Params),
+			Documentation: s.functionMarkdown(spec),
+		})
+	}
+	for _, k := range bloblangKeywords {
+		if strings.HasPrefix(k, prefix) {
+			items = append(items, lspCompletionItem{Label: k, Kind: lspCompletionKeyword})
+		}
+	}
+	return items
+}
+
+func (s *lspServer) hover(d *lspDocument, pos lspPosition) any {
+	r, off, ok := d.region(pos)
+	if !ok {
+		return nil
+	}
+
+	start, end := wordAt(r.text, off)
+	if start == end {
+		return nil
+	}
+	name := string(r.text[start:end])
+	isMethod := start > 0 && r.text[start-1] == '.'
+	isCall := end < len(r.text) && r.text[end] == '('
+
+	var contents *lspMarkupContent
+	if m, exists := findMap(s.maps(d, r), name); exists && (isCall || !isMethod) {
+		contents = mapMarkdown(m)
+	} else if isMethod {
+		if spec, exists := s.methods[name]; exists && isCall {
+			contents = s.methodMarkdown(spec)
+		}
+	} else if spec, exists := s.functions[name]; exists && isCall {
+		contents = s.functionMarkdown(spec)
+	}
+	if contents == nil {
+		return nil
+	}
+	return map[string]any{
+		"contents": contents,
+		"range":    d.regionRange(r, start, end),
+	}
+}
+
+type openCall struct {
+	name     string
+	isMethod bool
+	isParen  bool
+	commas   int
+}
+
+// openCallAt scans a mapping up to an offset and returns the innermost
+// function or method call that has not yet been closed.
+func openCallAt(text []rune, offset int) (openCall, bool) {
+	var stack []openCall
+	var quote rune
+	for i := 0; i < offset && i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			quote = c
+		case '#':
+			for i < offset && i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case '(':
+			start, _ := wordAt(text, i)
+			stack = append(stack, openCall{
+				name:     string(text[start:i]),
+				isMethod: start > 0 && text[start-1] == '.',
+				isParen:  true,
+			})
+		case '[', '{':
+			stack = append(stack, openCall{})
+		case ')', ']', '}':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			if len(stack) > 0 {
+				stack[len(stack)-1].commas++
+			}
+		}
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].isParen {
+			return stack[i], stack[i].name != ""
+		}
+	}
+	return openCall{}, false
+}
+
+func (s *lspServer) signatureHelp(d *lspDocument, pos lspPosition) any {
+	r, off, ok := d.region(pos)
+	if !ok {
+		return nil
+	}
+
+	call, ok := openCallAt(r.text, off)
+	if !ok {
+		return nil
+	}
+
+	var sig lspSignatureInformation
+	if m, exists := findMap(s.maps(d, r), call.name); exists && m.callable {
+		sig = lspSignatureInformation{
+			Label:         m.signature(),
+			Documentation: mapMarkdown(m),
+		}
+		for _, p := range m.params {
+			sig.Parameters = append(sig.Parameters, lspParameterInformation{Label: p})
+		}
+	} else {
+		var params query.Params
+		if call.isMethod {
+			spec, exists := s.methods[call.name]
+			if !exists {
+				return nil
+			}
+			params = spec.Params
+			sig.Documentation = s.methodMarkdown(spec)
+		} else {
+			spec, exists := s.functions[call.name]
+			if !exists {
+				return nil
+			}
+			params = spec.Params
+			sig.Documentation = s.functionMarkdown(spec)
+		}
+		sig.Label = functionSignature(call.name, params)
+		for _, p := range params.Definitions {
+			sig.Parameters = append(sig.Parameters, lspParameterInformation{
+				Label:         p.Name,
+				Documentation: p.Description,
+			})
+		}
+	}
+	if sig.Parameters == nil {
+		sig.Parameters = []lspParameterInformation{}
+	}
+
+	return map[string]any{
+		"signatures":      []lspSignatureInformation{sig},
+		"activeSignature": 0,
+		"activeParameter": call.commas,
+	}
+}
+
+func (s *lspServer) definition(d *lspDocument, pos lspPosition) any {
+	r, off, ok := d.region(pos)
+	if !ok {
+		return nil
+	}
+
+	// Jump to the file of an import statement.
+	line, _ := r.position(off)
+	if match := importRegexp.FindStringSubmatch(string(r.lines[line])); match != nil {
+		return lspLocation{
+			URI: pathToURI(resolveImport(s.importDir(d), match[1])),
+		}
+	}
+
+	start, end := wordAt(r.text, off)
+	if start == end {
+		return nil
+	}
+
+	if m, exists := findMap(s.maps(d, r), string(r.text[start:end])); exists {
+		return m.location
+	}
+	return nil
+}
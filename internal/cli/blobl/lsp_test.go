@@ -0,0 +1,386 @@
+package blobl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+type lspTestClient struct {
+	t      *testing.T
+	server *lspServer
+	out    *bytes.Buffer
+	nextID int
+}
+
+// testConfigDocs returns a config spec and provider with a minimal set of
+// components containing Bloblang fields.
+func testConfigDocs() (docs.FieldSpecs, docs.Provider) {
+	prov := docs.NewMappedDocsProvider()
+	prov.RegisterDocs(docs.ComponentSpec{
+		Name:   "mapping",
+		Type:   docs.TypeProcessor,
+		Config: docs.FieldBloblang("", ""),
+	})
+	prov.RegisterDocs(docs.ComponentSpec{
+		Name: "branch",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldBloblang("request_map", "").HasDefault(""),
+			docs.FieldProcessor("processors", "").Array(),
+			docs.FieldBloblang("result_map", "").HasDefault(""),
+		),
+	})
+	confSpec := docs.FieldSpecs{
+		docs.FieldObject("pipeline", "").WithChildren(
+			docs.FieldProcessor("processors", "").Array(),
+		),
+	}
+	return confSpec, prov
+}
+
+func newLSPTestClient(t *testing.T) *lspTestClient {
+	t.Helper()
+
+	confSpec, prov := testConfigDocs()
+	c := &lspTestClient{
+		t:      t,
+		server: newLSPServer(bloblang.GlobalEnvironment().Deactivated(), confSpec, prov),
+		out:    &bytes.Buffer{},
+	}
+	c.server.out = c.out
+	return c
+}
+
+func (c *lspTestClient) request(method string, params any) any {
+	c.t.Helper()
+
+	c.nextID++
+	paramBytes, err := json.Marshal(params)
+	require.NoError(c.t, err)
+
+	id := json.RawMessage(fmt.Sprintf("%d", c.nextID))
+	res, rErr := c.server.handle(lspRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: paramBytes})
+	require.Nil(c.t, rErr)
+
+	// Normalise the result into generic JSON values.
+	resBytes, err := json.Marshal(res)
+	require.NoError(c.t, err)
+
+	var v any
+	require.NoError(c.t, json.Unmarshal(resBytes, &v))
+	return v
+}
+
+func (c *lspTestClient) open(uri, text string) []any {
+	c.t.Helper()
+
+	c.out.Reset()
+	_ = c.request("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "", "version": 1, "text": text},
+	})
+
+	body, err := readLSPMessage(bufio.NewReader(c.out))
+	require.NoError(c.t, err)
+
+	var notif struct {
+		Method string `json:"method"`
+		Params struct {
+			URI         string `json:"uri"`
+			Diagnostics []any  `json:"diagnostics"`
+		} `json:"params"`
+	}
+	require.NoError(c.t, json.Unmarshal(body, &notif))
+	assert.Equal(c.t, "textDocument/publishDiagnostics", notif.Method)
+	assert.Equal(c.t, uri, notif.Params.URI)
+	return notif.Params.Diagnostics
+}
+
+func positionParams(uri string, line, char int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": char},
+	}
+}
+
+func lspRangeOf(startLine, startChar, endLine, endChar int) map[string]any {
+	return map[string]any{
+		"start": map[string]any{"line": float64(startLine), "character": float64(startChar)},
+		"end":   map[string]any{"line": float64(endLine), "character": float64(endChar)},
+	}
+}
+
+func completionLabels(t *testing.T, v any) []string {
+	t.Helper()
+
+	items, ok := v.([]any)
+	require.True(t, ok, "%T", v)
+
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.(map[string]any)["label"].(string))
+	}
+	return labels
+}
+
+func TestLSPServe(t *testing.T) {
+	var in bytes.Buffer
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"rootUri":"file:///tmp"}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"workspace/symbol","params":{}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, newLSPTestClient(t).server.serve(&in, &out))
+
+	r := bufio.NewReader(&out)
+
+	var responses []map[string]any
+	for {
+		body, err := readLSPMessage(r)
+		if err != nil {
+			break
+		}
+		var res map[string]any
+		require.NoError(t, json.Unmarshal(body, &res))
+		responses = append(responses, res)
+	}
+	require.Len(t, responses, 3)
+
+	caps := responses[0]["result"].(map[string]any)["capabilities"].(map[string]any)
+	assert.Equal(t, true, caps["hoverProvider"])
+	assert.Equal(t, true, caps["definitionProvider"])
+
+	assert.Equal(t, float64(lspMethodNotFound), responses[1]["error"].(map[string]any)["code"])
+
+	assert.Equal(t, float64(3), responses[2]["id"])
+	assert.Contains(t, responses[2], "result")
+	assert.Nil(t, responses[2]["result"])
+}
+
+func TestLSPDiagnosticsBlobl(t *testing.T) {
+	c := newLSPTestClient(t)
+
+	diags := c.open("file:///tmp/foo.blobl", `root.foo = this.foo
+root.bar = this.bar.uppercase(
+`)
+	require.Len(t, diags, 1)
+	diag := diags[0].(map[string]any)
+	assert.Equal(t, lspRangeOf(2, 0, 2, 0), diag["range"])
+	assert.Equal(t, "required: expected function argument", diag["message"])
+
+	diags = c.open("file:///tmp/foo.blobl", `root.foo = this.foo.nope()`)
+	require.Len(t, diags, 1)
+	diag = diags[0].(map[string]any)
+	assert.Equal(t, lspRangeOf(0, 26, 0, 26), diag["range"])
+	assert.Equal(t, "unrecognised method 'nope'", diag["message"])
+
+	diags = c.open("file:///tmp/foo.blobl", `root.foo = "🦊" + this.foo.nope()`)
+	require.Len(t, diags, 1)
+	// The fox emoji is two UTF-16 code units.
+	assert.Equal(t, lspRangeOf(0, 33, 0, 33), diags[0].(map[string]any)["range"])
+
+	diags = c.open("file:///tmp/foo.blobl", `root.foo = this.foo.uppercase()`)
+	assert.Empty(t, diags)
+}
+
+func TestLSPDiagnosticsYAML(t *testing.T) {
+	c := newLSPTestClient(t)
+
+	diags := c.open("file:///tmp/config.yaml", `pipeline:
+  processors:
+    - mapping: |
+        root.foo = this.foo
+        root.bar = this.bar.nope()
+    - branch:
+        request_map: 'root = this.nah()'
+        processors:
+          - mapping: root = this.also_nope()
+`)
+	require.Len(t, diags, 3)
+	assert.Equal(t, lspRangeOf(4, 34, 4, 34), diags[0].(map[string]any)["range"])
+	assert.Equal(t, lspRangeOf(6, 39, 6, 39), diags[1].(map[string]any)["range"])
+	assert.Equal(t, lspRangeOf(8, 44, 8, 44), diags[2].(map[string]any)["range"])
+}
+
+func TestLSPDocumentRegionsMatchSource(t *testing.T) {
+	confSpec, prov := testConfigDocs()
+
+	d := newLSPDocument("file:///tmp/config.yaml", "yaml", `pipeline:
+  processors:
+    - mapping: |
+        root.foo = this.foo
+
+        root.bar = this.bar
+    - mapping: >
+        root.foo = this.foo
+        root.bar = this.bar
+    - mapping: >
+        root = this.single_line
+    - mapping: "root = \"escaped\""
+    - mapping: 'root = ''escaped'''
+    - mapping: 'root = "not escaped"'
+    - mapping: root = this
+        .folded_plain
+`, confSpec, prov)
+
+	var texts []string
+	for _, r := range d.regions {
+		texts = append(texts, string(r.text))
+	}
+	assert.Equal(t, []string{
+		"root.foo = this.foo\n\nroot.bar = this.bar\n",
+		"root = this.single_line\n",
+		`root = "not escaped"`,
+	}, texts)
+}
+
+func TestLSPCompletion(t *testing.T) {
+	c := newLSPTestClient(t)
+
+	uri := "file:///tmp/foo.blobl"
+	_ = c.open(uri, `map greet(name, punctuation = "!") {
+  root = "hello " + $name + $punctuation
+}
+let thing = "foo"
+root.a = this.foo.upp
+root.b = gre
+root.c = $th
+root.d = this.foo.gr`)
+
+	labels := completionLabels(t, c.request("textDocument/completion", positionParams(uri, 4, 21)))
+	assert.Contains(t, labels, "uppercase")
+	assert.NotContains(t, labels, "lowercase")
+	assert.NotContains(t, labels, "uuid_v4")
+
+	labels = completionLabels(t, c.request("textDocument/completion", positionParams(uri, 5, 12)))
+	assert.Equal(t, []string{"greet"}, labels)
+
+	labels = completionLabels(t, c.request("textDocument/completion", positionParams(uri, 6, 12)))
+	assert.Equal(t, []string{"thing"}, labels)
+
+	labels = completionLabels(t, c.request("textDocument/completion", positionParams(uri, 7, 20)))
+	assert.Contains(t, labels, "greet")
+
+	labels = completionLabels(t, c.request("textDocument/completion", positionParams(uri, 5, 9)))
+	assert.Contains(t, labels, "now")
+	assert.Contains(t, labels, "root")
+	assert.NotContains(t, labels, "uppercase")
+}
+
+func TestLSPCompletionYAML(t *testing.T) {
+	c := newLSPTestClient(t)
+
+	uri := "file:///tmp/config.yaml"
+	_ = c.open(uri, `pipeline:
+  processors:
+    - mapping: |
+        root = this.foo.uppe
+`)
+
+	labels := completionLabels(t, c.request("textDocument/completion", positionParams(uri, 3, 28)))
+	assert.Equal(t, []string{"uppercase"}, labels)
+
+	assert.Nil(t, c.request("textDocument/completion", positionParams(uri, 1, 4)))
+}
+
+func TestLSPHoverAndSignatureHelp(t *testing.T) {
+	c := newLSPTestClient(t)
+
+	uri := "file:///tmp/foo.blobl"
+	_ = c.open(uri, `map greet(name, punctuation = "!") {
+  root = "hello " + $name + $punctuation
+}
+root.a = this.foo.replace_all("a", )
+root.b = greet("bob", [ 1, 2 ], )
+root.c = this.foo`)
+
+	hover := c.request("textDocument/hover", positionParams(uri, 3, 20)).(map[string]any)
+	contents := hover["contents"].(map[string]any)["value"].(string)
+	assert.True(t, strings.HasPrefix(contents, "```coffee\n.replace_all(old, new)\n```"), contents)
+	assert.Equal(t, lspRangeOf(3, 18, 3, 29), hover["range"])
+
+	hover = c.request("textDocument/hover", positionParams(uri, 4, 10)).(map[string]any)
+	assert.Equal(t, "```coffee\nmap greet(name, punctuation = \"!\")\n```", hover["contents"].(map[string]any)["value"])
+
+	assert.Nil(t, c.request("textDocument/hover", positionParams(uri, 5, 16)))
+
+	sig := c.request("textDocument/signatureHelp", positionParams(uri, 3, 35)).(map[string]any)
+	assert.Equal(t, float64(1), sig["activeParameter"])
+	assert.Equal(t, "replace_all(old, new)", sig["signatures"].([]any)[0].(map[string]any)["label"])
+
+	sig = c.request("textDocument/signatureHelp", positionParams(uri, 4, 32)).(map[string]any)
+	assert.Equal(t, float64(2), sig["activeParameter"])
+	assert.Equal(t, "greet(name, punctuation = \"!\")", sig["signatures"].([]any)[0].(map[string]any)["label"])
+
+	assert.Nil(t, c.request("textDocument/signatureHelp", positionParams(uri, 5, 16)))
+}
+
+func TestLSPDefinition(t *testing.T) {
+	dir := t.TempDir()
+
+	libPath := filepath.Join(dir, "lib", "common.blobl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(libPath), 0o755))
+	require.NoError(t, os.WriteFile(libPath, []byte(`import "./nested.blobl"
+
+map shout(suffix = "!") {
+  root = this.uppercase() + $suffix
+}`), 0o644))
+
+	nestedPath := filepath.Join(dir, "lib", "nested.blobl")
+	require.NoError(t, os.WriteFile(nestedPath, []byte(`map whisper {
+  root = this.lowercase()
+}`), 0o644))
+
+	c := newLSPTestClient(t)
+
+	uri := pathToURI(filepath.Join(dir, "main.blobl"))
+	diags := c.open(uri, `import "./lib/common.blobl"
+
+map local(a) {
+  root = $a
+}
+
+root.a = this.foo.shout()
+root.b = this.bar.apply("whisper")
+root.c = local(this.baz)`)
+	assert.Empty(t, diags)
+
+	assert.Equal(t, map[string]any{
+		"uri":   pathToURI(libPath),
+		"range": lspRangeOf(2, 4, 2, 9),
+	}, c.request("textDocument/definition", positionParams(uri, 6, 20)))
+
+	assert.Equal(t, map[string]any{
+		"uri":   pathToURI(nestedPath),
+		"range": lspRangeOf(0, 4, 0, 11),
+	}, c.request("textDocument/definition", positionParams(uri, 7, 27)))
+
+	assert.Equal(t, map[string]any{
+		"uri":   uri,
+		"range": lspRangeOf(2, 4, 2, 9),
+	}, c.request("textDocument/definition", positionParams(uri, 8, 11)))
+
+	assert.Equal(t, map[string]any{
+		"uri":   pathToURI(libPath),
+		"range": lspRangeOf(0, 0, 0, 0),
+	}, c.request("textDocument/definition", positionParams(uri, 0, 10)))
+
+	assert.Nil(t, c.request("textDocument/definition", positionParams(uri, 6, 10)))
+}
@@ -12,6 +12,7 @@ class BloblangPlayground {
       executionTimeout: null,
       inputFormatMode: "format", // "format" or "minify"
       outputFormatMode: "minify", // "format" or "minify"
+      traceEnabled: false,
       firstExecutionStartTime: null,
       CONNECTION_ERROR_DELAY: 3000, // 3 seconds before showing connection errors
     };
@@ -19,12 +20,14 @@ class BloblangPlayground {
     this.elements = {
       loadingOverlay: document.getElementById("loadingOverlay"),
       outputArea: document.getElementById("output"),
+      traceArea: document.getElementById("trace"),
       inputPanel: document.getElementById("inputPanel"),
       mappingPanel: document.getElementById("mappingPanel"),
       inputFileInput: document.getElementById("inputFileInput"),
       mappingFileInput: document.getElementById("mappingFileInput"),
       toggleFormatInputBtn: document.getElementById("toggleFormatInputBtn"),
       toggleFormatOutputBtn: document.getElementById("toggleFormatOutputBtn"),
+      toggleTraceBtn: document.getElementById("toggleTraceBtn"),
     };
 
     this.editor = new EditorManager(
@@ -161,6 +164,7 @@ class BloblangPlayground {
       "format-mapping": () => formatBloblang(),
       "toggle-format-input": () => this.toggleFormat("input"),
       "toggle-format-output": () => this.toggleFormat("output"),
+      "toggle-trace": () => this.toggleTrace(),
     };
 
     actions[action]?.();
@@ -178,12 +182,13 @@ class BloblangPlayground {
     try {
       const input = this.editor.getInput();
       const mapping = this.editor.getMapping();
+      const trace = this.state.traceEnabled;
 
       let result;
       switch (this.state.executionMode) {
         case "wasm":
           if (this.wasm) {
-            result = this.wasm.execute(input, mapping);
+            result = this.wasm.execute(input, mapping, trace);
             this.handleExecution(result);
           } else {
             throw new Error("WASM not available");
@@ -193,7 +198,7 @@ class BloblangPlayground {
           const response = await fetch("/execute", {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({ input, mapping }),
+            body: JSON.stringify({ input, mapping, trace }),
           });
 
           if (response.ok) {
@@ -228,9 +233,11 @@ class BloblangPlayground {
   handleExecution(response) {
     this.resetErrorStates();
 
-    const { result, mapping_error, parse_error } = response;
+    const { result, mapping_error, parse_error, trace } = response;
     let mappingErrorMessage = null;
 
+    this.renderTrace(trace);
+
     if (result && result.length > 0) {
       this.elements.outputArea.classList.add("success");
       this.ui.updateStatus("outputStatus", "success", "Success");
@@ -286,6 +293,56 @@ class BloblangPlayground {
     }
   }
 
+  toggleTrace() {
+    this.state.traceEnabled = !this.state.traceEnabled;
+    const btn = this.elements.toggleTraceBtn;
+    btn.classList.toggle("active", this.state.traceEnabled);
+    btn.setAttribute("aria-pressed", String(this.state.traceEnabled));
+    if (!this.state.traceEnabled) {
+      this.renderTrace(null);
+    }
+    this.execute();
+  }
+
+  renderTrace(trace) {
+    const { traceArea } = this.elements;
+    traceArea.innerHTML = "";
+    if (!this.state.traceEnabled || !trace || trace.length === 0) {
+      traceArea.classList.add("hidden");
+      return;
+    }
+    traceArea.classList.remove("hidden");
+
+    for (const entry of trace) {
+      const item = document.createElement("div");
+      item.className = entry.error ? "trace-entry error" : "trace-entry";
+
+      const header = document.createElement("div");
+      header.className = "trace-header";
+      header.textContent = `line ${entry.line}: ${entry.statement}`;
+      const duration = document.createElement("span");
+      duration.className = "trace-duration";
+      duration.textContent = entry.duration;
+      header.appendChild(duration);
+      item.appendChild(header);
+
+      const addDetail = (label, text) => {
+        const detail = document.createElement("div");
+        detail.className = "trace-detail";
+        detail.textContent = `${label}: ${text}`;
+        item.appendChild(detail);
+      };
+      for (const branch of entry.branches || []) {
+        addDetail("branch", `${branch.expression} took ${branch.branch}`);
+      }
+      if (entry.value) addDetail("value", entry.value);
+      if (entry.vars) addDetail("vars", JSON.stringify(entry.vars));
+      if (entry.error) addDetail("error", entry.error);
+
+      traceArea.appendChild(item);
+    }
+  }
+
   handleFileLoad(event, type) {
     const file = event.target.files[0];
     if (!file) return;
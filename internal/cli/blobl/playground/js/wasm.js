@@ -77,7 +77,7 @@ class WasmManager {
     });
   }
 
-  execute(input, mapping) {
+  execute(input, mapping, trace = false) {
     if (this.failed) {
       throw new Error(
         "WASM not loaded. Bloblang functionality is unavailable."
@@ -89,7 +89,7 @@ class WasmManager {
     }
 
     if (window.executeBloblangMapping) {
-      return window.executeBloblangMapping(input, mapping);
+      return window.executeBloblangMapping(input, mapping, trace);
     } else {
       throw new Error("Bloblang functionality not available in WASM context.");
     }
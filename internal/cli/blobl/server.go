@@ -180,6 +180,7 @@ func runPlayground(c *cli.Context) error {
 		req := struct {
 			Mapping string `json:"mapping"`
 			Input   string `json:"input"`
+			Trace   bool   `json:"trace"`
 		}{}
 		dec := json.NewDecoder(r.Body)
 		if err := dec.Decode(&req); err != nil {
@@ -188,17 +189,9 @@ func runPlayground(c *cli.Context) error {
 		}
 		fSync.update(req.Input, req.Mapping)
 
-		result := evaluateMapping(bloblang.GlobalEnvironment(), req.Input, req.Mapping)
+		result := evaluateMapping(bloblang.GlobalEnvironment(), req.Input, req.Mapping, req.Trace)
 
-		resBytes, err := json.Marshal(struct {
-			Result       any `json:"result"`
-			ParseError   any `json:"parse_error"`
-			MappingError any `json:"mapping_error"`
-		}{
-			Result:       result.Result,
-			ParseError:   result.ParseError,
-			MappingError: result.MappingError,
-		})
+		resBytes, err := json.Marshal(result)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadGateway)
 			return
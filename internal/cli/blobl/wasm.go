@@ -15,14 +15,16 @@ import (
 // Arguments:
 //   - args[0]: input JSON string
 //   - args[1]: Bloblang mapping string
+//   - args[2]: optional boolean, when true a trace of the execution is returned
 //
 // Returns a JS object with:
 //   - "result":        the mapping result (any type, or nil on error)
 //   - "parse_error":   error message if input JSON could not be parsed, else nil
 //   - "mapping_error": error message if mapping failed, else nil
+//   - "trace":         the execution trace, when requested
 func ExecuteBloblangMapping() js.Func {
 	return js.FuncOf(func(_ js.Value, args []js.Value) any {
-		if len(args) != 2 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString {
+		if len(args) < 2 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString {
 			return toJS(map[string]any{
 				"mapping_error": "Invalid arguments: expected two strings (input, mapping)",
 				"parse_error":   nil,
@@ -31,13 +33,10 @@ func ExecuteBloblangMapping() js.Func {
 		}
 
 		input, mapping := args[0].String(), args[1].String()
-		result := evaluateMapping(bloblang.GlobalEnvironment(), input, mapping)
+		trace := len(args) > 2 && args[2].Type() == js.TypeBoolean && args[2].Bool()
+		result := evaluateMapping(bloblang.GlobalEnvironment(), input, mapping, trace)
 
-		return toJS(map[string]any{
-			"mapping_error": result.MappingError,
-			"parse_error":   result.ParseError,
-			"result":        result.Result,
-		})
+		return toJS(result)
 	})
 }
 
@@ -83,7 +83,9 @@ func CreateManager(
 		if streamsMode {
 			sanitSpec = config.SpecWithoutStream(cliOpts.MainConfigSpecCtor())
 		}
-		err = sanitSpec.SanitiseYAML(&sanitNode, sanitConf)
+		if err = sanitSpec.SanitiseYAML(&sanitNode, sanitConf); err == nil {
+			config.GlobalSecrets.RedactYAML(&sanitNode)
+		}
 	}
 	if err != nil {
 		err = fmt.Errorf("failed to generate sanitised config: %w", err)
@@ -106,6 +108,17 @@ func CreateManager(
 		manager.OptSetStreamsMode(streamsMode),
 	}, mgrOpts...)
 
+	var budgetLimit, streamQuota int64
+	if budgetLimit, err = conf.MemoryBudget.LimitBytes(); err != nil {
+		return
+	}
+	if streamQuota, err = conf.MemoryBudget.StreamQuotaBytes(); err != nil {
+		return
+	}
+	if budgetLimit > 0 || streamQuota > 0 {
+		mgrOpts = append(mgrOpts, manager.OptSetMemoryBudget(budgetLimit, streamQuota))
+	}
+
 	// Initialise processors with global error handling strategy
 	if conf.ErrorHandling.Log.Enabled {
 		mgrOpts = append(mgrOpts, manager.OptSetEnvironment(errorsampling.ErrorSamplingBundle(conf.ErrorHandling, bundle.GlobalEnvironment)))
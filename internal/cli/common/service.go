@@ -80,11 +80,22 @@ func RunService(c *cli.Context, cliOpts *CLIOpts, streamsMode bool) int {
 
 	// Create data streams.
 	watching := c.Bool("watcher")
+	reload := reloadOpts{
+		readyTimeout: c.Duration("watcher-ready-timeout"),
+	}
+	switch strategy := c.String("watcher-strategy"); strategy {
+	case "", "restart":
+	case "blue_green":
+		reload.blueGreen = true
+	default:
+		logger.Error("Watcher strategy '%v' not recognised, options are: restart, blue_green", strategy)
+		return 1
+	}
 	if streamsMode {
 		enableStreamsAPI := !c.Bool("no-api")
-		stoppableStream = initStreamsMode(cliOpts, strict, watching, enableStreamsAPI, confReader, stoppableManager.Manager())
+		stoppableStream = initStreamsMode(cliOpts, strict, watching, enableStreamsAPI, reload, confReader, stoppableManager.Manager())
 	} else {
-		stoppableStream, dataStreamClosedChan = initNormalMode(cliOpts, conf, strict, watching, confReader, stoppableManager.Manager())
+		stoppableStream, dataStreamClosedChan = initNormalMode(cliOpts, conf, strict, watching, reload, confReader, stoppableManager.Manager())
 	}
 
 	return RunManagerUntilStopped(c, conf, stoppableManager, stoppableStream, dataStreamClosedChan)
@@ -114,9 +125,20 @@ func DelayShutdown(ctx context.Context, duration time.Duration) error {
 	return nil
 }
 
+// reloadOpts determines how stream config changes detected by the watcher are
+// applied.
+type reloadOpts struct {
+	// When true updated streams are started alongside existing streams and
+	// take over consumption once ready, otherwise existing streams are stopped
+	// before their replacements are started.
+	blueGreen    bool
+	readyTimeout time.Duration
+}
+
 func initStreamsMode(
 	opts *CLIOpts,
 	strict, watching, enableAPI bool,
+	reload reloadOpts,
 	confReader *config.Reader,
 	mgr *manager.Type,
 ) Stoppable {
@@ -155,12 +177,21 @@ func initStreamsMode(
 	logger.Info(opts.ExecTemplate("Launching {{.ProductName}} in streams mode, use CTRL+C to close"))
 
 	if err := confReader.SubscribeStreamChanges(func(id string, newStreamConf *stream.Config) error {
-		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		timeout := time.Second * 30
+		if reload.blueGreen {
+			timeout += reload.readyTimeout
+		}
+		ctx, done := context.WithTimeout(context.Background(), timeout)
 		defer done()
 
 		var updateErr error
 		if newStreamConf != nil {
-			if updateErr = streamMgr.Update(ctx, id, *newStreamConf); updateErr != nil && errors.Is(updateErr, strmmgr.ErrStreamDoesNotExist) {
+			if reload.blueGreen {
+				updateErr = streamMgr.UpdateBlueGreen(ctx, id, *newStreamConf, reload.readyTimeout)
+			} else {
+				updateErr = streamMgr.Update(ctx, id, *newStreamConf)
+			}
+			if updateErr != nil && errors.Is(updateErr, strmmgr.ErrStreamDoesNotExist) {
 				updateErr = streamMgr.Create(id, *newStreamConf)
 			}
 		} else {
@@ -187,6 +218,7 @@ func initNormalMode(
 	opts *CLIOpts,
 	conf config.Type,
 	strict, watching bool,
+	reload reloadOpts,
 	confReader *config.Reader,
 	mgr *manager.Type,
 ) (newStream Stoppable, stoppedChan chan struct{}) {
@@ -194,17 +226,18 @@ func initNormalMode(
 
 	stoppedChan = make(chan struct{})
 	var closeOnce sync.Once
-	streamInit := func() (Stoppable, error) {
-		return stream.New(conf.Config, mgr, stream.OptOnClose(func() {
+	streamInit := func(sConf stream.Config, opts ...func(*stream.Type)) (*stream.Type, error) {
+		opts = append(opts, stream.OptOnClose(func() {
 			if !watching {
 				closeOnce.Do(func() {
 					close(stoppedChan)
 				})
 			}
 		}))
+		return stream.New(sConf, mgr, opts...)
 	}
 
-	initStream, err := streamInit()
+	initStream, err := streamInit(conf.Config)
 	if err != nil {
 		logger.Error("Service closing due to: %v\n", err)
 		os.Exit(1)
@@ -215,12 +248,19 @@ func initNormalMode(
 	logger.Info(opts.ExecTemplate("Launching a {{.ProductName}} instance, use CTRL+C to close"))
 
 	if err := confReader.SubscribeConfigChanges(func(newStreamConf *config.Type) error {
+		// NOTE: We're ignoring observability field changes for now.
+		if reload.blueGreen {
+			ctx, done := context.WithTimeout(context.Background(), 30*time.Second+reload.readyTimeout)
+			defer done()
+			return stoppableStream.ReplaceBlueGreen(ctx, reload.readyTimeout, func() (*stream.Type, error) {
+				return streamInit(newStreamConf.Config, stream.OptHoldInput())
+			})
+		}
+
 		ctx, done := context.WithTimeout(context.Background(), 30*time.Second)
 		defer done()
-		// NOTE: We're ignoring observability field changes for now.
 		return stoppableStream.Replace(ctx, func() (Stoppable, error) {
-			conf.Config = newStreamConf.Config
-			return streamInit()
+			return streamInit(newStreamConf.Config)
 		})
 	}); err != nil {
 		logger.Error("Failed to create config file watcher: %v", err)
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/stream"
 )
 
 // Stoppable represents a resource (a Bento stream) that can be stopped.
@@ -92,3 +95,50 @@ func (s *SwappableStopper) Replace(ctx context.Context, fn func() (Stoppable, er
 	s.current = newStoppable
 	return nil
 }
+
+// ReplaceBlueGreen replaces the resource with a new stream without interrupting
+// consumption. The new stream is constructed with its input held alongside the
+// existing resource, and once it is connected input consumption is switched
+// over whilst the existing resource drains its in-flight data. If the new
+// stream fails to start or become ready within the provided timeout then it is
+// stopped and the existing resource continues to run.
+//
+// The closure is expected to construct the stream with stream.OptHoldInput. If
+// the existing resource is not a stream then it is stopped before the new
+// stream is released.
+func (s *SwappableStopper) ReplaceBlueGreen(ctx context.Context, readyTimeout time.Duration, fn func() (*stream.Type, error)) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.stopped {
+		// If the outer stream has been stopped then do not create a new one.
+		return nil
+	}
+
+	newStrm, err := fn()
+	if err != nil {
+		return fmt.Errorf("failed to init updated stream: %w", err)
+	}
+
+	readyCtx, done := context.WithTimeout(ctx, readyTimeout)
+	err = newStrm.WaitForReady(readyCtx)
+	done()
+	if err != nil {
+		_ = newStrm.Stop(ctx)
+		return fmt.Errorf("updated stream failed to become ready, rolled back to existing stream: %w", err)
+	}
+
+	prev := s.current
+	s.current = newStrm
+
+	prevStrm, isStream := prev.(*stream.Type)
+	if !isStream {
+		_ = prev.Stop(ctx)
+	}
+
+	// As with Replace, an error here indicates that the previous stream has not
+	// finished draining before the context deadline, which it will continue to
+	// attempt in the background.
+	_ = newStrm.TakeOver(ctx, prevStrm)
+	return nil
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/cli/common"
+)
+
+// CliCommand is a cli.Command definition for rendering the topology of a
+// config as a diagram.
+func CliCommand(opts *common.CLIOpts) *cli.Command {
+	return &cli.Command{
+		Name:  "graph",
+		Usage: opts.ExecTemplate("Render the components of a {{.ProductName}} config as a diagram"),
+		Description: opts.ExecTemplate(`
+Parses a config, along with any resources, and prints a diagram of the flow of
+messages between its components. Branch conditions such as switch checks,
+workflow execution order and references to resources are included:
+
+  {{.BinaryName}} -c ./config.yaml graph
+  {{.BinaryName}} -c ./config.yaml -r ./resources.yaml graph --format dot | dot -Tsvg > graph.svg
+
+The supported formats are mermaid (default), dot and json.`)[1:],
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Value:   string(FormatMermaid),
+				Usage:   "The format of the diagram, one of: mermaid, dot, json",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if code := Action(c, opts, os.Stdout, os.Stderr); code != 0 {
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+}
+
+// Action performs the graph subcommand and returns the appropriate exit code.
+// This function is exported for testing purposes only.
+func Action(c *cli.Context, opts *common.CLIOpts, stdout, stderr io.Writer) int {
+	format := Format(c.String("format"))
+	switch format {
+	case FormatMermaid, FormatDOT, FormatJSON:
+	default:
+		fmt.Fprintf(stderr, "Format not recognised: %v\n", format)
+		return 1
+	}
+
+	_, _, confReader := common.ReadConfig(c, opts, false)
+	conf, _, lints, _, err := confReader.Read()
+	if err != nil {
+		fmt.Fprintf(stderr, "Configuration file read error: %v\n", err)
+		return 1
+	}
+	for _, l := range lints {
+		fmt.Fprintf(stderr, "Lint error: %v\n", l)
+	}
+
+	g, err := Build(bundle.GlobalEnvironment, conf.Config, conf.ResourceConfig)
+	if err != nil {
+		fmt.Fprintf(stderr, "Graph error: %v\n", err)
+		return 1
+	}
+	if err := Render(stdout, g, format); err != nil {
+		fmt.Fprintf(stderr, "Render error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,730 @@
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// EdgeKind describes the relationship between two nodes of a graph.
+type EdgeKind string
+
+// Edge kinds.
+const (
+	// EdgeFlow indicates that messages flow from one component to another.
+	EdgeFlow EdgeKind = "flow"
+	// EdgeResource indicates that a component references a resource.
+	EdgeResource EdgeKind = "resource"
+)
+
+// Node is a component within a graph.
+type Node struct {
+	ID    string    `json:"id"`
+	Kind  docs.Type `json:"kind"`
+	Type  string    `json:"type"`
+	Label string    `json:"label,omitempty"`
+	Path  string    `json:"path"`
+
+	// Resource is true for nodes that are either resources or are nested
+	// within the config of a resource.
+	Resource bool `json:"resource,omitempty"`
+}
+
+// Edge is a directed connection between two nodes of a graph, with an optional
+// label describing a condition (such as a switch case check) or an order.
+type Edge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  EdgeKind `json:"kind"`
+	Label string   `json:"label,omitempty"`
+}
+
+// Graph is a representation of the topology of a config, where nodes are
+// components and edges are either the flow of messages between components or
+// references from components to resources.
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+// Resources returns the nodes of the graph that are resources, or are nested
+// within the config of a resource.
+func (g *Graph) Resources() (nodes []*Node) {
+	for _, n := range g.Nodes {
+		if n.Resource {
+			nodes = append(nodes, n)
+		}
+	}
+	return
+}
+
+// Components returns the nodes of the graph that are not part of a resource.
+func (g *Graph) Components() (nodes []*Node) {
+	for _, n := range g.Nodes {
+		if !n.Resource {
+			nodes = append(nodes, n)
+		}
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+
+var nonIDChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+type builder struct {
+	inResources bool
+
+	prov  docs.Provider
+	g     *Graph
+	ids   map[string]struct{}
+	res   map[docs.Type]map[string]string
+	procs map[string]processor.Config
+}
+
+// Build creates a graph from a stream config and a set of resources.
+func Build(prov docs.Provider, conf stream.Config, resConf manager.ResourceConfig) (*Graph, error) {
+	b := &builder{
+		prov:  prov,
+		g:     &Graph{},
+		ids:   map[string]struct{}{},
+		res:   map[docs.Type]map[string]string{},
+		procs: map[string]processor.Config{},
+	}
+
+	// Resource nodes are added first so that references to them can be
+	// resolved whilst walking the stream components.
+	for _, c := range resConf.ResourceCaches {
+		b.addResource(docs.TypeCache, c.Type, c.Label)
+	}
+	for _, c := range resConf.ResourceRateLimits {
+		b.addResource(docs.TypeRateLimit, c.Type, c.Label)
+	}
+	for _, c := range resConf.ResourceLocks {
+		b.addResource(docs.TypeLock, c.Type, c.Label)
+	}
+	for _, c := range resConf.ResourceInputs {
+		b.addResource(docs.TypeInput, c.Type, c.Label)
+	}
+	for _, c := range resConf.ResourceProcessors {
+		b.addResource(docs.TypeProcessor, c.Type, c.Label)
+		b.procs[c.Label] = c
+	}
+	for _, c := range resConf.ResourceOutputs {
+		b.addResource(docs.TypeOutput, c.Type, c.Label)
+	}
+
+	b.inResources = true
+	for _, c := range resConf.ResourceInputs {
+		if _, err := b.inputChildren(b.res[docs.TypeInput][c.Label], c, "input_resources."+c.Label); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range resConf.ResourceProcessors {
+		if _, err := b.processorChildren(b.res[docs.TypeProcessor][c.Label], c, "processor_resources."+c.Label); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range resConf.ResourceOutputs {
+		if err := b.outputChildren(b.res[docs.TypeOutput][c.Label], c, "output_resources."+c.Label); err != nil {
+			return nil, err
+		}
+	}
+
+	b.inResources = false
+
+	exits, err := b.input(conf.Input, "input")
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Buffer.Type != "" && conf.Buffer.Type != "none" {
+		id := b.addNode(&Node{Kind: docs.TypeBuffer, Type: conf.Buffer.Type, Path: "buffer"})
+		b.connect(exits, id, "")
+		exits = []string{id}
+	}
+
+	if len(conf.Pipeline.Processors) > 0 {
+		entry, pExits, err := b.chain(conf.Pipeline.Processors, "pipeline.processors")
+		if err != nil {
+			return nil, err
+		}
+		b.connect(exits, entry, "")
+		exits = pExits
+	}
+
+	entry, err := b.output(conf.Output, "output")
+	if err != nil {
+		return nil, err
+	}
+	b.connect(exits, entry, "")
+
+	return b.g, nil
+}
+
+func (b *builder) addNode(n *Node) string {
+	id := nonIDChars.ReplaceAllString(n.Path, "_")
+	if _, exists := b.ids[id]; exists {
+		for i := 1; ; i++ {
+			if _, exists := b.ids[id+"_"+strconv.Itoa(i)]; !exists {
+				id = id + "_" + strconv.Itoa(i)
+				break
+			}
+		}
+	}
+	b.ids[id] = struct{}{}
+	n.ID = id
+	if b.inResources {
+		n.Resource = true
+	}
+	b.g.Nodes = append(b.g.Nodes, n)
+	return id
+}
+
+func (b *builder) addResource(cType docs.Type, name, label string) {
+	id := b.addNode(&Node{
+		Kind:     cType,
+		Type:     name,
+		Label:    label,
+		Path:     string(cType) + "_resources." + label,
+		Resource: true,
+	})
+	if b.res[cType] == nil {
+		b.res[cType] = map[string]string{}
+	}
+	b.res[cType][label] = id
+}
+
+func (b *builder) connect(from []string, to, label string) {
+	for _, f := range from {
+		b.g.Edges = append(b.g.Edges, &Edge{From: f, To: to, Kind: EdgeFlow, Label: label})
+	}
+}
+
+func (b *builder) reference(from string, cType docs.Type, label string) {
+	if to, exists := b.res[cType][label]; exists {
+		b.g.Edges = append(b.g.Edges, &Edge{From: from, To: to, Kind: EdgeResource})
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func (b *builder) input(conf input.Config, path string) ([]string, error) {
+	id := b.addNode(&Node{Kind: docs.TypeInput, Type: conf.Type, Label: conf.Label, Path: path})
+	return b.inputChildren(id, conf, path)
+}
+
+func (b *builder) inputChildren(id string, conf input.Config, path string) ([]string, error) {
+	plugin := pluginAny(conf.Plugin)
+	if conf.Type == "resource" {
+		label, _ := plugin.(string)
+		b.reference(id, docs.TypeInput, label)
+	}
+	b.references(id, docs.TypeInput, conf.Type, plugin)
+
+	for _, group := range b.nestedGroups(docs.TypeInput, conf.Type, plugin, path+"."+conf.Type) {
+		for i, v := range group.confs {
+			childConf, err := input.FromAny(b.prov, v)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", group.path, err)
+			}
+			childExits, err := b.input(childConf, group.elementPath(i))
+			if err != nil {
+				return nil, err
+			}
+			b.connect(childExits, id, group.check)
+		}
+	}
+
+	exits := []string{id}
+	if len(conf.Processors) > 0 {
+		entry, pExits, err := b.chain(conf.Processors, path+".processors")
+		if err != nil {
+			return nil, err
+		}
+		b.connect(exits, entry, "")
+		exits = pExits
+	}
+	return exits, nil
+}
+
+func (b *builder) output(conf output.Config, path string) (string, error) {
+	id := b.addNode(&Node{Kind: docs.TypeOutput, Type: conf.Type, Label: conf.Label, Path: path})
+	if err := b.outputChildren(id, conf, path); err != nil {
+		return "", err
+	}
+	if len(conf.Processors) == 0 {
+		return id, nil
+	}
+	entry, exits, err := b.chain(conf.Processors, path+".processors")
+	if err != nil {
+		return "", err
+	}
+	b.connect(exits, id, "")
+	return entry, nil
+}
+
+func (b *builder) outputChildren(id string, conf output.Config, path string) error {
+	plugin := pluginAny(conf.Plugin)
+	if conf.Type == "resource" {
+		label, _ := plugin.(string)
+		b.reference(id, docs.TypeOutput, label)
+	}
+	b.references(id, docs.TypeOutput, conf.Type, plugin)
+
+	for _, group := range b.nestedGroups(docs.TypeOutput, conf.Type, plugin, path+"."+conf.Type) {
+		for i, v := range group.confs {
+			childConf, err := output.FromAny(b.prov, v)
+			if err != nil {
+				return fmt.Errorf("%v: %w", group.path, err)
+			}
+			entry, err := b.output(childConf, group.elementPath(i))
+			if err != nil {
+				return err
+			}
+			label := group.check
+			if conf.Type == "fallback" && i > 0 {
+				label = "fallback " + strconv.Itoa(i)
+			}
+			b.connect([]string{id}, entry, label)
+		}
+	}
+	return nil
+}
+
+// chain adds a sequence of processors to the graph and returns the entry node
+// of the first processor and the exit nodes of the last.
+func (b *builder) chain(confs []processor.Config, path string) (entry string, exits []string, err error) {
+	for i, conf := range confs {
+		var pEntry string
+		var pExits []string
+		if pEntry, pExits, err = b.processor(conf, path+"."+strconv.Itoa(i)); err != nil {
+			return
+		}
+		if i == 0 {
+			entry = pEntry
+		} else {
+			b.connect(exits, pEntry, "")
+		}
+		exits = pExits
+	}
+	return
+}
+
+func (b *builder) processor(conf processor.Config, path string) (string, []string, error) {
+	id := b.addNode(&Node{Kind: docs.TypeProcessor, Type: conf.Type, Label: conf.Label, Path: path})
+	exits, err := b.processorChildren(id, conf, path)
+	return id, exits, err
+}
+
+func (b *builder) processorChildren(id string, conf processor.Config, path string) ([]string, error) {
+	plugin := pluginAny(conf.Plugin)
+	switch conf.Type {
+	case "resource":
+		label, _ := plugin.(string)
+		b.reference(id, docs.TypeProcessor, label)
+		return []string{id}, nil
+	case "switch":
+		return b.processorSwitch(id, plugin, path+".switch")
+	case "workflow":
+		return b.processorWorkflow(id, plugin, path+".workflow")
+	}
+	b.references(id, docs.TypeProcessor, conf.Type, plugin)
+
+	var exits []string
+	for _, group := range b.nestedGroups(docs.TypeProcessor, conf.Type, plugin, path+"."+conf.Type) {
+		entry, gExits, err := b.chainAny(group.confs, group.path)
+		if err != nil {
+			return nil, err
+		}
+		if entry == "" {
+			continue
+		}
+		b.connect([]string{id}, entry, group.check)
+		exits = append(exits, gExits...)
+	}
+	if len(exits) == 0 {
+		exits = []string{id}
+	}
+	return exits, nil
+}
+
+func (b *builder) chainAny(vs []any, path string) (string, []string, error) {
+	confs := make([]processor.Config, 0, len(vs))
+	for i, v := range vs {
+		conf, err := processor.FromAny(b.prov, v)
+		if err != nil {
+			return "", nil, fmt.Errorf("%v.%v: %w", path, i, err)
+		}
+		confs = append(confs, conf)
+	}
+	return b.chain(confs, path)
+}
+
+func (b *builder) processorSwitch(id string, plugin any, path string) ([]string, error) {
+	cases, _ := plugin.([]any)
+
+	var exits []string
+	var prevFallthrough []string
+	passThrough := true
+	for i, c := range cases {
+		cObj, _ := c.(map[string]any)
+		check, _ := cObj["check"].(string)
+		procs, _ := cObj["processors"].([]any)
+		fallthroughCase, _ := cObj["fallthrough"].(bool)
+
+		if check == "" {
+			passThrough = false
+		}
+
+		entry, cExits, err := b.chainAny(procs, path+"."+strconv.Itoa(i)+".processors")
+		if err != nil {
+			return nil, err
+		}
+		if entry == "" {
+			// A case without processors results in the message continuing on
+			// unchanged.
+			cExits = []string{id}
+		} else {
+			b.connect([]string{id}, entry, checkLabel(check))
+			if len(prevFallthrough) > 0 {
+				b.connect(prevFallthrough, entry, "fallthrough")
+			}
+		}
+
+		if fallthroughCase && entry != "" {
+			prevFallthrough = cExits
+		} else {
+			prevFallthrough = nil
+			exits = append(exits, cExits...)
+		}
+	}
+	exits = append(exits, prevFallthrough...)
+
+	if passThrough {
+		exits = append(exits, id)
+	}
+	return dedupe(exits), nil
+}
+
+func (b *builder) processorWorkflow(id string, plugin any, path string) ([]string, error) {
+	obj, _ := plugin.(map[string]any)
+
+	branches := map[string]map[string]any{}
+	if bObj, ok := obj["branches"].(map[string]any); ok {
+		for k, v := range bObj {
+			branches[k], _ = v.(map[string]any)
+		}
+	}
+
+	var order [][]string
+	if oArr, ok := obj["order"].([]any); ok {
+		for _, tier := range oArr {
+			tArr, _ := tier.([]any)
+			var names []string
+			for _, n := range tArr {
+				if s, ok := n.(string); ok {
+					names = append(names, s)
+				}
+			}
+			order = append(order, names)
+		}
+	}
+
+	resourceBranches := map[string]map[string]any{}
+	if rArr, ok := obj["branch_resources"].([]any); ok {
+		for _, n := range rArr {
+			if s, ok := n.(string); ok {
+				rPlugin, _ := pluginAny(b.procs[s].Plugin).(map[string]any)
+				resourceBranches[s] = rPlugin
+			}
+		}
+	}
+	for _, tier := range order {
+		for _, n := range tier {
+			if _, exists := branches[n]; !exists {
+				rPlugin, _ := pluginAny(b.procs[n].Plugin).(map[string]any)
+				resourceBranches[n] = rPlugin
+			}
+		}
+	}
+
+	if len(order) == 0 {
+		allBranches := make(map[string]map[string]any, len(branches)+len(resourceBranches))
+		for k, v := range branches {
+			allBranches[k] = v
+		}
+		for k, v := range resourceBranches {
+			allBranches[k] = v
+		}
+		var err error
+		if order, err = resolveWorkflowDAG(allBranches); err != nil {
+			return nil, fmt.Errorf("%v: %w", path, err)
+		}
+	}
+
+	entries := map[string]string{}
+	branchExits := map[string][]string{}
+	for _, tier := range order {
+		for _, name := range tier {
+			bPath := path + ".branches." + name
+			if _, isResource := resourceBranches[name]; isResource {
+				bPath = path + ".branch_resources." + name
+			}
+			bID := b.addNode(&Node{Kind: docs.TypeProcessor, Type: "branch", Label: name, Path: bPath})
+			entries[name] = bID
+
+			if _, isResource := resourceBranches[name]; isResource {
+				b.reference(bID, docs.TypeProcessor, name)
+				branchExits[name] = []string{bID}
+				continue
+			}
+
+			procs, _ := branches[name]["processors"].([]any)
+			entry, exits, err := b.chainAny(procs, bPath+".processors")
+			if err != nil {
+				return nil, err
+			}
+			if entry == "" {
+				branchExits[name] = []string{bID}
+				continue
+			}
+			b.connect([]string{bID}, entry, "")
+			branchExits[name] = exits
+		}
+	}
+
+	exits := []string{id}
+	for i, tier := range order {
+		var tierExits []string
+		for _, name := range tier {
+			b.connect(exits, entries[name], "tier "+strconv.Itoa(i+1))
+			tierExits = append(tierExits, branchExits[name]...)
+		}
+		if len(tierExits) > 0 {
+			exits = tierExits
+		}
+	}
+	return exits, nil
+}
+
+//------------------------------------------------------------------------------
+
+// referenceFields are the names of string fields that commonly refer to cache,
+// rate limit or lock resources by their label.
+var referenceFields = map[string][]docs.Type{
+	"cache":      {docs.TypeCache},
+	"rate_limit": {docs.TypeRateLimit},
+	"lock":       {docs.TypeLock},
+	"resource":   {docs.TypeCache, docs.TypeRateLimit},
+}
+
+// references walks the config of a component and adds reference edges for any
+// string fields that refer to a cache, rate limit or lock resource that exists.
+func (b *builder) references(id string, cType docs.Type, name string, plugin any) {
+	spec, exists := b.prov.GetDocs(name, cType)
+	if !exists {
+		return
+	}
+	var walk func(f docs.FieldSpec, v any)
+	walkChildren := func(children docs.FieldSpecs, v any) {
+		obj, _ := v.(map[string]any)
+		for _, c := range children {
+			if cv, exists := obj[c.Name]; exists {
+				walk(c, cv)
+			}
+		}
+	}
+	walk = func(f docs.FieldSpec, v any) {
+		if _, isCore := f.Type.IsCoreComponent(); isCore {
+			return
+		}
+		if f.Type == docs.FieldTypeString && f.Kind == docs.KindScalar {
+			if s, ok := v.(string); ok {
+				for _, t := range referenceFields[f.Name] {
+					b.reference(id, t, s)
+				}
+			}
+			return
+		}
+		if len(f.Children) == 0 {
+			return
+		}
+		switch f.Kind {
+		case docs.KindArray:
+			arr, _ := v.([]any)
+			for _, e := range arr {
+				walkChildren(f.Children, e)
+			}
+		case docs.KindMap:
+			obj, _ := v.(map[string]any)
+			for _, k := range sortedKeys(obj) {
+				walkChildren(f.Children, obj[k])
+			}
+		default:
+			walkChildren(f.Children, v)
+		}
+	}
+	walk(spec.Config, plugin)
+}
+
+// nestedGroup is a group of components of the same type nested within the
+// config of another component, such as the cases of a switch or the
+// processors of a branch.
+type nestedGroup struct {
+	path  string
+	check string
+	array bool
+	confs []any
+}
+
+func (g nestedGroup) elementPath(i int) string {
+	if !g.array {
+		return g.path
+	}
+	return g.path + "." + strconv.Itoa(i)
+}
+
+// nestedGroups walks the config of a component and returns all groups of
+// nested components of a given type in the order of the config spec.
+func (b *builder) nestedGroups(cType docs.Type, name string, plugin any, path string) (groups []nestedGroup) {
+	spec, exists := b.prov.GetDocs(name, cType)
+	if !exists {
+		return nil
+	}
+
+	var walk func(f docs.FieldSpec, v any, path, check string)
+	walkChildren := func(children docs.FieldSpecs, v any, path string) {
+		obj, _ := v.(map[string]any)
+		check, _ := obj["check"].(string)
+		for _, c := range children {
+			if cv, exists := obj[c.Name]; exists {
+				walk(c, cv, path+"."+c.Name, check)
+			}
+		}
+	}
+	walk = func(f docs.FieldSpec, v any, path, check string) {
+		if coreType, isCore := f.Type.IsCoreComponent(); isCore {
+			if coreType != cType {
+				return
+			}
+			switch f.Kind {
+			case docs.KindArray:
+				arr, _ := v.([]any)
+				groups = append(groups, nestedGroup{path: path, check: checkLabel(check), array: true, confs: arr})
+			case docs.Kind2DArray:
+				arr, _ := v.([]any)
+				for i, e := range arr {
+					inner, _ := e.([]any)
+					groups = append(groups, nestedGroup{path: path + "." + strconv.Itoa(i), check: checkLabel(check), array: true, confs: inner})
+				}
+			case docs.KindMap:
+				obj, _ := v.(map[string]any)
+				for _, k := range sortedKeys(obj) {
+					groups = append(groups, nestedGroup{path: path + "." + k, check: checkLabel(check), confs: []any{obj[k]}})
+				}
+			default:
+				groups = append(groups, nestedGroup{path: path, check: checkLabel(check), confs: []any{v}})
+			}
+			return
+		}
+		if len(f.Children) == 0 {
+			return
+		}
+		switch f.Kind {
+		case docs.KindArray:
+			arr, _ := v.([]any)
+			for i, e := range arr {
+				walkChildren(f.Children, e, path+"."+strconv.Itoa(i))
+			}
+		case docs.KindMap:
+			obj, _ := v.(map[string]any)
+			for _, k := range sortedKeys(obj) {
+				walkChildren(f.Children, obj[k], path+"."+k)
+			}
+		default:
+			walkChildren(f.Children, v, path)
+		}
+	}
+	walk(spec.Config, plugin, path, "")
+	return
+}
+
+//------------------------------------------------------------------------------
+
+// resolveWorkflowDAG infers the order in which workflow branches are executed
+// from the fields referenced by their request maps and assigned by their
+// result maps, using the same resolver as the workflow processor.
+func resolveWorkflowDAG(branches map[string]map[string]any) ([][]string, error) {
+	targets := make(map[string]processor.BranchTargets, len(branches))
+	for id, conf := range branches {
+		var t processor.BranchTargets
+		if reqMap, _ := conf["request_map"].(string); reqMap != "" {
+			exec, err := bloblang.GlobalEnvironment().NewMapping(reqMap)
+			if err != nil {
+				return nil, fmt.Errorf("branch %v: request_map: %w", id, err)
+			}
+			t.Used = processor.RequestMapTargets(exec)
+		}
+		if resMap, _ := conf["result_map"].(string); resMap != "" {
+			exec, err := bloblang.GlobalEnvironment().NewMapping(resMap)
+			if err != nil {
+				return nil, fmt.Errorf("branch %v: result_map: %w", id, err)
+			}
+			t.Provided = processor.ResultMapTargets(exec)
+		}
+		targets[id] = t
+	}
+	return processor.ResolveBranchDAG(targets)
+}
+
+//------------------------------------------------------------------------------
+
+func pluginAny(v any) any {
+	if node, ok := v.(*yaml.Node); ok {
+		var a any
+		if err := node.Decode(&a); err == nil {
+			return a
+		}
+		return nil
+	}
+	return v
+}
+
+func checkLabel(check string) string {
+	return strings.Join(strings.Fields(check), " ")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func dedupe(ids []string) []string {
+	seen := map[string]struct{}{}
+	out := ids[:0]
+	for _, id := range ids {
+		if _, exists := seen[id]; exists {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
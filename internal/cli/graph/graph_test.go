@@ -0,0 +1,305 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/cli/graph"
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+
+	_ "github.com/warpstreamlabs/bento/internal/impl/pure"
+)
+
+func buildGraph(t *testing.T, confStr string) *graph.Graph {
+	t.Helper()
+
+	conf, err := testutil.ConfigFromYAML(confStr)
+	require.NoError(t, err)
+
+	g, err := graph.Build(bundle.GlobalEnvironment, conf.Config, conf.ResourceConfig)
+	require.NoError(t, err)
+	return g
+}
+
+type testEdge struct {
+	from, to, label string
+	kind            graph.EdgeKind
+}
+
+func graphEdges(g *graph.Graph) (edges []testEdge) {
+	for _, e := range g.Edges {
+		edges = append(edges, testEdge{from: e.From, to: e.To, label: e.Label, kind: e.Kind})
+	}
+	return
+}
+
+func TestGraphBuildLinear(t *testing.T) {
+	g := buildGraph(t, `
+input:
+  broker:
+    inputs:
+      - generate:
+          mapping: 'root = "a"'
+      - generate:
+          mapping: 'root = "b"'
+  processors:
+    - mapping: 'root = this'
+buffer:
+  memory: {}
+pipeline:
+  processors:
+    - label: foo
+      mapping: 'root = this'
+    - log:
+        message: hello
+output:
+  drop: {}
+`)
+
+	var nodes []string
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+	assert.Equal(t, []string{
+		"input",
+		"input_broker_inputs_0",
+		"input_broker_inputs_1",
+		"input_processors_0",
+		"buffer",
+		"pipeline_processors_0",
+		"pipeline_processors_1",
+		"output",
+	}, nodes)
+	assert.Equal(t, "foo", g.Nodes[5].Label)
+
+	assert.Equal(t, []testEdge{
+		{from: "input_broker_inputs_0", to: "input", kind: graph.EdgeFlow},
+		{from: "input_broker_inputs_1", to: "input", kind: graph.EdgeFlow},
+		{from: "input", to: "input_processors_0", kind: graph.EdgeFlow},
+		{from: "input_processors_0", to: "buffer", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0", to: "pipeline_processors_1", kind: graph.EdgeFlow},
+		{from: "buffer", to: "pipeline_processors_0", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_1", to: "output", kind: graph.EdgeFlow},
+	}, graphEdges(g))
+}
+
+func TestGraphBuildSwitches(t *testing.T) {
+	g := buildGraph(t, `
+input:
+  generate:
+    mapping: 'root = {}'
+pipeline:
+  processors:
+    - switch:
+        - check: this.a == 1
+          processors:
+            - mapping: 'root.b = 1'
+          fallthrough: true
+        - check: this.a == 2
+          processors:
+            - mapping: 'root.b = 2'
+output:
+  switch:
+    cases:
+      - check: this.b == 1
+        output:
+          drop: {}
+      - output:
+          fallback:
+            - drop: {}
+            - reject: nope
+`)
+
+	assert.Equal(t, []testEdge{
+		{from: "pipeline_processors_0", to: "pipeline_processors_0_switch_0_processors_0", label: "this.a == 1", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0", to: "pipeline_processors_0_switch_1_processors_0", label: "this.a == 2", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0_switch_0_processors_0", to: "pipeline_processors_0_switch_1_processors_0", label: "fallthrough", kind: graph.EdgeFlow},
+		{from: "input", to: "pipeline_processors_0", kind: graph.EdgeFlow},
+		{from: "output", to: "output_switch_cases_0_output", label: "this.b == 1", kind: graph.EdgeFlow},
+		{from: "output_switch_cases_1_output", to: "output_switch_cases_1_output_fallback_0", kind: graph.EdgeFlow},
+		{from: "output_switch_cases_1_output", to: "output_switch_cases_1_output_fallback_1", label: "fallback 1", kind: graph.EdgeFlow},
+		{from: "output", to: "output_switch_cases_1_output", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0_switch_1_processors_0", to: "output", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0", to: "output", kind: graph.EdgeFlow},
+	}, graphEdges(g))
+}
+
+func TestGraphBuildWorkflowAndResources(t *testing.T) {
+	g := buildGraph(t, `
+input:
+  resource: foo_in
+pipeline:
+  processors:
+    - workflow:
+        branches:
+          c:
+            request_map: 'root = this.b'
+            processors:
+              - cache:
+                  resource: foo_cache
+                  operator: get
+                  key: c
+            result_map: 'root.c = this'
+          a:
+            request_map: 'root = this.a'
+            processors:
+              - mapping: 'root = this'
+            result_map: 'root.b = this'
+        branch_resources: [ d ]
+output:
+  resource: foo_out
+input_resources:
+  - label: foo_in
+    generate:
+      mapping: 'root = {}'
+processor_resources:
+  - label: d
+    branch:
+      request_map: 'root = this.c'
+      processors:
+        - log:
+            message: hi
+      result_map: 'root.d = this'
+output_resources:
+  - label: foo_out
+    drop: {}
+cache_resources:
+  - label: foo_cache
+    memory: {}
+`)
+
+	var resources []string
+	for _, n := range g.Resources() {
+		resources = append(resources, n.ID)
+	}
+	assert.Equal(t, []string{
+		"cache_resources_foo_cache",
+		"input_resources_foo_in",
+		"processor_resources_d",
+		"output_resources_foo_out",
+		"processor_resources_d_branch_processors_0",
+	}, resources)
+
+	assert.Equal(t, []testEdge{
+		{from: "processor_resources_d", to: "processor_resources_d_branch_processors_0", kind: graph.EdgeFlow},
+		{from: "input", to: "input_resources_foo_in", kind: graph.EdgeResource},
+		{from: "pipeline_processors_0_workflow_branches_a", to: "pipeline_processors_0_workflow_branches_a_processors_0", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0_workflow_branches_c_processors_0", to: "cache_resources_foo_cache", kind: graph.EdgeResource},
+		{from: "pipeline_processors_0_workflow_branches_c", to: "pipeline_processors_0_workflow_branches_c_processors_0", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0_workflow_branch_resources_d", to: "processor_resources_d", kind: graph.EdgeResource},
+		{from: "pipeline_processors_0", to: "pipeline_processors_0_workflow_branches_a", label: "tier 1", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0_workflow_branches_a_processors_0", to: "pipeline_processors_0_workflow_branches_c", label: "tier 2", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0_workflow_branches_c_processors_0", to: "pipeline_processors_0_workflow_branch_resources_d", label: "tier 3", kind: graph.EdgeFlow},
+		{from: "input", to: "pipeline_processors_0", kind: graph.EdgeFlow},
+		{from: "output", to: "output_resources_foo_out", kind: graph.EdgeResource},
+		{from: "pipeline_processors_0_workflow_branch_resources_d", to: "output", kind: graph.EdgeFlow},
+	}, graphEdges(g))
+}
+
+func TestGraphBuildWorkflowExplicitOrder(t *testing.T) {
+	g := buildGraph(t, `
+input:
+  generate:
+    mapping: 'root = {}'
+pipeline:
+  processors:
+    - workflow:
+        order: [ [ a, b ], [ c ] ]
+        branches:
+          a:
+            processors: [ { mapping: 'root = 1' } ]
+          b:
+            processors: [ { mapping: 'root = 2' } ]
+          c:
+            processors: [ { mapping: 'root = 3' } ]
+output:
+  drop: {}
+`)
+
+	var tierEdges []testEdge
+	for _, e := range graphEdges(g) {
+		if e.label != "" {
+			tierEdges = append(tierEdges, e)
+		}
+	}
+	assert.Equal(t, []testEdge{
+		{from: "pipeline_processors_0", to: "pipeline_processors_0_workflow_branches_a", label: "tier 1", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0", to: "pipeline_processors_0_workflow_branches_b", label: "tier 1", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0_workflow_branches_a_processors_0", to: "pipeline_processors_0_workflow_branches_c", label: "tier 2", kind: graph.EdgeFlow},
+		{from: "pipeline_processors_0_workflow_branches_b_processors_0", to: "pipeline_processors_0_workflow_branches_c", label: "tier 2", kind: graph.EdgeFlow},
+	}, tierEdges)
+}
+
+func TestGraphRender(t *testing.T) {
+	g := buildGraph(t, `
+input:
+  label: in
+  generate:
+    mapping: 'root = {}'
+pipeline:
+  processors:
+    - switch:
+        - check: this.a == "b"
+          processors:
+            - cache:
+                resource: foo
+                operator: get
+                key: a
+output:
+  drop: {}
+cache_resources:
+  - label: foo
+    memory: {}
+`)
+
+	var buf bytes.Buffer
+	require.NoError(t, graph.Render(&buf, g, graph.FormatMermaid))
+	assert.Equal(t, `flowchart TD
+  input(["input: generate (in)"])
+  pipeline_processors_0["processor: switch"]
+  pipeline_processors_0_switch_0_processors_0["processor: cache"]
+  output(["output: drop"])
+  subgraph resources [Resources]
+    cache_resources_foo[("cache: memory (foo)")]
+  end
+  pipeline_processors_0_switch_0_processors_0 -.-> cache_resources_foo
+  pipeline_processors_0 -->|"this.a == #quot;b#quot;"| pipeline_processors_0_switch_0_processors_0
+  input --> pipeline_processors_0
+  pipeline_processors_0_switch_0_processors_0 --> output
+  pipeline_processors_0 --> output
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, graph.Render(&buf, g, graph.FormatDOT))
+	assert.Equal(t, `digraph bento {
+  rankdir=TB;
+  "input" [label="input: generate (in)", shape=box, style=rounded];
+  "pipeline_processors_0" [label="processor: switch", shape=box];
+  "pipeline_processors_0_switch_0_processors_0" [label="processor: cache", shape=box];
+  "output" [label="output: drop", shape=box, style=rounded];
+  subgraph cluster_resources {
+    label="Resources";
+    "cache_resources_foo" [label="cache: memory (foo)", shape=cylinder];
+  }
+  "pipeline_processors_0_switch_0_processors_0" -> "cache_resources_foo" [style=dashed];
+  "pipeline_processors_0" -> "pipeline_processors_0_switch_0_processors_0" [label="this.a == \"b\""];
+  "input" -> "pipeline_processors_0";
+  "pipeline_processors_0_switch_0_processors_0" -> "output";
+  "pipeline_processors_0" -> "output";
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, graph.Render(&buf, g, graph.FormatJSON))
+
+	var decoded graph.Graph
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, g, &decoded)
+
+	require.Error(t, graph.Render(&buf, g, "nope"))
+}
@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+// Format is a supported output format of a rendered graph.
+type Format string
+
+// Supported formats.
+const (
+	FormatMermaid Format = "mermaid"
+	FormatDOT     Format = "dot"
+	FormatJSON    Format = "json"
+)
+
+// Render writes a graph to a writer in a given format.
+func Render(w io.Writer, g *Graph, format Format) error {
+	switch format {
+	case FormatMermaid:
+		return RenderMermaid(w, g)
+	case FormatDOT:
+		return RenderDOT(w, g)
+	case FormatJSON:
+		return RenderJSON(w, g)
+	}
+	return fmt.Errorf("format not recognised: %v", format)
+}
+
+func nodeText(n *Node) string {
+	text := string(n.Kind) + ": " + n.Type
+	if n.Label != "" {
+		text += " (" + n.Label + ")"
+	}
+	return text
+}
+
+//------------------------------------------------------------------------------
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(
+		`"`, "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+		"|", "#124;",
+	).Replace(s)
+}
+
+func mermaidNode(n *Node) string {
+	text := `"` + mermaidEscape(nodeText(n)) + `"`
+	switch n.Kind {
+	case docs.TypeInput, docs.TypeOutput:
+		return n.ID + "([" + text + "])"
+	case docs.TypeBuffer, docs.TypeCache, docs.TypeRateLimit, docs.TypeLock:
+		return n.ID + "[(" + text + ")]"
+	}
+	return n.ID + "[" + text + "]"
+}
+
+// RenderMermaid writes a graph as a Mermaid flowchart.
+func RenderMermaid(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, n := range g.Components() {
+		fmt.Fprintf(&b, "  %v\n", mermaidNode(n))
+	}
+	if res := g.Resources(); len(res) > 0 {
+		b.WriteString("  subgraph resources [Resources]\n")
+		for _, n := range res {
+			fmt.Fprintf(&b, "    %v\n", mermaidNode(n))
+		}
+		b.WriteString("  end\n")
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == EdgeResource {
+			arrow = "-.->"
+		}
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %v %v|\"%v\"| %v\n", e.From, arrow, mermaidEscape(e.Label), e.To)
+		} else {
+			fmt.Fprintf(&b, "  %v %v %v\n", e.From, arrow, e.To)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func dotNode(n *Node) string {
+	shape := "box"
+	switch n.Kind {
+	case docs.TypeInput, docs.TypeOutput:
+		shape = "box, style=rounded"
+	case docs.TypeBuffer, docs.TypeCache, docs.TypeRateLimit, docs.TypeLock:
+		shape = "cylinder"
+	}
+	return fmt.Sprintf("%v [label=%v, shape=%v];", dotQuote(n.ID), dotQuote(nodeText(n)), shape)
+}
+
+// RenderDOT writes a graph in the Graphviz DOT language.
+func RenderDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph bento {\n")
+	b.WriteString("  rankdir=TB;\n")
+	for _, n := range g.Components() {
+		fmt.Fprintf(&b, "  %v\n", dotNode(n))
+	}
+	if res := g.Resources(); len(res) > 0 {
+		b.WriteString("  subgraph cluster_resources {\n")
+		b.WriteString("    label=\"Resources\";\n")
+		for _, n := range res {
+			fmt.Fprintf(&b, "    %v\n", dotNode(n))
+		}
+		b.WriteString("  }\n")
+	}
+	for _, e := range g.Edges {
+		var attrs []string
+		if e.Label != "" {
+			attrs = append(attrs, "label="+dotQuote(e.Label))
+		}
+		if e.Kind == EdgeResource {
+			attrs = append(attrs, "style=dashed")
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, "  %v -> %v [%v];\n", dotQuote(e.From), dotQuote(e.To), strings.Join(attrs, ", "))
+		} else {
+			fmt.Fprintf(&b, "  %v -> %v;\n", dotQuote(e.From), dotQuote(e.To))
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+// RenderJSON writes a graph as a JSON document.
+func RenderJSON(w io.Writer, g *Graph) error {
+	if g.Nodes == nil {
+		g.Nodes = []*Node{}
+	}
+	if g.Edges == nil {
+		g.Edges = []*Edge{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
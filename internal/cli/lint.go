@@ -2,6 +2,7 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -165,6 +166,16 @@ files with the .yaml or .yml extension.`)[1:],
 				Value: false,
 				Usage: "Do not produce lint errors when environment interpolations exist without defaults within configs but aren't defined.",
 			},
+			&cli.BoolFlag{
+				Name:  "type-check",
+				Value: false,
+				Usage: "Statically type check Bloblang mappings, printing linting errors for type errors that will always occur and warnings for unreachable branches.",
+			},
+			&cli.StringFlag{
+				Name:  "input-schema",
+				Value: "",
+				Usage: "An optional path to a JSON schema describing the input documents of Bloblang mappings, used when type checking.",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			if code := LintAction(c, cliOpts, os.Stderr); code != 0 {
@@ -192,6 +203,19 @@ func LintAction(c *cli.Context, opts *common.CLIOpts, stderr io.Writer) int {
 	lConf.RejectDeprecated = c.Bool("deprecated")
 	lConf.WarnDeprecated = !lConf.RejectDeprecated
 	lConf.RequireLabels = c.Bool("labels")
+	lConf.BloblangTypeCheck = c.Bool("type-check")
+	if schemaPath := c.String("input-schema"); schemaPath != "" {
+		schemaBytes, err := ifs.ReadFile(ifs.OS(), schemaPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "Input schema error: %v\n", err)
+			return 1
+		}
+		if !json.Valid(schemaBytes) {
+			fmt.Fprintf(stderr, "Input schema error: %v is not valid JSON\n", schemaPath)
+			return 1
+		}
+		lConf.BloblangInputSchema = schemaBytes
+	}
 	skipEnvVarCheck := c.Bool("skip-env-var-check")
 
 	spec := opts.MainConfigSpecCtor()
@@ -231,7 +255,7 @@ func LintAction(c *cli.Context, opts *common.CLIOpts, stderr io.Writer) int {
 	var lintErrors []pathLint
 
 	for _, lint := range pathLints {
-		if lint.lint.Level == docs.LintWarning && lint.lint.Type == docs.LintDeprecated {
+		if lint.lint.Level == docs.LintWarning && (lint.lint.Type == docs.LintDeprecated || lint.lint.Type == docs.LintUnreachableBloblang) {
 			fmt.Print(yellow(fmt.Sprintf("%v%v\n", lint.source, lint.lint.Error())))
 		} else {
 			lintErrors = append(lintErrors, lint)
@@ -0,0 +1,268 @@
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/cli/common"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	ifilepath "github.com/warpstreamlabs/bento/internal/filepath"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+)
+
+var (
+	red   = color.New(color.FgRed).SprintFunc()
+	green = color.New(color.FgGreen).SprintFunc()
+)
+
+// CliCommand is a cli.Command definition for rewriting configs that use
+// components with registered migrations.
+func CliCommand(opts *common.CLIOpts) *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: opts.ExecTemplate("Rewrite {{.ProductName}} configs that use deprecated components"),
+		Description: opts.ExecTemplate(`
+Rewrites configs in place so that components with a registered migration, such
+as the deprecated sql processor and output, are replaced with their modern
+equivalents. Only the migrated components are rewritten, and the rest of the
+file, including comments, is left as it is:
+
+  {{.BinaryName}} migrate ./config.yaml
+  {{.BinaryName}} migrate --dry-run ./configs/...
+
+If a path ends with '...' then {{.ProductName}} will walk the target and migrate
+any files with the .yaml or .yml extension. Components that cannot be migrated
+automatically are reported and left unchanged, and the command exits with a
+status code 1.`)[1:],
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Value: false,
+				Usage: "Print a diff of the changes that would be made rather than writing them.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if code := Action(c, opts, os.Stdout, os.Stderr); code != 0 {
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+}
+
+// Action performs the migrate subcommand and returns the appropriate exit
+// code. This function is exported for testing purposes only.
+func Action(c *cli.Context, opts *common.CLIOpts, stdout, stderr io.Writer) int {
+	targets, err := ifilepath.GlobsAndSuperPaths(ifs.OS(), c.Args().Slice(), "yaml", "yml")
+	if err != nil {
+		fmt.Fprintf(stderr, "Migrate paths error: %v\n", err)
+		return 1
+	}
+	if conf := c.String("config"); conf != "" {
+		targets = append(targets, conf)
+	}
+	targets = append(targets, c.StringSlice("resources")...)
+
+	spec := opts.MainConfigSpecCtor()
+	dryRun := c.Bool("dry-run")
+
+	exitCode := 0
+	for _, target := range targets {
+		if target == "" {
+			continue
+		}
+		if !migrateFile(target, spec, dryRun, stdout, stderr) {
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+func splitLines(b []byte) []string {
+	lines := strings.SplitAfter(string(b), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func migrateFile(path string, spec docs.FieldSpecs, dryRun bool, stdout, stderr io.Writer) bool {
+	rawBytes, err := ifs.ReadFile(ifs.OS(), path)
+	if err != nil {
+		fmt.Fprint(stderr, red(fmt.Sprintf("%v: failed to read file: %v\n", path, err)))
+		return false
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(rawBytes, &node); err != nil {
+		fmt.Fprint(stderr, red(fmt.Sprintf("%v: failed to parse file: %v\n", path, err)))
+		return false
+	}
+
+	results, err := spec.MigrateYAML(bundle.GlobalEnvironment, &node)
+	if err != nil {
+		fmt.Fprint(stderr, red(fmt.Sprintf("%v: %v\n", path, err)))
+		return false
+	}
+
+	success, migrated := true, 0
+	for _, r := range results {
+		if r.Err != nil {
+			success = false
+			fmt.Fprint(stderr, red(fmt.Sprintf("%v(%v,1) unable to migrate %v %v to %v: %v\n", path, r.Line, r.ComponentType, r.Name, r.To, r.Err)))
+			continue
+		}
+		migrated++
+		if !dryRun {
+			fmt.Fprint(stderr, green(fmt.Sprintf("%v(%v,1) migrated %v %v to %v\n", path, r.Line, r.ComponentType, r.Name, r.To)))
+		}
+	}
+	if migrated == 0 {
+		return success
+	}
+
+	// Only the text of migrated components is rewritten where possible, and
+	// otherwise the entire config is encoded.
+	newBytes, spliced, err := spliceMigratedComponents(rawBytes, results)
+	if err == nil && !spliced {
+		newBytes, err = encodeYAML(&node)
+	}
+	if err != nil {
+		fmt.Fprint(stderr, red(fmt.Sprintf("%v: failed to marshal migrated config: %v\n", path, err)))
+		return false
+	}
+	if bytes.Equal(rawBytes, newBytes) {
+		return success
+	}
+
+	if dryRun {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(rawBytes),
+			B:        splitLines(newBytes),
+			FromFile: path,
+			ToFile:   path,
+			Context:  3,
+		})
+		if err != nil {
+			fmt.Fprint(stderr, red(fmt.Sprintf("%v: failed to create diff: %v\n", path, err)))
+			return false
+		}
+		fmt.Fprint(stdout, diff)
+		return success
+	}
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, newBytes, mode); err != nil {
+		fmt.Fprint(stderr, red(fmt.Sprintf("%v: failed to write file: %v\n", path, err)))
+		return false
+	}
+	return success
+}
+
+func encodeYAML(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// spliceMigratedComponents replaces the text of each successfully migrated
+// component within a raw config with its encoded migrated node, leaving the
+// remainder of the config untouched. Returns false if a component could not
+// be located within the raw config, which is the case for flow style configs.
+func spliceMigratedComponents(rawBytes []byte, results []docs.MigratedYAMLComponent) ([]byte, bool, error) {
+	lines := strings.Split(string(rawBytes), "\n")
+
+	type splice struct {
+		start, end, col int
+		node            *yaml.Node
+	}
+
+	var splices []splice
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if r.Node.Kind != yaml.MappingNode || r.Node.Style&yaml.FlowStyle != 0 || r.Line < 1 || r.Line > len(lines) {
+			return nil, false, nil
+		}
+		sp := splice{start: r.Line - 1, end: r.Line - 1, col: r.Column - 1, node: r.Node}
+		if len(lines[sp.start]) < sp.col {
+			return nil, false, nil
+		}
+
+		// A block mapping continues until the first non-empty line that is
+		// indented less than the mapping itself.
+		for i := sp.start + 1; i < len(lines); i++ {
+			trimmed := strings.TrimLeft(lines[i], " ")
+			if trimmed == "" {
+				continue
+			}
+			if len(lines[i])-len(trimmed) < sp.col {
+				break
+			}
+			sp.end = i
+		}
+		splices = append(splices, sp)
+	}
+
+	// Components nested within other migrated components are encoded along
+	// with their parent.
+	sort.Slice(splices, func(i, j int) bool {
+		return splices[i].start < splices[j].start
+	})
+	var outer []splice
+	for _, sp := range splices {
+		if len(outer) > 0 && sp.start <= outer[len(outer)-1].end {
+			continue
+		}
+		outer = append(outer, sp)
+	}
+
+	for i := len(outer) - 1; i >= 0; i-- {
+		sp := outer[i]
+
+		// Comments preceding the component are not part of its text and are
+		// therefore left where they are.
+		node := *sp.node
+		node.HeadComment = ""
+		if len(node.Content) > 0 {
+			key := *node.Content[0]
+			key.HeadComment = ""
+			node.Content = append([]*yaml.Node{&key}, node.Content[1:]...)
+		}
+
+		encoded, err := encodeYAML(&node)
+		if err != nil {
+			return nil, false, err
+		}
+
+		indent := strings.Repeat(" ", sp.col)
+		newLines := strings.Split(strings.TrimSuffix(string(encoded), "\n"), "\n")
+		for j := range newLines {
+			if j == 0 {
+				newLines[j] = lines[sp.start][:sp.col] + newLines[j]
+			} else if newLines[j] != "" {
+				newLines[j] = indent + newLines[j]
+			}
+		}
+		lines = append(lines[:sp.start], append(newLines, lines[sp.end+1:]...)...)
+	}
+	return []byte(strings.Join(lines, "\n")), true, nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
@@ -14,8 +15,11 @@ import (
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/cli/blobl"
 	"github.com/warpstreamlabs/bento/internal/cli/common"
+	"github.com/warpstreamlabs/bento/internal/cli/graph"
+	"github.com/warpstreamlabs/bento/internal/cli/migrate"
 	clitemplate "github.com/warpstreamlabs/bento/internal/cli/template"
 	"github.com/warpstreamlabs/bento/internal/cli/test"
+	"github.com/warpstreamlabs/bento/internal/config"
 	"github.com/warpstreamlabs/bento/internal/docs"
 	"github.com/warpstreamlabs/bento/internal/filepath"
 	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
@@ -122,6 +126,21 @@ func runFlags(opts *common.CLIOpts) []cli.Flag {
 			Value:   false,
 			Usage:   "EXPERIMENTAL: watch config files for changes and automatically apply them",
 		},
+		&cli.StringFlag{
+			Name:  "watcher-strategy",
+			Value: "restart",
+			Usage: "EXPERIMENTAL: the strategy used for applying stream config changes detected by the watcher, options are: restart, blue_green",
+		},
+		&cli.DurationFlag{
+			Name:  "watcher-ready-timeout",
+			Value: 10 * time.Second,
+			Usage: "EXPERIMENTAL: the maximum period to wait for an updated stream to connect when using the blue_green watcher strategy before rolling back",
+		},
+		&cli.DurationFlag{
+			Name:  "secrets-ttl",
+			Value: 5 * time.Minute,
+			Usage: "the period for which values resolved from secret interpolations are cached before being refreshed when configs are re-read",
+		},
 		&cli.StringSliceFlag{
 			Name:    "env-file",
 			Aliases: []string{"e"},
@@ -161,6 +180,8 @@ func preRun(c *cli.Context, opts *common.CLIOpts) error {
 		}
 	}
 
+	config.GlobalSecrets.SetTTL(c.Duration("secrets-ttl"))
+
 	templatesPaths, err := filepath.Globs(ifs.OS(), c.StringSlice("templates"))
 	if err != nil {
 		fmt.Printf("Failed to resolve template glob pattern: %v\n", err)
@@ -262,7 +283,9 @@ variables have been resolved:
 						sanitConf := docs.NewSanitiseConfig(bundle.GlobalEnvironment)
 						sanitConf.RemoveTypeField = true
 						sanitConf.ScrubSecrets = true
-						err = opts.MainConfigSpecCtor().SanitiseYAML(&node, sanitConf)
+						if err = opts.MainConfigSpecCtor().SanitiseYAML(&node, sanitConf); err == nil {
+							config.GlobalSecrets.RedactYAML(&node)
+						}
 					}
 					if err == nil {
 						var configYAML []byte
@@ -278,6 +301,8 @@ variables have been resolved:
 				},
 			},
 			lintCliCommand(opts),
+			graph.CliCommand(opts),
+			migrate.CliCommand(opts),
 			{
 				Name:   "run",
 				Hidden: !opts.ShowRunCommand,
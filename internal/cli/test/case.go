@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
+	"time"
 
 	iprocessor "github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/internal/config/test"
@@ -25,6 +26,14 @@ func (c CaseFailure) String() string {
 	return fmt.Sprintf("%v [line %v]: %v", c.Name, c.TestLine, c.Reason)
 }
 
+// CaseResult describes the outcome of executing a test case.
+type CaseResult struct {
+	Name     string
+	TestLine int
+	Duration time.Duration
+	Failures []CaseFailure
+}
+
 // ProcProvider returns compiled processors extracted from a Bento config
 // using a JSON Pointer.
 type ProcProvider interface {
@@ -35,6 +44,10 @@ type ProcProvider interface {
 // ExecuteFrom executes a test case from the perspective of a given directory,
 // which is used for obtaining relative condition file imports.
 func ExecuteFrom(fs fs.FS, dir string, c test.Case, provider ProcProvider) (failures []CaseFailure, err error) {
+	return executeFrom(fs, dir, c, provider, false)
+}
+
+func executeFrom(fs fs.FS, dir string, c test.Case, provider ProcProvider, updateSnapshots bool) (failures []CaseFailure, err error) {
 	var procSet []iprocessor.V1
 	if c.TargetMapping != "" {
 		if procSet, err = provider.ProvideBloblang(c.TargetMapping); err != nil {
@@ -55,36 +68,62 @@ func ExecuteFrom(fs fs.FS, dir string, c test.Case, provider ProcProvider) (fail
 	}
 
 	var inputMsg []message.Batch
+	if inputMsg, err = inputBatches(fs, dir, c); err != nil {
+		return
+	}
 
+	outputBatches, result := executeProcessors(procSet, inputMsg)
+	if result != nil {
+		reportFailure(result.Error())
+	}
+
+	checkOutputBatches(fs, dir, c.OutputBatches, outputBatches, updateSnapshots, reportFailure)
+	return
+}
+
+// executeProcessors executes processors on input batches, where a panic is
+// reported as an error, as inputs saved from fuzzing can cause them.
+func executeProcessors(procSet []iprocessor.V1, inputMsg []message.Batch) (outputBatches []message.Batch, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("processors panicked: %v", r)
+		}
+	}()
+	if outputBatches, err = iprocessor.ExecuteAll(context.Background(), procSet, inputMsg...); err != nil {
+		err = fmt.Errorf("processors resulted in error: %w", err)
+	}
+	return
+}
+
+func inputBatches(fs fs.FS, dir string, c test.Case) ([]message.Batch, error) {
+	var inputMsg []message.Batch
 	for _, inputBatch := range c.InputBatches {
 		parts := make([]*message.Part, len(inputBatch))
 		for i, v := range inputBatch {
+			var err error
 			if parts[i], err = v.ToMessage(fs, dir); err != nil {
-				err = fmt.Errorf("failed to create test input %v: %w", i, err)
-				return
+				return nil, fmt.Errorf("failed to create test input %v: %w", i, err)
 			}
 		}
-
-		currentBatch := message.Batch(parts)
-		inputMsg = append(inputMsg, currentBatch)
-
-	}
-
-	outputBatches, result := iprocessor.ExecuteAll(context.Background(), procSet, inputMsg...)
-	if result != nil {
-		reportFailure(fmt.Sprintf("processors resulted in error: %v", result))
+		inputMsg = append(inputMsg, message.Batch(parts))
 	}
+	return inputMsg, nil
+}
 
-	if lExp, lAct := len(c.OutputBatches), len(outputBatches); lAct < lExp {
+// checkOutputBatches compares the batches resulting from a test against the
+// expected batches, reporting each mismatch found. When updateSnapshots is true
+// any snapshot conditions are rewritten from the resulting messages first.
+func checkOutputBatches(fs fs.FS, dir string, expected [][]test.OutputConditionsMap, actual []message.Batch, updateSnapshots bool, reportFailure func(reason string)) {
+	if lExp, lAct := len(expected), len(actual); lAct < lExp {
 		reportFailure(fmt.Sprintf("wrong batch count, expected %v, got %v", lExp, lAct))
 	}
 
-	for i, v := range outputBatches {
-		if len(c.OutputBatches) <= i {
+	for i, v := range actual {
+		if len(expected) <= i {
 			reportFailure(fmt.Sprintf("unexpected batch: %s", message.GetAllBytes(v)))
 			continue
 		}
-		expectedBatch := c.OutputBatches[i]
+		expectedBatch := expected[i]
 		if lExp, lAct := len(expectedBatch), v.Len(); lExp != lAct {
 			reportFailure(fmt.Sprintf("mismatch of output batch %v message counts, expected %v, got %v", i, lExp, lAct))
 		}
@@ -93,6 +132,11 @@ func ExecuteFrom(fs fs.FS, dir string, c test.Case, provider ProcProvider) (fail
 				reportFailure(fmt.Sprintf("unexpected message from batch %v: %s", i, part.AsBytes()))
 				return nil
 			}
+			if updateSnapshots {
+				for _, updateErr := range expectedBatch[i2].UpdateSnapshots(fs, dir, part) {
+					reportFailure(fmt.Sprintf("batch %v message %v: %v", i, i2, updateErr))
+				}
+			}
 			condErrs := expectedBatch[i2].CheckAll(fs, dir, part)
 			for _, condErr := range condErrs {
 				reportFailure(fmt.Sprintf("batch %v message %v: %v", i, i2, condErr))
@@ -103,5 +147,4 @@ func ExecuteFrom(fs fs.FS, dir string, c test.Case, provider ProcProvider) (fail
 			return nil
 		})
 	}
-	return
 }
@@ -24,6 +24,8 @@ fail the process will report the errors and exit with a status code 1.
   {{.BinaryName}} test ./path/to/configs/...
   {{.BinaryName}} test ./foo_configs/*.yaml ./bar_configs/*.yaml
   {{.BinaryName}} test ./foo.yaml
+  {{.BinaryName}} test --coverage --junit-report ./report.xml ./path/to/configs/...
+  {{.BinaryName}} test --fuzz --fuzz-iterations 1000 ./foo.yaml
 
 For more information check out the docs at:
 {{.DocumentationURL}}/configuration/unit_testing`)[1:],
@@ -33,6 +35,41 @@ For more information check out the docs at:
 				Value: "",
 				Usage: "allow components to write logs at a provided level to stdout.",
 			},
+			&cli.StringFlag{
+				Name:  "junit-report",
+				Value: "",
+				Usage: "write the results of each test case as a JUnit XML report to a provided file path.",
+			},
+			&cli.StringFlag{
+				Name:  "json-report",
+				Value: "",
+				Usage: "write the results of each test case as a JSON report to a provided file path, including coverage when enabled.",
+			},
+			&cli.BoolFlag{
+				Name:  "coverage",
+				Value: false,
+				Usage: "report which processors, switch cases, catch blocks and Bloblang branches of the tested configs were exercised.",
+			},
+			&cli.BoolFlag{
+				Name:  "update-snapshots",
+				Value: false,
+				Usage: "rewrite the files of snapshot conditions from the messages that they check.",
+			},
+			&cli.BoolFlag{
+				Name:  "fuzz",
+				Value: false,
+				Usage: "execute the targets of test cases with generated inputs rather than running the tests, saving inputs that cause failures as regression tests.",
+			},
+			&cli.IntFlag{
+				Name:  "fuzz-iterations",
+				Value: 0,
+				Usage: "override the number of generated inputs executed for each test case when fuzzing.",
+			},
+			&cli.Int64Flag{
+				Name:  "fuzz-seed",
+				Value: 0,
+				Usage: "the seed of generated inputs when fuzzing, by default a random seed is used.",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			if len(c.StringSlice("set")) > 0 {
@@ -45,18 +82,31 @@ For more information check out the docs at:
 				fmt.Printf("Failed to resolve resource glob pattern: %v\n", err)
 				os.Exit(1)
 			}
+			reports := ReportOptions{
+				JUnitPath:       c.String("junit-report"),
+				JSONPath:        c.String("json-report"),
+				Coverage:        c.Bool("coverage"),
+				UpdateSnapshots: c.Bool("update-snapshots"),
+			}
+			logger := log.Noop()
 			if logLevel := c.String("log"); logLevel != "" {
 				logConf := log.NewConfig()
 				logConf.LogLevel = logLevel
-				logger, err := log.New(os.Stdout, ifs.OS(), logConf)
-				if err != nil {
+				if logger, err = log.New(os.Stdout, ifs.OS(), logConf); err != nil {
 					fmt.Printf("Failed to init logger: %v\n", err)
 					os.Exit(1)
 				}
-				if RunAll(c.Args().Slice(), cliOpts.MainConfigSpecCtor(), "_bento_test", true, logger, resourcesPaths) {
+			}
+			if c.Bool("fuzz") {
+				if RunFuzz(c.Args().Slice(), cliOpts.MainConfigSpecCtor(), "_bento_test", logger, resourcesPaths, FuzzOptions{
+					Iterations: c.Int("fuzz-iterations"),
+					Seed:       c.Int64("fuzz-seed"),
+				}) {
 					os.Exit(0)
 				}
-			} else if RunAll(c.Args().Slice(), cliOpts.MainConfigSpecCtor(), "_bento_test", true, log.Noop(), resourcesPaths) {
+				os.Exit(1)
+			}
+			if RunAllWithReports(c.Args().Slice(), cliOpts.MainConfigSpecCtor(), "_bento_test", true, logger, resourcesPaths, reports) {
 				os.Exit(0)
 			}
 			os.Exit(1)
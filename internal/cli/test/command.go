@@ -1,8 +1,10 @@
 package test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -119,6 +121,31 @@ func lintTarget(spec docs.FieldSpecs, path, testSuffix string) ([]docs.Lint, err
 // a config file, a config files test definition file, a directory, or the
 // wildcard pattern './...'.
 func RunAll(paths []string, spec docs.FieldSpecs, testSuffix string, lint bool, logger log.Modular, resourcesPaths []string) bool {
+	return RunAllWithReports(paths, spec, testSuffix, lint, logger, resourcesPaths, ReportOptions{})
+}
+
+// ReportOptions determines the reports produced by a test run in addition to
+// the human readable summary, and whether the snapshots checked by the run are
+// rewritten.
+type ReportOptions struct {
+	// When set a JUnit XML report is written to this path.
+	JUnitPath string
+
+	// When set a JSON report is written to this path.
+	JSONPath string
+
+	// When true the coverage of the tested configs is printed, and included in
+	// the JSON report.
+	Coverage bool
+
+	// When true the snapshot files of snapshot conditions are rewritten from
+	// the messages that they check.
+	UpdateSnapshots bool
+}
+
+// RunAllWithReports executes the test command for a slice of paths in the same
+// way as RunAll, and also produces the reports enabled by the provided options.
+func RunAllWithReports(paths []string, spec docs.FieldSpecs, testSuffix string, lint bool, logger log.Modular, resourcesPaths []string, reports ReportOptions) bool {
 	targets, err := GetTestTargets(paths, testSuffix)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to obtain test targets: %v\n", err)
@@ -129,12 +156,12 @@ func RunAll(paths []string, spec docs.FieldSpecs, testSuffix string, lint bool,
 		return false
 	}
 
-	type failedTarget struct {
-		target string
-		lints  []docs.Lint
-		cases  []CaseFailure
+	var coverage *Coverage
+	execOpts := []func(*ProcessorsProvider){OptUpdateSnapshots(reports.UpdateSnapshots)}
+	if reports.Coverage {
+		coverage = NewCoverage(spec)
+		execOpts = append(execOpts, OptSetCoverage(coverage))
 	}
-	fails := []failedTarget{}
 
 	targetPaths := make([]string, 0, len(targets))
 	for k := range targets {
@@ -142,25 +169,23 @@ func RunAll(paths []string, spec docs.FieldSpecs, testSuffix string, lint bool,
 	}
 	sort.Strings(targetPaths)
 
+	results := make([]TargetResult, 0, len(targetPaths))
+	var fails []TargetResult
 	for _, target := range targetPaths {
-		var lints []docs.Lint
-		var failCases []CaseFailure
+		res := TargetResult{Target: target, Linted: lint}
 		if lint {
-			if lints, err = lintTarget(spec, target, testSuffix); err != nil {
+			if res.Lints, err = lintTarget(spec, target, testSuffix); err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, err)
 				return false
 			}
 		}
-		if failCases, err = Execute(spec, targets[target], target, resourcesPaths, logger); err != nil {
+		if res.Cases, err = ExecuteCases(spec, targets[target], target, resourcesPaths, logger, execOpts...); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, err)
 			return false
 		}
-		if len(lints) > 0 || len(failCases) > 0 {
-			fails = append(fails, failedTarget{
-				target: target,
-				lints:  lints,
-				cases:  failCases,
-			})
+		results = append(results, res)
+		if res.Failed() {
+			fails = append(fails, res)
 			fmt.Printf("Test '%v' %v\n", target, red("failed"))
 		} else {
 			fmt.Printf("Test '%v' %v\n", target, green("succeeded"))
@@ -172,16 +197,20 @@ func RunAll(paths []string, spec docs.FieldSpecs, testSuffix string, lint bool,
 			if i > 0 {
 				fmt.Println("")
 			}
-			fmt.Printf("--- %v ---\n\n", fail.target)
-			for _, lint := range fail.lints {
+			fmt.Printf("--- %v ---\n\n", fail.Target)
+			for _, lint := range fail.Lints {
 				fmt.Printf("Lint: %v\n", lint)
 			}
-			if len(fail.cases) > 0 {
-				if len(fail.lints) > 0 {
+			var failCases []CaseFailure
+			for _, c := range fail.Cases {
+				failCases = append(failCases, c.Failures...)
+			}
+			if len(failCases) > 0 {
+				if len(fail.Lints) > 0 {
 					fmt.Println("")
 				}
 				var namePrev string
-				for i, fail := range fail.cases {
+				for i, fail := range failCases {
 					if namePrev != fail.Name {
 						if i > 0 {
 							fmt.Println("")
@@ -193,7 +222,41 @@ func RunAll(paths []string, spec docs.FieldSpecs, testSuffix string, lint bool,
 				}
 			}
 		}
-		return false
 	}
-	return true
+
+	var coverageReport *CoverageReport
+	if coverage != nil {
+		if coverageReport, err = coverage.Report(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to produce coverage report: %v\n", err)
+			return false
+		}
+		fmt.Println("")
+		PrintCoverage(os.Stdout, coverageReport)
+	}
+
+	if reports.JUnitPath != "" {
+		if err := writeReportFile(reports.JUnitPath, func(w io.Writer) error {
+			return WriteJUnitReport(w, results)
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write JUnit report: %v\n", err)
+			return false
+		}
+	}
+	if reports.JSONPath != "" {
+		if err := writeReportFile(reports.JSONPath, func(w io.Writer) error {
+			return WriteJSONReport(w, results, coverageReport)
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write JSON report: %v\n", err)
+			return false
+		}
+	}
+	return len(fails) == 0
+}
+
+func writeReportFile(path string, fn func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		return err
+	}
+	return ifs.WriteFile(ifs.OS(), path, buf.Bytes(), 0o644)
 }
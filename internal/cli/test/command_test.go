@@ -6,6 +6,9 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/warpstreamlabs/bento/internal/cli/test"
 	"github.com/warpstreamlabs/bento/internal/config"
 	"github.com/warpstreamlabs/bento/internal/log"
@@ -257,3 +260,66 @@ tests:
 		t.Error("Unexpected result")
 	}
 }
+
+func TestCommandRunSnapshots(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"foo.yaml": `
+pipeline:
+  processors:
+  - mapping: |
+      root.doc = this
+      root.upper = this.value.uppercase()
+      meta topic = "foos"`,
+		"foo_bento_test.yaml": `
+tests:
+  - name: example test
+    target_processors: '/pipeline/processors'
+    input_batch:
+      - content: '{"value":"foo"}'
+    output_batches:
+      -
+        - snapshot: ./snapshots/example.json`,
+	})
+	require.NoError(t, err)
+
+	target := []string{filepath.Join(testDir, "foo.yaml")}
+	snapPath := filepath.Join(testDir, "snapshots", "example.json")
+
+	// The first run records the snapshot.
+	require.True(t, test.RunAll(target, config.Spec(), "_bento_test", true, log.Noop(), nil))
+
+	snapBytes, err := os.ReadFile(snapPath)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "json_content": {
+    "doc": {
+      "value": "foo"
+    },
+    "upper": "FOO"
+  },
+  "metadata": {
+    "topic": "foos"
+  }
+}
+`, string(snapBytes))
+
+	assert.True(t, test.RunAll(target, config.Spec(), "_bento_test", true, log.Noop(), nil))
+
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "foo.yaml"), []byte(`
+pipeline:
+  processors:
+  - mapping: |
+      root.doc = this
+      root.upper = this.value.uppercase() + "!"
+      meta topic = "foos"`), 0o644))
+
+	assert.False(t, test.RunAll(target, config.Spec(), "_bento_test", true, log.Noop(), nil))
+	assert.True(t, test.RunAllWithReports(target, config.Spec(), "_bento_test", true, log.Noop(), nil, test.ReportOptions{
+		UpdateSnapshots: true,
+	}))
+	assert.True(t, test.RunAll(target, config.Spec(), "_bento_test", true, log.Noop(), nil))
+
+	snapBytes, err = os.ReadFile(snapPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(snapBytes), `"upper": "FOO!"`)
+}
@@ -0,0 +1,349 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/internal/manager"
+)
+
+// Coverage records which processors, switch cases, catch blocks and Bloblang
+// branches of the configs targeted by tests were exercised.
+type Coverage struct {
+	spec     docs.FieldSpecs
+	branches *parser.BranchCoverage
+	bloblEnv *bloblang.Environment
+
+	mut          sync.Mutex
+	configs      map[string]*metrics.Local
+	mappingFiles map[string]string
+}
+
+// NewCoverage returns an empty coverage record, where configs are walked using
+// the provided spec.
+func NewCoverage(spec docs.FieldSpecs) *Coverage {
+	branches := parser.NewBranchCoverage()
+	return &Coverage{
+		spec:         spec,
+		branches:     branches,
+		bloblEnv:     bloblang.GlobalEnvironment().WithBranchCoverage(branches),
+		configs:      map[string]*metrics.Local{},
+		mappingFiles: map[string]string{},
+	}
+}
+
+// managerOpts returns the options for a manager of components from a target
+// config, which collect the metrics and Bloblang branches of the components.
+func (c *Coverage) managerOpts(targetPath string) []manager.OptFunc {
+	targetPath = filepath.Clean(targetPath)
+
+	c.mut.Lock()
+	local, exists := c.configs[targetPath]
+	if !exists {
+		local = metrics.NewLocal()
+		c.configs[targetPath] = local
+	}
+	c.mut.Unlock()
+
+	return []manager.OptFunc{
+		manager.OptSetMetrics(metrics.NewNamespaced(local)),
+		manager.OptSetBloblangEnvironment(c.bloblEnv),
+	}
+}
+
+func (c *Coverage) addMappingFile(path, mapping string) {
+	c.mut.Lock()
+	c.mappingFiles[mapping] = path
+	c.mut.Unlock()
+}
+
+//------------------------------------------------------------------------------
+
+// CoverageItem describes a part of a config and the number of times that it was
+// exercised by tests.
+type CoverageItem struct {
+	File        string `json:"file"`
+	Path        string `json:"path,omitempty"`
+	Line        int    `json:"line"`
+	Description string `json:"description"`
+	Hits        int64  `json:"hits"`
+}
+
+// CoverageReport lists the parts of the configs exercised by tests.
+type CoverageReport struct {
+	Processors       []CoverageItem `json:"processors"`
+	SwitchCases      []CoverageItem `json:"switch_cases"`
+	CatchBlocks      []CoverageItem `json:"catch_blocks"`
+	BloblangBranches []CoverageItem `json:"bloblang_branches"`
+}
+
+type mappingLocation struct {
+	file string
+	path string
+	line int
+}
+
+// Report walks each config exercised by tests and returns the coverage of its
+// processors, switch cases, catch blocks and the branches of its Bloblang
+// mappings.
+func (c *Coverage) Report() (*CoverageReport, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	files := make([]string, 0, len(c.configs))
+	for k := range c.configs {
+		files = append(files, k)
+	}
+	sort.Strings(files)
+
+	report := &CoverageReport{}
+	mappings := map[string]mappingLocation{}
+
+	for _, file := range files {
+		configBytes, _, _, err := config.ReadFileEnvSwap(ifs.OS(), file, os.LookupEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file '%v': %v", file, err)
+		}
+		root, err := docs.UnmarshalYAML(configBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file '%v': %v", file, err)
+		}
+
+		hits := newCoverageHits(c.configs[file].GetCounters())
+		c.spec.WalkYAMLPaths(bundle.GlobalEnvironment, root, nil, func(path []string, spec docs.FieldSpec, node *yaml.Node) {
+			if spec.Kind != docs.KindScalar {
+				return
+			}
+			if spec.Bloblang || spec.Interpolated {
+				c.addMapping(mappings, file, path, spec, node)
+				return
+			}
+
+			coreType, isCore := spec.Type.IsCoreComponent()
+			if !isCore || (coreType != docs.TypeProcessor && coreType != docs.TypeOutput) {
+				return
+			}
+			name, _, err := docs.GetInferenceCandidateFromYAML(bundle.GlobalEnvironment, coreType, node)
+			if err != nil {
+				return
+			}
+			label := ""
+			if labelNode, _ := docs.GetYAMLPath(node, "label"); labelNode != nil {
+				label = labelNode.Value
+			}
+			mPath := componentPath(path)
+
+			if coreType == docs.TypeOutput {
+				if name != "switch" {
+					return
+				}
+				casesNode, _ := docs.GetYAMLPath(node, "switch", "cases")
+				if casesNode == nil || casesNode.Kind != yaml.SequenceNode {
+					return
+				}
+				for i, caseNode := range casesNode.Content {
+					casePath := []string{"switch", "cases", strconv.Itoa(i)}
+					report.SwitchCases = append(report.SwitchCases, CoverageItem{
+						File:        file,
+						Path:        jsonPointer(append(path, casePath...)),
+						Line:        caseNode.Line,
+						Description: fmt.Sprintf("output switch case %v", i),
+						Hits:        hits.under("output_sent", append(mPath, casePath...)),
+					})
+				}
+				return
+			}
+
+			// Resource processors are not observed, the resource that they
+			// reference is reported instead.
+			if name == "resource" {
+				return
+			}
+
+			desc := name
+			if label != "" {
+				desc = fmt.Sprintf("%v (%v)", name, label)
+			}
+			report.Processors = append(report.Processors, CoverageItem{
+				File:        file,
+				Path:        jsonPointer(path),
+				Line:        node.Line,
+				Description: desc,
+				Hits:        hits.exact("processor_received", label, mPath),
+			})
+
+			switch name {
+			case "switch":
+				casesNode, _ := docs.GetYAMLPath(node, "switch")
+				if casesNode == nil || casesNode.Kind != yaml.SequenceNode {
+					return
+				}
+				for i, caseNode := range casesNode.Content {
+					// Cases without processors have nothing to exercise.
+					if procs, _ := docs.GetYAMLPath(caseNode, "processors"); procs == nil || len(procs.Content) == 0 {
+						continue
+					}
+					casePath := []string{"switch", strconv.Itoa(i)}
+					report.SwitchCases = append(report.SwitchCases, CoverageItem{
+						File:        file,
+						Path:        jsonPointer(append(path, casePath...)),
+						Line:        caseNode.Line,
+						Description: fmt.Sprintf("switch case %v", i),
+						Hits:        hits.under("processor_received", append(mPath, casePath...)),
+					})
+				}
+			case "catch":
+				if procs, _ := docs.GetYAMLPath(node, "catch"); procs == nil || len(procs.Content) == 0 {
+					return
+				}
+				report.CatchBlocks = append(report.CatchBlocks, CoverageItem{
+					File:        file,
+					Path:        jsonPointer(append(path, "catch")),
+					Line:        node.Line,
+					Description: desc,
+					Hits:        hits.under("processor_received", append(mPath, "catch")),
+				})
+			}
+		})
+	}
+
+	for b, n := range c.branches.Hits() {
+		item := CoverageItem{
+			Description: fmt.Sprintf("%v: %v", b.Expression, b.Branch),
+			Hits:        n,
+		}
+		if b.Import != "" {
+			item.File, item.Line = b.Import, b.Line
+		} else if f, exists := c.mappingFiles[b.Mapping]; exists {
+			item.File, item.Line = f, b.Line
+		} else if loc, exists := mappings[b.Mapping]; exists {
+			item.File, item.Path, item.Line = loc.file, loc.path, loc.line+b.Line-1
+		} else {
+			// Mappings outside of the tested configs, such as those of
+			// mocks, are not reported.
+			continue
+		}
+		if b.Column > 1 {
+			item.Description = fmt.Sprintf("%v at column %v: %v", b.Expression, b.Column, b.Branch)
+		}
+		report.BloblangBranches = append(report.BloblangBranches, item)
+	}
+
+	for _, items := range [][]CoverageItem{report.Processors, report.SwitchCases, report.CatchBlocks, report.BloblangBranches} {
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].File != items[j].File {
+				return items[i].File < items[j].File
+			}
+			if items[i].Line != items[j].Line {
+				return items[i].Line < items[j].Line
+			}
+			return items[i].Description < items[j].Description
+		})
+	}
+	return report, nil
+}
+
+// addMapping records the location of a Bloblang mapping or interpolated string
+// within a config, and parses it so that branches that are never executed are
+// still recorded.
+func (c *Coverage) addMapping(mappings map[string]mappingLocation, file string, path []string, spec docs.FieldSpec, node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return
+	}
+	if _, exists := mappings[node.Value]; exists {
+		return
+	}
+
+	line := node.Line
+	if node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle {
+		line++
+	}
+	mappings[node.Value] = mappingLocation{file: file, path: jsonPointer(path), line: line}
+
+	if spec.Bloblang {
+		_, _ = c.bloblEnv.NewMapping(node.Value)
+	} else if strings.Contains(node.Value, "${!") {
+		_, _ = c.bloblEnv.NewField(node.Value)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type coverageHits map[string]map[string]int64
+
+func newCoverageHits(counters map[string]int64) coverageHits {
+	h := coverageHits{}
+	for k, v := range counters {
+		name, tagNames, tagValues := metrics.ReverseLabelledPath(k)
+		var label, path string
+		for i, t := range tagNames {
+			switch t {
+			case "label":
+				label = tagValues[i]
+			case "path":
+				path = tagValues[i]
+			}
+		}
+		if h[name] == nil {
+			h[name] = map[string]int64{}
+		}
+		h[name][label+"\x00"+path] += v
+	}
+	return h
+}
+
+// exact returns the value of a counter of a component with a label and path.
+func (h coverageHits) exact(name, label string, path []string) int64 {
+	return h[name][label+"\x00"+metricPath(path)]
+}
+
+// under returns the sum of a counter of all components at or within a path.
+func (h coverageHits) under(name string, path []string) (total int64) {
+	prefix := metricPath(path)
+	for k, v := range h[name] {
+		_, p, _ := strings.Cut(k, "\x00")
+		if p == prefix || strings.HasPrefix(p, prefix+".") {
+			total += v
+		}
+	}
+	return
+}
+
+func metricPath(path []string) string {
+	return "root." + query.SliceToDotPath(path...)
+}
+
+// componentPath returns the path that a manager assigns to a component found at
+// a path of a config, where resources are identified by their label rather than
+// their index.
+func componentPath(path []string) []string {
+	if len(path) > 1 && strings.HasSuffix(path[0], "_resources") {
+		return append([]string{path[0]}, path[2:]...)
+	}
+	return append([]string(nil), path...)
+}
+
+func jsonPointer(path []string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	var b strings.Builder
+	for _, p := range path {
+		b.WriteByte('/')
+		b.WriteString(escaper.Replace(p))
+	}
+	return b.String()
+}
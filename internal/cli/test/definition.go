@@ -3,6 +3,7 @@ package test
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/warpstreamlabs/bento/internal/config/test"
 	"github.com/warpstreamlabs/bento/internal/docs"
@@ -12,26 +13,56 @@ import (
 
 // Execute the test definition.
 func Execute(confSpec docs.FieldSpecs, cases []test.Case, testFilePath string, resourcesPaths []string, logger log.Modular) ([]CaseFailure, error) {
+	results, err := ExecuteCases(confSpec, cases, testFilePath, resourcesPaths, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalFailures []CaseFailure
+	for _, r := range results {
+		totalFailures = append(totalFailures, r.Failures...)
+	}
+	return totalFailures, nil
+}
+
+// ExecuteCases executes each case of a test definition and returns the result
+// of each case, including the time it took to execute. Options are applied to
+// the processors provider of the cases.
+func ExecuteCases(confSpec docs.FieldSpecs, cases []test.Case, testFilePath string, resourcesPaths []string, logger log.Modular, opts ...func(*ProcessorsProvider)) ([]CaseResult, error) {
 	procsProvider := NewProcessorsProvider(
 		testFilePath,
-		OptAddResourcesPaths(resourcesPaths),
-		OptProcessorsProviderSetLogger(logger),
-		OptSetConfigSpec(confSpec),
+		append([]func(*ProcessorsProvider){
+			OptAddResourcesPaths(resourcesPaths),
+			OptProcessorsProviderSetLogger(logger),
+			OptSetConfigSpec(confSpec),
+		}, opts...)...,
 	)
 
 	dir := filepath.Dir(testFilePath)
 
-	var totalFailures []CaseFailure
+	results := make([]CaseResult, 0, len(cases))
 	for i, c := range cases {
 		cleanupEnv := setEnvironment(c.Environment)
-		failures, err := ExecuteFrom(ifs.OS(), dir, c, procsProvider)
+		var failures []CaseFailure
+		var err error
+		start := time.Now()
+		if c.Stream != nil {
+			failures, err = executeStreamFrom(ifs.OS(), dir, c, procsProvider, procsProvider.updateSnapshots)
+		} else {
+			failures, err = executeFrom(ifs.OS(), dir, c, procsProvider, procsProvider.updateSnapshots)
+		}
 		if err != nil {
 			cleanupEnv()
 			return nil, fmt.Errorf("test case %v failed: %v", i, err)
 		}
-		totalFailures = append(totalFailures, failures...)
+		results = append(results, CaseResult{
+			Name:     c.Name,
+			TestLine: c.Line(),
+			Duration: time.Since(start),
+			Failures: failures,
+		})
 		cleanupEnv()
 	}
 
-	return totalFailures, nil
+	return results, nil
 }
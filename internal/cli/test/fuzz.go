@@ -0,0 +1,525 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	iprocessor "github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/config/test"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+// fuzzShrinkBudget is the maximum number of executions spent minimising each
+// failing input.
+const fuzzShrinkBudget = 500
+
+// FuzzOptions determines how test cases are fuzzed.
+type FuzzOptions struct {
+	// When above zero this overrides the number of generated inputs executed
+	// for each test case.
+	Iterations int
+
+	// The seed of the generated inputs, when zero a random seed is used.
+	Seed int64
+}
+
+// FuzzFailure describes a minimised input that caused the target of a fuzzed
+// test case to fail.
+type FuzzFailure struct {
+	Name     string
+	TestLine int
+	Input    []byte
+	Metadata map[string]any
+	Reason   string
+
+	// The name of the regression test case that the failure was saved as.
+	Regression string
+
+	caseIndex   int
+	outputShape []int
+}
+
+// FuzzCase executes the target processors or mapping of a test case with inputs
+// generated from the input batches of the test and, optionally, a JSON Schema.
+// Inputs that result in errors, panics or violated invariants are minimised and
+// returned as failures, with one failure returned for each distinct reason.
+func FuzzCase(fs fs.FS, dir string, c test.Case, provider ProcProvider, rng *rand.Rand, opts FuzzOptions) ([]FuzzFailure, error) {
+	fConf := test.FuzzConfig{Iterations: test.DefaultFuzzIterations}
+	if c.Fuzz != nil {
+		fConf = *c.Fuzz
+	}
+	if opts.Iterations > 0 {
+		fConf.Iterations = opts.Iterations
+	}
+
+	gen := &fuzzGenerator{rng: rng}
+	for _, batch := range c.InputBatches {
+		for i, v := range batch {
+			part, err := v.ToMessage(fs, dir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create test input %v: %w", i, err)
+			}
+			gen.seeds = append(gen.seeds, fuzzSeed{content: part.AsBytes(), metadata: v.Metadata})
+		}
+	}
+
+	var err error
+	if gen.schema, err = fuzzSchema(fs, dir, fConf.Schema); err != nil {
+		return nil, err
+	}
+	if len(gen.seeds) == 0 && gen.schema == nil {
+		return nil, nil
+	}
+
+	f := &caseFuzzer{fs: fs, dir: dir, invariants: fConf.Invariants}
+	if c.TargetMapping != "" {
+		if f.procs, err = provider.ProvideBloblang(c.TargetMapping); err != nil {
+			return nil, fmt.Errorf("failed to initialise Bloblang mapping '%v': %v", c.TargetMapping, err)
+		}
+	} else {
+		if f.procs, err = provider.Provide(c.TargetProcessors, c.Environment, c.Mocks); err != nil {
+			return nil, fmt.Errorf("failed to initialise processors '%v': %v", c.TargetProcessors, err)
+		}
+	}
+
+	var failures []FuzzFailure
+	seen := map[string]struct{}{}
+	for i := 0; i < fConf.Iterations; i++ {
+		in := gen.next()
+		res := f.run(in)
+		if res.reason == "" {
+			continue
+		}
+		if in, res = f.shrink(in, res); res.reason == "" {
+			continue
+		}
+		if _, exists := seen[res.key()]; exists {
+			continue
+		}
+		seen[res.key()] = struct{}{}
+		failures = append(failures, FuzzFailure{
+			Name:        c.Name,
+			TestLine:    c.Line(),
+			Input:       in.content(),
+			Metadata:    in.metadata,
+			Reason:      res.reason,
+			outputShape: res.outputShape,
+		})
+	}
+	return failures, nil
+}
+
+func fuzzSchema(fs fs.FS, dir string, schema any) (map[string]any, error) {
+	if path, ok := schema.(string); ok {
+		schemaBytes, err := ifs.ReadFile(fs, filepath.Join(dir, path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fuzz schema: %w", err)
+		}
+		if err := yaml.Unmarshal(schemaBytes, &schema); err != nil {
+			return nil, fmt.Errorf("failed to parse fuzz schema: %w", err)
+		}
+	}
+	if schema == nil {
+		return nil, nil
+	}
+	obj, ok := schema.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected fuzz schema to be an object, got %T", schema)
+	}
+	return obj, nil
+}
+
+//------------------------------------------------------------------------------
+
+type caseFuzzer struct {
+	fs         fs.FS
+	dir        string
+	procs      []iprocessor.V1
+	invariants []test.FuzzInvariant
+}
+
+type fuzzResult struct {
+	kind        string
+	reason      string
+	outputShape []int
+}
+
+var (
+	fuzzReasonQuotedRegexp = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+	fuzzReasonParensRegexp = regexp.MustCompile(`\([^()]*\)`)
+	fuzzReasonDigitsRegexp = regexp.MustCompile(`[0-9]+`)
+)
+
+// key identifies the reason of a failure regardless of the values that it
+// mentions, such that a minimised input is only accepted when it fails in the
+// same way as the original.
+func (r fuzzResult) key() string {
+	reason := fuzzReasonQuotedRegexp.ReplaceAllString(r.reason, `""`)
+	reason = fuzzReasonParensRegexp.ReplaceAllString(reason, "()")
+	reason = fuzzReasonDigitsRegexp.ReplaceAllString(reason, "0")
+	return r.kind + "\x00" + reason
+}
+
+func (f *caseFuzzer) run(in fuzzInput) (res fuzzResult) {
+	part := message.NewPart(in.content())
+	for k, v := range in.metadata {
+		part.MetaSetMut(k, v)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			res = fuzzResult{kind: "panic", reason: fmt.Sprintf("processors panicked: %v", r)}
+		}
+	}()
+
+	outputs, err := iprocessor.ExecuteAll(context.Background(), f.procs, message.Batch{part})
+	for _, b := range outputs {
+		res.outputShape = append(res.outputShape, b.Len())
+	}
+	if err != nil {
+		res.kind, res.reason = "error", fmt.Sprintf("processors resulted in error: %v", err)
+		return
+	}
+	for i, b := range outputs {
+		for j, p := range b {
+			if pErr := p.ErrorGet(); pErr != nil {
+				res.kind, res.reason = "error", fmt.Sprintf("batch %v message %v: processing error: %v", i, j, pErr)
+				return
+			}
+			for k, inv := range f.invariants {
+				if cErr := inv.Condition.Check(f.fs, f.dir, p); cErr != nil {
+					res.kind = fmt.Sprintf("invariant %v", k)
+					res.reason = fmt.Sprintf("batch %v message %v: invariant '%v' violated: %v", i, j, inv.Check, cErr)
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
+// shrink attempts to simplify a failing input until no simpler variant fails
+// for the same reason.
+func (f *caseFuzzer) shrink(in fuzzInput, res fuzzResult) (fuzzInput, fuzzResult) {
+	key := res.key()
+	budget := fuzzShrinkBudget
+	for improved := true; improved && budget > 0; {
+		improved = false
+		for _, c := range shrinkCandidates(in.value) {
+			if budget == 0 {
+				break
+			}
+			budget--
+			cIn := in.withValue(c)
+			if cRes := f.run(cIn); cRes.reason != "" && cRes.key() == key {
+				in, res, improved = cIn, cRes, true
+				break
+			}
+		}
+	}
+	return in, res
+}
+
+//------------------------------------------------------------------------------
+
+// RunFuzz fuzzes the test cases found for a slice of paths, in the same way as
+// RunAll finds them, and saves each failure as a regression test case within
+// the test definition of the case. Stream test cases are not fuzzed.
+func RunFuzz(paths []string, spec docs.FieldSpecs, testSuffix string, logger log.Modular, resourcesPaths []string, opts FuzzOptions) bool {
+	targets, err := GetTestTargets(paths, testSuffix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to obtain test targets: %v\n", err)
+		return false
+	}
+	if len(targets) == 0 {
+		fmt.Printf("%v\n", yellow("No tests were found"))
+		return false
+	}
+
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	fmt.Printf("Fuzzing with seed %v\n", opts.Seed)
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	targetPaths := make([]string, 0, len(targets))
+	for k := range targets {
+		targetPaths = append(targetPaths, k)
+	}
+	sort.Strings(targetPaths)
+
+	failed := map[string][]FuzzFailure{}
+	for _, target := range targetPaths {
+		failures, err := fuzzTarget(spec, targets[target], target, testSuffix, logger, resourcesPaths, rng, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fuzz test target '%v': %v\n", target, err)
+			return false
+		}
+		if len(failures) > 0 {
+			failed[target] = failures
+			fmt.Printf("Fuzz '%v' %v\n", target, red("failed"))
+		} else {
+			fmt.Printf("Fuzz '%v' %v\n", target, green("succeeded"))
+		}
+	}
+	if len(failed) == 0 {
+		return true
+	}
+
+	fmt.Printf("\nFailures:\n\n")
+	first := true
+	for _, target := range targetPaths {
+		failures, exists := failed[target]
+		if !exists {
+			continue
+		}
+		if !first {
+			fmt.Println("")
+		}
+		first = false
+		fmt.Printf("--- %v ---\n", target)
+		for _, fail := range failures {
+			fmt.Printf("\n%v [line %v]:\n", fail.Name, fail.TestLine)
+			fmt.Printf("input: %s\n", fail.Input)
+			if len(fail.Metadata) > 0 {
+				metaBytes, _ := json.Marshal(fail.Metadata)
+				fmt.Printf("metadata: %s\n", metaBytes)
+			}
+			fmt.Println(fail.Reason)
+			fmt.Printf("saved as regression test '%v'\n", fail.Regression)
+		}
+	}
+	return false
+}
+
+func fuzzTarget(spec docs.FieldSpecs, cases []test.Case, target, testSuffix string, logger log.Modular, resourcesPaths []string, rng *rand.Rand, opts FuzzOptions) ([]FuzzFailure, error) {
+	procsProvider := NewProcessorsProvider(
+		target,
+		OptAddResourcesPaths(resourcesPaths),
+		OptProcessorsProviderSetLogger(logger),
+		OptSetConfigSpec(spec),
+	)
+	dir := filepath.Dir(target)
+
+	var failures []FuzzFailure
+	for i, c := range cases {
+		if c.Stream != nil {
+			continue
+		}
+		cleanupEnv := setEnvironment(c.Environment)
+		caseFailures, err := FuzzCase(ifs.OS(), dir, c, procsProvider, rng, opts)
+		cleanupEnv()
+		if err != nil {
+			return nil, fmt.Errorf("test case %v failed: %v", i, err)
+		}
+		for _, fail := range caseFailures {
+			fail.caseIndex = i
+			failures = append(failures, fail)
+		}
+	}
+	if len(failures) == 0 {
+		return nil, nil
+	}
+
+	definitionPath := target
+	if _, defPath := GetPathPair(target, testSuffix); defPath != target {
+		if _, err := ifs.OS().Stat(defPath); err == nil {
+			definitionPath = defPath
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if err := saveRegressionCases(definitionPath, cases, failures); err != nil {
+		return nil, fmt.Errorf("failed to save regression test cases to '%v': %w", definitionPath, err)
+	}
+	return failures, nil
+}
+
+//------------------------------------------------------------------------------
+
+// saveRegressionCases appends a test case to a test definition for each
+// failure, where each case is a copy of the fuzzed case with the failing input
+// and output conditions that the processing and invariants succeed.
+func saveRegressionCases(definitionPath string, cases []test.Case, failures []FuzzFailure) error {
+	rawBytes, err := ifs.ReadFile(ifs.OS(), definitionPath)
+	if err != nil {
+		return err
+	}
+	root, err := docs.UnmarshalYAML(rawBytes)
+	if err != nil {
+		return err
+	}
+	testsNode, err := docs.GetYAMLPath(root, "tests")
+	if err != nil {
+		return err
+	}
+	if testsNode.Kind != yaml.SequenceNode || len(testsNode.Content) != len(cases) {
+		return errors.New("failed to locate test cases")
+	}
+
+	existingNames := map[string]struct{}{}
+	for _, c := range cases {
+		existingNames[c.Name] = struct{}{}
+	}
+
+	var newNodes []*yaml.Node
+	for i := range failures {
+		c := cases[failures[i].caseIndex]
+
+		name := ""
+		for n := 1; ; n++ {
+			if name = fmt.Sprintf("%v (fuzz regression %v)", c.Name, n); !nameExists(existingNames, name) {
+				break
+			}
+		}
+		existingNames[name] = struct{}{}
+		failures[i].Regression = name
+
+		node, err := regressionCaseNode(testsNode.Content[failures[i].caseIndex], name, failures[i], c.Fuzz)
+		if err != nil {
+			return err
+		}
+		newNodes = append(newNodes, node)
+	}
+
+	newBytes, err := appendTestCaseNodes(rawBytes, root, testsNode, newNodes)
+	if err != nil {
+		return err
+	}
+	return ifs.WriteFile(ifs.OS(), definitionPath, newBytes, 0o644)
+}
+
+func nameExists(names map[string]struct{}, name string) bool {
+	_, exists := names[name]
+	return exists
+}
+
+func regressionCaseNode(original *yaml.Node, name string, fail FuzzFailure, fConf *test.FuzzConfig) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	addField := func(key string, value any) error {
+		var valueNode yaml.Node
+		if err := valueNode.Encode(value); err != nil {
+			return err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &valueNode)
+		return nil
+	}
+
+	if err := addField("name", name); err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(original.Content)-1; i += 2 {
+		switch original.Content[i].Value {
+		case "name", "input_batch", "input_batches", "output_batches", "fuzz":
+			continue
+		}
+		node.Content = append(node.Content, original.Content[i], original.Content[i+1])
+	}
+
+	input := map[string]any{}
+	if jsonContent, ok := yamlJSONContent(fail.Input); ok {
+		input["json_content"] = jsonContent
+	} else {
+		input["content"] = string(fail.Input)
+	}
+	if len(fail.Metadata) > 0 {
+		input["metadata"] = fail.Metadata
+	}
+	if err := addField("input_batch", []any{input}); err != nil {
+		return nil, err
+	}
+
+	check := "!errored()"
+	if fConf != nil {
+		for _, inv := range fConf.Invariants {
+			check += " && (" + inv.Check + ")"
+		}
+		if _, err := bloblang.GlobalEnvironment().NewMapping(check); err != nil {
+			check = "!errored()"
+		}
+	}
+	shape := fail.outputShape
+	if len(shape) == 0 {
+		shape = []int{1}
+	}
+	outputBatches := make([]any, len(shape))
+	for i, n := range shape {
+		batch := make([]any, n)
+		for j := range batch {
+			batch[j] = map[string]any{"bloblang": check}
+		}
+		outputBatches[i] = batch
+	}
+	if err := addField("output_batches", outputBatches); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// yamlJSONContent returns a structure that encodes the same JSON document as
+// the provided content when written as YAML, or false if the document cannot
+// be represented exactly.
+func yamlJSONContent(content []byte) (any, bool) {
+	var v any
+	if err := json.Unmarshal(content, &v); err != nil {
+		return nil, false
+	}
+	if _, isStr := v.(string); isStr {
+		return nil, false
+	}
+	reencoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, content); err != nil || !bytes.Equal(compacted.Bytes(), reencoded) {
+		return nil, false
+	}
+	return v, true
+}
+
+// appendTestCaseNodes adds test cases to a test definition. When the list of
+// tests is the final field of a block style definition the cases are appended
+// as text, leaving the remainder of the definition untouched, otherwise the
+// definition is encoded again.
+func appendTestCaseNodes(rawBytes []byte, root, testsNode *yaml.Node, nodes []*yaml.Node) ([]byte, error) {
+	lastValue := root.Content[len(root.Content)-1]
+	if lastValue == testsNode && testsNode.Style&yaml.FlowStyle == 0 && len(testsNode.Content) > 0 {
+		seqBytes, err := docs.MarshalYAML(yaml.Node{Kind: yaml.SequenceNode, Content: nodes})
+		if err != nil {
+			return nil, err
+		}
+
+		indent := strings.Repeat(" ", testsNode.Content[0].Column-3)
+		var buf bytes.Buffer
+		buf.Write(rawBytes)
+		if len(rawBytes) > 0 && rawBytes[len(rawBytes)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(string(seqBytes), "\n"), "\n") {
+			buf.WriteString(indent + line)
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+
+	testsNode.Content = append(testsNode.Content, nodes...)
+	return docs.MarshalYAML(*root)
+}
@@ -0,0 +1,454 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fuzzInput is a generated input message, where the content is either a
+// structured document or, when raw is true, a string of raw bytes.
+type fuzzInput struct {
+	value    any
+	raw      bool
+	metadata map[string]any
+}
+
+func (f fuzzInput) content() []byte {
+	if f.raw {
+		s, _ := f.value.(string)
+		return []byte(s)
+	}
+	b, _ := json.Marshal(f.value)
+	return b
+}
+
+func (f fuzzInput) withValue(v any) fuzzInput {
+	f.value = v
+	return f
+}
+
+// fuzzSeed is an example input from which mutated inputs are derived.
+type fuzzSeed struct {
+	content  []byte
+	metadata map[string]any
+}
+
+func (s fuzzSeed) input() fuzzInput {
+	in := fuzzInput{metadata: s.metadata}
+	dec := json.NewDecoder(bytes.NewReader(s.content))
+	dec.UseNumber()
+	if err := dec.Decode(&in.value); err != nil || dec.More() {
+		in.value, in.raw = string(s.content), true
+	}
+	return in
+}
+
+// fuzzGenerator produces inputs by mutating seeds, and by generating documents
+// from a JSON Schema.
+type fuzzGenerator struct {
+	rng    *rand.Rand
+	seeds  []fuzzSeed
+	schema map[string]any
+}
+
+func (g *fuzzGenerator) next() fuzzInput {
+	if g.schema != nil && (len(g.seeds) == 0 || g.rng.Intn(2) == 0) {
+		// Generated documents can reference values of the schema, which must
+		// not be mutated.
+		in := fuzzInput{value: cloneValue(g.fromSchema(g.schema, 0))}
+		if g.rng.Intn(2) == 0 {
+			in.value = g.mutate(in.value)
+		}
+		return in
+	}
+
+	in := g.seeds[g.rng.Intn(len(g.seeds))].input()
+	for n := 1 + g.rng.Intn(3); n > 0; n-- {
+		if in.raw {
+			s, _ := in.value.(string)
+			in.value = g.mutateString(s)
+		} else {
+			in.value = g.mutate(in.value)
+		}
+	}
+	return in
+}
+
+//------------------------------------------------------------------------------
+
+// mutate changes a random node of a document, the document may be modified in
+// place.
+func (g *fuzzGenerator) mutate(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) > 0 && g.rng.Intn(3) > 0 {
+			keys := sortedKeys(t)
+			k := keys[g.rng.Intn(len(keys))]
+			if g.rng.Intn(4) == 0 {
+				delete(t, k)
+			} else {
+				t[k] = g.mutate(t[k])
+			}
+			return t
+		}
+	case []any:
+		if len(t) > 0 && g.rng.Intn(3) > 0 {
+			i := g.rng.Intn(len(t))
+			if g.rng.Intn(4) == 0 {
+				return append(t[:i:i], t[i+1:]...)
+			}
+			t[i] = g.mutate(t[i])
+			return t
+		}
+	}
+	return g.replace(v)
+}
+
+// replace returns a value that takes the place of a node of a document, which
+// is usually of a different type or an edge case of the same type.
+func (g *fuzzGenerator) replace(v any) any {
+	switch g.rng.Intn(7) {
+	case 0:
+		return nil
+	case 1:
+		return g.randomValue(0)
+	case 2:
+		// Huge arrays, where elements are shared rather than copied.
+		if arr, ok := v.([]any); ok && len(arr) > 1 {
+			v = arr[0]
+		}
+		arr := make([]any, 1000+g.rng.Intn(1000))
+		for i := range arr {
+			arr[i] = v
+		}
+		return arr
+	case 3:
+		switch v.(type) {
+		case map[string]any:
+			return map[string]any{}
+		case []any:
+			return []any{}
+		case string:
+			return ""
+		case json.Number, float64:
+			return json.Number("0")
+		}
+		return false
+	case 4:
+		if s, ok := v.(string); ok {
+			return g.mutateString(s)
+		}
+		return g.edgeString()
+	case 5:
+		return g.edgeNumber()
+	}
+	if obj, ok := v.(map[string]any); ok {
+		obj[g.randomKey()] = g.randomValue(0)
+		return obj
+	}
+	return []any{v}
+}
+
+func (g *fuzzGenerator) mutateString(s string) string {
+	switch g.rng.Intn(5) {
+	case 0:
+		return ""
+	case 1:
+		return s + strings.Repeat("x", 1000+g.rng.Intn(9000))
+	case 2:
+		if len(s) > 0 {
+			i := g.rng.Intn(len(s))
+			return s[:i] + g.edgeString() + s[i:]
+		}
+	case 3:
+		if len(s) > 0 {
+			return s[:g.rng.Intn(len(s))]
+		}
+	}
+	return g.edgeString()
+}
+
+var fuzzEdgeStrings = []string{
+	"", " ", "null", "true", "0", "-1", "NaN", "{", "[]", "\x00", "\n\r\t",
+	"💥", "‮", "ÿ\xfe\xff", "${!content()}", "\"'`", "<script>",
+}
+
+func (g *fuzzGenerator) edgeString() string {
+	return fuzzEdgeStrings[g.rng.Intn(len(fuzzEdgeStrings))]
+}
+
+var fuzzEdgeNumbers = []any{
+	json.Number("0"), json.Number("-1"), json.Number("1"), json.Number("0.5"),
+	json.Number("-0.000001"), json.Number("9223372036854775807"),
+	json.Number("-9223372036854775808"), json.Number("18446744073709551616"),
+	json.Number("1e308"), json.Number("-1e308"),
+}
+
+func (g *fuzzGenerator) edgeNumber() any {
+	return fuzzEdgeNumbers[g.rng.Intn(len(fuzzEdgeNumbers))]
+}
+
+func (g *fuzzGenerator) randomKey() string {
+	keys := []string{"", "id", "type", "value", "unexpected", "__proto__", "a.b", "💥"}
+	return keys[g.rng.Intn(len(keys))]
+}
+
+func (g *fuzzGenerator) randomValue(depth int) any {
+	n := 6
+	if depth > 3 {
+		n = 4
+	}
+	switch g.rng.Intn(n) {
+	case 0:
+		return nil
+	case 1:
+		return g.rng.Intn(2) == 0
+	case 2:
+		return g.edgeNumber()
+	case 3:
+		return g.edgeString()
+	case 4:
+		arr := make([]any, g.rng.Intn(4))
+		for i := range arr {
+			arr[i] = g.randomValue(depth + 1)
+		}
+		return arr
+	}
+	obj := map[string]any{}
+	for i := g.rng.Intn(4); i > 0; i-- {
+		obj[g.randomKey()] = g.randomValue(depth + 1)
+	}
+	return obj
+}
+
+//------------------------------------------------------------------------------
+
+const fuzzMaxSchemaDepth = 8
+
+// fromSchema generates a document that is valid for a JSON Schema, where only
+// a subset of keywords is supported and the rest are ignored.
+func (g *fuzzGenerator) fromSchema(schema map[string]any, depth int) any {
+	if depth > fuzzMaxSchemaDepth {
+		return nil
+	}
+	if c, exists := schema["const"]; exists {
+		return c
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		return enum[g.rng.Intn(len(enum))]
+	}
+	for _, k := range []string{"anyOf", "oneOf", "allOf"} {
+		if subs, ok := schema[k].([]any); ok && len(subs) > 0 {
+			sub := subs[0]
+			if k != "allOf" {
+				sub = subs[g.rng.Intn(len(subs))]
+			}
+			if subSchema, ok := sub.(map[string]any); ok {
+				return g.fromSchema(subSchema, depth+1)
+			}
+		}
+	}
+
+	var typeName string
+	switch t := schema["type"].(type) {
+	case string:
+		typeName = t
+	case []any:
+		if len(t) > 0 {
+			typeName, _ = t[g.rng.Intn(len(t))].(string)
+		}
+	}
+	if typeName == "" {
+		if _, exists := schema["properties"]; exists {
+			typeName = "object"
+		} else if _, exists := schema["items"]; exists {
+			typeName = "array"
+		}
+	}
+
+	switch typeName {
+	case "null":
+		return nil
+	case "boolean":
+		return g.rng.Intn(2) == 0
+	case "integer", "number":
+		lower, upper := -1000.0, 1000.0
+		if v, ok := schemaNumber(schema["minimum"]); ok {
+			lower = v
+		}
+		if v, ok := schemaNumber(schema["maximum"]); ok {
+			upper = v
+		}
+		if upper < lower {
+			upper = lower
+		}
+		n := lower + g.rng.Float64()*(upper-lower)
+		if typeName == "integer" {
+			n = math.Max(math.Ceil(lower), math.Min(math.Floor(upper), math.Round(n)))
+			return json.Number(strconv.FormatInt(int64(n), 10))
+		}
+		return n
+	case "string":
+		minLen, maxLen := 0, 16
+		if v, ok := schemaNumber(schema["minLength"]); ok {
+			minLen = int(v)
+		}
+		if v, ok := schemaNumber(schema["maxLength"]); ok {
+			maxLen = int(v)
+		}
+		if maxLen < minLen {
+			maxLen = minLen
+		}
+		var b strings.Builder
+		for i := minLen + g.rng.Intn(maxLen-minLen+1); i > 0; i-- {
+			b.WriteByte(byte('a' + g.rng.Intn(26)))
+		}
+		return b.String()
+	case "array":
+		minItems, maxItems := 0, 5
+		if v, ok := schemaNumber(schema["minItems"]); ok {
+			minItems = int(v)
+		}
+		if v, ok := schemaNumber(schema["maxItems"]); ok {
+			maxItems = int(v)
+		}
+		if maxItems < minItems {
+			maxItems = minItems
+		}
+		items, _ := schema["items"].(map[string]any)
+		arr := make([]any, minItems+g.rng.Intn(maxItems-minItems+1))
+		for i := range arr {
+			if items != nil {
+				arr[i] = g.fromSchema(items, depth+1)
+			} else {
+				arr[i] = g.randomValue(depth + 1)
+			}
+		}
+		return arr
+	case "object":
+		required := map[string]bool{}
+		if reqs, ok := schema["required"].([]any); ok {
+			for _, r := range reqs {
+				if rStr, ok := r.(string); ok {
+					required[rStr] = true
+				}
+			}
+		}
+		obj := map[string]any{}
+		props, _ := schema["properties"].(map[string]any)
+		for _, k := range sortedKeys(props) {
+			if !required[k] && g.rng.Intn(2) == 0 {
+				continue
+			}
+			propSchema, _ := props[k].(map[string]any)
+			obj[k] = g.fromSchema(propSchema, depth+1)
+		}
+		if additional, _ := schema["additionalProperties"].(bool); additional && g.rng.Intn(2) == 0 {
+			obj[g.randomKey()] = g.randomValue(depth + 1)
+		}
+		return obj
+	}
+	return g.randomValue(depth)
+}
+
+func schemaNumber(v any) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case float64:
+		return t, true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//------------------------------------------------------------------------------
+
+// fuzzMaxShrinkElements is the maximum length of an array for which the
+// removal of each individual element is attempted when shrinking, larger arrays
+// are halved instead.
+const fuzzMaxShrinkElements = 32
+
+// shrinkCandidates returns simpler variants of a document, in the order that
+// they should be attempted.
+func shrinkCandidates(v any) []any {
+	var candidates []any
+	switch t := v.(type) {
+	case map[string]any:
+		keys := sortedKeys(t)
+		for _, k := range keys {
+			c := cloneValue(t).(map[string]any)
+			delete(c, k)
+			candidates = append(candidates, c)
+		}
+		for _, k := range keys {
+			for _, sub := range shrinkCandidates(t[k]) {
+				c := cloneValue(t).(map[string]any)
+				c[k] = sub
+				candidates = append(candidates, c)
+			}
+		}
+	case []any:
+		if len(t) > 1 {
+			candidates = append(candidates, cloneValue(t[:len(t)/2]), cloneValue(t[len(t)/2:]))
+		}
+		if len(t) > fuzzMaxShrinkElements {
+			break
+		}
+		for i := range t {
+			c := cloneValue(t).([]any)
+			candidates = append(candidates, append(c[:i:i], c[i+1:]...))
+		}
+		for i := range t {
+			for _, sub := range shrinkCandidates(t[i]) {
+				c := cloneValue(t).([]any)
+				c[i] = sub
+				candidates = append(candidates, c)
+			}
+		}
+	case string:
+		if t != "" {
+			candidates = append(candidates, "")
+		}
+		if len(t) > 1 {
+			candidates = append(candidates, t[:len(t)/2], t[len(t)/2:])
+		}
+	}
+	return candidates
+}
+
+func cloneValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(t))
+		for k, v := range t {
+			c[k] = cloneValue(v)
+		}
+		return c
+	case []any:
+		c := make([]any, len(t))
+		for i, v := range t {
+			c[i] = cloneValue(v)
+		}
+		return c
+	}
+	return v
+}
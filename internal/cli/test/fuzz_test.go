@@ -0,0 +1,143 @@
+package test_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/cli/test"
+	"github.com/warpstreamlabs/bento/internal/config"
+	tdef "github.com/warpstreamlabs/bento/internal/config/test"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+	"github.com/warpstreamlabs/bento/internal/log"
+)
+
+func TestFuzzCaseSchemaInvariants(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"foo.yaml": `
+pipeline:
+  processors:
+    - mapping: 'root = this'
+`,
+		"schema.json": `{
+  "type": "object",
+  "properties": {
+    "id": { "type": "string", "minLength": 1 },
+    "tags": { "type": "array", "items": { "type": "string" } }
+  },
+  "required": [ "id" ]
+}`,
+	})
+	require.NoError(t, err)
+
+	node, err := docs.UnmarshalYAML([]byte(`
+name: schema test
+fuzz:
+  schema: ./schema.json
+  iterations: 50
+  invariants:
+    - 'this.id.type() == "string"'
+`))
+	require.NoError(t, err)
+
+	c, err := tdef.CaseFromAny(node)
+	require.NoError(t, err)
+
+	provider := test.NewProcessorsProvider(filepath.Join(testDir, "foo.yaml"))
+	failures, err := test.FuzzCase(ifs.OS(), testDir, c, provider, rand.New(rand.NewSource(1)), test.FuzzOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, failures)
+
+	for _, f := range failures {
+		assert.Equal(t, "schema test", f.Name)
+		assert.Contains(t, f.Reason, `invariant 'this.id.type() == "string"' violated`)
+
+		// Minimised inputs are scalars or empty structures, as the id field
+		// is removed or replaced.
+		var v any
+		require.NoError(t, json.Unmarshal(f.Input, &v), string(f.Input))
+		switch doc := v.(type) {
+		case map[string]any:
+			assert.NotContains(t, doc, "tags", string(f.Input))
+		case []any:
+			assert.Empty(t, doc)
+		}
+	}
+
+	// Valid documents never fail.
+	c.Fuzz.Invariants = nil
+	failures, err = test.FuzzCase(ifs.OS(), testDir, c, provider, rand.New(rand.NewSource(1)), test.FuzzOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+}
+
+func TestRunFuzzSavesRegressions(t *testing.T) {
+	definition := `# Tests for foo.
+tests:
+  - name: sum items
+    target_processors: /pipeline/processors
+    input_batch:
+      - json_content: { "items": [ 1, 2, 3 ] }
+        metadata:
+          topic: foos
+    output_batches:
+      - - json_equals: { "total": 6 }
+`
+	testDir, err := initTestFiles(t, map[string]string{
+		"foo.yaml": `
+pipeline:
+  processors:
+    - mapping: 'root.total = this.items.sum()'
+`,
+		"foo_bento_test.yaml": definition,
+	})
+	require.NoError(t, err)
+
+	target := []string{filepath.Join(testDir, "foo.yaml")}
+	require.True(t, test.RunAll(target, config.Spec(), "_bento_test", true, log.Noop(), nil))
+
+	assert.False(t, test.RunFuzz(target, config.Spec(), "_bento_test", log.Noop(), nil, test.FuzzOptions{
+		Iterations: 50,
+		Seed:       1,
+	}))
+
+	defBytes, err := os.ReadFile(filepath.Join(testDir, "foo_bento_test.yaml"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(defBytes), definition), string(defBytes))
+
+	node, err := docs.UnmarshalYAML(defBytes)
+	require.NoError(t, err)
+	cases, err := tdef.FromAny(node)
+	require.NoError(t, err)
+	require.Greater(t, len(cases), 1)
+
+	for i, c := range cases[1:] {
+		assert.Equal(t, "sum items (fuzz regression "+string(rune('1'+i))+")", c.Name)
+		assert.Equal(t, "/pipeline/processors", c.TargetProcessors)
+		require.Len(t, c.InputBatches, 1)
+		require.Len(t, c.InputBatches[0], 1)
+		assert.Equal(t, map[string]any{"topic": "foos"}, c.InputBatches[0][0].Metadata)
+		assert.Less(t, len(c.InputBatches[0][0].Content), 20, c.InputBatches[0][0].Content)
+	}
+
+	// The regression cases fail until the mapping is fixed.
+	assert.False(t, test.RunAll(target, config.Spec(), "_bento_test", true, log.Noop(), nil))
+
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "foo.yaml"), []byte(`
+pipeline:
+  processors:
+    - mapping: |
+        root.total = match this.items {
+          this.type() == "array" => this.filter(i -> i.type() == "number").sum()
+          _ => 0
+        }
+`), 0o644))
+	assert.True(t, test.RunAll(target, config.Spec(), "_bento_test", true, log.Noop(), nil))
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/Jeffail/gabs/v2"
@@ -26,8 +27,10 @@ import (
 )
 
 type cachedConfig struct {
-	mgr   manager.ResourceConfig
-	procs []processor.Config
+	targetPath string
+	mgr        manager.ResourceConfig
+	procs      []processor.Config
+	procPaths  [][]string
 }
 
 // ProcessorsProvider consumes a Bento config and, given a JSON Pointer,
@@ -37,8 +40,10 @@ type ProcessorsProvider struct {
 	resourcesPaths []string
 	cachedConfigs  map[string]cachedConfig
 
-	spec   docs.FieldSpecs
-	logger log.Modular
+	spec            docs.FieldSpecs
+	logger          log.Modular
+	coverage        *Coverage
+	updateSnapshots bool
 }
 
 // NewProcessorsProvider returns a new processors provider aimed at a filepath.
@@ -76,6 +81,22 @@ func OptProcessorsProviderSetLogger(logger log.Modular) func(*ProcessorsProvider
 	}
 }
 
+// OptSetCoverage sets a coverage record that is updated with the components
+// and Bloblang branches exercised by provided processors and streams.
+func OptSetCoverage(c *Coverage) func(*ProcessorsProvider) {
+	return func(p *ProcessorsProvider) {
+		p.coverage = c
+	}
+}
+
+// OptUpdateSnapshots determines whether the snapshot conditions of test cases
+// executed with the provider are rewritten from the messages that they check.
+func OptUpdateSnapshots(update bool) func(*ProcessorsProvider) {
+	return func(p *ProcessorsProvider) {
+		p.updateSnapshots = update
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Provide attempts to extract an array of processors from a Bento config.
@@ -103,6 +124,10 @@ func (p *ProcessorsProvider) ProvideBloblang(pathStr string) ([]processor.V1, er
 	}
 
 	pCtx := parser.GlobalContext().WithImporterRelativeToFile(pathStr)
+	if p.coverage != nil {
+		pCtx = pCtx.WithBranchCoverage(p.coverage.branches)
+		p.coverage.addMappingFile(pathStr, string(mappingBytes))
+	}
 	exec, mapErr := parser.ParseMapping(pCtx, string(mappingBytes))
 	if mapErr != nil {
 		return nil, mapErr
@@ -153,15 +178,25 @@ func (b *bloblangProc) Close(context.Context) error {
 
 //------------------------------------------------------------------------------
 
+// managerOpts returns the options for managers of components from a target
+// config, which record coverage when it is enabled.
+func (p *ProcessorsProvider) managerOpts(targetPath string) []manager.OptFunc {
+	opts := []manager.OptFunc{manager.OptSetLogger(p.logger)}
+	if p.coverage != nil {
+		opts = append(opts, p.coverage.managerOpts(targetPath)...)
+	}
+	return opts
+}
+
 func (p *ProcessorsProvider) initProcs(confs cachedConfig) ([]processor.V1, error) {
-	mgr, err := manager.New(confs.mgr, manager.OptSetLogger(p.logger))
+	mgr, err := manager.New(confs.mgr, p.managerOpts(confs.targetPath)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialise resources: %v", err)
 	}
 
 	procs := make([]processor.V1, len(confs.procs))
 	for i, conf := range confs.procs {
-		if procs[i], err = mgr.NewProcessor(conf); err != nil {
+		if procs[i], err = mgr.IntoPath(confs.procPaths[i]...).NewProcessor(conf); err != nil {
 			return nil, fmt.Errorf("failed to initialise processor index '%v': %v", i, err)
 		}
 	}
@@ -283,6 +318,74 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 	cleanupEnv := setEnvironment(environment)
 	defer cleanupEnv()
 
+	root, labelsToPaths, err := p.readConfigWithMocks(targetPath, environment, mocks)
+	if err != nil {
+		return confs, err
+	}
+
+	confSpec := p.spec
+
+	pConf, err := confSpec.ParsedConfigFromAny(root)
+	if err != nil {
+		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+	}
+
+	mgrWrapper, err := p.resourcesFromParsed(targetPath, pConf, environment)
+	if err != nil {
+		return confs, err
+	}
+
+	// We can clear all input and output resources as they're not used by procs
+	// under any circumstances.
+	mgrWrapper.ResourceInputs = nil
+	mgrWrapper.ResourceOutputs = nil
+
+	confs.targetPath = targetPath
+	confs.mgr = mgrWrapper
+
+	var pathSlice []string
+	if strings.HasPrefix(procPath, "/") {
+		if pathSlice, err = gabs.JSONPointerToSlice(procPath); err != nil {
+			return confs, fmt.Errorf("failed to parse case processors path '%v': %w", procPath, err)
+		}
+	} else {
+		if pathSlice, exists = labelsToPaths[procPath]; !exists {
+			return confs, fmt.Errorf("target for label '%v' failed as the label was not found in the test target file, it is not currently possible to target resources imported separate to the test file", procPath)
+		}
+	}
+
+	if root, err = docs.GetYAMLPath(root, pathSlice...); err != nil {
+		return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
+	}
+
+	// Processors are constructed at the paths they are found within the config
+	// so that their logs and metrics are labelled as they would be normally.
+	basePath := componentPath(pathSlice)
+	if root.Kind == yaml.SequenceNode {
+		for i, n := range root.Content {
+			procConf, err := processor.FromAny(bundle.GlobalEnvironment, n)
+			if err != nil {
+				return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
+			}
+			confs.procs = append(confs.procs, procConf)
+			confs.procPaths = append(confs.procPaths, append(basePath[:len(basePath):len(basePath)], strconv.Itoa(i)))
+		}
+	} else {
+		procConf, err := processor.FromAny(bundle.GlobalEnvironment, root)
+		if err != nil {
+			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
+		}
+		confs.procs = append(confs.procs, procConf)
+		confs.procPaths = append(confs.procPaths, basePath)
+	}
+
+	p.cachedConfigs[cacheKey] = confs
+	return confs, nil
+}
+
+// readConfigWithMocks reads a config file and returns its YAML tree with mocks
+// applied, along with a map of the labels of its components to their paths.
+func (p *ProcessorsProvider) readConfigWithMocks(targetPath string, environment map[string]string, mocks map[string]any) (*yaml.Node, map[string][]string, error) {
 	envVarLookup := func(name string) (string, bool) {
 		if s, ok := environment[name]; ok {
 			return s, true
@@ -297,12 +400,12 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 
 	configBytes, _, _, err := config.ReadFileEnvSwap(ifs.OS(), targetPath, envVarLookup)
 	if err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+		return nil, nil, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
 	root, err := docs.UnmarshalYAML(configBytes)
 	if err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+		return nil, nil, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
 	confSpec := p.spec
@@ -315,100 +418,62 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		}
 		mockPathSlice, err := gabs.JSONPointerToSlice(k)
 		if err != nil {
-			return confs, fmt.Errorf("failed to parse mock path '%v': %w", k, err)
+			return nil, nil, fmt.Errorf("failed to parse mock path '%v': %w", k, err)
 		}
 		if err = setMock(confSpec, root, &v, mockPathSlice...); err != nil {
-			return confs, fmt.Errorf("failed to set mock '%v': %w", k, err)
+			return nil, nil, fmt.Errorf("failed to set mock '%v': %w", k, err)
 		}
 		delete(remainingMocks, k)
 	}
 
 	labelsToPaths := map[string][]string{}
-	if len(remainingMocks) > 0 {
-		confSpec.YAMLLabelsToPaths(bundle.GlobalEnvironment, root, labelsToPaths, nil)
-		for k, v := range remainingMocks {
-			mockPathSlice, exists := labelsToPaths[k]
-			if !exists {
-				return confs, fmt.Errorf("mock for label '%v' could not be applied as the label was not found in the test target file, it is not currently possible to mock resources imported separate to the test file", k)
-			}
-			if err = setMock(confSpec, root, &v, mockPathSlice...); err != nil {
-				return confs, fmt.Errorf("failed to set mock '%v': %w", k, err)
-			}
-			delete(remainingMocks, k)
+	confSpec.YAMLLabelsToPaths(bundle.GlobalEnvironment, root, labelsToPaths, nil)
+	for k, v := range remainingMocks {
+		mockPathSlice, exists := labelsToPaths[k]
+		if !exists {
+			return nil, nil, fmt.Errorf("mock for label '%v' could not be applied as the label was not found in the test target file, it is not currently possible to mock resources imported separate to the test file", k)
+		}
+		if err = setMock(confSpec, root, &v, mockPathSlice...); err != nil {
+			return nil, nil, fmt.Errorf("failed to set mock '%v': %w", k, err)
 		}
+		delete(remainingMocks, k)
 	}
+	return root, labelsToPaths, nil
+}
 
-	pConf, err := confSpec.ParsedConfigFromAny(root)
-	if err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+// resourcesFromParsed returns the resources of a parsed config merged with the
+// resources of any extra resource files.
+func (p *ProcessorsProvider) resourcesFromParsed(targetPath string, pConf *docs.ParsedConfig, environment map[string]string) (manager.ResourceConfig, error) {
+	envVarLookup := func(name string) (string, bool) {
+		if s, ok := environment[name]; ok {
+			return s, true
+		}
+		return os.LookupEnv(name)
 	}
 
 	mgrWrapper, err := manager.FromParsed(bundle.GlobalEnvironment, pConf)
 	if err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+		return mgrWrapper, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
 	for _, path := range p.resourcesPaths {
 		resourceBytes, _, _, err := config.ReadFileEnvSwap(ifs.OS(), path, envVarLookup)
 		if err != nil {
-			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
+			return mgrWrapper, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
 		}
 
 		confNode, err := docs.UnmarshalYAML(resourceBytes)
 		if err != nil {
-			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
+			return mgrWrapper, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
 		}
 
 		extraMgrWrapper, err := manager.FromAny(bundle.GlobalEnvironment, confNode)
 		if err != nil {
-			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
+			return mgrWrapper, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
 		}
 		if err = mgrWrapper.AddFrom(&extraMgrWrapper); err != nil {
-			return confs, fmt.Errorf("failed to merge resources from '%v': %v", path, err)
+			return mgrWrapper, fmt.Errorf("failed to merge resources from '%v': %v", path, err)
 		}
 	}
-
-	// We can clear all input and output resources as they're not used by procs
-	// under any circumstances.
-	mgrWrapper.ResourceInputs = nil
-	mgrWrapper.ResourceOutputs = nil
-
-	confs.mgr = mgrWrapper
-
-	var pathSlice []string
-	if strings.HasPrefix(procPath, "/") {
-		if pathSlice, err = gabs.JSONPointerToSlice(procPath); err != nil {
-			return confs, fmt.Errorf("failed to parse case processors path '%v': %w", procPath, err)
-		}
-	} else {
-		if len(labelsToPaths) == 0 {
-			confSpec.YAMLLabelsToPaths(bundle.GlobalEnvironment, root, labelsToPaths, nil)
-		}
-		if pathSlice, exists = labelsToPaths[procPath]; !exists {
-			return confs, fmt.Errorf("target for label '%v' failed as the label was not found in the test target file, it is not currently possible to target resources imported separate to the test file", procPath)
-		}
-	}
-
-	if root, err = docs.GetYAMLPath(root, pathSlice...); err != nil {
-		return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
-	}
-
-	if root.Kind == yaml.SequenceNode {
-		for _, n := range root.Content {
-			procConf, err := processor.FromAny(bundle.GlobalEnvironment, n)
-			if err != nil {
-				return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
-			}
-			confs.procs = append(confs.procs, procConf)
-		}
-	} else {
-		procConf, err := processor.FromAny(bundle.GlobalEnvironment, root)
-		if err != nil {
-			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
-		}
-		confs.procs = append(confs.procs, procConf)
-	}
-
-	p.cachedConfigs[cacheKey] = confs
-	return confs, nil
+	return mgrWrapper, nil
 }
@@ -0,0 +1,270 @@
+package test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+// TargetResult describes the outcome of executing the test definition of a
+// config target.
+type TargetResult struct {
+	Target string
+
+	// Linted is true when the target was linted, in which case any lint errors
+	// are listed.
+	Linted bool
+	Lints  []docs.Lint
+
+	Cases []CaseResult
+}
+
+// Failed returns true if the target has lint errors or failed cases.
+func (t TargetResult) Failed() bool {
+	if len(t.Lints) > 0 {
+		return true
+	}
+	for _, c := range t.Cases {
+		if len(c.Failures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (t TargetResult) duration() (d time.Duration) {
+	for _, c := range t.Cases {
+		d += c.Duration
+	}
+	return
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+//------------------------------------------------------------------------------
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the results of test targets as a JUnit XML report,
+// where each target is a test suite and each case is a test case. Lint errors
+// of a linted target are reported by an extra test case named "lint".
+func WriteJUnitReport(w io.Writer, results []TargetResult) error {
+	suites := junitTestSuites{Name: "bento"}
+
+	var total time.Duration
+	for _, r := range results {
+		suite := junitTestSuite{
+			Name: r.Target,
+			Time: formatSeconds(r.duration()),
+		}
+		total += r.duration()
+
+		if r.Linted {
+			lintCase := junitTestCase{
+				Name:      "lint",
+				Classname: r.Target,
+				File:      r.Target,
+				Time:      formatSeconds(0),
+			}
+			if len(r.Lints) > 0 {
+				var text string
+				for _, l := range r.Lints {
+					text += l.Error() + "\n"
+				}
+				lintCase.Failure = &junitFailure{
+					Message: fmt.Sprintf("%v lint errors", len(r.Lints)),
+					Text:    text,
+				}
+			}
+			suite.Cases = append(suite.Cases, lintCase)
+		}
+
+		for _, c := range r.Cases {
+			tCase := junitTestCase{
+				Name:      c.Name,
+				Classname: r.Target,
+				File:      r.Target,
+				Line:      c.TestLine,
+				Time:      formatSeconds(c.Duration),
+			}
+			if len(c.Failures) > 0 {
+				var text string
+				for _, f := range c.Failures {
+					text += f.Reason + "\n"
+				}
+				tCase.Failure = &junitFailure{
+					Message: fmt.Sprintf("%v failures", len(c.Failures)),
+					Text:    text,
+				}
+			}
+			suite.Cases = append(suite.Cases, tCase)
+		}
+
+		for _, c := range suite.Cases {
+			suite.Tests++
+			if c.Failure != nil {
+				suite.Failures++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = formatSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+type jsonReport struct {
+	Passed   bool            `json:"passed"`
+	Targets  []jsonTarget    `json:"targets"`
+	Coverage *CoverageReport `json:"coverage,omitempty"`
+}
+
+type jsonTarget struct {
+	Target string     `json:"target"`
+	Passed bool       `json:"passed"`
+	Lints  []string   `json:"lints,omitempty"`
+	Cases  []jsonCase `json:"cases"`
+}
+
+type jsonCase struct {
+	Name            string   `json:"name"`
+	Line            int      `json:"line"`
+	Passed          bool     `json:"passed"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	Failures        []string `json:"failures,omitempty"`
+}
+
+// WriteJSONReport writes the results of test targets, and optionally the
+// coverage of the tests, as a JSON document.
+func WriteJSONReport(w io.Writer, results []TargetResult, coverage *CoverageReport) error {
+	report := jsonReport{
+		Passed:   true,
+		Targets:  []jsonTarget{},
+		Coverage: coverage,
+	}
+	for _, r := range results {
+		t := jsonTarget{
+			Target: r.Target,
+			Passed: !r.Failed(),
+			Cases:  []jsonCase{},
+		}
+		for _, l := range r.Lints {
+			t.Lints = append(t.Lints, l.Error())
+		}
+		for _, c := range r.Cases {
+			jCase := jsonCase{
+				Name:            c.Name,
+				Line:            c.TestLine,
+				Passed:          len(c.Failures) == 0,
+				DurationSeconds: c.Duration.Seconds(),
+			}
+			for _, f := range c.Failures {
+				jCase.Failures = append(jCase.Failures, f.Reason)
+			}
+			t.Cases = append(t.Cases, jCase)
+		}
+		if !t.Passed {
+			report.Passed = false
+		}
+		report.Targets = append(report.Targets, t)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+//------------------------------------------------------------------------------
+
+// PrintCoverage writes a human readable summary of a coverage report, listing
+// each part of the tested configs that was not exercised.
+func PrintCoverage(w io.Writer, report *CoverageReport) {
+	sections := []struct {
+		name  string
+		items []CoverageItem
+	}{
+		{name: "Processors", items: report.Processors},
+		{name: "Switch cases", items: report.SwitchCases},
+		{name: "Catch blocks", items: report.CatchBlocks},
+		{name: "Bloblang branches", items: report.BloblangBranches},
+	}
+
+	fmt.Fprintf(w, "Coverage:\n\n")
+
+	var missed []CoverageItem
+	for _, s := range sections {
+		hit := 0
+		for _, item := range s.items {
+			if item.Hits > 0 {
+				hit++
+			} else {
+				missed = append(missed, item)
+			}
+		}
+		summary := fmt.Sprintf("%v/%v", hit, len(s.items))
+		if len(s.items) > 0 {
+			summary += fmt.Sprintf(" (%.1f%%)", float64(hit)/float64(len(s.items))*100)
+		}
+		fmt.Fprintf(w, "  %-18v %v\n", s.name+":", summary)
+	}
+
+	if len(missed) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nNot exercised:\n\n")
+	for _, item := range missed {
+		location := fmt.Sprintf("%v:%v", item.File, item.Line)
+		if item.Path != "" {
+			location += " " + item.Path
+		}
+		fmt.Fprintf(w, "  %v %v\n", yellow(location), item.Description)
+	}
+}
@@ -0,0 +1,153 @@
+package test_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/cli/test"
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/internal/log"
+)
+
+func TestWriteJUnitReport(t *testing.T) {
+	results := []test.TargetResult{
+		{
+			Target: "foo.yaml",
+			Linted: true,
+			Cases: []test.CaseResult{
+				{Name: "first", TestLine: 3, Duration: 1500 * time.Millisecond},
+				{
+					Name:     "second",
+					TestLine: 10,
+					Duration: 2 * time.Millisecond,
+					Failures: []test.CaseFailure{
+						{Name: "second", TestLine: 10, Reason: "batch 0 message 0: content mismatch"},
+						{Name: "second", TestLine: 10, Reason: "unexpected batch: [foo]"},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, test.WriteJUnitReport(&buf, results))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="bento" tests="3" failures="1" time="1.502">
+  <testsuite name="foo.yaml" tests="3" failures="1" time="1.502">
+    <testcase name="lint" classname="foo.yaml" file="foo.yaml" time="0.000"></testcase>
+    <testcase name="first" classname="foo.yaml" file="foo.yaml" line="3" time="1.500"></testcase>
+    <testcase name="second" classname="foo.yaml" file="foo.yaml" line="10" time="0.002">
+      <failure message="2 failures">batch 0 message 0: content mismatch&#xA;unexpected batch: [foo]&#xA;</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestRunAllReportsAndCoverage(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"foo.yaml": `pipeline:
+  processors:
+    - switch:
+        - check: this.type == "a"
+          processors:
+            - mapping: |
+                root = match this.value {
+                  "x" => "is x"
+                  _ => "other"
+                }
+        - processors:
+            - mapping: 'root = this.value.number()'
+            - catch:
+                - mapping: 'root = if this.type == "b" { "failed b" } else { "failed" }'
+`,
+		"foo_bento_test.yaml": `tests:
+  - name: match x
+    target_processors: /pipeline/processors
+    input_batch:
+      - json_content: { "type": "a", "value": "x" }
+    output_batches:
+      - - content_equals: is x
+  - name: wrong
+    target_processors: /pipeline/processors
+    input_batch:
+      - json_content: { "type": "a", "value": "y" }
+    output_batches:
+      - - content_equals: is x
+`,
+	})
+	require.NoError(t, err)
+
+	jsonPath := filepath.Join(testDir, "report.json")
+	junitPath := filepath.Join(testDir, "report.xml")
+
+	passed := test.RunAllWithReports([]string{filepath.Join(testDir, "foo.yaml")}, config.Spec(), "_bento_test", false, log.Noop(), nil, test.ReportOptions{
+		JUnitPath: junitPath,
+		JSONPath:  jsonPath,
+		Coverage:  true,
+	})
+	assert.False(t, passed)
+
+	junitBytes, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(junitBytes), `<testsuites name="bento" tests="2" failures="1"`)
+
+	jsonBytes, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+
+	var report struct {
+		Passed  bool `json:"passed"`
+		Targets []struct {
+			Target string `json:"target"`
+			Passed bool   `json:"passed"`
+			Cases  []struct {
+				Name     string   `json:"name"`
+				Line     int      `json:"line"`
+				Passed   bool     `json:"passed"`
+				Failures []string `json:"failures"`
+			} `json:"cases"`
+		} `json:"targets"`
+		Coverage *test.CoverageReport `json:"coverage"`
+	}
+	require.NoError(t, json.Unmarshal(jsonBytes, &report))
+
+	assert.False(t, report.Passed)
+	require.Len(t, report.Targets, 1)
+	assert.False(t, report.Targets[0].Passed)
+	require.Len(t, report.Targets[0].Cases, 2)
+	assert.Equal(t, "match x", report.Targets[0].Cases[0].Name)
+	assert.Equal(t, 2, report.Targets[0].Cases[0].Line)
+	assert.True(t, report.Targets[0].Cases[0].Passed)
+	assert.False(t, report.Targets[0].Cases[1].Passed)
+	assert.Equal(t, []string{"batch 0 message 0: content_equals: content mismatch\n  expected: is x\n  received: other"}, report.Targets[0].Cases[1].Failures)
+
+	file := filepath.Join(testDir, "foo.yaml")
+	require.NotNil(t, report.Coverage)
+	assert.Equal(t, []test.CoverageItem{
+		{File: file, Path: "/pipeline/processors/0", Line: 3, Description: "switch", Hits: 2},
+		{File: file, Path: "/pipeline/processors/0/switch/0/processors/0", Line: 6, Description: "mapping", Hits: 2},
+		{File: file, Path: "/pipeline/processors/0/switch/1/processors/0", Line: 12, Description: "mapping", Hits: 0},
+		{File: file, Path: "/pipeline/processors/0/switch/1/processors/1", Line: 13, Description: "catch", Hits: 0},
+		{File: file, Path: "/pipeline/processors/0/switch/1/processors/1/catch/0", Line: 14, Description: "mapping", Hits: 0},
+	}, report.Coverage.Processors)
+	assert.Equal(t, []test.CoverageItem{
+		{File: file, Path: "/pipeline/processors/0/switch/0", Line: 4, Description: "switch case 0", Hits: 2},
+		{File: file, Path: "/pipeline/processors/0/switch/1", Line: 11, Description: "switch case 1", Hits: 0},
+	}, report.Coverage.SwitchCases)
+	assert.Equal(t, []test.CoverageItem{
+		{File: file, Path: "/pipeline/processors/0/switch/1/processors/1/catch", Line: 13, Description: "catch", Hits: 0},
+	}, report.Coverage.CatchBlocks)
+	assert.Equal(t, []test.CoverageItem{
+		{File: file, Path: "/pipeline/processors/0/switch/0/processors/0/mapping", Line: 7, Description: "match expression at column 8: case 0", Hits: 1},
+		{File: file, Path: "/pipeline/processors/0/switch/0/processors/0/mapping", Line: 7, Description: "match expression at column 8: case 1", Hits: 1},
+		{File: file, Path: "/pipeline/processors/0/switch/1/processors/1/catch/0/mapping", Line: 14, Description: "if expression at column 8: else", Hits: 0},
+		{File: file, Path: "/pipeline/processors/0/switch/1/processors/1/catch/0/mapping", Line: 14, Description: "if expression at column 8: if", Hits: 0},
+	}, report.Coverage.BloblangBranches)
+}
@@ -0,0 +1,330 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/config/test"
+	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+const streamInputPipe = "bento_test_input"
+
+// CapturedStream is a running stream where the input has been replaced with a
+// channel of transactions to inject, and outputs have been replaced with
+// channels of the transactions they receive.
+type CapturedStream struct {
+	Input   chan<- message.Transaction
+	Outputs map[string]<-chan message.Transaction
+
+	stop func(ctx context.Context) error
+}
+
+// Stop the stream and its resources without waiting for pending messages.
+func (c *CapturedStream) Stop(ctx context.Context) error {
+	return c.stop(ctx)
+}
+
+// StreamProvider returns streams constructed from a Bento config, where the
+// input and a list of outputs, identified by label or JSON Pointer, are
+// replaced with captures.
+type StreamProvider interface {
+	ProvideStream(target string, environment map[string]string, mocks map[string]any, outputs []string) (*CapturedStream, error)
+}
+
+// ExecuteStreamFrom executes a stream test case from the perspective of a
+// given directory, which is used for obtaining relative condition file
+// imports.
+func ExecuteStreamFrom(fs fs.FS, dir string, c test.Case, provider StreamProvider) (failures []CaseFailure, err error) {
+	return executeStreamFrom(fs, dir, c, provider, false)
+}
+
+func executeStreamFrom(fs fs.FS, dir string, c test.Case, provider StreamProvider, updateSnapshots bool) (failures []CaseFailure, err error) {
+	sConf := c.Stream
+	if lExp, lIn := len(sConf.AckOutcomes), len(c.InputBatches); lExp > 0 && lExp != lIn {
+		return nil, fmt.Errorf("number of ack outcomes (%v) does not match the number of input batches (%v)", lExp, lIn)
+	}
+
+	var inputMsg []message.Batch
+	if inputMsg, err = inputBatches(fs, dir, c); err != nil {
+		return
+	}
+
+	outputs := make([]string, 0, len(sConf.Outputs))
+	for k := range sConf.Outputs {
+		outputs = append(outputs, k)
+	}
+	sort.Strings(outputs)
+
+	var strm *CapturedStream
+	if strm, err = provider.ProvideStream(sConf.Target, c.Environment, c.Mocks, outputs); err != nil {
+		return nil, fmt.Errorf("failed to initialise stream: %v", err)
+	}
+	defer func() {
+		stopCtx, done := context.WithTimeout(context.Background(), time.Second*5)
+		defer done()
+		_ = strm.Stop(stopCtx)
+	}()
+
+	reportFailure := func(reason string) {
+		failures = append(failures, CaseFailure{
+			Name:     c.Name,
+			TestLine: c.Line(),
+			Reason:   reason,
+		})
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), sConf.Timeout)
+	defer done()
+
+	captureCtx, captureDone := context.WithCancel(ctx)
+	defer captureDone()
+
+	var capturedMut sync.Mutex
+	captured := map[string][]message.Batch{}
+
+	var wg sync.WaitGroup
+	for _, k := range outputs {
+		var ackErr error
+		if errStr := sConf.Outputs[k].Error; errStr != "" {
+			ackErr = errors.New(errStr)
+		}
+
+		wg.Add(1)
+		go func(k string, tChan <-chan message.Transaction) {
+			defer wg.Done()
+			for {
+				select {
+				case tran, open := <-tChan:
+					if !open {
+						return
+					}
+					capturedMut.Lock()
+					captured[k] = append(captured[k], tran.Payload.DeepCopy())
+					capturedMut.Unlock()
+					_ = tran.Ack(captureCtx, ackErr)
+				case <-captureCtx.Done():
+					return
+				}
+			}
+		}(k, strm.Outputs[k])
+	}
+
+	// Batches are sent without waiting for prior batches to be acknowledged so
+	// that batching policies and parallel outputs behave as they would
+	// normally.
+	resChans := make([]chan error, 0, len(inputMsg))
+sendLoop:
+	for _, b := range inputMsg {
+		resChan := make(chan error, 1)
+		select {
+		case strm.Input <- message.NewTransaction(b, resChan):
+			resChans = append(resChans, resChan)
+		case <-ctx.Done():
+			break sendLoop
+		}
+	}
+
+	outcomes := make([]error, len(inputMsg))
+	resolved := 0
+resLoop:
+	for i, resChan := range resChans {
+		select {
+		case outcomes[i] = <-resChan:
+			resolved++
+		case <-ctx.Done():
+			break resLoop
+		}
+	}
+	if resolved < len(inputMsg) {
+		reportFailure(fmt.Sprintf("timed out after %v waiting for input batch %v to be acknowledged", sConf.Timeout, resolved))
+	}
+
+	captureDone()
+	wg.Wait()
+
+	for i := 0; i < resolved; i++ {
+		if len(sConf.AckOutcomes) == 0 {
+			if outcomes[i] != nil {
+				reportFailure(fmt.Sprintf("input batch %v was rejected: %v", i, outcomes[i]))
+			}
+			continue
+		}
+		switch exp := sConf.AckOutcomes[i]; {
+		case exp == test.AckOutcomeAck && outcomes[i] != nil:
+			reportFailure(fmt.Sprintf("input batch %v: expected %v, got %v: %v", i, exp, test.AckOutcomeNack, outcomes[i]))
+		case exp == test.AckOutcomeNack && outcomes[i] == nil:
+			reportFailure(fmt.Sprintf("input batch %v: expected %v, got %v", i, exp, test.AckOutcomeAck))
+		}
+	}
+
+	for _, k := range outputs {
+		checkOutputBatches(fs, dir, sConf.Outputs[k].OutputBatches, captured[k], updateSnapshots, func(reason string) {
+			reportFailure(fmt.Sprintf("output '%v': %v", k, reason))
+		})
+	}
+	return
+}
+
+//------------------------------------------------------------------------------
+
+// ProvideStream constructs and runs the stream of a Bento config, where the
+// input is replaced with a channel of transactions and each of the provided
+// outputs, identified by either a label or a JSON Pointer, is replaced with a
+// channel of the transactions that it would have received. Supports injected
+// mocked components in the parsed config.
+func (p *ProcessorsProvider) ProvideStream(target string, environment map[string]string, mocks map[string]any, outputs []string) (*CapturedStream, error) {
+	targetPath := p.targetPath
+	if target != "" {
+		if targetPath = target; !filepath.IsAbs(targetPath) {
+			targetPath = filepath.Join(filepath.Dir(p.targetPath), target)
+		}
+	}
+
+	cleanupEnv := setEnvironment(environment)
+	defer cleanupEnv()
+
+	root, labelsToPaths, err := p.readConfigWithMocks(targetPath, environment, mocks)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setCapture(root, streamInputPipe, "input"); err != nil {
+		return nil, fmt.Errorf("failed to replace input: %w", err)
+	}
+
+	outputPipes := make(map[string]string, len(outputs))
+	for i, k := range outputs {
+		var pathSlice []string
+		if strings.HasPrefix(k, "/") {
+			if pathSlice, err = gabs.JSONPointerToSlice(k); err != nil {
+				return nil, fmt.Errorf("failed to parse output path '%v': %w", k, err)
+			}
+		} else {
+			var exists bool
+			if pathSlice, exists = labelsToPaths[k]; !exists {
+				return nil, fmt.Errorf("capture for label '%v' could not be applied as the label was not found in the test target file", k)
+			}
+		}
+		outputPipes[k] = fmt.Sprintf("bento_test_output_%v", i)
+		if err := setCapture(root, outputPipes[k], pathSlice...); err != nil {
+			return nil, fmt.Errorf("failed to capture output '%v': %w", k, err)
+		}
+	}
+
+	pConf, err := p.spec.ParsedConfigFromAny(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+	}
+
+	mgrConf, err := p.resourcesFromParsed(targetPath, pConf, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Input resources are never consumed as the input of the stream has been
+	// replaced.
+	mgrConf.ResourceInputs = nil
+
+	streamConf, err := stream.FromParsed(bundle.GlobalEnvironment, pConf, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+	}
+
+	mgr, err := manager.New(mgrConf, p.managerOpts(targetPath)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise resources: %v", err)
+	}
+
+	inputChan := make(chan message.Transaction)
+	mgr.SetPipe(streamInputPipe, inputChan)
+
+	stopMgr := func(ctx context.Context) error {
+		mgr.TriggerStopConsuming()
+		mgr.TriggerCloseNow()
+		return mgr.WaitForClose(ctx)
+	}
+
+	strm, err := stream.New(streamConf, mgr)
+	if err != nil {
+		_ = stopMgr(context.Background())
+		return nil, fmt.Errorf("failed to initialise stream: %v", err)
+	}
+
+	s := &CapturedStream{
+		Input:   inputChan,
+		Outputs: make(map[string]<-chan message.Transaction, len(outputPipes)),
+		stop: func(ctx context.Context) error {
+			err := strm.StopUnordered(ctx)
+			if mErr := stopMgr(ctx); err == nil {
+				err = mErr
+			}
+			return err
+		},
+	}
+	for k, pipe := range outputPipes {
+		if s.Outputs[k], err = mgr.GetPipe(pipe); err != nil {
+			_ = s.stop(context.Background())
+			return nil, fmt.Errorf("output '%v' is not used by the stream", k)
+		}
+	}
+	return s, nil
+}
+
+// setCapture replaces the component at a path of a config with an inproc
+// component connected to a named pipe, preserving the label and processors of
+// the original component. When the original component has a batching policy
+// the inproc component is wrapped in a broker with the same policy.
+func setCapture(root *yaml.Node, pipe string, pathSlice ...string) error {
+	node, err := docs.GetYAMLPath(root, pathSlice...)
+	if err != nil {
+		return err
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("expected a component object, got %v", node.Tag)
+	}
+
+	var content []*yaml.Node
+	var batching *yaml.Node
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		switch k, v := node.Content[i].Value, node.Content[i+1]; k {
+		case "label", "processors":
+			content = append(content, node.Content[i], v)
+		default:
+			if batchingNode, _ := docs.GetYAMLPath(v, "batching"); batchingNode != nil {
+				batching = batchingNode
+			}
+		}
+	}
+
+	capture := map[string]any{"inproc": pipe}
+	if batching != nil {
+		capture = map[string]any{
+			"broker": map[string]any{
+				"outputs":  []any{capture},
+				"batching": batching,
+			},
+		}
+	}
+
+	var captureNode yaml.Node
+	if err := captureNode.Encode(capture); err != nil {
+		return err
+	}
+	node.Content = append(content, captureNode.Content...)
+	return nil
+}
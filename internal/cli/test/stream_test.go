@@ -0,0 +1,258 @@
+package test_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/warpstreamlabs/bento/internal/cli/test"
+	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
+
+	ctest "github.com/warpstreamlabs/bento/internal/config/test"
+)
+
+func TestStreamCases(t *testing.T) {
+	config := `
+input:
+  label: real_input
+  generate:
+    mapping: 'root = "not used"'
+    interval: 1ms
+  processors:
+    - mapping: 'root = content().uppercase()'
+
+pipeline:
+  processors:
+    - mapping: 'meta route = if content().has_prefix("A") { "a" } else { "b" }'
+
+output:
+  switch:
+    cases:
+      - check: '@route == "a"'
+        output:
+          label: a_out
+          drop: {}
+      - output:
+          fallback:
+            - label: primary_out
+              drop: {}
+            - label: secondary_out
+              drop: {}
+
+output_resources:
+  - label: batched_out
+    http_client:
+      url: http://localhost:1/not_used
+      batching:
+        count: 2
+`
+
+	tests := []struct {
+		name     string
+		testCase string
+		failures []string
+	}{
+		{
+			name: "switch routing",
+			testCase: `
+name: switch routing
+stream:
+  outputs:
+    a_out:
+      output_batches:
+        - - content_equals: APPLE
+        - - content_equals: AVOCADO
+    primary_out:
+      output_batches:
+        - - content_equals: BANANA
+    secondary_out: {}
+input_batches:
+  - - content: apple
+  - - content: banana
+  - - content: avocado
+`,
+		},
+		{
+			name: "fallback on error",
+			testCase: `
+name: fallback on error
+stream:
+  ack_outcomes: [ ack, ack ]
+  outputs:
+    /output/switch/cases/1/output/fallback/0:
+      error: nope
+      output_batches:
+        - - content_equals: BANANA
+        - - content_equals: CHERRY
+    secondary_out:
+      output_batches:
+        - - content_equals: BANANA
+        - - content_equals: CHERRY
+input_batches:
+  - - content: banana
+  - - content: cherry
+`,
+		},
+		{
+			name: "all outputs fail",
+			testCase: `
+name: all outputs fail
+stream:
+  ack_outcomes: [ ack, nack ]
+  outputs:
+    primary_out:
+      error: nope
+      output_batches:
+        - - content_equals: BANANA
+    secondary_out:
+      error: also nope
+      output_batches:
+        - - content_equals: BANANA
+input_batches:
+  - - content: apple
+  - - content: banana
+`,
+		},
+		{
+			name: "unexpected nack",
+			testCase: `
+name: unexpected nack
+stream:
+  outputs:
+    a_out:
+      error: nope
+      output_batches:
+        - - content_equals: APPLE
+input_batch:
+  - content: apple
+`,
+			failures: []string{
+				"unexpected nack [line 2]: input batch 0 was rejected: nope",
+			},
+		},
+		{
+			name: "wrong routing",
+			testCase: `
+name: wrong routing
+stream:
+  ack_outcomes: [ nack ]
+  outputs:
+    a_out:
+      output_batches:
+        - - content_equals: BANANA
+    primary_out: {}
+input_batch:
+  - content: banana
+`,
+			failures: []string{
+				"wrong routing [line 2]: input batch 0: expected nack, got ack",
+				"wrong routing [line 2]: output 'a_out': wrong batch count, expected 1, got 0",
+				"wrong routing [line 2]: output 'primary_out': unexpected batch: [BANANA]",
+			},
+		},
+		{
+			name: "batching policy",
+			testCase: `
+name: batching policy
+mocks:
+  /output:
+    resource: batched_out
+stream:
+  outputs:
+    batched_out:
+      output_batches:
+        - - content_equals: APPLE
+          - content_equals: BANANA
+input_batches:
+  - - content: apple
+  - - content: banana
+`,
+		},
+	}
+
+	testDir, err := initTestFiles(t, map[string]string{
+		"config.yaml": config,
+	})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var node yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(tt.testCase), &node))
+
+			c, err := ctest.CaseFromAny(&node)
+			require.NoError(t, err)
+			require.NotNil(t, c.Stream)
+
+			provider := test.NewProcessorsProvider(filepath.Join(testDir, "config.yaml"))
+			fails, err := test.ExecuteStreamFrom(ifs.OS(), testDir, c, provider)
+			require.NoError(t, err)
+
+			var failStrs []string
+			for _, f := range fails {
+				failStrs = append(failStrs, f.String())
+			}
+			assert.ElementsMatch(t, tt.failures, failStrs)
+		})
+	}
+}
+
+func TestStreamCaseErrors(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"config.yaml": `
+input:
+  generate:
+    mapping: 'root = "not used"'
+output:
+  label: foo
+  drop: {}
+`,
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		testCase string
+		err      string
+	}{
+		"unknown label": {
+			testCase: `
+name: unknown label
+stream:
+  outputs:
+    bar: {}
+`,
+			err: "failed to initialise stream: capture for label 'bar' could not be applied as the label was not found in the test target file",
+		},
+		"mismatched ack outcomes": {
+			testCase: `
+name: mismatched ack outcomes
+stream:
+  ack_outcomes: [ ack, ack ]
+  outputs:
+    foo: {}
+input_batch:
+  - content: foo
+`,
+			err: "number of ack outcomes (2) does not match the number of input batches (1)",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var node yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(tt.testCase), &node))
+
+			c, err := ctest.CaseFromAny(&node)
+			require.NoError(t, err)
+
+			provider := test.NewProcessorsProvider(filepath.Join(testDir, "config.yaml"))
+			_, err = test.ExecuteStreamFrom(ifs.OS(), testDir, c, provider)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/message"
 	"github.com/warpstreamlabs/bento/internal/old/util/throttle"
 	"github.com/warpstreamlabs/bento/internal/tracing"
+	"github.com/warpstreamlabs/bento/internal/transaction"
 )
 
 // AckFunc is a function used to acknowledge receipt of a message batch from a
@@ -55,6 +56,7 @@ type Stream struct {
 	stats   metrics.Type
 	log     log.Modular
 	tracer  trace.TracerProvider
+	budget  *transaction.Budget
 	typeStr string
 
 	buffer ReaderWriter
@@ -75,6 +77,7 @@ func NewStream(typeStr string, buffer ReaderWriter, mgr component.Observability)
 		stats:       mgr.Metrics(),
 		log:         mgr.Logger(),
 		tracer:      mgr.Tracer(),
+		budget:      mgr.MemoryBudget(),
 		buffer:      buffer,
 		shutSig:     shutdown.NewSignaller(),
 		messagesOut: make(chan message.Transaction),
@@ -183,9 +186,11 @@ func (m *Stream) outputLoop() {
 
 		m.errThrottle.Reset()
 		resChan := make(chan error, 1)
+		tran, releaseBudget := m.budget.NewTransaction(msg, resChan)
 		select {
-		case m.messagesOut <- message.NewTransaction(msg, resChan):
+		case m.messagesOut <- tran:
 		case <-m.shutSig.HardStopChan():
+			releaseBudget()
 			return
 		}
 
@@ -197,6 +202,7 @@ func (m *Stream) outputLoop() {
 
 		go func() {
 			defer ackGroup.Done()
+			defer releaseBudget()
 			select {
 			case res, open := <-resChan:
 				if !open {
@@ -32,6 +32,19 @@ type metricsCache struct {
 	mDelError   metrics.StatCounter
 	mDelSuccess metrics.StatCounter
 	mDelLatency metrics.StatTimer
+
+	mIncrError   metrics.StatCounter
+	mIncrSuccess metrics.StatCounter
+	mIncrLatency metrics.StatTimer
+
+	mCASMismatch metrics.StatCounter
+	mCASError    metrics.StatCounter
+	mCASSuccess  metrics.StatCounter
+	mCASLatency  metrics.StatTimer
+
+	mScanError   metrics.StatCounter
+	mScanSuccess metrics.StatCounter
+	mScanLatency metrics.StatTimer
 }
 
 // MetricsForCache wraps a cache with a struct that adds standard metrics over
@@ -61,6 +74,19 @@ func MetricsForCache(c V1, stats metrics.Type) V1 {
 		mDelError:   cacheError.With("delete"),
 		mDelSuccess: cacheSuccess.With("delete"),
 		mDelLatency: cacheLatency.With("delete"),
+
+		mIncrError:   cacheError.With("increment"),
+		mIncrSuccess: cacheSuccess.With("increment"),
+		mIncrLatency: cacheLatency.With("increment"),
+
+		mCASMismatch: stats.GetCounterVec("cache_mismatch", "operation").With("compare_and_swap"),
+		mCASError:    cacheError.With("compare_and_swap"),
+		mCASSuccess:  cacheSuccess.With("compare_and_swap"),
+		mCASLatency:  cacheLatency.With("compare_and_swap"),
+
+		mScanError:   cacheError.With("scan"),
+		mScanSuccess: cacheSuccess.With("scan"),
+		mScanLatency: cacheLatency.With("scan"),
 	}
 }
 
@@ -80,6 +106,19 @@ func (a *metricsCache) Get(ctx context.Context, key string) ([]byte, error) {
 	return b, err
 }
 
+func (a *metricsCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	started := time.Now()
+	res, err := GetMulti(ctx, a.c, keys...)
+	a.mGetLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mGetError.Incr(int64(len(keys)))
+	} else {
+		a.mGetSuccess.Incr(int64(len(res)))
+		a.mGetNotFound.Incr(int64(len(keys) - len(res)))
+	}
+	return res, err
+}
+
 func (a *metricsCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
 	started := time.Now()
 	err := a.c.Set(ctx, key, value, ttl)
@@ -132,6 +171,46 @@ func (a *metricsCache) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+func (a *metricsCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	started := time.Now()
+	v, err := Increment(ctx, a.c, key, delta, ttl)
+	a.mIncrLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mIncrError.Incr(1)
+	} else {
+		a.mIncrSuccess.Incr(1)
+	}
+	return v, err
+}
+
+func (a *metricsCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	started := time.Now()
+	err := CompareAndSwap(ctx, a.c, key, old, value, ttl)
+	a.mCASLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		if errors.Is(err, component.ErrValueMismatch) {
+			a.mCASMismatch.Incr(1)
+		} else {
+			a.mCASError.Incr(1)
+		}
+	} else {
+		a.mCASSuccess.Incr(1)
+	}
+	return err
+}
+
+func (a *metricsCache) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	started := time.Now()
+	res, err := Scan(ctx, a.c, prefix)
+	a.mScanLatency.Timing(int64(time.Since(started)))
+	if err != nil {
+		a.mScanError.Incr(1)
+	} else {
+		a.mScanSuccess.Incr(1)
+	}
+	return res, err
+}
+
 func (a *metricsCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
@@ -253,3 +254,32 @@ func TestCacheAirGapDelete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, map[string]testCacheItem{}, rl.m)
 }
+
+func TestCacheAirGapFallbacks(t *testing.T) {
+	ctx := context.Background()
+	rl := &closableCache{
+		m: map[string]testCacheItem{
+			"foo": {b: []byte("bar")},
+			"baz": {b: []byte("buz")},
+		},
+	}
+	agrl := MetricsForCache(rl, metrics.Noop())
+
+	res, err := agrl.(MultiGetter).GetMulti(ctx, "foo", "baz", "not exist")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"foo": []byte("bar"),
+		"baz": []byte("buz"),
+	}, res)
+
+	_, err = agrl.(Incrementer).Increment(ctx, "counter", 5, nil)
+	require.ErrorIs(t, err, ErrNotSupported)
+	assert.NotContains(t, rl.m, "counter")
+
+	require.NoError(t, agrl.(CompareAndSwapper).CompareAndSwap(ctx, "new", nil, []byte("a"), nil))
+	require.ErrorIs(t, agrl.(CompareAndSwapper).CompareAndSwap(ctx, "new", nil, []byte("b"), nil), component.ErrValueMismatch)
+	require.ErrorIs(t, agrl.(CompareAndSwapper).CompareAndSwap(ctx, "new", []byte("a"), []byte("b"), nil), ErrNotSupported)
+
+	_, err = agrl.(Scanner).Scan(ctx, "f")
+	require.ErrorIs(t, err, ErrNotSupported)
+}
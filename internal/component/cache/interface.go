@@ -2,7 +2,10 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component"
 )
 
 // TTLItem contains a value to cache along with an optional TTL.
@@ -38,3 +41,101 @@ type V1 interface {
 	// is cancelled.
 	Close(ctx context.Context) error
 }
+
+// ErrNotSupported is returned when a cache operation is not supported by the
+// underlying cache implementation.
+var ErrNotSupported = errors.New("operation not supported by cache")
+
+// MultiGetter is implemented by caches that are able to retrieve multiple keys
+// in as few requests as possible.
+type MultiGetter interface {
+	// GetMulti attempts to locate and return the cached values of multiple
+	// keys. Keys that do not exist are omitted from the result.
+	GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error)
+}
+
+// Incrementer is implemented by caches that are able to atomically increment a
+// counter.
+type Incrementer interface {
+	// Increment atomically adds delta to the integer value of a key and returns
+	// the result. A key that does not exist is treated as zero and the TTL is
+	// applied when the key is created.
+	Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+}
+
+// CompareAndSwapper is implemented by caches that are able to atomically
+// replace the value of a key only when it matches an expected value.
+type CompareAndSwapper interface {
+	// CompareAndSwap sets the value of a key only if its current value matches
+	// old, where a nil old value means that the key must not exist. Returns
+	// component.ErrValueMismatch if the current value does not match.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error
+}
+
+// Scanner is implemented by caches that are able to list keys by prefix.
+type Scanner interface {
+	// Scan returns all keys and their values where the key begins with prefix.
+	Scan(ctx context.Context, prefix string) (map[string][]byte, error)
+}
+
+// GetMulti attempts to retrieve multiple keys from a cache, falling back to
+// individual Get calls when the cache does not support GetMulti. Keys that do
+// not exist are omitted from the result.
+func GetMulti(ctx context.Context, c V1, keys ...string) (map[string][]byte, error) {
+	if mg, ok := c.(MultiGetter); ok {
+		if res, err := mg.GetMulti(ctx, keys...); !errors.Is(err, ErrNotSupported) {
+			return res, err
+		}
+	}
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		v, err := c.Get(ctx, k)
+		if err != nil {
+			if errors.Is(err, component.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		res[k] = v
+	}
+	return res, nil
+}
+
+// Increment attempts to add delta to the integer value of a key, returning
+// ErrNotSupported when the cache does not support Increment as the operation
+// cannot be performed atomically.
+func Increment(ctx context.Context, c V1, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if inc, ok := c.(Incrementer); ok {
+		return inc.Increment(ctx, key, delta, ttl)
+	}
+	return 0, ErrNotSupported
+}
+
+// CompareAndSwap attempts to set the value of a key only if its current value
+// matches old. When the cache does not support CompareAndSwap a nil old value
+// falls back to Add, otherwise ErrNotSupported is returned as the operation
+// cannot be performed safely.
+func CompareAndSwap(ctx context.Context, c V1, key string, old, value []byte, ttl *time.Duration) error {
+	if cas, ok := c.(CompareAndSwapper); ok {
+		if err := cas.CompareAndSwap(ctx, key, old, value, ttl); !errors.Is(err, ErrNotSupported) {
+			return err
+		}
+	}
+	if old != nil {
+		return ErrNotSupported
+	}
+	err := c.Add(ctx, key, value, ttl)
+	if errors.Is(err, component.ErrKeyAlreadyExists) {
+		err = component.ErrValueMismatch
+	}
+	return err
+}
+
+// Scan attempts to list all keys and their values where the key begins with
+// prefix, returning ErrNotSupported when the cache does not support Scan.
+func Scan(ctx context.Context, c V1, prefix string) (map[string][]byte, error) {
+	if s, ok := c.(Scanner); ok {
+		return s.Scan(ctx, prefix)
+	}
+	return nil, ErrNotSupported
+}
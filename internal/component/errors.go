@@ -91,14 +91,24 @@ var (
 	ErrCacheNotFound     = errors.New("cache not found")
 	ErrProcessorNotFound = errors.New("processor not found")
 	ErrRateLimitNotFound = errors.New("rate limit not found")
+	ErrLockNotFound      = errors.New("lock not found")
 	ErrOutputNotFound    = errors.New("output not found")
 	ErrKeyAlreadyExists  = errors.New("key already exists")
 	ErrKeyNotFound       = errors.New("key does not exist")
+	ErrValueMismatch     = errors.New("key value does not match")
 	ErrPipeNotFound      = errors.New("pipe was not found")
 )
 
 //------------------------------------------------------------------------------
 
+// Lock errors.
+var (
+	ErrLockHeld  = errors.New("lock is held by another owner")
+	ErrLeaseLost = errors.New("lease has been lost")
+)
+
+//------------------------------------------------------------------------------
+
 // Buffer errors.
 var (
 	ErrMessageTooLarge = errors.New("message body larger than buffer space")
@@ -155,7 +155,15 @@ func (r *AsyncReader) loop() {
 	mConn.Incr(1)
 	r.connection.Store(component.ConnectionActive(r.mgr))
 
+	budget := r.mgr.MemoryBudget()
+
 	for {
+		// Block until messages in flight have been acknowledged if the
+		// memory budget is exhausted.
+		if err := budget.Wait(closeAtLeisureCtx); err != nil {
+			return
+		}
+
 		msg, ackFn, err := r.reader.ReadBatch(closeAtLeisureCtx)
 
 		// If our reader says it is not connected.
@@ -203,9 +211,11 @@ func (r *AsyncReader) loop() {
 
 		resChan := make(chan error, 1)
 		tracing.InitSpans(r.mgr.Tracer(), traceName, msg)
+		tran, releaseBudget := budget.NewTransaction(msg, resChan)
 		select {
-		case r.transactions <- message.NewTransaction(msg, resChan):
+		case r.transactions <- tran:
 		case <-r.shutSig.SoftStopChan():
+			releaseBudget()
 			return
 		}
 
@@ -216,6 +226,7 @@ func (r *AsyncReader) loop() {
 			rChan chan error,
 		) {
 			defer pendingAcks.Done()
+			defer releaseBudget()
 
 			var res error
 			select {
@@ -15,6 +15,7 @@ import (
 
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/manager/mock"
 	"github.com/warpstreamlabs/bento/internal/message"
 )
@@ -786,3 +787,77 @@ func benchmarkAsyncReaderGenerateN(b *testing.B, capacity int) {
 		}
 	}
 }
+
+type budgetAsyncReader struct {
+	reads chan struct{}
+	acks  chan error
+}
+
+func (r *budgetAsyncReader) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (r *budgetAsyncReader) ReadBatch(ctx context.Context) (message.Batch, input.AsyncAckFn, error) {
+	select {
+	case r.reads <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, component.ErrTypeClosed
+	}
+	return message.QuickBatch([][]byte{[]byte("0123456789")}), func(ctx context.Context, err error) error {
+		r.acks <- err
+		return nil
+	}, nil
+}
+
+func (r *budgetAsyncReader) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestAsyncReaderMemoryBudget(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetMemoryBudget(15, 0))
+	require.NoError(t, err)
+
+	rdr := &budgetAsyncReader{
+		reads: make(chan struct{}),
+		acks:  make(chan error, 10),
+	}
+	r, err := input.NewAsyncReader("foo", rdr, mgr)
+	require.NoError(t, err)
+
+	var trans []message.Transaction
+	for i := 0; i < 2; i++ {
+		select {
+		case <-rdr.reads:
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		select {
+		case tran := <-r.TransactionChan():
+			trans = append(trans, tran)
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+	assert.Equal(t, int64(20), mgr.MemoryBudget().Used())
+
+	// The budget is exhausted and so no more reads are made.
+	select {
+	case <-rdr.reads:
+		t.Fatal("unexpected read")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	require.NoError(t, trans[0].Ack(ctx, nil))
+	select {
+	case <-rdr.reads:
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+	assert.NoError(t, <-rdr.acks)
+
+	r.TriggerCloseNow()
+	require.NoError(t, r.WaitForClose(ctx))
+}
@@ -0,0 +1,35 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+// V1 is a common interface implemented by locks.
+type V1 interface {
+	// Acquire attempts to obtain a lease on a named lock without blocking.
+	// Returns component.ErrLockHeld if the lock is currently held by another
+	// owner.
+	Acquire(ctx context.Context, name string) (Lease, error)
+
+	// Close the component, blocks until either the underlying resources are
+	// cleaned up or the context is cancelled. Returns an error if the context
+	// is cancelled.
+	Close(ctx context.Context) error
+}
+
+// Lease represents ownership of a named lock, which expires unless it is
+// renewed within its TTL.
+type Lease interface {
+	// TTL returns the duration that the lease remains valid for after being
+	// acquired or renewed.
+	TTL() time.Duration
+
+	// Renew extends the lease by its TTL. Returns component.ErrLeaseLost if
+	// the lease has expired or has been taken by another owner.
+	Renew(ctx context.Context) error
+
+	// Release the lease so that the lock may be acquired by another owner
+	// immediately.
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,96 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+type metricsLock struct {
+	l V1
+
+	mAcquireSuccess metrics.StatCounter
+	mAcquireHeld    metrics.StatCounter
+	mAcquireErr     metrics.StatCounter
+	mAcquireLatency metrics.StatTimer
+	mRenewSuccess   metrics.StatCounter
+	mRenewLost      metrics.StatCounter
+	mRenewErr       metrics.StatCounter
+	mRelease        metrics.StatCounter
+	mReleaseErr     metrics.StatCounter
+}
+
+// MetricsForLock wraps a lock with a struct that adds metrics.
+func MetricsForLock(l V1, stats metrics.Type) V1 {
+	return &metricsLock{
+		l: l,
+
+		mAcquireSuccess: stats.GetCounter("lock_acquire_success"),
+		mAcquireHeld:    stats.GetCounter("lock_acquire_held"),
+		mAcquireErr:     stats.GetCounter("lock_acquire_error"),
+		mAcquireLatency: stats.GetTimer("lock_acquire_latency_ns"),
+		mRenewSuccess:   stats.GetCounter("lock_renew_success"),
+		mRenewLost:      stats.GetCounter("lock_renew_lost"),
+		mRenewErr:       stats.GetCounter("lock_renew_error"),
+		mRelease:        stats.GetCounter("lock_release_success"),
+		mReleaseErr:     stats.GetCounter("lock_release_error"),
+	}
+}
+
+func (m *metricsLock) Acquire(ctx context.Context, name string) (Lease, error) {
+	started := time.Now()
+	l, err := m.l.Acquire(ctx, name)
+	m.mAcquireLatency.Timing(int64(time.Since(started)))
+	switch {
+	case err == nil:
+		m.mAcquireSuccess.Incr(1)
+	case errors.Is(err, component.ErrLockHeld):
+		m.mAcquireHeld.Incr(1)
+		return nil, err
+	default:
+		m.mAcquireErr.Incr(1)
+		return nil, err
+	}
+	return &metricsLease{l: l, m: m}, nil
+}
+
+func (m *metricsLock) Close(ctx context.Context) error {
+	return m.l.Close(ctx)
+}
+
+//------------------------------------------------------------------------------
+
+type metricsLease struct {
+	l Lease
+	m *metricsLock
+}
+
+func (l *metricsLease) TTL() time.Duration {
+	return l.l.TTL()
+}
+
+func (l *metricsLease) Renew(ctx context.Context) error {
+	err := l.l.Renew(ctx)
+	switch {
+	case err == nil:
+		l.m.mRenewSuccess.Incr(1)
+	case errors.Is(err, component.ErrLeaseLost):
+		l.m.mRenewLost.Incr(1)
+	default:
+		l.m.mRenewErr.Incr(1)
+	}
+	return err
+}
+
+func (l *metricsLease) Release(ctx context.Context) error {
+	err := l.l.Release(ctx)
+	if err != nil {
+		l.m.mReleaseErr.Incr(1)
+	} else {
+		l.m.mRelease.Incr(1)
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+type testLock struct {
+	held   map[string]bool
+	closed bool
+}
+
+func (t *testLock) Acquire(ctx context.Context, name string) (Lease, error) {
+	if t.held[name] {
+		return nil, component.ErrLockHeld
+	}
+	t.held[name] = true
+	return &testLease{l: t, name: name}, nil
+}
+
+func (t *testLock) Close(ctx context.Context) error {
+	t.closed = true
+	return nil
+}
+
+type testLease struct {
+	l    *testLock
+	name string
+}
+
+func (t *testLease) TTL() time.Duration {
+	return time.Second
+}
+
+func (t *testLease) Renew(ctx context.Context) error {
+	if !t.l.held[t.name] {
+		return component.ErrLeaseLost
+	}
+	return nil
+}
+
+func (t *testLease) Release(ctx context.Context) error {
+	delete(t.l.held, t.name)
+	return nil
+}
+
+func TestLockMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	tl := &testLock{held: map[string]bool{}}
+	stats := metrics.NewLocal()
+	l := MetricsForLock(tl, stats)
+
+	lease, err := l.Acquire(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, lease.TTL())
+
+	_, err = l.Acquire(ctx, "foo")
+	assert.ErrorIs(t, err, component.ErrLockHeld)
+
+	require.NoError(t, lease.Renew(ctx))
+	require.NoError(t, lease.Release(ctx))
+	assert.ErrorIs(t, lease.Renew(ctx), component.ErrLeaseLost)
+
+	require.NoError(t, l.Close(ctx))
+	assert.True(t, tl.closed)
+
+	counters := stats.GetCounters()
+	assert.Equal(t, int64(1), counters["lock_acquire_success"])
+	assert.Equal(t, int64(1), counters["lock_acquire_held"])
+	assert.Equal(t, int64(1), counters["lock_renew_success"])
+	assert.Equal(t, int64(1), counters["lock_renew_lost"])
+	assert.Equal(t, int64(1), counters["lock_release_success"])
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/internal/transaction"
 )
 
 // Observability is an interface implemented by components that provide a range
@@ -17,6 +18,7 @@ type Observability interface {
 	Tracer() trace.TracerProvider
 	Path() []string
 	Label() string
+	MemoryBudget() *transaction.Budget
 }
 
 type mockObs struct{}
@@ -41,6 +43,10 @@ func (m mockObs) Label() string {
 	return ""
 }
 
+func (m mockObs) MemoryBudget() *transaction.Budget {
+	return nil
+}
+
 // NoopObservability returns an implementation of Observability that does
 // nothing.
 func NoopObservability() Observability {
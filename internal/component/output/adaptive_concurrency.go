@@ -0,0 +1,163 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+// AdaptiveConcurrencyConfig describes the bounds and tuning of an adaptive
+// concurrency limit, where the number of writes in flight is adjusted with an
+// additive increase, multiplicative decrease (AIMD) algorithm.
+type AdaptiveConcurrencyConfig struct {
+	// Min is the lowest number of writes that are permitted in flight.
+	Min int
+
+	// Max is the highest number of writes that are permitted in flight.
+	Max int
+
+	// LatencyTolerance is the ratio of the observed write latency against the
+	// lowest latency observed before the limit is reduced.
+	LatencyTolerance float64
+
+	// BackoffRatio is multiplied with the current limit in order to reduce it
+	// after an error or a write exceeding the latency tolerance.
+	BackoffRatio float64
+}
+
+// NewAdaptiveConcurrencyConfig returns an AdaptiveConcurrencyConfig with
+// default values.
+func NewAdaptiveConcurrencyConfig() AdaptiveConcurrencyConfig {
+	return AdaptiveConcurrencyConfig{
+		Min:              1,
+		Max:              256,
+		LatencyTolerance: 2,
+		BackoffRatio:     0.5,
+	}
+}
+
+// baselineDrift is the weight with which the baseline latency moves towards
+// each observed latency, allowing the baseline to recover when the latency of
+// a sink permanently increases.
+const baselineDrift = 0.01
+
+// adaptiveLimiter limits the number of writes in flight to a limit that grows
+// by one for each window of successful writes at the limit, and shrinks by a
+// ratio after errors or writes that are much slower than the fastest writes
+// observed.
+type adaptiveLimiter struct {
+	conf AdaptiveConcurrencyConfig
+
+	mut         sync.Mutex
+	limit       float64
+	inFlight    int
+	baselineNs  float64
+	decreasedAt time.Time
+	changed     chan struct{}
+
+	mLimit metrics.StatGauge
+}
+
+func newAdaptiveLimiter(initial int, conf AdaptiveConcurrencyConfig, stats metrics.Type) *adaptiveLimiter {
+	if conf.Min < 1 {
+		conf.Min = 1
+	}
+	if conf.Max < conf.Min {
+		conf.Max = conf.Min
+	}
+	if conf.BackoffRatio <= 0 || conf.BackoffRatio >= 1 {
+		conf.BackoffRatio = 0.5
+	}
+	if conf.LatencyTolerance < 1 {
+		conf.LatencyTolerance = 1
+	}
+	l := &adaptiveLimiter{
+		conf:    conf,
+		limit:   float64(min(max(initial, conf.Min), conf.Max)),
+		changed: make(chan struct{}),
+		mLimit:  stats.GetGauge("output_concurrency_limit"),
+	}
+	l.mLimit.Set(int64(l.limit))
+	return l
+}
+
+// Limit returns the current number of writes permitted in flight.
+func (l *adaptiveLimiter) Limit() int {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return int(l.limit)
+}
+
+// acquire blocks until a write is permitted, returning the time at which the
+// write began, or an error if the context is cancelled first.
+func (l *adaptiveLimiter) acquire(ctx context.Context) (time.Time, error) {
+	for {
+		l.mut.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mut.Unlock()
+			return time.Now(), nil
+		}
+		changed := l.changed
+		l.mut.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		}
+	}
+}
+
+// release marks a write that began at the provided time as finished, adjusting
+// the limit according to its latency and error.
+func (l *adaptiveLimiter) release(started time.Time, latencyNs int64, err error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	saturated := l.inFlight >= int(l.limit)
+	l.inFlight--
+
+	defer func() {
+		l.mLimit.Set(int64(l.limit))
+		close(l.changed)
+		l.changed = make(chan struct{})
+	}()
+
+	if errors.Is(err, component.ErrTypeClosed) || errors.Is(err, component.ErrNotConnected) {
+		// Connection problems are not a reflection of the load on a sink.
+		return
+	}
+
+	congested := err != nil
+	if err == nil {
+		lat := float64(latencyNs)
+		if l.baselineNs == 0 || lat < l.baselineNs {
+			l.baselineNs = lat
+		} else {
+			l.baselineNs += (lat - l.baselineNs) * baselineDrift
+		}
+		congested = lat > l.baselineNs*l.conf.LatencyTolerance
+	}
+
+	if congested {
+		// Writes that began before the last decrease were sent under the
+		// previous limit and therefore shouldn't decrease it further.
+		if started.Before(l.decreasedAt) {
+			return
+		}
+		l.limit = max(float64(l.conf.Min), l.limit*l.conf.BackoffRatio)
+		l.decreasedAt = time.Now()
+		return
+	}
+
+	// Only grow the limit when it is being reached, otherwise the limit would
+	// grow indefinitely during periods of low traffic.
+	if saturated {
+		l.limit = min(float64(l.conf.Max), l.limit+1/l.limit)
+	}
+}
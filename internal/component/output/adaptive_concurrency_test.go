@@ -0,0 +1,200 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func TestAdaptiveLimiterAIMD(t *testing.T) {
+	stats := metrics.NewLocal()
+	l := newAdaptiveLimiter(2, AdaptiveConcurrencyConfig{
+		Min:              1,
+		Max:              4,
+		LatencyTolerance: 2,
+		BackoffRatio:     0.5,
+	}, stats)
+	assert.Equal(t, 2, l.Limit())
+
+	ctx := context.Background()
+
+	// Saturated successful writes grow the limit additively.
+	for l.Limit() < 4 {
+		limit := l.Limit()
+		starts := make([]time.Time, limit)
+		for i := range starts {
+			var err error
+			starts[i], err = l.acquire(ctx)
+			require.NoError(t, err)
+		}
+		for _, started := range starts {
+			l.release(started, 10, nil)
+		}
+	}
+
+	// The limit never exceeds the max.
+	for i := 0; i < 10; i++ {
+		started, err := l.acquire(ctx)
+		require.NoError(t, err)
+		l.release(started, 10, nil)
+	}
+	assert.Equal(t, 4, l.Limit())
+	assert.Equal(t, int64(4), stats.GetCounters()["output_concurrency_limit"])
+
+	// Writes are blocked at the limit.
+	var starts []time.Time
+	for i := 0; i < 4; i++ {
+		started, err := l.acquire(ctx)
+		require.NoError(t, err)
+		starts = append(starts, started)
+	}
+	blockedCtx, done := context.WithTimeout(ctx, time.Millisecond*10)
+	_, err := l.acquire(blockedCtx)
+	done()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Errors decrease the limit multiplicatively, but only once for writes
+	// that were in flight together.
+	l.release(starts[0], 10, errors.New("throttled"))
+	assert.Equal(t, 2, l.Limit())
+	l.release(starts[1], 10, errors.New("throttled"))
+	assert.Equal(t, 2, l.Limit())
+
+	// Connection errors don't affect the limit.
+	l.release(starts[2], 10, component.ErrNotConnected)
+	assert.Equal(t, 2, l.Limit())
+
+	// Slow writes decrease the limit.
+	l.release(starts[3], 10, nil)
+	started, err := l.acquire(ctx)
+	require.NoError(t, err)
+	l.release(started, 100, nil)
+	assert.Equal(t, 1, l.Limit())
+
+	// The limit never drops below the min.
+	started, err = l.acquire(ctx)
+	require.NoError(t, err)
+	l.release(started, 10, errors.New("throttled"))
+	assert.Equal(t, 1, l.Limit())
+	assert.Equal(t, int64(1), stats.GetCounters()["output_concurrency_limit"])
+}
+
+type throttlingAsyncWriter struct {
+	capacity    int32
+	inFlight    int32
+	maxInFlight int32
+}
+
+func (w *throttlingAsyncWriter) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (w *throttlingAsyncWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
+	n := atomic.AddInt32(&w.inFlight, 1)
+	defer atomic.AddInt32(&w.inFlight, -1)
+	for {
+		m := atomic.LoadInt32(&w.maxInFlight)
+		if n <= m || atomic.CompareAndSwapInt32(&w.maxInFlight, m, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	if n > w.capacity {
+		return errors.New("too many requests")
+	}
+	return nil
+}
+
+func (w *throttlingAsyncWriter) Close(context.Context) error { return nil }
+
+func TestAsyncWriterAdaptiveConcurrency(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	sink := &throttlingAsyncWriter{capacity: 4}
+
+	conf := NewAdaptiveConcurrencyConfig()
+	conf.Max = 32
+	conf.LatencyTolerance = 100
+
+	w, err := NewAdaptiveAsyncWriter("foo", 16, conf, sink, component.NoopObservability())
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, w.Consume(tChan))
+
+	var wg sync.WaitGroup
+	var failed int32
+	for i := 0; i < 500; i++ {
+		resChan := make(chan error, 1)
+		select {
+		case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), resChan):
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := <-resChan; err != nil {
+				atomic.AddInt32(&failed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	limiter := w.(*AsyncWriter).adaptive
+	assert.LessOrEqual(t, limiter.Limit(), 8)
+	assert.Less(t, int(atomic.LoadInt32(&failed)), 250)
+
+	w.TriggerCloseNow()
+	require.NoError(t, w.WaitForClose(ctx))
+}
+
+func TestAsyncWriterAdaptiveConcurrencyLightTraffic(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	sink := &throttlingAsyncWriter{capacity: 100}
+
+	conf := NewAdaptiveConcurrencyConfig()
+	conf.Max = 32
+	conf.LatencyTolerance = 100
+
+	w, err := NewAdaptiveAsyncWriter("foo", 4, conf, sink, component.NoopObservability())
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, w.Consume(tChan))
+
+	// Writes that never reach the limit must not grow it.
+	for i := 0; i < 100; i++ {
+		resChan := make(chan error, 1)
+		select {
+		case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), resChan):
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		select {
+		case err := <-resChan:
+			require.NoError(t, err)
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	assert.Equal(t, 4, w.(*AsyncWriter).adaptive.Limit())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&sink.maxInFlight))
+
+	w.TriggerCloseNow()
+	require.NoError(t, w.WaitForClose(ctx))
+}
@@ -45,6 +45,7 @@ type AsyncWriter struct {
 
 	typeStr     string
 	maxInflight int
+	adaptive    *adaptiveLimiter
 	writer      AsyncSink
 
 	mgr    component.Observability
@@ -74,6 +75,21 @@ func NewAsyncWriter(typeStr string, maxInflight int, w AsyncSink, mgr component.
 	return aWriter, nil
 }
 
+// NewAdaptiveAsyncWriter creates a Streamed implementation around an AsyncSink
+// where the number of writes in flight is adjusted within the bounds of an
+// AdaptiveConcurrencyConfig according to the latency and errors of writes,
+// beginning at maxInflight.
+func NewAdaptiveAsyncWriter(typeStr string, maxInflight int, conf AdaptiveConcurrencyConfig, w AsyncSink, mgr component.Observability) (Streamed, error) {
+	s, err := NewAsyncWriter(typeStr, maxInflight, w, mgr)
+	if err != nil {
+		return nil, err
+	}
+	aWriter := s.(*AsyncWriter)
+	aWriter.adaptive = newAdaptiveLimiter(maxInflight, conf, aWriter.stats)
+	aWriter.maxInflight = aWriter.adaptive.conf.Max
+	return aWriter, nil
+}
+
 //------------------------------------------------------------------------------
 
 func (w *AsyncWriter) latencyMeasuringWrite(ctx context.Context, msg message.Batch) (latencyNs int64, err error) {
@@ -196,13 +212,23 @@ func (w *AsyncWriter) loop() {
 			var open bool
 			select {
 			case ts, open = <-w.transactions:
-				if !open {
-					return
-				}
 			case <-w.shutSig.SoftStopChan():
+			}
+			if !open {
 				return
 			}
 
+			// A slot is only acquired once there is a transaction to write, as
+			// otherwise idle writers would appear to saturate the limit.
+			var started time.Time
+			if w.adaptive != nil {
+				var err error
+				if started, err = w.adaptive.acquire(closeLeisureCtx); err != nil {
+					_ = ts.Ack(closeLeisureCtx, component.ErrTypeClosed)
+					return
+				}
+			}
+
 			w.log.Trace("Attempting to write %v messages to '%v'.\n", ts.Payload.Len(), w.typeStr)
 			_, spans := tracing.WithChildSpans(w.tracer, traceName, ts.Payload)
 
@@ -215,6 +241,10 @@ func (w *AsyncWriter) loop() {
 				mError.Incr(1)
 			}
 
+			if w.adaptive != nil {
+				w.adaptive.release(started, latency, err)
+			}
+
 			// Close immediately if our writer is closed.
 			if errors.Is(err, component.ErrTypeClosed) {
 				return
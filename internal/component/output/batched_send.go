@@ -29,6 +29,10 @@ func sendErrIsFatal(err error) bool {
 //
 // However, if a fatal error is returned such as a connection loss or shut down
 // then it is returned immediately.
+//
+// Messages are sent sequentially within the write of a batch, and therefore
+// the concurrency of batched sends, including adaptive concurrency, is governed
+// by the number of batches that the AsyncWriter has in flight.
 func IterateBatchedSend(msg message.Batch, fn func(int, *message.Part) error) error {
 	if msg.Len() == 1 {
 		return fn(0, msg.Get(0))
@@ -15,9 +15,11 @@ import (
 	batchInternal "github.com/warpstreamlabs/bento/internal/batch"
 	"github.com/warpstreamlabs/bento/internal/batch/policy"
 	"github.com/warpstreamlabs/bento/internal/batch/policy/batchconfig"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/output/batcher"
 	"github.com/warpstreamlabs/bento/internal/manager/mock"
 	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/transaction"
 )
 
 func TestBatcherEarlyTermination(t *testing.T) {
@@ -188,6 +190,53 @@ func TestBatcherBasic(t *testing.T) {
 	wg.Wait()
 }
 
+func TestBatcherMemoryBudget(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	budget := transaction.NewBudget(0, nil, metrics.Noop().GetGauge("used"))
+
+	tInChan := make(chan message.Transaction)
+	resChan := make(chan error, 2)
+
+	policyConf := batchconfig.NewConfig()
+	policyConf.Count = 2
+	batchPol, err := policy.New(policyConf, mock.NewManager())
+	require.NoError(t, err)
+
+	out := &mock.OutputChanneled{}
+
+	b := batcher.New(batchPol, out, mock.NewManager())
+	require.NoError(t, b.Consume(tInChan))
+
+	for _, v := range []string{"foo", "barbaz"} {
+		tran, _ := budget.NewTransaction(message.QuickBatch([][]byte{[]byte(v)}), resChan)
+		select {
+		case tInChan <- tran:
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	var outTr message.Transaction
+	select {
+	case outTr = <-out.TChan:
+	case <-ctx.Done():
+		t.Fatal("timed out")
+	}
+
+	// Messages held by the batch remain counted until it is acknowledged.
+	assert.Equal(t, int64(9), budget.Used())
+	require.NoError(t, outTr.Ack(ctx, nil))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, <-resChan)
+	}
+	assert.Equal(t, int64(0), budget.Used())
+
+	close(tInChan)
+	require.NoError(t, b.WaitForClose(ctx))
+}
+
 func TestBatcherMaxInFlight(t *testing.T) {
 	timeOutCtx, done := context.WithTimeout(context.Background(), time.Second*30)
 	defer done()
@@ -0,0 +1,137 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/quipo/dependencysolver"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+)
+
+// BranchTargets describes the paths of a message that a workflow branch
+// depends on and the paths that it provides. Each path is prefixed by a
+// namespace `metadata` or `path` indicating the source.
+type BranchTargets struct {
+	Used     [][]string
+	Provided [][]string
+}
+
+// RequestMapTargets returns the paths that a branch request map depends on.
+func RequestMapTargets(requestMap *mapping.Executor) [][]string {
+	if requestMap == nil {
+		return nil
+	}
+
+	var paths [][]string
+	_, queryTargets := requestMap.QueryTargets(query.TargetsContext{})
+
+pathLoop:
+	for _, p := range queryTargets {
+		path := make([]string, 0, len(p.Path)+1)
+		switch p.Type {
+		case query.TargetValue:
+			path = append(path, "path")
+		case query.TargetMetadata:
+			path = append(path, "metadata")
+		default:
+			continue pathLoop
+		}
+		paths = append(paths, append(path, p.Path...))
+	}
+	return paths
+}
+
+// ResultMapTargets returns the paths that a branch result map provides.
+func ResultMapTargets(resultMap *mapping.Executor) [][]string {
+	if resultMap == nil {
+		return nil
+	}
+
+	var paths [][]string
+
+pathLoop:
+	for _, p := range resultMap.AssignmentTargets() {
+		path := make([]string, 0, len(p.Path)+1)
+		switch p.Type {
+		case mapping.TargetValue:
+			path = append(path, "path")
+		case mapping.TargetMetadata:
+			path = append(path, "metadata")
+		default:
+			continue pathLoop
+		}
+		paths = append(paths, append(path, p.Path...))
+	}
+	return paths
+}
+
+func depHasPrefix(wanted, provided []string) bool {
+	if len(wanted) < len(provided) {
+		return false
+	}
+	for i, s := range provided {
+		if wanted[i] != s {
+			return false
+		}
+	}
+	return true
+}
+
+func getBranchDeps(id string, wanted [][]string, branches map[string]BranchTargets) []string {
+	dependencies := []string{}
+
+	for k, b := range branches {
+		if k == id {
+			continue
+		}
+		for _, tp := range b.Provided {
+			for _, tn := range wanted {
+				if depHasPrefix(tn, tp) {
+					dependencies = append(dependencies, k)
+					break
+				}
+			}
+		}
+	}
+
+	return dependencies
+}
+
+// ResolveBranchDAG infers the order in which workflow branches are executed
+// from the paths that they depend on and provide. Each tier of the result
+// contains branches that can be executed in parallel, sorted by name.
+func ResolveBranchDAG(branches map[string]BranchTargets) ([][]string, error) {
+	if len(branches) == 0 {
+		return [][]string{}, nil
+	}
+	remaining := map[string]struct{}{}
+
+	var entries []dependencysolver.Entry
+	for id, b := range branches {
+		remaining[id] = struct{}{}
+		entries = append(entries, dependencysolver.Entry{
+			ID: id, Deps: getBranchDeps(id, b.Used, branches),
+		})
+	}
+
+	layers := dependencysolver.LayeredTopologicalSort(entries)
+	for _, l := range layers {
+		sort.Strings(l)
+		for _, id := range l {
+			delete(remaining, id)
+		}
+	}
+
+	if len(remaining) > 0 {
+		var tProcs []string
+		for k := range remaining {
+			tProcs = append(tProcs, k)
+		}
+		sort.Strings(tProcs)
+		return nil, fmt.Errorf("failed to automatically resolve DAG, circular dependencies detected for branches: %v", tProcs)
+	}
+
+	return layers, nil
+}
@@ -0,0 +1,54 @@
+package processor_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+)
+
+func TestBranchMapTargets(t *testing.T) {
+	reqMap, err := bloblang.GlobalEnvironment().NewMapping(`root.id = this.user.id
+root.key = @kafka_key`)
+	require.NoError(t, err)
+
+	resMap, err := bloblang.GlobalEnvironment().NewMapping(`root.user.name = this.name
+meta result = "done"`)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, [][]string{
+		{"path", "user", "id"},
+		{"metadata", "kafka_key"},
+	}, processor.RequestMapTargets(reqMap))
+	assert.ElementsMatch(t, [][]string{
+		{"path", "user", "name"},
+		{"metadata", "result"},
+	}, processor.ResultMapTargets(resMap))
+
+	assert.Nil(t, processor.RequestMapTargets(nil))
+	assert.Nil(t, processor.ResultMapTargets(nil))
+}
+
+func TestResolveBranchDAG(t *testing.T) {
+	dag, err := processor.ResolveBranchDAG(map[string]processor.BranchTargets{
+		"c": {Used: [][]string{{"path", "b", "result"}}},
+		"b": {Used: [][]string{{"path", "a"}}, Provided: [][]string{{"path", "b"}}},
+		"a": {Provided: [][]string{{"path", "a"}}},
+		"z": {Provided: [][]string{{"path", "z"}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "z"}, {"b"}, {"c"}}, dag)
+
+	dag, err = processor.ResolveBranchDAG(nil)
+	require.NoError(t, err)
+	assert.Empty(t, dag)
+
+	_, err = processor.ResolveBranchDAG(map[string]processor.BranchTargets{
+		"a": {Used: [][]string{{"path", "b"}}, Provided: [][]string{{"path", "a"}}},
+		"b": {Used: [][]string{{"path", "a"}}, Provided: [][]string{{"path", "b"}}},
+	})
+	require.EqualError(t, err, "failed to automatically resolve DAG, circular dependencies detected for branches: [a b]")
+}
@@ -2,6 +2,7 @@ package ratelimit
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -18,3 +19,43 @@ type V1 interface {
 	// is cancelled.
 	Close(ctx context.Context) error
 }
+
+// Keyed is implemented by rate limits that track a separate budget for each
+// key, allowing a single resource to limit each tenant independently.
+type Keyed interface {
+	// AccessKey accesses the rate limited resource on behalf of a key. Returns
+	// a duration or an error if the rate limit check fails. The returned
+	// duration is either zero (meaning the resource may be accessed) or a
+	// reasonable length of time to wait before requesting again.
+	AccessKey(ctx context.Context, key string) (time.Duration, error)
+}
+
+// ErrKeyedNotSupported is returned when attempting to access a rate limit with
+// a key when the rate limit does not support keys.
+var ErrKeyedNotSupported = errors.New("rate limit does not support keys")
+
+// AccessKey accesses a rate limit on behalf of a key, returning an error if the
+// rate limit does not support keys. An empty key accesses the rate limit
+// without a key.
+func AccessKey(ctx context.Context, r V1, key string) (time.Duration, error) {
+	if key == "" {
+		return r.Access(ctx)
+	}
+	k, ok := r.(Keyed)
+	if !ok {
+		return 0, ErrKeyedNotSupported
+	}
+	return k.AccessKey(ctx, key)
+}
+
+// SupportsKeys returns true if a rate limit can be accessed with a key. Rate
+// limits that wrap others implement Keyed regardless of whether the wrapped
+// rate limit supports keys, and can therefore report the capability of the
+// wrapped rate limit with a SupportsKeys method.
+func SupportsKeys(r V1) bool {
+	if s, ok := r.(interface{ SupportsKeys() bool }); ok {
+		return s.SupportsKeys()
+	}
+	_, ok := r.(Keyed)
+	return ok
+}
@@ -39,6 +39,21 @@ func (r *metricsRateLimit) Access(ctx context.Context) (time.Duration, error) {
 	return tout, err
 }
 
+func (r *metricsRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	r.mChecked.Incr(1)
+	tout, err := AccessKey(ctx, r.r, key)
+	if err != nil {
+		r.mErr.Incr(1)
+	} else if tout > 0 {
+		r.mLimited.Incr(1)
+	}
+	return tout, err
+}
+
+func (r *metricsRateLimit) SupportsKeys() bool {
+	return SupportsKeys(r.r)
+}
+
 func (r *metricsRateLimit) Close(ctx context.Context) error {
 	return r.r.Close(ctx)
 }
@@ -80,6 +95,21 @@ func (r *metricsMessageAwareRateLimit) Access(ctx context.Context) (time.Duratio
 	return tout, err
 }
 
+func (r *metricsMessageAwareRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	r.mChecked.Incr(1)
+	tout, err := AccessKey(ctx, r.r, key)
+	if err != nil {
+		r.mErr.Incr(1)
+	} else if tout > 0 {
+		r.mLimited.Incr(1)
+	}
+	return tout, err
+}
+
+func (r *metricsMessageAwareRateLimit) SupportsKeys() bool {
+	return SupportsKeys(r.r)
+}
+
 func (r *metricsMessageAwareRateLimit) Close(ctx context.Context) error {
 	return r.r.Close(ctx)
 }
@@ -31,3 +31,23 @@ func TestRateLimitAirGapShutdown(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, rl.closed)
 }
+
+type keyedRateLimit struct {
+	closableRateLimit
+}
+
+func (k *keyedRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return 0, nil
+}
+
+func TestRateLimitSupportsKeys(t *testing.T) {
+	assert.False(t, SupportsKeys(&closableRateLimit{}))
+	assert.True(t, SupportsKeys(&keyedRateLimit{}))
+
+	// Wrappers report the capability of the rate limit they wrap.
+	assert.False(t, SupportsKeys(MetricsForRateLimit(&closableRateLimit{}, metrics.Noop())))
+	assert.True(t, SupportsKeys(MetricsForRateLimit(&keyedRateLimit{}, metrics.Noop())))
+
+	_, err := AccessKey(context.Background(), MetricsForRateLimit(&closableRateLimit{}, metrics.Noop()), "foo")
+	assert.ErrorIs(t, err, ErrKeyedNotSupported)
+}
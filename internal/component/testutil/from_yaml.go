@@ -7,6 +7,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
@@ -95,6 +96,14 @@ func RateLimitFromYAMLWithArgs(confStr string, args ...any) (ratelimit.Config, e
 	return fromYAMLGenericWithArgs(confStr, args, ratelimit.FromAny)
 }
 
+func LockFromYAML(confStr string) (lock.Config, error) {
+	return fromYAMLGeneric(confStr, lock.FromAny)
+}
+
+func LockFromYAMLWithArgs(confStr string, args ...any) (lock.Config, error) {
+	return fromYAMLGenericWithArgs(confStr, args, lock.FromAny)
+}
+
 func TracerFromYAML(confStr string) (tracer.Config, error) {
 	return fromYAMLGeneric(confStr, tracer.FromAny)
 }
@@ -17,6 +17,9 @@ var (
 type ErrMissingEnvVars struct {
 	Variables []string
 
+	// Secrets that could not be resolved, along with the reason.
+	Secrets []string
+
 	// Our best attempt at parsing the config that's missing variables by simply
 	// inserting an empty string. There's a good chance this is still a valid
 	// config! :)
@@ -26,9 +29,17 @@ type ErrMissingEnvVars struct {
 // Error returns a rather sweet error message.
 func (e *ErrMissingEnvVars) Error() string {
 	// TODO: Deduplicate the variables as they might be repeated.
-	return fmt.Sprintf("required environment variables were not set: %v", e.Variables)
+	if len(e.Secrets) == 0 {
+		return fmt.Sprintf("required environment variables were not set: %v", e.Variables)
+	}
+	if len(e.Variables) == 0 {
+		return fmt.Sprintf("required secrets could not be resolved: %v", e.Secrets)
+	}
+	return fmt.Sprintf("required environment variables were not set: %v, and required secrets could not be resolved: %v", e.Variables, e.Secrets)
 }
 
+const secretPrefix = "${secret:"
+
 // ReplaceEnvVariables will search a blob of data for the pattern `${FOO:bar}`,
 // where `FOO` is an environment variable name and `bar` is a default value. The
 // `bar` section (including the colon) can be left out if there is no
@@ -38,14 +49,49 @@ func (e *ErrMissingEnvVars) Error() string {
 // respective environment variable will be read and will replace the pattern. If
 // the environment variable is empty or does not exist then either the default
 // value is used or the field will be left empty.
+//
+// Patterns of the form `${secret:provider:path}`, where `provider` is the name
+// of a registered secret provider, are instead replaced with the value of the
+// secret as resolved by GlobalSecrets.
 func ReplaceEnvVariables(inBytes []byte, lookupFn func(string) (string, bool)) (replaced []byte, err error) {
 	var missingVarsErr ErrMissingEnvVars
 
-	replaced = envRegex.ReplaceAllFunc(inBytes, func(content []byte) []byte {
+	replaced = interpolateEnvVariables(inBytes, lookupFn, GlobalSecrets.Resolve, &missingVarsErr)
+	if len(missingVarsErr.Variables) > 0 || len(missingVarsErr.Secrets) > 0 {
+		missingVarsErr.BestAttempt = replaced
+		err = &missingVarsErr
+		replaced = nil
+		return
+	}
+
+	if bytes.Contains(inBytes, []byte(secretPrefix)) {
+		// Interpolate the config a second time with secret references left in
+		// place so that the fields containing secrets can later be redacted.
+		redacted := interpolateEnvVariables(inBytes, lookupFn, func(ref string) (string, error) {
+			return secretPrefix + ref + "}", nil
+		}, &ErrMissingEnvVars{})
+		GlobalSecrets.trackFields(replaced, redacted)
+	}
+	return
+}
+
+func interpolateEnvVariables(
+	inBytes []byte,
+	lookupFn func(string) (string, bool),
+	secretFn func(string) (string, error),
+	missingVarsErr *ErrMissingEnvVars,
+) []byte {
+	replaced := envRegex.ReplaceAllFunc(inBytes, func(content []byte) []byte {
 		var value string
 		var ok bool
 		if len(content) > 3 {
-			if colonIndex := bytes.IndexByte(content, ':'); colonIndex == -1 {
+			if bytes.HasPrefix(content, []byte(secretPrefix)) && IsSecretRef(string(content[len(secretPrefix):len(content)-1])) {
+				ref := string(content[len(secretPrefix) : len(content)-1])
+				var err error
+				if value, err = secretFn(ref); err != nil {
+					missingVarsErr.Secrets = append(missingVarsErr.Secrets, fmt.Sprintf("%v (%v)", ref, err))
+				}
+			} else if colonIndex := bytes.IndexByte(content, ':'); colonIndex == -1 {
 				varName := string(content[2 : len(content)-1])
 				if value, ok = lookupFn(varName); !ok {
 					missingVarsErr.Variables = append(missingVarsErr.Variables, varName)
@@ -64,12 +110,5 @@ func ReplaceEnvVariables(inBytes []byte, lookupFn func(string) (string, bool)) (
 		}
 		return []byte(value)
 	})
-	replaced = escapedEnvRegex.ReplaceAll(replaced, []byte("$$$1"))
-
-	if len(missingVarsErr.Variables) > 0 {
-		missingVarsErr.BestAttempt = replaced
-		err = &missingVarsErr
-		replaced = nil
-	}
-	return
+	return escapedEnvRegex.ReplaceAll(replaced, []byte("$$$1"))
 }
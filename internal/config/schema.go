@@ -12,6 +12,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/log"
 	"github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/stream"
+	"github.com/warpstreamlabs/bento/internal/transaction"
 )
 
 const (
@@ -20,6 +21,7 @@ const (
 	fieldMetrics            = "metrics"
 	fieldTracer             = "tracer"
 	fieldErrorHandling      = "error_handling"
+	fieldMemoryBudget       = "memory_budget"
 	fieldSystemCloseDelay   = "shutdown_delay"
 	fieldSystemCloseTimeout = "shutdown_timeout"
 	fieldTests              = "tests"
@@ -30,13 +32,14 @@ type Type struct {
 	HTTP                   api.Config `yaml:"http"`
 	stream.Config          `yaml:",inline"`
 	manager.ResourceConfig `yaml:",inline"`
-	Logger                 log.Config           `yaml:"logger"`
-	Metrics                metrics.Config       `yaml:"metrics"`
-	Tracer                 tracer.Config        `yaml:"tracer"`
-	ErrorHandling          errorhandling.Config `yaml:"error_handling"`
-	SystemCloseDelay       string               `yaml:"shutdown_delay"`
-	SystemCloseTimeout     string               `yaml:"shutdown_timeout"`
-	Tests                  []any                `yaml:"tests"`
+	Logger                 log.Config               `yaml:"logger"`
+	Metrics                metrics.Config           `yaml:"metrics"`
+	Tracer                 tracer.Config            `yaml:"tracer"`
+	ErrorHandling          errorhandling.Config     `yaml:"error_handling"`
+	MemoryBudget           transaction.BudgetConfig `yaml:"memory_budget"`
+	SystemCloseDelay       string                   `yaml:"shutdown_delay"`
+	SystemCloseTimeout     string                   `yaml:"shutdown_timeout"`
+	Tests                  []any                    `yaml:"tests"`
 
 	rawSource any
 }
@@ -70,6 +73,12 @@ func errorHandlingFields() docs.FieldSpecs {
 	}
 }
 
+func memoryBudgetFields() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldObject(fieldMemoryBudget, "Limits the number of message bytes held in flight by the process, applying backpressure to inputs when exceeded.").WithChildren(transaction.BudgetSpec()...).Advanced(),
+	}
+}
+
 // Spec returns a docs.FieldSpec for an entire Bento configuration.
 func Spec() docs.FieldSpecs {
 	var httpField = docs.FieldObject(fieldHTTP, "Configures the service-wide HTTP server.").WithChildren(api.Spec()...)
@@ -79,6 +88,7 @@ func Spec() docs.FieldSpecs {
 	fields = append(fields, manager.Spec()...)
 	fields = append(fields, observabilityFields()...)
 	fields = append(fields, errorHandlingFields()...)
+	fields = append(fields, memoryBudgetFields()...)
 	fields = append(fields, test.ConfigSpec().Advanced())
 	return fields
 }
@@ -160,6 +170,14 @@ func noStreamFromParsed(prov docs.Provider, pConf *docs.ParsedConfig, conf *Type
 		conf.ErrorHandling = errorhandling.NewConfig()
 	}
 
+	if pConf.Contains(fieldMemoryBudget) {
+		if conf.MemoryBudget, err = transaction.BudgetConfigFromParsed(pConf.Namespace(fieldMemoryBudget)); err != nil {
+			return
+		}
+	} else {
+		conf.MemoryBudget = transaction.NewBudgetConfig()
+	}
+
 	if pConf.Contains(fieldTests) {
 		var tmpTests []*docs.ParsedConfig
 		if tmpTests, err = pConf.FieldAnyList(fieldTests); err != nil {
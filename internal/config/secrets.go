@@ -0,0 +1,324 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretLookupFunc obtains the value of a secret from a provider, where the
+// path identifies the secret in a format specific to the provider.
+type SecretLookupFunc func(ctx context.Context, path string) (string, error)
+
+var (
+	secretProviderNameRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+	secretProviders    = map[string]SecretLookupFunc{}
+	secretProvidersMut sync.RWMutex
+)
+
+// RegisterSecretProvider adds a secret provider that can be referenced within
+// configs with the interpolation `${secret:<name>:<path>}`.
+func RegisterSecretProvider(name string, fn SecretLookupFunc) error {
+	if !secretProviderNameRegex.MatchString(name) {
+		return fmt.Errorf("secret provider name '%v' does not match the pattern %v", name, secretProviderNameRegex.String())
+	}
+
+	secretProvidersMut.Lock()
+	defer secretProvidersMut.Unlock()
+
+	if _, exists := secretProviders[name]; exists {
+		return fmt.Errorf("secret provider '%v' has already been registered", name)
+	}
+	secretProviders[name] = fn
+	return nil
+}
+
+func getSecretProvider(name string) (SecretLookupFunc, bool) {
+	secretProvidersMut.RLock()
+	defer secretProvidersMut.RUnlock()
+
+	fn, exists := secretProviders[name]
+	return fn, exists
+}
+
+func init() {
+	_ = RegisterSecretProvider("file", func(ctx context.Context, path string) (string, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	})
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	defaultSecretTTL           = 5 * time.Minute
+	defaultSecretLookupTimeout = 30 * time.Second
+)
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// interpolatedField identifies a config field by its name and the value it was
+// given after interpolation.
+type interpolatedField struct {
+	key   string
+	value string
+}
+
+// SecretResolver resolves secret references by calling upon registered secret
+// providers. Values obtained from providers are cached for a TTL period, after
+// which they are refreshed the next time they are resolved. The resolver also
+// keeps track of the config fields that were interpolated with secrets in
+// order to redact them from configs that are displayed.
+type SecretResolver struct {
+	ttl     time.Duration
+	timeout time.Duration
+	nowFn   func() time.Time
+
+	cache  map[string]cachedSecret
+	fields map[interpolatedField]string
+	mut    sync.Mutex
+}
+
+// NewSecretResolver creates a secret resolver with the provided cache TTL. A
+// TTL of zero or less results in values being cached indefinitely.
+func NewSecretResolver(ttl time.Duration) *SecretResolver {
+	return &SecretResolver{
+		ttl:     ttl,
+		timeout: defaultSecretLookupTimeout,
+		nowFn:   time.Now,
+		cache:   map[string]cachedSecret{},
+		fields:  map[interpolatedField]string{},
+	}
+}
+
+// GlobalSecrets is the secret resolver used for config interpolations.
+var GlobalSecrets = NewSecretResolver(defaultSecretTTL)
+
+// SetTTL changes the period of time for which resolved secret values are
+// cached before being refreshed.
+func (s *SecretResolver) SetTTL(ttl time.Duration) {
+	s.mut.Lock()
+	s.ttl = ttl
+	s.mut.Unlock()
+}
+
+// IsSecretRef returns true if a reference (everything after `${secret:` within
+// an interpolation) targets a registered secret provider.
+func IsSecretRef(ref string) bool {
+	name, _, ok := strings.Cut(ref, ":")
+	if !ok {
+		return false
+	}
+	_, exists := getSecretProvider(name)
+	return exists
+}
+
+// Resolve obtains the value of a secret reference of the form
+// `<provider>:<path>`, where the path may optionally be suffixed with
+// `#<key>` in order to extract a single field from a secret containing a JSON
+// object.
+//
+// If a cached value has expired but cannot be refreshed due to a provider
+// error then the stale value is returned.
+func (s *SecretResolver) Resolve(ref string) (string, error) {
+	s.mut.Lock()
+	cached, exists := s.cache[ref]
+	fresh := exists && (s.ttl <= 0 || s.nowFn().Sub(cached.fetchedAt) < s.ttl)
+	s.mut.Unlock()
+	if fresh {
+		return cached.value, nil
+	}
+
+	// The lookup is performed without holding the lock as providers may take
+	// a while to respond, and this shouldn't block the resolution of other
+	// secrets.
+	value, err := s.lookup(ref)
+	if err != nil {
+		if exists {
+			return cached.value, nil
+		}
+		return "", err
+	}
+
+	s.mut.Lock()
+	s.cache[ref] = cachedSecret{
+		value:     value,
+		fetchedAt: s.nowFn(),
+	}
+	s.mut.Unlock()
+	return value, nil
+}
+
+func (s *SecretResolver) lookup(ref string) (string, error) {
+	name, path, ok := strings.Cut(ref, ":")
+	if !ok || path == "" {
+		return "", fmt.Errorf("secret reference '%v' must be of the form <provider>:<path>", ref)
+	}
+
+	fn, exists := getSecretProvider(name)
+	if !exists {
+		return "", fmt.Errorf("secret provider '%v' was not recognised", name)
+	}
+
+	var key string
+	if i := strings.LastIndexByte(path, '#'); i != -1 {
+		path, key = path[:i], path[i+1:]
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), s.timeout)
+	defer done()
+
+	value, err := fn(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return value, nil
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(value), &obj); err != nil {
+		return "", fmt.Errorf("failed to parse secret as a JSON object in order to extract key '%v': %w", key, err)
+	}
+
+	v, exists := obj[key]
+	if !exists {
+		return "", fmt.Errorf("key '%v' was not found within secret", key)
+	}
+	if str, isStr := v.(string); isStr {
+		return str, nil
+	}
+
+	vBytes, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(vBytes), nil
+}
+
+// trackFields walks two versions of the same YAML config, one with secrets
+// interpolated and one with the secret references left in place, and records
+// the fields that differ so that they can be redacted later on. If either
+// version fails to parse then nothing is recorded.
+func (s *SecretResolver) trackFields(interpolated, redacted []byte) {
+	var iNode, rNode yaml.Node
+	if err := yaml.Unmarshal(interpolated, &iNode); err != nil {
+		return
+	}
+	if err := yaml.Unmarshal(redacted, &rNode); err != nil {
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var walk func(key string, iNode, rNode *yaml.Node)
+	walk = func(key string, iNode, rNode *yaml.Node) {
+		if iNode.Kind != rNode.Kind || len(iNode.Content) != len(rNode.Content) {
+			return
+		}
+		switch iNode.Kind {
+		case yaml.ScalarNode:
+			if iNode.Value != rNode.Value {
+				s.fields[interpolatedField{key: key, value: iNode.Value}] = rNode.Value
+			}
+		case yaml.MappingNode:
+			for i := 0; i < len(iNode.Content)-1; i += 2 {
+				walk(iNode.Content[i].Value, iNode.Content[i+1], rNode.Content[i+1])
+			}
+		default:
+			for i := range iNode.Content {
+				walk(key, iNode.Content[i], rNode.Content[i])
+			}
+		}
+	}
+	walk("", &iNode, &rNode)
+}
+
+// Redact returns the value of a config field with any interpolated secrets
+// replaced by the references that they were resolved from. The key is the
+// name of the field, or the name of the field containing the array that the
+// value is an element of. Values of fields that were not interpolated with
+// secrets are returned unchanged.
+func (s *SecretResolver) Redact(key, value string) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if redacted, exists := s.fields[interpolatedField{key: key, value: value}]; exists {
+		return redacted
+	}
+	return value
+}
+
+// RedactYAML replaces the scalar values of a YAML node that were interpolated
+// with secrets with the references that they were resolved from.
+func (s *SecretResolver) RedactYAML(node *yaml.Node) {
+	s.redactYAML("", node)
+}
+
+func (s *SecretResolver) redactYAML(key string, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if redacted := s.Redact(key, node.Value); redacted != node.Value {
+			// The field may have been interpolated as a non-string value, but
+			// the reference is always a string.
+			node.Value, node.Tag = redacted, "!!str"
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			s.redactYAML(node.Content[i].Value, node.Content[i+1])
+		}
+	default:
+		for _, c := range node.Content {
+			s.redactYAML(key, c)
+		}
+	}
+}
+
+// RedactAny replaces the strings of a generic structure, such as a parsed JSON
+// document, that were interpolated with secrets with the references that they
+// were resolved from. A new structure is returned.
+func (s *SecretResolver) RedactAny(v any) any {
+	return s.redactAny("", v)
+}
+
+func (s *SecretResolver) redactAny(key string, v any) any {
+	switch t := v.(type) {
+	case string:
+		return s.Redact(key, t)
+	case map[string]any:
+		newMap := make(map[string]any, len(t))
+		for k, v := range t {
+			newMap[k] = s.redactAny(k, v)
+		}
+		return newMap
+	case []any:
+		newSlice := make([]any, len(t))
+		for i, v := range t {
+			newSlice[i] = s.redactAny(key, v)
+		}
+		return newSlice
+	case int, int64, uint64, float64, bool:
+		// Non-string values may also have been interpolated with secrets, in
+		// which case they're replaced with their string reference.
+		str := fmt.Sprint(t)
+		if redacted := s.Redact(key, str); redacted != str {
+			return redacted
+		}
+	}
+	return v
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	testSecrets = map[string]string{
+		"foo":  "foo secret",
+		"json": `{"user":"bob","pass":"hunter2","port":5432}`,
+	}
+	testSecretsErr   error
+	testSecretsCalls int
+	testSecretsMut   sync.Mutex
+	testSecretsOnce  sync.Once
+)
+
+func registerTestSecrets(t testing.TB) {
+	t.Helper()
+	testSecretsOnce.Do(func() {
+		require.NoError(t, RegisterSecretProvider("test_secrets", func(ctx context.Context, path string) (string, error) {
+			testSecretsMut.Lock()
+			defer testSecretsMut.Unlock()
+
+			testSecretsCalls++
+			if testSecretsErr != nil {
+				return "", testSecretsErr
+			}
+			v, exists := testSecrets[path]
+			if !exists {
+				return "", errors.New("nope")
+			}
+			return v, nil
+		}))
+	})
+}
+
+func setTestSecretsErr(err error) {
+	testSecretsMut.Lock()
+	testSecretsErr = err
+	testSecretsMut.Unlock()
+}
+
+func TestSecretProviderRegister(t *testing.T) {
+	registerTestSecrets(t)
+
+	noop := func(ctx context.Context, path string) (string, error) {
+		return "", nil
+	}
+	require.Error(t, RegisterSecretProvider("test_secrets", noop))
+	require.Error(t, RegisterSecretProvider("Not Valid", noop))
+	require.Error(t, RegisterSecretProvider("", noop))
+
+	assert.True(t, IsSecretRef("test_secrets:foo"))
+	assert.True(t, IsSecretRef("file:/run/secrets/foo"))
+	assert.False(t, IsSecretRef("test_secrets"))
+	assert.False(t, IsSecretRef("nope:foo"))
+}
+
+func TestSecretResolverKeys(t *testing.T) {
+	registerTestSecrets(t)
+
+	r := NewSecretResolver(time.Minute)
+
+	for ref, exp := range map[string]string{
+		"test_secrets:foo":       "foo secret",
+		"test_secrets:json":      `{"user":"bob","pass":"hunter2","port":5432}`,
+		"test_secrets:json#pass": "hunter2",
+		"test_secrets:json#port": "5432",
+	} {
+		v, err := r.Resolve(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, exp, v, ref)
+	}
+
+	for ref, errContains := range map[string]string{
+		"test_secrets:nah":      "nope",
+		"test_secrets:json#nah": "key 'nah' was not found",
+		"test_secrets:foo#bar":  "failed to parse secret as a JSON object",
+		"test_secrets:":         "must be of the form",
+		"does_not_exist:foo":    "was not recognised",
+	} {
+		_, err := r.Resolve(ref)
+		require.Error(t, err, ref)
+		assert.Contains(t, err.Error(), errContains, ref)
+	}
+}
+
+func TestSecretResolverTTL(t *testing.T) {
+	registerTestSecrets(t)
+	t.Cleanup(func() {
+		setTestSecretsErr(nil)
+	})
+
+	now := time.Now()
+	r := NewSecretResolver(time.Minute)
+	r.nowFn = func() time.Time {
+		return now
+	}
+
+	getCalls := func() int {
+		testSecretsMut.Lock()
+		defer testSecretsMut.Unlock()
+		return testSecretsCalls
+	}
+	startCalls := getCalls()
+
+	v, err := r.Resolve("test_secrets:foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo secret", v)
+	assert.Equal(t, startCalls+1, getCalls())
+
+	now = now.Add(time.Second * 30)
+	v, err = r.Resolve("test_secrets:foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo secret", v)
+	assert.Equal(t, startCalls+1, getCalls())
+
+	now = now.Add(time.Minute)
+	v, err = r.Resolve("test_secrets:foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo secret", v)
+	assert.Equal(t, startCalls+2, getCalls())
+
+	// A failed refresh falls back to the stale value.
+	setTestSecretsErr(errors.New("provider down"))
+	now = now.Add(time.Minute)
+	v, err = r.Resolve("test_secrets:foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo secret", v)
+	assert.Equal(t, startCalls+3, getCalls())
+
+	_, err = r.Resolve("test_secrets:json")
+	require.EqualError(t, err, "provider down")
+}
+
+func TestSecretResolverFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo")
+	require.NoError(t, os.WriteFile(path, []byte("file secret\n"), 0o600))
+
+	r := NewSecretResolver(time.Minute)
+
+	v, err := r.Resolve("file:" + path)
+	require.NoError(t, err)
+	assert.Equal(t, "file secret", v)
+
+	_, err = r.Resolve("file:" + filepath.Join(dir, "bar"))
+	require.Error(t, err)
+}
+
+func TestSecretResolverRedact(t *testing.T) {
+	registerTestSecrets(t)
+
+	r := NewSecretResolver(time.Minute)
+	assert.Equal(t, "foo secret", r.Redact("a", "foo secret"))
+
+	r.trackFields([]byte(`
+a: foo secret
+b:
+  - c: bob:hunter2@localhost:5432
+  - bob
+d: 5432
+port: 5432
+`), []byte(`
+a: ${secret:test_secrets:foo}
+b:
+  - c: ${secret:test_secrets:json#user}:${secret:test_secrets:json#pass}@localhost:5432
+  - bob
+d: 5432
+port: ${secret:test_secrets:json#port}
+`))
+
+	assert.Equal(t, "${secret:test_secrets:foo}", r.Redact("a", "foo secret"))
+	assert.Equal(t, "foo secret", r.Redact("b", "foo secret"))
+
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(`
+a: foo secret
+b:
+  - c: bob:hunter2@localhost:5432
+  - bob
+  - e: foo secret bob
+d: 5432
+port: 5432
+`), &node))
+	r.RedactYAML(&node)
+
+	var v any
+	require.NoError(t, node.Decode(&v))
+	assert.Equal(t, map[string]any{
+		"a": "${secret:test_secrets:foo}",
+		"b": []any{
+			map[string]any{"c": "${secret:test_secrets:json#user}:${secret:test_secrets:json#pass}@localhost:5432"},
+			"bob",
+			map[string]any{"e": "foo secret bob"},
+		},
+		"d":    5432,
+		"port": "${secret:test_secrets:json#port}",
+	}, v)
+
+	assert.Equal(t, map[string]any{
+		"a":    "${secret:test_secrets:foo}",
+		"b":    []any{"bob", 10},
+		"d":    5432,
+		"port": "${secret:test_secrets:json#port}",
+	}, r.RedactAny(map[string]any{
+		"a":    "foo secret",
+		"b":    []any{"bob", 10},
+		"d":    5432,
+		"port": 5432,
+	}))
+}
+
+func TestSecretResolverResolveConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	require.NoError(t, RegisterSecretProvider("test_slow_secrets", func(ctx context.Context, path string) (string, error) {
+		if path == "slow" {
+			<-release
+		}
+		return path + " value", nil
+	}))
+
+	r := NewSecretResolver(time.Minute)
+
+	slowRes := make(chan string)
+	go func() {
+		v, _ := r.Resolve("test_slow_secrets:slow")
+		slowRes <- v
+	}()
+
+	// A slow provider must not block the resolution of other secrets.
+	v, err := r.Resolve("test_slow_secrets:fast")
+	require.NoError(t, err)
+	assert.Equal(t, "fast value", v)
+	assert.Equal(t, "foo", r.Redact("a", "foo"))
+
+	close(release)
+	assert.Equal(t, "slow value", <-slowRes)
+}
+
+func TestEnvSwappingSecretsRedacted(t *testing.T) {
+	registerTestSecrets(t)
+
+	res, err := ReplaceEnvVariables([]byte(`
+dsn: postgres://${secret:test_secrets:json#user}:${secret:test_secrets:json#pass}@localhost:5432/db
+user: bob
+port: 5432
+foo: ${BENTO_TEST_FOO}
+`), func(s string) (string, bool) {
+		return "testfoo", true
+	})
+	require.NoError(t, err)
+
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal(res, &node))
+	GlobalSecrets.RedactYAML(&node)
+
+	var v any
+	require.NoError(t, node.Decode(&v))
+	assert.Equal(t, map[string]any{
+		"dsn":  "postgres://${secret:test_secrets:json#user}:${secret:test_secrets:json#pass}@localhost:5432/db",
+		"user": "bob",
+		"port": 5432,
+		"foo":  "testfoo",
+	}, v)
+}
+
+func TestEnvSwappingSecrets(t *testing.T) {
+	registerTestSecrets(t)
+
+	envFn := func(s string) (string, bool) {
+		if s == "BENTO_TEST_FOO" {
+			return "testfoo", true
+		}
+		return "", false
+	}
+
+	tests := map[string]struct {
+		result      string
+		errContains string
+	}{
+		"foo ${secret:test_secrets:foo} baz":                         {result: "foo foo secret baz"},
+		"foo ${secret:test_secrets:json#user} ${BENTO_TEST_FOO} baz": {result: "foo bob testfoo baz"},
+		"foo ${secret:unknown:foo} baz":                              {result: "foo unknown:foo baz"},
+		"foo ${{secret:test_secrets:foo}} baz":                       {result: "foo ${secret:test_secrets:foo} baz"},
+		"foo ${secret:test_secrets:nah} baz":                         {errContains: "required secrets could not be resolved: [test_secrets:nah (nope)]"},
+		"foo ${secret:test_secrets:nah} ${BENTO_TEST_BAR} baz":       {errContains: "required environment variables were not set: [BENTO_TEST_BAR], and required secrets could not be resolved"},
+	}
+
+	for in, test := range tests {
+		res, err := ReplaceEnvVariables([]byte(in), envFn)
+		if test.errContains != "" {
+			require.Error(t, err, in)
+			assert.Contains(t, err.Error(), test.errContains, in)
+			var errEnvMissing *ErrMissingEnvVars
+			require.ErrorAs(t, err, &errEnvMissing)
+			assert.NotEmpty(t, errEnvMissing.BestAttempt)
+		} else {
+			require.NoError(t, err, in)
+			assert.Equal(t, test.result, string(res), in)
+		}
+	}
+}
@@ -13,6 +13,8 @@ const (
 	fieldCaseInputBatch       = "input_batch"
 	fieldCaseInputBatches     = "input_batches"
 	fieldCaseOutputBatches    = "output_batches"
+	fieldCaseStream           = "stream"
+	fieldCaseFuzz             = "fuzz"
 )
 
 // Case contains a definition of a single Bento config test case.
@@ -24,6 +26,8 @@ type Case struct {
 	Mocks            map[string]any
 	InputBatches     [][]InputConfig
 	OutputBatches    [][]OutputConditionsMap
+	Stream           *StreamConfig
+	Fuzz             *FuzzConfig
 
 	line int
 }
@@ -68,6 +72,10 @@ It is also possible to target processors in a separate file by prefixing the tar
 			ArrayOfArrays().Optional().WithChildren(inputFields()...),
 		docs.FieldObject(fieldCaseOutputBatches, "List of output batches.").
 			ArrayOfArrays().Optional().WithChildren(outputFields()...),
+		docs.FieldObject(fieldCaseStream, "Run the whole stream of the target config rather than a set of processors. The input of the config is replaced with the input batches of the test, and the outputs listed are replaced with captures that assert on the messages they receive. When specified the fields `target_processors`, `target_mapping` and `output_batches` are ignored.").
+			HasDefault(nil).WithChildren(streamFields()...),
+		docs.FieldObject(fieldCaseFuzz, "Customise how the target of the test is fuzzed when tests are executed with the flag `--fuzz`. Inputs are generated by mutating the input batches of the test, and from a JSON Schema when specified.").
+			HasDefault(nil).WithChildren(fuzzFields()...),
 	}
 }
 
@@ -143,20 +151,44 @@ func CaseFromParsed(pConf *docs.ParsedConfig) (c Case, err error) {
 		}
 	}
 
-	if pConf.Contains(fieldCaseOutputBatches) {
-		var oBListOfList [][]*docs.ParsedConfig
-		if oBListOfList, err = pConf.FieldObjectListOfLists(fieldCaseOutputBatches); err != nil {
+	if c.OutputBatches, err = outputBatchesFromParsed(pConf, fieldCaseOutputBatches); err != nil {
+		return
+	}
+
+	if v, _ := pConf.Field(fieldCaseStream); v != nil {
+		var sConf StreamConfig
+		if sConf, err = StreamFromParsed(pConf.Namespace(fieldCaseStream)); err != nil {
 			return
 		}
-		for _, ol := range oBListOfList {
-			tmpList := make([]OutputConditionsMap, len(ol))
-			for i, il := range ol {
-				if tmpList[i], err = OutputConditionsFromParsed(il); err != nil {
-					return
-				}
+		c.Stream = &sConf
+	}
+
+	if v, _ := pConf.Field(fieldCaseFuzz); v != nil {
+		var fConf FuzzConfig
+		if fConf, err = FuzzFromParsed(pConf.Namespace(fieldCaseFuzz)); err != nil {
+			return
+		}
+		c.Fuzz = &fConf
+	}
+	return
+}
+
+func outputBatchesFromParsed(pConf *docs.ParsedConfig, field string) (batches [][]OutputConditionsMap, err error) {
+	if !pConf.Contains(field) {
+		return
+	}
+	var oBListOfList [][]*docs.ParsedConfig
+	if oBListOfList, err = pConf.FieldObjectListOfLists(field); err != nil {
+		return
+	}
+	for _, ol := range oBListOfList {
+		tmpList := make([]OutputConditionsMap, len(ol))
+		for i, il := range ol {
+			if tmpList[i], err = OutputConditionsFromParsed(il); err != nil {
+				return
 			}
-			c.OutputBatches = append(c.OutputBatches, tmpList)
 		}
+		batches = append(batches, tmpList)
 	}
 	return
 }
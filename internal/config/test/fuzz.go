@@ -0,0 +1,84 @@
+package test
+
+import (
+	"fmt"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+const (
+	fieldFuzzSchema     = "schema"
+	fieldFuzzIterations = "iterations"
+	fieldFuzzInvariants = "invariants"
+)
+
+// DefaultFuzzIterations is the number of generated inputs that a test case is
+// fuzzed with when not specified.
+const DefaultFuzzIterations = 100
+
+// FuzzConfig describes how the target of a test case is fuzzed when tests are
+// executed in fuzzing mode.
+type FuzzConfig struct {
+	// Schema is either nil, a path relative to the test definition of a JSON
+	// Schema document, or an inline JSON Schema document.
+	Schema     any
+	Iterations int
+	Invariants []FuzzInvariant
+}
+
+// FuzzInvariant is a Bloblang query that must resolve to true for every
+// message resulting from a fuzzed input.
+type FuzzInvariant struct {
+	Check     string
+	Condition OutputCondition
+}
+
+func fuzzFields() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldAnything(fieldFuzzSchema, "An optional JSON Schema that inputs are generated from, either as a path relative to the test definition file or as an inline document. Generated documents are also mutated in order to produce unexpected shapes.",
+			"./schemas/foo.json",
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id":   map[string]any{"type": "string"},
+					"tags": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				},
+				"required": []any{"id"},
+			},
+		).Optional(),
+		docs.FieldInt(fieldFuzzIterations, "The number of generated inputs to execute.").HasDefault(DefaultFuzzIterations),
+		docs.FieldBloblang(fieldFuzzInvariants, "A list of Bloblang queries that must resolve to `true` for every message resulting from a generated input.",
+			[]any{"this.id != null", "@topic.or(\"\") != \"\""},
+		).Array().Optional(),
+	}
+}
+
+// FuzzFromParsed extracts a fuzz config from a parsed config.
+func FuzzFromParsed(pConf *docs.ParsedConfig) (conf FuzzConfig, err error) {
+	if pConf.Contains(fieldFuzzSchema) {
+		if conf.Schema, err = pConf.FieldAny(fieldFuzzSchema); err != nil {
+			return
+		}
+	}
+	if conf.Iterations, err = pConf.FieldInt(fieldFuzzIterations); err != nil {
+		return
+	}
+	if pConf.Contains(fieldFuzzInvariants) {
+		var checks []string
+		if checks, err = pConf.FieldStringList(fieldFuzzInvariants); err != nil {
+			return
+		}
+		for i, check := range checks {
+			var cond *BloblangCondition
+			if cond, err = parseBloblangCondition(check); err != nil {
+				err = fmt.Errorf("%v %v: %w", fieldFuzzInvariants, i, err)
+				return
+			}
+			conf.Invariants = append(conf.Invariants, FuzzInvariant{
+				Check:     check,
+				Condition: cond,
+			})
+		}
+	}
+	return
+}
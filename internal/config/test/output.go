@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -36,6 +37,7 @@ const (
 	fieldOutputFileJSONContains = "file_json_contains"
 	fieldOutputJSONEquals       = "json_equals"
 	fieldOutputJSONContains     = "json_contains"
+	fieldOutputSnapshot         = "snapshot"
 )
 
 func outputFields() docs.FieldSpecs {
@@ -65,6 +67,9 @@ func outputFields() docs.FieldSpecs {
 		docs.FieldString(fieldOutputFileJSONContains, "Checks that both the message and the file contents are valid JSON documents, and that the message is a superset of the condition. Will ignore formatting and ordering differences. The path of the file should be relative to the path of the test file.",
 			"./foo/bar.json",
 		).Optional(),
+		docs.FieldString(fieldOutputSnapshot, "Checks that the contents and metadata of a message are structurally equivalent to those stored in a snapshot file. When the file does not exist it is created from the message, and it can be rewritten after intentional changes by running tests with the flag `--update-snapshots`. The path of the file should be relative to the path of the test file.",
+			"./snapshots/bar.json",
+		).Optional(),
 	}
 }
 
@@ -88,6 +93,32 @@ func (c OutputConditionsMap) CheckAll(fs fs.FS, dir string, part *message.Part)
 	return
 }
 
+// SnapshotUpdater is implemented by output conditions that compare messages
+// against a stored snapshot, which can be rewritten from a message.
+type SnapshotUpdater interface {
+	UpdateSnapshot(fs fs.FS, dir string, part *message.Part) error
+}
+
+// UpdateSnapshots rewrites the snapshot of each condition that stores one from
+// a message.
+func (c OutputConditionsMap) UpdateSnapshots(fs fs.FS, dir string, part *message.Part) (errs []error) {
+	condTypes := []string{}
+	for k := range c {
+		condTypes = append(condTypes, k)
+	}
+	sort.Strings(condTypes)
+	for _, k := range condTypes {
+		u, ok := c[k].(SnapshotUpdater)
+		if !ok {
+			continue
+		}
+		if err := u.UpdateSnapshot(fs, dir, part); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %v", k, err))
+		}
+	}
+	return
+}
+
 func OutputConditionsFromParsed(pConf *docs.ParsedConfig) (m OutputConditionsMap, err error) {
 	m = OutputConditionsMap{}
 	if pConf.Contains(fieldOutputBloblang) {
@@ -180,6 +211,14 @@ func OutputConditionsFromParsed(pConf *docs.ParsedConfig) (m OutputConditionsMap
 		}
 		m[fieldOutputJSONContains] = ContentJSONContainsCondition(tmpStr)
 	}
+
+	if pConf.Contains(fieldOutputSnapshot) {
+		var tmpStr string
+		if tmpStr, err = pConf.FieldString(fieldOutputSnapshot); err != nil {
+			return
+		}
+		m[fieldOutputSnapshot] = SnapshotCondition(tmpStr)
+	}
 	return
 }
 
@@ -307,6 +346,100 @@ func (m MetadataEqualsCondition) Check(fs fs.FS, dir string, p *message.Part) er
 	return nil
 }
 
+// SnapshotCondition checks that the contents and metadata of a message are
+// structurally equivalent to a snapshot stored in a file, creating the file from
+// the message when it does not exist.
+type SnapshotCondition string
+
+type messageSnapshot struct {
+	Content     *string         `json:"content,omitempty"`
+	JSONContent json.RawMessage `json:"json_content,omitempty"`
+	Metadata    map[string]any  `json:"metadata,omitempty"`
+}
+
+// snapshotFromPart returns the snapshot document of a message, where contents
+// that are valid JSON are stored as a structure so that they can be diffed.
+func snapshotFromPart(p *message.Part) ([]byte, error) {
+	var s messageSnapshot
+	if content := p.AsBytes(); json.Valid(content) {
+		s.JSONContent = content
+	} else {
+		str := string(content)
+		s.Content = &str
+	}
+	_ = p.MetaIterMut(func(k string, v any) error {
+		if s.Metadata == nil {
+			s.Metadata = map[string]any{}
+		}
+		s.Metadata[k] = v
+		return nil
+	})
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+func (c SnapshotCondition) Check(fs fs.FS, dir string, p *message.Part) error {
+	relPath := filepath.Join(dir, string(c))
+
+	snapshot, err := snapshotFromPart(p)
+	if err != nil {
+		return err
+	}
+
+	fileContent, err := ifs.ReadFile(fs, relPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return writeSnapshot(fs, relPath, snapshot)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+
+	jdopts := jsondiff.DefaultConsoleOptions()
+	diff, explanation := jsondiff.Compare(snapshot, fileContent, &jdopts)
+	switch diff {
+	case jsondiff.FullMatch:
+		return nil
+	case jsondiff.SecondArgIsInvalidJson:
+		return fmt.Errorf("snapshot file '%v' is not a valid JSON document", relPath)
+	}
+	return fmt.Errorf("snapshot mismatch, run with --update-snapshots to accept the changes\n%v", explanation)
+}
+
+// UpdateSnapshot rewrites the snapshot file from a message.
+func (c SnapshotCondition) UpdateSnapshot(fs fs.FS, dir string, p *message.Part) error {
+	snapshot, err := snapshotFromPart(p)
+	if err != nil {
+		return err
+	}
+	return writeSnapshot(fs, filepath.Join(dir, string(c)), snapshot)
+}
+
+func writeSnapshot(fs fs.FS, path string, snapshot []byte) error {
+	wfs, ok := fs.(ifs.FS)
+	if !ok {
+		return errors.New("filesystem does not support writing snapshot files")
+	}
+	if err := wfs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	f, err := wfs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	_, err = ifs.FileWrite(f, snapshot)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	return nil
+}
+
 func anyValueToJSONTestString(v any) (string, error) {
 	if str, ok := v.(string); ok {
 		return str, nil
@@ -579,3 +579,61 @@ func TestFileJSONContainsCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotCondition(t *testing.T) {
+	color.NoColor = true
+
+	tmpDir := t.TempDir()
+	snapPath := filepath.Join(tmpDir, "snapshots", "foo.json")
+
+	part := message.NewPart([]byte(`{"name":"Bento","id":123456}`))
+	part.MetaSetMut("foo", "bar")
+
+	conds := condsFromYAML(t, `snapshot: ./snapshots/foo.json`)
+	assert.Equal(t, SnapshotCondition("./snapshots/foo.json"), conds["snapshot"])
+
+	// The snapshot is created on the first check.
+	require.Empty(t, conds.CheckAll(ifs.OS(), tmpDir, part))
+
+	snapBytes, err := os.ReadFile(snapPath)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "json_content": {
+    "name": "Bento",
+    "id": 123456
+  },
+  "metadata": {
+    "foo": "bar"
+  }
+}
+`, string(snapBytes))
+
+	// Formatting and ordering differences are ignored.
+	part = message.NewPart([]byte(`{ "id": 123456, "name": "Bento" }`))
+	part.MetaSetMut("foo", "bar")
+	assert.Empty(t, conds.CheckAll(ifs.OS(), tmpDir, part))
+
+	part = message.NewPart([]byte(`{"name":"Bento","id":654321}`))
+	part.MetaSetMut("foo", "baz")
+	errs := conds.CheckAll(ifs.OS(), tmpDir, part)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "snapshot: snapshot mismatch, run with --update-snapshots to accept the changes")
+	assert.Contains(t, errs[0].Error(), `654321 => 123456`)
+	assert.Contains(t, errs[0].Error(), `"baz" => "bar"`)
+
+	require.Empty(t, conds.UpdateSnapshots(ifs.OS(), tmpDir, part))
+	assert.Empty(t, conds.CheckAll(ifs.OS(), tmpDir, part))
+
+	rawPart := message.NewPart([]byte(`hello world`))
+	require.Empty(t, conds.UpdateSnapshots(ifs.OS(), tmpDir, rawPart))
+
+	snapBytes, err = os.ReadFile(snapPath)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "content": "hello world"
+}
+`, string(snapBytes))
+
+	assert.Empty(t, conds.CheckAll(ifs.OS(), tmpDir, rawPart))
+	assert.NotEmpty(t, conds.CheckAll(ifs.OS(), tmpDir, message.NewPart([]byte(`hello there`))))
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+const (
+	fieldStreamTarget      = "target"
+	fieldStreamTimeout     = "timeout"
+	fieldStreamAckOutcomes = "ack_outcomes"
+	fieldStreamOutputs     = "outputs"
+
+	fieldStreamOutputError         = "error"
+	fieldStreamOutputOutputBatches = "output_batches"
+)
+
+// Possible outcomes of an input batch within a stream test.
+const (
+	AckOutcomeAck  = "ack"
+	AckOutcomeNack = "nack"
+)
+
+// StreamConfig describes a test that runs a whole stream, where the input of
+// the target config is replaced with the input batches of the test and
+// outputs are replaced with captures.
+type StreamConfig struct {
+	Target      string
+	Timeout     time.Duration
+	AckOutcomes []string
+	Outputs     map[string]StreamOutputConfig
+}
+
+// StreamOutputConfig describes a captured output of a stream test.
+type StreamOutputConfig struct {
+	Error         string
+	OutputBatches [][]OutputConditionsMap
+}
+
+func streamFields() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldString(fieldStreamTarget, "An optional path, relative to the test definition file, of the config to run. By default the config targeted by the test definition is used.", "../foo.yaml").HasDefault(""),
+		docs.FieldString(fieldStreamTimeout, "The maximum period of time to wait for all input batches to be acknowledged before the test fails.").HasDefault("10s"),
+		docs.FieldString(fieldStreamAckOutcomes, "An optional list of the expected outcome of each input batch, in the order that they are defined. When specified the number of outcomes must match the number of input batches.").
+			HasOptions(AckOutcomeAck, AckOutcomeNack).Array().Optional(),
+		docs.FieldObject(fieldStreamOutputs, "A map of outputs to capture. Keys should contain either a label or a JSON pointer of an output, which is replaced with a capture that records the batches it receives. Outputs that are not captured run as configured.").
+			Map().Optional().WithChildren(
+			docs.FieldString(fieldStreamOutputError, "An optional error to reject every batch with, emulating an output that fails to write. Rejected batches are still recorded by the capture.").HasDefault(""),
+			docs.FieldObject(fieldStreamOutputOutputBatches, "The batches expected to be received by the output, in the order that they arrive. When omitted the output is expected to receive nothing.").
+				ArrayOfArrays().Optional().WithChildren(outputFields()...),
+		),
+	}
+}
+
+// StreamFromParsed extracts a stream test config from a parsed config.
+func StreamFromParsed(pConf *docs.ParsedConfig) (conf StreamConfig, err error) {
+	if conf.Target, err = pConf.FieldString(fieldStreamTarget); err != nil {
+		return
+	}
+	if conf.Timeout, err = pConf.FieldDuration(fieldStreamTimeout); err != nil {
+		return
+	}
+	if pConf.Contains(fieldStreamAckOutcomes) {
+		if conf.AckOutcomes, err = pConf.FieldStringList(fieldStreamAckOutcomes); err != nil {
+			return
+		}
+		for i, o := range conf.AckOutcomes {
+			if o != AckOutcomeAck && o != AckOutcomeNack {
+				err = fmt.Errorf("ack outcome %v: expected %v or %v, got %v", i, AckOutcomeAck, AckOutcomeNack, o)
+				return
+			}
+		}
+	}
+	conf.Outputs = map[string]StreamOutputConfig{}
+	if pConf.Contains(fieldStreamOutputs) {
+		var outputs map[string]*docs.ParsedConfig
+		if outputs, err = pConf.FieldObjectMap(fieldStreamOutputs); err != nil {
+			return
+		}
+		for k, v := range outputs {
+			var oConf StreamOutputConfig
+			if oConf.Error, err = v.FieldString(fieldStreamOutputError); err != nil {
+				return
+			}
+			if oConf.OutputBatches, err = outputBatchesFromParsed(v, fieldStreamOutputOutputBatches); err != nil {
+				return
+			}
+			conf.Outputs[k] = oConf
+		}
+	}
+	return
+}
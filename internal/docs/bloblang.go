@@ -1,6 +1,8 @@
 package docs
 
 import (
+	"errors"
+
 	"github.com/warpstreamlabs/bento/public/bloblang"
 )
 
@@ -16,6 +18,9 @@ func LintBloblangMapping(ctx LintContext, line, col int, v any) []Lint {
 	}
 	_, err := ctx.conf.BloblangEnv.Parse(str)
 	if err == nil {
+		if ctx.conf.BloblangTypeCheck {
+			return lintBloblangTypes(ctx, line, col, str)
+		}
 		return nil
 	}
 	if mErr, ok := err.(*bloblang.ParseError); ok {
@@ -26,6 +31,26 @@ func LintBloblangMapping(ctx LintContext, line, col int, v any) []Lint {
 	return []Lint{NewLintError(line, LintBadBloblang, err)}
 }
 
+func lintBloblangTypes(ctx LintContext, line, col int, mapping string) []Lint {
+	issues, err := ctx.conf.BloblangEnv.TypeCheck(mapping, ctx.conf.BloblangInputSchema)
+	if err != nil {
+		return []Lint{NewLintError(line, LintBadBloblang, err)}
+	}
+
+	var lints []Lint
+	for _, issue := range issues {
+		var lint Lint
+		if issue.Unreachable {
+			lint = NewLintWarning(line+issue.Line-1, LintUnreachableBloblang, issue.Message)
+		} else {
+			lint = NewLintError(line+issue.Line-1, LintBadBloblang, errors.New(issue.Message))
+		}
+		lint.Column = col + issue.Column
+		lints = append(lints, lint)
+	}
+	return lints
+}
+
 // LintBloblangField is function for linting a config field expected to be an
 // interpolation string.
 func LintBloblangField(ctx LintContext, line, col int, v any) []Lint {
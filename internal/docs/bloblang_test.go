@@ -53,6 +53,69 @@ func TestLintBloblangMapping(t *testing.T) {
 	}
 }
 
+func TestLintBloblangMappingTypeCheck(t *testing.T) {
+	type Test struct {
+		mapping   string
+		schema    string
+		wantLints []docs.Lint
+	}
+	tests := map[string]Test{
+		"no type checking issues": {
+			mapping: `root.foo = this.foo.uppercase()`,
+		},
+		"type error": {
+			mapping: `root.foo = "bar"
+root.bar = 10.uppercase()`,
+			wantLints: []docs.Lint{
+				{
+					Line:   3,
+					Column: 5,
+					Level:  docs.LintError,
+					Type:   docs.LintBadBloblang,
+					What:   `number literal: expected string or bytes value, got number`,
+				},
+			},
+		},
+		"type error from input schema": {
+			mapping: `root.foo = this.foo.uppercase()`,
+			schema:  `{"type":"object","properties":{"foo":{"type":"number"}},"required":["foo"]}`,
+			wantLints: []docs.Lint{
+				{
+					Line:   2,
+					Column: 5,
+					Level:  docs.LintError,
+					Type:   docs.LintBadBloblang,
+					What:   "field `this.foo`: expected string or bytes value, got number",
+				},
+			},
+		},
+		"unreachable branch": {
+			mapping: `root.foo = if false { "a" } else { "b" }`,
+			wantLints: []docs.Lint{
+				{
+					Line:   2,
+					Column: 5,
+					Level:  docs.LintWarning,
+					Type:   docs.LintUnreachableBloblang,
+					What:   `branch 1 of if expression is unreachable as its condition is always false`,
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			conf := docs.NewLintConfig(bundle.GlobalEnvironment)
+			conf.BloblangTypeCheck = true
+			conf.BloblangInputSchema = []byte(test.schema)
+
+			gotLints := docs.LintBloblangMapping(docs.NewLintContext(conf), 2, 4, test.mapping)
+			require.EqualValues(t, test.wantLints, gotLints)
+		})
+	}
+}
+
 func TestLintBloblangField(t *testing.T) {
 	type Test struct {
 		mapping   string
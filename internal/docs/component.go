@@ -52,6 +52,7 @@ var (
 	TypeOutput    Type = "output"
 	TypeProcessor Type = "processor"
 	TypeRateLimit Type = "rate_limit"
+	TypeLock      Type = "lock"
 	TypeTracer    Type = "tracer"
 	TypeScanner   Type = "scanner"
 )
@@ -66,6 +67,7 @@ func Types() []Type {
 		TypeOutput,
 		TypeProcessor,
 		TypeRateLimit,
+		TypeLock,
 		TypeTracer,
 		TypeScanner,
 	}
@@ -105,4 +107,8 @@ type ComponentSpec struct {
 
 	// Version is the Bento version this component was introduced.
 	Version string `json:"version,omitempty"`
+
+	// Migration describes how configs of this component can be rewritten in
+	// order to use a replacement component or replacement fields.
+	Migration *ComponentMigration `json:"migration,omitempty"`
 }
@@ -28,6 +28,7 @@ var (
 	FieldTypeCache     FieldType = "cache"
 	FieldTypeProcessor FieldType = "processor"
 	FieldTypeRateLimit FieldType = "rate_limit"
+	FieldTypeLock      FieldType = "lock"
 	FieldTypeOutput    FieldType = "output"
 	FieldTypeMetrics   FieldType = "metrics"
 	FieldTypeTracer    FieldType = "tracer"
@@ -47,6 +48,8 @@ func (t FieldType) IsCoreComponent() (Type, bool) {
 		return TypeProcessor, true
 	case FieldTypeRateLimit:
 		return TypeRateLimit, true
+	case FieldTypeLock:
+		return TypeLock, true
 	case FieldTypeOutput:
 		return TypeOutput, true
 	case FieldTypeTracer:
@@ -550,6 +553,11 @@ func FieldRateLimit(name, description string, examples ...any) FieldSpec {
 	return newField(name, description, examples...).HasType(FieldTypeRateLimit)
 }
 
+// FieldLock returns a field spec for a lock typed field.
+func FieldLock(name, description string, examples ...any) FieldSpec {
+	return newField(name, description, examples...).HasType(FieldTypeLock)
+}
+
 // FieldMetrics returns a field spec for a metrics typed field.
 func FieldMetrics(name, description string, examples ...any) FieldSpec {
 	return newField(name, description, examples...).HasType(FieldTypeMetrics)
@@ -683,6 +691,14 @@ type LintConfig struct {
 
 	// Require labels for components.
 	RequireLabels bool
+
+	// Perform static type checking of Bloblang mappings, reporting definite
+	// type errors as linting errors and unreachable branches as warnings.
+	BloblangTypeCheck bool
+
+	// An optional JSON schema describing the input documents of Bloblang
+	// mappings, used during type checking.
+	BloblangInputSchema []byte
 }
 
 // NewLintConfig creates a default linting config.
@@ -783,6 +799,10 @@ const (
 
 	// LintDeprecated means a field is deprecated and should not be used.
 	LintDeprecated LintType = iota
+
+	// LintUnreachableBloblang means the field contains a Bloblang mapping with
+	// branches that can never be taken.
+	LintUnreachableBloblang LintType = iota
 )
 
 // Lint describes a single linting issue found with a Bento config.
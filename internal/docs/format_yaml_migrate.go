@@ -0,0 +1,343 @@
+package docs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MigratedYAMLComponent describes a component within a YAML config that has a
+// registered migration, along with the result of applying it.
+type MigratedYAMLComponent struct {
+	Line          int
+	Column        int
+	ComponentType Type
+	Name          string
+	To            string
+
+	// Node is the config of the component, which has been rewritten in place
+	// when Err is nil.
+	Node *yaml.Node
+
+	// Err is non-nil when the component could not be migrated, in which case
+	// its config is left untouched.
+	Err error
+}
+
+// MigrateYAML walks a YAML config and rewrites, in place, each component that
+// has a registered migration. The nodes of fields that are kept or renamed are
+// moved rather than recreated, and therefore comments and formatting are
+// preserved.
+func (f FieldSpecs) MigrateYAML(prov Provider, node *yaml.Node) ([]MigratedYAMLComponent, error) {
+	type pendingMigration struct {
+		walked WalkedYAMLComponent
+		spec   ComponentSpec
+	}
+
+	var pending []pendingMigration
+	if err := f.WalkYAML(node, prov, func(c WalkedYAMLComponent) error {
+		if spec, exists := prov.GetDocs(c.Name, c.ComponentType); exists && spec.Migration != nil {
+			pending = append(pending, pendingMigration{walked: c, spec: spec})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Components are walked parents first, and so by migrating them in reverse
+	// any nested components are migrated before their parents are copied.
+	results := make([]MigratedYAMLComponent, len(pending))
+	for i := len(pending) - 1; i >= 0; i-- {
+		p := pending[i]
+		to := p.spec.Migration.To
+		if to == "" {
+			to = p.walked.Name
+		}
+		results[i] = MigratedYAMLComponent{
+			Line:          p.walked.Conf.Line,
+			Column:        p.walked.Conf.Column,
+			ComponentType: p.walked.ComponentType,
+			Name:          p.walked.Name,
+			To:            to,
+			Node:          p.walked.Conf,
+			Err:           migrateComponentYAML(prov, p.walked.ComponentType, p.spec, to, p.walked.Conf),
+		}
+	}
+	return results, nil
+}
+
+func migrateComponentYAML(prov Provider, cType Type, spec ComponentSpec, to string, node *yaml.Node) error {
+	if _, exists := prov.GetDocs(to, cType); !exists {
+		return fmt.Errorf("%v type %v was not recognised", cType, to)
+	}
+
+	newNode := cloneYAMLNode(node)
+
+	nameIdx, pluginIdx, typeIdx := -1, -1, -1
+	for i := 0; i < len(newNode.Content)-1; i += 2 {
+		switch newNode.Content[i].Value {
+		case spec.Name:
+			nameIdx = i
+		case "plugin":
+			pluginIdx = i
+		case "type":
+			typeIdx = i
+		}
+	}
+
+	var conf *yaml.Node
+	switch {
+	case nameIdx >= 0:
+		conf = newNode.Content[nameIdx+1]
+	case pluginIdx >= 0 && typeIdx >= 0:
+		conf = newNode.Content[pluginIdx+1]
+	default:
+		conf = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		newNode.Content = append(newNode.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: spec.Name}, conf)
+		nameIdx = len(newNode.Content) - 2
+	}
+	if conf.Kind == yaml.ScalarNode && conf.Tag == "!!null" {
+		*conf = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", LineComment: conf.LineComment}
+	}
+	if conf.Kind != yaml.MappingNode {
+		return fmt.Errorf("expected object config, got %v", conf.ShortTag())
+	}
+
+	for _, rule := range spec.Migration.Rules {
+		if err := applyMigrationRule(spec.Config, rule, conf); err != nil {
+			return err
+		}
+	}
+
+	if nameIdx >= 0 {
+		newNode.Content[nameIdx].Value = to
+	}
+	if typeIdx >= 0 {
+		newNode.Content[typeIdx+1].Value = to
+	}
+
+	var unknown []string
+	for _, l := range LintYAML(NewLintContext(NewLintConfig(prov)), cType, newNode) {
+		if l.Type == LintUnknown {
+			unknown = append(unknown, l.What)
+		}
+	}
+	if len(unknown) > 0 {
+		return errors.New(strings.Join(unknown, ", "))
+	}
+
+	*node = *newNode
+	return nil
+}
+
+func applyMigrationRule(spec FieldSpec, rule MigrationRule, conf *yaml.Node) error {
+	path := strings.Split(rule.Path, ".")
+	switch rule.Type {
+	case MigrationRuleRename:
+		if _, value := lookupYAMLField(conf, path); value == nil {
+			return nil
+		}
+		toPath := strings.Split(rule.To, ".")
+		if _, existing := lookupYAMLField(conf, toPath); existing != nil {
+			return fmt.Errorf("field %v cannot be moved to %v as it is already set", rule.Path, rule.To)
+		}
+		if sameYAMLParent(path, toPath) {
+			// Renaming the key in place retains the position of the field.
+			key, _ := lookupYAMLField(conf, path)
+			key.Value = toPath[len(toPath)-1]
+			return nil
+		}
+		key, value := takeYAMLField(conf, path)
+		return putYAMLField(conf, toPath, key, value)
+
+	case MigrationRuleRemove:
+		_, _ = takeYAMLField(conf, path)
+		return nil
+
+	case MigrationRuleMapValue:
+		key, value := lookupYAMLField(conf, path)
+		var from string
+		if value == nil {
+			defValue, err := migrationFieldDefault(spec, path)
+			if err != nil {
+				return nil
+			}
+			from = fmt.Sprint(defValue)
+		} else if value.Kind != yaml.ScalarNode {
+			return fmt.Errorf("field %v must be a scalar value", rule.Path)
+		} else {
+			from = value.Value
+		}
+		mapped, exists := rule.Values[from]
+		if !exists {
+			return fmt.Errorf("field %v value %v cannot be migrated", rule.Path, from)
+		}
+		if mapped == nil {
+			_, _ = takeYAMLField(conf, path)
+			return nil
+		}
+		var newValue yaml.Node
+		if err := newValue.Encode(mapped); err != nil {
+			return err
+		}
+		toPath := path
+		if rule.To != "" {
+			toPath = strings.Split(rule.To, ".")
+		}
+		if value != nil && sameYAMLParent(path, toPath) {
+			newValue.HeadComment, newValue.LineComment = value.HeadComment, value.LineComment
+			key.Value = toPath[len(toPath)-1]
+			*value = newValue
+			return nil
+		}
+		_, _ = takeYAMLField(conf, path)
+		return putYAMLField(conf, toPath, key, &newValue)
+
+	case MigrationRulePreserveDefault, MigrationRuleSet:
+		if _, value := lookupYAMLField(conf, path); value != nil {
+			return nil
+		}
+		v := rule.Value
+		if rule.Type == MigrationRulePreserveDefault {
+			var err error
+			if v, err = migrationFieldDefault(spec, path); err != nil {
+				return nil
+			}
+		}
+		var newValue yaml.Node
+		if err := newValue.Encode(v); err != nil {
+			return err
+		}
+		return putYAMLField(conf, path, nil, &newValue)
+
+	case MigrationRuleUnsupported:
+		_, value := lookupYAMLField(conf, path)
+		if value == nil {
+			return nil
+		}
+		var v any
+		if err := value.Decode(&v); err != nil {
+			return err
+		}
+		if defValue, err := migrationFieldDefault(spec, path); err == nil && migrationValuesEqual(v, defValue) {
+			return nil
+		}
+		return fmt.Errorf("field %v cannot be migrated automatically", rule.Path)
+	}
+	return fmt.Errorf("migration rule type not recognised: %v", rule.Type)
+}
+
+func migrationFieldDefault(spec FieldSpec, path []string) (any, error) {
+	target := spec
+	for _, p := range path {
+		var found bool
+		for _, c := range target.Children {
+			if c.Name == p {
+				target, found = c, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("field %v not recognised", p)
+		}
+	}
+	return getDefault(strings.Join(path, "."), target)
+}
+
+func migrationValuesEqual(a, b any) bool {
+	normalise := func(v any) any {
+		jBytes, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var n any
+		if err := json.Unmarshal(jBytes, &n); err != nil {
+			return v
+		}
+		return n
+	}
+	return reflect.DeepEqual(normalise(a), normalise(b))
+}
+
+func sameYAMLParent(a, b []string) bool {
+	return len(a) == len(b) && reflect.DeepEqual(a[:len(a)-1], b[:len(b)-1])
+}
+
+func lookupYAMLField(node *yaml.Node, path []string) (key, value *yaml.Node) {
+	for i, p := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		key, value = nil, nil
+		for j := 0; j < len(node.Content)-1; j += 2 {
+			if node.Content[j].Value == p {
+				key, value = node.Content[j], node.Content[j+1]
+				break
+			}
+		}
+		if value == nil {
+			return nil, nil
+		}
+		if i < len(path)-1 {
+			node = value
+		}
+	}
+	return
+}
+
+func takeYAMLField(node *yaml.Node, path []string) (key, value *yaml.Node) {
+	parent := node
+	if len(path) > 1 {
+		if _, parent = lookupYAMLField(node, path[:len(path)-1]); parent == nil || parent.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+	}
+	name := path[len(path)-1]
+	for i := 0; i < len(parent.Content)-1; i += 2 {
+		if parent.Content[i].Value == name {
+			key, value = parent.Content[i], parent.Content[i+1]
+			parent.Content = append(parent.Content[:i:i], parent.Content[i+2:]...)
+			return
+		}
+	}
+	return nil, nil
+}
+
+func putYAMLField(node *yaml.Node, path []string, key, value *yaml.Node) error {
+	for _, p := range path[:len(path)-1] {
+		_, next := lookupYAMLField(node, []string{p})
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: p}, next)
+		}
+		if next.Kind != yaml.MappingNode {
+			return fmt.Errorf("field %v must be an object", p)
+		}
+		node = next
+	}
+	name := path[len(path)-1]
+	if key == nil {
+		key = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+	}
+	key.Value = name
+	node.Content = append(node.Content, key, value)
+	return nil
+}
+
+func cloneYAMLNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	newNode := *node
+	if len(node.Content) > 0 {
+		newNode.Content = make([]*yaml.Node, len(node.Content))
+		for i, c := range node.Content {
+			newNode.Content[i] = cloneYAMLNode(c)
+		}
+	}
+	return &newNode
+}
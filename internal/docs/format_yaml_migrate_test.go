@@ -0,0 +1,200 @@
+package docs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+func migrateTestProvider() *docs.MappedDocsProvider {
+	mockProv := docs.NewMappedDocsProvider()
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name:   "old_kafka",
+		Type:   docs.TypeInput,
+		Status: docs.StatusDeprecated,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("addresses", "").Array(),
+			docs.FieldString("topics", "").Array(),
+			docs.FieldString("commit_period", "").HasDefault("1s"),
+			docs.FieldString("target_version", "").Optional(),
+			docs.FieldObject("sasl", "").WithChildren(
+				docs.FieldString("mechanism", "").HasDefault("none"),
+			),
+		),
+		Migration: &docs.ComponentMigration{
+			To: "new_kafka",
+			Rules: []docs.MigrationRule{
+				docs.MigrateRename("addresses", "seed_brokers"),
+				docs.MigrateRemove("target_version"),
+				docs.MigratePreserveDefault("commit_period"),
+				docs.MigrateUnsupported("sasl.mechanism"),
+				docs.MigrateRemove("sasl"),
+			},
+		},
+	})
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name: "new_kafka",
+		Type: docs.TypeInput,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("seed_brokers", "").Array(),
+			docs.FieldString("topics", "").Array(),
+			docs.FieldString("commit_period", "").HasDefault("5s"),
+		),
+	})
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name:   "old_sql",
+		Type:   docs.TypeProcessor,
+		Status: docs.StatusDeprecated,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("data_source_name", ""),
+			docs.FieldString("query", ""),
+			docs.FieldString("result_codec", "").HasDefault("json_array"),
+		),
+		Migration: &docs.ComponentMigration{
+			To: "new_sql",
+			Rules: []docs.MigrationRule{
+				docs.MigrateRename("data_source_name", "dsn"),
+				docs.MigrateMapValue("result_codec", "exec_only", map[string]any{
+					"json_array": nil,
+					"none":       true,
+				}),
+			},
+		},
+	})
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name: "new_sql",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("dsn", ""),
+			docs.FieldString("query", ""),
+			docs.FieldBool("exec_only", "").HasDefault(false),
+		),
+	})
+	return mockProv
+}
+
+func TestMigrateYAML(t *testing.T) {
+	mockProv := migrateTestProvider()
+
+	node, err := docs.UnmarshalYAML([]byte(`
+input:
+  # The source of data
+  old_kafka:
+    addresses: [ localhost:9092 ] # The brokers
+    # The topics
+    topics: [ foo ]
+    target_version: 2.1.0
+pipeline:
+  processors:
+    - old_sql:
+        data_source_name: foo # Connection
+        query: 'SELECT 1'
+        result_codec: none # Don't decode
+    - old_sql:
+        data_source_name: bar
+        query: 'SELECT 2'
+`))
+	require.NoError(t, err)
+
+	results, err := configSpec.MigrateYAML(mockProv, node)
+	require.NoError(t, err)
+	for i := range results {
+		require.NotNil(t, results[i].Node)
+		results[i].Node = nil
+	}
+	assert.Equal(t, []docs.MigratedYAMLComponent{
+		{Line: 4, Column: 3, ComponentType: docs.TypeInput, Name: "old_kafka", To: "new_kafka"},
+		{Line: 11, Column: 7, ComponentType: docs.TypeProcessor, Name: "old_sql", To: "new_sql"},
+		{Line: 15, Column: 7, ComponentType: docs.TypeProcessor, Name: "old_sql", To: "new_sql"},
+	}, results)
+
+	resBytes, err := docs.MarshalYAML(*node)
+	require.NoError(t, err)
+	assert.Equal(t, `input:
+  # The source of data
+  new_kafka:
+    seed_brokers: ['localhost:9092'] # The brokers
+    # The topics
+    topics: [foo]
+    commit_period: 1s
+pipeline:
+  processors:
+    - new_sql:
+        dsn: foo # Connection
+        query: 'SELECT 1'
+        exec_only: true # Don't decode
+    - new_sql:
+        dsn: bar
+        query: 'SELECT 2'
+`, string(resBytes))
+}
+
+func TestMigrateYAMLErrors(t *testing.T) {
+	mockProv := migrateTestProvider()
+
+	input := `input:
+  old_kafka:
+    addresses: [ localhost:9092 ]
+    sasl:
+      mechanism: PLAIN
+pipeline:
+  processors:
+    - old_sql:
+        data_source_name: foo
+        query: 'SELECT 1'
+        result_codec: csv
+    - old_sql:
+        data_source_name: foo
+        query: 'SELECT 1'
+        nope: bar
+`
+
+	node, err := docs.UnmarshalYAML([]byte(input))
+	require.NoError(t, err)
+
+	results, err := configSpec.MigrateYAML(mockProv, node)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.EqualError(t, results[0].Err, "field sasl.mechanism cannot be migrated automatically")
+	assert.EqualError(t, results[1].Err, "field result_codec value csv cannot be migrated")
+	assert.EqualError(t, results[2].Err, "field nope not recognised")
+
+	resBytes, err := docs.MarshalYAML(*node)
+	require.NoError(t, err)
+
+	expNode, err := docs.UnmarshalYAML([]byte(input))
+	require.NoError(t, err)
+	expBytes, err := docs.MarshalYAML(*expNode)
+	require.NoError(t, err)
+	assert.Equal(t, string(expBytes), string(resBytes))
+}
+
+func TestMigrateYAMLUnsupportedDefault(t *testing.T) {
+	mockProv := migrateTestProvider()
+
+	node, err := docs.UnmarshalYAML([]byte(`
+input:
+  old_kafka:
+    addresses: [ localhost:9092 ]
+    commit_period: 10s
+    sasl:
+      mechanism: none
+`))
+	require.NoError(t, err)
+
+	results, err := configSpec.MigrateYAML(mockProv, node)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].Err)
+
+	resBytes, err := docs.MarshalYAML(*node)
+	require.NoError(t, err)
+	assert.Equal(t, `input:
+  new_kafka:
+    seed_brokers: ['localhost:9092']
+    commit_period: 10s
+`, string(resBytes))
+}
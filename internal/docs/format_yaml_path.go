@@ -374,3 +374,86 @@ func (f FieldSpec) YAMLLabelsToPaths(docsProvider Provider, node *yaml.Node, lab
 		}
 	}
 }
+
+//------------------------------------------------------------------------------
+
+// YAMLPathWalkFunc is called for each node of a YAML tree that corresponds to a
+// field, along with the path of the node and the spec of the field. For array
+// and map fields the func is called for the field itself, with the spec of the
+// whole field, followed by each element, with the spec of an element.
+type YAMLPathWalkFunc func(path []string, spec FieldSpec, node *yaml.Node)
+
+// WalkYAMLPaths walks a YAML tree using a field spec as a reference point and
+// calls a provided func for each node of the tree that corresponds to a field,
+// including the fields of components, along with its path.
+func (f FieldSpecs) WalkYAMLPaths(docsProvider Provider, node *yaml.Node, path []string, fn YAMLPathWalkFunc) {
+	node = unwrapDocumentNode(node)
+
+	fieldMap := map[string]FieldSpec{}
+	for _, spec := range f {
+		fieldMap[spec.Name] = spec
+	}
+
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		key := node.Content[i].Value
+		if spec, exists := fieldMap[key]; exists {
+			spec.WalkYAMLPaths(docsProvider, node.Content[i+1], append(path, key), fn)
+		}
+	}
+}
+
+// WalkYAMLPaths walks a YAML tree using a field spec as a reference point and
+// calls a provided func for each node of the tree that corresponds to a field,
+// including the fields of components, along with its path.
+func (f FieldSpec) WalkYAMLPaths(docsProvider Provider, node *yaml.Node, path []string, fn YAMLPathWalkFunc) {
+	node = unwrapDocumentNode(node)
+
+	pathCopy := make([]string, len(path))
+	copy(pathCopy, path)
+	fn(pathCopy, f, node)
+
+	switch f.Kind {
+	case Kind2DArray:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		nextSpec := f.Array()
+		for i, child := range node.Content {
+			nextSpec.WalkYAMLPaths(docsProvider, child, append(path, strconv.Itoa(i)), fn)
+		}
+	case KindArray:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		nextSpec := f.Scalar()
+		for i, child := range node.Content {
+			nextSpec.WalkYAMLPaths(docsProvider, child, append(path, strconv.Itoa(i)), fn)
+		}
+	case KindMap:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		nextSpec := f.Scalar()
+		for i := 0; i < len(node.Content)-1; i += 2 {
+			nextSpec.WalkYAMLPaths(docsProvider, node.Content[i+1], append(path, node.Content[i].Value), fn)
+		}
+	default:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		if coreType, isCore := f.Type.IsCoreComponent(); isCore {
+			coreFields := FieldSpecs{}
+			for _, f := range ReservedFieldsByType(coreType) {
+				coreFields = append(coreFields, f)
+			}
+			if inferred, cSpec, err := GetInferenceCandidateFromYAML(docsProvider, coreType, node); err == nil {
+				conf := cSpec.Config
+				conf.Name = inferred
+				coreFields = append(coreFields, conf)
+			}
+			coreFields.WalkYAMLPaths(docsProvider, node, path, fn)
+		} else if len(f.Children) > 0 {
+			f.Children.WalkYAMLPaths(docsProvider, node, path, fn)
+		}
+	}
+}
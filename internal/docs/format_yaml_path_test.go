@@ -835,3 +835,71 @@ pipeline:
 		})
 	}
 }
+
+func TestWalkYAMLPaths(t *testing.T) {
+	mockProv := docs.NewMappedDocsProvider()
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name: "compress",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldString("algorithm", ""),
+		),
+	})
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name: "switch",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().Array().WithChildren(
+			docs.FieldBloblang("check", ""),
+			docs.FieldProcessor("processors", "").Array(),
+		),
+	})
+	mockProv.RegisterDocs(docs.ComponentSpec{
+		Name: "workflow",
+		Type: docs.TypeProcessor,
+		Config: docs.FieldComponent().WithChildren(
+			docs.FieldProcessor("things", "").Map(),
+		),
+	})
+
+	input := `
+pipeline:
+  processors:
+    - label: fooproc1
+      switch:
+        - check: this.foo
+          processors:
+            - compress:
+                algorithm: nahm8
+        - processors: []
+    - workflow:
+        things:
+          first:
+            compress:
+              algorithm: nahm8
+`
+
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(input), &node))
+
+	var procPaths [][]string
+	var stringPaths []string
+	configSpec.WalkYAMLPaths(mockProv, &node, nil, func(path []string, spec docs.FieldSpec, node *yaml.Node) {
+		if spec.Kind != docs.KindScalar {
+			return
+		}
+		if spec.Type == docs.FieldTypeProcessor {
+			procPaths = append(procPaths, path)
+		}
+		if spec.Type == docs.FieldTypeString {
+			stringPaths = append(stringPaths, node.Value)
+		}
+	})
+
+	assert.Equal(t, [][]string{
+		{"pipeline", "processors", "0"},
+		{"pipeline", "processors", "0", "switch", "0", "processors", "0"},
+		{"pipeline", "processors", "1"},
+		{"pipeline", "processors", "1", "workflow", "things", "first"},
+	}, procPaths)
+	assert.Equal(t, []string{"fooproc1", "this.foo", "nahm8", "nahm8"}, stringPaths)
+}
@@ -15,26 +15,37 @@ func jSchemaIsRequired(f *FieldSpec) bool {
 	return false
 }
 
+// JSONSchemaDefinitionsRef is the prefix used by JSONSchema when referencing
+// component definitions.
+const JSONSchemaDefinitionsRef = "#/definitions/"
+
 // JSONSchema serializes a field spec into a JSON schema structure.
 func (f FieldSpec) JSONSchema() any {
+	return f.JSONSchemaWithRefs(JSONSchemaDefinitionsRef)
+}
+
+// JSONSchemaWithRefs serializes a field spec into a JSON schema structure where
+// component fields are referenced by their type name appended to the provided
+// prefix, e.g. `#/components/schemas/` for OpenAPI documents.
+func (f FieldSpec) JSONSchemaWithRefs(refPrefix string) any {
 	spec := map[string]any{}
 	switch f.Kind {
 	case Kind2DArray:
 		innerField := f
 		innerField.Kind = KindArray
 		spec["type"] = "array"
-		spec["items"] = innerField.JSONSchema()
+		spec["items"] = innerField.JSONSchemaWithRefs(refPrefix)
 	case KindArray:
 		innerField := f
 		innerField.Kind = KindScalar
 		spec["type"] = "array"
-		spec["items"] = innerField.JSONSchema()
+		spec["items"] = innerField.JSONSchemaWithRefs(refPrefix)
 	case KindMap:
 		innerField := f
 		innerField.Kind = KindScalar
 		spec["type"] = "object"
 		spec["patternProperties"] = map[string]any{
-			".": innerField.JSONSchema(),
+			".": innerField.JSONSchemaWithRefs(refPrefix),
 		}
 	default:
 		switch f.Type {
@@ -48,7 +59,7 @@ func (f FieldSpec) JSONSchema() any {
 			spec["type"] = "number"
 		case FieldTypeObject:
 			spec["type"] = "object"
-			spec["properties"] = f.Children.JSONSchema()
+			spec["properties"] = f.Children.JSONSchemaWithRefs(refPrefix)
 			var required []string
 			for _, child := range f.Children {
 				if jSchemaIsRequired(&child) {
@@ -60,23 +71,25 @@ func (f FieldSpec) JSONSchema() any {
 			}
 			spec["additionalProperties"] = false
 		case FieldTypeInput:
-			spec["$ref"] = "#/definitions/input"
+			spec["$ref"] = refPrefix + "input"
 		case FieldTypeBuffer:
-			spec["$ref"] = "#/definitions/buffer"
+			spec["$ref"] = refPrefix + "buffer"
 		case FieldTypeCache:
-			spec["$ref"] = "#/definitions/cache"
+			spec["$ref"] = refPrefix + "cache"
 		case FieldTypeProcessor:
-			spec["$ref"] = "#/definitions/processor"
+			spec["$ref"] = refPrefix + "processor"
 		case FieldTypeRateLimit:
-			spec["$ref"] = "#/definitions/rate_limit"
+			spec["$ref"] = refPrefix + "rate_limit"
+		case FieldTypeLock:
+			spec["$ref"] = refPrefix + "lock"
 		case FieldTypeOutput:
-			spec["$ref"] = "#/definitions/output"
+			spec["$ref"] = refPrefix + "output"
 		case FieldTypeMetrics:
-			spec["$ref"] = "#/definitions/metrics"
+			spec["$ref"] = refPrefix + "metrics"
 		case FieldTypeTracer:
-			spec["$ref"] = "#/definitions/tracer"
+			spec["$ref"] = refPrefix + "tracer"
 		case FieldTypeScanner:
-			spec["$ref"] = "#/definitions/scanner"
+			spec["$ref"] = refPrefix + "scanner"
 		}
 	}
 	return spec
@@ -84,9 +97,47 @@ func (f FieldSpec) JSONSchema() any {
 
 // JSONSchema serializes a field spec into a JSON schema structure.
 func (f FieldSpecs) JSONSchema() map[string]any {
+	return f.JSONSchemaWithRefs(JSONSchemaDefinitionsRef)
+}
+
+// JSONSchemaWithRefs serializes a field spec into a JSON schema structure where
+// component fields are referenced with the provided prefix.
+func (f FieldSpecs) JSONSchemaWithRefs(refPrefix string) map[string]any {
 	spec := map[string]any{}
 	for _, field := range f {
-		spec[field.Name] = field.JSONSchema()
+		spec[field.Name] = field.JSONSchemaWithRefs(refPrefix)
 	}
 	return spec
 }
+
+// ComponentJSONSchema serializes a list of component specs of a given type
+// into a JSON schema definition that matches any one of them, along with the
+// reserved fields common to all components of that type.
+func ComponentJSONSchema(ctype Type, specs []ComponentSpec, refPrefix string) map[string]any {
+	generalFields := map[string]any{}
+	for k, v := range ReservedFieldsByType(ctype) {
+		generalFields[k] = v.JSONSchemaWithRefs(refPrefix)
+	}
+
+	var componentDefs []any
+	for _, s := range specs {
+		componentDefs = append(componentDefs, map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				s.Name: s.Config.JSONSchemaWithRefs(refPrefix),
+			},
+		})
+	}
+
+	return map[string]any{
+		"allOf": []any{
+			map[string]any{
+				"anyOf": componentDefs, // TODO: Convert this to oneOf once issues are resolved.
+			},
+			map[string]any{
+				"type":       "object",
+				"properties": generalFields,
+			},
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package docs
+
+// MigrationRuleType describes the action of a migration rule.
+type MigrationRuleType string
+
+// Migration rule types.
+var (
+	// MigrationRuleRename moves the value of a field to a new path.
+	MigrationRuleRename MigrationRuleType = "rename"
+
+	// MigrationRuleRemove removes a field.
+	MigrationRuleRemove MigrationRuleType = "remove"
+
+	// MigrationRuleMapValue replaces a scalar field with a value from a map of
+	// old values to new ones, optionally under a new path. When the field is
+	// absent its default value is mapped instead.
+	MigrationRuleMapValue MigrationRuleType = "map_value"
+
+	// MigrationRulePreserveDefault explicitly sets a field to its default
+	// value when absent, used for fields where the default of the replacement
+	// component differs.
+	MigrationRulePreserveDefault MigrationRuleType = "preserve_default"
+
+	// MigrationRuleSet sets a field to a value when absent.
+	MigrationRuleSet MigrationRuleType = "set"
+
+	// MigrationRuleUnsupported prevents a migration when a field is set to
+	// anything other than its default value.
+	MigrationRuleUnsupported MigrationRuleType = "unsupported"
+)
+
+// MigrationRule is a single step of a component migration. Paths are dot
+// separated and relative to the config of the component.
+type MigrationRule struct {
+	Type   MigrationRuleType `json:"type"`
+	Path   string            `json:"path"`
+	To     string            `json:"to,omitempty"`
+	Value  any               `json:"value,omitempty"`
+	Values map[string]any    `json:"values,omitempty"`
+}
+
+// ComponentMigration describes how the config of a component can be rewritten.
+type ComponentMigration struct {
+	// To is the name of the component that configs are migrated to, when
+	// empty the component remains the same and only the rules are applied.
+	To string `json:"to,omitempty"`
+
+	// Rules are applied in order to the config of the component.
+	Rules []MigrationRule `json:"rules,omitempty"`
+}
+
+// MigrateRename creates a rule that moves the value of a field to a new path.
+func MigrateRename(from, to string) MigrationRule {
+	return MigrationRule{Type: MigrationRuleRename, Path: from, To: to}
+}
+
+// MigrateRemove creates a rule that removes a field.
+func MigrateRemove(path string) MigrationRule {
+	return MigrationRule{Type: MigrationRuleRemove, Path: path}
+}
+
+// MigrateMapValue creates a rule that replaces a scalar field with a value
+// from a map, where a nil value removes the field. Values that are not present
+// in the map prevent the migration.
+func MigrateMapValue(from, to string, values map[string]any) MigrationRule {
+	return MigrationRule{Type: MigrationRuleMapValue, Path: from, To: to, Values: values}
+}
+
+// MigratePreserveDefault creates a rule that explicitly sets a field to its
+// default value when it is absent.
+func MigratePreserveDefault(path string) MigrationRule {
+	return MigrationRule{Type: MigrationRulePreserveDefault, Path: path}
+}
+
+// MigrateSet creates a rule that sets a field to a value when it is absent.
+func MigrateSet(path string, value any) MigrationRule {
+	return MigrationRule{Type: MigrationRuleSet, Path: path, Value: value}
+}
+
+// MigrateUnsupported creates a rule that prevents a migration when a field is
+// set to anything other than its default value.
+func MigrateUnsupported(path string) MigrationRule {
+	return MigrationRule{Type: MigrationRuleUnsupported, Path: path}
+}
@@ -30,6 +30,7 @@ type Client struct {
 
 	// Request execution and retry logic
 	rateLimit     string
+	rateLimitKey  *service.InterpolatedString
 	numRetries    int
 	retryThrottle *throttle.Type
 	backoffOn     map[int]struct{}
@@ -131,6 +132,15 @@ func NewClientFromOldConfig(conf OldConfig, mgr *service.Resources, opts ...Requ
 		if !h.mgr.HasRateLimit(h.rateLimit) {
 			return nil, fmt.Errorf("rate limit resource '%v' was not found", h.rateLimit)
 		}
+		if h.rateLimitKey = conf.RateLimitKey; h.rateLimitKey != nil {
+			keysSupported := true
+			_ = h.mgr.AccessRateLimit(context.Background(), h.rateLimit, func(rl service.RateLimit) {
+				keysSupported = service.RateLimitSupportsKeys(rl)
+			})
+			if !keysSupported {
+				return nil, fmt.Errorf("rate limit resource '%v' does not support keys", h.rateLimit)
+			}
+		}
 	}
 
 	h.numRetries = conf.NumRetries
@@ -171,17 +181,43 @@ func (h *Client) waitForAccess(ctx context.Context, sendMsg service.MessageBatch
 	if h.rateLimit == "" {
 		return true
 	}
+
+	var key string
+	if h.rateLimitKey != nil {
+		keyBatch := sendMsg
+		if len(keyBatch) == 0 {
+			keyBatch = service.MessageBatch{service.NewMessage(nil)}
+		}
+		var err error
+		if key, err = keyBatch.TryInterpolatedString(0, h.rateLimitKey); err != nil {
+			h.log.Errorf("Rate limit key error: %v\n", err)
+		}
+	}
+
+	// Messages are added to message aware rate limits only once, regardless of
+	// how many attempts it takes to gain access.
+	pending := sendMsg
 	for {
 		var period time.Duration
 		var err error
 		if rerr := h.mgr.AccessRateLimit(ctx, h.rateLimit, func(rl service.RateLimit) {
-			if mar, ok := rl.(service.MessageAwareRateLimit); ok {
-				mar.Add(ctx, sendMsg...)
+			if mar, ok := rl.(service.MessageAwareRateLimit); ok && len(pending) > 0 {
+				mar.Add(ctx, pending...)
+				pending = nil
+			}
+			if krl, ok := rl.(service.KeyedRateLimit); ok && key != "" {
+				period, err = krl.AccessKey(ctx, key)
+			} else {
+				period, err = rl.Access(ctx)
 			}
-			period, err = rl.Access(ctx)
 		}); rerr != nil {
 			err = rerr
 		}
+		if errors.Is(err, service.ErrRateLimitKeysNotSupported) {
+			h.log.Errorf("Rate limit resource '%v' does not support keys, falling back to accessing it without a key\n", h.rateLimit)
+			key = ""
+			continue
+		}
 		if err != nil {
 			h.log.Errorf("Rate limit error: %v\n", err)
 			period = time.Second
@@ -59,6 +59,48 @@ retries: 3
 	assert.Equal(t, uint32(4), atomic.LoadUint32(&reqCount))
 }
 
+func TestHTTPClientRateLimitKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	var hits uint32
+	res := service.MockResources(service.MockResourcesOptAddRateLimit("foo", func(context.Context) (time.Duration, error) {
+		atomic.AddUint32(&hits, 1)
+		return 0, nil
+	}))
+
+	_, err := NewClientFromOldConfig(clientConfig(t, `
+url: %v
+rate_limit: foo
+rate_limit_key: ${! @tenant }
+`, ts.URL), res)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit resource 'foo' does not support keys")
+
+	h, err := NewClientFromOldConfig(clientConfig(t, `
+url: %v
+rate_limit: foo
+`, ts.URL), res)
+	require.NoError(t, err)
+	defer h.Close(context.Background())
+
+	// A rate limit that loses key support after the client is created is
+	// accessed without a key rather than retried indefinitely.
+	h.rateLimitKey, err = service.NewInterpolatedString("${! @tenant }")
+	require.NoError(t, err)
+
+	msg := service.NewMessage([]byte("test"))
+	msg.MetaSetMut("tenant", "foo")
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	_, err = h.Send(ctx, service.MessageBatch{msg})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&hits))
+}
+
 func TestHTTPClientBadRequest(t *testing.T) {
 	conf := clientConfig(t, `
 url: htp://notvalid:1111
@@ -17,6 +17,7 @@ const (
 	hcFieldMetadata            = "metadata"
 	hcFieldExtractHeaders      = "extract_headers"
 	hcFieldRateLimit           = "rate_limit"
+	hcFieldRateLimitKey        = "rate_limit_key"
 	hcFieldTimeout             = "timeout"
 	hcFieldRetryPeriod         = "retry_period"
 	hcFieldMaxRetryBackoff     = "max_retry_backoff"
@@ -71,6 +72,11 @@ func ConfigField(defaultVerb string, forOutput bool, extraChildren ...*service.C
 		service.NewStringField(hcFieldRateLimit).
 			Description("An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by.").
 			Optional(),
+		service.NewInterpolatedStringField(hcFieldRateLimitKey).
+			Description("An optional key to access the `rate_limit` with, where each key is limited independently. The key is resolved against the first message of each request. The target rate limit must support keys, such as a `local` or `redis` rate limit with a `rate` configured.").
+			Example("${! @tenant_id }").
+			Optional().
+			Advanced(),
 		service.NewDurationField(hcFieldTimeout).
 			Description("A static timeout to apply to requests.").
 			Default("5s"),
@@ -130,6 +136,11 @@ func ConfigFromParsed(pConf *service.ParsedConfig) (conf OldConfig, err error) {
 		return
 	}
 	conf.RateLimit, _ = pConf.FieldString(hcFieldRateLimit)
+	if pConf.Contains(hcFieldRateLimitKey) {
+		if conf.RateLimitKey, err = pConf.FieldInterpolatedString(hcFieldRateLimitKey); err != nil {
+			return
+		}
+	}
 	if conf.Timeout, err = pConf.FieldDuration(hcFieldTimeout); err != nil {
 		return
 	}
@@ -177,6 +188,7 @@ type OldConfig struct {
 	Metadata            *service.MetadataFilter
 	ExtractMetadata     *service.MetadataFilter
 	RateLimit           string
+	RateLimitKey        *service.InterpolatedString
 	Timeout             time.Duration
 	Retry               time.Duration
 	MaxBackoff          time.Duration
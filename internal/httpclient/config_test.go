@@ -46,6 +46,7 @@ headers:
 			inputYAML: `
 url: example.com/foo2
 rate_limit: nah
+rate_limit_key: ${! @tenant }
 `,
 			verbOverride: "GET",
 			validator: func(t *testing.T, o *OldConfig) {
@@ -53,6 +54,10 @@ rate_limit: nah
 				assert.Equal(t, "example.com/foo2", sURL)
 				assert.Equal(t, "GET", o.Verb)
 				assert.Equal(t, "nah", o.RateLimit)
+				require.NotNil(t, o.RateLimitKey)
+				msg := service.NewMessage(nil)
+				msg.MetaSetMut("tenant", "foo")
+				assert.Equal(t, "foo", o.RateLimitKey.String(msg))
 			},
 		},
 		{
@@ -132,6 +132,8 @@ type dynamoDBAPIV2 interface {
 	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
 	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
 	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 }
 
 type dynamodbCache struct {
@@ -412,6 +414,186 @@ func (d *dynamodbCache) putItemInput(key string, value []byte, ttl *time.Duratio
 	return &input
 }
 
+// retry calls fn until it succeeds, the backoff is exhausted or the context is
+// cancelled. Errors that indicate a definitive result are not retried.
+func (d *dynamodbCache) retry(ctx context.Context, fn func() error) error {
+	boff := d.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		d.boffPool.Put(boff)
+	}()
+
+	err := fn()
+	for err != nil && err != service.ErrValueMismatch {
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			break
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+		err = fn()
+	}
+	return err
+}
+
+// The maximum number of keys within a single BatchGetItem request.
+const dynamoDBMaxBatchGet = 100
+
+func (d *dynamodbCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	for len(keys) > 0 {
+		chunk := keys
+		if len(chunk) > dynamoDBMaxBatchGet {
+			chunk = chunk[:dynamoDBMaxBatchGet]
+		}
+		keys = keys[len(chunk):]
+
+		reqKeys := make([]map[string]types.AttributeValue, 0, len(chunk))
+		for _, k := range chunk {
+			reqKeys = append(reqKeys, map[string]types.AttributeValue{
+				d.hashKey: &types.AttributeValueMemberS{Value: k},
+			})
+		}
+
+		err := d.retry(ctx, func() error {
+			out, err := d.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					d.table: {
+						Keys:           reqKeys,
+						ConsistentRead: aws.Bool(d.consistentRead),
+					},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			for _, item := range out.Responses[d.table] {
+				d.addItem(res, item)
+			}
+			if unproc := out.UnprocessedKeys[d.table]; len(unproc.Keys) > 0 {
+				reqKeys = unproc.Keys
+				return fmt.Errorf("failed to get %v items", len(unproc.Keys))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (d *dynamodbCache) addItem(res map[string][]byte, item map[string]types.AttributeValue) {
+	key, ok := item[d.hashKey].(*types.AttributeValueMemberS)
+	if !ok {
+		return
+	}
+	if val, ok := item[d.dataKey].(*types.AttributeValueMemberB); ok {
+		res[key.Value] = val.Value
+	}
+}
+
+func (d *dynamodbCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	// Values are stored as binary and therefore cannot be incremented with an
+	// update expression, instead we optimistically swap the current value
+	// until no concurrent modification has occurred in between.
+	for {
+		var old []byte
+		var current int64
+
+		b, err := d.Get(ctx, key)
+		if err == nil {
+			if current, err = strconv.ParseInt(string(b), 10, 64); err != nil {
+				return 0, fmt.Errorf("value is not an integer: %w", err)
+			}
+			old = b
+		} else if err != service.ErrKeyNotFound {
+			return 0, err
+		}
+
+		current += delta
+		err = d.CompareAndSwap(ctx, key, old, strconv.AppendInt(nil, current, 10), ttl)
+		if err == nil {
+			return current, nil
+		}
+		if err != service.ErrValueMismatch {
+			return 0, err
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (d *dynamodbCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	return d.retry(ctx, func() error {
+		return d.compareAndSwap(ctx, key, old, value, ttl)
+	})
+}
+
+func (d *dynamodbCache) compareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	input := d.putItemInput(key, value, ttl)
+
+	cond := expression.AttributeNotExists(expression.Name(d.hashKey))
+	if old != nil {
+		cond = expression.Name(d.dataKey).Equal(expression.Value(old))
+	}
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return err
+	}
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	input.ConditionExpression = expr.Condition()
+
+	if _, err = d.client.PutItem(ctx, input); err != nil {
+		var derr *types.ConditionalCheckFailedException
+		if errors.As(err, &derr) {
+			return service.ErrValueMismatch
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *dynamodbCache) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	expr, err := expression.NewBuilder().
+		WithFilter(expression.Name(d.hashKey).BeginsWith(prefix)).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string][]byte{}
+	var startKey map[string]types.AttributeValue
+	for {
+		var out *dynamodb.ScanOutput
+		if err := d.retry(ctx, func() (err error) {
+			out, err = d.client.Scan(ctx, &dynamodb.ScanInput{
+				TableName:                 &d.table,
+				ConsistentRead:            aws.Bool(d.consistentRead),
+				ExclusiveStartKey:         startKey,
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+				FilterExpression:          expr.Filter(),
+			})
+			return
+		}); err != nil {
+			return nil, err
+		}
+		for _, item := range out.Items {
+			d.addItem(res, item)
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return res, nil
+		}
+		startKey = out.LastEvaluatedKey
+	}
+}
+
 func (d *dynamodbCache) Close(context.Context) error {
 	return nil
 }
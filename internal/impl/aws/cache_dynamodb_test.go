@@ -1,12 +1,18 @@
 package aws
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
 )
 
 func TestDynamoDBCacheConfig(t *testing.T) {
@@ -88,3 +94,104 @@ ttl_key: buz
 		})
 	}
 }
+
+type mockDynamoDBCache struct {
+	dynamoDBAPIV2
+	batchGetFn func(*dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error)
+	putFn      func(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
+}
+
+func (m *mockDynamoDBCache) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return m.batchGetFn(params)
+}
+
+func (m *mockDynamoDBCache) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return m.putFn(params)
+}
+
+func testDynamoDBCache(t *testing.T, client dynamoDBAPIV2) *dynamodbCache {
+	t.Helper()
+
+	conf, err := dynCacheConfig().ParseYAML(`
+table: foo
+hash_key: id
+data_key: data
+retries:
+  initial_interval: 1ms
+  max_interval: 1ms
+`, nil)
+	require.NoError(t, err)
+
+	dc, err := newDynamodbCacheFromConfig(conf)
+	require.NoError(t, err)
+	dc.client = client
+	return dc
+}
+
+func TestDynamoDBCacheGetMulti(t *testing.T) {
+	var requested []int
+	dc := testDynamoDBCache(t, &mockDynamoDBCache{
+		batchGetFn: func(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+			keys := input.RequestItems["foo"].Keys
+			requested = append(requested, len(keys))
+
+			out := &dynamodb.BatchGetItemOutput{
+				Responses:       map[string][]map[string]types.AttributeValue{},
+				UnprocessedKeys: map[string]types.KeysAndAttributes{},
+			}
+			for i, k := range keys {
+				id := k["id"].(*types.AttributeValueMemberS).Value
+				if id == "missing" {
+					continue
+				}
+				// Report the last key of each request as unprocessed once.
+				if i == len(keys)-1 && len(keys) > 1 {
+					out.UnprocessedKeys["foo"] = types.KeysAndAttributes{Keys: keys[i:]}
+					continue
+				}
+				out.Responses["foo"] = append(out.Responses["foo"], map[string]types.AttributeValue{
+					"id":   k["id"],
+					"data": &types.AttributeValueMemberB{Value: []byte("value " + id)},
+				})
+			}
+			return out, nil
+		},
+	})
+
+	keys := []string{"missing"}
+	exp := map[string][]byte{}
+	for i := 0; i < 150; i++ {
+		k := fmt.Sprintf("key%v", i)
+		keys = append(keys, k)
+		exp[k] = []byte("value " + k)
+	}
+
+	res, err := dc.GetMulti(context.Background(), keys...)
+	require.NoError(t, err)
+	assert.Equal(t, exp, res)
+	assert.Equal(t, []int{100, 1, 51, 1}, requested)
+}
+
+func TestDynamoDBCacheCompareAndSwap(t *testing.T) {
+	var conditions []string
+	dc := testDynamoDBCache(t, &mockDynamoDBCache{
+		putFn: func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			conditions = append(conditions, *input.ConditionExpression)
+			if string(input.Item["data"].(*types.AttributeValueMemberB).Value) == "mismatch" {
+				return nil, &types.ConditionalCheckFailedException{}
+			}
+			return &dynamodb.PutItemOutput{}, nil
+		},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, dc.CompareAndSwap(ctx, "foo", nil, []byte("bar"), nil))
+	require.NoError(t, dc.CompareAndSwap(ctx, "foo", []byte("bar"), []byte("baz"), nil))
+	require.ErrorIs(t, dc.CompareAndSwap(ctx, "foo", []byte("bar"), []byte("mismatch"), nil), service.ErrValueMismatch)
+
+	assert.Equal(t, []string{
+		"attribute_not_exists (#0)",
+		"#0 = :0",
+		"#0 = :0",
+	}, conditions)
+}
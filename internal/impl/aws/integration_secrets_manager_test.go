@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func secretsManagerIntegrationSuite(t *testing.T, lsPort string) {
+	endpoint := fmt.Sprintf("http://localhost:%v", lsPort)
+	opts := []func(*config.LoadOptions) error{
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("xxxxx", "xxxxx", "xxxxx")),
+		config.WithRegion("eu-west-1"),
+		config.WithBaseEndpoint(endpoint),
+	}
+
+	conf, err := config.LoadDefaultConfig(context.Background(), opts...)
+	require.NoError(t, err)
+
+	_, err = secretsmanager.NewFromConfig(conf).CreateSecret(context.Background(), &secretsmanager.CreateSecretInput{
+		Name:         aws.String("bento/test"),
+		SecretString: aws.String(`{"password":"hunter2"}`),
+	})
+	require.NoError(t, err)
+
+	lookup := secretsManagerLookup(opts...)
+
+	v, err := lookup(context.Background(), "bento/test")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password":"hunter2"}`, v)
+
+	_, err = lookup(context.Background(), "bento/does-not-exist")
+	require.Error(t, err)
+}
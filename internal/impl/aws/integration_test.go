@@ -25,4 +25,8 @@ func TestIntegration(t *testing.T) {
 	t.Run("sqs", func(t *testing.T) {
 		sqsIntegrationSuite(t, servicePort)
 	})
+
+	t.Run("secrets_manager", func(t *testing.T) {
+		secretsManagerIntegrationSuite(t, servicePort)
+	})
 }
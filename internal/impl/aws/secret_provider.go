@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func init() {
+	if err := service.RegisterSecretProvider("aws_sm", secretsManagerLookup()); err != nil {
+		panic(err)
+	}
+}
+
+// secretsManagerLookup returns a func that reads the value of a secret from
+// AWS Secrets Manager, where the path is the name or ARN of the secret.
+// Credentials, region and endpoint are obtained from the default AWS config
+// chain (environment variables, shared config files, instance roles, etc).
+func secretsManagerLookup(opts ...func(*config.LoadOptions) error) service.SecretLookupFunc {
+	return func(ctx context.Context, path string) (string, error) {
+		conf, err := config.LoadDefaultConfig(ctx, opts...)
+		if err != nil {
+			return "", err
+		}
+
+		res, err := secretsmanager.NewFromConfig(conf).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+			SecretId: aws.String(path),
+		})
+		if err != nil {
+			return "", err
+		}
+		if res.SecretString == nil {
+			return "", errors.New("secret does not contain a string value")
+		}
+		return *res.SecretString, nil
+	}
+}
@@ -1,8 +1,11 @@
 package dgraph
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,7 +24,9 @@ func ristrettoCacheConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
 		Stable().
 		Summary(`Stores key/value pairs in a map held in the memory-bound [Ristretto cache](https://github.com/dgraph-io/ristretto).`).
-		Description(`This cache is more efficient and appropriate for high-volume use cases than the standard memory cache. However, the add command is non-atomic, and therefore this cache is not suitable for deduplication.`).
+		Description(`This cache is more efficient and appropriate for high-volume use cases than the standard memory cache. However, the add command is non-atomic, and therefore this cache is not suitable for deduplication.
+
+Increment and compare-and-swap operations are atomic with respect to each other, but not with respect to concurrent set operations. Since Ristretto may drop or evict items at any time a counter can be reset, and the scan operation is not supported as keys cannot be listed.`).
 		Field(service.NewDurationField("default_ttl").
 			Description("A default TTL to set for items, calculated from the moment the item is cached. Set to an empty string or zero duration to disable TTLs.").
 			Default("").
@@ -70,6 +75,9 @@ type ristrettoCache struct {
 	retriesEnabled bool
 	boffPool       sync.Pool
 	closeOnce      sync.Once
+
+	// Serialises read-modify-write operations.
+	rmwMut sync.Mutex
 }
 
 func newRistrettoCache(defaultTTL time.Duration, retriesEnabled bool, backOff *backoff.ExponentialBackOff) (*ristrettoCache, error) {
@@ -152,6 +160,68 @@ func (r *ristrettoCache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (r *ristrettoCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		if v, ok := r.cache.Get(k); ok {
+			res[k] = v
+		}
+	}
+	return res, nil
+}
+
+// setAndWait sets a key and waits for the write to be applied so that it is
+// visible to subsequent reads.
+func (r *ristrettoCache) setAndWait(key string, value []byte, ttl time.Duration) error {
+	if !r.cache.SetWithTTL(key, value, 1, ttl) {
+		return errors.New("set operation was dropped")
+	}
+	r.cache.Wait()
+	return nil
+}
+
+func (r *ristrettoCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	r.rmwMut.Lock()
+	defer r.rmwMut.Unlock()
+
+	t := r.defaultTTL
+	if ttl != nil {
+		t = *ttl
+	}
+
+	var current int64
+	if v, ok := r.cache.Get(key); ok {
+		var err error
+		if current, err = strconv.ParseInt(string(v), 10, 64); err != nil {
+			return 0, fmt.Errorf("value is not an integer: %w", err)
+		}
+		// The TTL only applies when the counter is created.
+		t, _ = r.cache.GetTTL(key)
+	}
+	current += delta
+
+	if err := r.setAndWait(key, strconv.AppendInt(nil, current, 10), t); err != nil {
+		return 0, err
+	}
+	return current, nil
+}
+
+func (r *ristrettoCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	r.rmwMut.Lock()
+	defer r.rmwMut.Unlock()
+
+	current, exists := r.cache.Get(key)
+	if exists != (old != nil) || (exists && !bytes.Equal(current, old)) {
+		return service.ErrValueMismatch
+	}
+
+	t := r.defaultTTL
+	if ttl != nil {
+		t = *ttl
+	}
+	return r.setAndWait(key, value, t)
+}
+
 func (r *ristrettoCache) Close(ctx context.Context) error {
 	r.closeOnce.Do(func() {
 		r.cache.Close()
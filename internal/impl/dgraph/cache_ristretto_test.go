@@ -2,6 +2,7 @@ package dgraph
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -63,3 +64,36 @@ func TestRistrettoCacheWithTTL(t *testing.T) {
 		return err == service.ErrKeyNotFound
 	}, time.Second, time.Millisecond*5)
 }
+
+func TestRistrettoCacheOptionalOperations(t *testing.T) {
+	c, err := newRistrettoCache(0, false, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, err := c.Increment(ctx, "counter", 1, nil)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	res, err := c.GetMulti(ctx, "counter", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"counter": []byte("100")}, res)
+
+	require.ErrorIs(t, c.CompareAndSwap(ctx, "counter", nil, []byte("1"), nil), service.ErrValueMismatch)
+	require.ErrorIs(t, c.CompareAndSwap(ctx, "counter", []byte("99"), []byte("1"), nil), service.ErrValueMismatch)
+	require.NoError(t, c.CompareAndSwap(ctx, "counter", []byte("100"), []byte("1"), nil))
+	require.NoError(t, c.CompareAndSwap(ctx, "new", nil, []byte("2"), nil))
+
+	res, err = c.GetMulti(ctx, "counter", "new")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"counter": []byte("1"), "new": []byte("2")}, res)
+}
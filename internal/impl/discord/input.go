@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -33,6 +34,15 @@ func inputConfig() *service.ConfigSpec {
 				Description("The key identifier used when storing the ID of the last message received.").
 				Default("last_message_id").
 				Advanced(),
+			service.NewStringField("rate_limit").
+				Description("An optional [`rate_limit`](/docs/components/rate_limits/about) resource to throttle consumed messages by.").
+				Default("").
+				Advanced(),
+			service.NewInterpolatedStringField("rate_limit_key").
+				Description("An optional key to access the `rate_limit` with, where each key is limited independently. The key is resolved for each consumed message. The target rate limit must support keys, such as a `local` or `redis` rate limit with a `rate` configured.").
+				Example(`${! json("author.id") }`).
+				Optional().
+				Advanced(),
 			service.NewAutoRetryNacksToggleField(),
 
 			// Deprecated
@@ -44,10 +54,6 @@ func inputConfig() *service.ConfigSpec {
 				Description("The maximum number of messages to receive in a single request.").
 				Default(100).
 				Deprecated(),
-			service.NewStringField("rate_limit").
-				Description("").
-				Default("An optional rate limit resource to restrict API requests with.").
-				Deprecated(),
 		)
 }
 
@@ -80,8 +86,14 @@ type reader struct {
 	cache     string
 	cacheKey  string
 
+	rateLimit    string
+	rateLimitKey *service.InterpolatedString
+
 	connMut sync.Mutex
 	msgChan chan *discordgo.Message
+
+	// A message that was consumed but not yet delivered due to rate limiting.
+	pending *discordgo.Message
 }
 
 func newReader(conf *service.ParsedConfig, mgr *service.Resources) (*reader, error) {
@@ -104,6 +116,26 @@ func newReader(conf *service.ParsedConfig, mgr *service.Resources) (*reader, err
 	if r.cacheKey, err = conf.FieldString("cache_key"); err != nil {
 		return nil, err
 	}
+	if r.rateLimit, err = conf.FieldString("rate_limit"); err != nil {
+		return nil, err
+	}
+	if r.rateLimit != "" {
+		if !mgr.HasRateLimit(r.rateLimit) {
+			return nil, fmt.Errorf("rate limit resource '%v' was not found", r.rateLimit)
+		}
+		if conf.Contains("rate_limit_key") {
+			if r.rateLimitKey, err = conf.FieldInterpolatedString("rate_limit_key"); err != nil {
+				return nil, err
+			}
+			keysSupported := true
+			_ = mgr.AccessRateLimit(context.Background(), r.rateLimit, func(rl service.RateLimit) {
+				keysSupported = service.RateLimitSupportsKeys(rl)
+			})
+			if !keysSupported {
+				return nil, fmt.Errorf("rate limit resource '%v' does not support keys", r.rateLimit)
+			}
+		}
+	}
 	return r, nil
 }
 
@@ -215,11 +247,14 @@ func (r *reader) Read(ctx context.Context) (*service.Message, service.AckFunc, e
 		return nil, nil, service.ErrNotConnected
 	}
 
-	var msgEvent *discordgo.Message
-	select {
-	case msgEvent = <-msgChan:
-	case <-ctx.Done():
-		return nil, nil, ctx.Err()
+	msgEvent := r.pending
+	r.pending = nil
+	if msgEvent == nil {
+		select {
+		case msgEvent = <-msgChan:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
 	}
 
 	jBytes, err := json.Marshal(msgEvent)
@@ -227,12 +262,17 @@ func (r *reader) Read(ctx context.Context) (*service.Message, service.AckFunc, e
 		return nil, nil, err
 	}
 
+	msg := service.NewMessage(jBytes)
+	if !r.waitForAccess(ctx, msg) {
+		r.pending = msgEvent
+		return nil, nil, ctx.Err()
+	}
+
 	release, err := r.checkpointer.Track(ctx, msgEvent.ID, 1)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	msg := service.NewMessage(jBytes)
 	return msg, func(ctx context.Context, err error) error {
 		highestID := release()
 		if highestID == nil {
@@ -248,6 +288,56 @@ func (r *reader) Read(ctx context.Context) (*service.Message, service.AckFunc, e
 	}, nil
 }
 
+func (r *reader) waitForAccess(ctx context.Context, msg *service.Message) bool {
+	if r.rateLimit == "" {
+		return true
+	}
+
+	var key string
+	if r.rateLimitKey != nil {
+		var err error
+		if key, err = r.rateLimitKey.TryString(msg); err != nil {
+			r.log.Errorf("Rate limit key error: %v", err)
+		}
+	}
+
+	added := false
+	for {
+		var period time.Duration
+		var err error
+		if rerr := r.mgr.AccessRateLimit(ctx, r.rateLimit, func(rl service.RateLimit) {
+			if mar, ok := rl.(service.MessageAwareRateLimit); ok && !added {
+				mar.Add(ctx, msg)
+				added = true
+			}
+			if krl, ok := rl.(service.KeyedRateLimit); ok && key != "" {
+				period, err = krl.AccessKey(ctx, key)
+			} else {
+				period, err = rl.Access(ctx)
+			}
+		}); rerr != nil {
+			err = rerr
+		}
+		if errors.Is(err, service.ErrRateLimitKeysNotSupported) {
+			r.log.Errorf("Rate limit resource '%v' does not support keys, falling back to accessing it without a key", r.rateLimit)
+			key = ""
+			continue
+		}
+		if err != nil {
+			r.log.Errorf("Rate limit error: %v", err)
+			period = time.Second
+		}
+		if period == 0 {
+			return true
+		}
+		select {
+		case <-time.After(period):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
 func (r *reader) Close(ctx context.Context) error {
 	go func() {
 		r.shutSig.TriggerSoftStop()
@@ -238,6 +238,7 @@ It's possible to enable AWS connectivity with this output using the `+"`aws`"+`
 				Default("5s"),
 			service.NewTLSToggledField(esoFieldTLS),
 			service.NewOutputMaxInFlightField(),
+			service.NewOutputAdaptiveConcurrencyField(),
 		).
 		Fields(retries.CommonRetryBackOffFields(0, "1s", "5s", "30s")...).
 		Fields(
@@ -260,6 +261,16 @@ It's possible to enable AWS connectivity with this output using the `+"`aws`"+`
 				Description("Enable gzip compression on the request side.").
 				Advanced().
 				Default(false),
+		).
+		Migration("elasticsearch_v2",
+			service.NewMigrationPreserveDefault(esoFieldSniff),
+			service.NewMigrationRename(esoFieldSniff, "discover_nodes_on_start"),
+			service.NewMigrationRemove(esoFieldHealthcheck),
+			service.NewMigrationUnsupported(esoFieldType),
+			service.NewMigrationRemove(esoFieldType),
+			service.NewMigrationUnsupported(esoFieldAWS+"."+ESOFieldAWSEnabled),
+			service.NewMigrationRemove(esoFieldAWS),
+			service.NewMigrationRename(esoFieldGzipCompression, "compress_request_body"),
 		)
 }
 
@@ -100,6 +100,7 @@ Both the `+"`id` and `index`"+` fields can be dynamically set using function int
 				Default("5s"),
 			service.NewTLSToggledField(esoV2FieldTLS),
 			service.NewOutputMaxInFlightField(),
+			service.NewOutputAdaptiveConcurrencyField(),
 			service.NewObjectField(esoV2FieldAuth,
 				service.NewBoolField(esoV2FieldAuthEnabled).
 					Description("Whether to use basic authentication in requests.").
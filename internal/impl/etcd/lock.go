@@ -0,0 +1,138 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+// etcd lock configuration
+const (
+	etcdLockPrefixField = "prefix"
+	etcdLockTTLField    = "ttl"
+)
+
+func etcdLockConfigSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Summary("Grants leases on named locks stored as keys within etcd, allowing multiple instances of Bento to coordinate.").
+		Description(`
+Each named lock is stored as a key consisting of the configured prefix followed by the lock name, which is attached to an [etcd lease](https://etcd.io/docs/v3.5/learning/api/#lease-api). Leases expire unless they are renewed within the configured TTL, at which point etcd deletes the key, and therefore a lock held by an instance that dies is freed once the TTL has passed.
+
+The TTL is rounded up to the nearest second as this is the granularity of etcd leases.`).
+		Fields(etcdClientFields()...).
+		Field(service.NewStringField(etcdLockPrefixField).
+			Description("A prefix to add to the names of locks in order to obtain their keys.").
+			Default("bento_lock/")).
+		Field(service.NewDurationField(etcdLockTTLField).
+			Description("The duration after which a lease expires unless it is renewed. Lower durations allow locks held by instances that have died to be acquired sooner at the cost of more frequent renewals.").
+			Default("10s"))
+}
+
+func init() {
+	err := service.RegisterLock("etcd", etcdLockConfigSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+			return newEtcdLockFromConfig(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type etcdLock struct {
+	client *clientv3.Client
+	prefix string
+	ttl    time.Duration
+}
+
+func newEtcdLockFromConfig(parsedConf *service.ParsedConfig) (*etcdLock, error) {
+	config, err := newEtcdConfigFromParsed(parsedConf)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := parsedConf.FieldString(etcdLockPrefixField)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := parsedConf.FieldDuration(etcdLockTTLField)
+	if err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		return nil, errors.New("ttl must be larger than zero")
+	}
+	ttl = time.Duration(math.Ceil(ttl.Seconds())) * time.Second
+
+	client, err := newEtcdClientFromConfig(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &etcdLock{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}, nil
+}
+
+func (e *etcdLock) Acquire(ctx context.Context, name string) (service.LockLease, error) {
+	grant, err := e.client.Grant(ctx, int64(e.ttl.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("granting etcd lease: %w", err)
+	}
+
+	key := e.prefix + name
+	res, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, "", clientv3.WithLease(grant.ID))).
+		Commit()
+	if err == nil && !res.Succeeded {
+		err = service.ErrLockHeld
+	}
+	if err != nil {
+		// The lease is of no use without the key, and revoking it is merely an
+		// optimisation as it would otherwise expire.
+		_, _ = e.client.Revoke(context.WithoutCancel(ctx), grant.ID)
+		return nil, err
+	}
+	return &etcdLease{e: e, id: grant.ID}, nil
+}
+
+func (e *etcdLock) Close(ctx context.Context) error {
+	return e.client.Close()
+}
+
+type etcdLease struct {
+	e  *etcdLock
+	id clientv3.LeaseID
+}
+
+func (l *etcdLease) TTL() time.Duration {
+	return l.e.ttl
+}
+
+func (l *etcdLease) Renew(ctx context.Context) error {
+	if _, err := l.e.client.KeepAliveOnce(ctx, l.id); err != nil {
+		if errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			return service.ErrLeaseLost
+		}
+		return fmt.Errorf("renewing etcd lease: %w", err)
+	}
+	return nil
+}
+
+func (l *etcdLease) Release(ctx context.Context) error {
+	if _, err := l.e.client.Revoke(ctx, l.id); err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+		return fmt.Errorf("revoking etcd lease: %w", err)
+	}
+	return nil
+}
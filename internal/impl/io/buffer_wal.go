@@ -0,0 +1,967 @@
+package io
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	walFieldPath         = "path"
+	walFieldSegmentSize  = "segment_size"
+	walFieldMaxSize      = "max_size"
+	walFieldOnFull       = "on_full"
+	walFieldSync         = "sync"
+	walFieldSyncInterval = "sync_interval"
+	walFieldRetention    = "retention"
+
+	walOnFullBlock      = "block"
+	walOnFullDropOldest = "drop_oldest"
+
+	walSyncAlways   = "always"
+	walSyncInterval = "interval"
+	walSyncNone     = "none"
+)
+
+func walBufferSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Summary("Stores messages in a write-ahead log of segment files on local disk and acknowledges them at the input level.").
+		Description(`
+Messages are appended to segment files within a directory, and are consumed from the oldest segment onwards. The position of the oldest message that has not yet been acknowledged downstream is tracked with a cursor file within the same directory, and once all messages of a segment have been acknowledged the segment is deleted.
+
+If the service is restarted, or crashes, then when it starts again it will consume from the cursor, which means messages that were delivered but not yet recorded by the cursor may be delivered again. Any partially written messages at the end of a segment are discarded during recovery.
+
+## Delivery Guarantees
+
+Messages are not acknowledged at the input level until they have been written to a segment, and they are not removed from disk until they have been successfully delivered. Whether a written message survives a crash of the machine (rather than just the process) depends on the `+"`sync`"+` policy:
+
+- `+"`always`"+`: Segments are flushed to disk before each message is acknowledged at the input level, which is the safest and slowest option.
+- `+"`interval`"+`: Segments are flushed to disk periodically according to `+"`sync_interval`"+`, a crash of the machine may lose messages written since the last flush.
+- `+"`none`"+`: Flushing is left to the operating system.
+
+## Size Limits
+
+When `+"`max_size`"+` is set the total size of all segments is capped. Once the cap is reached either writes are blocked until enough messages are acknowledged for segments to be deleted (`+"`block`"+`), or the oldest segment is deleted along with any messages within it that have not been delivered (`+"`drop_oldest`"+`).
+
+Similarly, when a `+"`retention`"+` period is set segments older than that period are deleted regardless of whether their messages have been delivered.
+
+## Batching
+
+Messages that are logically batched at the point where they are added to the buffer will continue to be associated with that batch when they are consumed. Each batch is written as a single record, and therefore it is recommended to use batching at the input level in high-throughput use cases.
+`).
+		Field(service.NewStringField(walFieldPath).
+			Description("The path of a directory in which to store segments, which will be created if it does not already exist.")).
+		Field(service.NewIntField(walFieldSegmentSize).
+			Description("The size in bytes at which a new segment is started.").
+			Advanced().
+			Default(64*1024*1024)).
+		Field(service.NewIntField(walFieldMaxSize).
+			Description("The maximum total size in bytes of all segments, where zero means unlimited.").
+			Default(0)).
+		Field(service.NewStringEnumField(walFieldOnFull, walOnFullBlock, walOnFullDropOldest).
+			Description("What to do when writing a batch would exceed `max_size`.").
+			Default(walOnFullBlock)).
+		Field(service.NewStringEnumField(walFieldSync, walSyncAlways, walSyncInterval, walSyncNone).
+			Description("When to flush segments and the cursor to disk.").
+			Default(walSyncInterval)).
+		Field(service.NewDurationField(walFieldSyncInterval).
+			Description("The period between flushes when `sync` is set to `interval`.").
+			Default("1s")).
+		Field(service.NewDurationField(walFieldRetention).
+			Description("An optional maximum age of segments, after which they are deleted even if they contain messages that have not been delivered.").
+			Example("24h").
+			Optional()).
+		Example("Durable buffering", "Decouple an input from slow downstream services whilst surviving restarts, blocking the input once 10GB of messages are pending.", `
+buffer:
+  wal:
+    path: ./bento_wal
+    max_size: 10000000000
+    on_full: block
+    sync: interval
+    sync_interval: 500ms
+`)
+}
+
+func init() {
+	err := service.RegisterBatchBuffer(
+		"wal", walBufferSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchBuffer, error) {
+			return newWALBufferFromConfig(conf, mgr)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type walConfig struct {
+	Path         string
+	SegmentSize  int64
+	MaxSize      int64
+	OnFull       string
+	Sync         string
+	SyncInterval time.Duration
+	Retention    time.Duration
+}
+
+func newWALBufferFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*walBuffer, error) {
+	var wConf walConfig
+	var err error
+	if wConf.Path, err = conf.FieldString(walFieldPath); err != nil {
+		return nil, err
+	}
+
+	var segmentSize, maxSize int
+	if segmentSize, err = conf.FieldInt(walFieldSegmentSize); err != nil {
+		return nil, err
+	}
+	if segmentSize <= 0 {
+		return nil, fmt.Errorf("field %v must be greater than zero", walFieldSegmentSize)
+	}
+	wConf.SegmentSize = int64(segmentSize)
+	if maxSize, err = conf.FieldInt(walFieldMaxSize); err != nil {
+		return nil, err
+	}
+	wConf.MaxSize = int64(maxSize)
+
+	if wConf.OnFull, err = conf.FieldString(walFieldOnFull); err != nil {
+		return nil, err
+	}
+	if wConf.Sync, err = conf.FieldString(walFieldSync); err != nil {
+		return nil, err
+	}
+	if wConf.SyncInterval, err = conf.FieldDuration(walFieldSyncInterval); err != nil {
+		return nil, err
+	}
+	if conf.Contains(walFieldRetention) {
+		if wConf.Retention, err = conf.FieldDuration(walFieldRetention); err != nil {
+			return nil, err
+		}
+	}
+	return newWALBuffer(wConf, mgr)
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	walSegmentSuffix  = ".wal"
+	walCursorFile     = "cursor"
+	walRecordHeader   = 16
+	walMaxRecordBytes = 1 << 31
+)
+
+var errWALCorrupt = errors.New("the data appears to be corrupt")
+
+type walSegment struct {
+	path      string
+	firstSeq  uint64
+	nextSeq   uint64
+	size      int64
+	lastWrite time.Time
+}
+
+type walEntry struct {
+	seq   uint64
+	batch service.MessageBatch
+}
+
+// walBuffer stores batches as records within append-only segment files, where
+// each record is identified by a sequence number. Records are read in order
+// and the cursor, which is the lowest sequence that has not been acknowledged,
+// is persisted so that consumption resumes from it after a restart.
+type walBuffer struct {
+	conf walConfig
+	log  *service.Logger
+
+	mDropped *service.MetricCounter
+	mSize    *service.MetricGauge
+
+	cond *sync.Cond
+
+	segments  []*walSegment
+	totalSize int64
+	nextSeq   uint64
+
+	// The active segment is the last segment and was created by this process.
+	writeFile  *os.File
+	writeDirty bool
+
+	cursor      uint64
+	cursorDirty bool
+	acked       map[uint64]struct{}
+	inFlight    map[uint64]struct{}
+	requeued    []walEntry
+
+	readSeq    uint64
+	readPath   string
+	readFile   *os.File
+	readOffset int64
+
+	endOfInput bool
+	closed     bool
+
+	now     func() time.Time
+	shutSig *shutdown.Signaller
+}
+
+func newWALBuffer(conf walConfig, mgr *service.Resources) (*walBuffer, error) {
+	if err := os.MkdirAll(conf.Path, 0o755); err != nil {
+		return nil, err
+	}
+
+	w := &walBuffer{
+		conf:     conf,
+		log:      mgr.Logger(),
+		mDropped: mgr.Metrics().NewCounter("buffer_wal_dropped"),
+		mSize:    mgr.Metrics().NewGauge("buffer_wal_size_bytes"),
+		cond:     sync.NewCond(&sync.Mutex{}),
+		acked:    map[uint64]struct{}{},
+		inFlight: map[uint64]struct{}{},
+		now:      time.Now,
+		shutSig:  shutdown.NewSignaller(),
+	}
+	if err := w.recover(); err != nil {
+		return nil, err
+	}
+
+	go w.maintenanceLoop()
+	return w, nil
+}
+
+// recover loads the existing segments and cursor from disk, truncating any
+// segments at the first record that is incomplete or corrupt.
+func (w *walBuffer) recover() error {
+	cursor, hasCursor, err := w.readCursor()
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(w.conf.Path)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, walSegmentSuffix) {
+			continue
+		}
+		firstSeq, err := strconv.ParseUint(strings.TrimSuffix(name, walSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		w.segments = append(w.segments, &walSegment{
+			path:     filepath.Join(w.conf.Path, name),
+			firstSeq: firstSeq,
+		})
+	}
+	sort.Slice(w.segments, func(i, j int) bool {
+		return w.segments[i].firstSeq < w.segments[j].firstSeq
+	})
+
+	for _, seg := range w.segments {
+		if err := w.scanSegment(seg); err != nil {
+			return err
+		}
+		w.totalSize += seg.size
+	}
+
+	if len(w.segments) > 0 {
+		w.nextSeq = w.segments[len(w.segments)-1].nextSeq
+		if !hasCursor {
+			cursor = w.segments[0].firstSeq
+		}
+	}
+	if cursor > w.nextSeq {
+		w.nextSeq = cursor
+	}
+	w.cursor = cursor
+	w.readSeq = cursor
+
+	w.compact()
+	w.mSize.Set(w.totalSize)
+	return nil
+}
+
+func (w *walBuffer) scanSegment(seg *walSegment) error {
+	f, err := os.OpenFile(seg.path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	seg.lastWrite = info.ModTime()
+
+	seg.nextSeq = seg.firstSeq
+	var offset int64
+	for offset < info.Size() {
+		seq, _, n, err := readWALRecord(f, offset, info.Size())
+		if err == nil && seq != seg.nextSeq {
+			err = errWALCorrupt
+		}
+		if err != nil {
+			w.log.Warnf("Truncating segment %v at offset %v after reading an invalid record: %v", seg.path, offset, err)
+			if err := f.Truncate(offset); err != nil {
+				return err
+			}
+			break
+		}
+		offset += n
+		seg.nextSeq++
+	}
+	seg.size = offset
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+func (w *walBuffer) cursorPath() string {
+	return filepath.Join(w.conf.Path, walCursorFile)
+}
+
+func (w *walBuffer) readCursor() (uint64, bool, error) {
+	b, err := os.ReadFile(w.cursorPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if len(b) != 12 || crc32.ChecksumIEEE(b[:8]) != binary.BigEndian.Uint32(b[8:]) {
+		w.log.Warnf("Ignoring cursor file %v as it appears to be corrupt", w.cursorPath())
+		return 0, false, nil
+	}
+	return binary.BigEndian.Uint64(b[:8]), true, nil
+}
+
+func (w *walBuffer) persistCursor() error {
+	if !w.cursorDirty {
+		return nil
+	}
+
+	b := binary.BigEndian.AppendUint64(nil, w.cursor)
+	b = binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b))
+
+	tmpPath := w.cursorPath() + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err == nil && w.conf.Sync != walSyncNone {
+		err = f.Sync()
+	}
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, w.cursorPath()); err != nil {
+		return err
+	}
+	if err := w.syncDir(); err != nil {
+		return err
+	}
+	w.cursorDirty = false
+	return nil
+}
+
+// syncDir flushes the entries of the buffer directory so that created and
+// renamed files survive a crash.
+func (w *walBuffer) syncDir() error {
+	if w.conf.Sync == walSyncNone || runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(w.conf.Path)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cErr := d.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+func (w *walBuffer) sync() error {
+	if w.writeFile != nil && w.writeDirty && w.conf.Sync != walSyncNone {
+		if err := w.writeFile.Sync(); err != nil {
+			return err
+		}
+		w.writeDirty = false
+	}
+	return w.persistCursor()
+}
+
+//------------------------------------------------------------------------------
+
+func appendWALRecord(buf []byte, seq uint64, payload []byte) []byte {
+	var seqBytes [8]byte
+	binary.BigEndian.PutUint64(seqBytes[:], seq)
+	crc := crc32.Update(crc32.ChecksumIEEE(seqBytes[:]), crc32.IEEETable, payload)
+
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = binary.BigEndian.AppendUint32(buf, crc)
+	buf = append(buf, seqBytes[:]...)
+	return append(buf, payload...)
+}
+
+// readWALRecord reads the record at an offset of a segment, returning its
+// sequence, payload and total length.
+func readWALRecord(r io.ReaderAt, offset, size int64) (uint64, []byte, int64, error) {
+	var header [walRecordHeader]byte
+	if size-offset < walRecordHeader {
+		return 0, nil, 0, errWALCorrupt
+	}
+	if _, err := r.ReadAt(header[:], offset); err != nil {
+		return 0, nil, 0, err
+	}
+
+	payloadLen := int64(binary.BigEndian.Uint32(header[0:4]))
+	if payloadLen > size-offset-walRecordHeader {
+		return 0, nil, 0, errWALCorrupt
+	}
+	payload := make([]byte, payloadLen)
+	if _, err := r.ReadAt(payload, offset+walRecordHeader); err != nil {
+		return 0, nil, 0, err
+	}
+	if crc32.Update(crc32.ChecksumIEEE(header[8:16]), crc32.IEEETable, payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return 0, nil, 0, errWALCorrupt
+	}
+	return binary.BigEndian.Uint64(header[8:16]), payload, walRecordHeader + payloadLen, nil
+}
+
+func appendWALBatch(buf []byte, batch service.MessageBatch) ([]byte, error) {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(batch)))
+	for _, msg := range batch {
+		metaObj := map[string]any{}
+		_ = msg.MetaWalkMut(func(key string, value any) error {
+			metaObj[key] = value
+			return nil
+		})
+		metaBytes, err := msgpack.Marshal(metaObj)
+		if err != nil {
+			return nil, err
+		}
+		msgBytes, err := msg.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(metaBytes)))
+		buf = append(buf, metaBytes...)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(msgBytes)))
+		buf = append(buf, msgBytes...)
+	}
+	return buf, nil
+}
+
+func readWALBytes(b []byte) ([]byte, []byte, error) {
+	if len(b) < 4 {
+		return nil, nil, errWALCorrupt
+	}
+	l := binary.BigEndian.Uint32(b)
+	b = b[4:]
+	if uint64(len(b)) < uint64(l) {
+		return nil, nil, errWALCorrupt
+	}
+	return b[:l], b[l:], nil
+}
+
+func readWALBatch(b []byte) (service.MessageBatch, error) {
+	if len(b) < 4 {
+		return nil, errWALCorrupt
+	}
+	parts := binary.BigEndian.Uint32(b)
+	b = b[4:]
+
+	var batch service.MessageBatch
+	for i := uint32(0); i < parts; i++ {
+		var metaBytes, contentBytes []byte
+		var err error
+		if metaBytes, b, err = readWALBytes(b); err != nil {
+			return nil, err
+		}
+		if contentBytes, b, err = readWALBytes(b); err != nil {
+			return nil, err
+		}
+
+		msg := service.NewMessage(contentBytes)
+		metaObj := map[string]any{}
+		if err := msgpack.Unmarshal(metaBytes, &metaObj); err != nil {
+			return nil, err
+		}
+		for k, v := range metaObj {
+			msg.MetaSetMut(k, v)
+		}
+		batch = append(batch, msg)
+	}
+	return batch, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (w *walBuffer) rollSegment() error {
+	if w.writeFile != nil {
+		if w.conf.Sync != walSyncNone {
+			if err := w.writeFile.Sync(); err != nil {
+				return err
+			}
+		}
+		if err := w.writeFile.Close(); err != nil {
+			return err
+		}
+		w.writeFile = nil
+		w.writeDirty = false
+	}
+
+	seg := &walSegment{
+		path:      filepath.Join(w.conf.Path, fmt.Sprintf("%020d%v", w.nextSeq, walSegmentSuffix)),
+		firstSeq:  w.nextSeq,
+		nextSeq:   w.nextSeq,
+		lastWrite: w.now(),
+	}
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := w.syncDir(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.writeFile = f
+	w.segments = append(w.segments, seg)
+	return nil
+}
+
+func (w *walBuffer) activeSegment() *walSegment {
+	if w.writeFile == nil {
+		return nil
+	}
+	return w.segments[len(w.segments)-1]
+}
+
+// removeFirstSegment deletes the oldest segment from disk.
+func (w *walBuffer) removeFirstSegment() {
+	seg := w.segments[0]
+	if w.writeFile != nil && len(w.segments) == 1 {
+		_ = w.writeFile.Close()
+		w.writeFile = nil
+		w.writeDirty = false
+	}
+	if w.readPath == seg.path {
+		_ = w.readFile.Close()
+		w.readFile, w.readPath = nil, ""
+	}
+	if err := os.Remove(seg.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		w.log.Errorf("Failed to remove segment %v: %v", seg.path, err)
+	}
+	w.totalSize -= seg.size
+	w.segments = w.segments[1:]
+	w.mSize.Set(w.totalSize)
+}
+
+// compact deletes all segments that only contain acknowledged records.
+func (w *walBuffer) compact() {
+	for len(w.segments) > 0 && w.segments[0].nextSeq <= w.cursor {
+		if w.segments[0].size == 0 && w.activeSegment() == w.segments[0] {
+			return
+		}
+		w.removeFirstSegment()
+	}
+}
+
+// dropOldest deletes the oldest segment regardless of whether its records
+// have been acknowledged, returning false if there are no segments.
+func (w *walBuffer) dropOldest(reason string) bool {
+	if len(w.segments) == 0 {
+		return false
+	}
+
+	seg := w.segments[0]
+	dropped := int64(0)
+	for seq := max(seg.firstSeq, w.cursor); seq < seg.nextSeq; seq++ {
+		if _, exists := w.acked[seq]; !exists {
+			dropped++
+		}
+	}
+	w.removeFirstSegment()
+
+	if seg.nextSeq > w.cursor {
+		w.setCursor(seg.nextSeq)
+	}
+	if dropped > 0 {
+		w.log.Warnf("Dropped %v batches from segment %v that were not delivered: %v", dropped, seg.path, reason)
+		w.mDropped.Incr(dropped)
+	}
+	return true
+}
+
+func (w *walBuffer) setCursor(cursor uint64) {
+	for seq := range w.acked {
+		if seq < cursor {
+			delete(w.acked, seq)
+		}
+	}
+	for seq := range w.inFlight {
+		if seq < cursor {
+			delete(w.inFlight, seq)
+		}
+	}
+	requeued := w.requeued[:0]
+	for _, e := range w.requeued {
+		if e.seq >= cursor {
+			requeued = append(requeued, e)
+		}
+	}
+	w.requeued = requeued
+
+	w.cursor = cursor
+	w.cursorDirty = true
+	if w.readSeq < cursor {
+		w.readSeq = cursor
+	}
+}
+
+func (w *walBuffer) advanceCursor() {
+	cursor := w.cursor
+	for cursor < w.readSeq {
+		if _, exists := w.acked[cursor]; !exists {
+			break
+		}
+		delete(w.acked, cursor)
+		cursor++
+	}
+	if cursor == w.cursor {
+		return
+	}
+	w.cursor = cursor
+	w.cursorDirty = true
+
+	w.compact()
+	if w.conf.Sync == walSyncAlways {
+		if err := w.persistCursor(); err != nil {
+			w.log.Errorf("Failed to persist cursor: %v", err)
+		}
+	}
+	w.cond.Broadcast()
+}
+
+func (w *walBuffer) enforceRetention() {
+	if w.conf.Retention <= 0 {
+		return
+	}
+	cutoff := w.now().Add(-w.conf.Retention)
+	for len(w.segments) > 0 && w.segments[0].lastWrite.Before(cutoff) {
+		w.dropOldest("segment exceeded the retention period")
+	}
+	w.cond.Broadcast()
+}
+
+func (w *walBuffer) maintenanceLoop() {
+	defer w.shutSig.TriggerHasStopped()
+
+	interval := time.Second
+	if w.conf.Sync == walSyncInterval {
+		interval = w.conf.SyncInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.shutSig.SoftStopChan():
+			return
+		}
+
+		w.cond.L.Lock()
+		if !w.closed {
+			w.enforceRetention()
+			if err := w.sync(); err != nil {
+				w.log.Errorf("Failed to sync write-ahead log: %v", err)
+			}
+		}
+		w.cond.L.Unlock()
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// readNext reads the next unread record from the segments, returning false if
+// there are none.
+func (w *walBuffer) readNext() (walEntry, bool, error) {
+	for w.readSeq < w.nextSeq {
+		var seg *walSegment
+		for _, s := range w.segments {
+			if s.nextSeq > w.readSeq {
+				seg = s
+				break
+			}
+		}
+		if seg == nil {
+			return walEntry{}, false, nil
+		}
+
+		// Records lost to a truncated segment are skipped over.
+		if w.readSeq < seg.firstSeq {
+			for ; w.readSeq < seg.firstSeq; w.readSeq++ {
+				w.acked[w.readSeq] = struct{}{}
+			}
+			w.advanceCursor()
+		}
+
+		if w.readPath != seg.path {
+			if w.readFile != nil {
+				_ = w.readFile.Close()
+			}
+			f, err := os.Open(seg.path)
+			if err != nil {
+				return walEntry{}, false, err
+			}
+			w.readFile, w.readPath, w.readOffset = f, seg.path, 0
+		}
+
+		seq, payload, n, err := readWALRecord(w.readFile, w.readOffset, seg.size)
+		if err != nil {
+			return walEntry{}, false, err
+		}
+		w.readOffset += n
+		if seq < w.readSeq {
+			continue
+		}
+		w.readSeq = seq + 1
+
+		batch, err := readWALBatch(payload)
+		if err != nil {
+			return walEntry{}, false, err
+		}
+		return walEntry{seq: seq, batch: batch}, true, nil
+	}
+	return walEntry{}, false, nil
+}
+
+func (w *walBuffer) ackFn(e walEntry) service.AckFunc {
+	var once sync.Once
+	return func(ctx context.Context, err error) error {
+		once.Do(func() {
+			w.cond.L.Lock()
+			defer w.cond.L.Unlock()
+
+			if _, exists := w.inFlight[e.seq]; !exists {
+				// The record was dropped whilst in flight.
+				return
+			}
+			delete(w.inFlight, e.seq)
+
+			if err != nil {
+				w.requeued = append(w.requeued, e)
+				w.cond.Broadcast()
+				return
+			}
+			w.acked[e.seq] = struct{}{}
+			w.advanceCursor()
+			w.cond.Broadcast()
+		})
+		return nil
+	}
+}
+
+// ReadBatch reads the oldest batch that has not yet been delivered.
+func (w *walBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+
+	go func() {
+		<-ctx.Done()
+		w.cond.Broadcast()
+	}()
+
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	for {
+		if w.closed {
+			return nil, nil, service.ErrEndOfBuffer
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+
+		var e walEntry
+		var ok bool
+		if len(w.requeued) > 0 {
+			e, ok = w.requeued[0], true
+			w.requeued = w.requeued[1:]
+		} else {
+			var err error
+			if e, ok, err = w.readNext(); err != nil {
+				return nil, nil, err
+			}
+		}
+		if ok {
+			w.inFlight[e.seq] = struct{}{}
+			return e.batch, w.ackFn(e), nil
+		}
+
+		if w.endOfInput && len(w.inFlight) == 0 {
+			return nil, nil, service.ErrEndOfBuffer
+		}
+		w.cond.Wait()
+	}
+}
+
+// makeRoom ensures that a record of a given size can be written without
+// exceeding the max size, either by blocking or by dropping old segments.
+func (w *walBuffer) makeRoom(ctx context.Context, size int64) error {
+	if w.conf.MaxSize <= 0 {
+		return nil
+	}
+
+	var stopWaiting func() bool
+	defer func() {
+		if stopWaiting != nil {
+			stopWaiting()
+		}
+	}()
+
+	for w.totalSize > 0 && w.totalSize+size > w.conf.MaxSize {
+		if w.conf.OnFull == walOnFullDropOldest {
+			w.dropOldest("max_size reached")
+			continue
+		}
+
+		if w.closed {
+			return service.ErrEndOfBuffer
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if stopWaiting == nil {
+			stopWaiting = context.AfterFunc(ctx, w.cond.Broadcast)
+		}
+		w.cond.Wait()
+	}
+	return nil
+}
+
+// WriteBatch appends a batch to the active segment.
+func (w *walBuffer) WriteBatch(ctx context.Context, batch service.MessageBatch, aFn service.AckFunc) error {
+	payload, err := appendWALBatch(nil, batch)
+	if err != nil {
+		return err
+	}
+	if len(payload) >= walMaxRecordBytes {
+		return fmt.Errorf("batch of size %v exceeds the maximum record size", len(payload))
+	}
+
+	if err := w.writeRecord(ctx, payload); err != nil {
+		return err
+	}
+
+	// Acknowledge outside of the lock as it may block on upstream components.
+	return aFn(ctx, nil)
+}
+
+// writeRecord appends a record containing a payload to the active segment.
+func (w *walBuffer) writeRecord(ctx context.Context, payload []byte) error {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	if w.closed {
+		return service.ErrEndOfBuffer
+	}
+
+	if err := w.makeRoom(ctx, walRecordHeader+int64(len(payload))); err != nil {
+		return err
+	}
+
+	seg := w.activeSegment()
+	if seg == nil || seg.size >= w.conf.SegmentSize {
+		if err := w.rollSegment(); err != nil {
+			return err
+		}
+		seg = w.activeSegment()
+	}
+
+	record := appendWALRecord(nil, w.nextSeq, payload)
+	if _, err := w.writeFile.Write(record); err != nil {
+		_ = w.writeFile.Truncate(seg.size)
+		return err
+	}
+	seg.size += int64(len(record))
+	seg.nextSeq++
+	seg.lastWrite = w.now()
+	w.nextSeq++
+	w.totalSize += int64(len(record))
+	w.mSize.Set(w.totalSize)
+
+	w.writeDirty = true
+	if w.conf.Sync == walSyncAlways {
+		if err := w.writeFile.Sync(); err != nil {
+			return err
+		}
+		w.writeDirty = false
+	}
+
+	w.cond.Broadcast()
+	return nil
+}
+
+// EndOfInput signals to the buffer that the input is finished and therefore
+// once the segments are drained it should close.
+func (w *walBuffer) EndOfInput() {
+	go func() {
+		w.cond.L.Lock()
+		defer w.cond.L.Unlock()
+
+		w.endOfInput = true
+		w.cond.Broadcast()
+	}()
+}
+
+// Close flushes the segments and cursor to disk and closes all files.
+func (w *walBuffer) Close(ctx context.Context) error {
+	w.shutSig.TriggerSoftStop()
+
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.cond.Broadcast()
+
+	err := w.sync()
+	if w.writeFile != nil {
+		if cErr := w.writeFile.Close(); err == nil {
+			err = cErr
+		}
+		w.writeFile = nil
+	}
+	if w.readFile != nil {
+		_ = w.readFile.Close()
+		w.readFile = nil
+	}
+	return err
+}
@@ -0,0 +1,324 @@
+package io
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func walBufFromConf(t testing.TB, conf string, args ...any) *walBuffer {
+	t.Helper()
+
+	pConf, err := walBufferSpec().ParseYAML(fmt.Sprintf(conf, args...), nil)
+	require.NoError(t, err)
+
+	buf, err := newWALBufferFromConfig(pConf, service.MockResources())
+	require.NoError(t, err)
+	return buf
+}
+
+func walWrite(t testing.TB, buf *walBuffer, contents ...string) {
+	t.Helper()
+
+	var batch service.MessageBatch
+	for _, c := range contents {
+		msg := service.NewMessage([]byte(c))
+		msg.MetaSetMut("content", c)
+		batch = append(batch, msg)
+	}
+	require.NoError(t, buf.WriteBatch(context.Background(), batch, func(ctx context.Context, err error) error {
+		return err
+	}))
+}
+
+func walRead(t testing.TB, buf *walBuffer) ([]string, service.AckFunc) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*5)
+	defer done()
+
+	batch, aFn, err := buf.ReadBatch(ctx)
+	require.NoError(t, err)
+
+	var contents []string
+	for _, msg := range batch {
+		mBytes, err := msg.AsBytes()
+		require.NoError(t, err)
+		contents = append(contents, string(mBytes))
+
+		v, _ := msg.MetaGetMut("content")
+		assert.Equal(t, string(mBytes), v)
+	}
+	return contents, aFn
+}
+
+func TestBufferWALBasic(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	buf := walBufFromConf(t, `
+path: %v
+sync: always
+`, dir)
+
+	walWrite(t, buf, "hello", "world")
+	walWrite(t, buf, "foo")
+
+	contents, aFn := walRead(t, buf)
+	assert.Equal(t, []string{"hello", "world"}, contents)
+	require.NoError(t, aFn(ctx, nil))
+
+	// Rejected batches are delivered again.
+	contents, aFn = walRead(t, buf)
+	assert.Equal(t, []string{"foo"}, contents)
+	require.NoError(t, aFn(ctx, errors.New("nope")))
+
+	contents, aFn = walRead(t, buf)
+	assert.Equal(t, []string{"foo"}, contents)
+	require.NoError(t, aFn(ctx, nil))
+
+	buf.EndOfInput()
+	_, _, err := buf.ReadBatch(ctx)
+	require.ErrorIs(t, err, service.ErrEndOfBuffer)
+
+	// Acknowledged segments are compacted.
+	segs, err := filepath.Glob(filepath.Join(dir, "*"+walSegmentSuffix))
+	require.NoError(t, err)
+	assert.Empty(t, segs)
+
+	require.NoError(t, buf.Close(ctx))
+}
+
+func TestBufferWALAckOutsideLock(t *testing.T) {
+	ctx := context.Background()
+
+	buf := walBufFromConf(t, `
+path: %v
+sync: always
+`, t.TempDir())
+
+	// Acknowledgements may block on upstream components and therefore must
+	// not be made whilst holding the lock of the buffer.
+	var acked bool
+	require.NoError(t, buf.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte("foo"))}, func(ctx context.Context, err error) error {
+		mut := buf.cond.L.(*sync.Mutex)
+		require.True(t, mut.TryLock())
+		mut.Unlock()
+		acked = true
+		return err
+	}))
+	assert.True(t, acked)
+
+	require.NoError(t, buf.Close(ctx))
+}
+
+func TestBufferWALRecovery(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	buf := walBufFromConf(t, `
+path: %v
+segment_size: 50
+`, dir)
+	for i := 0; i < 5; i++ {
+		walWrite(t, buf, fmt.Sprintf("msg %v", i))
+	}
+
+	// Only the first two are acknowledged, the third is in flight.
+	for i := 0; i < 3; i++ {
+		contents, aFn := walRead(t, buf)
+		assert.Equal(t, []string{fmt.Sprintf("msg %v", i)}, contents)
+		if i < 2 {
+			require.NoError(t, aFn(ctx, nil))
+		}
+	}
+	require.NoError(t, buf.Close(ctx))
+
+	// Simulate a torn write at the end of the last segment.
+	segs, err := filepath.Glob(filepath.Join(dir, "*"+walSegmentSuffix))
+	require.NoError(t, err)
+	require.NotEmpty(t, segs)
+	f, err := os.OpenFile(segs[len(segs)-1], os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write(appendWALRecord(nil, 5, []byte("torn"))[:10])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	buf = walBufFromConf(t, `
+path: %v
+segment_size: 50
+`, dir)
+	for i := 2; i < 5; i++ {
+		contents, aFn := walRead(t, buf)
+		assert.Equal(t, []string{fmt.Sprintf("msg %v", i)}, contents)
+		require.NoError(t, aFn(ctx, nil))
+	}
+
+	// New writes continue after the truncated record.
+	walWrite(t, buf, "msg 5")
+	contents, aFn := walRead(t, buf)
+	assert.Equal(t, []string{"msg 5"}, contents)
+	require.NoError(t, aFn(ctx, nil))
+
+	buf.EndOfInput()
+	_, _, err = buf.ReadBatch(ctx)
+	require.ErrorIs(t, err, service.ErrEndOfBuffer)
+	require.NoError(t, buf.Close(ctx))
+}
+
+func TestBufferWALOutOfOrderAcks(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	buf := walBufFromConf(t, `
+path: %v
+segment_size: 1
+`, dir)
+	for i := 0; i < 3; i++ {
+		walWrite(t, buf, fmt.Sprintf("msg %v", i))
+	}
+
+	var aFns []service.AckFunc
+	for i := 0; i < 3; i++ {
+		_, aFn := walRead(t, buf)
+		aFns = append(aFns, aFn)
+	}
+
+	// The cursor does not pass the first batch until it is acknowledged.
+	require.NoError(t, aFns[2](ctx, nil))
+	require.NoError(t, aFns[1](ctx, nil))
+	assert.Equal(t, uint64(0), buf.cursor)
+	assert.Len(t, buf.segments, 3)
+
+	require.NoError(t, aFns[0](ctx, nil))
+	assert.Equal(t, uint64(3), buf.cursor)
+	assert.Empty(t, buf.segments)
+
+	require.NoError(t, buf.Close(ctx))
+
+	cursor, ok, err := buf.readCursor()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), cursor)
+}
+
+func TestBufferWALMaxSizeBlock(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	buf := walBufFromConf(t, `
+path: %v
+segment_size: 1
+max_size: 100
+`, dir)
+
+	walWrite(t, buf, "msg 0")
+	walWrite(t, buf, "msg 1")
+
+	blockedCtx, done := context.WithTimeout(ctx, time.Millisecond*50)
+	err := buf.WriteBatch(blockedCtx, service.MessageBatch{service.NewMessage([]byte("msg 2"))}, func(ctx context.Context, err error) error {
+		return err
+	})
+	done()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	writeErr := make(chan error)
+	go func() {
+		writeErr <- buf.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte("msg 2"))}, func(ctx context.Context, err error) error {
+			return err
+		})
+	}()
+
+	contents, aFn := walRead(t, buf)
+	assert.Equal(t, []string{"msg 0"}, contents)
+	require.NoError(t, aFn(ctx, nil))
+
+	select {
+	case err := <-writeErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	contents, _ = walRead(t, buf)
+	assert.Equal(t, []string{"msg 1"}, contents)
+	require.NoError(t, buf.Close(ctx))
+}
+
+func TestBufferWALMaxSizeDropOldest(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	buf := walBufFromConf(t, `
+path: %v
+segment_size: 1
+max_size: 100
+on_full: drop_oldest
+`, dir)
+
+	walWrite(t, buf, "msg 0")
+	walWrite(t, buf, "msg 1")
+
+	_, aFn := walRead(t, buf)
+
+	walWrite(t, buf, "msg 2")
+	assert.LessOrEqual(t, buf.totalSize, int64(100))
+
+	// Acknowledging a dropped batch has no effect.
+	require.NoError(t, aFn(ctx, nil))
+
+	contents, aFn := walRead(t, buf)
+	assert.Equal(t, []string{"msg 1"}, contents)
+	require.NoError(t, aFn(ctx, nil))
+
+	contents, aFn = walRead(t, buf)
+	assert.Equal(t, []string{"msg 2"}, contents)
+	require.NoError(t, aFn(ctx, nil))
+
+	require.NoError(t, buf.Close(ctx))
+}
+
+func TestBufferWALRetention(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	buf := walBufFromConf(t, `
+path: %v
+segment_size: 1
+retention: 1h
+`, dir)
+
+	now := time.Now()
+	buf.cond.L.Lock()
+	buf.now = func() time.Time { return now }
+	buf.cond.L.Unlock()
+
+	walWrite(t, buf, "msg 0")
+
+	buf.cond.L.Lock()
+	now = now.Add(time.Minute * 30)
+	buf.cond.L.Unlock()
+
+	walWrite(t, buf, "msg 1")
+
+	buf.cond.L.Lock()
+	now = now.Add(time.Minute * 45)
+	buf.enforceRetention()
+	buf.cond.L.Unlock()
+
+	contents, aFn := walRead(t, buf)
+	assert.Equal(t, []string{"msg 1"}, contents)
+	require.NoError(t, aFn(ctx, nil))
+
+	require.NoError(t, buf.Close(ctx))
+}
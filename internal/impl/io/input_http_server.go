@@ -48,6 +48,7 @@ const (
 	hsiFieldAllowedVerbs            = "allowed_verbs"
 	hsiFieldTimeout                 = "timeout"
 	hsiFieldRateLimit               = "rate_limit"
+	hsiFieldRateLimitKey            = "rate_limit_key"
 	hsiFieldCertFile                = "cert_file"
 	hsiFieldKeyFile                 = "key_file"
 	hsiFieldCORS                    = "cors"
@@ -70,6 +71,7 @@ type hsiConfig struct {
 	AllowedVerbs       map[string]struct{}
 	Timeout            time.Duration
 	RateLimit          string
+	RateLimitKey       *service.InterpolatedString
 	CertFile           string
 	KeyFile            string
 	CORS               httpserver.CORSConfig
@@ -118,6 +120,11 @@ func hsiConfigFromParsed(pConf *service.ParsedConfig) (conf hsiConfig, err error
 	if conf.RateLimit, err = pConf.FieldString(hsiFieldRateLimit); err != nil {
 		return
 	}
+	if pConf.Contains(hsiFieldRateLimitKey) {
+		if conf.RateLimitKey, err = pConf.FieldInterpolatedString(hsiFieldRateLimitKey); err != nil {
+			return
+		}
+	}
 	if conf.CertFile, err = pConf.FieldString(hsiFieldCertFile); err != nil {
 		return
 	}
@@ -258,6 +265,11 @@ You can access these metadata fields using [function interpolation](/docs/config
 			service.NewStringField(hsiFieldRateLimit).
 				Description("An optional [rate limit](/docs/components/rate_limits/about) to throttle requests by.").
 				Default(""),
+			service.NewInterpolatedStringField(hsiFieldRateLimitKey).
+				Description("An optional key to access the `rate_limit` with, where each key is limited independently. The key is resolved against the first message of a request, and therefore the body of a request is read before the rate limit is checked. The target rate limit must support keys, such as a `local` or `redis` rate limit with a `rate` configured.").
+				Example(`${! meta("X-Tenant-Id") }`).
+				Advanced().
+				Optional(),
 			service.NewStringField(hsiFieldCertFile).
 				Description("Enable TLS by specifying a certificate and key file. Only valid with a custom `address`.").
 				Advanced().
@@ -436,6 +448,15 @@ func newHTTPServerInput(conf hsiConfig, mgr bundle.NewManagement) (input.Streame
 		if !h.mgr.ProbeRateLimit(h.conf.RateLimit) {
 			return nil, fmt.Errorf("rate limit resource '%v' was not found", h.conf.RateLimit)
 		}
+		if h.conf.RateLimitKey != nil {
+			keysSupported := true
+			_ = h.mgr.AccessRateLimit(context.Background(), h.conf.RateLimit, func(rl ratelimit.V1) {
+				keysSupported = ratelimit.SupportsKeys(rl)
+			})
+			if !keysSupported {
+				return nil, fmt.Errorf("rate limit resource '%v' does not support keys", h.conf.RateLimit)
+			}
+		}
 	}
 
 	go h.loop()
@@ -537,6 +558,38 @@ func (h *httpServerInput) extractMessageFromRequest(r *http.Request) (message.Ba
 	return msg, nil
 }
 
+func (h *httpServerInput) rateLimitKey(msg message.Batch) (string, error) {
+	if h.conf.RateLimitKey == nil || msg.Len() == 0 {
+		return "", nil
+	}
+	return service.MessageBatch{service.NewInternalMessage(msg.Get(0))}.TryInterpolatedString(0, h.conf.RateLimitKey)
+}
+
+func (h *httpServerInput) accessRateLimit(ctx context.Context, key string) (tUntil time.Duration, err error) {
+	if rerr := h.mgr.AccessRateLimit(ctx, h.conf.RateLimit, func(rl ratelimit.V1) {
+		tUntil, err = ratelimit.AccessKey(ctx, rl, key)
+	}); rerr != nil {
+		err = rerr
+	}
+	return
+}
+
+// checkRateLimit accesses the rate limit on behalf of a request, writing an
+// error response and returning false if the request should be rejected.
+func (h *httpServerInput) checkRateLimit(w http.ResponseWriter, r *http.Request, key string) bool {
+	tUntil, err := h.accessRateLimit(r.Context(), key)
+	if err != nil {
+		http.Error(w, "Server error", http.StatusBadGateway)
+		h.log.Warn("Failed to access rate limit: %v\n", err)
+		return false
+	} else if tUntil > 0 {
+		w.Header().Add("Retry-After", strconv.Itoa(int(tUntil.Seconds())))
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
 func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 	if h.shutSig.IsSoftStopSignalled() {
 		http.Error(w, "Server closing", http.StatusServiceUnavailable)
@@ -552,27 +605,23 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if h.conf.RateLimit != "" {
-		var tUntil time.Duration
-		var err error
-		if rerr := h.mgr.AccessRateLimit(r.Context(), h.conf.RateLimit, func(rl ratelimit.V1) {
-			tUntil, err = rl.Access(r.Context())
-		}); rerr != nil {
-			http.Error(w, "Server error", http.StatusBadGateway)
-			h.log.Warn("Failed to access rate limit: %v\n", rerr)
-			return
-		}
-		if err != nil {
-			http.Error(w, "Server error", http.StatusBadGateway)
-			h.log.Warn("Failed to access rate limit: %v\n", err)
-			return
-		} else if tUntil > 0 {
-			w.Header().Add("Retry-After", strconv.Itoa(int(tUntil.Seconds())))
-			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+	if h.conf.RateLimit != "" && h.conf.RateLimitKey == nil {
+		if !h.checkRateLimit(w, r, "") {
 			return
 		}
 	}
 
+	// Hold off reading the request body until messages in flight have been
+	// acknowledged if the memory budget is exhausted.
+	budget := h.mgr.MemoryBudget()
+	budgetCtx, budgetDone := context.WithTimeout(r.Context(), h.conf.Timeout)
+	err := budget.Wait(budgetCtx)
+	budgetDone()
+	if err != nil {
+		http.Error(w, "Request timed out", http.StatusRequestTimeout)
+		return
+	}
+
 	msg, err := h.extractMessageFromRequest(r)
 	if err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
@@ -581,6 +630,18 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tracing.FinishSpans(msg)
 
+	if h.conf.RateLimit != "" && h.conf.RateLimitKey != nil {
+		key, err := h.rateLimitKey(msg)
+		if err != nil {
+			http.Error(w, "Server error", http.StatusBadGateway)
+			h.log.Warn("Failed to resolve rate limit key: %v\n", err)
+			return
+		}
+		if !h.checkRateLimit(w, r, key) {
+			return
+		}
+	}
+
 	startedAt := time.Now()
 
 	store := transaction.NewResultStore()
@@ -590,15 +651,19 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 	h.log.Trace("Consumed %v messages from POST to '%v'.\n", msg.Len(), h.conf.Path)
 
 	resChan := make(chan error, 1)
+	tran, releaseBudget := budget.NewTransaction(msg, resChan)
 	select {
-	case h.transactions <- message.NewTransaction(msg, resChan):
+	case h.transactions <- tran:
 	case <-time.After(h.conf.Timeout):
+		releaseBudget()
 		http.Error(w, "Request timed out", http.StatusRequestTimeout)
 		return
 	case <-r.Context().Done():
+		releaseBudget()
 		http.Error(w, "Request timed out", http.StatusRequestTimeout)
 		return
 	case <-h.shutSig.SoftStopChan():
+		releaseBudget()
 		http.Error(w, "Server closing", http.StatusServiceUnavailable)
 		return
 	}
@@ -621,6 +686,7 @@ func (h *httpServerInput) postHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Request timed out", http.StatusRequestTimeout)
 		return
 	case <-h.shutSig.HardStopChan():
+		releaseBudget()
 		http.Error(w, "Server closing", http.StatusServiceUnavailable)
 		return
 	}
@@ -759,38 +825,25 @@ func (h *httpServerInput) wsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	budget := h.mgr.MemoryBudget()
+	budgetCtx, budgetDone := h.shutSig.SoftStopCtx(r.Context())
+	defer budgetDone()
+
 	var msgBytes []byte
 	for !h.shutSig.IsSoftStopSignalled() {
 		if msgBytes == nil {
+			// Hold off reading the next message until messages in flight have
+			// been acknowledged if the memory budget is exhausted.
+			if budget.Wait(budgetCtx) != nil {
+				return
+			}
 			if _, msgBytes, err = ws.ReadMessage(); err != nil {
 				return
 			}
 			h.mWSRcvd.Incr(1)
 		}
 
-		if h.conf.RateLimit != "" {
-			var tUntil time.Duration
-			if rerr := h.mgr.AccessRateLimit(r.Context(), h.conf.RateLimit, func(rl ratelimit.V1) {
-				tUntil, err = rl.Access(r.Context())
-			}); rerr != nil {
-				h.log.Warn("Failed to access rate limit: %v\n", rerr)
-				err = rerr
-			}
-			if err != nil || tUntil > 0 {
-				if err != nil {
-					h.log.Warn("Failed to access rate limit: %v\n", err)
-				}
-				if rlMsg := h.conf.WSRateLimitMessage; rlMsg != "" {
-					if err = ws.WriteMessage(websocket.BinaryMessage, []byte(rlMsg)); err != nil {
-						h.log.Error("Failed to send rate limit message: %v\n", err)
-					}
-				}
-				continue
-			}
-		}
-
 		msg := message.QuickBatch([][]byte{msgBytes})
-		startedAt := time.Now()
 
 		part := msg.Get(0)
 		part.MetaSetMut("http_server_user_agent", r.UserAgent())
@@ -810,14 +863,37 @@ func (h *httpServerInput) wsHandler(w http.ResponseWriter, r *http.Request) {
 		for _, c := range r.Cookies() {
 			part.MetaSetMut(c.Name, c.Value)
 		}
+
+		if h.conf.RateLimit != "" {
+			var tUntil time.Duration
+			var key string
+			if key, err = h.rateLimitKey(msg); err == nil {
+				tUntil, err = h.accessRateLimit(r.Context(), key)
+			}
+			if err != nil || tUntil > 0 {
+				if err != nil {
+					h.log.Warn("Failed to access rate limit: %v\n", err)
+				}
+				if rlMsg := h.conf.WSRateLimitMessage; rlMsg != "" {
+					if err = ws.WriteMessage(websocket.BinaryMessage, []byte(rlMsg)); err != nil {
+						h.log.Error("Failed to send rate limit message: %v\n", err)
+					}
+				}
+				continue
+			}
+		}
+
+		startedAt := time.Now()
 		tracing.InitSpans(h.mgr.Tracer(), "input_http_server_websocket", msg)
 
 		store := transaction.NewResultStore()
 		transaction.AddResultStore(msg, store)
 
+		tran, releaseBudget := budget.NewTransaction(msg, resChan)
 		select {
-		case h.transactions <- message.NewTransaction(msg, resChan):
+		case h.transactions <- tran:
 		case <-h.shutSig.SoftStopChan():
+			releaseBudget()
 			return
 		}
 		select {
@@ -834,6 +910,7 @@ func (h *httpServerInput) wsHandler(w http.ResponseWriter, r *http.Request) {
 				throt.Reset()
 			}
 		case <-h.shutSig.HardStopChan():
+			releaseBudget()
 			return
 		}
 
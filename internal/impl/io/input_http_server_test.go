@@ -779,6 +779,101 @@ http_server:
 	}
 }
 
+func TestHTTPRateLimitKeyed(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	t.Parallel()
+
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+
+	mgrConf, err := testutil.ManagerFromYAML(`
+rate_limit_resources:
+  - label: foorl
+    local:
+      rate: 0.001
+      burst: 1
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(mgrConf, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /testpost
+  rate_limit: foorl
+  rate_limit_key: ${! meta("X-Tenant") }
+`)
+
+	h, err := mgr.NewInput(conf)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(reg.mut)
+	defer server.Close()
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			var ts message.Transaction
+			select {
+			case ts = <-h.TransactionChan():
+			case <-time.After(time.Second):
+				t.Error("Timed out waiting for message")
+				return
+			}
+			require.NoError(t, ts.Ack(tCtx, nil))
+		}
+	}()
+
+	post := func(tenant string) int {
+		t.Helper()
+
+		req, err := http.NewRequest("POST", server.URL+"/testpost", bytes.NewBufferString("hello world"))
+		require.NoError(t, err)
+		req.Header.Set("X-Tenant", tenant)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return res.StatusCode
+	}
+
+	// Each tenant is limited independently.
+	assert.Equal(t, http.StatusOK, post("foo"))
+	assert.Equal(t, http.StatusOK, post("bar"))
+	assert.Equal(t, http.StatusTooManyRequests, post("foo"))
+	assert.Equal(t, http.StatusTooManyRequests, post("bar"))
+
+	h.TriggerStopConsuming()
+	require.NoError(t, h.WaitForClose(tCtx))
+}
+
+func TestHTTPRateLimitKeyNotSupported(t *testing.T) {
+	reg := apiRegGorillaMutWrapper{mut: mux.NewRouter()}
+
+	mgrConf, err := testutil.ManagerFromYAML(`
+rate_limit_resources:
+  - label: foorl
+    local:
+      count: 1
+      interval: 1s
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(mgrConf, manager.OptSetAPIReg(reg))
+	require.NoError(t, err)
+
+	conf := parseYAMLInputConf(t, `
+http_server:
+  path: /testpost
+  rate_limit: foorl
+  rate_limit_key: ${! meta("X-Tenant") }
+`)
+
+	_, err = mgr.NewInput(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit resource 'foorl' does not support keys")
+}
+
 func TestHTTPServerWebsockets(t *testing.T) {
 	tCtx, done := context.WithTimeout(context.Background(), time.Minute)
 	defer done()
@@ -0,0 +1,102 @@
+package io
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	flFieldDirectory = "directory"
+
+	// Leases of a file lock are held until they are released or the process
+	// holding them exits, and therefore never expire. The TTL only determines
+	// how frequently holders check that they still own the lease.
+	fileLeaseTTL = time.Second * 5
+)
+
+func fileLockConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Summary(`Grants leases on named locks by obtaining an exclusive lock on a file within a directory, allowing multiple Bento processes on the same host to coordinate.`).
+		Description(`
+Each named lock is represented by a file ` + "`<name>.lock`" + ` within the configured directory, which is created if it does not already exist. Leases are held until they are released or the process holding them exits, at which point the operating system frees the file lock immediately and another process may acquire it.
+
+Lock files are left in place after being released and must not be deleted whilst in use. File locks are not reliable on many network file systems, and therefore this lock should only be used for coordinating processes that share a local disk.`).
+		Field(service.NewStringField(flFieldDirectory).
+			Description("The directory within which to create lock files.").
+			Example("/var/run/bento"))
+}
+
+func init() {
+	err := service.RegisterLock(
+		"file", fileLockConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+			return newFileLockFromConfig(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newFileLockFromConfig(conf *service.ParsedConfig) (*fileLock, error) {
+	dir, err := conf.FieldString(flFieldDirectory)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	return &fileLock{dir: dir}, nil
+}
+
+//------------------------------------------------------------------------------
+
+type fileLock struct {
+	dir string
+}
+
+func (f *fileLock) Acquire(_ context.Context, name string) (service.LockLease, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("lock name %q is not a valid file name", name)
+	}
+	fl := flock.New(filepath.Join(f.dir, name+".lock"))
+	locked, err := fl.TryLock()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, service.ErrLockHeld
+	}
+	return &fileLease{fl: fl}, nil
+}
+
+func (f *fileLock) Close(context.Context) error {
+	return nil
+}
+
+type fileLease struct {
+	fl *flock.Flock
+}
+
+func (f *fileLease) TTL() time.Duration {
+	return fileLeaseTTL
+}
+
+func (f *fileLease) Renew(context.Context) error {
+	if !f.fl.Locked() {
+		return service.ErrLeaseLost
+	}
+	return nil
+}
+
+func (f *fileLease) Release(context.Context) error {
+	return f.fl.Unlock()
+}
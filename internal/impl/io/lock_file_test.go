@@ -0,0 +1,52 @@
+package io
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func TestFileLock(t *testing.T) {
+	dir := t.TempDir()
+	tCtx := context.Background()
+
+	newLock := func() *fileLock {
+		conf, err := fileLockConfig().ParseYAML(fmt.Sprintf(`directory: %v`, dir), nil)
+		require.NoError(t, err)
+
+		l, err := newFileLockFromConfig(conf)
+		require.NoError(t, err)
+		return l
+	}
+
+	first, second := newLock(), newLock()
+
+	lease, err := first.Acquire(tCtx, "foo")
+	require.NoError(t, err)
+
+	_, err = second.Acquire(tCtx, "foo")
+	assert.Equal(t, service.ErrLockHeld, err)
+
+	otherLease, err := second.Acquire(tCtx, "bar")
+	require.NoError(t, err)
+	require.NoError(t, otherLease.Release(tCtx))
+
+	require.NoError(t, lease.Renew(tCtx))
+	require.NoError(t, lease.Release(tCtx))
+	assert.Equal(t, service.ErrLeaseLost, lease.Renew(tCtx))
+
+	lease, err = second.Acquire(tCtx, "foo")
+	require.NoError(t, err)
+	require.NoError(t, lease.Release(tCtx))
+
+	_, err = first.Acquire(tCtx, "../foo")
+	require.Error(t, err)
+
+	require.NoError(t, first.Close(tCtx))
+	require.NoError(t, second.Close(tCtx))
+}
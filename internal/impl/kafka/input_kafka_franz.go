@@ -156,6 +156,11 @@ With this option, you can return topic order and per-topic partition ordering. T
 			Description("An optional [`rate_limit`](/docs/components/rate_limits/about) to throttle invocations by.").
 			Default("").
 			Advanced()).
+		Field(service.NewInterpolatedStringField("rate_limit_key").
+			Description("An optional key to access the `rate_limit` with, where each key is limited independently. The key is resolved for each message of a batch and the batch waits for access to each distinct key. The target rate limit must support keys, such as a `local` or `redis` rate limit with a `rate` configured.").
+			Example("${! @kafka_key }").
+			Optional().
+			Advanced()).
 		LintRule(`
 let has_topic_partitions = this.topics.any(t -> t.contains(":"))
 root = if $has_topic_partitions {
@@ -214,11 +219,12 @@ type franzKafkaReader struct {
 	preferringLagFn        kgo.PreferLagFn
 	balancers              []kgo.GroupBalancer
 
-	batchChan atomic.Value
-	rateLimit string
-	res       *service.Resources
-	log       *service.Logger
-	shutSig   *shutdown.Signaller
+	batchChan    atomic.Value
+	rateLimit    string
+	rateLimitKey *service.InterpolatedString
+	res          *service.Resources
+	log          *service.Logger
+	shutSig      *shutdown.Signaller
 }
 
 func (f *franzKafkaReader) getBatchChan() chan batchWithAckFn {
@@ -239,17 +245,56 @@ func (f *franzKafkaReader) waitForAccess(ctx context.Context, batch service.Mess
 		return true
 	}
 
+	if f.rateLimitKey == nil {
+		return f.waitForKeyAccess(ctx, batch, "")
+	}
+
+	// Records are added to message aware rate limits only once, alongside the
+	// first key, regardless of how many distinct keys the batch resolves to.
+	pending := batch
+	seen := map[string]struct{}{}
+	for i := range batch {
+		key, err := batch.TryInterpolatedString(i, f.rateLimitKey)
+		if err != nil {
+			f.log.Errorf("Rate limit key error: %v\n", err)
+			continue
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		f.waitForKeyAccess(ctx, pending, key)
+		pending = nil
+	}
+	if len(pending) > 0 {
+		// None of the keys could be resolved.
+		return f.waitForKeyAccess(ctx, pending, "")
+	}
+	return true
+}
+
+func (f *franzKafkaReader) waitForKeyAccess(ctx context.Context, batch service.MessageBatch, key string) bool {
 	for {
 		var period time.Duration
 		var err error
 		if rerr := f.res.AccessRateLimit(ctx, f.rateLimit, func(rl service.RateLimit) {
-			if mar, ok := rl.(service.MessageAwareRateLimit); ok {
+			if mar, ok := rl.(service.MessageAwareRateLimit); ok && len(batch) > 0 {
 				mar.Add(ctx, batch...)
+				batch = nil
+			}
+			if krl, ok := rl.(service.KeyedRateLimit); ok && key != "" {
+				period, err = krl.AccessKey(ctx, key)
+			} else {
+				period, err = rl.Access(ctx)
 			}
-			period, err = rl.Access(ctx)
 		}); rerr != nil {
 			err = rerr
 		}
+		if errors.Is(err, service.ErrRateLimitKeysNotSupported) {
+			f.log.Errorf("Rate limit resource '%v' does not support keys, falling back to accessing it without a key\n", f.rateLimit)
+			key = ""
+			continue
+		}
 		if err != nil {
 			f.log.Errorf("Rate limit error: %v\n", err)
 			period = time.Second
@@ -441,6 +486,20 @@ func newFranzKafkaReaderFromConfig(conf *service.ParsedConfig, res *service.Reso
 	if f.rateLimit, err = conf.FieldString("rate_limit"); err != nil {
 		return nil, err
 	}
+	if conf.Contains("rate_limit_key") {
+		if f.rateLimitKey, err = conf.FieldInterpolatedString("rate_limit_key"); err != nil {
+			return nil, err
+		}
+		if f.rateLimit != "" {
+			keysSupported := true
+			_ = res.AccessRateLimit(context.Background(), f.rateLimit, func(rl service.RateLimit) {
+				keysSupported = service.RateLimitSupportsKeys(rl)
+			})
+			if !keysSupported {
+				return nil, fmt.Errorf("rate limit resource '%v' does not support keys", f.rateLimit)
+			}
+		}
+	}
 
 	tlsConf, tlsEnabled, err := conf.FieldTLSToggled("tls")
 	if err != nil {
@@ -67,6 +67,26 @@ topic: foo
 `,
 			errContains: "seed broker address cannot be empty",
 		},
+		{
+			name: "rate limit",
+			conf: `
+seed_brokers: [ broker_1 ]
+topics: [ test ]
+consumer_group: test
+rate_limit: foo
+`,
+		},
+		{
+			name: "rate limit key without key support",
+			conf: `
+seed_brokers: [ broker_1 ]
+topics: [ test ]
+consumer_group: test
+rate_limit: foo
+rate_limit_key: ${! @kafka_key }
+`,
+			errContains: "rate limit resource 'foo' does not support keys",
+		},
 	}
 
 	for _, test := range testCases {
@@ -75,7 +95,10 @@ topic: foo
 			conf, err := franzKafkaInputConfig().ParseYAML(test.conf, nil)
 			require.NoError(t, err)
 
-			_, err = newFranzKafkaReaderFromConfig(conf, service.MockResources())
+			res := service.MockResources(service.MockResourcesOptAddRateLimit("foo", func(context.Context) (time.Duration, error) {
+				return 0, nil
+			}))
+			_, err = newFranzKafkaReaderFromConfig(conf, res)
 			if test.errContains == "" {
 				require.NoError(t, err)
 			} else {
@@ -86,6 +109,42 @@ topic: foo
 	}
 }
 
+func TestInputKafkaFranzRateLimitKeyFallback(t *testing.T) {
+	var hits int
+	res := service.MockResources(service.MockResourcesOptAddRateLimit("foo", func(context.Context) (time.Duration, error) {
+		hits++
+		return 0, nil
+	}))
+
+	conf, err := franzKafkaInputConfig().ParseYAML(`
+seed_brokers: [ broker_1 ]
+topics: [ test ]
+consumer_group: test
+rate_limit: foo
+`, nil)
+	require.NoError(t, err)
+
+	reader, err := newFranzKafkaReaderFromConfig(conf, res)
+	require.NoError(t, err)
+
+	// A rate limit that loses key support after the input is created is
+	// accessed without a key rather than retried indefinitely.
+	reader.rateLimitKey, err = service.NewInterpolatedString("${! @kafka_key }")
+	require.NoError(t, err)
+
+	var batch service.MessageBatch
+	for _, k := range []string{"a", "b", "a"} {
+		msg := service.NewMessage(nil)
+		msg.MetaSetMut("kafka_key", k)
+		batch = append(batch, msg)
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	require.True(t, reader.waitForAccess(ctx, batch))
+	assert.Equal(t, 2, hits)
+}
+
 func TestInputKafkaFranzRetriableError(t *testing.T) {
 	conf, err := franzKafkaInputConfig().ParseYAML(`
 seed_brokers: [ localhost:9092 ]
@@ -146,6 +146,17 @@ Unfortunately this error message will appear for a wide range of connection prob
 				Description("Decode headers into lists to allow handling of multiple values with the same key").
 				Advanced().Default(false),
 			service.NewBatchPolicyField(iskFieldBatching).Advanced(),
+		).
+		Migration("kafka_franz",
+			service.NewMigrationRename(iskFieldAddresses, "seed_brokers"),
+			service.NewMigrationRemove(iskFieldTargetVersion),
+			service.NewMigrationUnsupported(saramaFieldSASL+"."+saramaFieldSASLMechanism),
+			service.NewMigrationRemove(saramaFieldSASL),
+			service.NewMigrationUnsupported("extract_tracing_map"),
+			service.NewMigrationPreserveDefault(iskFieldCommitPeriod),
+			service.NewMigrationRemove(iskFieldMaxProcessingPeriod),
+			service.NewMigrationRemove(iskFieldGroup),
+			service.NewMigrationRemove(iskFieldFetchBufferCap),
 		)
 }
 
@@ -151,6 +151,33 @@ Unfortunately this error message will appear for a wide range of connection prob
 				MaxInterval:     time.Second * 10,
 				MaxElapsedTime:  time.Second * 30,
 			}).Description("Control time intervals between retry attempts.").Advanced(),
+		).
+		Migration("kafka_franz",
+			service.NewMigrationRename(oskFieldAddresses, "seed_brokers"),
+			service.NewMigrationRemove(oskFieldTargetVersion),
+			service.NewMigrationUnsupported(saramaFieldSASL+"."+saramaFieldSASLMechanism),
+			service.NewMigrationRemove(saramaFieldSASL),
+			service.NewMigrationMapValue(oskFieldPartitioner, "", map[string]any{
+				"murmur2_hash": "murmur2_hash",
+				"round_robin":  "round_robin",
+				"manual":       "manual",
+			}),
+			service.NewMigrationUnsupported(oskFieldCustomTopic+"."+oskFieldCustomTopicEnabled),
+			service.NewMigrationRemove(oskFieldCustomTopic),
+			service.NewMigrationUnsupported(oskFieldStaticHeaders),
+			service.NewMigrationUnsupported(oskFieldMetadata+".exclude_prefixes"),
+			service.NewMigrationRemove(oskFieldMetadata),
+			service.NewMigrationSet(oskFieldMetadata+".include_patterns", []any{".*"}),
+			service.NewMigrationUnsupported("inject_tracing_map"),
+			service.NewMigrationPreserveDefault(oskFieldCompression),
+			service.NewMigrationPreserveDefault("max_in_flight"),
+			service.NewMigrationPreserveDefault(oskFieldIdempotentWrite),
+			service.NewMigrationPreserveDefault(oskFieldTimeout),
+			service.NewMigrationRename(oskFieldMaxMsgBytes, "max_message_bytes"),
+			service.NewMigrationRemove(oskFieldAckReplicas),
+			service.NewMigrationRemove(oskFieldRetryAsBatch),
+			service.NewMigrationRemove(oskFieldMaxRetries),
+			service.NewMigrationRemove(oskFieldBackoff),
 		)
 }
 
@@ -1,8 +1,12 @@
 package nats
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -147,6 +151,107 @@ func (p *kvCache) Delete(ctx context.Context, key string) error {
 	return p.kv.Delete(key)
 }
 
+func (p *kvCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	p.connMut.RLock()
+	defer p.connMut.RUnlock()
+
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		entry, err := p.kv.Get(k)
+		if err != nil {
+			if errors.Is(err, nats.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		res[k] = entry.Value()
+	}
+	return res, nil
+}
+
+func (p *kvCache) Increment(ctx context.Context, key string, delta int64, _ *time.Duration) (int64, error) {
+	p.connMut.RLock()
+	defer p.connMut.RUnlock()
+
+	// Optimistically update the latest revision until no concurrent
+	// modification has occurred in between.
+	for {
+		var revision uint64
+		var current int64
+
+		entry, err := p.kv.Get(key)
+		if err == nil {
+			if current, err = strconv.ParseInt(string(entry.Value()), 10, 64); err != nil {
+				return 0, fmt.Errorf("value is not an integer: %w", err)
+			}
+			revision = entry.Revision()
+		} else if !errors.Is(err, nats.ErrKeyNotFound) {
+			return 0, err
+		}
+
+		current += delta
+		value := strconv.AppendInt(nil, current, 10)
+		if revision == 0 {
+			_, err = p.kv.Create(key, value)
+		} else {
+			_, err = p.kv.Update(key, value, revision)
+		}
+		if err == nil {
+			return current, nil
+		}
+		if !errors.Is(err, nats.ErrKeyExists) {
+			return 0, err
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (p *kvCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, _ *time.Duration) error {
+	p.connMut.RLock()
+	defer p.connMut.RUnlock()
+
+	var err error
+	if old == nil {
+		_, err = p.kv.Create(key, value)
+	} else {
+		var entry nats.KeyValueEntry
+		if entry, err = p.kv.Get(key); err != nil {
+			if errors.Is(err, nats.ErrKeyNotFound) {
+				return service.ErrValueMismatch
+			}
+			return err
+		}
+		if !bytes.Equal(entry.Value(), old) {
+			return service.ErrValueMismatch
+		}
+		_, err = p.kv.Update(key, value, entry.Revision())
+	}
+	if errors.Is(err, nats.ErrKeyExists) {
+		return service.ErrValueMismatch
+	}
+	return err
+}
+
+func (p *kvCache) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	p.connMut.RLock()
+	lister, err := p.kv.ListKeys()
+	p.connMut.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// The lister stops itself once all keys have been consumed.
+	var keys []string
+	for k := range lister.Keys() {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return p.GetMulti(ctx, keys...)
+}
+
 func (p *kvCache) Close(ctx context.Context) error {
 	go func() {
 		p.disconnect()
@@ -104,6 +104,10 @@ cache_resources:
 			integration.CacheTestDoubleAdd(),
 			integration.CacheTestDelete(),
 			integration.CacheTestGetAndSet(50),
+			integration.CacheTestGetMulti(10),
+			integration.CacheTestIncrement(),
+			integration.CacheTestCompareAndSwap(),
+			integration.CacheTestScan(10),
 		)
 		suite.Run(
 			t, template,
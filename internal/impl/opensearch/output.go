@@ -158,6 +158,7 @@ Both the `+"`id` and `index`"+` fields can be dynamically set using function int
 				Default(""),
 			service.NewTLSToggledField(esoFieldTLS),
 			service.NewOutputMaxInFlightField(),
+			service.NewOutputAdaptiveConcurrencyField(),
 		).
 		Fields(
 			service.NewObjectField(esoFieldAuth,
@@ -7,7 +7,9 @@ import (
 
 	"github.com/warpstreamlabs/bento/internal/batch/policy"
 	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/interop"
 	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/transaction"
 	"github.com/warpstreamlabs/bento/public/service"
 )
 
@@ -127,6 +129,12 @@ type memoryBuffer struct {
 
 	batcher *service.Batcher
 
+	// Bytes waiting within the buffer are counted against the memory budget
+	// until they are read, at which point the transaction of the read batch
+	// takes over the reservation.
+	budget   *transaction.Budget
+	budgeted int
+
 	activeBytes    *service.MetricGauge
 	spilloverBytes *service.MetricCounter
 }
@@ -137,6 +145,7 @@ func newMemoryBuffer(capacity int, spilloverEnabled bool, batcher *service.Batch
 		spilloverEnabled: spilloverEnabled,
 		cond:             sync.NewCond(&sync.Mutex{}),
 		batcher:          batcher,
+		budget:           interop.UnwrapManagement(res).MemoryBudget(),
 		activeBytes:      res.Metrics().NewGauge("buffer_active"),
 		spilloverBytes:   res.Metrics().NewCounter("buffer_spillover"),
 	}
@@ -231,6 +240,7 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 		m.cond.Wait()
 	}
 
+	m.budgetAdd(-outSize)
 	m.cond.Broadcast()
 	return outBatch, func(ctx context.Context, err error) error {
 		m.cond.L.Lock()
@@ -240,6 +250,9 @@ func (m *memoryBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 			m.activeBytes.Set(int64(m.bytes))
 		} else {
 			m.batches = append(batchSources, m.batches...)
+			if !m.closed {
+				m.budgetAdd(outSize)
+			}
 		}
 		m.cond.Broadcast()
 		return nil
@@ -292,11 +305,22 @@ func (m *memoryBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageB
 	})
 	m.bytes += extraBytes
 	m.activeBytes.Set(int64(m.bytes))
+	m.budgetAdd(extraBytes)
 
 	m.cond.Broadcast()
 	return nil
 }
 
+// budgetAdd adjusts the bytes of the buffer counted against the memory budget,
+// and must be called whilst holding the lock.
+func (m *memoryBuffer) budgetAdd(n int) {
+	if n < -m.budgeted {
+		n = -m.budgeted
+	}
+	m.budgeted += n
+	m.budget.Add(int64(n))
+}
+
 func (m *memoryBuffer) EndOfInput() {
 	go func() {
 		m.cond.L.Lock()
@@ -316,6 +340,7 @@ func (m *memoryBuffer) EndOfInput() {
 func (m *memoryBuffer) Close(ctx context.Context) error {
 	m.cond.L.Lock()
 	m.closed = true
+	m.budgetAdd(-m.budgeted)
 	m.cond.Broadcast()
 	m.cond.L.Unlock()
 	return nil
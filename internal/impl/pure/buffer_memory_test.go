@@ -14,6 +14,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/transaction"
 	"github.com/warpstreamlabs/bento/public/service"
 )
 
@@ -539,3 +542,40 @@ spillover: true
 	require.Empty(t, m)
 	require.Nil(t, ackFunc)
 }
+
+func TestMemoryBufferBudget(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	budget := transaction.NewBudget(0, nil, metrics.Noop().GetGauge("used"))
+
+	parsedConf, err := memoryBufferConfig().ParseYAML(`limit: 1000`, nil)
+	require.NoError(t, err)
+
+	block, err := newMemoryBufferFromConfig(parsedConf, service.MockResources(func(m *mock.Manager) {
+		m.B = budget
+	}))
+	require.NoError(t, err)
+
+	noopAck := func(ctx context.Context, err error) error { return nil }
+	require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte("foo"))}, noopAck))
+	require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{service.NewMessage([]byte("barbaz"))}, noopAck))
+	assert.Equal(t, int64(9), budget.Used())
+
+	// Batches that are read are no longer counted by the buffer, and are
+	// counted again when rejected.
+	_, ackFn, err := block.ReadBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), budget.Used())
+
+	require.NoError(t, ackFn(ctx, errors.New("nope")))
+	assert.Equal(t, int64(9), budget.Used())
+
+	_, ackFn, err = block.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ackFn(ctx, nil))
+	assert.Equal(t, int64(6), budget.Used())
+
+	require.NoError(t, block.Close(ctx))
+	assert.Equal(t, int64(0), budget.Used())
+}
@@ -29,3 +29,27 @@ cache_resources:
 	)
 	suite.Run(t, template)
 }
+
+func TestIntegrationMemoryCache(t *testing.T) {
+	integration.CheckSkip(t)
+
+	t.Parallel()
+
+	template := `
+cache_resources:
+  - label: testcache
+    memory: {}
+`
+	suite := integration.CacheTests(
+		integration.CacheTestOpenClose(),
+		integration.CacheTestMissingKey(),
+		integration.CacheTestDoubleAdd(),
+		integration.CacheTestDelete(),
+		integration.CacheTestGetAndSet(50),
+		integration.CacheTestGetMulti(10),
+		integration.CacheTestIncrement(),
+		integration.CacheTestCompareAndSwap(),
+		integration.CacheTestScan(10),
+	)
+	suite.Run(t, template)
+}
@@ -1,7 +1,11 @@
 package pure
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -242,3 +246,80 @@ func (m *memoryCache) Delete(_ context.Context, key string) error {
 func (m *memoryCache) Close(context.Context) error {
 	return nil
 }
+
+func (m *memoryCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		v, err := m.Get(ctx, k)
+		if err != nil {
+			continue
+		}
+		res[k] = v
+	}
+	return res, nil
+}
+
+func (m *memoryCache) Increment(_ context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	shard := m.getShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	var current int64
+	k, exists := shard.items[key]
+	if exists && !shard.isExpired(k) {
+		var err error
+		if current, err = strconv.ParseInt(string(k.value), 10, 64); err != nil {
+			return 0, fmt.Errorf("value is not an integer: %w", err)
+		}
+	} else {
+		// The TTL only applies when the counter is created.
+		if ttl != nil {
+			k.expires = time.Now().Add(*ttl)
+		} else {
+			k.expires = time.Now().Add(m.defaultTTL)
+		}
+	}
+	current += delta
+
+	shard.compaction()
+	shard.items[key] = item{value: strconv.AppendInt(nil, current, 10), expires: k.expires}
+	return current, nil
+}
+
+func (m *memoryCache) CompareAndSwap(_ context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	var expires time.Time
+	if ttl != nil {
+		expires = time.Now().Add(*ttl)
+	} else {
+		expires = time.Now().Add(m.defaultTTL)
+	}
+	shard := m.getShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+
+	k, exists := shard.items[key]
+	if exists && shard.isExpired(k) {
+		exists = false
+	}
+	if exists != (old != nil) || (exists && !bytes.Equal(k.value, old)) {
+		return service.ErrValueMismatch
+	}
+
+	shard.compaction()
+	shard.items[key] = item{value: value, expires: expires}
+	return nil
+}
+
+func (m *memoryCache) Scan(_ context.Context, prefix string) (map[string][]byte, error) {
+	res := map[string][]byte{}
+	for _, shard := range m.shards {
+		shard.RLock()
+		for k, v := range shard.items {
+			if strings.HasPrefix(k, prefix) && !shard.isExpired(v) {
+				res[k] = v.value
+			}
+		}
+		shard.RUnlock()
+	}
+	return res, nil
+}
@@ -253,3 +253,77 @@ shards: 10
 		assert.Equal(b, value, res)
 	}
 }
+
+func TestMemoryCacheOptionalOperations(t *testing.T) {
+	defConf, err := memCacheConfig().ParseYAML(`
+shards: 4
+init_values:
+  foo: bar
+  foz: baz
+  bar: buz
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	res, err := c.GetMulti(ctx, "foo", "bar", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("bar"), "bar": []byte("buz")}, res)
+
+	res, err = c.Scan(ctx, "fo")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("bar"), "foz": []byte("baz")}, res)
+
+	v, err := c.Increment(ctx, "counter", 5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), v)
+
+	v, err = c.Increment(ctx, "counter", -7, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-2), v)
+
+	_, err = c.Increment(ctx, "foo", 1, nil)
+	require.Error(t, err)
+
+	require.ErrorIs(t, c.CompareAndSwap(ctx, "foo", []byte("nope"), []byte("qux"), nil), service.ErrValueMismatch)
+	require.ErrorIs(t, c.CompareAndSwap(ctx, "foo", nil, []byte("qux"), nil), service.ErrValueMismatch)
+	require.ErrorIs(t, c.CompareAndSwap(ctx, "new", []byte("bar"), []byte("qux"), nil), service.ErrValueMismatch)
+
+	require.NoError(t, c.CompareAndSwap(ctx, "foo", []byte("bar"), []byte("qux"), nil))
+	require.NoError(t, c.CompareAndSwap(ctx, "new", nil, []byte("quz"), nil))
+
+	res, err = c.GetMulti(ctx, "foo", "new")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("qux"), "new": []byte("quz")}, res)
+}
+
+func TestMemoryCacheIncrementTTL(t *testing.T) {
+	defConf, err := memCacheConfig().ParseYAML(`
+compaction_interval: 1ms
+`, nil)
+	require.NoError(t, err)
+
+	c, err := newMemCacheFromConfig(defConf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	ttl := time.Millisecond * 50
+	_, err = c.Increment(ctx, "counter", 1, &ttl)
+	require.NoError(t, err)
+
+	// Incrementing an existing counter does not extend its TTL.
+	longTTL := time.Hour
+	v, err := c.Increment(ctx, "counter", 1, &longTTL)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	<-time.After(time.Millisecond * 100)
+
+	v, err = c.Increment(ctx, "counter", 1, &ttl)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+}
@@ -2,6 +2,7 @@ package pure
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/warpstreamlabs/bento/internal/component/input"
 	"github.com/warpstreamlabs/bento/internal/component/input/batcher"
@@ -16,6 +17,15 @@ const (
 	ibFieldCopies   = "copies"
 	ibFieldInputs   = "inputs"
 	ibFieldBatching = "batching"
+	ibFieldFanIn    = "fan_in"
+
+	ibFanInFieldStrategy          = "strategy"
+	ibFanInFieldStarvationTimeout = "starvation_timeout"
+	ibFanInFieldQueueSize         = "queue_size"
+	ibFanInFieldInputs            = "inputs"
+	ibFanInFieldPriority          = "priority"
+	ibFanInFieldWeight            = "weight"
+	ibFanInFieldRateCap           = "rate_cap"
 )
 
 func brokerInputSpec() *service.ConfigSpec {
@@ -62,7 +72,41 @@ It's possible to configure a [batch policy](/docs/configuration/batching#batch-p
 
 ### Processors
 
-It is possible to configure [processors](/docs/components/processors/about) at the broker level, where they will be applied to _all_ child inputs, as well as on the individual child inputs. If you have processors at both the broker level _and_ on child inputs then the broker processors will be applied _after_ the child nodes processors.`).
+It is possible to configure [processors](/docs/components/processors/about) at the broker level, where they will be applied to _all_ child inputs, as well as on the individual child inputs. If you have processors at both the broker level _and_ on child inputs then the broker processors will be applied _after_ the child nodes processors.
+
+### Fan In Strategies
+
+By default messages are consumed from whichever child input is ready first, which means a large backlog on one input can delay messages from the others. The `+"`fan_in`"+` fields allow you to choose which child is read from next when more than one has messages pending:
+
+- `+"`priority`"+`: Children with a higher `+"`priority`"+` are always read from first. A `+"`starvation_timeout`"+` can be set in order to consume messages from lower priority children once they have been waiting that long.
+- `+"`weighted`"+`: Children are read from in proportion to their `+"`weight`"+` using weighted fair queuing, so that a child with a weight of 3 receives three times the share of a child with a weight of 1 whilst both have messages pending.
+
+Each child may also be given a `+"`rate_cap`"+`, limiting the number of messages (or batches) per second consumed from it regardless of the strategy. The options of `+"`fan_in.inputs`"+` are matched to `+"`inputs`"+` by their index, and are repeated for each copy of the inputs.
+
+For example, prioritising a low-latency control topic over a bulk topic:
+
+`+"```yaml"+`
+input:
+  broker:
+    inputs:
+      - kafka_franz:
+          seed_brokers: [ localhost:9092 ]
+          topics: [ control ]
+          consumer_group: bento
+      - kafka_franz:
+          seed_brokers: [ localhost:9092 ]
+          topics: [ bulk ]
+          consumer_group: bento
+    fan_in:
+      strategy: priority
+      starvation_timeout: 5s
+      inputs:
+        - priority: 1
+        - priority: 0
+          rate_cap: 1000
+`+"```"+`
+
+When a strategy or a rate cap is set the number of messages waiting to be consumed from each child is exported as the gauge `+"`input_broker_queue_depth`"+`, labelled by the index of the child.`).
 		Fields(
 			service.NewIntField(ibFieldCopies).
 				Description("Whatever is specified within `inputs` will be created this many times.").
@@ -71,6 +115,34 @@ It is possible to configure [processors](/docs/components/processors/about) at t
 			service.NewInputListField(ibFieldInputs).
 				Description("A list of inputs to create."),
 			service.NewBatchPolicyField("batching"),
+			service.NewObjectField(ibFieldFanIn,
+				service.NewStringEnumField(ibFanInFieldStrategy, fanInStrategyNone, fanInStrategyPriority, fanInStrategyWeighted).
+					Description("The strategy used to choose which child input is consumed from next. When `none` messages are consumed from whichever child is ready first.").
+					Default(fanInStrategyNone),
+				service.NewDurationField(ibFanInFieldStarvationTimeout).
+					Description("When using the `priority` strategy, messages of lower priority children that have been waiting for at least this long are consumed regardless of their priority. Leave empty to disable.").
+					Example("5s").
+					Optional(),
+				service.NewIntField(ibFanInFieldQueueSize).
+					Description("The maximum number of messages held pending from each child input whilst waiting to be consumed.").
+					Advanced().
+					Default(1),
+				service.NewObjectListField(ibFanInFieldInputs,
+					service.NewIntField(ibFanInFieldPriority).
+						Description("The priority of the child when using the `priority` strategy, where higher values are consumed first.").
+						Default(0),
+					service.NewIntField(ibFanInFieldWeight).
+						Description("The share of the child when using the `weighted` strategy.").
+						Default(1),
+					service.NewFloatField(ibFanInFieldRateCap).
+						Description("The maximum number of messages per second consumed from the child, where zero means unlimited.").
+						Default(0.0),
+				).
+					Description("Options for each child input, matched to `inputs` by index.").
+					Default([]any{}),
+			).
+				Description("Controls how messages from the child inputs are merged.").
+				Advanced(),
 		)
 }
 
@@ -103,8 +175,16 @@ func newBrokerInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources
 		return nil, ErrBrokerNoInputs
 	}
 
+	fanInConf, err := fanInConfigFromParsed(conf.Namespace(ibFieldFanIn))
+	if err != nil {
+		return nil, err
+	}
+	if len(fanInConf.Children) > len(children) {
+		return nil, fmt.Errorf("field %v.%v has %v entries but only %v inputs are configured", ibFieldFanIn, ibFanInFieldInputs, len(fanInConf.Children), len(children))
+	}
+
 	var b input.Streamed
-	if len(children) == 1 && copies == 1 {
+	if len(children) == 1 && copies == 1 && !fanInConf.scheduled() {
 		b = interop.UnwrapOwnedInput(children[0])
 	} else {
 		var inputs []input.Streamed
@@ -120,8 +200,28 @@ func newBrokerInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources
 				inputs = append(inputs, interop.UnwrapOwnedInput(v))
 			}
 		}
-		if b, err = newFanInInputBroker(inputs); err != nil {
-			return nil, err
+		if !fanInConf.scheduled() {
+			if b, err = newFanInInputBroker(inputs); err != nil {
+				return nil, err
+			}
+		} else {
+			// Child options are matched by index and repeated for each copy.
+			childConfs := make([]fanInChildConfig, 0, len(inputs))
+			for j := 0; j < copies; j++ {
+				for k := range children {
+					cConf := fanInChildConfig{Weight: 1}
+					if k < len(fanInConf.Children) {
+						cConf = fanInConf.Children[k]
+					}
+					childConfs = append(childConfs, cConf)
+				}
+			}
+			fanInConf.Children = childConfs
+
+			stats := interop.UnwrapManagement(mgr).Metrics()
+			if b, err = newScheduledFanInInputBroker(fanInConf, stats, inputs); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -142,3 +242,48 @@ func newBrokerInputFromParsed(conf *service.ParsedConfig, mgr *service.Resources
 	iBatcher := interop.UnwrapBatcher(pubBatcher)
 	return batcher.New(iBatcher, b, interop.UnwrapManagement(mgr).Logger()), nil
 }
+
+func fanInConfigFromParsed(conf *service.ParsedConfig) (fConf fanInConfig, err error) {
+	if fConf.Strategy, err = conf.FieldString(ibFanInFieldStrategy); err != nil {
+		return
+	}
+	if conf.Contains(ibFanInFieldStarvationTimeout) {
+		if fConf.StarvationTimeout, err = conf.FieldDuration(ibFanInFieldStarvationTimeout); err != nil {
+			return
+		}
+	}
+	if fConf.QueueSize, err = conf.FieldInt(ibFanInFieldQueueSize); err != nil {
+		return
+	}
+	if fConf.QueueSize < 1 {
+		err = fmt.Errorf("field %v.%v must be greater than zero", ibFieldFanIn, ibFanInFieldQueueSize)
+		return
+	}
+
+	var childConfs []*service.ParsedConfig
+	if childConfs, err = conf.FieldObjectList(ibFanInFieldInputs); err != nil {
+		return
+	}
+	for _, cConf := range childConfs {
+		var child fanInChildConfig
+		if child.Priority, err = cConf.FieldInt(ibFanInFieldPriority); err != nil {
+			return
+		}
+		if child.Weight, err = cConf.FieldInt(ibFanInFieldWeight); err != nil {
+			return
+		}
+		if child.Weight < 1 {
+			err = fmt.Errorf("field %v.%v.%v must be greater than zero", ibFieldFanIn, ibFanInFieldInputs, ibFanInFieldWeight)
+			return
+		}
+		if child.RateCap, err = cConf.FieldFloat(ibFanInFieldRateCap); err != nil {
+			return
+		}
+		if child.RateCap < 0 {
+			err = fmt.Errorf("field %v.%v.%v must not be negative", ibFieldFanIn, ibFanInFieldInputs, ibFanInFieldRateCap)
+			return
+		}
+		fConf.Children = append(fConf.Children, child)
+	}
+	return
+}
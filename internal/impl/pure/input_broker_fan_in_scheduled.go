@@ -0,0 +1,381 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+const (
+	fanInStrategyNone     = "none"
+	fanInStrategyPriority = "priority"
+	fanInStrategyWeighted = "weighted"
+)
+
+// fanInChildConfig describes how a child input of a scheduled fan in broker is
+// read relative to the others.
+type fanInChildConfig struct {
+	Priority int
+	Weight   int
+	RateCap  float64
+}
+
+type fanInConfig struct {
+	Strategy          string
+	StarvationTimeout time.Duration
+	QueueSize         int
+
+	// Children is indexed the same as the inputs of the broker, any inputs
+	// beyond its length use the default child config.
+	Children []fanInChildConfig
+}
+
+// scheduled returns true if the children require scheduling, either because a
+// strategy is set or because a child has a rate cap.
+func (c fanInConfig) scheduled() bool {
+	if c.Strategy != fanInStrategyNone {
+		return true
+	}
+	for _, child := range c.Children {
+		if child.RateCap > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type queuedTransaction struct {
+	tran       message.Transaction
+	enqueuedAt time.Time
+}
+
+type scheduledFanInChild struct {
+	conf  fanInChildConfig
+	queue chan queuedTransaction
+	depth int64
+
+	head   *queuedTransaction
+	closed bool
+
+	// Virtual start and finish tags used for weighted fair queuing, the start
+	// tag belongs to the current head.
+	start  float64
+	finish float64
+
+	// The earliest time at which this child may be dispatched from again
+	// according to its rate cap.
+	nextAllowed time.Time
+
+	mDepth metrics.StatGauge
+}
+
+// scheduledFanInInputBroker merges child inputs into a single stream, where
+// the child that is read from next is chosen according to a scheduling
+// strategy rather than whichever child happens to be ready first.
+type scheduledFanInInputBroker struct {
+	conf         fanInConfig
+	transactions chan message.Transaction
+
+	closables []input.Streamed
+	children  []*scheduledFanInChild
+	notify    chan struct{}
+
+	// The virtual time of the system, which is the start tag of the most
+	// recently dispatched transaction.
+	vclock float64
+
+	remainingMap map[int]struct{}
+	remainingMut sync.Mutex
+
+	now     func() time.Time
+	shutSig *shutdown.Signaller
+}
+
+func newScheduledFanInInputBroker(conf fanInConfig, stats metrics.Type, inputs []input.Streamed) (*scheduledFanInInputBroker, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("fan in broker requires at least one input")
+	}
+	mDepth := stats.GetGaugeVec("input_broker_queue_depth", "child")
+
+	i := &scheduledFanInInputBroker{
+		conf:         conf,
+		transactions: make(chan message.Transaction),
+		notify:       make(chan struct{}, 1),
+		remainingMap: map[int]struct{}{},
+		now:          time.Now,
+		shutSig:      shutdown.NewSignaller(),
+	}
+
+	for n, in := range inputs {
+		childConf := fanInChildConfig{Weight: 1}
+		if n < len(conf.Children) {
+			childConf = conf.Children[n]
+		}
+		i.closables = append(i.closables, in)
+		i.remainingMap[n] = struct{}{}
+		i.children = append(i.children, &scheduledFanInChild{
+			conf:   childConf,
+			queue:  make(chan queuedTransaction, conf.QueueSize),
+			mDepth: mDepth.With(strconv.Itoa(n)),
+		})
+	}
+
+	for n, in := range inputs {
+		go i.readChild(n, in)
+	}
+	go i.loop()
+	return i, nil
+}
+
+func (i *scheduledFanInInputBroker) readChild(index int, in input.Streamed) {
+	child := i.children[index]
+	defer func() {
+		i.remainingMut.Lock()
+		delete(i.remainingMap, index)
+		i.remainingMut.Unlock()
+
+		close(child.queue)
+		i.wake()
+	}()
+
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-in.TransactionChan():
+			if !open {
+				return
+			}
+		case <-i.shutSig.HardStopChan():
+			return
+		}
+
+		child.mDepth.Set(atomic.AddInt64(&child.depth, 1))
+		select {
+		case child.queue <- queuedTransaction{tran: tran, enqueuedAt: i.now()}:
+		case <-i.shutSig.HardStopChan():
+			return
+		}
+		i.wake()
+	}
+}
+
+func (i *scheduledFanInInputBroker) wake() {
+	select {
+	case i.notify <- struct{}{}:
+	default:
+	}
+}
+
+// fillHeads pulls the next pending transaction of each child that isn't
+// already holding one, returning false once all children are closed and
+// drained.
+func (i *scheduledFanInInputBroker) fillHeads() bool {
+	active := false
+	for _, c := range i.children {
+		if c.head == nil && !c.closed {
+			select {
+			case qt, open := <-c.queue:
+				if !open {
+					c.closed = true
+				} else {
+					i.setHead(c, &qt)
+				}
+			default:
+			}
+		}
+		if c.head != nil || !c.closed {
+			active = true
+		}
+	}
+	return active
+}
+
+func (i *scheduledFanInInputBroker) setHead(c *scheduledFanInChild, qt *queuedTransaction) {
+	c.head = qt
+
+	// Children that were idle start from the current virtual time, otherwise
+	// they would be owed a burst of transactions.
+	c.start = c.finish
+	if i.vclock > c.start {
+		c.start = i.vclock
+	}
+}
+
+// pick chooses the child to dispatch from next, or returns -1 along with the
+// duration until a rate capped child becomes available.
+func (i *scheduledFanInInputBroker) pick(now time.Time) (int, time.Duration) {
+	chosen := -1
+	var wait time.Duration
+	for n, c := range i.children {
+		if c.head == nil {
+			continue
+		}
+		if now.Before(c.nextAllowed) {
+			if w := c.nextAllowed.Sub(now); wait == 0 || w < wait {
+				wait = w
+			}
+			continue
+		}
+		if chosen == -1 {
+			chosen = n
+			continue
+		}
+		best := i.children[chosen]
+		switch i.conf.Strategy {
+		case fanInStrategyPriority:
+			if c.conf.Priority > best.conf.Priority {
+				chosen = n
+			}
+		case fanInStrategyWeighted:
+			if c.start+1/float64(c.conf.Weight) < best.start+1/float64(best.conf.Weight) {
+				chosen = n
+			}
+		default:
+			if c.head.enqueuedAt.Before(best.head.enqueuedAt) {
+				chosen = n
+			}
+		}
+	}
+	if chosen == -1 {
+		return -1, wait
+	}
+
+	// Protect lower priority children from starvation by choosing the
+	// longest waiting transaction once it has exceeded the timeout.
+	if i.conf.Strategy == fanInStrategyPriority && i.conf.StarvationTimeout > 0 {
+		for n, c := range i.children {
+			if c.head == nil || now.Before(c.nextAllowed) {
+				continue
+			}
+			if now.Sub(c.head.enqueuedAt) < i.conf.StarvationTimeout {
+				continue
+			}
+			if !i.children[chosen].head.enqueuedAt.Before(c.head.enqueuedAt) {
+				chosen = n
+			}
+		}
+	}
+	return chosen, 0
+}
+
+func (i *scheduledFanInInputBroker) dispatched(index int, now time.Time) {
+	c := i.children[index]
+	c.head = nil
+	c.mDepth.Set(atomic.AddInt64(&c.depth, -1))
+
+	if c.conf.RateCap > 0 {
+		c.nextAllowed = now.Add(time.Duration(float64(time.Second) / c.conf.RateCap))
+	}
+
+	if c.start > i.vclock {
+		i.vclock = c.start
+	}
+	c.finish = c.start + 1/float64(c.conf.Weight)
+}
+
+func (i *scheduledFanInInputBroker) loop() {
+	defer func() {
+		close(i.transactions)
+		i.shutSig.TriggerHasStopped()
+	}()
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		if !i.fillHeads() {
+			return
+		}
+
+		now := i.now()
+		chosen, wait := i.pick(now)
+
+		var outChan chan message.Transaction
+		var next message.Transaction
+		if chosen >= 0 {
+			outChan = i.transactions
+			next = i.children[chosen].head.tran
+		}
+
+		var timerChan <-chan time.Time
+		if chosen < 0 && wait > 0 {
+			if timer == nil {
+				timer = time.NewTimer(wait)
+			} else {
+				timer.Reset(wait)
+			}
+			timerChan = timer.C
+		}
+
+		select {
+		case outChan <- next:
+			i.dispatched(chosen, i.now())
+		case <-i.notify:
+		case <-timerChan:
+		case <-i.shutSig.HardStopChan():
+			return
+		}
+		if timerChan != nil && !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+}
+
+func (i *scheduledFanInInputBroker) TransactionChan() <-chan message.Transaction {
+	return i.transactions
+}
+
+func (i *scheduledFanInInputBroker) ConnectionStatus() component.ConnectionStatuses {
+	i.remainingMut.Lock()
+	defer i.remainingMut.Unlock()
+
+	if len(i.remainingMap) == 0 {
+		return nil
+	}
+
+	var statuses component.ConnectionStatuses
+	for index := range i.remainingMap {
+		statuses = append(statuses, i.closables[index].ConnectionStatus()...)
+	}
+	return statuses
+}
+
+func (i *scheduledFanInInputBroker) TriggerStopConsuming() {
+	for _, closable := range i.closables {
+		closable.TriggerStopConsuming()
+	}
+}
+
+func (i *scheduledFanInInputBroker) TriggerCloseNow() {
+	for _, closable := range i.closables {
+		closable.TriggerCloseNow()
+	}
+	i.shutSig.TriggerHardStop()
+}
+
+func (i *scheduledFanInInputBroker) WaitForClose(ctx context.Context) error {
+	select {
+	case <-i.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package pure
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+var _ input.Streamed = &scheduledFanInInputBroker{}
+
+func testScheduler(conf fanInConfig) *scheduledFanInInputBroker {
+	i := &scheduledFanInInputBroker{conf: conf}
+	for _, c := range conf.Children {
+		i.children = append(i.children, &scheduledFanInChild{
+			conf:   c,
+			mDepth: metrics.Noop().GetGauge("foo"),
+		})
+	}
+	return i
+}
+
+func fillScheduler(i *scheduledFanInInputBroker, enqueuedAt time.Time) {
+	for _, c := range i.children {
+		if c.head == nil {
+			i.setHead(c, &queuedTransaction{enqueuedAt: enqueuedAt})
+		}
+	}
+}
+
+func TestScheduledFanInPriority(t *testing.T) {
+	now := time.Now()
+	i := testScheduler(fanInConfig{
+		Strategy:          fanInStrategyPriority,
+		StarvationTimeout: time.Second,
+		Children: []fanInChildConfig{
+			{Priority: 0, Weight: 1},
+			{Priority: 2, Weight: 1},
+			{Priority: 1, Weight: 1},
+		},
+	})
+
+	// The highest priority child always wins whilst it has pending messages.
+	for n := 0; n < 5; n++ {
+		fillScheduler(i, now)
+		chosen, _ := i.pick(now)
+		require.Equal(t, 1, chosen)
+		i.dispatched(chosen, now)
+	}
+
+	i.children[1].head = nil
+	chosen, _ := i.pick(now)
+	require.Equal(t, 2, chosen)
+
+	// Once a message has waited beyond the starvation timeout it is consumed
+	// regardless of priority.
+	i.children[1].head = &queuedTransaction{enqueuedAt: now}
+	i.children[0].head = &queuedTransaction{enqueuedAt: now.Add(-time.Second * 2)}
+	chosen, _ = i.pick(now)
+	assert.Equal(t, 0, chosen)
+}
+
+func TestScheduledFanInWeighted(t *testing.T) {
+	now := time.Now()
+	i := testScheduler(fanInConfig{
+		Strategy: fanInStrategyWeighted,
+		Children: []fanInChildConfig{
+			{Weight: 3},
+			{Weight: 1},
+		},
+	})
+
+	counts := map[int]int{}
+	for n := 0; n < 40; n++ {
+		fillScheduler(i, now)
+		chosen, _ := i.pick(now)
+		require.GreaterOrEqual(t, chosen, 0)
+		counts[chosen]++
+		i.dispatched(chosen, now)
+	}
+	assert.Equal(t, map[int]int{0: 30, 1: 10}, counts)
+
+	// A child that was idle does not receive a burst once it has messages
+	// again.
+	i.children[1].head = nil
+	for n := 0; n < 20; n++ {
+		if i.children[0].head == nil {
+			i.setHead(i.children[0], &queuedTransaction{enqueuedAt: now})
+		}
+		chosen, _ := i.pick(now)
+		require.Equal(t, 0, chosen)
+		i.dispatched(chosen, now)
+	}
+
+	counts = map[int]int{}
+	for n := 0; n < 8; n++ {
+		fillScheduler(i, now)
+		chosen, _ := i.pick(now)
+		counts[chosen]++
+		i.dispatched(chosen, now)
+	}
+	assert.Equal(t, map[int]int{0: 6, 1: 2}, counts)
+}
+
+func TestScheduledFanInRateCap(t *testing.T) {
+	now := time.Now()
+	i := testScheduler(fanInConfig{
+		Strategy: fanInStrategyPriority,
+		Children: []fanInChildConfig{
+			{Priority: 1, Weight: 1, RateCap: 10},
+			{Priority: 0, Weight: 1},
+		},
+	})
+
+	fillScheduler(i, now)
+	chosen, _ := i.pick(now)
+	require.Equal(t, 0, chosen)
+	i.dispatched(chosen, now)
+
+	// The capped child is skipped until its interval has passed.
+	fillScheduler(i, now)
+	chosen, _ = i.pick(now)
+	require.Equal(t, 1, chosen)
+	i.dispatched(chosen, now)
+
+	i.children[1].head = nil
+	chosen, wait := i.pick(now.Add(time.Millisecond * 40))
+	require.Equal(t, -1, chosen)
+	assert.Equal(t, time.Millisecond*60, wait)
+
+	chosen, _ = i.pick(now.Add(time.Millisecond * 100))
+	assert.Equal(t, 0, chosen)
+}
+
+func TestScheduledFanInBrokerWeighted(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	var heavyBatches, lightBatches []message.Batch
+	for n := 0; n < 200; n++ {
+		heavyBatches = append(heavyBatches, message.QuickBatch([][]byte{[]byte("heavy")}))
+		lightBatches = append(lightBatches, message.QuickBatch([][]byte{[]byte("light")}))
+	}
+
+	stats := metrics.NewLocal()
+	fanIn, err := newScheduledFanInInputBroker(fanInConfig{
+		Strategy:  fanInStrategyWeighted,
+		QueueSize: 200,
+		Children: []fanInChildConfig{
+			{Weight: 3},
+			{Weight: 1},
+		},
+	}, stats, []input.Streamed{
+		mock.NewInput(heavyBatches),
+		mock.NewInput(lightBatches),
+	})
+	require.NoError(t, err)
+
+	// Wait for both children to be queued before consuming.
+	require.Eventually(t, func() bool {
+		counters := stats.GetCounters()
+		return counters[`input_broker_queue_depth{child="0"}`] == 200 &&
+			counters[`input_broker_queue_depth{child="1"}`] == 200
+	}, time.Second*5, time.Millisecond)
+
+	var results []string
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-fanIn.TransactionChan():
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		if !open {
+			break
+		}
+		results = append(results, string(tran.Payload.Get(0).AsBytes()))
+		require.NoError(t, tran.Ack(ctx, nil))
+	}
+	require.Len(t, results, 400)
+
+	// Whilst both children have messages pending the heavy child receives
+	// three times the share of the light child. The first message may have
+	// been chosen before the light child was queued.
+	counts := map[string]int{}
+	for _, r := range results[:200] {
+		counts[r]++
+	}
+	assert.InDelta(t, 150, counts["heavy"], 1)
+	assert.InDelta(t, 50, counts["light"], 1)
+
+	require.NoError(t, fanIn.WaitForClose(ctx))
+}
+
+func TestScheduledFanInBroker(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	var lowBatches, highBatches []message.Batch
+	for n := 0; n < 5; n++ {
+		lowBatches = append(lowBatches, message.QuickBatch([][]byte{[]byte(fmt.Sprintf("low %v", n))}))
+		highBatches = append(highBatches, message.QuickBatch([][]byte{[]byte(fmt.Sprintf("high %v", n))}))
+	}
+
+	stats := metrics.NewLocal()
+	fanIn, err := newScheduledFanInInputBroker(fanInConfig{
+		Strategy:  fanInStrategyPriority,
+		QueueSize: 10,
+		Children: []fanInChildConfig{
+			{Priority: 0, Weight: 1},
+			{Priority: 1, Weight: 1},
+		},
+	}, stats, []input.Streamed{
+		mock.NewInput(lowBatches),
+		mock.NewInput(highBatches),
+	})
+	require.NoError(t, err)
+
+	// Wait for both children to be queued before consuming.
+	require.Eventually(t, func() bool {
+		counters := stats.GetCounters()
+		return counters[`input_broker_queue_depth{child="0"}`] == 5 &&
+			counters[`input_broker_queue_depth{child="1"}`] == 5
+	}, time.Second*5, time.Millisecond)
+
+	var results []string
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-fanIn.TransactionChan():
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		if !open {
+			break
+		}
+		results = append(results, string(tran.Payload.Get(0).AsBytes()))
+		require.NoError(t, tran.Ack(ctx, nil))
+	}
+
+	// The first message may have been chosen before the high priority child
+	// was queued, after which all high priority messages come first.
+	require.Len(t, results, 10)
+	assert.Equal(t, []string{"low 1", "low 2", "low 3", "low 4"}, results[6:])
+
+	counters := stats.GetCounters()
+	assert.Equal(t, int64(0), counters[`input_broker_queue_depth{child="0"}`])
+	assert.Equal(t, int64(0), counters[`input_broker_queue_depth{child="1"}`])
+
+	require.NoError(t, fanIn.WaitForClose(ctx))
+}
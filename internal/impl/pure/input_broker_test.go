@@ -2,6 +2,7 @@ package pure_test
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -98,6 +99,56 @@ processors:
 				"meow HELLO WORLD 1\nHELLO WORLD 1\nHELLO WORLD 1 woof": 1,
 			},
 		},
+		{
+			name: "priority fan in",
+			config: `
+broker:
+  inputs:
+    - generate:
+        count: 2
+        interval: ""
+        mapping: 'root = "hello world 1"'
+    - generate:
+        count: 2
+        interval: ""
+        mapping: 'root = "hello world 2"'
+  fan_in:
+    strategy: priority
+    starvation_timeout: 1s
+    inputs:
+      - priority: 1
+`,
+			output: map[string]int{
+				"hello world 1": 2,
+				"hello world 2": 2,
+			},
+		},
+		{
+			name: "weighted fan in with copies",
+			config: `
+broker:
+  copies: 2
+  inputs:
+    - generate:
+        count: 1
+        interval: ""
+        mapping: 'root = "hello world 1"'
+    - generate:
+        count: 1
+        interval: ""
+        mapping: 'root = "hello world 2"'
+  fan_in:
+    strategy: weighted
+    inputs:
+      - weight: 3
+      - weight: 1
+        rate_cap: 100
+`,
+			output: map[string]int{
+				"hello world 1": 2,
+				"hello world 2": 2,
+			},
+		},
 	} {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
@@ -123,3 +174,95 @@ processors:
 		})
 	}
 }
+
+func TestBrokerRateCapWithoutStrategy(t *testing.T) {
+	builder := service.NewEnvironment().NewStreamBuilder()
+	require.NoError(t, builder.AddInputYAML(`
+broker:
+  inputs:
+    - generate:
+        mapping: 'root = "hello world"'
+        interval: ""
+  fan_in:
+    inputs:
+      - rate_cap: 20
+`))
+	require.NoError(t, builder.SetLoggerYAML(`level: none`))
+
+	var consumed int64
+	require.NoError(t, builder.AddConsumerFunc(func(ctx context.Context, msg *service.Message) error {
+		atomic.AddInt64(&consumed, 1)
+		return nil
+	}))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer done()
+
+	require.ErrorIs(t, strm.Run(tCtx), context.DeadlineExceeded)
+
+	// The rate cap applies even though no strategy is set.
+	n := atomic.LoadInt64(&consumed)
+	assert.Greater(t, n, int64(0))
+	assert.LessOrEqual(t, n, int64(12))
+}
+
+func TestBrokerFanInConfigErrs(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		config      string
+		errContains string
+	}{
+		{
+			name: "too many fan in inputs",
+			config: `
+broker:
+  inputs:
+    - generate:
+        mapping: 'root = "hello world"'
+  fan_in:
+    strategy: priority
+    inputs:
+      - priority: 1
+      - priority: 2
+`,
+			errContains: "has 2 entries but only 1 inputs",
+		},
+		{
+			name: "zero weight",
+			config: `
+broker:
+  inputs:
+    - generate:
+        mapping: 'root = "hello world"'
+  fan_in:
+    strategy: weighted
+    inputs:
+      - weight: 0
+`,
+			errContains: "weight must be greater than zero",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			builder := service.NewEnvironment().NewStreamBuilder()
+			require.NoError(t, builder.AddInputYAML(test.config))
+			require.NoError(t, builder.SetLoggerYAML(`level: none`))
+			require.NoError(t, builder.AddConsumerFunc(func(ctx context.Context, msg *service.Message) error {
+				return nil
+			}))
+
+			strm, err := builder.Build()
+			require.NoError(t, err)
+
+			tCtx, done := context.WithTimeout(context.Background(), time.Minute)
+			defer done()
+
+			err = strm.Run(tCtx)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errContains)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package pure_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	bmock "github.com/warpstreamlabs/bento/internal/manager/mock"
+)
+
+func TestLeaderOnlyErrs(t *testing.T) {
+	conf, err := testutil.InputFromYAML(`
+leader_only:
+  lock: nope
+  name: foo
+  input:
+    stdin: {}
+`)
+	require.NoError(t, err)
+
+	_, err = bmock.NewManager().NewInput(conf)
+	assert.EqualError(t, err, "failed to init input <no label>: lock resource 'nope' was not found")
+}
+
+func TestLeaderOnlyFailover(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	lock := bmock.NewLock(time.Millisecond * 300)
+	mgr := bmock.NewManager()
+	mgr.Locks["foo"] = lock
+
+	newInput := func(content string) input.Streamed {
+		conf, err := testutil.InputFromYAML(fmt.Sprintf(`
+leader_only:
+  lock: foo
+  name: bar
+  retry_period: 10ms
+  input:
+    generate:
+      interval: 1ms
+      mapping: 'root = "%v"'
+`, content))
+		require.NoError(t, err)
+
+		in, err := mgr.NewInput(conf)
+		require.NoError(t, err)
+		return in
+	}
+
+	readContent := func(in input.Streamed) string {
+		t.Helper()
+		select {
+		case tran, open := <-in.TransactionChan():
+			require.True(t, open)
+			require.NoError(t, tran.Ack(ctx, nil))
+			return string(tran.Payload.Get(0).AsBytes())
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		return ""
+	}
+
+	first := newInput("first")
+	assert.Equal(t, "first", readContent(first))
+	assert.True(t, lock.Held("bar"))
+
+	// The second input remains on standby whilst the first holds the lease.
+	second := newInput("second")
+	select {
+	case <-second.TransactionChan():
+		t.Fatal("unexpected message from an input on standby")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// Shutting down the holder releases the lease for the second input.
+	first.TriggerStopConsuming()
+	require.NoError(t, first.WaitForClose(ctx))
+	assert.Equal(t, "second", readContent(second))
+
+	// Losing the lease closes the child input until it is reacquired.
+	lock.Revoke("bar")
+	held, err := lock.Acquire(ctx, "bar")
+	require.NoError(t, err)
+
+drainLoop:
+	for {
+		select {
+		case tran := <-second.TransactionChan():
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-time.After(time.Millisecond * 200):
+			break drainLoop
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+
+	require.NoError(t, held.Release(ctx))
+	assert.Equal(t, "second", readContent(second))
+
+	second.TriggerStopConsuming()
+	require.NoError(t, second.WaitForClose(ctx))
+	assert.False(t, lock.Held("bar"))
+}
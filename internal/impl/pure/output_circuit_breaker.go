@@ -0,0 +1,465 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/interop"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	cboFieldOutput              = "output"
+	cboFieldConsecutiveFailures = "consecutive_failures"
+	cboFieldErrorRate           = "error_rate"
+	cboFieldWindow              = "window"
+	cboFieldMinRequests         = "min_requests"
+	cboFieldResetTimeout        = "reset_timeout"
+	cboFieldFailReadiness       = "fail_readiness"
+)
+
+func circuitBreakerOutputSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Categories("Utility").
+		Beta().
+		Summary("Writes messages to a child output until it fails persistently, at which point the circuit breaker opens and writes fail immediately without reaching the child output.").
+		Description(`
+The circuit breaker opens once the child output fails a number of consecutive writes, or once the ratio of failed writes within a window of time exceeds the configured error rate. While open, messages are rejected without being sent to the child output, which allows a `+"[`fallback`](/docs/components/outputs/fallback)"+` output to route messages to the next output immediately rather than waiting for retries of a broken output to be exhausted.
+
+After the `+"`reset_timeout`"+` has elapsed the circuit breaker becomes half-open and the next message is sent to the child output as a probe. If the probe succeeds the circuit breaker closes and writes resume as normal, otherwise it opens again for another `+"`reset_timeout`"+`.
+
+### Monitoring
+
+The state of the circuit breaker is exposed with the gauge `+"`circuit_breaker_state`"+`, where `+"`0`"+` is closed, `+"`1`"+` is half-open and `+"`2`"+` is open. The counters `+"`circuit_breaker_opened`"+` and `+"`circuit_breaker_rejected`"+` track the number of times the circuit breaker has opened and the number of writes rejected whilst open respectively.
+
+By default the state of the circuit breaker does not affect the `+"`/ready`"+` endpoint, as messages rejected whilst open are often still delivered by a `+"`fallback`"+` output. When `+"`fail_readiness`"+` is enabled the output is instead reported as not connected whilst the circuit breaker is open or half-open, and the `+"`/ready`"+` endpoint responds with the state of the circuit breaker.`).
+		Fields(
+			service.NewOutputField(cboFieldOutput).
+				Description("A child output."),
+			service.NewIntField(cboFieldConsecutiveFailures).
+				Description("The number of consecutive failed writes after which the circuit breaker opens. Set to `0` to disable.").
+				Default(5),
+			service.NewFloatField(cboFieldErrorRate).
+				Description("The ratio of failed writes within the `window` above which the circuit breaker opens, between `0` and `1`. Set to `0` to disable.").
+				Example(0.5).
+				Default(0.0),
+			service.NewDurationField(cboFieldWindow).
+				Description("The period of time over which the error rate is calculated.").
+				Advanced().
+				Default("1m"),
+			service.NewIntField(cboFieldMinRequests).
+				Description("The minimum number of writes within the `window` before the error rate is considered.").
+				Advanced().
+				Default(10),
+			service.NewDurationField(cboFieldResetTimeout).
+				Description("The period of time that the circuit breaker remains open before a probe is sent to the child output.").
+				Default("30s"),
+			service.NewBoolField(cboFieldFailReadiness).
+				Description("Whether the output should be reported as not connected whilst the circuit breaker is open or half-open, which causes the `/ready` endpoint to fail.").
+				Advanced().
+				Default(false),
+		).
+		Example("Failing Over Quickly", "Route messages to a dead letter queue immediately whilst an HTTP endpoint is failing, probing the endpoint every ten seconds until it recovers.", `
+output:
+  fallback:
+    - circuit_breaker:
+        consecutive_failures: 3
+        reset_timeout: 10s
+        output:
+          http_client:
+            url: http://foo:4195/post
+    - file:
+        path: /usr/local/bento/dead_letters.jsonl
+`)
+}
+
+func init() {
+	err := service.RegisterBatchOutput(
+		"circuit_breaker", circuitBreakerOutputSpec(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (out service.BatchOutput, batchPolicy service.BatchPolicy, maxInFlight int, err error) {
+			maxInFlight = 1
+
+			var s output.Streamed
+			if s, err = circuitBreakerOutputFromConfig(conf, interop.UnwrapManagement(mgr)); err != nil {
+				return
+			}
+			out = interop.NewUnwrapInternalOutput(s)
+			return
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var (
+	errCircuitOpen     = errors.New("circuit breaker is open")
+	errCircuitHalfOpen = errors.New("circuit breaker is half-open")
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitHalfOpen:
+		return "half-open"
+	case circuitOpen:
+		return "open"
+	}
+	return "closed"
+}
+
+type circuitBreakerConfig struct {
+	ConsecutiveFailures int
+	ErrorRate           float64
+	Window              time.Duration
+	MinRequests         int
+	ResetTimeout        time.Duration
+	FailReadiness       bool
+}
+
+func circuitBreakerOutputFromConfig(conf *service.ParsedConfig, mgr bundle.NewManagement) (output.Streamed, error) {
+	pOut, err := conf.FieldOutput(cboFieldOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	var cConf circuitBreakerConfig
+	if cConf.ConsecutiveFailures, err = conf.FieldInt(cboFieldConsecutiveFailures); err != nil {
+		return nil, err
+	}
+	if cConf.ErrorRate, err = conf.FieldFloat(cboFieldErrorRate); err != nil {
+		return nil, err
+	}
+	if cConf.ErrorRate < 0 || cConf.ErrorRate > 1 {
+		return nil, fmt.Errorf("%v must be between 0 and 1, got %v", cboFieldErrorRate, cConf.ErrorRate)
+	}
+	if cConf.Window, err = conf.FieldDuration(cboFieldWindow); err != nil {
+		return nil, err
+	}
+	if cConf.MinRequests, err = conf.FieldInt(cboFieldMinRequests); err != nil {
+		return nil, err
+	}
+	if cConf.ResetTimeout, err = conf.FieldDuration(cboFieldResetTimeout); err != nil {
+		return nil, err
+	}
+	if cConf.FailReadiness, err = conf.FieldBool(cboFieldFailReadiness); err != nil {
+		return nil, err
+	}
+	if cConf.ConsecutiveFailures <= 0 && cConf.ErrorRate <= 0 {
+		return nil, fmt.Errorf("at least one of %v or %v must be set", cboFieldConsecutiveFailures, cboFieldErrorRate)
+	}
+	return newCircuitBreakerOutput(cConf, mgr, interop.UnwrapOwnedOutput(pOut)), nil
+}
+
+//------------------------------------------------------------------------------
+
+// circuitBreakerBuckets is the number of buckets that the error rate window is
+// divided into.
+const circuitBreakerBuckets = 10
+
+type circuitBucket struct {
+	start     time.Time
+	successes int
+	failures  int
+}
+
+// circuitBreaker tracks the outcome of writes and decides whether further
+// writes are permitted.
+type circuitBreaker struct {
+	conf circuitBreakerConfig
+	now  func() time.Time
+
+	mut          sync.Mutex
+	state        circuitState
+	openedAt     time.Time
+	probing      bool
+	consecutive  int
+	buckets      [circuitBreakerBuckets]circuitBucket
+	bucketPeriod time.Duration
+
+	log       log.Modular
+	mState    metrics.StatGauge
+	mOpened   metrics.StatCounter
+	mRejected metrics.StatCounter
+}
+
+func newCircuitBreaker(conf circuitBreakerConfig, mgr bundle.NewManagement) *circuitBreaker {
+	bucketPeriod := conf.Window / circuitBreakerBuckets
+	if bucketPeriod <= 0 {
+		bucketPeriod = time.Millisecond
+	}
+	stats := mgr.Metrics()
+	b := &circuitBreaker{
+		conf:         conf,
+		now:          time.Now,
+		bucketPeriod: bucketPeriod,
+		log:          mgr.Logger(),
+		mState:       stats.GetGauge("circuit_breaker_state"),
+		mOpened:      stats.GetCounter("circuit_breaker_opened"),
+		mRejected:    stats.GetCounter("circuit_breaker_rejected"),
+	}
+	b.mState.Set(int64(circuitClosed))
+	return b
+}
+
+// allow returns nil if a write is permitted, and whether the write is a probe
+// of a half-open circuit.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.conf.ResetTimeout {
+			b.mRejected.Incr(1)
+			return false, errCircuitOpen
+		}
+		b.setState(circuitHalfOpen)
+		b.log.Info("Circuit breaker is half-open, sending a probe to the output")
+		fallthrough
+	case circuitHalfOpen:
+		if b.probing {
+			b.mRejected.Incr(1)
+			return false, errCircuitHalfOpen
+		}
+		b.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// record the outcome of a write.
+func (b *circuitBreaker) record(probe bool, err error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if probe {
+		b.probing = false
+		if err != nil {
+			b.open(fmt.Sprintf("probe failed: %v", err))
+			return
+		}
+		b.reset()
+		b.setState(circuitClosed)
+		b.log.Info("Circuit breaker is closed, the output has recovered")
+		return
+	}
+	if b.state != circuitClosed {
+		// Writes that began before the circuit opened are ignored.
+		return
+	}
+
+	bucket := b.currentBucket()
+	if err == nil {
+		b.consecutive = 0
+		bucket.successes++
+		return
+	}
+	b.consecutive++
+	bucket.failures++
+
+	if b.conf.ConsecutiveFailures > 0 && b.consecutive >= b.conf.ConsecutiveFailures {
+		b.open(fmt.Sprintf("%v consecutive writes failed, last error: %v", b.consecutive, err))
+		return
+	}
+	if b.conf.ErrorRate > 0 {
+		var successes, failures int
+		windowStart := b.now().Add(-b.conf.Window)
+		for _, bk := range b.buckets {
+			if bk.start.After(windowStart) {
+				successes += bk.successes
+				failures += bk.failures
+			}
+		}
+		total := successes + failures
+		if total >= b.conf.MinRequests {
+			if rate := float64(failures) / float64(total); rate > b.conf.ErrorRate {
+				b.open(fmt.Sprintf("error rate of %.2f exceeded %v, last error: %v", rate, b.conf.ErrorRate, err))
+			}
+		}
+	}
+}
+
+func (b *circuitBreaker) currentBucket() *circuitBucket {
+	start := b.now().Truncate(b.bucketPeriod)
+	bucket := &b.buckets[(start.UnixNano()/int64(b.bucketPeriod))%circuitBreakerBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = circuitBucket{start: start}
+	}
+	return bucket
+}
+
+func (b *circuitBreaker) open(reason string) {
+	b.reset()
+	b.openedAt = b.now()
+	b.setState(circuitOpen)
+	b.mOpened.Incr(1)
+	b.log.Warn("Circuit breaker is open for %v: %v\n", b.conf.ResetTimeout, reason)
+}
+
+func (b *circuitBreaker) reset() {
+	b.consecutive = 0
+	b.buckets = [circuitBreakerBuckets]circuitBucket{}
+}
+
+func (b *circuitBreaker) setState(s circuitState) {
+	b.state = s
+	b.mState.Set(int64(s))
+}
+
+// err returns an error describing the state of the circuit if it isn't
+// closed.
+func (b *circuitBreaker) err() error {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		return errCircuitOpen
+	case circuitHalfOpen:
+		return errCircuitHalfOpen
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// circuitBreakerOutput is an output type that writes messages to a child output
+// until the circuit breaker opens, whilst open messages are rejected.
+type circuitBreakerOutput struct {
+	mgr     bundle.NewManagement
+	breaker *circuitBreaker
+	wrapped output.Streamed
+
+	transactionsIn  <-chan message.Transaction
+	transactionsOut chan message.Transaction
+
+	shutSig *shutdown.Signaller
+}
+
+func newCircuitBreakerOutput(conf circuitBreakerConfig, mgr bundle.NewManagement, wrapped output.Streamed) *circuitBreakerOutput {
+	return &circuitBreakerOutput{
+		mgr:             mgr,
+		breaker:         newCircuitBreaker(conf, mgr),
+		wrapped:         wrapped,
+		transactionsOut: make(chan message.Transaction),
+		shutSig:         shutdown.NewSignaller(),
+	}
+}
+
+func (c *circuitBreakerOutput) loop() {
+	wg := sync.WaitGroup{}
+
+	defer func() {
+		wg.Wait()
+		close(c.transactionsOut)
+		c.wrapped.TriggerCloseNow()
+		_ = c.wrapped.WaitForClose(context.Background())
+		c.shutSig.TriggerHasStopped()
+	}()
+
+	cnCtx, cnDone := c.shutSig.HardStopCtx(context.Background())
+	defer cnDone()
+
+	for !c.shutSig.IsSoftStopSignalled() {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-c.transactionsIn:
+			if !open {
+				return
+			}
+		case <-c.shutSig.HardStopChan():
+			return
+		}
+
+		probe, err := c.breaker.allow()
+		if err != nil {
+			if err := tran.Ack(cnCtx, err); err != nil && cnCtx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		rChan := make(chan error)
+		select {
+		case c.transactionsOut <- message.NewTransaction(tran.Payload.ShallowCopy(), rChan):
+		case <-c.shutSig.HardStopChan():
+			return
+		}
+
+		wg.Add(1)
+		go func(ts message.Transaction, resChan chan error, probe bool) {
+			defer wg.Done()
+
+			var res error
+			select {
+			case res = <-resChan:
+			case <-c.shutSig.HardStopChan():
+				return
+			}
+			c.breaker.record(probe, res)
+			_ = ts.Ack(cnCtx, res)
+		}(tran, rChan, probe)
+	}
+}
+
+// Consume assigns a messages channel for the output to read.
+func (c *circuitBreakerOutput) Consume(ts <-chan message.Transaction) error {
+	if c.transactionsIn != nil {
+		return component.ErrAlreadyStarted
+	}
+	if err := c.wrapped.Consume(c.transactionsOut); err != nil {
+		return err
+	}
+	c.transactionsIn = ts
+	go c.loop()
+	return nil
+}
+
+// ConnectionStatus returns the connection status of the child output, or a
+// failing status describing the circuit breaker whilst it isn't closed when
+// configured to fail readiness.
+func (c *circuitBreakerOutput) ConnectionStatus() component.ConnectionStatuses {
+	if !c.breaker.conf.FailReadiness {
+		return c.wrapped.ConnectionStatus()
+	}
+	if err := c.breaker.err(); err != nil {
+		return component.ConnectionStatuses{component.ConnectionFailing(c.mgr, err)}
+	}
+	return c.wrapped.ConnectionStatus()
+}
+
+// TriggerCloseNow shuts down the output and stops processing requests.
+func (c *circuitBreakerOutput) TriggerCloseNow() {
+	c.shutSig.TriggerHardStop()
+}
+
+// WaitForClose blocks until the output has closed down.
+func (c *circuitBreakerOutput) WaitForClose(ctx context.Context) error {
+	select {
+	case <-c.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package pure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func TestCircuitBreakerConfigErrs(t *testing.T) {
+	conf := parseYAMLOutputConf(t, `
+circuit_breaker:
+  consecutive_failures: 0
+  output:
+    drop: {}
+`)
+	_, err := bundle.AllOutputs.Init(conf, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at least one of")
+
+	conf = parseYAMLOutputConf(t, `
+circuit_breaker:
+  error_rate: 2
+  output:
+    drop: {}
+`)
+	_, err = bundle.AllOutputs.Init(conf, mock.NewManager())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "between 0 and 1")
+}
+
+func TestCircuitBreakerOutput(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := parseYAMLOutputConf(t, `
+circuit_breaker:
+  consecutive_failures: 2
+  reset_timeout: 10s
+  output:
+    drop: {}
+`)
+
+	out, err := bundle.AllOutputs.Init(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	cb, ok := out.(*circuitBreakerOutput)
+	require.True(t, ok, "%T", out)
+
+	now := time.Now()
+	cb.breaker.now = func() time.Time { return now }
+
+	mOut := &mock.OutputChanneled{}
+	cb.wrapped = mOut
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, cb.Consume(tChan))
+
+	send := func() chan error {
+		t.Helper()
+		resChan := make(chan error, 1)
+		select {
+		case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), resChan):
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		return resChan
+	}
+	receive := func() message.Transaction {
+		t.Helper()
+		select {
+		case tran := <-mOut.TChan:
+			return tran
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		return message.Transaction{}
+	}
+	forward := func(err error) {
+		t.Helper()
+		tran := receive()
+		require.NoError(t, tran.Ack(ctx, err))
+	}
+	result := func(resChan chan error) error {
+		t.Helper()
+		select {
+		case err := <-resChan:
+			return err
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+		return nil
+	}
+
+	errFailed := errors.New("nope")
+
+	// Consecutive failures open the circuit.
+	for i := 0; i < 2; i++ {
+		resChan := send()
+		forward(errFailed)
+		require.ErrorIs(t, result(resChan), errFailed)
+	}
+	require.Eventually(t, func() bool {
+		return cb.breaker.err() != nil
+	}, time.Second, time.Millisecond)
+
+	// An open circuit only fails readiness when configured to.
+	assert.True(t, cb.ConnectionStatus().AllActive())
+
+	cb.breaker.conf.FailReadiness = true
+	status := cb.ConnectionStatus()
+	require.Len(t, status, 1)
+	assert.False(t, status[0].Connected)
+	assert.ErrorIs(t, status[0].Err, errCircuitOpen)
+	cb.breaker.conf.FailReadiness = false
+
+	// Writes fail fast whilst open.
+	require.ErrorIs(t, result(send()), errCircuitOpen)
+
+	// After the reset timeout a probe is sent, and other writes are rejected
+	// until the probe resolves.
+	now = now.Add(time.Second * 11)
+
+	probeChan := send()
+	probe := receive()
+	require.ErrorIs(t, result(send()), errCircuitHalfOpen)
+	require.NoError(t, probe.Ack(ctx, errFailed))
+	require.ErrorIs(t, result(probeChan), errFailed)
+	require.ErrorIs(t, result(send()), errCircuitOpen)
+
+	now = now.Add(time.Second * 11)
+
+	probeChan = send()
+	forward(nil)
+	require.NoError(t, result(probeChan))
+	assert.NoError(t, cb.breaker.err())
+
+	resChan := send()
+	forward(nil)
+	require.NoError(t, result(resChan))
+
+	cb.TriggerCloseNow()
+	require.NoError(t, cb.WaitForClose(ctx))
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(circuitBreakerConfig{
+		ErrorRate:    0.5,
+		Window:       time.Minute,
+		MinRequests:  10,
+		ResetTimeout: time.Second,
+	}, mock.NewManager())
+	b.now = func() time.Time { return now }
+
+	errFailed := errors.New("nope")
+
+	// Alternating failures stay at the error rate without exceeding it.
+	for i := 0; i < 20; i++ {
+		var err error
+		if i%2 == 1 {
+			err = errFailed
+		}
+		_, aErr := b.allow()
+		require.NoError(t, aErr)
+		b.record(false, err)
+	}
+	require.NoError(t, b.err())
+
+	// Outcomes outside of the window are forgotten.
+	now = now.Add(time.Minute * 2)
+	for i := 0; i < 9; i++ {
+		b.record(false, errFailed)
+	}
+	require.NoError(t, b.err(), "fewer than min_requests within the window")
+
+	b.record(false, errFailed)
+	require.ErrorIs(t, b.err(), errCircuitOpen)
+}
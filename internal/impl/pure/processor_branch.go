@@ -10,7 +10,6 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
-	"github.com/warpstreamlabs/bento/internal/bloblang/query"
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component/interop"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
@@ -228,53 +227,12 @@ func newBranchFromParsed(conf *service.ParsedConfig, mgr bundle.NewManagement) (
 // TargetsUsed returns a list of paths that this branch depends on. Each path is
 // prefixed by a namespace `metadata` or `path` indicating the source.
 func (b *Branch) targetsUsed() [][]string {
-	if b.requestMap == nil {
-		return nil
-	}
-
-	var paths [][]string
-	_, queryTargets := b.requestMap.QueryTargets(query.TargetsContext{})
-
-pathLoop:
-	for _, p := range queryTargets {
-		path := make([]string, 0, len(p.Path)+1)
-		switch p.Type {
-		case query.TargetValue:
-			path = append(path, "path")
-		case query.TargetMetadata:
-			path = append(path, "metadata")
-		default:
-			continue pathLoop
-		}
-		paths = append(paths, append(path, p.Path...))
-	}
-
-	return paths
+	return processor.RequestMapTargets(b.requestMap)
 }
 
 // TargetsProvided returns a list of paths that this branch provides.
 func (b *Branch) targetsProvided() [][]string {
-	if b.resultMap == nil {
-		return nil
-	}
-
-	var paths [][]string
-
-pathLoop:
-	for _, p := range b.resultMap.AssignmentTargets() {
-		path := make([]string, 0, len(p.Path)+1)
-		switch p.Type {
-		case mapping.TargetValue:
-			path = append(path, "path")
-		case mapping.TargetMetadata:
-			path = append(path, "metadata")
-		default:
-			continue pathLoop
-		}
-		paths = append(paths, append(path, p.Path...))
-	}
-
-	return paths
+	return processor.ResultMapTargets(b.resultMap)
 }
 
 //------------------------------------------------------------------------------
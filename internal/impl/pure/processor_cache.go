@@ -2,8 +2,10 @@ package pure
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/bloblang/field"
@@ -21,6 +23,7 @@ const (
 	cachePFieldOperator = "operator"
 	cachePFieldKey      = "key"
 	cachePFieldValue    = "value"
+	cachePFieldOldValue = "old_value"
 	cachePFieldTTL      = "ttl"
 )
 
@@ -53,10 +56,39 @@ Retrieve the contents of a cached key and replace the original message payload
 with the result. If the key does not exist the action fails with an error, which
 can be detected with [processor error handling](/docs/configuration/error_handling).
 
+The keys of all messages within a batch are retrieved together, which for caches
+that support it results in a single request. If retrieving the keys together
+fails then each key is retrieved individually, so that an error only fails the
+messages of the keys it affects.
+
 ### `+"`delete`"+`
 
 Delete a key and its contents from the cache.  If the key does not exist the
-action is a no-op and will not fail with an error.`).
+action is a no-op and will not fail with an error.
+
+### `+"`increment`"+`
+
+Add the integer `+"`value`"+` (or one when the value is empty) to the integer
+stored at a key and replace the original message payload with the result. A key
+that does not exist is treated as zero, and the TTL is only applied when the key
+is created. Caches that do not support atomic increments fail with an error.
+
+### `+"`compare_and_swap`"+`
+
+Set a key in the cache to a value only if its current value matches
+`+"`old_value`"+`, or, when `+"`old_value`"+` is empty, only if the key does
+not already exist. If the current value does not match the action fails with a
+'key value does not match' error, which can be detected with
+[processor error handling](/docs/configuration/error_handling). Caches that do
+not support compare and swap natively fail with an error unless `+"`old_value`"+`
+is empty, in which case the operation is equivalent to `+"`add`"+`.
+
+### `+"`scan`"+`
+
+Retrieve all keys that begin with the prefix `+"`key`"+` along with their values
+and replace the original message payload with a JSON object of the results,
+where values are strings. Caches that do not support listing keys fail with an
+error.`).
 		Example("Deduplication", `
 Deduplication can be done using the add operator with a key extracted from the message payload, since it fails when a key already exists we can remove the duplicates using a [`+"`mapping` processor"+`](/docs/components/processors/mapping):`,
 			`
@@ -118,17 +150,39 @@ cache_resources:
   - label: foocache
     memcached:
       addresses: [ "TODO:11211" ]
+`).
+		Example("Counting", `
+The increment operator can be used to count messages by a field without racing other instances that share the cache, here the count of each user within the current hour is added to the message:`,
+			`
+pipeline:
+  processors:
+    - branch:
+        processors:
+          - cache:
+              resource: foocache
+              operator: increment
+              key: '${! json("user.id") }-${! now().ts_format("2006-01-02T15") }'
+              ttl: 1h
+        result_map: 'root.user.hourly_count = this'
+
+cache_resources:
+  - label: foocache
+    redis:
+      url: tcp://TODO:6379
 `).
 		Fields(
 			service.NewStringField(cachePFieldResource).
 				Description("The [`cache` resource](/docs/components/caches/about) to target with this processor."),
-			service.NewStringEnumField(cachePFieldOperator, "set", "add", "get", "delete").
+			service.NewStringEnumField(cachePFieldOperator, "set", "add", "get", "delete", "increment", "compare_and_swap", "scan").
 				Description("The [operation](#operators) to perform with the cache."),
 			service.NewInterpolatedStringField(cachePFieldKey).
 				Description("A key to use with the cache."),
 			service.NewInterpolatedStringField(cachePFieldValue).
 				Description("A value to use with the cache (when applicable).").
 				Optional(),
+			service.NewInterpolatedStringField(cachePFieldOldValue).
+				Description("The value expected to be currently held by the key when using the `compare_and_swap` operator. When empty the key is expected to not exist.").
+				Optional(),
 			service.NewInterpolatedStringField(cachePFieldTTL).
 				Description("The TTL of each individual item as a duration string. After this period an item will be eligible for removal during the next compaction. Not all caches support per-key TTLs, those that do will have a configuration field `default_ttl`, and those that do not will fall back to their generally configured TTL setting.").
 				Examples("60s", "5m", "36h").
@@ -143,6 +197,7 @@ type cacheProcConfig struct {
 	Operator string
 	Key      string
 	Value    string
+	OldValue string
 	TTL      string
 }
 
@@ -163,6 +218,7 @@ func init() {
 				return nil, err
 			}
 			cConf.Value, _ = conf.FieldString(cachePFieldValue)
+			cConf.OldValue, _ = conf.FieldString(cachePFieldOldValue)
 			cConf.TTL, _ = conf.FieldString(cachePFieldTTL)
 
 			mgr := interop.UnwrapManagement(res)
@@ -180,13 +236,18 @@ func init() {
 //------------------------------------------------------------------------------
 
 type cacheProc struct {
-	key   *field.Expression
-	value *field.Expression
-	ttl   *field.Expression
+	key      *field.Expression
+	value    *field.Expression
+	oldValue *field.Expression
+	ttl      *field.Expression
 
 	mgr       bundle.NewManagement
 	cacheName string
 	operator  cacheOperator
+
+	// When true the keys of a batch are retrieved with a single GetMulti call
+	// rather than applying the operator to each message.
+	getMulti bool
 }
 
 func newCache(conf cacheProcConfig, mgr bundle.NewManagement) (*cacheProc, error) {
@@ -210,6 +271,11 @@ func newCache(conf cacheProcConfig, mgr bundle.NewManagement) (*cacheProc, error
 		return nil, fmt.Errorf("failed to parse value expression: %v", err)
 	}
 
+	oldValue, err := mgr.BloblEnvironment().NewField(conf.OldValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old_value expression: %v", err)
+	}
+
 	ttl, err := mgr.BloblEnvironment().NewField(conf.TTL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ttl expression: %v", err)
@@ -220,48 +286,92 @@ func newCache(conf cacheProcConfig, mgr bundle.NewManagement) (*cacheProc, error
 	}
 
 	return &cacheProc{
-		key:   key,
-		value: value,
-		ttl:   ttl,
+		key:      key,
+		value:    value,
+		oldValue: oldValue,
+		ttl:      ttl,
 
 		mgr:       mgr,
 		cacheName: cacheName,
 		operator:  op,
+		getMulti:  conf.Operator == "get",
 	}, nil
 }
 
 //------------------------------------------------------------------------------
 
-type cacheOperator func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error)
+type cacheOperator func(ctx context.Context, cache cache.V1, key string, old, value []byte, ttl *time.Duration) ([]byte, bool, error)
 
 func newCacheSetOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, _, value []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Set(ctx, key, value, ttl)
 		return nil, false, err
 	}
 }
 
 func newCacheAddOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, _, value []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Add(ctx, key, value, ttl)
 		return nil, false, err
 	}
 }
 
 func newCacheGetOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, _ []byte, _ *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, _, _ []byte, _ *time.Duration) ([]byte, bool, error) {
 		result, err := cache.Get(ctx, key)
 		return result, true, err
 	}
 }
 
 func newCacheDeleteOperator() cacheOperator {
-	return func(ctx context.Context, cache cache.V1, key string, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
+	return func(ctx context.Context, cache cache.V1, key string, _, _ []byte, ttl *time.Duration) ([]byte, bool, error) {
 		err := cache.Delete(ctx, key)
 		return nil, false, err
 	}
 }
 
+func newCacheIncrementOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, _, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+		delta := int64(1)
+		if len(value) > 0 {
+			var err error
+			if delta, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+				return nil, false, fmt.Errorf("value must be an integer: %w", err)
+			}
+		}
+		result, err := cache.Increment(ctx, c, key, delta, ttl)
+		if err != nil {
+			return nil, false, err
+		}
+		return strconv.AppendInt(nil, result, 10), true, nil
+	}
+}
+
+func newCacheCompareAndSwapOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, key string, old, value []byte, ttl *time.Duration) ([]byte, bool, error) {
+		if len(old) == 0 {
+			old = nil
+		}
+		err := cache.CompareAndSwap(ctx, c, key, old, value, ttl)
+		return nil, false, err
+	}
+}
+
+func newCacheScanOperator() cacheOperator {
+	return func(ctx context.Context, c cache.V1, prefix string, _, _ []byte, _ *time.Duration) ([]byte, bool, error) {
+		items, err := cache.Scan(ctx, c, prefix)
+		if err != nil {
+			return nil, false, err
+		}
+		obj := make(map[string]any, len(items))
+		for k, v := range items {
+			obj[k] = string(v)
+		}
+		result, err := json.Marshal(obj)
+		return result, true, err
+	}
+}
+
 func cacheOperatorFromString(operator string) (cacheOperator, error) {
 	switch operator {
 	case "set":
@@ -272,6 +382,12 @@ func cacheOperatorFromString(operator string) (cacheOperator, error) {
 		return newCacheGetOperator(), nil
 	case "delete":
 		return newCacheDeleteOperator(), nil
+	case "increment":
+		return newCacheIncrementOperator(), nil
+	case "compare_and_swap":
+		return newCacheCompareAndSwapOperator(), nil
+	case "scan":
+		return newCacheScanOperator(), nil
 	}
 	return nil, fmt.Errorf("operator not recognised: %v", operator)
 }
@@ -279,6 +395,11 @@ func cacheOperatorFromString(operator string) (cacheOperator, error) {
 //------------------------------------------------------------------------------
 
 func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Batch) ([]message.Batch, error) {
+	if c.getMulti {
+		c.processGetMulti(ctx, msg)
+		return []message.Batch{msg}, nil
+	}
+
 	_ = msg.Iter(func(index int, part *message.Part) error {
 		key, err := c.key.String(index, msg)
 		if err != nil {
@@ -294,6 +415,13 @@ func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Ba
 			return nil
 		}
 
+		oldValue, err := c.oldValue.Bytes(index, msg)
+		if err != nil {
+			err = fmt.Errorf("old_value interpolation error: %w", err)
+			ctx.OnError(err, index, nil)
+			return nil
+		}
+
 		var ttl *time.Duration
 		ttls, err := c.ttl.String(index, msg)
 		if err != nil {
@@ -315,17 +443,12 @@ func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Ba
 		var result []byte
 		var useResult bool
 		if cerr := c.mgr.AccessCache(context.Background(), c.cacheName, func(cache cache.V1) {
-			result, useResult, err = c.operator(context.Background(), cache, key, value, ttl)
+			result, useResult, err = c.operator(context.Background(), cache, key, oldValue, value, ttl)
 		}); cerr != nil {
 			err = cerr
 		}
 		if err != nil {
-			if err != component.ErrKeyAlreadyExists {
-				err = fmt.Errorf("operator failed for key '%s': %v", key, err)
-			} else {
-				err = fmt.Errorf("key already exists: %v", key)
-			}
-			ctx.OnError(err, index, nil)
+			ctx.OnError(cacheOperatorErr(key, err), index, nil)
 			return nil
 		}
 
@@ -338,6 +461,82 @@ func (c *cacheProc) ProcessBatch(ctx *processor.BatchProcContext, msg message.Ba
 	return []message.Batch{msg}, nil
 }
 
+// processGetMulti retrieves the keys of all messages within a batch with a
+// single call, replacing the payload of each message with its result.
+func (c *cacheProc) processGetMulti(ctx *processor.BatchProcContext, msg message.Batch) {
+	keys := make([]string, len(msg))
+	valid := make([]bool, len(msg))
+	var uniqueKeys []string
+	seen := map[string]struct{}{}
+	_ = msg.Iter(func(index int, part *message.Part) error {
+		key, err := c.key.String(index, msg)
+		if err != nil {
+			ctx.OnError(fmt.Errorf("key interpolation error: %w", err), index, nil)
+			return nil
+		}
+		keys[index], valid[index] = key, true
+		if _, exists := seen[key]; !exists {
+			seen[key] = struct{}{}
+			uniqueKeys = append(uniqueKeys, key)
+		}
+		return nil
+	})
+
+	var results map[string][]byte
+	keyErrs := map[string]error{}
+	if len(uniqueKeys) > 0 {
+		if cerr := c.mgr.AccessCache(context.Background(), c.cacheName, func(c cache.V1) {
+			var err error
+			if results, err = cache.GetMulti(context.Background(), c, uniqueKeys...); err == nil {
+				return
+			}
+
+			// Fall back to retrieving each key individually so that an error
+			// only fails the messages of the keys it affects.
+			results = make(map[string][]byte, len(uniqueKeys))
+			for _, k := range uniqueKeys {
+				v, err := c.Get(context.Background(), k)
+				if err != nil {
+					if !errors.Is(err, component.ErrKeyNotFound) {
+						keyErrs[k] = err
+					}
+					continue
+				}
+				results[k] = v
+			}
+		}); cerr != nil {
+			for _, k := range uniqueKeys {
+				keyErrs[k] = cerr
+			}
+		}
+	}
+
+	_ = msg.Iter(func(index int, part *message.Part) error {
+		if !valid[index] {
+			return nil
+		}
+		key := keys[index]
+		if err, failed := keyErrs[key]; failed {
+			ctx.OnError(cacheOperatorErr(key, err), index, nil)
+			return nil
+		}
+		result, exists := results[key]
+		if !exists {
+			ctx.OnError(cacheOperatorErr(key, component.ErrKeyNotFound), index, nil)
+			return nil
+		}
+		part.SetBytes(result)
+		return nil
+	})
+}
+
+func cacheOperatorErr(key string, err error) error {
+	if err != component.ErrKeyAlreadyExists {
+		return fmt.Errorf("operator failed for key '%s': %v", key, err)
+	}
+	return fmt.Errorf("key already exists: %v", key)
+}
+
 func (c *cacheProc) Close(ctx context.Context) error {
 	return nil
 }
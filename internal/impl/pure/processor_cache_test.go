@@ -2,6 +2,7 @@ package pure_test
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -214,3 +215,192 @@ cache:
 	_, ok = mgr.Caches["foocache"]["3"]
 	require.False(t, ok)
 }
+
+func TestCacheIncrement(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"2":   {Value: "10"},
+		"nan": {Value: "foo"},
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+cache:
+  operator: increment
+  key: ${!json("key")}
+  value: ${!json("delta").or("")}
+  resource: foocache
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, res := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"key":"1"}`),
+		[]byte(`{"key":"1","delta":"5"}`),
+		[]byte(`{"key":"2","delta":"-3"}`),
+		[]byte(`{"key":"nan"}`),
+		[]byte(`{"key":"1","delta":"nope"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, [][]byte{
+		[]byte(`1`),
+		[]byte(`6`),
+		[]byte(`7`),
+		[]byte(`{"key":"nan"}`),
+		[]byte(`{"key":"1","delta":"nope"}`),
+	}, message.GetAllBytes(output[0]))
+
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.NoError(t, output[0].Get(2).ErrorGet())
+	assert.Error(t, output[0].Get(3).ErrorGet())
+	assert.Error(t, output[0].Get(4).ErrorGet())
+
+	assert.Equal(t, "6", mgr.Caches["foocache"]["1"].Value)
+	assert.Equal(t, "7", mgr.Caches["foocache"]["2"].Value)
+}
+
+func TestCacheCompareAndSwap(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "foo 1"},
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+cache:
+  operator: compare_and_swap
+  key: ${!json("key")}
+  old_value: ${!json("old").or("")}
+  value: ${!json("value")}
+  resource: foocache
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, res := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","old":"nope","value":"foo 2"}`),
+		[]byte(`{"key":"1","old":"foo 1","value":"foo 3"}`),
+		[]byte(`{"key":"2","value":"bar 1"}`),
+		[]byte(`{"key":"2","value":"bar 2"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	assert.Error(t, output[0].Get(0).ErrorGet())
+	assert.NoError(t, output[0].Get(1).ErrorGet())
+	assert.NoError(t, output[0].Get(2).ErrorGet())
+	assert.Error(t, output[0].Get(3).ErrorGet())
+
+	assert.Equal(t, "foo 3", mgr.Caches["foocache"]["1"].Value)
+	assert.Equal(t, "bar 1", mgr.Caches["foocache"]["2"].Value)
+}
+
+func TestCacheScan(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"user:1": {Value: "foo"},
+		"user:2": {Value: "bar"},
+		"team:1": {Value: "baz"},
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+cache:
+  operator: scan
+  key: ${!content()}
+  resource: foocache
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, res := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`user:`),
+		[]byte(`nope:`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, [][]byte{
+		[]byte(`{"user:1":"foo","user:2":"bar"}`),
+		[]byte(`{}`),
+	}, message.GetAllBytes(output[0]))
+}
+
+func TestCacheGetDuplicateKeys(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "foo 1"},
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+cache:
+  operator: get
+  key: ${!json("key")}
+  resource: foocache
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, res := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"key":"1"}`),
+		[]byte(`{"key":"2"}`),
+		[]byte(`{"key":"1"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	assert.Equal(t, [][]byte{
+		[]byte(`foo 1`),
+		[]byte(`{"key":"2"}`),
+		[]byte(`foo 1`),
+	}, message.GetAllBytes(output[0]))
+	assert.EqualError(t, output[0].Get(1).ErrorGet(), "operator failed for key '2': key does not exist")
+}
+
+func TestCacheGetPartialFailure(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mock.CacheItem{
+		"1": {Value: "foo 1"},
+		"2": {Err: errors.New("nope")},
+		"3": {Value: "foo 3"},
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+cache:
+  operator: get
+  key: ${!json("key")}
+  resource: foocache
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	output, res := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"key":"1"}`),
+		[]byte(`{"key":"2"}`),
+		[]byte(`{"key":"3"}`),
+		[]byte(`{"key":"4"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+
+	// A failed key only fails the messages it belongs to.
+	assert.Equal(t, [][]byte{
+		[]byte(`foo 1`),
+		[]byte(`{"key":"2"}`),
+		[]byte(`foo 3`),
+		[]byte(`{"key":"4"}`),
+	}, message.GetAllBytes(output[0]))
+	assert.NoError(t, output[0].Get(0).ErrorGet())
+	assert.EqualError(t, output[0].Get(1).ErrorGet(), "operator failed for key '2': nope")
+	assert.NoError(t, output[0].Get(2).ErrorGet())
+	assert.EqualError(t, output[0].Get(3).ErrorGet(), "operator failed for key '4': key does not exist")
+}
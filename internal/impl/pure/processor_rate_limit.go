@@ -2,10 +2,12 @@ package pure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/warpstreamlabs/bento/internal/bloblang/field"
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/interop"
@@ -17,15 +19,33 @@ import (
 
 const (
 	rlimitFieldResource = "resource"
+	rlimitFieldKey      = "key"
 )
 
 func rlimitProcSpec() *service.ConfigSpec {
 	return service.NewConfigSpec().
 		Categories("Utility").
 		Stable().
-		Summary(`Throttles the throughput of a pipeline according to a specified ` + "[`rate_limit`](/docs/components/rate_limits/about)" + ` resource. Rate limits are shared across components and therefore apply globally to all processing pipelines.`).
+		Summary(`Throttles the throughput of a pipeline according to a specified `+"[`rate_limit`](/docs/components/rate_limits/about)"+` resource. Rate limits are shared across components and therefore apply globally to all processing pipelines.`).
 		Field(service.NewStringField(rlimitFieldResource).
-			Description("The target [`rate_limit` resource](/docs/components/rate_limits/about)."))
+			Description("The target [`rate_limit` resource](/docs/components/rate_limits/about).")).
+		Field(service.NewInterpolatedStringField(rlimitFieldKey).
+			Description("An optional key to access the rate limit with, where each key is limited independently. The target rate limit must support keys, such as a `local` or `redis` rate limit with a `rate` configured. Messages that resolve to an empty key are limited without a key.").
+			Example("${! @tenant_id }").
+			Optional()).
+		Example("Per-tenant limits", "Limit each tenant to 10 messages per second with a burst of 20, where the tenant is identified by metadata.", `
+pipeline:
+  processors:
+    - rate_limit:
+        resource: tenant_limit
+        key: ${! @tenant_id }
+
+rate_limit_resources:
+  - label: tenant_limit
+    local:
+      rate: 10
+      burst: 20
+`)
 }
 
 func init() {
@@ -42,6 +62,18 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
+			if conf.Contains(rlimitFieldKey) {
+				keyStr, err := conf.FieldString(rlimitFieldKey)
+				if err != nil {
+					return nil, err
+				}
+				if r.key, err = mgr.BloblEnvironment().NewField(keyStr); err != nil {
+					return nil, fmt.Errorf("failed to parse key expression: %v", err)
+				}
+				if err := r.checkKeyed(); err != nil {
+					return nil, err
+				}
+			}
 			return interop.NewUnwrapInternalBatchProcessor(processor.NewAutoObservedProcessor("rate_limit", r, mgr)), nil
 		})
 	if err != nil {
@@ -51,6 +83,7 @@ func init() {
 
 type rateLimitProc struct {
 	rlName string
+	key    *field.Expression
 	mgr    bundle.NewManagement
 
 	closeChan chan struct{}
@@ -69,7 +102,30 @@ func newRateLimitProc(resStr string, mgr bundle.NewManagement) (*rateLimitProc,
 	return r, nil
 }
 
+// checkKeyed returns an error if the target rate limit cannot be accessed with
+// a key.
+func (r *rateLimitProc) checkKeyed() error {
+	supported := true
+	if err := r.mgr.AccessRateLimit(context.Background(), r.rlName, func(rl ratelimit.V1) {
+		supported = ratelimit.SupportsKeys(rl)
+	}); err != nil {
+		return err
+	}
+	if !supported {
+		return fmt.Errorf("rate limit resource '%v' does not support keys", r.rlName)
+	}
+	return nil
+}
+
 func (r *rateLimitProc) Process(ctx context.Context, msg *message.Part) ([]*message.Part, error) {
+	var key string
+	if r.key != nil {
+		var err error
+		if key, err = r.key.String(0, message.Batch{msg}); err != nil {
+			return nil, fmt.Errorf("key evaluation error: %w", err)
+		}
+	}
+
 	for {
 		var waitFor time.Duration
 		var err error
@@ -79,12 +135,12 @@ func (r *rateLimitProc) Process(ctx context.Context, msg *message.Part) ([]*mess
 				v2.Add(ctx, msg)
 			}
 
-			waitFor, err = rl.Access(ctx)
+			waitFor, err = ratelimit.AccessKey(ctx, rl, key)
 
 		}); rerr != nil {
 			err = rerr
 		}
-		if ctx.Err() != nil {
+		if ctx.Err() != nil || errors.Is(err, ratelimit.ErrKeyedNotSupported) {
 			return nil, err
 		}
 		if err != nil {
@@ -11,7 +11,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/warpstreamlabs/bento/internal/component/ratelimit"
 	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/manager/mock"
 	"github.com/warpstreamlabs/bento/internal/message"
 
@@ -168,3 +170,113 @@ rate_limit:
 		t.Error("Timed out")
 	}
 }
+
+func TestRateLimitKeyed(t *testing.T) {
+	mgrConf, err := testutil.ManagerFromYAML(`
+rate_limit_resources:
+  - label: foo
+    local:
+      rate: 0.001
+      burst: 2
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(mgrConf)
+	require.NoError(t, err)
+
+	conf, err := testutil.ProcessorFromYAML(`
+rate_limit:
+  resource: foo
+  key: ${! json("key") }
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	// Each key has its own burst of two.
+	input := message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","value":"foo 1"}`),
+		[]byte(`{"key":"2","value":"foo 2"}`),
+		[]byte(`{"key":"1","value":"foo 3"}`),
+		[]byte(`{"key":"2","value":"foo 4"}`),
+	})
+	output, res := proc.ProcessBatch(context.Background(), input)
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	assert.Equal(t, message.GetAllBytes(input), message.GetAllBytes(output[0]))
+
+	// A third message of a key is blocked until its bucket refills.
+	ctx, done := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer done()
+	output, res = proc.ProcessBatch(ctx, message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","value":"foo 5"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	require.ErrorIs(t, output[0].Get(0).ErrorGet(), context.DeadlineExceeded)
+
+	require.NoError(t, proc.Close(context.Background()))
+}
+
+func TestRateLimitKeyedNotSupported(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.RateLimits["foo"] = func(context.Context) (time.Duration, error) {
+		return 0, nil
+	}
+
+	conf, err := testutil.ProcessorFromYAML(`
+rate_limit:
+  resource: foo
+  key: ${! json("key") }
+`)
+	require.NoError(t, err)
+
+	_, err = mgr.NewProcessor(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support keys")
+}
+
+func TestRateLimitKeyedNotSupportedAfterUpdate(t *testing.T) {
+	mgrConf, err := testutil.ManagerFromYAML(`
+rate_limit_resources:
+  - label: foo
+    local:
+      rate: 10
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(mgrConf)
+	require.NoError(t, err)
+
+	conf, err := testutil.ProcessorFromYAML(`
+rate_limit:
+  resource: foo
+  key: ${! json("key") }
+`)
+	require.NoError(t, err)
+
+	proc, err := mgr.NewProcessor(conf)
+	require.NoError(t, err)
+
+	// Replacing the resource with one that doesn't support keys fails messages
+	// rather than retrying them indefinitely.
+	rlConf, err := testutil.RateLimitFromYAML(`
+local:
+  count: 10
+  interval: 1s
+`)
+	require.NoError(t, err)
+	require.NoError(t, mgr.StoreRateLimit(context.Background(), "foo", rlConf))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+	output, res := proc.ProcessBatch(ctx, message.QuickBatch([][]byte{
+		[]byte(`{"key":"1","value":"foo 1"}`),
+	}))
+	require.NoError(t, res)
+	require.Len(t, output, 1)
+	require.ErrorIs(t, output[0].Get(0).ErrorGet(), ratelimit.ErrKeyedNotSupported)
+
+	require.NoError(t, proc.Close(context.Background()))
+}
@@ -0,0 +1,598 @@
+package pure
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OneOfOne/xxhash"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/field"
+	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component/interop"
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/log"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	sampleFieldMode          = "mode"
+	sampleFieldKey           = "key"
+	sampleFieldRate          = "rate"
+	sampleFieldSize          = "size"
+	sampleFieldCount         = "count"
+	sampleFieldInterval      = "interval"
+	sampleFieldKeepCheck     = "keep_check"
+	sampleFieldCompleteCheck = "complete_check"
+	sampleFieldTimeout       = "timeout"
+	sampleFieldMaxKeys       = "max_keys"
+	sampleFieldMaxGroupSize  = "max_group_size"
+)
+
+func sampleProcSpec() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Categories("Utility").
+		Beta().
+		Summary("Thins a stream of messages by sampling them with one of several strategies.").
+		Description(`
+The strategy is chosen with the field `+"`mode`"+`, and each mode uses a subset of the remaining fields as described in their documentation.
+
+### `+"`hash`"+`
+
+Keeps a fraction of messages determined by the field `+"`rate`"+`, where the decision is made from a hash of the key. Messages that share a key are therefore either all kept or all dropped, which keeps correlated events such as those of a trace together. The decision is also consistent across restarts and between instances of Bento.
+
+### `+"`reservoir`"+`
+
+Keeps at most `+"`size`"+` messages from each batch, chosen uniformly at random with their order preserved. When a key is specified a separate reservoir is kept for each key within the batch. In order to sample a window of time combine this mode with a `+"[`system_window` buffer](/docs/components/buffers/system_window)"+` or a batching policy.
+
+### `+"`rate_cap`"+`
+
+Keeps at most `+"`count`"+` messages for each key within each `+"`interval`"+`, and drops the rest.
+
+### `+"`tail`"+`
+
+Holds messages that share a key, such as a trace or session ID, until their group completes, and then decides whether to keep the whole group. A group completes when a message passes the `+"`complete_check`"+` query, when it reaches `+"`max_group_size`"+` messages, or when no messages have been added to it within the `+"`timeout`"+`. A completed group is kept if any of its messages passed the `+"`keep_check`"+` query, and otherwise it is kept with the probability `+"`rate`"+` using the hash of its key.
+
+Completed groups are emitted by the processor as it processes messages, and therefore groups that time out are only flushed when the processor next receives a message. When the number of groups being held exceeds `+"`max_keys`"+` the least recently updated group is completed early.
+
+## State
+
+The `+"`rate_cap`"+` and `+"`tail`"+` modes hold state in memory for at most `+"`max_keys`"+` keys, where the least recently updated keys are evicted first. This state is local to each instance of the processor, and is lost when Bento restarts.
+
+## Delivery Guarantees
+
+Messages held by the `+"`tail`"+` mode are acknowledged at their source once they are held, and are therefore lost if Bento shuts down before their group completes. In order to preserve at-least-once delivery guarantees use one of the other modes.`).
+		Example(
+			"Keep traces together",
+			"Keeping 10% of traces, where all spans of a trace are either kept or dropped.",
+			`
+pipeline:
+  processors:
+    - sample:
+        mode: hash
+        key: ${! this.trace_id }
+        rate: 0.1
+`,
+		).
+		Example(
+			"Sample a window",
+			"Keeping at most 100 messages from each 10 second window.",
+			`
+buffer:
+  system_window:
+    timestamp_mapping: root = now()
+    size: 10s
+
+pipeline:
+  processors:
+    - sample:
+        mode: reservoir
+        size: 100
+`,
+		).
+		Example(
+			"Keep errored traces",
+			"Holding spans until the root span of their trace arrives, keeping all traces where any span errored along with 5% of the remainder.",
+			`
+pipeline:
+  processors:
+    - sample:
+        mode: tail
+        key: ${! this.trace_id }
+        complete_check: this.parent_span_id == ""
+        keep_check: this.status == "error"
+        rate: 0.05
+        timeout: 30s
+`,
+		).
+		Fields(
+			service.NewStringAnnotatedEnumField(sampleFieldMode, map[string]string{
+				"hash":      "Keeps a fraction of messages using a hash of their key.",
+				"reservoir": "Keeps a random subset of a fixed size from each batch.",
+				"rate_cap":  "Keeps at most a number of messages for each key within an interval.",
+				"tail":      "Holds groups of messages that share a key and decides whether to keep each group once it completes.",
+			}).
+				Description("The sampling strategy to use."),
+			service.NewInterpolatedStringField(sampleFieldKey).
+				Description("An interpolated string yielding the key of each message. Required by the `hash`, `rate_cap` and `tail` modes, and optional for the `reservoir` mode.").
+				Examples(`${! this.trace_id }`, `${! metadata("kafka_key") }`).
+				Optional(),
+			service.NewFloatField(sampleFieldRate).
+				Description("The fraction of messages to keep, between 0 and 1. Required by the `hash` mode, and for the `tail` mode this is the fraction of groups to keep that have not passed the `keep_check`.").
+				Examples(0.1, 0.01).
+				Optional(),
+			service.NewIntField(sampleFieldSize).
+				Description("The maximum number of messages to keep from each batch. Required by the `reservoir` mode.").
+				Example(100).
+				Optional(),
+			service.NewIntField(sampleFieldCount).
+				Description("The maximum number of messages to keep for each key within an interval. Required by the `rate_cap` mode.").
+				Example(10).
+				Optional(),
+			service.NewDurationField(sampleFieldInterval).
+				Description("The interval within which the `count` applies. Required by the `rate_cap` mode.").
+				Example("1s").
+				Optional(),
+			service.NewBloblangField(sampleFieldKeepCheck).
+				Description("A [Bloblang query](/docs/guides/bloblang/about/) that should return a boolean value indicating whether the group of a message should be kept. Used by the `tail` mode.").
+				Examples(`this.status == "error"`, `errored()`).
+				Default(`errored()`),
+			service.NewBloblangField(sampleFieldCompleteCheck).
+				Description("A [Bloblang query](/docs/guides/bloblang/about/) that should return a boolean value indicating whether the group of a message is complete. Used by the `tail` mode.").
+				Example(`this.parent_span_id == ""`).
+				Optional(),
+			service.NewDurationField(sampleFieldTimeout).
+				Description("The period after which a group that has not been added to is completed. Used by the `tail` mode.").
+				Default("10s"),
+			service.NewIntField(sampleFieldMaxKeys).
+				Description("The maximum number of keys to hold state for. Used by the `rate_cap` and `tail` modes.").
+				Default(10000).
+				Advanced(),
+			service.NewIntField(sampleFieldMaxGroupSize).
+				Description("The maximum number of messages to hold for a group, at which point it is completed. Used by the `tail` mode.").
+				Default(1000).
+				Advanced(),
+		)
+}
+
+func init() {
+	err := service.RegisterBatchProcessor(
+		"sample", sampleProcSpec(),
+		func(conf *service.ParsedConfig, res *service.Resources) (service.BatchProcessor, error) {
+			mgr := interop.UnwrapManagement(res)
+			p, err := newSampleFromParsed(conf, mgr)
+			if err != nil {
+				return nil, err
+			}
+			return interop.NewUnwrapInternalBatchProcessor(processor.NewAutoObservedBatchedProcessor("sample", p, mgr)), nil
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newSampleFromParsed(conf *service.ParsedConfig, mgr bundle.NewManagement) (processor.AutoObservedBatched, error) {
+	mode, err := conf.FieldString(sampleFieldMode)
+	if err != nil {
+		return nil, err
+	}
+
+	var key *field.Expression
+	if conf.Contains(sampleFieldKey) {
+		keyStr, err := conf.FieldString(sampleFieldKey)
+		if err != nil {
+			return nil, err
+		}
+		if key, err = mgr.BloblEnvironment().NewField(keyStr); err != nil {
+			return nil, fmt.Errorf("failed to parse key expression: %v", err)
+		}
+	}
+	if key == nil && mode != "reservoir" {
+		return nil, fmt.Errorf("a key must be specified for the %v mode", mode)
+	}
+
+	rate := -1.0
+	if conf.Contains(sampleFieldRate) {
+		if rate, err = conf.FieldFloat(sampleFieldRate); err != nil {
+			return nil, err
+		}
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("rate must be between 0 and 1, got %v", rate)
+		}
+	}
+
+	maxKeys, err := conf.FieldInt(sampleFieldMaxKeys)
+	if err != nil {
+		return nil, err
+	}
+	if maxKeys <= 0 {
+		return nil, errors.New("max_keys must be greater than zero")
+	}
+
+	switch mode {
+	case "hash":
+		if rate < 0 {
+			return nil, errors.New("a rate must be specified for the hash mode")
+		}
+		return &sampleHashProc{key: key, rate: rate}, nil
+	case "reservoir":
+		if !conf.Contains(sampleFieldSize) {
+			return nil, errors.New("a size must be specified for the reservoir mode")
+		}
+		size, err := conf.FieldInt(sampleFieldSize)
+		if err != nil {
+			return nil, err
+		}
+		if size <= 0 {
+			return nil, errors.New("size must be greater than zero")
+		}
+		return &sampleReservoirProc{key: key, size: size}, nil
+	case "rate_cap":
+		if !conf.Contains(sampleFieldCount) || !conf.Contains(sampleFieldInterval) {
+			return nil, errors.New("a count and interval must be specified for the rate_cap mode")
+		}
+		count, err := conf.FieldInt(sampleFieldCount)
+		if err != nil {
+			return nil, err
+		}
+		if count <= 0 {
+			return nil, errors.New("count must be greater than zero")
+		}
+		interval, err := conf.FieldDuration(sampleFieldInterval)
+		if err != nil {
+			return nil, err
+		}
+		if interval <= 0 {
+			return nil, errors.New("interval must be greater than zero")
+		}
+		return newSampleRateCapProc(key, count, interval, maxKeys), nil
+	case "tail":
+		if rate < 0 {
+			rate = 0
+		}
+		t := &sampleTailProc{
+			key:     key,
+			rate:    rate,
+			maxKeys: maxKeys,
+			log:     mgr.Logger(),
+			groups:  map[string]*list.Element{},
+			order:   list.New(),
+		}
+		keepStr, err := conf.FieldString(sampleFieldKeepCheck)
+		if err != nil {
+			return nil, err
+		}
+		if t.keepCheck, err = mgr.BloblEnvironment().NewMapping(keepStr); err != nil {
+			return nil, fmt.Errorf("failed to parse keep_check query: %w", err)
+		}
+		if completeStr, _ := conf.FieldString(sampleFieldCompleteCheck); completeStr != "" {
+			if t.completeCheck, err = mgr.BloblEnvironment().NewMapping(completeStr); err != nil {
+				return nil, fmt.Errorf("failed to parse complete_check query: %w", err)
+			}
+		}
+		if t.timeout, err = conf.FieldDuration(sampleFieldTimeout); err != nil {
+			return nil, err
+		}
+		if t.maxGroupSize, err = conf.FieldInt(sampleFieldMaxGroupSize); err != nil {
+			return nil, err
+		}
+		if t.maxGroupSize <= 0 {
+			return nil, errors.New("max_group_size must be greater than zero")
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("mode not recognised: %v", mode)
+}
+
+// sampleHashKeep returns whether a key falls within the fraction of the hash
+// space determined by rate.
+func sampleHashKeep(key string, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	return float64(xxhash.ChecksumString64(key)) < rate*math.MaxUint64
+}
+
+//------------------------------------------------------------------------------
+
+type sampleHashProc struct {
+	key  *field.Expression
+	rate float64
+}
+
+func (s *sampleHashProc) ProcessBatch(ctx *processor.BatchProcContext, batch message.Batch) ([]message.Batch, error) {
+	newBatch := message.QuickBatch(nil)
+	_ = batch.Iter(func(i int, p *message.Part) error {
+		key, err := s.key.String(i, batch)
+		if err != nil {
+			ctx.OnError(fmt.Errorf("key interpolation error: %w", err), i, p)
+		} else if !sampleHashKeep(key, s.rate) {
+			ctx.Span(i).LogKV("event", "dropped", "type", "sampled")
+			return nil
+		}
+		newBatch = append(newBatch, p)
+		return nil
+	})
+	if newBatch.Len() == 0 {
+		return nil, nil
+	}
+	return []message.Batch{newBatch}, nil
+}
+
+func (s *sampleHashProc) Close(context.Context) error {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type sampleReservoirProc struct {
+	key  *field.Expression
+	size int
+}
+
+func (s *sampleReservoirProc) ProcessBatch(ctx *processor.BatchProcContext, batch message.Batch) ([]message.Batch, error) {
+	type reservoir struct {
+		seen    int
+		indexes []int
+	}
+	reservoirs := map[string]*reservoir{}
+
+	var kept []int
+	_ = batch.Iter(func(i int, p *message.Part) error {
+		var key string
+		if s.key != nil {
+			var err error
+			if key, err = s.key.String(i, batch); err != nil {
+				ctx.OnError(fmt.Errorf("key interpolation error: %w", err), i, p)
+				kept = append(kept, i)
+				return nil
+			}
+		}
+
+		r, exists := reservoirs[key]
+		if !exists {
+			r = &reservoir{}
+			reservoirs[key] = r
+		}
+		r.seen++
+
+		// Algorithm R, where each message has an equal probability of ending
+		// up within the reservoir.
+		if len(r.indexes) < s.size {
+			r.indexes = append(r.indexes, i)
+		} else if j := rand.IntN(r.seen); j < s.size {
+			r.indexes[j] = i
+		}
+		return nil
+	})
+
+	for _, r := range reservoirs {
+		kept = append(kept, r.indexes...)
+	}
+	if len(kept) == 0 {
+		return nil, nil
+	}
+	sort.Ints(kept)
+
+	newBatch := make(message.Batch, 0, len(kept))
+	nextKept := 0
+	for i, p := range batch {
+		if nextKept < len(kept) && kept[nextKept] == i {
+			newBatch = append(newBatch, p)
+			nextKept++
+			continue
+		}
+		ctx.Span(i).LogKV("event", "dropped", "type", "sampled")
+	}
+	return []message.Batch{newBatch}, nil
+}
+
+func (s *sampleReservoirProc) Close(context.Context) error {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type sampleRateCapWindow struct {
+	key     string
+	started time.Time
+	count   int
+}
+
+type sampleRateCapProc struct {
+	key      *field.Expression
+	count    int
+	interval time.Duration
+	maxKeys  int
+
+	mut     sync.Mutex
+	windows map[string]*list.Element
+
+	// order holds windows from the oldest to the most recently started.
+	order *list.List
+}
+
+func newSampleRateCapProc(key *field.Expression, count int, interval time.Duration, maxKeys int) *sampleRateCapProc {
+	return &sampleRateCapProc{
+		key:      key,
+		count:    count,
+		interval: interval,
+		maxKeys:  maxKeys,
+		windows:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (s *sampleRateCapProc) allow(key string, now time.Time) bool {
+	if e, exists := s.windows[key]; exists {
+		w := e.Value.(*sampleRateCapWindow)
+		if now.Sub(w.started) >= s.interval {
+			w.started, w.count = now, 0
+			s.order.MoveToBack(e)
+		}
+		if w.count >= s.count {
+			return false
+		}
+		w.count++
+		return true
+	}
+
+	for len(s.windows) >= s.maxKeys {
+		oldest := s.order.Front()
+		delete(s.windows, oldest.Value.(*sampleRateCapWindow).key)
+		s.order.Remove(oldest)
+	}
+	s.windows[key] = s.order.PushBack(&sampleRateCapWindow{
+		key:     key,
+		started: now,
+		count:   1,
+	})
+	return true
+}
+
+func (s *sampleRateCapProc) ProcessBatch(ctx *processor.BatchProcContext, batch message.Batch) ([]message.Batch, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	now := time.Now()
+	newBatch := message.QuickBatch(nil)
+	_ = batch.Iter(func(i int, p *message.Part) error {
+		key, err := s.key.String(i, batch)
+		if err != nil {
+			ctx.OnError(fmt.Errorf("key interpolation error: %w", err), i, p)
+		} else if !s.allow(key, now) {
+			ctx.Span(i).LogKV("event", "dropped", "type", "sampled")
+			return nil
+		}
+		newBatch = append(newBatch, p)
+		return nil
+	})
+	if newBatch.Len() == 0 {
+		return nil, nil
+	}
+	return []message.Batch{newBatch}, nil
+}
+
+func (s *sampleRateCapProc) Close(context.Context) error {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type sampleTailGroup struct {
+	key     string
+	parts   message.Batch
+	keep    bool
+	updated time.Time
+}
+
+type sampleTailProc struct {
+	key           *field.Expression
+	rate          float64
+	keepCheck     *mapping.Executor
+	completeCheck *mapping.Executor
+	timeout       time.Duration
+	maxKeys       int
+	maxGroupSize  int
+
+	log log.Modular
+
+	mut    sync.Mutex
+	groups map[string]*list.Element
+
+	// order holds groups from the least to the most recently updated.
+	order *list.List
+}
+
+// complete removes a group and returns its messages if it is to be kept.
+func (s *sampleTailProc) complete(e *list.Element) message.Batch {
+	g := e.Value.(*sampleTailGroup)
+	delete(s.groups, g.key)
+	s.order.Remove(e)
+
+	if g.keep || sampleHashKeep(g.key, s.rate) {
+		return g.parts
+	}
+	return nil
+}
+
+func (s *sampleTailProc) ProcessBatch(ctx *processor.BatchProcContext, batch message.Batch) ([]message.Batch, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	now := time.Now()
+	var newBatch message.Batch
+
+	// Flush groups that have timed out since the last batch.
+	for e := s.order.Front(); e != nil && now.Sub(e.Value.(*sampleTailGroup).updated) >= s.timeout; e = s.order.Front() {
+		newBatch = append(newBatch, s.complete(e)...)
+	}
+
+	_ = batch.Iter(func(i int, p *message.Part) error {
+		key, err := s.key.String(i, batch)
+		if err != nil {
+			ctx.OnError(fmt.Errorf("key interpolation error: %w", err), i, p)
+			newBatch = append(newBatch, p)
+			return nil
+		}
+
+		e, exists := s.groups[key]
+		if !exists {
+			for len(s.groups) >= s.maxKeys {
+				newBatch = append(newBatch, s.complete(s.order.Front())...)
+			}
+			e = s.order.PushBack(&sampleTailGroup{key: key})
+			s.groups[key] = e
+		} else {
+			s.order.MoveToBack(e)
+		}
+
+		g := e.Value.(*sampleTailGroup)
+		g.parts = append(g.parts, p.ShallowCopy())
+		g.updated = now
+
+		if !g.keep {
+			if g.keep, err = s.keepCheck.QueryPart(i, batch); err != nil {
+				s.log.Error("Failed to execute keep_check query: %v", err)
+			}
+		}
+
+		complete := len(g.parts) >= s.maxGroupSize
+		if !complete && s.completeCheck != nil {
+			if complete, err = s.completeCheck.QueryPart(i, batch); err != nil {
+				s.log.Error("Failed to execute complete_check query: %v", err)
+			}
+		}
+		if complete {
+			newBatch = append(newBatch, s.complete(e)...)
+		}
+		return nil
+	})
+
+	if len(newBatch) == 0 {
+		return nil, nil
+	}
+	return []message.Batch{newBatch}, nil
+}
+
+func (s *sampleTailProc) Close(context.Context) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var held int
+	for e := s.order.Front(); e != nil; e = e.Next() {
+		held += len(e.Value.(*sampleTailGroup).parts)
+	}
+	if held > 0 {
+		s.log.Warn("Dropping %v messages held by incomplete groups", held)
+	}
+	return nil
+}
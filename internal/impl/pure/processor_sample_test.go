@@ -0,0 +1,301 @@
+package pure_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/processor"
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func newSampleProc(t *testing.T, confStr string) processor.V1 {
+	t.Helper()
+
+	conf, err := testutil.ProcessorFromYAML(confStr)
+	require.NoError(t, err)
+
+	proc, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, proc.Close(context.Background()))
+	})
+	return proc
+}
+
+func sampleContents(t *testing.T, batches []message.Batch) []string {
+	t.Helper()
+
+	var contents []string
+	for _, b := range batches {
+		for _, p := range b {
+			contents = append(contents, string(p.AsBytes()))
+		}
+	}
+	return contents
+}
+
+func TestSampleErrs(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		conf   string
+		errStr string
+	}{
+		{
+			name: "hash without key",
+			conf: `
+sample:
+  mode: hash
+  rate: 0.5
+`,
+			errStr: "failed to init processor <no label>: a key must be specified for the hash mode",
+		},
+		{
+			name: "hash without rate",
+			conf: `
+sample:
+  mode: hash
+  key: ${! content() }
+`,
+			errStr: "failed to init processor <no label>: a rate must be specified for the hash mode",
+		},
+		{
+			name: "rate out of bounds",
+			conf: `
+sample:
+  mode: hash
+  key: ${! content() }
+  rate: 1.5
+`,
+			errStr: "failed to init processor <no label>: rate must be between 0 and 1, got 1.5",
+		},
+		{
+			name: "reservoir without size",
+			conf: `
+sample:
+  mode: reservoir
+`,
+			errStr: "failed to init processor <no label>: a size must be specified for the reservoir mode",
+		},
+		{
+			name: "rate cap without interval",
+			conf: `
+sample:
+  mode: rate_cap
+  key: ${! content() }
+  count: 10
+`,
+			errStr: "failed to init processor <no label>: a count and interval must be specified for the rate_cap mode",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf, err := testutil.ProcessorFromYAML(test.conf)
+			require.NoError(t, err)
+
+			_, err = mock.NewManager().NewProcessor(conf)
+			require.EqualError(t, err, test.errStr)
+		})
+	}
+}
+
+func TestSampleHash(t *testing.T) {
+	proc := newSampleProc(t, `
+sample:
+  mode: hash
+  key: ${! content().split("-").index(0) }
+  rate: 0.5
+`)
+
+	var inputs [][]byte
+	for i := 0; i < 1000; i++ {
+		inputs = append(inputs, []byte(fmt.Sprintf("%v-a", i)), []byte(fmt.Sprintf("%v-b", i)))
+	}
+
+	res, err := proc.ProcessBatch(context.Background(), message.QuickBatch(inputs))
+	require.NoError(t, err)
+
+	kept := sampleContents(t, res)
+	assert.InDelta(t, 1000, len(kept), 100)
+
+	// Messages that share a key are kept together.
+	require.Equal(t, 0, len(kept)%2)
+	for i := 0; i < len(kept); i += 2 {
+		assert.Equal(t, kept[i][:len(kept[i])-1]+"b", kept[i+1])
+	}
+
+	// The decision for a key is deterministic.
+	res, err = proc.ProcessBatch(context.Background(), message.QuickBatch(inputs))
+	require.NoError(t, err)
+	assert.Equal(t, kept, sampleContents(t, res))
+}
+
+func TestSampleReservoir(t *testing.T) {
+	proc := newSampleProc(t, `
+sample:
+  mode: reservoir
+  key: ${! content().split("-").index(0) }
+  size: 3
+`)
+
+	var inputs [][]byte
+	for i := 0; i < 10; i++ {
+		inputs = append(inputs, []byte("a-"+strconv.Itoa(i)), []byte("b-"+strconv.Itoa(i)))
+	}
+	inputs = append(inputs, []byte("c-0"))
+
+	res, err := proc.ProcessBatch(context.Background(), message.QuickBatch(inputs))
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+
+	counts := map[string]int{}
+	lastIndex := map[string]int{}
+	for _, c := range sampleContents(t, res) {
+		key := c[:1]
+		counts[key]++
+
+		// The order of messages is preserved.
+		index, err := strconv.Atoi(c[2:])
+		require.NoError(t, err)
+		if last, exists := lastIndex[key]; exists {
+			assert.Greater(t, index, last)
+		}
+		lastIndex[key] = index
+	}
+	assert.Equal(t, map[string]int{"a": 3, "b": 3, "c": 1}, counts)
+}
+
+func TestSampleRateCap(t *testing.T) {
+	proc := newSampleProc(t, `
+sample:
+  mode: rate_cap
+  key: ${! content() }
+  count: 2
+  interval: 100ms
+`)
+
+	inputs := [][]byte{[]byte("a"), []byte("a"), []byte("b"), []byte("a"), []byte("a")}
+
+	res, err := proc.ProcessBatch(context.Background(), message.QuickBatch(inputs))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "a", "b"}, sampleContents(t, res))
+
+	res, err = proc.ProcessBatch(context.Background(), message.QuickBatch(inputs))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, sampleContents(t, res))
+
+	time.Sleep(time.Millisecond * 150)
+
+	res, err = proc.ProcessBatch(context.Background(), message.QuickBatch(inputs))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "a", "b"}, sampleContents(t, res))
+}
+
+func TestSampleRateCapMaxKeys(t *testing.T) {
+	proc := newSampleProc(t, `
+sample:
+  mode: rate_cap
+  key: ${! content() }
+  count: 1
+  interval: 1h
+  max_keys: 1
+`)
+
+	res, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte("a"), []byte("a"), []byte("b"), []byte("a"),
+	}))
+	require.NoError(t, err)
+
+	// Tracking b evicts a, which is then allowed again.
+	assert.Equal(t, []string{"a", "b", "a"}, sampleContents(t, res))
+}
+
+func TestSampleTail(t *testing.T) {
+	proc := newSampleProc(t, `
+sample:
+  mode: tail
+  key: ${! this.trace }
+  complete_check: this.root == true
+  keep_check: this.error == true
+  rate: 0
+  timeout: 100ms
+  max_group_size: 3
+`)
+
+	tCtx := context.Background()
+
+	res, err := proc.ProcessBatch(tCtx, message.QuickBatch([][]byte{
+		[]byte(`{"trace":"a","id":1}`),
+		[]byte(`{"trace":"b","id":2}`),
+		[]byte(`{"trace":"a","id":3,"error":true}`),
+		[]byte(`{"trace":"c","id":4}`),
+	}))
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	res, err = proc.ProcessBatch(tCtx, message.QuickBatch([][]byte{
+		[]byte(`{"trace":"b","id":5,"root":true}`),
+		[]byte(`{"trace":"a","id":6,"root":true}`),
+	}))
+	require.NoError(t, err)
+
+	// Trace b completes without errors and is dropped, whereas trace a is kept.
+	assert.Equal(t, []string{
+		`{"trace":"a","id":1}`,
+		`{"trace":"a","id":3,"error":true}`,
+		`{"trace":"a","id":6,"root":true}`,
+	}, sampleContents(t, res))
+
+	res, err = proc.ProcessBatch(tCtx, message.QuickBatch([][]byte{
+		[]byte(`{"trace":"d","id":7,"error":true}`),
+		[]byte(`{"trace":"d","id":8}`),
+		[]byte(`{"trace":"d","id":9}`),
+		[]byte(`{"trace":"e","id":10,"error":true}`),
+	}))
+	require.NoError(t, err)
+
+	// Trace d completes by reaching the maximum group size.
+	assert.Equal(t, []string{
+		`{"trace":"d","id":7,"error":true}`,
+		`{"trace":"d","id":8}`,
+		`{"trace":"d","id":9}`,
+	}, sampleContents(t, res))
+
+	time.Sleep(time.Millisecond * 150)
+
+	// Traces c and e time out, where only e is kept.
+	res, err = proc.ProcessBatch(tCtx, message.QuickBatch([][]byte{
+		[]byte(`{"trace":"f","id":11}`),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"trace":"e","id":10,"error":true}`,
+	}, sampleContents(t, res))
+}
+
+func TestSampleTailMaxKeys(t *testing.T) {
+	proc := newSampleProc(t, `
+sample:
+  mode: tail
+  key: ${! this.trace }
+  rate: 1
+  max_keys: 2
+`)
+
+	res, err := proc.ProcessBatch(context.Background(), message.QuickBatch([][]byte{
+		[]byte(`{"trace":"a","id":1}`),
+		[]byte(`{"trace":"b","id":2}`),
+		[]byte(`{"trace":"a","id":3}`),
+		[]byte(`{"trace":"c","id":4}`),
+	}))
+	require.NoError(t, err)
+
+	// Holding trace c completes trace b early as it was least recently updated.
+	assert.Equal(t, []string{`{"trace":"b","id":2}`}, sampleContents(t, res))
+}
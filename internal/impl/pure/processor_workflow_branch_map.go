@@ -4,11 +4,8 @@ import (
 	"context"
 	"fmt"
 	"regexp"
-	"sort"
 	"sync"
 
-	"github.com/quipo/dependencysolver"
-
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/public/service"
@@ -216,38 +213,6 @@ func (r *normalBranch) lock() (branch *Branch, unlockFn func()) {
 
 //------------------------------------------------------------------------------
 
-func depHasPrefix(wanted, provided []string) bool {
-	if len(wanted) < len(provided) {
-		return false
-	}
-	for i, s := range provided {
-		if wanted[i] != s {
-			return false
-		}
-	}
-	return true
-}
-
-func getBranchDeps(id string, wanted [][]string, branches map[string]*Branch) []string {
-	dependencies := []string{}
-
-	for k, b := range branches {
-		if k == id {
-			continue
-		}
-		for _, tp := range b.targetsProvided() {
-			for _, tn := range wanted {
-				if depHasPrefix(tn, tp) {
-					dependencies = append(dependencies, k)
-					break
-				}
-			}
-		}
-	}
-
-	return dependencies
-}
-
 func verifyStaticBranchDAG(order [][]string, branches map[string]workflowBranch) error {
 	remaining := map[string]struct{}{}
 	seen := map[string]struct{}{}
@@ -277,36 +242,12 @@ func verifyStaticBranchDAG(order [][]string, branches map[string]workflowBranch)
 }
 
 func resolveDynamicBranchDAG(branches map[string]*Branch) ([][]string, error) {
-	if len(branches) == 0 {
-		return [][]string{}, nil
-	}
-	remaining := map[string]struct{}{}
-
-	var entries []dependencysolver.Entry
+	targets := make(map[string]processor.BranchTargets, len(branches))
 	for id, b := range branches {
-		wanted := b.targetsUsed()
-
-		remaining[id] = struct{}{}
-		entries = append(entries, dependencysolver.Entry{
-			ID: id, Deps: getBranchDeps(id, wanted, branches),
-		})
-	}
-
-	layers := dependencysolver.LayeredTopologicalSort(entries)
-	for _, l := range layers {
-		for _, id := range l {
-			delete(remaining, id)
+		targets[id] = processor.BranchTargets{
+			Used:     b.targetsUsed(),
+			Provided: b.targetsProvided(),
 		}
 	}
-
-	if len(remaining) > 0 {
-		var tProcs []string
-		for k := range remaining {
-			tProcs = append(tProcs, k)
-		}
-		sort.Strings(tProcs)
-		return nil, fmt.Errorf("failed to automatically resolve DAG, circular dependencies detected for branches: %v", tProcs)
-	}
-
-	return layers, nil
+	return processor.ResolveBranchDAG(targets)
 }
@@ -3,6 +3,7 @@ package pure
 import (
 	"context"
 	"errors"
+	"math"
 	"sync"
 	"time"
 
@@ -14,6 +15,12 @@ func localRatelimitConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
 		Stable().
 		Summary(`The local rate limit is a simple X every Y type rate limit that can be shared across any number of components within the pipeline but does not support distributed rate limits across multiple running instances of Bento.`).
+		Description(`
+### Token Bucket
+
+When the field ` + "`rate`" + ` is set the rate limit instead uses token bucket semantics, where tokens are added at ` + "`rate`" + ` per second up to a maximum of ` + "`burst`" + `, and each access consumes a token. In this mode the fields ` + "`count`" + `, ` + "`byte_size`" + ` and ` + "`interval`" + ` are ignored.
+
+A token bucket rate limit also supports keys, where each key is given its own bucket. This allows a single rate limit resource to limit each tenant independently when used with components that have a ` + "`key`" + ` field, such as the ` + "[`rate_limit` processor](/docs/components/processors/rate_limit)" + `.`).
 		Field(service.NewIntField("count").
 			Description("The maximum number of requests to allow for a given period of time. If `0` disables count based rate-limiting.").
 			Default(1000).LintRule(`root = if this < 0 { [ "count cannot be less than zero" ] }`)).
@@ -22,7 +29,15 @@ func localRatelimitConfig() *service.ConfigSpec {
 			Default(0).LintRule(`root = if this < 0 { [ "byte_size cannot be less than zero" ] }`)).
 		Field(service.NewDurationField("interval").
 			Description("The time window to limit requests by.").
-			Default("1s"))
+			Default("1s")).
+		Field(service.NewFloatField("rate").
+			Description("The number of tokens added per second when using token bucket semantics. When set the fields `count`, `byte_size` and `interval` are ignored.").
+			Optional().
+			LintRule(`root = if this <= 0 { [ "rate must be larger than zero" ] }`)).
+		Field(service.NewIntField("burst").
+			Description("The maximum number of tokens held by a bucket when using token bucket semantics, defaults to `rate` rounded up.").
+			Optional().
+			LintRule(`root = if this <= 0 { [ "burst must be larger than zero" ] }`))
 
 	return spec
 }
@@ -31,6 +46,9 @@ func init() {
 	err := service.RegisterRateLimit(
 		"local", localRatelimitConfig(),
 		func(conf *service.ParsedConfig, mgr *service.Resources) (service.RateLimit, error) {
+			if conf.Contains("rate") {
+				return newLocalTokenBucketsFromConfig(conf)
+			}
 			return newLocalRatelimitFromConfig(conf)
 		})
 	if err != nil {
@@ -157,3 +175,106 @@ func (r *localRatelimit) refresh() {
 func (r *localRatelimit) Close(ctx context.Context) error {
 	return nil
 }
+
+//------------------------------------------------------------------------------
+
+func newLocalTokenBucketsFromConfig(conf *service.ParsedConfig) (*localTokenBuckets, error) {
+	rate, err := conf.FieldFloat("rate")
+	if err != nil {
+		return nil, err
+	}
+	burst := int(math.Ceil(rate))
+	if conf.Contains("burst") {
+		if burst, err = conf.FieldInt("burst"); err != nil {
+			return nil, err
+		}
+	}
+	return newLocalTokenBuckets(rate, burst)
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// localTokenBuckets is a token bucket rate limit with a separate bucket for
+// each key.
+type localTokenBuckets struct {
+	rate  float64
+	burst float64
+
+	mut       sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newLocalTokenBuckets(rate float64, burst int) (*localTokenBuckets, error) {
+	if rate <= 0 {
+		return nil, errors.New("rate must be larger than zero")
+	}
+	if burst <= 0 {
+		return nil, errors.New("burst must be larger than zero")
+	}
+	return &localTokenBuckets{
+		rate:      rate,
+		burst:     float64(burst),
+		buckets:   map[string]*tokenBucket{},
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}, nil
+}
+
+func (r *localTokenBuckets) refill(b *tokenBucket, now time.Time) {
+	if elapsed := now.Sub(b.lastRefill); elapsed > 0 {
+		b.tokens = math.Min(r.burst, b.tokens+elapsed.Seconds()*r.rate)
+	}
+	b.lastRefill = now
+}
+
+// sweep removes buckets that have refilled completely, as they are no
+// different from a new bucket.
+func (r *localTokenBuckets) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < time.Minute {
+		return
+	}
+	r.lastSweep = now
+	for k, b := range r.buckets {
+		if r.refill(b, now); b.tokens >= r.burst {
+			delete(r.buckets, k)
+		}
+	}
+}
+
+func (r *localTokenBuckets) Access(ctx context.Context) (time.Duration, error) {
+	return r.AccessKey(ctx, "")
+}
+
+func (r *localTokenBuckets) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	now := r.now()
+	r.sweep(now)
+
+	b, exists := r.buckets[key]
+	if !exists {
+		b = &tokenBucket{tokens: r.burst, lastRefill: now}
+		r.buckets[key] = b
+	}
+	r.refill(b, now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, nil
+	}
+	wait := time.Duration((1 - b.tokens) / r.rate * float64(time.Second))
+	if wait <= 0 {
+		wait = time.Nanosecond
+	}
+	return wait, nil
+}
+
+func (r *localTokenBuckets) Close(ctx context.Context) error {
+	return nil
+}
@@ -296,3 +296,64 @@ interval: 1ns
 	close(startChan)
 	wg.Wait()
 }
+
+func TestLocalTokenBucketConf(t *testing.T) {
+	conf, err := localRatelimitConfig().ParseYAML(`rate: 2.5`, nil)
+	require.NoError(t, err)
+
+	rl, err := newLocalTokenBucketsFromConfig(conf)
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, rl.rate)
+	assert.Equal(t, float64(3), rl.burst)
+
+	conf, err = localRatelimitConfig().ParseYAML(`
+rate: 10
+burst: 0
+`, nil)
+	require.NoError(t, err)
+
+	_, err = newLocalTokenBucketsFromConfig(conf)
+	require.Error(t, err)
+}
+
+func TestLocalTokenBucketKeyed(t *testing.T) {
+	rl, err := newLocalTokenBuckets(10, 2)
+	require.NoError(t, err)
+
+	now := time.Now()
+	rl.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	// Each key starts with a full bucket.
+	for _, key := range []string{"foo", "bar", "foo", "bar"} {
+		period, err := rl.AccessKey(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), period, key)
+	}
+
+	period, err := rl.AccessKey(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*100, period)
+
+	// Unkeyed access has its own bucket.
+	period, err = rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	// Tokens are refilled at the rate.
+	now = now.Add(time.Millisecond * 150)
+	period, err = rl.AccessKey(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	period, err = rl.AccessKey(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*50, period)
+
+	// Full buckets are swept after a while.
+	now = now.Add(time.Minute * 2)
+	_, err = rl.AccessKey(ctx, "baz")
+	require.NoError(t, err)
+	assert.Len(t, rl.buckets, 1)
+}
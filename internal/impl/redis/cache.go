@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -251,3 +252,165 @@ func (r *redisCache) Delete(ctx context.Context, key string) error {
 func (r *redisCache) Close(ctx context.Context) error {
 	return r.client.Close()
 }
+
+// retry calls fn until it succeeds, the backoff is exhausted or the context is
+// cancelled.
+func (r *redisCache) retry(ctx context.Context, fn func() error) error {
+	boff := r.boffPool.Get().(backoff.BackOff)
+	defer func() {
+		boff.Reset()
+		r.boffPool.Put(boff)
+	}()
+
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		// Errors returned by the server, such as incrementing a value that
+		// isn't an integer, will not succeed on a retry.
+		var rErr redis.Error
+		if errors.As(err, &rErr) {
+			return err
+		}
+
+		wait := boff.NextBackOff()
+		if wait == backoff.Stop {
+			return err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (r *redisCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	res := make(map[string][]byte, len(keys))
+	err := r.retry(ctx, func() error {
+		// A pipeline is used rather than MGET as the keys may span multiple
+		// slots when using a cluster.
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.StringCmd, len(keys))
+		for i, k := range keys {
+			cmds[i] = pipe.Get(ctx, r.prefix+k)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for i, cmd := range cmds {
+			v, err := cmd.Bytes()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			res[keys[i]] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+var incrementScript = redis.NewScript(`
+local exists = redis.call("EXISTS", KEYS[1])
+local v = redis.call("INCRBY", KEYS[1], ARGV[1])
+if exists == 0 and tonumber(ARGV[2]) > 0 then
+  redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return v
+`)
+
+func (r *redisCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	t := r.defaultTTL
+	if ttl != nil {
+		t = *ttl
+	}
+
+	var v int64
+	err := r.retry(ctx, func() (err error) {
+		v, err = incrementScript.Run(ctx, r.client, []string{r.prefix + key}, delta, t.Milliseconds()).Int64()
+		return
+	})
+	return v, err
+}
+
+var compareAndSwapScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if ARGV[1] == "1" then
+  if current ~= ARGV[2] then
+    return 0
+  end
+elseif current then
+  return 0
+end
+if tonumber(ARGV[4]) > 0 then
+  redis.call("SET", KEYS[1], ARGV[3], "PX", ARGV[4])
+else
+  redis.call("SET", KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+func (r *redisCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	t := r.defaultTTL
+	if ttl != nil {
+		t = *ttl
+	}
+
+	expectExists := "0"
+	if old != nil {
+		expectExists = "1"
+	}
+
+	var swapped int64
+	if err := r.retry(ctx, func() (err error) {
+		swapped, err = compareAndSwapScript.Run(ctx, r.client, []string{r.prefix + key}, expectExists, old, value, t.Milliseconds()).Int64()
+		return
+	}); err != nil {
+		return err
+	}
+	if swapped == 0 {
+		return service.ErrValueMismatch
+	}
+	return nil
+}
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+func (r *redisCache) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	match := globEscaper.Replace(r.prefix+prefix) + "*"
+
+	var keysMut sync.Mutex
+	var keys []string
+	scanNode := func(ctx context.Context, c redis.Cmdable) error {
+		iter := c.Scan(ctx, 0, match, 0).Iterator()
+		for iter.Next(ctx) {
+			keysMut.Lock()
+			keys = append(keys, strings.TrimPrefix(iter.Val(), r.prefix))
+			keysMut.Unlock()
+		}
+		return iter.Err()
+	}
+
+	if err := r.retry(ctx, func() error {
+		keys = nil
+		if cc, ok := r.client.(*redis.ClusterClient); ok {
+			return cc.ForEachMaster(ctx, func(ctx context.Context, c *redis.Client) error {
+				return scanNode(ctx, c)
+			})
+		}
+		return scanNode(ctx, r.client)
+	}); err != nil {
+		return nil, err
+	}
+
+	// Keys that expire between the scan and the get are omitted.
+	return r.GetMulti(ctx, keys...)
+}
@@ -61,6 +61,10 @@ cache_resources:
 		integration.CacheTestDoubleAdd(),
 		integration.CacheTestDelete(),
 		integration.CacheTestGetAndSet(50),
+		integration.CacheTestGetMulti(10),
+		integration.CacheTestIncrement(),
+		integration.CacheTestCompareAndSwap(),
+		integration.CacheTestScan(10),
 	)
 	suite.Run(
 		t, template,
@@ -154,6 +158,10 @@ cache_resources:
 		integration.CacheTestDoubleAdd(),
 		integration.CacheTestDelete(),
 		integration.CacheTestGetAndSet(50),
+		integration.CacheTestGetMulti(10),
+		integration.CacheTestIncrement(),
+		integration.CacheTestCompareAndSwap(),
+		integration.CacheTestScan(10),
 	)
 	suite.Run(
 		t, template,
@@ -267,6 +275,10 @@ cache_resources:
 		integration.CacheTestDoubleAdd(),
 		integration.CacheTestDelete(),
 		integration.CacheTestGetAndSet(50),
+		integration.CacheTestGetMulti(10),
+		integration.CacheTestIncrement(),
+		integration.CacheTestCompareAndSwap(),
+		integration.CacheTestScan(10),
 	)
 	suite.Run(
 		t, template,
@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	rlFieldPrefix = "prefix"
+	rlFieldTTL    = "ttl"
+)
+
+func redisLockConfig() *service.ConfigSpec {
+	spec := service.NewConfigSpec().
+		Beta().
+		Summary(`Grants leases on named locks stored as keys within Redis, allowing multiple instances of Bento to coordinate.`).
+		Description(`
+Each named lock is stored as a key consisting of the configured prefix followed by the lock name, with a value unique to the lease holder. Leases expire unless they are renewed within the configured TTL, and therefore a lock held by an instance that dies is freed once the TTL has passed.
+
+When Redis is deployed with replication it is possible for a lease to be lost during a failover before it has been replicated, and therefore this lock provides best effort mutual exclusion.`)
+
+	for _, f := range clientFields() {
+		spec = spec.Field(f)
+	}
+
+	return spec.
+		Field(service.NewStringField(rlFieldPrefix).
+			Description("A prefix to add to the names of locks in order to obtain their keys.").
+			Default("bento_lock:")).
+		Field(service.NewDurationField(rlFieldTTL).
+			Description("The duration after which a lease expires unless it is renewed. Lower durations allow locks held by instances that have died to be acquired sooner at the cost of more frequent renewals.").
+			Default("10s"))
+}
+
+func init() {
+	err := service.RegisterLock(
+		"redis", redisLockConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+			return newRedisLockFromConfig(conf)
+		})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// renewLockScript extends the expiry of a lock only when it is still held by
+// the lease with the provided token.
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], tonumber(ARGV[2]))
+end
+return 0
+`)
+
+// releaseLockScript deletes a lock only when it is still held by the lease
+// with the provided token.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type redisLock struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+func newRedisLockFromConfig(conf *service.ParsedConfig) (*redisLock, error) {
+	client, err := getClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := conf.FieldString(rlFieldPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := conf.FieldDuration(rlFieldTTL)
+	if err != nil {
+		return nil, err
+	}
+	if ttl < time.Millisecond {
+		return nil, errors.New("ttl must be at least one millisecond")
+	}
+
+	return &redisLock{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}, nil
+}
+
+func (r *redisLock) Acquire(ctx context.Context, name string) (service.LockLease, error) {
+	token, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	key := r.prefix + name
+	acquired, err := r.client.SetNX(ctx, key, token.String(), r.ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("acquiring redis lock: %w", err)
+	}
+	if !acquired {
+		return nil, service.ErrLockHeld
+	}
+	return &redisLease{r: r, key: key, token: token.String()}, nil
+}
+
+func (r *redisLock) Close(ctx context.Context) error {
+	return r.client.Close()
+}
+
+type redisLease struct {
+	r     *redisLock
+	key   string
+	token string
+}
+
+func (l *redisLease) TTL() time.Duration {
+	return l.r.ttl
+}
+
+func (l *redisLease) Renew(ctx context.Context) error {
+	renewed, err := renewLockScript.Run(ctx, l.r.client, []string{l.key}, l.token, l.r.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("renewing redis lock: %w", err)
+	}
+	if renewed == 0 {
+		return service.ErrLeaseLost
+	}
+	return nil
+}
+
+func (l *redisLease) Release(ctx context.Context) error {
+	if err := releaseLockScript.Run(ctx, l.r.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("releasing redis lock: %w", err)
+	}
+	return nil
+}
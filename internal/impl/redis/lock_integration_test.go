@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+	"github.com/warpstreamlabs/bento/public/service/integration"
+)
+
+func TestIntegrationRedisLock(t *testing.T) {
+	integration.CheckSkip(t)
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Skipf("Could not connect to docker: %s", err)
+	}
+	pool.MaxWait = time.Second * 30
+
+	resource, err := pool.Run("redis", "latest", nil)
+	if err != nil {
+		t.Fatalf("Could not start resource: %s", err)
+	}
+
+	urlStr := fmt.Sprintf("tcp://localhost:%v", resource.GetPort("6379/tcp"))
+	uri, err := url.Parse(urlStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:    uri.Host,
+		Network: uri.Scheme,
+	})
+
+	ctx := context.Background()
+	if err = pool.Retry(func() error {
+		return client.Ping(ctx).Err()
+	}); err != nil {
+		t.Fatalf("Could not connect to docker resource: %s", err)
+	}
+
+	defer func() {
+		if err = pool.Purge(resource); err != nil {
+			t.Logf("Failed to clean up docker resource: %v", err)
+		}
+	}()
+
+	defer client.Close()
+
+	newLock := func() *redisLock {
+		conf, err := redisLockConfig().ParseYAML(`
+ttl: 500ms
+url: `+urlStr, nil)
+		require.NoError(t, err)
+
+		l, err := newRedisLockFromConfig(conf)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = l.Close(ctx)
+		})
+		return l
+	}
+
+	first, second := newLock(), newLock()
+
+	lease, err := first.Acquire(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*500, lease.TTL())
+
+	_, err = second.Acquire(ctx, "foo")
+	assert.Equal(t, service.ErrLockHeld, err)
+
+	require.NoError(t, lease.Renew(ctx))
+	require.NoError(t, lease.Release(ctx))
+	assert.Equal(t, service.ErrLeaseLost, lease.Renew(ctx))
+
+	// A lease that is not renewed expires and may be taken by another owner.
+	_, err = second.Acquire(ctx, "foo")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		lease, err = first.Acquire(ctx, "foo")
+		return err == nil
+	}, time.Second*5, time.Millisecond*50)
+	require.NoError(t, lease.Release(ctx))
+}
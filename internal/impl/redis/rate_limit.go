@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,6 +15,14 @@ import (
 func redisRatelimitConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
 		Summary(`A rate limit implementation using Redis. It works by using a simple token bucket algorithm to limit the number of requests to a given count within a given time period. The rate limit is shared across all instances of Bento that use the same Redis instance, which must all have a consistent count and interval.`).
+		Description(`
+### Token Bucket
+
+When the field ` + "`rate`" + ` is set the rate limit instead uses token bucket semantics, where tokens are added at ` + "`rate`" + ` per second up to a maximum of ` + "`burst`" + `, and each access consumes a token. In this mode the fields ` + "`count`" + ` and ` + "`interval`" + ` are ignored.
+
+### Keys
+
+This rate limit supports keys, where each key is limited independently by appending it to the field ` + "`key`" + `, separated by a colon. This allows a single rate limit resource to limit each tenant independently when used with components that have a ` + "`key`" + ` field, such as the ` + "[`rate_limit` processor](/docs/components/processors/rate_limit)" + `.`).
 		Version("1.0.0")
 
 	for _, f := range clientFields() {
@@ -27,7 +36,15 @@ func redisRatelimitConfig() *service.ConfigSpec {
 			Description("The time window to limit requests by.").
 			Default("1s")).
 		Field(service.NewStringField("key").
-			Description("The key to use for the rate limit."))
+			Description("The key to use for the rate limit.")).
+		Field(service.NewFloatField("rate").
+			Description("The number of tokens added per second when using token bucket semantics. When set the fields `count` and `interval` are ignored.").
+			Optional().
+			LintRule(`root = if this <= 0 { [ "rate must be larger than zero" ] }`)).
+		Field(service.NewIntField("burst").
+			Description("The maximum number of tokens held by a bucket when using token bucket semantics, defaults to `rate` rounded up.").
+			Optional().
+			LintRule(`root = if this <= 0 { [ "burst must be larger than zero" ] }`))
 
 	return spec
 }
@@ -50,6 +67,9 @@ type redisRatelimit struct {
 	key    string
 	period time.Duration
 
+	rate  float64
+	burst int
+
 	client redis.UniversalClient
 
 	accessScript *redis.Script
@@ -80,12 +100,34 @@ func newRedisRatelimitFromConfig(conf *service.ParsedConfig) (*redisRatelimit, e
 		return nil, errors.New("count must be larger than zero")
 	}
 
-	return &redisRatelimit{
+	r := &redisRatelimit{
 		size:   count,
 		period: interval,
 		client: client,
 		key:    key,
-		accessScript: redis.NewScript(`
+	}
+
+	if conf.Contains("rate") {
+		if r.rate, err = conf.FieldFloat("rate"); err != nil {
+			return nil, err
+		}
+		if r.rate <= 0 {
+			return nil, errors.New("rate must be larger than zero")
+		}
+		r.burst = int(math.Ceil(r.rate))
+		if conf.Contains("burst") {
+			if r.burst, err = conf.FieldInt("burst"); err != nil {
+				return nil, err
+			}
+		}
+		if r.burst <= 0 {
+			return nil, errors.New("burst must be larger than zero")
+		}
+		r.accessScript = redis.NewScript(tokenBucketScript)
+		return r, nil
+	}
+
+	r.accessScript = redis.NewScript(`
 local current = redis.call("INCR",KEYS[1])
 
 if current == 1 then
@@ -97,14 +139,63 @@ if current > tonumber(ARGV[1]) then
 end
 
 return 0
-`),
-	}, nil
+`)
+	return r, nil
 }
 
+// tokenBucketScript refills a bucket according to the time elapsed since it
+// was last accessed and then attempts to consume a token, returning zero on
+// success or the milliseconds until a token is available. Idle buckets expire
+// once they would have refilled completely.
+const tokenBucketScript = `
+redis.replicate_commands()
+
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.max(1, math.ceil((1 - tokens) * 1000 / rate))
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+
+return wait
+`
+
 //------------------------------------------------------------------------------
 
 func (r *redisRatelimit) Access(ctx context.Context) (time.Duration, error) {
-	result := r.accessScript.Run(ctx, r.client, []string{r.key}, r.size, int(r.period.Milliseconds()))
+	return r.access(ctx, r.key)
+}
+
+func (r *redisRatelimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return r.access(ctx, r.key+":"+key)
+}
+
+func (r *redisRatelimit) access(ctx context.Context, key string) (time.Duration, error) {
+	var result *redis.Cmd
+	if r.rate > 0 {
+		result = r.accessScript.Run(ctx, r.client, []string{key}, r.rate, r.burst)
+	} else {
+		result = r.accessScript.Run(ctx, r.client, []string{key}, r.size, int(r.period.Milliseconds()))
+	}
 
 	if result.Err() != nil {
 		return 0, fmt.Errorf("accessing redis rate limit: %w", result.Err())
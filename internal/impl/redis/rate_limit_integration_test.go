@@ -63,6 +63,42 @@ func TestIntegrationRedisRateLimit(t *testing.T) {
 	t.Run("testRedisRateLimitRefresh", func(t *testing.T) {
 		testRedisRateLimitRefresh(t, urlStr)
 	})
+
+	t.Run("testRedisRateLimitTokenBucketKeyed", func(t *testing.T) {
+		testRedisRateLimitTokenBucketKeyed(t, urlStr)
+	})
+}
+
+func testRedisRateLimitTokenBucketKeyed(t *testing.T, url string) {
+	conf, err := redisRatelimitConfig().ParseYAML(`
+key: rate_limit_token_bucket
+rate: 10
+burst: 2
+url: `+url, nil)
+	require.NoError(t, err)
+
+	rl, err := newRedisRatelimitFromConfig(conf)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// Each key starts with a full bucket.
+	for _, key := range []string{"foo", "bar", "foo", "bar"} {
+		period, err := rl.AccessKey(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), period, key)
+	}
+
+	period, err := rl.AccessKey(ctx, "foo")
+	require.NoError(t, err)
+	assert.Greater(t, period, time.Duration(0))
+	assert.LessOrEqual(t, period, time.Millisecond*100)
+
+	<-time.After(period)
+
+	period, err = rl.AccessKey(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
 }
 
 func testRedisRateLimitBasic(t *testing.T, url string) {
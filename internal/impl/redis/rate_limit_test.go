@@ -30,4 +30,27 @@ key: asdf`, nil)
 
 	_, err = redisRatelimitConfig().ParseYAML(`url: redis://localhost:6379`, nil)
 	require.Error(t, err)
+
+	conf, err = redisRatelimitConfig().ParseYAML(`
+url: redis://localhost:6379
+key: asdf
+rate: 10
+burst: 0`, nil)
+	require.NoError(t, err)
+
+	_, err = newRedisRatelimitFromConfig(conf)
+	require.Error(t, err)
+}
+
+func TestRedisRateLimitTokenBucketConf(t *testing.T) {
+	conf, err := redisRatelimitConfig().ParseYAML(`
+url: redis://localhost:6379
+key: asdf
+rate: 2.5`, nil)
+	require.NoError(t, err)
+
+	rl, err := newRedisRatelimitFromConfig(conf)
+	require.NoError(t, err)
+	require.Equal(t, 2.5, rl.rate)
+	require.Equal(t, 3, rl.burst)
 }
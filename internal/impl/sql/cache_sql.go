@@ -1,9 +1,12 @@
 package sql
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -52,6 +55,14 @@ This will behave as an ` + "`add`" + ` operation by default, and so ideally need
 ### Add
 
 The ` + "`add`" + ` operation is performed with a traditional ` + "`insert`" + ` statement.
+
+### Compare and Swap
+
+A ` + "`compare_and_swap`" + ` operation is performed with an ` + "`update`" + ` statement conditional on the current value, or an ` + "`insert`" + ` statement when the key is expected not to exist. Increments are performed with the same statements, retrying when the value is modified concurrently.
+
+### Scan
+
+A ` + "`scan`" + ` operation is performed with a ` + "`select`" + ` statement and a ` + "`like`" + ` condition on the key column.
 `).
 		Field(driverField).
 		Field(dsnField).
@@ -96,12 +107,15 @@ type sqlCache struct {
 	dsn    string
 	db     *sql.DB
 
-	keyColumn string
+	keyColumn   string
+	valueColumn string
 
-	selectBuilder squirrel.SelectBuilder
-	insertBuilder squirrel.InsertBuilder
-	upsertBuilder squirrel.InsertBuilder
-	deleteBuilder squirrel.DeleteBuilder
+	selectBuilder      squirrel.SelectBuilder
+	selectMultiBuilder squirrel.SelectBuilder
+	insertBuilder      squirrel.InsertBuilder
+	upsertBuilder      squirrel.InsertBuilder
+	updateBuilder      squirrel.UpdateBuilder
+	deleteBuilder      squirrel.DeleteBuilder
 
 	awsConf aws.Config
 
@@ -134,26 +148,31 @@ func newSQLCacheFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (
 		return nil, err
 	}
 
-	valueColumn, err := conf.FieldString(cacheValueColumnField)
-	if err != nil {
+	if s.valueColumn, err = conf.FieldString(cacheValueColumnField); err != nil {
 		return nil, err
 	}
 
-	s.selectBuilder = squirrel.Select(valueColumn).From(tableStr)
-	s.insertBuilder = squirrel.Insert(tableStr).Columns(s.keyColumn, valueColumn)
-	s.upsertBuilder = squirrel.Insert(tableStr).Columns(s.keyColumn, valueColumn)
+	s.selectBuilder = squirrel.Select(s.valueColumn).From(tableStr)
+	s.selectMultiBuilder = squirrel.Select(s.keyColumn, s.valueColumn).From(tableStr)
+	s.insertBuilder = squirrel.Insert(tableStr).Columns(s.keyColumn, s.valueColumn)
+	s.upsertBuilder = squirrel.Insert(tableStr).Columns(s.keyColumn, s.valueColumn)
+	s.updateBuilder = squirrel.Update(tableStr)
 	s.deleteBuilder = squirrel.Delete(tableStr)
 
 	switch s.driver {
 	case "postgres", "clickhouse":
 		s.selectBuilder = s.selectBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.selectMultiBuilder = s.selectMultiBuilder.PlaceholderFormat(squirrel.Dollar)
 		s.insertBuilder = s.insertBuilder.PlaceholderFormat(squirrel.Dollar)
 		s.upsertBuilder = s.upsertBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.updateBuilder = s.updateBuilder.PlaceholderFormat(squirrel.Dollar)
 		s.deleteBuilder = s.deleteBuilder.PlaceholderFormat(squirrel.Dollar)
 	case "oracle", "gocosmos":
 		s.selectBuilder = s.selectBuilder.PlaceholderFormat(squirrel.Colon)
+		s.selectMultiBuilder = s.selectMultiBuilder.PlaceholderFormat(squirrel.Colon)
 		s.insertBuilder = s.insertBuilder.PlaceholderFormat(squirrel.Colon)
 		s.upsertBuilder = s.upsertBuilder.PlaceholderFormat(squirrel.Colon)
+		s.updateBuilder = s.updateBuilder.PlaceholderFormat(squirrel.Colon)
 		s.deleteBuilder = s.deleteBuilder.PlaceholderFormat(squirrel.Colon)
 	}
 
@@ -232,6 +251,111 @@ func (s *sqlCache) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+func (s *sqlCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if len(keys) == 0 {
+		return map[string][]byte{}, nil
+	}
+	return s.selectMulti(ctx, squirrel.Eq{s.keyColumn: keys})
+}
+
+func (s *sqlCache) selectMulti(ctx context.Context, pred any) (map[string][]byte, error) {
+	rows, err := s.selectMultiBuilder.Where(pred).RunWith(s.db).QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := map[string][]byte{}
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		res[key] = value
+	}
+	return res, rows.Err()
+}
+
+func (s *sqlCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	// Optimistically swap the current value until no concurrent modification
+	// has occurred in between.
+	for {
+		var old []byte
+		var current int64
+
+		b, err := s.Get(ctx, key)
+		if err == nil {
+			if current, err = strconv.ParseInt(string(b), 10, 64); err != nil {
+				return 0, fmt.Errorf("value is not an integer: %w", err)
+			}
+			old = b
+		} else if !errors.Is(err, service.ErrKeyNotFound) {
+			return 0, err
+		}
+
+		current += delta
+		err = s.CompareAndSwap(ctx, key, old, strconv.AppendInt(nil, current, 10), ttl)
+		if err == nil {
+			return current, nil
+		}
+		if !errors.Is(err, service.ErrValueMismatch) {
+			return 0, err
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (s *sqlCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	if old == nil {
+		if _, err := s.insertBuilder.Values(key, value).RunWith(s.db).ExecContext(ctx); err != nil {
+			// Collisions are reported differently by each SQL engine, and so we
+			// check whether the key now exists instead.
+			if _, gErr := s.Get(ctx, key); gErr == nil {
+				return service.ErrValueMismatch
+			}
+			return err
+		}
+		return nil
+	}
+
+	if bytes.Equal(old, value) {
+		// Some engines report zero affected rows when the value is unchanged.
+		current, err := s.Get(ctx, key)
+		if err != nil && !errors.Is(err, service.ErrKeyNotFound) {
+			return err
+		}
+		if err != nil || !bytes.Equal(current, old) {
+			return service.ErrValueMismatch
+		}
+		return nil
+	}
+
+	res, err := s.updateBuilder.
+		Set(s.valueColumn, value).
+		Where(squirrel.Eq{s.keyColumn: key, s.valueColumn: old}).
+		RunWith(s.db).ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return service.ErrValueMismatch
+	}
+	return nil
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func (s *sqlCache) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	return s.selectMulti(ctx, squirrel.Expr(s.keyColumn+" LIKE ? ESCAPE '!'", likeEscaper.Replace(prefix)+"%"))
+}
+
 func (s *sqlCache) Close(ctx context.Context) error {
 	s.shutSig.TriggerHardStop()
 	select {
@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func TestSQLCacheOptionalOperations(t *testing.T) {
+	ctx := context.Background()
+	dsn := "file:" + filepath.Join(t.TempDir(), "cache.db")
+
+	conf, err := sqlCacheConfig().ParseYAML(fmt.Sprintf(`
+driver: sqlite
+dsn: %v
+table: footable
+key_column: foo
+value_column: bar
+set_suffix: ON CONFLICT (foo) DO UPDATE SET bar=excluded.bar
+init_statement: CREATE TABLE footable (foo TEXT PRIMARY KEY, bar BLOB)
+`, dsn), nil)
+	require.NoError(t, err)
+
+	c, err := newSQLCacheFromConfig(conf, service.MockResources())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, c.Close(ctx))
+	})
+
+	for k, v := range map[string]string{
+		"foo":     "bar",
+		"foz":     "baz",
+		"bar":     "buz",
+		"f%o_":    "wild",
+		"fo!":     "bang",
+		"counter": "10",
+	} {
+		require.NoError(t, c.Set(ctx, k, []byte(v), nil))
+	}
+
+	res, err := c.GetMulti(ctx, "foo", "bar", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("bar"), "bar": []byte("buz")}, res)
+
+	res, err = c.Scan(ctx, "fo")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("bar"), "foz": []byte("baz"), "fo!": []byte("bang")}, res)
+
+	// Wildcards within the prefix are matched literally.
+	res, err = c.Scan(ctx, "f%")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"f%o_": []byte("wild")}, res)
+
+	v, err := c.Increment(ctx, "counter", 5, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), v)
+
+	v, err = c.Increment(ctx, "newcounter", -2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-2), v)
+
+	_, err = c.Increment(ctx, "foo", 1, nil)
+	require.Error(t, err)
+
+	require.ErrorIs(t, c.CompareAndSwap(ctx, "foo", []byte("nope"), []byte("qux"), nil), service.ErrValueMismatch)
+	require.ErrorIs(t, c.CompareAndSwap(ctx, "foo", nil, []byte("qux"), nil), service.ErrValueMismatch)
+	require.ErrorIs(t, c.CompareAndSwap(ctx, "new", []byte("bar"), []byte("qux"), nil), service.ErrValueMismatch)
+	require.NoError(t, c.CompareAndSwap(ctx, "foo", []byte("bar"), []byte("bar"), nil))
+	require.NoError(t, c.CompareAndSwap(ctx, "foo", []byte("bar"), []byte("qux"), nil))
+	require.NoError(t, c.CompareAndSwap(ctx, "new", nil, []byte("quz"), nil))
+
+	res, err = c.GetMulti(ctx, "foo", "new")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("qux"), "new": []byte("quz")}, res)
+}
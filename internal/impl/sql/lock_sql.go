@@ -0,0 +1,261 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid"
+
+	"github.com/Jeffail/shutdown"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const (
+	lockNameColumnField    = "name_column"
+	lockOwnerColumnField   = "owner_column"
+	lockExpiresColumnField = "expires_column"
+	lockTTLField           = "ttl"
+)
+
+func sqlLockConfig() *service.ConfigSpec {
+	spec := service.NewConfigSpec().
+		Beta().
+		Categories("Services").
+		Summary("Grants leases on named locks stored as rows within an SQL database table, allowing multiple instances of Bento to coordinate.").
+		Description(`
+Each named lock held is a row within the specified table, consisting of the lock name, a value unique to the lease holder, and the time at which the lease expires as an integer number of milliseconds since the unix epoch. The name column must be a primary key or have a unique constraint. Leases expire unless they are renewed within the configured TTL, and therefore a lock held by an instance that dies can be acquired by another once the TTL has passed.
+
+Lease expiry is calculated from the clocks of the instances sharing a lock, and therefore the clocks of these instances should be kept in sync.
+
+Lock operations are translated into SQL statements as follows:
+
+### Acquire
+
+An ` + "`update`" + ` statement takes over the row of the lock when it has expired, and when no such row exists an ` + "`insert`" + ` statement is attempted. If the insert fails because another owner holds the lock the error is not reported.
+
+### Renew
+
+An ` + "`update`" + ` statement extends the expiry of the row conditional on the owner column matching the lease holder.
+
+### Release
+
+A ` + "`delete`" + ` statement removes the row conditional on the owner column matching the lease holder.`).
+		Field(driverField).
+		Field(dsnField).
+		Field(service.NewStringField("table").
+			Description("The table within which to store locks.").
+			Example("bento_locks")).
+		Field(service.NewStringField(lockNameColumnField).
+			Description("The name of a column to be used for storing lock names.").
+			Default("name")).
+		Field(service.NewStringField(lockOwnerColumnField).
+			Description("The name of a column to be used for storing a value unique to the holder of a lease.").
+			Default("owner")).
+		Field(service.NewStringField(lockExpiresColumnField).
+			Description("The name of a column to be used for storing the time at which a lease expires, as an integer number of milliseconds since the unix epoch.").
+			Default("expires_at")).
+		Field(service.NewDurationField(lockTTLField).
+			Description("The duration after which a lease expires unless it is renewed. Lower durations allow locks held by instances that have died to be acquired sooner at the cost of more frequent renewals.").
+			Default("10s")).
+		LintRule(SQLConnLintRule)
+
+	for _, f := range connFields() {
+		spec = spec.Field(f)
+	}
+	return spec
+}
+
+func init() {
+	err := service.RegisterLock("sql", sqlLockConfig(), func(conf *service.ParsedConfig, mgr *service.Resources) (service.Lock, error) {
+		return newSQLLockFromConfig(conf, mgr)
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type sqlLock struct {
+	db  *sql.DB
+	ttl time.Duration
+
+	nameColumn    string
+	ownerColumn   string
+	expiresColumn string
+
+	selectBuilder squirrel.SelectBuilder
+	insertBuilder squirrel.InsertBuilder
+	updateBuilder squirrel.UpdateBuilder
+	deleteBuilder squirrel.DeleteBuilder
+
+	shutSig *shutdown.Signaller
+}
+
+func newSQLLockFromConfig(conf *service.ParsedConfig, mgr *service.Resources) (*sqlLock, error) {
+	s := &sqlLock{
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	driver, err := conf.FieldString("driver")
+	if err != nil {
+		return nil, err
+	}
+
+	dsn, err := conf.FieldString("dsn")
+	if err != nil {
+		return nil, err
+	}
+
+	tableStr, err := conf.FieldString("table")
+	if err != nil {
+		return nil, err
+	}
+
+	if s.nameColumn, err = conf.FieldString(lockNameColumnField); err != nil {
+		return nil, err
+	}
+	if s.ownerColumn, err = conf.FieldString(lockOwnerColumnField); err != nil {
+		return nil, err
+	}
+	if s.expiresColumn, err = conf.FieldString(lockExpiresColumnField); err != nil {
+		return nil, err
+	}
+
+	if s.ttl, err = conf.FieldDuration(lockTTLField); err != nil {
+		return nil, err
+	}
+	if s.ttl < time.Millisecond {
+		return nil, errors.New("ttl must be at least one millisecond")
+	}
+
+	s.selectBuilder = squirrel.Select(s.ownerColumn).From(tableStr)
+	s.insertBuilder = squirrel.Insert(tableStr).Columns(s.nameColumn, s.ownerColumn, s.expiresColumn)
+	s.updateBuilder = squirrel.Update(tableStr)
+	s.deleteBuilder = squirrel.Delete(tableStr)
+
+	switch driver {
+	case "postgres", "clickhouse":
+		s.selectBuilder = s.selectBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.insertBuilder = s.insertBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.updateBuilder = s.updateBuilder.PlaceholderFormat(squirrel.Dollar)
+		s.deleteBuilder = s.deleteBuilder.PlaceholderFormat(squirrel.Dollar)
+	case "oracle", "gocosmos":
+		s.selectBuilder = s.selectBuilder.PlaceholderFormat(squirrel.Colon)
+		s.insertBuilder = s.insertBuilder.PlaceholderFormat(squirrel.Colon)
+		s.updateBuilder = s.updateBuilder.PlaceholderFormat(squirrel.Colon)
+		s.deleteBuilder = s.deleteBuilder.PlaceholderFormat(squirrel.Colon)
+	}
+
+	connSettings, err := connSettingsFromParsed(conf, mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.db, err = sqlOpenWithReworks(context.Background(), mgr.Logger(), driver, dsn, connSettings); err != nil {
+		return nil, err
+	}
+	connSettings.apply(context.Background(), s.db, mgr.Logger())
+
+	go func() {
+		<-s.shutSig.HardStopChan()
+		_ = s.db.Close()
+		s.shutSig.TriggerHasStopped()
+	}()
+	return s, nil
+}
+
+func (s *sqlLock) expiresAt() int64 {
+	return time.Now().Add(s.ttl).UnixMilli()
+}
+
+func (s *sqlLock) Acquire(ctx context.Context, name string) (service.LockLease, error) {
+	token, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	lease := &sqlLease{s: s, name: name, owner: token.String()}
+
+	// Take over the lock if it exists and has expired.
+	res, err := s.updateBuilder.
+		Set(s.ownerColumn, lease.owner).
+		Set(s.expiresColumn, s.expiresAt()).
+		Where(squirrel.Eq{s.nameColumn: name}).
+		Where(squirrel.Lt{s.expiresColumn: time.Now().UnixMilli()}).
+		RunWith(s.db).ExecContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n > 0 {
+		return lease, nil
+	}
+
+	if _, err = s.insertBuilder.
+		Values(name, lease.owner, s.expiresAt()).
+		RunWith(s.db).ExecContext(ctx); err == nil {
+		return lease, nil
+	}
+
+	// Each SQL engine reports collisions differently, and therefore we check
+	// whether the insert failed due to the lock being held.
+	var owner string
+	if rowErr := s.selectBuilder.
+		Where(squirrel.Eq{s.nameColumn: name}).
+		RunWith(s.db).QueryRowContext(ctx).
+		Scan(&owner); rowErr == nil {
+		return nil, service.ErrLockHeld
+	}
+	return nil, err
+}
+
+func (s *sqlLock) Close(ctx context.Context) error {
+	s.shutSig.TriggerHardStop()
+	select {
+	case <-s.shutSig.HasStoppedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+type sqlLease struct {
+	s     *sqlLock
+	name  string
+	owner string
+}
+
+func (l *sqlLease) TTL() time.Duration {
+	return l.s.ttl
+}
+
+func (l *sqlLease) Renew(ctx context.Context) error {
+	res, err := l.s.updateBuilder.
+		Set(l.s.expiresColumn, l.s.expiresAt()).
+		Where(squirrel.Eq{l.s.nameColumn: l.name, l.s.ownerColumn: l.owner}).
+		RunWith(l.s.db).ExecContext(ctx)
+	if err != nil {
+		return fmt.Errorf("renewing sql lock: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return service.ErrLeaseLost
+	}
+	return nil
+}
+
+func (l *sqlLease) Release(ctx context.Context) error {
+	_, err := l.s.deleteBuilder.
+		Where(squirrel.Eq{l.s.nameColumn: l.name, l.s.ownerColumn: l.owner}).
+		RunWith(l.s.db).ExecContext(ctx)
+	return err
+}
@@ -0,0 +1,70 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+func TestSQLLock(t *testing.T) {
+	ctx := context.Background()
+	dsn := "file:" + filepath.Join(t.TempDir(), "locks.db")
+
+	newLock := func(initStatement string) *sqlLock {
+		conf, err := sqlLockConfig().ParseYAML(fmt.Sprintf(`
+driver: sqlite
+dsn: %v
+table: locks
+ttl: 200ms
+init_statement: %q
+`, dsn, initStatement), nil)
+		require.NoError(t, err)
+
+		l, err := newSQLLockFromConfig(conf, service.MockResources())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, l.Close(ctx))
+		})
+		return l
+	}
+
+	first := newLock("CREATE TABLE locks (name TEXT PRIMARY KEY, owner TEXT, expires_at INTEGER)")
+	second := newLock("")
+
+	lease, err := first.Acquire(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond*200, lease.TTL())
+
+	_, err = second.Acquire(ctx, "foo")
+	assert.Equal(t, service.ErrLockHeld, err)
+
+	otherLease, err := second.Acquire(ctx, "bar")
+	require.NoError(t, err)
+	require.NoError(t, otherLease.Release(ctx))
+
+	require.NoError(t, lease.Renew(ctx))
+	require.NoError(t, lease.Release(ctx))
+	assert.Equal(t, service.ErrLeaseLost, lease.Renew(ctx))
+
+	// A lease that is not renewed expires and may be taken by another owner.
+	lease, err = second.Acquire(ctx, "foo")
+	require.NoError(t, err)
+
+	var takenLease service.LockLease
+	assert.Eventually(t, func() bool {
+		takenLease, err = first.Acquire(ctx, "foo")
+		return err == nil
+	}, time.Second*5, time.Millisecond*50)
+
+	assert.Equal(t, service.ErrLeaseLost, lease.Renew(ctx))
+	require.NoError(t, lease.Release(ctx))
+	require.NoError(t, takenLease.Renew(ctx))
+	require.NoError(t, takenLease.Release(ctx))
+}
@@ -11,7 +11,7 @@ import (
 )
 
 func sqlDeprecatedOutputConfig() *service.ConfigSpec {
-	return service.NewConfigSpec().
+	spec := service.NewConfigSpec().
 		Deprecated().
 		Categories("Services").
 		Summary("Executes an arbitrary SQL query for each message.").
@@ -33,6 +33,10 @@ For basic inserts use the ` + "[`sql_insert`](/docs/components/outputs/sql)" + `
 			Default(64)).
 		Field(service.NewBatchPolicyField("batching")).
 		Version("1.0.0")
+
+	return spec.Migration("sql_raw",
+		service.NewMigrationRename("data_source_name", "dsn"),
+	)
 }
 
 func init() {
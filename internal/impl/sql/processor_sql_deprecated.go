@@ -7,7 +7,7 @@ import (
 
 // DeprecatedProcessorConfig returns a config spec for an sql processor.
 func DeprecatedProcessorConfig() *service.ConfigSpec {
-	return service.NewConfigSpec().
+	spec := service.NewConfigSpec().
 		Deprecated().
 		Categories("Integration").
 		Summary("Runs an arbitrary SQL query against a database and (optionally) returns the result as an array of objects, one for each row returned.").
@@ -35,6 +35,14 @@ For basic inserts or select queries use either the ` + "[`sql_insert`](/docs/com
 			Default("none")).
 		Version("1.0.0")
 	// TODO: Add example
+
+	return spec.Migration("sql_raw",
+		service.NewMigrationRename("data_source_name", "dsn"),
+		service.NewMigrationMapValue("result_codec", "exec_only", map[string]any{
+			"none":       true,
+			"json_array": nil,
+		}),
+	)
 }
 
 func init() {
@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/config"
+	"github.com/warpstreamlabs/bento/public/service/integration"
+)
+
+func TestIntegrationVaultSecrets(t *testing.T) {
+	integration.CheckSkip(t)
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+	pool.MaxWait = time.Minute
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository:   "hashicorp/vault",
+		Tag:          "1.17",
+		ExposedPorts: []string{"8200/tcp"},
+		Env: []string{
+			"VAULT_DEV_ROOT_TOKEN_ID=roottoken",
+			"VAULT_DEV_LISTEN_ADDRESS=0.0.0.0:8200",
+		},
+		CapAdd: []string{"IPC_LOCK"},
+	}, func(hostConf *docker.HostConfig) {
+		hostConf.AutoRemove = true
+		hostConf.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, pool.Purge(resource))
+	})
+	_ = resource.Expire(900)
+
+	addr := fmt.Sprintf("http://localhost:%v", resource.GetPort("8200/tcp"))
+
+	// Dev mode mounts a KV version 2 engine at secret/
+	require.NoError(t, pool.Retry(func() error {
+		req, err := http.NewRequest(http.MethodPost, addr+"/v1/secret/data/bento", bytes.NewReader([]byte(`{"data":{"password":"hunter2"}}`)))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("X-Vault-Token", "roottoken")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("status: %v", res.StatusCode)
+		}
+		return nil
+	}))
+
+	t.Setenv("VAULT_ADDR", addr)
+	t.Setenv("VAULT_TOKEN", "roottoken")
+
+	v, err := (&secretProvider{
+		addrFn:      func() string { return addr },
+		tokenFn:     func() string { return "roottoken" },
+		namespaceFn: func() string { return "" },
+		client:      http.DefaultClient,
+	}).Lookup(context.Background(), "secret/data/bento")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password":"hunter2"}`, v)
+
+	res, err := config.ReplaceEnvVariables([]byte(`password: ${secret:vault:secret/data/bento#password}`), func(string) (string, bool) {
+		return "", false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "password: hunter2", string(res))
+}
@@ -0,0 +1,109 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/warpstreamlabs/bento/public/service"
+)
+
+const defaultVaultAddr = "http://127.0.0.1:8200"
+
+func init() {
+	p := &secretProvider{
+		addrFn: func() string {
+			if addr := os.Getenv("VAULT_ADDR"); addr != "" {
+				return addr
+			}
+			return defaultVaultAddr
+		},
+		tokenFn: func() string {
+			return os.Getenv("VAULT_TOKEN")
+		},
+		namespaceFn: func() string {
+			return os.Getenv("VAULT_NAMESPACE")
+		},
+		client: http.DefaultClient,
+	}
+	if err := service.RegisterSecretProvider("vault", p.Lookup); err != nil {
+		panic(err)
+	}
+}
+
+// secretProvider reads secrets from the HTTP API of a Vault server, where the
+// address and credentials are obtained from the standard Vault environment
+// variables at the time of the lookup.
+type secretProvider struct {
+	addrFn      func() string
+	tokenFn     func() string
+	namespaceFn func() string
+	client      *http.Client
+}
+
+type vaultResponse struct {
+	Errors []string        `json:"errors"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Lookup reads a secret at the provided path, which is relative to `/v1/` of
+// the Vault API and therefore for KV version 2 engines must include the `data`
+// segment (e.g. `secret/data/foo`). The data of the secret is returned as a
+// JSON object.
+func (p *secretProvider) Lookup(ctx context.Context, path string) (string, error) {
+	addr := strings.TrimSuffix(p.addrFn(), "/")
+	path = strings.TrimPrefix(path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+path, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	if token := p.tokenFn(); token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if ns := p.namespaceFn(); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	resBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var vRes vaultResponse
+	if err := json.Unmarshal(resBytes, &vRes); err != nil && res.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		if len(vRes.Errors) > 0 {
+			return "", fmt.Errorf("vault returned status %v: %v", res.StatusCode, strings.Join(vRes.Errors, ", "))
+		}
+		return "", fmt.Errorf("vault returned status %v", res.StatusCode)
+	}
+	if len(vRes.Data) == 0 {
+		return "", errors.New("vault response did not contain secret data")
+	}
+
+	// Secrets from a KV version 2 engine are nested within a further data
+	// object alongside the metadata of the secret version.
+	var kvV2 struct {
+		Data     json.RawMessage `json:"data"`
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(vRes.Data, &kvV2); err == nil && len(kvV2.Data) > 0 && len(kvV2.Metadata) > 0 {
+		return string(kvV2.Data), nil
+	}
+	return string(vRes.Data), nil
+}
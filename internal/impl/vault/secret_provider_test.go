@@ -0,0 +1,57 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProvider(addr string) *secretProvider {
+	return &secretProvider{
+		addrFn:      func() string { return addr },
+		tokenFn:     func() string { return "footoken" },
+		namespaceFn: func() string { return "" },
+		client:      http.DefaultClient,
+	}
+}
+
+func TestSecretProviderLookup(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "footoken" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/foo":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2"},"metadata":{"version":1}}}`))
+		case "/v1/kv/bar":
+			_, _ = w.Write([]byte(`{"data":{"user":"bob"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := testProvider(ts.URL + "/")
+
+	v, err := p.Lookup(context.Background(), "secret/data/foo")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password":"hunter2"}`, v)
+
+	v, err = p.Lookup(context.Background(), "/kv/bar")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user":"bob"}`, v)
+
+	_, err = p.Lookup(context.Background(), "nope")
+	require.EqualError(t, err, "vault returned status 404")
+
+	p.tokenFn = func() string { return "" }
+	_, err = p.Lookup(context.Background(), "secret/data/foo")
+	require.EqualError(t, err, "vault returned status 403: permission denied")
+}
@@ -0,0 +1,218 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/cache"
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// cacheAccessor provides access to cache resources for Bloblang functions.
+type cacheAccessor interface {
+	AccessCache(ctx context.Context, name string, fn func(cache.V1)) error
+}
+
+var errNoCacheResources = errors.New("cache resources are not available to this mapping")
+
+type cacheFunction struct {
+	spec query.FunctionSpec
+	ctor func(mgr cacheAccessor, args *query.ParsedParams) (query.Function, error)
+}
+
+// cacheFunctions are registered to the global Bloblang environment so that
+// mappings that use them can be parsed and linted, but they can only be
+// executed within an environment bound to the resources of a manager.
+var cacheFunctions = []cacheFunction{
+	{
+		spec: query.NewFunctionSpec(
+			query.FunctionCategoryEnvironment, "cache_get",
+			"Returns the value of a key from a [`cache` resource](/docs/components/caches/about) as bytes, or `null` if the key does not exist.",
+			query.NewExampleSpec("", `root.user = cache_get("users", this.user_id).parse_json()`),
+		).Experimental().MarkImpure().
+			Param(query.ParamString("resource", "The label of a cache resource.")).
+			Param(query.ParamString("key", "The key to retrieve.")),
+		ctor: func(mgr cacheAccessor, args *query.ParsedParams) (query.Function, error) {
+			resource, key, err := cacheResourceAndString(args, "key")
+			if err != nil {
+				return nil, err
+			}
+			return cacheClosure(mgr, "cache_get", resource, func(c cache.V1) (any, error) {
+				v, err := c.Get(context.Background(), key)
+				if errors.Is(err, component.ErrKeyNotFound) {
+					return nil, nil
+				}
+				return v, err
+			}), nil
+		},
+	},
+	{
+		spec: query.NewFunctionSpec(
+			query.FunctionCategoryEnvironment, "cache_get_multi",
+			"Returns an object of the values of multiple keys from a [`cache` resource](/docs/components/caches/about), where values are strings and keys that do not exist are omitted. Caches that support it retrieve all keys with a single request, otherwise each key is retrieved individually.",
+			query.NewExampleSpec("", `root.users = cache_get_multi("users", this.user_ids).map_each(user -> user.value.parse_json())`),
+		).Experimental().MarkImpure().
+			Param(query.ParamString("resource", "The label of a cache resource.")).
+			Param(query.ParamArray("keys", "An array of keys to retrieve.")),
+		ctor: func(mgr cacheAccessor, args *query.ParsedParams) (query.Function, error) {
+			resource, err := args.FieldString("resource")
+			if err != nil {
+				return nil, err
+			}
+			keysArr, err := args.FieldArray("keys")
+			if err != nil {
+				return nil, err
+			}
+			keys := make([]string, 0, len(keysArr))
+			for _, k := range keysArr {
+				ks, err := value.IGetString(k)
+				if err != nil {
+					return nil, fmt.Errorf("keys: %w", err)
+				}
+				keys = append(keys, ks)
+			}
+			return cacheClosure(mgr, "cache_get_multi", resource, func(c cache.V1) (any, error) {
+				res, err := cache.GetMulti(context.Background(), c, keys...)
+				if err != nil {
+					return nil, err
+				}
+				return cacheResultsToObject(res), nil
+			}), nil
+		},
+	},
+	{
+		spec: query.NewFunctionSpec(
+			query.FunctionCategoryEnvironment, "cache_increment",
+			"Atomically adds a delta to the integer value of a key within a [`cache` resource](/docs/components/caches/about) and returns the result. A key that does not exist is treated as zero, and the TTL is only applied when the key is created. Caches that do not support atomic increments return an error.",
+			query.NewExampleSpec("", `root.count = cache_increment(resource: "counters", key: this.user_id, ttl: "1h")`),
+		).Experimental().MarkImpure().
+			Param(query.ParamString("resource", "The label of a cache resource.")).
+			Param(query.ParamString("key", "The key to increment.")).
+			Param(query.ParamInt64("delta", "The amount to add to the value of the key.").Default(1)).
+			Param(query.ParamString("ttl", "An optional TTL to set for the key when it is created.").Optional()),
+		ctor: func(mgr cacheAccessor, args *query.ParsedParams) (query.Function, error) {
+			resource, key, err := cacheResourceAndString(args, "key")
+			if err != nil {
+				return nil, err
+			}
+			delta, err := args.FieldInt64("delta")
+			if err != nil {
+				return nil, err
+			}
+			ttlStr, err := args.FieldOptionalString("ttl")
+			if err != nil {
+				return nil, err
+			}
+			var ttl *time.Duration
+			if ttlStr != nil && *ttlStr != "" {
+				td, err := time.ParseDuration(*ttlStr)
+				if err != nil {
+					return nil, fmt.Errorf("ttl must be a duration: %w", err)
+				}
+				ttl = &td
+			}
+			return cacheClosure(mgr, "cache_increment", resource, func(c cache.V1) (any, error) {
+				return cache.Increment(context.Background(), c, key, delta, ttl)
+			}), nil
+		},
+	},
+	{
+		spec: query.NewFunctionSpec(
+			query.FunctionCategoryEnvironment, "cache_scan",
+			"Returns an object of all keys and their values from a [`cache` resource](/docs/components/caches/about) where the key begins with a prefix, and where values are strings. Caches that do not support listing keys return an error.",
+			query.NewExampleSpec("", `root.sessions = cache_scan("sessions", this.user_id + ":").keys()`),
+		).Experimental().MarkImpure().
+			Param(query.ParamString("resource", "The label of a cache resource.")).
+			Param(query.ParamString("prefix", "The prefix of keys to list.")),
+		ctor: func(mgr cacheAccessor, args *query.ParsedParams) (query.Function, error) {
+			resource, prefix, err := cacheResourceAndString(args, "prefix")
+			if err != nil {
+				return nil, err
+			}
+			return cacheClosure(mgr, "cache_scan", resource, func(c cache.V1) (any, error) {
+				res, err := cache.Scan(context.Background(), c, prefix)
+				if err != nil {
+					return nil, err
+				}
+				return cacheResultsToObject(res), nil
+			}), nil
+		},
+	},
+}
+
+func init() {
+	env := bloblang.GlobalEnvironment()
+	for _, f := range cacheFunctions {
+		if err := env.RegisterFunction(f.spec, cacheFunctionCtor(f, nil)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func cacheFunctionCtor(f cacheFunction, mgr cacheAccessor) query.FunctionCtor {
+	return func(args *query.ParsedParams) (query.Function, error) {
+		return f.ctor(mgr, args)
+	}
+}
+
+// withCacheFunctions returns a copy of a Bloblang environment where the cache
+// functions it contains are bound to the resources of a manager. Functions that
+// have been removed from the environment are not added back.
+func withCacheFunctions(env *bloblang.Environment, mgr cacheAccessor) *bloblang.Environment {
+	present := map[string]struct{}{}
+	env.WalkFunctions(func(name string, _ query.FunctionSpec) {
+		present[name] = struct{}{}
+	})
+
+	var bound *bloblang.Environment
+	for _, f := range cacheFunctions {
+		if _, exists := present[f.spec.Name]; !exists {
+			continue
+		}
+		if bound == nil {
+			bound = env.WithoutFunctions()
+		}
+		_ = bound.RegisterFunction(f.spec, cacheFunctionCtor(f, mgr))
+	}
+	if bound == nil {
+		return env
+	}
+	return bound
+}
+
+func cacheResourceAndString(args *query.ParsedParams, field string) (resource, str string, err error) {
+	if resource, err = args.FieldString("resource"); err != nil {
+		return
+	}
+	str, err = args.FieldString(field)
+	return
+}
+
+func cacheClosure(mgr cacheAccessor, name, resource string, fn func(c cache.V1) (any, error)) query.Function {
+	return query.ClosureFunction("function "+name, func(_ query.FunctionContext) (any, error) {
+		if mgr == nil {
+			return nil, errNoCacheResources
+		}
+		var res any
+		var err error
+		if cerr := mgr.AccessCache(context.Background(), resource, func(c cache.V1) {
+			res, err = fn(c)
+		}); cerr != nil {
+			return nil, cerr
+		}
+		return res, err
+	}, nil)
+}
+
+func cacheResultsToObject(res map[string][]byte) map[string]any {
+	obj := make(map[string]any, len(res))
+	for k, v := range res {
+		obj[k] = string(v)
+	}
+	return obj
+}
@@ -0,0 +1,90 @@
+package manager_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang"
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func TestManagerBloblangCacheFunctions(t *testing.T) {
+	conf, err := testutil.ManagerFromYAML(`
+cache_resources:
+  - label: foo
+    memory:
+      init_values:
+        user:1: '{"name":"a"}'
+        user:2: '{"name":"b"}'
+        other: nope
+  - label: baz
+    memory: {}
+  - label: bar
+    multilevel: [ foo, baz ]
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(conf)
+	require.NoError(t, err)
+
+	exec := func(mapping string) (any, error) {
+		t.Helper()
+		m, err := mgr.BloblEnvironment().NewMapping(mapping)
+		require.NoError(t, err)
+		p, err := m.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+		if err != nil {
+			return nil, err
+		}
+		return p.AsStructured()
+	}
+
+	res, err := exec(`root = cache_get("foo", "user:1").parse_json()`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "a"}, res)
+
+	res, err = exec(`root = cache_get("foo", "nope")`)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	res, err = exec(`root = cache_get_multi("foo", ["user:1", "user:2", "nope"])`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"user:1": `{"name":"a"}`,
+		"user:2": `{"name":"b"}`,
+	}, res)
+
+	res, err = exec(`root = cache_scan("foo", "user:").keys().sort()`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"user:1", "user:2"}, res)
+
+	res, err = exec(`root = [ cache_increment("foo", "counter"), cache_increment("foo", "counter", 5) ]`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), int64(6)}, res)
+
+	// Caches without the capability either fall back to basic operations or
+	// fail when the operation cannot be performed safely.
+	res, err = exec(`root = cache_get_multi("bar", ["user:1", "nope"])`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"user:1": `{"name":"a"}`}, res)
+
+	_, err = exec(`root = cache_increment("bar", "counter")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "operation not supported by cache")
+
+	_, err = exec(`root = cache_scan("bar", "user:")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "operation not supported by cache")
+}
+
+func TestBloblangCacheFunctionsWithoutManager(t *testing.T) {
+	m, err := bloblang.GlobalEnvironment().NewMapping(`root = cache_get("foo", "bar")`)
+	require.NoError(t, err)
+
+	_, err = m.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache resources are not available")
+}
@@ -3,6 +3,7 @@ package manager
 import (
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/internal/component/ratelimit"
@@ -15,6 +16,7 @@ const (
 	fieldResourceOutputs    = "output_resources"
 	fieldResourceCaches     = "cache_resources"
 	fieldResourceRateLimits = "rate_limit_resources"
+	fieldResourceLocks      = "lock_resources"
 )
 
 // ResourceConfig contains fields for specifying resource components at the root
@@ -25,6 +27,7 @@ type ResourceConfig struct {
 	ResourceOutputs    []output.Config    `yaml:"output_resources,omitempty"`
 	ResourceCaches     []cache.Config     `yaml:"cache_resources,omitempty"`
 	ResourceRateLimits []ratelimit.Config `yaml:"rate_limit_resources,omitempty"`
+	ResourceLocks      []lock.Config      `yaml:"lock_resources,omitempty"`
 }
 
 // NewResourceConfig creates a ResourceConfig with default values.
@@ -35,6 +38,7 @@ func NewResourceConfig() ResourceConfig {
 		ResourceOutputs:    []output.Config{},
 		ResourceCaches:     []cache.Config{},
 		ResourceRateLimits: []ratelimit.Config{},
+		ResourceLocks:      []lock.Config{},
 	}
 }
 
@@ -46,6 +50,7 @@ func (r *ResourceConfig) AddFrom(extra *ResourceConfig) error {
 	r.ResourceOutputs = append(r.ResourceOutputs, extra.ResourceOutputs...)
 	r.ResourceCaches = append(r.ResourceCaches, extra.ResourceCaches...)
 	r.ResourceRateLimits = append(r.ResourceRateLimits, extra.ResourceRateLimits...)
+	r.ResourceLocks = append(r.ResourceLocks, extra.ResourceLocks...)
 	return nil
 }
 
@@ -132,5 +137,19 @@ func FromParsed(prov docs.Provider, pConf *docs.ParsedConfig) (conf ResourceConf
 		}
 		conf.ResourceRateLimits = append(conf.ResourceRateLimits, c)
 	}
+
+	if l, err = pConf.FieldAnyList(fieldResourceLocks); err != nil {
+		return
+	}
+	for _, p := range l {
+		if v, err = p.FieldAny(); err != nil {
+			return
+		}
+		var c lock.Config
+		if c, err = lock.FromAny(prov, v); err != nil {
+			return
+		}
+		conf.ResourceLocks = append(conf.ResourceLocks, c)
+	}
 	return
 }
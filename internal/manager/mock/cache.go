@@ -2,6 +2,9 @@ package mock
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/component"
@@ -12,6 +15,9 @@ import (
 type CacheItem struct {
 	Value string
 	TTL   *time.Duration
+
+	// Err is returned when attempting to get the item.
+	Err error
 }
 
 // Cache provides a mock cache implementation.
@@ -25,6 +31,9 @@ func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
 	if !ok {
 		return nil, component.ErrKeyNotFound
 	}
+	if i.Err != nil {
+		return nil, i.Err
+	}
 	return []byte(i.Value), nil
 }
 
@@ -70,3 +79,46 @@ func (c *Cache) Delete(ctx context.Context, key string) error {
 func (c *Cache) Close(ctx context.Context) error {
 	return nil
 }
+
+// Increment adds delta to the integer value of a mock cache item.
+func (c *Cache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	var current int64
+	i, ok := c.Values[key]
+	if ok {
+		var err error
+		if current, err = strconv.ParseInt(i.Value, 10, 64); err != nil {
+			return 0, fmt.Errorf("value is not an integer: %w", err)
+		}
+		ttl = i.TTL
+	}
+	current += delta
+	c.Values[key] = CacheItem{
+		Value: strconv.FormatInt(current, 10),
+		TTL:   ttl,
+	}
+	return current, nil
+}
+
+// CompareAndSwap sets a mock cache item if its current value matches old.
+func (c *Cache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	i, ok := c.Values[key]
+	if ok != (old != nil) || (ok && i.Value != string(old)) {
+		return component.ErrValueMismatch
+	}
+	c.Values[key] = CacheItem{
+		Value: string(value),
+		TTL:   ttl,
+	}
+	return nil
+}
+
+// Scan returns all mock cache items with a key prefix.
+func (c *Cache) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	res := map[string][]byte{}
+	for k, v := range c.Values {
+		if strings.HasPrefix(k, prefix) {
+			res[k] = []byte(v.Value)
+		}
+	}
+	return res, nil
+}
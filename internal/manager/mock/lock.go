@@ -0,0 +1,93 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
+)
+
+// Lock provides a mock lock implementation that holds leases in memory.
+type Lock struct {
+	ttl time.Duration
+
+	mut     sync.Mutex
+	holders map[string]*lockLease
+}
+
+// NewLock returns a mock lock where leases report the provided TTL. Leases
+// never expire on their own and must be released or revoked.
+func NewLock(ttl time.Duration) *Lock {
+	return &Lock{
+		ttl:     ttl,
+		holders: map[string]*lockLease{},
+	}
+}
+
+// Acquire a lease on a named lock, returns component.ErrLockHeld if the lock
+// is already held.
+func (l *Lock) Acquire(ctx context.Context, name string) (lock.Lease, error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if _, exists := l.holders[name]; exists {
+		return nil, component.ErrLockHeld
+	}
+	lease := &lockLease{l: l, name: name}
+	l.holders[name] = lease
+	return lease, nil
+}
+
+// Held returns whether a named lock is currently held.
+func (l *Lock) Held(name string) bool {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	_, exists := l.holders[name]
+	return exists
+}
+
+// Revoke the lease of a named lock, the holder loses the lease on its next
+// renewal.
+func (l *Lock) Revoke(name string) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	delete(l.holders, name)
+}
+
+// Close does nothing.
+func (l *Lock) Close(ctx context.Context) error {
+	return nil
+}
+
+type lockLease struct {
+	l    *Lock
+	name string
+}
+
+func (l *lockLease) TTL() time.Duration {
+	return l.l.ttl
+}
+
+func (l *lockLease) Renew(ctx context.Context) error {
+	l.l.mut.Lock()
+	defer l.l.mut.Unlock()
+
+	if l.l.holders[l.name] != l {
+		return component.ErrLeaseLost
+	}
+	return nil
+}
+
+func (l *lockLease) Release(ctx context.Context) error {
+	l.l.mut.Lock()
+	defer l.l.mut.Unlock()
+
+	if l.l.holders[l.name] == l {
+		delete(l.l.holders, l.name)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 
+	"github.com/warpstreamlabs/bento/internal/api"
 	"github.com/warpstreamlabs/bento/internal/bloblang"
 	"github.com/warpstreamlabs/bento/internal/bloblang/query"
 	"github.com/warpstreamlabs/bento/internal/bundle"
@@ -17,6 +18,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
@@ -29,6 +31,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/message"
 	"github.com/warpstreamlabs/bento/internal/pipeline"
 	"github.com/warpstreamlabs/bento/internal/pipeline/constructor"
+	"github.com/warpstreamlabs/bento/internal/transaction"
 )
 
 // ErrResourceNotFound represents an error where a named resource could not be
@@ -83,6 +86,7 @@ type Type struct {
 	processors *liveResources[processor.V1]
 	outputs    *liveResources[*outputWrapper]
 	rateLimits *liveResources[ratelimit.V1]
+	locks      *liveResources[lock.V1]
 
 	// Collections of component constructors
 	env      *bundle.Environment
@@ -98,6 +102,12 @@ type Type struct {
 
 	// Generic key/value store for plugin implementations.
 	genericValues *sync.Map
+
+	// Limits the bytes of messages in flight, when streamQuota is set each
+	// stream is given its own budget that is a child of the global budget.
+	memBudgetLimit int64
+	streamQuota    int64
+	memBudget      *transaction.Budget
 }
 
 // OptFunc is an opt setting for a manager type.
@@ -185,6 +195,17 @@ func OptSetFS(fs ifs.FS) OptFunc {
 	}
 }
 
+// OptSetMemoryBudget sets the maximum number of message bytes that may be held
+// in flight by inputs of the manager, and the maximum number of bytes that each
+// stream may hold in flight when running in streams mode. A value of zero
+// disables either limit.
+func OptSetMemoryBudget(limit, streamQuota int64) OptFunc {
+	return func(t *Type) {
+		t.memBudgetLimit = limit
+		t.streamQuota = streamQuota
+	}
+}
+
 func OptSetPipelineCtor(ctor func(pipeline.Config, bundle.NewManagement) (processor.Pipeline, error)) OptFunc {
 	return func(t *Type) {
 		t.pipeCtor = ctor
@@ -203,6 +224,7 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		processors: newLiveResources[processor.V1](),
 		outputs:    newLiveResources[*outputWrapper](),
 		rateLimits: newLiveResources[ratelimit.V1](),
+		locks:      newLiveResources[lock.V1](),
 
 		// Environment defaults to global (everything that was imported).
 		env:      bundle.GlobalEnvironment,
@@ -224,6 +246,11 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 	for _, opt := range opts {
 		opt(t)
 	}
+	t.bloblEnv = withCacheFunctions(t.bloblEnv, t)
+
+	if t.memBudgetLimit > 0 || t.streamQuota > 0 {
+		t.memBudget = transaction.NewBudget(t.memBudgetLimit, nil, t.stats.GetGauge("memory_budget_used_bytes"))
+	}
 
 	seen := map[string]struct{}{}
 
@@ -273,6 +300,12 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		}
 		t.rateLimits.Add(c.Label, nil)
 	}
+	for _, c := range conf.ResourceLocks {
+		if err := checkLabel("lock", c.Label); err != nil {
+			return nil, err
+		}
+		t.locks.Add(c.Label, nil)
+	}
 
 	// Labels validated, begin construction
 	for _, conf := range conf.ResourceRateLimits {
@@ -281,6 +314,12 @@ func New(conf ResourceConfig, opts ...OptFunc) (*Type, error) {
 		}
 	}
 
+	for _, conf := range conf.ResourceLocks {
+		if err := t.StoreLock(context.Background(), conf.Label, conf); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, conf := range conf.ResourceCaches {
 		if err := t.StoreCache(context.Background(), conf.Label, conf); err != nil {
 			return nil, err
@@ -330,6 +369,9 @@ func (t *Type) forStream(id string) *Type {
 		"stream": id,
 	})
 	newT.stats = t.stats.WithLabels("stream", id)
+	if t.streamQuota > 0 {
+		newT.memBudget = transaction.NewBudget(t.streamQuota, t.memBudget, newT.stats.GetGauge("memory_budget_used_bytes"))
+	}
 	return &newT
 }
 
@@ -375,6 +417,12 @@ func (t *Type) Label() string {
 	return t.label
 }
 
+// MemoryBudget returns the budget that inputs should reserve the bytes of
+// messages from until they are acknowledged, or nil if there is no budget.
+func (t *Type) MemoryBudget() *transaction.Budget {
+	return t.memBudget
+}
+
 // WithAddedMetrics returns a modified version of the manager where metrics are
 // registered to both the current metrics target as well as the provided one.
 func (t *Type) WithAddedMetrics(m metrics.Type) bundle.NewManagement {
@@ -395,6 +443,18 @@ func (t *Type) RegisterEndpoint(apiPath, desc string, h http.HandlerFunc) {
 	}
 }
 
+// RegisterEndpointSpec documents the operations of a server wide HTTP endpoint
+// in order to generate an OpenAPI description, this is a no-op when the
+// underlying API registry does not support it.
+func (t *Type) RegisterEndpointSpec(apiPath string, spec api.EndpointSpec) {
+	if t.stream != "" && t.namespaceStreamEndpoints {
+		apiPath = path.Join("/", t.stream, apiPath)
+	}
+	if sReg, ok := t.apiReg.(api.SpecReg); ok {
+		sReg.RegisterEndpointSpec(apiPath, spec)
+	}
+}
+
 // FS returns an ifs.FS implementation that provides access to a filesystem. By
 // default this simply access the os package, with relative paths resolved from
 // the directory that the process is running from.
@@ -911,6 +971,81 @@ func (t *Type) RemoveRateLimit(ctx context.Context, name string) error {
 
 //------------------------------------------------------------------------------
 
+// ProbeLock returns true if a lock resource exists under the provided name.
+func (t *Type) ProbeLock(name string) bool {
+	return t.locks.Probe(name)
+}
+
+// AccessLock attempts to access a lock resource by a unique identifier and
+// executes a closure function with the lock as an argument. Returns an error if
+// the lock does not exist (or is otherwise inaccessible).
+//
+// During the execution of the provided closure it is guaranteed that the
+// resource will not be closed or removed. However, it is possible for the
+// resource to be accessed by any number of components in parallel.
+func (t *Type) AccessLock(ctx context.Context, name string, fn func(lock.V1)) (err error) {
+	if rerr := t.locks.RAccess(name, func(l lock.V1) {
+		if l == nil {
+			err = ErrResourceNotFound(name)
+			return
+		}
+		fn(l)
+	}); rerr != nil {
+		err = rerr
+	}
+	return
+}
+
+// NewLock attempts to create a new lock component from a config.
+func (t *Type) NewLock(conf lock.Config) (lock.V1, error) {
+	return t.env.LockInit(conf, t.forLabel(conf.Label))
+}
+
+// StoreLock attempts to store a new lock resource. If an existing resource has
+// the same name it is closed and removed _before_ the new one is initialized in
+// order to avoid duplicate connections.
+func (t *Type) StoreLock(ctx context.Context, name string, conf lock.Config) error {
+	var initErr error
+	if err := t.locks.Access(name, true, func(l *lock.V1, set func(*lock.V1)) {
+		if l != nil {
+			// If a previous resource exists with the same name then we do NOT allow
+			// it to be replaced unless it can be successfully closed. This ensures
+			// that we do not leak connections.
+			if initErr = (*l).Close(ctx); initErr != nil {
+				return
+			}
+		}
+
+		var newLock lock.V1
+		if newLock, initErr = t.intoPath("lock_resources").NewLock(conf); initErr != nil {
+			return
+		}
+		set(&newLock)
+	}); err != nil {
+		return err
+	}
+	return initErr
+}
+
+// RemoveLock attempts to close and remove an existing lock resource.
+func (t *Type) RemoveLock(ctx context.Context, name string) error {
+	var closeErr error
+	if err := t.locks.Access(name, false, func(l *lock.V1, set func(l *lock.V1)) {
+		if l == nil {
+			return
+		}
+		if closeErr = (*l).Close(ctx); closeErr != nil {
+			return
+		}
+		set(nil)
+	}); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+//------------------------------------------------------------------------------
+
 // NewScanner attempts to create a new scanner component from a config.
 func (t *Type) NewScanner(conf scanner.Config) (scanner.Creator, error) {
 	return t.env.ScannerInit(conf, t)
@@ -1031,5 +1166,18 @@ func (t *Type) WaitForClose(ctx context.Context) error {
 	}); err != nil {
 		return err
 	}
+
+	if err := t.locks.Walk(func(name string, l *lock.V1, set func(l *lock.V1)) error {
+		if l == nil {
+			return nil
+		}
+		if err := (*l).Close(ctx); err != nil {
+			return fmt.Errorf("resource '%s' failed to cleanly shutdown: %v", name, err)
+		}
+		set(nil)
+		return nil
+	}); err != nil {
+		return err
+	}
 	return nil
 }
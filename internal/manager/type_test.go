@@ -577,3 +577,34 @@ func TestManagerGenericGetOrSet(t *testing.T) {
 	assert.True(t, loaded)
 	assert.Equal(t, "foo", v)
 }
+
+func TestManagerMemoryBudget(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+	assert.Nil(t, mgr.MemoryBudget())
+
+	mgr, err = manager.New(manager.NewResourceConfig(), manager.OptSetMemoryBudget(100, 0))
+	require.NoError(t, err)
+	require.NotNil(t, mgr.MemoryBudget())
+	assert.Equal(t, int64(100), mgr.MemoryBudget().Limit())
+
+	strmMgr := mgr.ForStream("foo").(*manager.Type)
+	assert.Same(t, mgr.MemoryBudget(), strmMgr.MemoryBudget())
+
+	mgr, err = manager.New(manager.NewResourceConfig(), manager.OptSetMemoryBudget(0, 10))
+	require.NoError(t, err)
+	require.NotNil(t, mgr.MemoryBudget())
+	assert.Equal(t, int64(0), mgr.MemoryBudget().Limit())
+
+	// Each stream is given a quota that also counts against the global
+	// budget.
+	strmMgr = mgr.ForStream("foo").(*manager.Type)
+	require.NotNil(t, strmMgr.MemoryBudget())
+	assert.Equal(t, int64(10), strmMgr.MemoryBudget().Limit())
+
+	release := strmMgr.IntoPath("input").(*manager.Type).MemoryBudget().Reserve(5)
+	assert.Equal(t, int64(5), strmMgr.MemoryBudget().Used())
+	assert.Equal(t, int64(5), mgr.MemoryBudget().Used())
+	release()
+	assert.Equal(t, int64(0), mgr.MemoryBudget().Used())
+}
@@ -41,6 +41,18 @@ func (m Batch) DeepCopy() Batch {
 	return parts
 }
 
+// ByteSize returns the total number of bytes of the contents of each message
+// within the batch.
+func (m Batch) ByteSize() int {
+	var n int
+	for _, p := range m {
+		if p != nil {
+			n += len(p.AsBytes())
+		}
+	}
+	return n
+}
+
 //------------------------------------------------------------------------------
 
 // Get returns a message part at a particular index, indexes can be negative.
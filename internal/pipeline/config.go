@@ -17,6 +17,10 @@ func ConfigSpec() docs.FieldSpec {
 		"pipeline", "Describes optional processing pipelines used for mutating messages.",
 	).WithChildren(
 		threadsField,
+		docs.FieldBloblang(
+			"ordering_key", "An optional [Bloblang query](/docs/guides/bloblang/about) that resolves a key for each message. When set, messages sharing a key are always processed by the same thread, preserving their order whilst still using all threads. Batches containing messages of differing keys are split across threads and acknowledged once all parts have been processed.",
+			"this.customer_id", "@kafka_key",
+		).Optional().Advanced(),
 		docs.FieldProcessor("processors", "A list of processors to apply to messages.").Array().HasDefault([]any{}),
 	)
 }
@@ -30,15 +34,17 @@ func ConfigSpec() docs.FieldSpec {
 // number of parallel inputs that matches or surpasses the number of pipeline
 // threads, or use a memory buffer.
 type Config struct {
-	Threads    int                `json:"threads" yaml:"threads"`
-	Processors []processor.Config `json:"processors" yaml:"processors"`
+	Threads     int                `json:"threads" yaml:"threads"`
+	OrderingKey string             `json:"ordering_key,omitempty" yaml:"ordering_key,omitempty"`
+	Processors  []processor.Config `json:"processors" yaml:"processors"`
 }
 
 // NewConfig returns a configuration struct fully populated with default values.
 func NewConfig() Config {
 	return Config{
-		Threads:    -1,
-		Processors: []processor.Config{},
+		Threads:     -1,
+		OrderingKey: "",
+		Processors:  []processor.Config{},
 	}
 }
 
@@ -68,6 +74,14 @@ func fromMap(prov docs.Provider, val map[string]any) (conf Config, err error) {
 		conf.Threads = int(threads64)
 	}
 
+	if keyV, exists := val["ordering_key"]; exists {
+		var ok bool
+		if conf.OrderingKey, ok = keyV.(string); !ok {
+			err = fmt.Errorf("expected ordering_key to be a string, got %T", keyV)
+			return
+		}
+	}
+
 	if procVs, ok := val["processors"].([]any); ok {
 		for _, iv := range procVs {
 			var tmpProc processor.Config
@@ -88,6 +102,10 @@ func fromYAML(prov docs.Provider, val *yaml.Node) (conf Config, err error) {
 			if err = val.Content[i+1].Decode(&conf.Threads); err != nil {
 				return
 			}
+		case "ordering_key":
+			if err = val.Content[i+1].Decode(&conf.OrderingKey); err != nil {
+				return
+			}
 		case "processors":
 			node := val.Content[i+1]
 			if node.Kind != yaml.SequenceNode {
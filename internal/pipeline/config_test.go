@@ -37,6 +37,18 @@ processors:
 				assert.Equal(t, "mapping", v.Processors[1].Type)
 			},
 		},
+		{
+			name: "ordering key",
+			input: `
+threads: 4
+ordering_key: this.customer_id
+`,
+			validateFn: func(t testing.TB, v pipeline.Config) {
+				assert.Equal(t, 4, v.Threads)
+				assert.Equal(t, "this.customer_id", v.OrderingKey)
+				assert.Empty(t, v.Processors)
+			},
+		},
 	}
 
 	for _, test := range tests {
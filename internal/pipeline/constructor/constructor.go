@@ -1,6 +1,7 @@
 package constructor
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/warpstreamlabs/bento/internal/bundle"
@@ -22,5 +23,12 @@ func New(conf pipeline.Config, mgr bundle.NewManagement) (processor.Pipeline, er
 	if conf.Threads == 1 {
 		return pipeline.NewProcessor(processors...), nil
 	}
+	if conf.OrderingKey != "" {
+		orderingKey, err := mgr.BloblEnvironment().NewMapping(conf.OrderingKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ordering_key: %w", err)
+		}
+		return pipeline.NewKeyedPool(conf.Threads, orderingKey, mgr.Logger(), processors...)
+	}
 	return pipeline.NewPool(conf.Threads, mgr.Logger(), processors...)
 }
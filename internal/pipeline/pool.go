@@ -2,12 +2,16 @@ package pipeline
 
 import (
 	"context"
+	"errors"
+	"hash/fnv"
 	"runtime"
 	"sync"
 	"sync/atomic"
 
 	"github.com/Jeffail/shutdown"
 
+	"github.com/warpstreamlabs/bento/internal/batch"
+	"github.com/warpstreamlabs/bento/internal/bloblang/mapping"
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/internal/log"
@@ -18,7 +22,8 @@ import (
 // channel. Inputs remain coupled to their outputs as they propagate the
 // response channel in the transaction.
 type Pool struct {
-	workers []processor.Pipeline
+	workers     []processor.Pipeline
+	orderingKey *mapping.Executor
 
 	log log.Modular
 
@@ -48,6 +53,20 @@ func NewPool(threads int, log log.Modular, msgProcessors ...processor.V1) (*Pool
 	return p, nil
 }
 
+// NewKeyedPool creates a new processing pool where each message is processed by
+// a thread chosen by the hash of an ordering key, such that messages sharing a
+// key are processed in the order that they are received. Batches containing
+// messages of multiple threads are split, and the batch is acknowledged once
+// each of its parts have been.
+func NewKeyedPool(threads int, orderingKey *mapping.Executor, log log.Modular, msgProcessors ...processor.V1) (*Pool, error) {
+	p, err := NewPool(threads, log, msgProcessors...)
+	if err != nil {
+		return nil, err
+	}
+	p.orderingKey = orderingKey
+	return p, nil
+}
+
 //------------------------------------------------------------------------------
 
 // loop is the processing loop of this pipeline.
@@ -75,8 +94,21 @@ func (p *Pool) loop() {
 
 	var closeInternalOnce sync.Once
 
-	for _, worker := range p.workers {
-		if err := worker.Consume(p.messagesIn); err != nil {
+	workerIns := make([]<-chan message.Transaction, len(p.workers))
+	for i := range workerIns {
+		workerIns[i] = p.messagesIn
+	}
+	if p.orderingKey != nil {
+		keyedIns := make([]chan message.Transaction, len(p.workers))
+		for i := range keyedIns {
+			keyedIns[i] = make(chan message.Transaction)
+			workerIns[i] = keyedIns[i]
+		}
+		go p.dispatchKeyed(keyedIns)
+	}
+
+	for i, worker := range p.workers {
+		if err := worker.Consume(workerIns[i]); err != nil {
 			p.log.Error("Failed to start pipeline worker: %v\n", err)
 			atomic.AddInt64(&remainingWorkers, -1)
 			continue
@@ -126,6 +158,143 @@ func (p *Pool) loop() {
 	}
 }
 
+// dispatchKeyed distributes transactions across the inputs of workers by the
+// ordering key of each message, closing the inputs once there are no more
+// transactions.
+func (p *Pool) dispatchKeyed(workerIns []chan message.Transaction) {
+	defer func() {
+		for _, c := range workerIns {
+			close(c)
+		}
+	}()
+
+	for {
+		var tran message.Transaction
+		var open bool
+		select {
+		case tran, open = <-p.messagesIn:
+			if !open {
+				return
+			}
+		case <-p.shutSig.HardStopChan():
+			return
+		}
+
+		for _, kt := range p.splitByKey(tran, len(workerIns)) {
+			select {
+			case workerIns[kt.worker] <- kt.tran:
+			case <-p.shutSig.HardStopChan():
+				return
+			}
+		}
+	}
+}
+
+type keyedTransaction struct {
+	worker int
+	tran   message.Transaction
+}
+
+// splitByKey splits a transaction into a transaction for each worker that its
+// messages are assigned to, preserving the order of messages within each.
+func (p *Pool) splitByKey(tran message.Transaction, workers int) []keyedTransaction {
+	var order []int
+	indexes := map[int][]int{}
+	for i := range tran.Payload {
+		w := p.workerForKey(i, tran.Payload, workers)
+		if _, exists := indexes[w]; !exists {
+			order = append(order, w)
+		}
+		indexes[w] = append(indexes[w], i)
+	}
+	if len(order) == 0 {
+		return []keyedTransaction{{worker: 0, tran: tran}}
+	}
+	if len(order) == 1 {
+		return []keyedTransaction{{worker: order[0], tran: tran}}
+	}
+
+	ack := &splitAck{tran: tran, remaining: len(order)}
+	splits := make([]keyedTransaction, len(order))
+	for i, w := range order {
+		partIndexes := indexes[w]
+		parts := make(message.Batch, len(partIndexes))
+		for j, index := range partIndexes {
+			parts[j] = tran.Payload[index]
+		}
+		sorter, sortParts := message.NewSortGroup(parts)
+		splits[i] = keyedTransaction{
+			worker: w,
+			tran: message.NewTransactionFunc(sortParts, func(ctx context.Context, err error) error {
+				return ack.ack(ctx, err, sorter, sortParts, partIndexes)
+			}),
+		}
+	}
+	return splits
+}
+
+func (p *Pool) workerForKey(index int, msg message.Batch, workers int) int {
+	var key []byte
+	part, err := p.orderingKey.MapPart(index, msg)
+	if err != nil {
+		p.log.Error("Failed to resolve ordering key: %v\n", err)
+	} else if part != nil {
+		key = part.AsBytes()
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	return int(h.Sum64() % uint64(workers))
+}
+
+// splitAck acknowledges a transaction that was split once each of its splits
+// have been acknowledged, merging any errors of the splits.
+type splitAck struct {
+	tran message.Transaction
+
+	mut       sync.Mutex
+	remaining int
+	batchErr  *batch.Error
+}
+
+func (s *splitAck) ack(ctx context.Context, err error, sorter *message.SortGroup, parts message.Batch, indexes []int) error {
+	s.mut.Lock()
+	if err != nil {
+		if s.batchErr == nil {
+			s.batchErr = batch.NewError(s.tran.Payload, err)
+		}
+		failed := false
+		var bErr *batch.Error
+		if errors.As(err, &bErr) {
+			bErr.WalkPartsBySource(sorter, parts, func(i int, _ *message.Part, pErr error) bool {
+				if pErr != nil {
+					s.batchErr.Failed(indexes[i], pErr)
+					failed = true
+				}
+				return true
+			})
+		}
+		if !failed {
+			// The error could not be linked with individual messages and
+			// therefore all messages of the split have failed.
+			for _, i := range indexes {
+				s.batchErr.Failed(i, err)
+			}
+		}
+	}
+	s.remaining--
+	done := s.remaining == 0
+	s.mut.Unlock()
+
+	if !done {
+		return nil
+	}
+	if s.batchErr != nil {
+		return s.tran.Ack(ctx, s.batchErr)
+	}
+	return s.tran.Ack(ctx, nil)
+}
+
 //------------------------------------------------------------------------------
 
 // Consume assigns a messages channel for the pipeline to read.
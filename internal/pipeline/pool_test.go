@@ -2,13 +2,18 @@ package pipeline_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/warpstreamlabs/bento/internal/batch"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/internal/log"
 	"github.com/warpstreamlabs/bento/internal/manager/mock"
@@ -279,3 +284,148 @@ func TestPoolMultiNaturalClose(t *testing.T) {
 	close(tChan)
 	require.NoError(t, proc.WaitForClose(context.Background()))
 }
+
+func TestPoolOrderingKey(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := pipeline.NewConfig()
+	conf.Threads = 4
+	conf.OrderingKey = `this.key`
+	conf.Processors = append(conf.Processors, processor.NewConfig())
+
+	proc, err := constructor.New(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	tChan, resChan := make(chan message.Transaction), make(chan error, 100)
+	require.NoError(t, proc.Consume(tChan))
+
+	go func() {
+		for i := 0; i < 100; i++ {
+			msg := message.QuickBatch([][]byte{
+				[]byte(fmt.Sprintf(`{"key":"k%v","seq":%v}`, i%5, i)),
+			})
+			select {
+			case tChan <- message.NewTransaction(msg, resChan):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	lastSeqs := map[string]int{}
+	for i := 0; i < 100; i++ {
+		var procT message.Transaction
+		select {
+		case procT = <-proc.TransactionChan():
+		case <-ctx.Done():
+			t.Fatal("Timed out")
+		}
+		require.Len(t, procT.Payload, 1)
+
+		v, err := procT.Payload[0].AsStructured()
+		require.NoError(t, err)
+
+		obj := v.(map[string]any)
+		key := obj["key"].(string)
+		seq, err := obj["seq"].(json.Number).Int64()
+		require.NoError(t, err)
+
+		if last, exists := lastSeqs[key]; exists {
+			assert.Greater(t, int(seq), last, key)
+		}
+		lastSeqs[key] = int(seq)
+		require.NoError(t, procT.Ack(ctx, nil))
+	}
+	assert.Len(t, lastSeqs, 5)
+
+	for i := 0; i < 100; i++ {
+		select {
+		case res := <-resChan:
+			require.NoError(t, res)
+		case <-ctx.Done():
+			t.Fatal("Timed out")
+		}
+	}
+
+	proc.TriggerCloseNow()
+	require.NoError(t, proc.WaitForClose(ctx))
+}
+
+func TestPoolOrderingKeySplitBatch(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := pipeline.NewConfig()
+	conf.Threads = 4
+	conf.OrderingKey = `content().string().slice(0, 1)`
+	conf.Processors = append(conf.Processors, processor.NewConfig())
+
+	proc, err := constructor.New(conf, mock.NewManager())
+	require.NoError(t, err)
+
+	tChan, resChan := make(chan message.Transaction), make(chan error)
+	require.NoError(t, proc.Consume(tChan))
+
+	inputs := [][]byte{
+		[]byte("a0"), []byte("b0"), []byte("c0"), []byte("a1"),
+		[]byte("d0"), []byte("b1"), []byte("a2"), []byte("c1"),
+	}
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch(inputs), resChan):
+	case <-ctx.Done():
+		t.Fatal("Timed out")
+	}
+
+	errFail := errors.New("b1 failed")
+
+	var received [][]byte
+	var wg sync.WaitGroup
+	for len(received) < len(inputs) {
+		var procT message.Transaction
+		select {
+		case procT = <-proc.TransactionChan():
+		case <-ctx.Done():
+			t.Fatal("Timed out")
+		}
+		received = append(received, message.GetAllBytes(procT.Payload)...)
+
+		var ackErr error
+		for i, p := range procT.Payload {
+			if string(p.AsBytes()) == "b1" {
+				ackErr = batch.NewError(procT.Payload, errFail).Failed(i, errFail)
+			}
+		}
+
+		wg.Add(1)
+		go func(tran message.Transaction, err error) {
+			defer wg.Done()
+			require.NoError(t, tran.Ack(ctx, err))
+		}(procT, ackErr)
+	}
+	assert.ElementsMatch(t, inputs, received)
+
+	var res error
+	select {
+	case res = <-resChan:
+	case <-ctx.Done():
+		t.Fatal("Timed out")
+	}
+	wg.Wait()
+
+	var bErr *batch.Error
+	require.ErrorAs(t, res, &bErr)
+	assert.Equal(t, 1, bErr.IndexedErrors())
+
+	failed := map[int]error{}
+	bErr.WalkPartsNaively(func(i int, _ *message.Part, err error) bool {
+		if err != nil {
+			failed[i] = err
+		}
+		return true
+	})
+	assert.Equal(t, map[int]error{5: errFail}, failed)
+
+	proc.TriggerCloseNow()
+	require.NoError(t, proc.WaitForClose(ctx))
+}
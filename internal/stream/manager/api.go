@@ -16,6 +16,7 @@ import (
 
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/processor"
 	"github.com/warpstreamlabs/bento/internal/component/ratelimit"
@@ -37,7 +38,7 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 	}
 	m.manager.RegisterEndpoint(
 		"/resources/{type}/{id}",
-		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `output`, `processor` and `rate_limit`.",
+		"POST: Create or replace a given resource configuration of a specified type. Types supported are `cache`, `input`, `lock`, `output`, `processor` and `rate_limit`.",
 		m.HandleResourceCRUD,
 	)
 	m.manager.RegisterEndpoint(
@@ -394,7 +395,7 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
 			conf := info.Config()
-			sanit := conf.GetRawSource()
+			sanit := config.GlobalSecrets.RedactAny(conf.GetRawSource())
 
 			var bodyBytes []byte
 			if bodyBytes, serverErr = json.Marshal(struct {
@@ -531,8 +532,16 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 			}
 			serverErr = m.manager.StoreRateLimit(ctx, id, rlConf)
 		}
+	case docs.TypeLock:
+		storeFn = func(n *yaml.Node) {
+			var lockConf lock.Config
+			if lockConf, requestErr = lock.FromAny(m.manager.Environment(), n); requestErr != nil {
+				return
+			}
+			serverErr = m.manager.StoreLock(ctx, id, lockConf)
+		}
 	default:
-		http.Error(w, "Var `type` must be set to one of `cache`, `input`, `output`, `processor` or `rate_limit`", http.StatusBadRequest)
+		http.Error(w, "Var `type` must be set to one of `cache`, `input`, `lock`, `output`, `processor` or `rate_limit`", http.StatusBadRequest)
 		return
 	}
 
@@ -0,0 +1,244 @@
+package manager
+
+import (
+	"github.com/warpstreamlabs/bento/internal/api"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+// configContent returns a content map for request bodies that accept either a
+// JSON or YAML config of a given schema.
+func configContent(schema any) map[string]api.OpenAPIMediaType {
+	return map[string]api.OpenAPIMediaType{
+		"application/json": {Schema: schema},
+		"application/yaml": {Schema: schema},
+	}
+}
+
+var chilledParam = api.OpenAPIParameter{
+	Name:        "chilled",
+	In:          "query",
+	Description: "When set to `true` linting errors within the provided config are ignored.",
+	Schema:      map[string]any{"type": "boolean"},
+}
+
+var lintErrorResponse = api.OpenAPIResponse{
+	Description: "The request was invalid, which may be due to linting errors within the provided config.",
+	Content:     api.JSONContent(api.SchemaRef("lint_errors")),
+}
+
+var notFoundResponse = api.OpenAPIResponse{
+	Description: "The stream does not exist.",
+}
+
+var serverErrResponse = api.OpenAPIResponse{
+	Description: "The operation failed.",
+}
+
+// apiSchemas returns the JSON schemas of request and response bodies used by
+// the streams API, including definitions for all components of the
+// environment so that stream configs can be fully validated.
+func (m *Type) apiSchemas() map[string]any {
+	schemas := m.manager.Environment().JSONSchemaDefinitions(api.OpenAPISchemaRef)
+
+	schemas["stream_config"] = map[string]any{
+		"type":       "object",
+		"properties": stream.Spec().JSONSchemaWithRefs(api.OpenAPISchemaRef),
+	}
+	schemas["stream_status"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"active":     map[string]any{"type": "boolean"},
+			"uptime":     map[string]any{"type": "number"},
+			"uptime_str": map[string]any{"type": "string"},
+		},
+	}
+	schemas["stream_status_with_config"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"active":     map[string]any{"type": "boolean"},
+			"uptime":     map[string]any{"type": "number"},
+			"uptime_str": map[string]any{"type": "string"},
+			"config":     api.SchemaRef("stream_config"),
+		},
+	}
+	schemas["lint_errors"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"lint_errors": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+	}
+	return schemas
+}
+
+// registerEndpointSpecs documents the streams API endpoints when the
+// underlying manager supports generating an OpenAPI description.
+func (m *Type) registerEndpointSpecs(enableCrud bool) {
+	sReg, ok := m.manager.(api.SpecReg)
+	if !ok {
+		return
+	}
+
+	sReg.RegisterEndpointSpec("/ready", api.EndpointSpec{
+		Operations: map[string]api.OpenAPIOperation{
+			"GET": {
+				Summary: "Check whether all running streams are connected.",
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {Description: "All running streams are connected."},
+					"503": {Description: "One or more streams are not connected."},
+				},
+			},
+		},
+	})
+	if !enableCrud {
+		return
+	}
+
+	schemas := m.apiSchemas()
+
+	sReg.RegisterEndpointSpec("/resources/{type}/{id}", api.EndpointSpec{
+		Operations: map[string]api.OpenAPIOperation{
+			"POST": {
+				Summary:    "Create or replace a resource.",
+				Parameters: []api.OpenAPIParameter{chilledParam},
+				RequestBody: &api.OpenAPIRequestBody{
+					Description: "The resource config, the schema of which depends on the `type` parameter.",
+					Required:    true,
+					Content: configContent(map[string]any{
+						"oneOf": []any{
+							api.SchemaRef("cache"),
+							api.SchemaRef("input"),
+							api.SchemaRef("lock"),
+							api.SchemaRef("output"),
+							api.SchemaRef("processor"),
+							api.SchemaRef("rate_limit"),
+						},
+					}),
+				},
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {Description: "The resource was stored."},
+					"400": lintErrorResponse,
+					"502": serverErrResponse,
+				},
+			},
+		},
+		Schemas: schemas,
+	})
+
+	sReg.RegisterEndpointSpec("/streams/{id}/stats", api.EndpointSpec{
+		Operations: map[string]api.OpenAPIOperation{
+			"GET": {
+				Summary: "Obtain the metrics of a stream.",
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {
+						Description: "An object of metric names to their values.",
+						Content:     api.JSONContent(map[string]any{"type": "object"}),
+					},
+					"404": notFoundResponse,
+					"502": serverErrResponse,
+				},
+			},
+		},
+	})
+
+	configBody := &api.OpenAPIRequestBody{
+		Required: true,
+		Content:  configContent(api.SchemaRef("stream_config")),
+	}
+	sReg.RegisterEndpointSpec("/streams/{id}", api.EndpointSpec{
+		Operations: map[string]api.OpenAPIOperation{
+			"POST": {
+				Summary:     "Create a new stream.",
+				Parameters:  []api.OpenAPIParameter{chilledParam},
+				RequestBody: configBody,
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {Description: "The stream was created."},
+					"400": lintErrorResponse,
+					"502": serverErrResponse,
+				},
+			},
+			"GET": {
+				Summary: "Read the status and config of a stream.",
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {
+						Description: "The status and config of the stream.",
+						Content:     api.JSONContent(api.SchemaRef("stream_status_with_config")),
+					},
+					"404": notFoundResponse,
+					"502": serverErrResponse,
+				},
+			},
+			"PUT": {
+				Summary:     "Replace the config of an existing stream.",
+				Parameters:  []api.OpenAPIParameter{chilledParam},
+				RequestBody: configBody,
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {Description: "The stream was updated."},
+					"400": lintErrorResponse,
+					"404": notFoundResponse,
+					"502": serverErrResponse,
+				},
+			},
+			"PATCH": {
+				Summary: "Merge a partial config into the config of an existing stream.",
+				RequestBody: &api.OpenAPIRequestBody{
+					Required: true,
+					Content:  configContent(map[string]any{"type": "object"}),
+				},
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {Description: "The stream was updated."},
+					"400": {Description: "The patched config was invalid."},
+					"404": notFoundResponse,
+					"502": serverErrResponse,
+				},
+			},
+			"DELETE": {
+				Summary: "Stop and remove a stream.",
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {Description: "The stream was removed."},
+					"404": notFoundResponse,
+					"502": serverErrResponse,
+				},
+			},
+		},
+		Schemas: schemas,
+	})
+
+	sReg.RegisterEndpointSpec("/streams", api.EndpointSpec{
+		Operations: map[string]api.OpenAPIOperation{
+			"GET": {
+				Summary: "List all streams along with their status.",
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {
+						Description: "An object of stream ids to their status.",
+						Content: api.JSONContent(map[string]any{
+							"type":                 "object",
+							"additionalProperties": api.SchemaRef("stream_status"),
+						}),
+					},
+					"502": serverErrResponse,
+				},
+			},
+			"POST": {
+				Summary:    "Replace the entire set of streams.",
+				Parameters: []api.OpenAPIParameter{chilledParam},
+				RequestBody: &api.OpenAPIRequestBody{
+					Description: "An object of stream ids to their configs, streams that are not present are removed.",
+					Required:    true,
+					Content: configContent(map[string]any{
+						"type":                 "object",
+						"additionalProperties": api.SchemaRef("stream_config"),
+					}),
+				},
+				Responses: map[string]api.OpenAPIResponse{
+					"200": {Description: "The set of streams was replaced."},
+					"400": lintErrorResponse,
+					"502": serverErrResponse,
+				},
+			},
+		},
+		Schemas: schemas,
+	})
+}
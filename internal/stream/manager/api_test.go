@@ -15,15 +15,19 @@ import (
 	"time"
 
 	"github.com/Jeffail/gabs/v2"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/warpstreamlabs/bento/internal/api"
 	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/testutil"
 	"github.com/warpstreamlabs/bento/internal/config"
 	"github.com/warpstreamlabs/bento/internal/docs"
+	"github.com/warpstreamlabs/bento/internal/log"
 	bmanager "github.com/warpstreamlabs/bento/internal/manager"
 	"github.com/warpstreamlabs/bento/internal/manager/mock"
 	"github.com/warpstreamlabs/bento/internal/message"
@@ -1011,3 +1015,44 @@ output:
 		return response.Code == http.StatusServiceUnavailable
 	}, time.Second*10, time.Millisecond*50)
 }
+
+func TestTypeAPIOpenAPI(t *testing.T) {
+	apiType, err := api.New("", "", api.NewConfig(), nil, log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	rMgr, err := bmanager.New(bmanager.NewResourceConfig(), bmanager.OptSetAPIReg(apiType))
+	require.NoError(t, err)
+
+	_ = manager.New(rMgr, manager.OptAPIEnabled(true))
+
+	docBytes, err := json.Marshal(apiType.OpenAPI())
+	require.NoError(t, err)
+
+	doc, err := gabs.ParseJSON(docBytes)
+	require.NoError(t, err)
+
+	for _, method := range []string{"get", "post", "put", "patch", "delete"} {
+		assert.True(t, doc.Exists("paths", "/streams/{id}", method), method)
+	}
+	assert.Equal(t, "id", doc.S("paths", "/streams/{id}", "parameters", "0", "name").Data())
+	assert.Equal(t, "#/components/schemas/stream_config", doc.S("paths", "/streams/{id}", "post", "requestBody", "content", "application/json", "schema", "$ref").Data())
+	assert.Equal(t, "#/components/schemas/stream_config", doc.S("paths", "/streams", "post", "requestBody", "content", "application/yaml", "schema", "additionalProperties", "$ref").Data())
+
+	assert.Equal(t, "#/components/schemas/input", doc.S("components", "schemas", "stream_config", "properties", "input", "$ref").Data())
+	assert.Equal(t, "#/components/schemas/processor", doc.S("components", "schemas", "stream_config", "properties", "pipeline", "properties", "processors", "items", "$ref").Data())
+	for _, name := range []string{"input", "buffer", "cache", "processor", "rate_limit", "lock", "output", "scanner", "lint_errors"} {
+		assert.True(t, doc.Exists("components", "schemas", name), name)
+	}
+
+	var resourceRefs []any
+	for _, v := range doc.S("paths", "/resources/{type}/{id}", "post", "requestBody", "content", "application/json", "schema", "oneOf").Children() {
+		resourceRefs = append(resourceRefs, v.S("$ref").Data())
+	}
+	assert.Contains(t, resourceRefs, "#/components/schemas/lock")
+	assert.True(t, doc.Exists("paths", "/ready", "get", "responses", "503"))
+
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData(docBytes)
+	require.NoError(t, err)
+	require.NoError(t, spec.Validate(loader.Context))
+}
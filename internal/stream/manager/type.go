@@ -102,6 +102,7 @@ func New(mgr bundle.NewManagement, opts ...func(*Type)) *Type {
 		opt(t)
 	}
 	t.registerEndpoints(t.apiEnabled)
+	t.registerEndpointSpecs(t.apiEnabled)
 	return t
 }
 
@@ -121,6 +122,7 @@ func OptAPIEnabled(b bool) func(*Type) {
 var (
 	ErrStreamExists       = errors.New("stream already exists")
 	ErrStreamDoesNotExist = errors.New("stream does not exist")
+	ErrStreamModified     = errors.New("stream was modified during update")
 )
 
 //------------------------------------------------------------------------------
@@ -199,6 +201,75 @@ func (m *Type) Update(ctx context.Context, id string, conf stream.Config) error
 	return m.Create(id, conf)
 }
 
+// UpdateBlueGreen attempts to replace an existing stream with a new version of
+// the same stream without interrupting consumption. The new stream is created
+// alongside the existing one with its input held, and once it is connected
+// consumption is switched over whilst the existing stream drains its in-flight
+// data. If the new stream fails to start, or fails to become ready within the
+// provided timeout, then it is stopped and the existing stream is left running.
+// The new stream is also stopped if the existing stream is deleted or updated
+// by another call whilst waiting for it to become ready.
+func (m *Type) UpdateBlueGreen(ctx context.Context, id string, conf stream.Config, readyTimeout time.Duration) error {
+	m.lock.Lock()
+	prev, exists := m.streams[id]
+	closed := m.closed
+	m.lock.Unlock()
+
+	if closed {
+		return component.ErrTypeClosed
+	}
+	if !exists {
+		return ErrStreamDoesNotExist
+	}
+
+	strmFlatMetrics := metrics.NewLocal()
+	sMgr := m.manager.ForStream(id).WithAddedMetrics(strmFlatMetrics)
+
+	wrapper := newStreamStatus(conf, strmFlatMetrics)
+	strm, err := stream.New(conf, sMgr, stream.OptHoldInput(), stream.OptOnClose(func() {
+		wrapper.setClosed()
+	}))
+	if err != nil {
+		return err
+	}
+	wrapper.setStream(strm)
+
+	readyCtx, done := context.WithTimeout(ctx, readyTimeout)
+	err = strm.WaitForReady(readyCtx)
+	done()
+	if err != nil {
+		_ = strm.Stop(ctx)
+		return fmt.Errorf("updated stream failed to become ready, rolled back to existing stream: %w", err)
+	}
+
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		_ = strm.Stop(ctx)
+		return component.ErrTypeClosed
+	}
+
+	// The stream may have been deleted or updated whilst we were waiting for
+	// the new one to become ready, in which case we must not overwrite it.
+	current, exists := m.streams[id]
+	if !exists || current != prev {
+		m.lock.Unlock()
+		_ = strm.Stop(ctx)
+		if !exists {
+			return ErrStreamDoesNotExist
+		}
+		return ErrStreamModified
+	}
+	m.streams[id] = wrapper
+	m.lock.Unlock()
+
+	// The switch over has already happened at this point, an error here
+	// indicates that the previous stream has not finished draining before the
+	// context deadline, which it will continue to attempt in the background.
+	_ = strm.TakeOver(ctx, prev.strm)
+	return nil
+}
+
 // Delete attempts to stop and remove a stream by its ID. Returns an error if
 // the stream was not found, or if clean shutdown fails in the specified period
 // of time.
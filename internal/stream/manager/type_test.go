@@ -2,10 +2,13 @@ package manager
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component"
@@ -113,3 +116,105 @@ func TestTypeBasicClose(t *testing.T) {
 		t.Errorf("Unexpected error: %v != %v", act, exp)
 	}
 }
+
+func TestTypeUpdateBlueGreen(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res)
+
+	require.ErrorIs(t, mgr.UpdateBlueGreen(ctx, "foo", harmlessConf(t), time.Second), ErrStreamDoesNotExist)
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+
+	badConf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    mapping: 'root = deleted()'
+output:
+  socket:
+    network: tcp
+    address: 127.0.0.1:1
+`)
+	require.NoError(t, err)
+
+	require.Error(t, mgr.UpdateBlueGreen(ctx, "foo", badConf, time.Millisecond*500))
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+	assert.Equal(t, harmlessConf(t), info.Config())
+
+	newConf := harmlessConf(t)
+	newConf.Buffer.Type = "memory"
+
+	require.NoError(t, mgr.UpdateBlueGreen(ctx, "foo", newConf, time.Second*5))
+
+	info, err = mgr.Read("foo")
+	require.NoError(t, err)
+	assert.True(t, info.IsRunning())
+	assert.Equal(t, newConf, info.Config())
+
+	require.NoError(t, mgr.Stop(ctx))
+}
+
+func TestTypeUpdateBlueGreenDeletedDuringWait(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	res, err := bmanager.New(bmanager.NewResourceConfig())
+	require.NoError(t, err)
+
+	mgr := New(res)
+	require.NoError(t, mgr.Create("foo", harmlessConf(t)))
+
+	// Reserve an address for the new stream to connect to, which we only
+	// listen on once the existing stream has been deleted.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	newConf, err := testutil.StreamFromYAML(fmt.Sprintf(`
+input:
+  generate:
+    mapping: 'root = deleted()'
+output:
+  socket:
+    network: tcp
+    address: %v
+`, addr))
+	require.NoError(t, err)
+
+	updateErr := make(chan error, 1)
+	go func() {
+		updateErr <- mgr.UpdateBlueGreen(ctx, "foo", newConf, time.Second*20)
+	}()
+
+	<-time.After(time.Millisecond * 500)
+	require.NoError(t, mgr.Delete(ctx, "foo"))
+
+	ln, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	require.ErrorIs(t, <-updateErr, ErrStreamDoesNotExist)
+
+	_, err = mgr.Read("foo")
+	require.ErrorIs(t, err, ErrStreamDoesNotExist)
+
+	require.NoError(t, mgr.Stop(ctx))
+}
@@ -0,0 +1,122 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+// ErrStreamClosed is returned when waiting on a stream that terminated before
+// becoming ready.
+var ErrStreamClosed = errors.New("stream closed before becoming ready")
+
+// inputHold prevents transactions from the input layer of a stream from
+// reaching the remaining layers until it is either released or aborted.
+type inputHold struct {
+	release     chan struct{}
+	releaseOnce sync.Once
+
+	abort     chan struct{}
+	abortOnce sync.Once
+}
+
+func newInputHold() *inputHold {
+	return &inputHold{
+		release: make(chan struct{}),
+		abort:   make(chan struct{}),
+	}
+}
+
+func (h *inputHold) Release() {
+	h.releaseOnce.Do(func() {
+		close(h.release)
+	})
+}
+
+func (h *inputHold) Abort() {
+	h.abortOnce.Do(func() {
+		close(h.abort)
+	})
+}
+
+// Wrap returns a transaction channel that only begins forwarding from the
+// provided channel once the hold is released. If the hold is aborted before
+// being released the returned channel is closed without consuming anything,
+// leaving the input free to shut down with its data unacknowledged. Aborting
+// after the hold is released has no effect.
+func (h *inputHold) Wrap(tChan <-chan message.Transaction) <-chan message.Transaction {
+	outChan := make(chan message.Transaction)
+	go func() {
+		defer close(outChan)
+
+		select {
+		case <-h.release:
+		case <-h.abort:
+			select {
+			case <-h.release:
+			default:
+				return
+			}
+		}
+
+		for t := range tChan {
+			outChan <- t
+		}
+	}()
+	return outChan
+}
+
+//------------------------------------------------------------------------------
+
+// OptHoldInput starts the stream with all components created and connected, but
+// prevents data consumed by the input layer from flowing into the rest of the
+// stream until TakeOver is called. This allows a stream to be validated before
+// it begins processing data.
+func OptHoldInput() func(*Type) {
+	return func(t *Type) {
+		t.inputHold = newInputHold()
+	}
+}
+
+// WaitForReady blocks until both the input and output layers of the stream are
+// connected, the stream is closed, or the context is cancelled.
+func (t *Type) WaitForReady(ctx context.Context) error {
+	ticker := time.NewTicker(time.Millisecond * 50)
+	defer ticker.Stop()
+
+	for {
+		if atomic.LoadUint32(&t.closed) == 1 {
+			return ErrStreamClosed
+		}
+		if t.IsReady() {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// TakeOver instructs the input layer of a previous stream to stop consuming and
+// then releases the input layer of this stream (when started with
+// OptHoldInput), switching consumption over from the previous stream. This
+// call then blocks until the previous stream has finished processing its
+// in-flight data and is fully stopped, or the context is cancelled.
+func (t *Type) TakeOver(ctx context.Context, prev *Type) error {
+	if prev != nil {
+		prev.inputLayer.TriggerStopConsuming()
+	}
+	if t.inputHold != nil {
+		t.inputHold.Release()
+	}
+	if prev == nil {
+		return nil
+	}
+	return prev.Stop(ctx)
+}
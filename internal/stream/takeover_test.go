@@ -0,0 +1,147 @@
+package stream_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/testutil"
+	"github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/message"
+	"github.com/warpstreamlabs/bento/internal/stream"
+)
+
+func readTran(ctx context.Context, t testing.TB, tChan <-chan message.Transaction) string {
+	t.Helper()
+
+	var tran message.Transaction
+	select {
+	case tran = <-tChan:
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+	require.Len(t, tran.Payload, 1)
+	require.NoError(t, tran.Ack(ctx, nil))
+	return string(tran.Payload[0].AsBytes())
+}
+
+func TestTypeTakeOver(t *testing.T) {
+	oldConf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    interval: 1ms
+    mapping: 'root = "old"'
+output:
+  inproc: foo
+`)
+	require.NoError(t, err)
+
+	newConf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    interval: 1ms
+    mapping: 'root = "new"'
+output:
+  inproc: bar
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	oldStrm, err := stream.New(oldConf, mgr)
+	require.NoError(t, err)
+
+	fooChan, err := mgr.GetPipe("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "old", readTran(ctx, t, fooChan))
+
+	newStrm, err := stream.New(newConf, mgr, stream.OptHoldInput())
+	require.NoError(t, err)
+	require.NoError(t, newStrm.WaitForReady(ctx))
+
+	barChan, err := mgr.GetPipe("bar")
+	require.NoError(t, err)
+
+	select {
+	case <-barChan:
+		t.Fatal("expected held stream to not produce data")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// Keep draining the old stream so that it can finish in-flight data.
+	drainCtx, drainDone := context.WithCancel(ctx)
+	defer drainDone()
+	go func() {
+		for {
+			select {
+			case tran, open := <-fooChan:
+				if !open {
+					return
+				}
+				_ = tran.Ack(drainCtx, nil)
+			case <-drainCtx.Done():
+				return
+			}
+		}
+	}()
+
+	require.NoError(t, newStrm.TakeOver(ctx, oldStrm))
+	assert.Equal(t, "new", readTran(ctx, t, barChan))
+
+	go func() {
+		for {
+			select {
+			case tran, open := <-barChan:
+				if !open {
+					return
+				}
+				_ = tran.Ack(drainCtx, nil)
+			case <-drainCtx.Done():
+				return
+			}
+		}
+	}()
+	require.NoError(t, newStrm.Stop(ctx))
+}
+
+func TestTypeHeldInputStop(t *testing.T) {
+	conf, err := testutil.StreamFromYAML(`
+input:
+  generate:
+    interval: 1ms
+    mapping: 'root = "held"'
+output:
+  inproc: foo
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	strm, err := stream.New(conf, mgr, stream.OptHoldInput())
+	require.NoError(t, err)
+	require.NoError(t, strm.WaitForReady(ctx))
+
+	fooChan, err := mgr.GetPipe("foo")
+	require.NoError(t, err)
+
+	select {
+	case <-fooChan:
+		t.Fatal("expected held stream to not produce data")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	stopCtx, stopDone := context.WithTimeout(ctx, time.Second*5)
+	defer stopDone()
+	require.NoError(t, strm.StopGracefully(stopCtx))
+}
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"runtime/pprof"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/buffer"
 	"github.com/warpstreamlabs/bento/internal/component/input"
 	"github.com/warpstreamlabs/bento/internal/component/output"
@@ -28,6 +32,8 @@ type Type struct {
 
 	manager bundle.NewManagement
 
+	inputHold *inputHold
+
 	onClose func()
 	closed  uint32
 }
@@ -67,9 +73,11 @@ func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, err
 		w.WriteHeader(http.StatusServiceUnavailable)
 		if !inputConnected {
 			_, _ = w.Write([]byte("input not connected\n"))
+			writeConnectionErrors(w, inputStatuses)
 		}
 		if !outputConnected {
 			_, _ = w.Write([]byte("output not connected\n"))
+			writeConnectionErrors(w, outputStatuses)
 		}
 	}
 	t.manager.RegisterEndpoint(
@@ -80,6 +88,17 @@ func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, err
 	return t, nil
 }
 
+// writeConnectionErrors writes the path and error of each connection that is
+// failing for a known reason, such as an open circuit breaker.
+func writeConnectionErrors(w io.Writer, statuses component.ConnectionStatuses) {
+	for _, s := range statuses {
+		if s.Connected || s.Err == nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "  %v: %v\n", strings.Join(s.Path, "."), s.Err)
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // OptOnClose sets a closure to be called when the stream closes.
@@ -124,6 +143,9 @@ func (t *Type) start() (err error) {
 	var nextTranChan <-chan message.Transaction
 
 	nextTranChan = t.inputLayer.TransactionChan()
+	if t.inputHold != nil {
+		nextTranChan = t.inputHold.Wrap(nextTranChan)
+	}
 	if t.bufferLayer != nil {
 		if err = t.bufferLayer.Consume(nextTranChan); err != nil {
 			return
@@ -158,6 +180,9 @@ func (t *Type) start() (err error) {
 // proxy. This should guarantee that all in-flight and buffered data is resolved
 // before shutting down.
 func (t *Type) StopGracefully(ctx context.Context) (err error) {
+	if t.inputHold != nil {
+		t.inputHold.Abort()
+	}
 	t.inputLayer.TriggerStopConsuming()
 	if err = t.inputLayer.WaitForClose(ctx); err != nil {
 		return
@@ -189,6 +214,9 @@ func (t *Type) StopGracefully(ctx context.Context) (err error) {
 // the stream to gracefully wind down in the order of component layers. This
 // should only be attempted if both stopGracefully and stopOrdered failed.
 func (t *Type) StopUnordered(ctx context.Context) (err error) {
+	if t.inputHold != nil {
+		t.inputHold.Abort()
+	}
 	t.inputLayer.TriggerCloseNow()
 	if t.bufferLayer != nil {
 		t.bufferLayer.TriggerCloseNow()
@@ -0,0 +1,153 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+// Budget limits the number of message bytes held in flight, from the moment
+// they are read by an input until they are acknowledged. A budget may have a
+// parent, in which case bytes reserved from the budget are also reserved from
+// its parent, allowing a global budget to be subdivided into quotas.
+//
+// Reservations are never refused, instead callers are expected to Wait for
+// capacity before reading more data. This means a single message larger than
+// the budget is still able to pass through, but nothing else will be read
+// until it has been acknowledged.
+//
+// A nil budget is valid and unlimited, and does not track usage.
+type Budget struct {
+	limit  int64
+	parent *Budget
+
+	mut     sync.Mutex
+	used    int64
+	changed chan struct{}
+
+	mUsed metrics.StatGauge
+}
+
+// NewBudget creates a budget that permits limit bytes to be in flight, where a
+// limit of zero or less means the budget is unlimited and only tracks usage.
+// The parent may be nil, and the gauge reports the current usage.
+func NewBudget(limit int64, parent *Budget, mUsed metrics.StatGauge) *Budget {
+	return &Budget{
+		limit:   limit,
+		parent:  parent,
+		changed: make(chan struct{}),
+		mUsed:   mUsed,
+	}
+}
+
+// Limit returns the number of bytes permitted in flight by this budget, which
+// is zero or less when unlimited.
+func (b *Budget) Limit() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.limit
+}
+
+// Used returns the number of bytes currently reserved from this budget.
+func (b *Budget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.used
+}
+
+// Wait blocks until both this budget and all of its parents have capacity for
+// more bytes, or until the context is cancelled.
+func (b *Budget) Wait(ctx context.Context) error {
+	for l := b; l != nil; l = l.parent {
+		if err := l.waitLevel(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Budget) waitLevel(ctx context.Context) error {
+	if b == nil || b.limit <= 0 {
+		return nil
+	}
+	for {
+		b.mut.Lock()
+		if b.used < b.limit {
+			b.mut.Unlock()
+			return nil
+		}
+		changed := b.changed
+		b.mut.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Reserve adds n bytes to the usage of this budget and all of its parents,
+// returning a func that releases them. The release func is safe to call more
+// than once, subsequent calls have no effect.
+func (b *Budget) Reserve(n int64) (release func()) {
+	if b == nil {
+		return func() {}
+	}
+	b.Add(n)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.Add(-n)
+		})
+	}
+}
+
+// Add adjusts the usage of this budget and all of its parents by n bytes,
+// where a negative n releases bytes that were previously added. This is useful
+// for components such as buffers that hold bytes beyond the lifetime of a
+// single transaction, otherwise Reserve should be preferred.
+func (b *Budget) Add(n int64) {
+	for l := b; l != nil; l = l.parent {
+		l.add(n)
+	}
+}
+
+func (b *Budget) add(n int64) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	b.used += n
+	b.mUsed.Set(b.used)
+	if n < 0 {
+		close(b.changed)
+		b.changed = make(chan struct{})
+	}
+}
+
+// NewTransaction creates a transaction for a payload read by an input, where
+// the bytes of the payload are reserved from the budget until the transaction
+// is acknowledged. The returned func releases the reservation and must be
+// called if the transaction is never delivered, it has no effect once the
+// transaction has been acknowledged.
+func (b *Budget) NewTransaction(payload message.Batch, resChan chan<- error) (message.Transaction, func()) {
+	if b == nil {
+		return message.NewTransaction(payload, resChan), func() {}
+	}
+	release := b.Reserve(int64(payload.ByteSize()))
+	return message.NewTransactionFunc(payload, func(ctx context.Context, err error) error {
+		defer release()
+		select {
+		case resChan <- err:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	}), release
+}
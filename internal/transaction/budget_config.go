@@ -0,0 +1,70 @@
+package transaction
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+const (
+	fieldBudgetLimit       = "limit"
+	fieldBudgetStreamQuota = "stream_quota"
+)
+
+// BudgetConfig holds configuration options for the global memory budget.
+type BudgetConfig struct {
+	Limit       string `yaml:"limit"`
+	StreamQuota string `yaml:"stream_quota"`
+}
+
+// NewBudgetConfig returns a config struct with the default values for each
+// field.
+func NewBudgetConfig() BudgetConfig {
+	return BudgetConfig{
+		Limit:       "",
+		StreamQuota: "",
+	}
+}
+
+// BudgetSpec returns the field specs of a BudgetConfig.
+func BudgetSpec() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldString(fieldBudgetLimit, "The maximum number of message bytes to hold in flight across all inputs, from the moment they are read until they are acknowledged. Once exceeded, inputs stop reading until messages are acknowledged. Leave empty to disable.", "512MiB", "2GB").HasDefault(""),
+		docs.FieldString(fieldBudgetStreamQuota, "When running in streams mode, the maximum number of message bytes each stream may hold in flight, in addition to the global limit. Leave empty to disable.", "64MiB").HasDefault(""),
+	}
+}
+
+// BudgetConfigFromParsed extracts a BudgetConfig from a parsed config.
+func BudgetConfigFromParsed(pConf *docs.ParsedConfig) (conf BudgetConfig, err error) {
+	if conf.Limit, err = pConf.FieldString(fieldBudgetLimit); err != nil {
+		return
+	}
+	if conf.StreamQuota, err = pConf.FieldString(fieldBudgetStreamQuota); err != nil {
+		return
+	}
+	return
+}
+
+// LimitBytes returns the global limit in bytes, or zero if unlimited.
+func (c BudgetConfig) LimitBytes() (int64, error) {
+	return parseBudgetBytes(fieldBudgetLimit, c.Limit)
+}
+
+// StreamQuotaBytes returns the per stream quota in bytes, or zero if
+// unlimited.
+func (c BudgetConfig) StreamQuotaBytes() (int64, error) {
+	return parseBudgetBytes(fieldBudgetStreamQuota, c.StreamQuota)
+}
+
+func parseBudgetBytes(field, v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := humanize.ParseBytes(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse memory_budget.%v: %w", field, err)
+	}
+	return int64(n), nil
+}
@@ -0,0 +1,121 @@
+package transaction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/message"
+)
+
+func TestBudgetWaitReserve(t *testing.T) {
+	stats := metrics.NewLocal()
+	b := NewBudget(100, nil, stats.GetGauge("used"))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, b.Wait(ctx))
+	releaseA := b.Reserve(60)
+	require.NoError(t, b.Wait(ctx))
+	releaseB := b.Reserve(60)
+	assert.Equal(t, int64(120), b.Used())
+	assert.Equal(t, int64(120), stats.GetCounters()["used"])
+
+	blockedCtx, blockedDone := context.WithTimeout(ctx, time.Millisecond*10)
+	require.ErrorIs(t, b.Wait(blockedCtx), context.DeadlineExceeded)
+	blockedDone()
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- b.Wait(ctx)
+	}()
+
+	releaseA()
+	releaseA()
+	require.NoError(t, <-waitErr)
+	assert.Equal(t, int64(60), b.Used())
+
+	releaseB()
+	assert.Equal(t, int64(0), b.Used())
+	assert.Equal(t, int64(0), stats.GetCounters()["used"])
+}
+
+func TestBudgetParent(t *testing.T) {
+	parent := NewBudget(100, nil, metrics.Noop().GetGauge("parent"))
+	childA := NewBudget(50, parent, metrics.Noop().GetGauge("a"))
+	childB := NewBudget(0, parent, metrics.Noop().GetGauge("b"))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	releaseA := childA.Reserve(50)
+	assert.Equal(t, int64(50), childA.Used())
+	assert.Equal(t, int64(50), parent.Used())
+
+	// The quota of a child is exhausted without affecting its siblings.
+	blockedCtx, blockedDone := context.WithTimeout(ctx, time.Millisecond*10)
+	require.ErrorIs(t, childA.Wait(blockedCtx), context.DeadlineExceeded)
+	blockedDone()
+	require.NoError(t, childB.Wait(ctx))
+
+	// Exhausting the parent blocks all children.
+	releaseB := childB.Reserve(60)
+	blockedCtx, blockedDone = context.WithTimeout(ctx, time.Millisecond*10)
+	require.ErrorIs(t, childB.Wait(blockedCtx), context.DeadlineExceeded)
+	blockedDone()
+
+	releaseA()
+	require.NoError(t, childA.Wait(ctx))
+	require.NoError(t, childB.Wait(ctx))
+
+	releaseB()
+	assert.Equal(t, int64(0), parent.Used())
+}
+
+func TestBudgetNewTransaction(t *testing.T) {
+	b := NewBudget(100, nil, metrics.Noop().GetGauge("used"))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	resChan := make(chan error, 1)
+	tran, release := b.NewTransaction(message.QuickBatch([][]byte{[]byte("foo"), []byte("barbaz")}), resChan)
+	assert.Equal(t, int64(9), b.Used())
+
+	require.NoError(t, tran.Ack(ctx, nil))
+	require.NoError(t, <-resChan)
+	assert.Equal(t, int64(0), b.Used())
+
+	// Releasing after acknowledgement has no effect.
+	release()
+	assert.Equal(t, int64(0), b.Used())
+
+	// Transactions that are never delivered are released by the caller.
+	_, release = b.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), resChan)
+	assert.Equal(t, int64(3), b.Used())
+	release()
+	assert.Equal(t, int64(0), b.Used())
+}
+
+func TestBudgetNil(t *testing.T) {
+	var b *Budget
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, b.Wait(ctx))
+	b.Reserve(10)()
+	b.Add(10)
+	assert.Equal(t, int64(0), b.Used())
+
+	resChan := make(chan error, 1)
+	tran, release := b.NewTransaction(message.QuickBatch([][]byte{[]byte("foo")}), resChan)
+	release()
+	require.NoError(t, tran.Ack(ctx, nil))
+	require.NoError(t, <-resChan)
+}
@@ -68,6 +68,24 @@ func (e *Environment) Parse(blobl string) (*Executor, error) {
 	return newExecutor(exec), nil
 }
 
+// Format parses a Bloblang mapping using the Environment and returns it in a
+// canonical format, where whitespace between tokens and indentation are
+// normalised whilst comments and line breaks are preserved.
+//
+// When a parsing error occurs the error will be the type *ParseError, which
+// gives access to the line and column where the error occurred, as well as a
+// method for creating a well formatted error message.
+func (e *Environment) Format(blobl string) (string, error) {
+	formatted, err := e.env.FormatMapping(blobl)
+	if err != nil {
+		if pErr, ok := err.(*parser.Error); ok {
+			return "", internalToPublicParserError([]rune(blobl), pErr)
+		}
+		return "", err
+	}
+	return formatted, nil
+}
+
 // CheckInterpolatedString attempts to parse a Bloblang interpolated string
 // using the Environment to determine the features (functions and methods)
 // available to it.
@@ -136,6 +154,7 @@ func methodSpecFromPublic(name string, spec *PluginSpec) query.MethodSpec {
 		iSpec = iSpec.MarkImpure()
 	}
 	iSpec.Params = spec.params
+	iSpec.ReturnTypes = spec.returns
 	return iSpec
 }
 
@@ -240,6 +259,7 @@ func functionSpecFromPublic(name string, spec *PluginSpec) query.FunctionSpec {
 		iSpec = iSpec.MarkImpure()
 	}
 	iSpec.Params = spec.params
+	iSpec.ReturnTypes = spec.returns
 	return iSpec
 }
 
@@ -377,6 +397,18 @@ func Parse(blobl string) (*Executor, error) {
 	return newExecutor(exec), nil
 }
 
+// Format parses a Bloblang mapping allowing the use of the globally accessible
+// range of features (functions and methods) and returns it in a canonical
+// format, where whitespace between tokens and indentation are normalised whilst
+// comments and line breaks are preserved.
+//
+// When a parsing error occurs the error will be the type *ParseError, which
+// gives access to the line and column where the error occurred, as well as a
+// method for creating a well formatted error message.
+func Format(blobl string) (string, error) {
+	return GlobalEnvironment().Format(blobl)
+}
+
 // RegisterMethod adds a new Bloblang method to the global environment. All
 // method names must match the regular expression /^[a-z0-9]+(_[a-z0-9]+)*$/
 // (snake case).
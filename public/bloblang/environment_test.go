@@ -106,3 +106,61 @@ func TestEnvironmentDisabledImports(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "imports are disabled in this context")
 }
+
+func TestEnvironmentFormat(t *testing.T) {
+	env := NewEmptyEnvironment()
+
+	require.NoError(t, env.RegisterMethod("foo", func(_ ...any) (Method, error) {
+		return StringMethod(func(s string) (any, error) {
+			return "foo:" + s, nil
+		}), nil
+	}))
+
+	formatted, err := env.Format(`
+# Add a prefix
+root.a = this.a.foo( )   # inline
+root.b   = [1,2]
+`)
+	require.NoError(t, err)
+	assert.Equal(t, `# Add a prefix
+root.a = this.a.foo() # inline
+root.b = [1, 2]
+`, formatted)
+
+	_, err = env.Format(`root = now()`)
+	require.Error(t, err)
+
+	var pErr *ParseError
+	require.ErrorAs(t, err, &pErr)
+	assert.Equal(t, 1, pErr.Line)
+}
+
+func TestEnvironmentTypeCheck(t *testing.T) {
+	env := NewEnvironment()
+
+	require.NoError(t, env.RegisterFunctionV2("answer", NewPluginSpec().Returns(ValueNumber), func(_ *ParsedParams) (Function, error) {
+		return func() (any, error) {
+			return 42, nil
+		}, nil
+	}))
+
+	issues, err := env.TypeCheck(`root.a = answer().uppercase()
+root.b = this.name.floor()
+root.c = match this.name {
+  _ => "foo"
+  "bar" => "baz"
+}`, []byte(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []TypeIssue{
+		{Line: 1, Column: 1, Message: "function answer: expected string or bytes value, got number"},
+		{Line: 2, Column: 1, Message: "field `this.name`: expected number value, got string"},
+		{Line: 3, Column: 1, Unreachable: true, Message: "match case 1 is unreachable as a previous case always matches"},
+	}, issues)
+
+	_, err = env.TypeCheck(`root = `, nil)
+	var pErr *ParseError
+	require.ErrorAs(t, err, &pErr)
+
+	_, err = env.TypeCheck(`root = this`, []byte(`not json`))
+	require.Error(t, err)
+}
@@ -5,6 +5,23 @@ import (
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+	"github.com/warpstreamlabs/bento/internal/value"
+)
+
+// ValueType describes a type of value that a function or method returns,
+// which is used during static type checking of mappings.
+type ValueType string
+
+// ValueType variants.
+var (
+	ValueString    ValueType = ValueType(value.TString)
+	ValueBytes     ValueType = ValueType(value.TBytes)
+	ValueNumber    ValueType = ValueType(value.TNumber)
+	ValueBool      ValueType = ValueType(value.TBool)
+	ValueTimestamp ValueType = ValueType(value.TTimestamp)
+	ValueArray     ValueType = ValueType(value.TArray)
+	ValueObject    ValueType = ValueType(value.TObject)
+	ValueNull      ValueType = ValueType(value.TNull)
 )
 
 // ParamDefinition describes a single parameter for a function or method.
@@ -119,6 +136,7 @@ type PluginSpec struct {
 	params      query.Params
 	examples    []pluginExample
 	version     string
+	returns     []value.Type
 }
 
 type pluginExample struct {
@@ -231,6 +249,17 @@ func (p *PluginSpec) Param(def ParamDefinition) *PluginSpec {
 	return p
 }
 
+// Returns declares the types of value that the plugin may return, which allows
+// mappings that use the plugin to be statically type checked. When omitted the
+// return type is considered unknown.
+func (p *PluginSpec) Returns(types ...ValueType) *PluginSpec {
+	p.returns = p.returns[:0]
+	for _, t := range types {
+		p.returns = append(p.returns, value.Type(t))
+	}
+	return p
+}
+
 // Impure marks the plugin as "impure", meaning it either reads from or
 // interacts with state outside of the boundaries of a single mapping
 // invocation. This usually means reading state from the machine. Impure plugins
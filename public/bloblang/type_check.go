@@ -0,0 +1,69 @@
+package bloblang
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/warpstreamlabs/bento/internal/bloblang/parser"
+	"github.com/warpstreamlabs/bento/internal/bloblang/query"
+)
+
+// TypeIssue describes a problem found during static type checking of a
+// mapping.
+type TypeIssue struct {
+	// The line and column of the statement that the issue was found within.
+	Line   int
+	Column int
+
+	// Unreachable is true when the issue describes a branch of the mapping
+	// that can never be taken, and false when it describes a type error that
+	// will occur whenever the statement is executed.
+	Unreachable bool
+
+	Message string
+}
+
+// TypeCheck parses a Bloblang mapping using the Environment and performs
+// static type checking on it, returning any type errors that will occur
+// regardless of the input as well as any branches that can never be taken.
+//
+// An optional JSON schema can be provided that describes the input document,
+// which allows the types of input fields referenced by the mapping to be
+// inferred. When omitted the input document is considered to be of an unknown
+// type.
+//
+// When a parsing error occurs the error will be the type *ParseError, which
+// gives access to the line and column where the error occurred, as well as a
+// method for creating a well formatted error message.
+//
+// Experimental: This method is not intended for general use and could have its
+// signature and/or behaviour changed outside of major version bumps.
+func (e *Environment) TypeCheck(blobl string, inputSchema []byte) ([]TypeIssue, error) {
+	var input query.StaticType
+	if len(inputSchema) > 0 {
+		var schema map[string]any
+		if err := json.Unmarshal(inputSchema, &schema); err != nil {
+			return nil, fmt.Errorf("failed to parse input schema: %w", err)
+		}
+		input = query.StaticTypeFromJSONSchema(schema)
+	}
+
+	iIssues, err := e.env.CheckMappingTypes(blobl, input)
+	if err != nil {
+		if pErr, ok := err.(*parser.Error); ok {
+			return nil, internalToPublicParserError([]rune(blobl), pErr)
+		}
+		return nil, err
+	}
+
+	issues := make([]TypeIssue, len(iIssues))
+	for i, issue := range iIssues {
+		issues[i] = TypeIssue{
+			Line:        issue.Line,
+			Column:      issue.Column,
+			Unreachable: issue.Unreachable,
+			Message:     issue.Message,
+		}
+	}
+	return issues, nil
+}
@@ -57,6 +57,7 @@ import (
 	_ "github.com/warpstreamlabs/bento/public/components/sql"
 	_ "github.com/warpstreamlabs/bento/public/components/statsd"
 	_ "github.com/warpstreamlabs/bento/public/components/twitter"
+	_ "github.com/warpstreamlabs/bento/public/components/vault"
 	_ "github.com/warpstreamlabs/bento/public/components/wasm"
 	_ "github.com/warpstreamlabs/bento/public/components/zeromq"
 )
@@ -0,0 +1,6 @@
+package vault
+
+import (
+	// Bring in the internal plugin definitions.
+	_ "github.com/warpstreamlabs/bento/internal/impl/vault"
+)
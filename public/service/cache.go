@@ -14,6 +14,11 @@ import (
 var (
 	ErrKeyAlreadyExists = errors.New("key already exists")
 	ErrKeyNotFound      = errors.New("key does not exist")
+	ErrValueMismatch    = errors.New("key value does not match")
+
+	// ErrCacheOperationNotSupported is returned when an optional cache
+	// operation is not supported by the underlying cache.
+	ErrCacheOperationNotSupported = errors.New("operation not supported by cache")
 )
 
 // Cache is an interface implemented by Bento caches.
@@ -55,17 +60,70 @@ type batchedCache interface {
 	SetMulti(ctx context.Context, keyValues ...CacheItem) error
 }
 
+// MultiGetCache is an interface implemented by Bento caches that are able to
+// retrieve multiple keys in as few requests as possible. Caches that do not
+// implement it are read one key at a time.
+type MultiGetCache interface {
+	// GetMulti attempts to retrieve multiple cache items, keys that do not
+	// exist are omitted from the result.
+	GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error)
+
+	Cache
+}
+
+// IncrementCache is an interface implemented by Bento caches that are able to
+// atomically increment a counter. Caches that do not implement it are
+// incremented with a Get followed by a Set, which is not atomic.
+type IncrementCache interface {
+	// Increment atomically adds delta to the integer value of a key and returns
+	// the result. A key that does not exist is treated as zero, and the TTL is
+	// applied when the key is created.
+	Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error)
+
+	Cache
+}
+
+// CompareAndSwapCache is an interface implemented by Bento caches that are able
+// to atomically replace the value of a key only when it matches an expected
+// value.
+type CompareAndSwapCache interface {
+	// CompareAndSwap sets the value of a key only if its current value matches
+	// old, where a nil old value means the key must not exist. Returns
+	// ErrValueMismatch if the current value does not match.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error
+
+	Cache
+}
+
+// ScanCache is an interface implemented by Bento caches that are able to list
+// keys by prefix.
+type ScanCache interface {
+	// Scan returns all cache items where the key begins with prefix.
+	Scan(ctx context.Context, prefix string) (map[string][]byte, error)
+
+	Cache
+}
+
 //------------------------------------------------------------------------------
 
 // Implements types.Cache.
 type airGapCache struct {
 	c  Cache
 	cm batchedCache
+
+	mg  MultiGetCache
+	inc IncrementCache
+	cas CompareAndSwapCache
+	sc  ScanCache
 }
 
 func newAirGapCache(c Cache, stats metrics.Type) cache.V1 {
 	ag := &airGapCache{c: c, cm: nil}
 	ag.cm, _ = c.(batchedCache)
+	ag.mg, _ = c.(MultiGetCache)
+	ag.inc, _ = c.(IncrementCache)
+	ag.cas, _ = c.(CompareAndSwapCache)
+	ag.sc, _ = c.(ScanCache)
 	return cache.MetricsForCache(ag, stats)
 }
 
@@ -113,6 +171,47 @@ func (a *airGapCache) Delete(ctx context.Context, key string) error {
 	return a.c.Delete(ctx, key)
 }
 
+func (a *airGapCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	if a.mg == nil {
+		return nil, cache.ErrNotSupported
+	}
+	res, err := a.mg.GetMulti(ctx, keys...)
+	return res, airGapCacheErr(err)
+}
+
+func (a *airGapCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	if a.inc == nil {
+		return 0, cache.ErrNotSupported
+	}
+	v, err := a.inc.Increment(ctx, key, delta, ttl)
+	return v, airGapCacheErr(err)
+}
+
+func (a *airGapCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	if a.cas == nil {
+		return cache.ErrNotSupported
+	}
+	return airGapCacheErr(a.cas.CompareAndSwap(ctx, key, old, value, ttl))
+}
+
+func (a *airGapCache) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	if a.sc == nil {
+		return nil, cache.ErrNotSupported
+	}
+	res, err := a.sc.Scan(ctx, prefix)
+	return res, airGapCacheErr(err)
+}
+
+func airGapCacheErr(err error) error {
+	switch {
+	case errors.Is(err, ErrValueMismatch):
+		return component.ErrValueMismatch
+	case errors.Is(err, ErrCacheOperationNotSupported):
+		return cache.ErrNotSupported
+	}
+	return err
+}
+
 func (a *airGapCache) Close(ctx context.Context) error {
 	return a.c.Close(ctx)
 }
@@ -151,6 +250,34 @@ func (r *reverseAirGapCache) Delete(ctx context.Context, key string) error {
 	return r.c.Delete(ctx, key)
 }
 
+func (r *reverseAirGapCache) GetMulti(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	return cache.GetMulti(ctx, r.c, keys...)
+}
+
+func (r *reverseAirGapCache) Increment(ctx context.Context, key string, delta int64, ttl *time.Duration) (int64, error) {
+	v, err := cache.Increment(ctx, r.c, key, delta, ttl)
+	return v, reverseCacheErr(err)
+}
+
+func (r *reverseAirGapCache) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	return reverseCacheErr(cache.CompareAndSwap(ctx, r.c, key, old, value, ttl))
+}
+
+func (r *reverseAirGapCache) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	res, err := cache.Scan(ctx, r.c, prefix)
+	return res, reverseCacheErr(err)
+}
+
+func reverseCacheErr(err error) error {
+	switch {
+	case errors.Is(err, component.ErrValueMismatch):
+		return ErrValueMismatch
+	case errors.Is(err, cache.ErrNotSupported):
+		return ErrCacheOperationNotSupported
+	}
+	return err
+}
+
 func (r *reverseAirGapCache) Close(ctx context.Context) error {
 	return r.c.Close(ctx)
 }
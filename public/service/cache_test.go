@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
@@ -490,3 +492,80 @@ func TestCacheReverseAirGapDelete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, map[string]testCacheItem{}, rl.m)
 }
+
+type closableCacheAtomic struct {
+	*closableCache
+}
+
+func (c *closableCacheAtomic) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl *time.Duration) error {
+	if i, ok := c.m[key]; ok != (old != nil) || (ok && string(i.b) != string(old)) {
+		return ErrValueMismatch
+	}
+	c.m[key] = testCacheItem{b: value, ttl: ttl}
+	return nil
+}
+
+func (c *closableCacheAtomic) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	res := map[string][]byte{}
+	for k, v := range c.m {
+		if strings.HasPrefix(k, prefix) {
+			res[k] = v.b
+		}
+	}
+	return res, nil
+}
+
+func TestCacheAirGapOptionalOperations(t *testing.T) {
+	ctx := context.Background()
+	rl := &closableCacheAtomic{
+		closableCache: &closableCache{
+			m: map[string]testCacheItem{
+				"foo": {b: []byte("bar")},
+				"baz": {b: []byte("buz")},
+			},
+		},
+	}
+	agrl := newAirGapCache(rl, metrics.Noop())
+
+	err := agrl.(cache.CompareAndSwapper).CompareAndSwap(ctx, "foo", []byte("nope"), []byte("qux"), nil)
+	require.ErrorIs(t, err, component.ErrValueMismatch)
+
+	err = agrl.(cache.CompareAndSwapper).CompareAndSwap(ctx, "foo", []byte("bar"), []byte("qux"), nil)
+	require.NoError(t, err)
+
+	res, err := agrl.(cache.Scanner).Scan(ctx, "f")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("qux")}, res)
+
+	// Operations the cache doesn't implement fall back to the basic methods.
+	res, err = agrl.(cache.MultiGetter).GetMulti(ctx, "foo", "baz", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("qux"), "baz": []byte("buz")}, res)
+
+	// Increments cannot be performed atomically with the basic methods.
+	_, err = agrl.(cache.Incrementer).Increment(ctx, "counter", 3, nil)
+	require.ErrorIs(t, err, cache.ErrNotSupported)
+}
+
+func TestCacheReverseAirGapOptionalOperations(t *testing.T) {
+	ctx := context.Background()
+	rl := &closableCacheType{
+		m: map[string]testCacheItem{
+			"foo": {b: []byte("bar")},
+		},
+	}
+	agrl := newReverseAirGapCache(rl)
+
+	res, err := agrl.GetMulti(ctx, "foo", "nope")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foo": []byte("bar")}, res)
+
+	_, err = agrl.Increment(ctx, "counter", 2, nil)
+	require.ErrorIs(t, err, ErrCacheOperationNotSupported)
+
+	require.ErrorIs(t, agrl.CompareAndSwap(ctx, "foo", nil, []byte("baz"), nil), ErrValueMismatch)
+	require.ErrorIs(t, agrl.CompareAndSwap(ctx, "foo", []byte("bar"), []byte("baz"), nil), ErrCacheOperationNotSupported)
+
+	_, err = agrl.Scan(ctx, "f")
+	require.ErrorIs(t, err, ErrCacheOperationNotSupported)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/warpstreamlabs/bento/internal/bundle"
@@ -524,6 +525,7 @@ func (c *ConfigView) RenderDocs() ([]byte, error) {
 	_, rootOnly := map[string]struct{}{
 		"cache":      {},
 		"rate_limit": {},
+		"lock":       {},
 		"processor":  {},
 		"scanner":    {},
 	}[string(c.component.Type)]
@@ -591,10 +593,11 @@ func (p *ParsedConfig) FieldAnyList(path ...string) ([]*ParsedConfig, error) {
 		return nil, err
 	}
 
+	tmpMgr := p.mgr.IntoPath(path...)
 	pl := make([]*ParsedConfig, len(il))
 	for i, v := range il {
 		pl[i] = &ParsedConfig{
-			mgr: p.mgr,
+			mgr: tmpMgr.IntoPath(strconv.Itoa(i)),
 			i:   v,
 		}
 	}
@@ -611,10 +614,11 @@ func (p *ParsedConfig) FieldAnyMap(path ...string) (map[string]*ParsedConfig, er
 		return nil, err
 	}
 
+	tmpMgr := p.mgr.IntoPath(path...)
 	pm := make(map[string]*ParsedConfig, len(im))
 	for k, v := range im {
 		pm[k] = &ParsedConfig{
-			mgr: p.mgr,
+			mgr: tmpMgr.IntoPath(k),
 			i:   v,
 		}
 	}
@@ -713,11 +717,12 @@ func (p *ParsedConfig) FieldObjectList(path ...string) ([]*ParsedConfig, error)
 		return nil, err
 	}
 
+	tmpMgr := p.mgr.IntoPath(path...)
 	pl := make([]*ParsedConfig, len(il))
 	for i, v := range il {
 		pl[i] = &ParsedConfig{
 			i:   v,
-			mgr: p.mgr,
+			mgr: tmpMgr.IntoPath(strconv.Itoa(i)),
 		}
 	}
 	return pl, nil
@@ -733,11 +738,12 @@ func (p *ParsedConfig) FieldObjectMap(path ...string) (map[string]*ParsedConfig,
 		return nil, err
 	}
 
+	tmpMgr := p.mgr.IntoPath(path...)
 	pl := make(map[string]*ParsedConfig, len(im))
 	for k, v := range im {
 		pl[k] = &ParsedConfig{
 			i:   v,
-			mgr: p.mgr,
+			mgr: tmpMgr.IntoPath(k),
 		}
 	}
 	return pl, nil
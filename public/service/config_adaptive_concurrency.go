@@ -0,0 +1,78 @@
+package service
+
+import (
+	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component/output"
+)
+
+const (
+	acField                 = "adaptive_concurrency"
+	acFieldEnabled          = "enabled"
+	acFieldMin              = "min"
+	acFieldMax              = "max"
+	acFieldLatencyTolerance = "latency_tolerance"
+	acFieldBackoffRatio     = "backoff_ratio"
+)
+
+// NewOutputAdaptiveConcurrencyField creates a common field for enabling
+// adaptive concurrency on an output, where the number of messages in flight
+// begins at the value of max_in_flight and is then adjusted according to the
+// latency and errors of writes. Outputs registered with RegisterOutput or
+// RegisterBatchOutput that include this field have adaptive concurrency
+// applied automatically.
+func NewOutputAdaptiveConcurrencyField() *ConfigField {
+	defaults := output.NewAdaptiveConcurrencyConfig()
+	return NewObjectField(acField,
+		NewBoolField(acFieldEnabled).
+			Description("Whether to adjust the number of messages in flight adaptively, beginning at `max_in_flight`.").
+			Default(false),
+		NewIntField(acFieldMin).
+			Description("The minimum number of messages to have in flight.").
+			Default(defaults.Min),
+		NewIntField(acFieldMax).
+			Description("The maximum number of messages to have in flight.").
+			Default(defaults.Max),
+		NewFloatField(acFieldLatencyTolerance).
+			Description("The ratio of write latency against the lowest latency observed beyond which the output is considered overloaded and the limit is reduced.").
+			Default(defaults.LatencyTolerance),
+		NewFloatField(acFieldBackoffRatio).
+			Description("The ratio the limit is multiplied by when the output is overloaded or a write fails.").
+			Default(defaults.BackoffRatio),
+	).Description("Adjusts the number of messages in flight with an additive increase, multiplicative decrease (AIMD) algorithm. The limit grows by one as writes succeed at the current limit, and is reduced when writes fail or their latency exceeds the tolerance. The current limit is reported with the gauge `output_concurrency_limit`. When messages are sent as batches the limit applies to the number of batches in flight, where each batch counts as a single write.").
+		Advanced()
+}
+
+// outputAdaptiveConcurrency extracts an adaptive concurrency config from a
+// parsed config defined with NewOutputAdaptiveConcurrencyField, returning
+// false if the field is absent or disabled.
+func (p *ParsedConfig) outputAdaptiveConcurrency() (conf output.AdaptiveConcurrencyConfig, enabled bool, err error) {
+	if !p.Contains(acField) {
+		return
+	}
+	pConf := p.Namespace(acField)
+	if enabled, err = pConf.FieldBool(acFieldEnabled); err != nil || !enabled {
+		return
+	}
+	if conf.Min, err = pConf.FieldInt(acFieldMin); err != nil {
+		return
+	}
+	if conf.Max, err = pConf.FieldInt(acFieldMax); err != nil {
+		return
+	}
+	if conf.LatencyTolerance, err = pConf.FieldFloat(acFieldLatencyTolerance); err != nil {
+		return
+	}
+	conf.BackoffRatio, err = pConf.FieldFloat(acFieldBackoffRatio)
+	return
+}
+
+func newOutputAsyncWriter(typeStr string, maxInFlight int, w output.AsyncSink, pConf *ParsedConfig, nm bundle.NewManagement) (output.Streamed, error) {
+	acConf, enabled, err := pConf.outputAdaptiveConcurrency()
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return output.NewAdaptiveAsyncWriter(typeStr, maxInFlight, acConf, w, nm)
+	}
+	return output.NewAsyncWriter(typeStr, maxInFlight, w, nm)
+}
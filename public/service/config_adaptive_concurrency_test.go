@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component/output"
+)
+
+func TestConfigAdaptiveConcurrency(t *testing.T) {
+	spec := NewConfigSpec().
+		Field(NewOutputMaxInFlightField()).
+		Field(NewOutputAdaptiveConcurrencyField())
+
+	parsedConfig, err := spec.ParseYAML(`
+max_in_flight: 10
+adaptive_concurrency:
+  enabled: true
+  max: 100
+`, nil)
+	require.NoError(t, err)
+
+	conf, enabled, err := parsedConfig.outputAdaptiveConcurrency()
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	assert.Equal(t, output.AdaptiveConcurrencyConfig{
+		Min:              1,
+		Max:              100,
+		LatencyTolerance: 2,
+		BackoffRatio:     0.5,
+	}, conf)
+
+	parsedConfig, err = spec.ParseYAML(`
+max_in_flight: 10
+`, nil)
+	require.NoError(t, err)
+
+	_, enabled, err = parsedConfig.outputAdaptiveConcurrency()
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	parsedConfig, err = NewConfigSpec().ParseYAML(`{}`, nil)
+	require.NoError(t, err)
+
+	_, enabled, err = parsedConfig.outputAdaptiveConcurrency()
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"github.com/warpstreamlabs/bento/internal/docs"
+)
+
+// MigrationRule describes a single step in rewriting the config of a component
+// for use with a replacement component. Paths are dot separated and relative
+// to the config of the component being migrated.
+type MigrationRule struct {
+	rule docs.MigrationRule
+}
+
+// NewMigrationRename creates a migration rule that moves the value of a field
+// to a new path.
+func NewMigrationRename(from, to string) *MigrationRule {
+	return &MigrationRule{rule: docs.MigrateRename(from, to)}
+}
+
+// NewMigrationRemove creates a migration rule that removes a field, this should
+// only be used for fields that do not change the behaviour of a component.
+func NewMigrationRemove(path string) *MigrationRule {
+	return &MigrationRule{rule: docs.MigrateRemove(path)}
+}
+
+// NewMigrationMapValue creates a migration rule that replaces the value of a
+// scalar field with a value from a map, optionally moving it to a new path.
+// When the field is absent its default value is mapped instead. Mapping a
+// value to nil removes the field, and values that are not present in the map
+// prevent the component from being migrated.
+func NewMigrationMapValue(from, to string, values map[string]any) *MigrationRule {
+	return &MigrationRule{rule: docs.MigrateMapValue(from, to, values)}
+}
+
+// NewMigrationPreserveDefault creates a migration rule that explicitly sets a
+// field to its default value when it is absent. This is useful when the default
+// value of the equivalent field of a replacement component differs.
+func NewMigrationPreserveDefault(path string) *MigrationRule {
+	return &MigrationRule{rule: docs.MigratePreserveDefault(path)}
+}
+
+// NewMigrationSet creates a migration rule that sets a field to a value when it
+// is absent.
+func NewMigrationSet(path string, value any) *MigrationRule {
+	return &MigrationRule{rule: docs.MigrateSet(path, value)}
+}
+
+// NewMigrationUnsupported creates a migration rule that prevents a component
+// from being migrated when a field is set to anything other than its default
+// value, as there is no equivalent within the replacement component.
+func NewMigrationUnsupported(path string) *MigrationRule {
+	return &MigrationRule{rule: docs.MigrateUnsupported(path)}
+}
+
+// Migration registers rules for rewriting the config of this component in
+// order to use another component, these are applied in order by the migrate
+// subcommand. If the name of the target component is empty then the component
+// remains the same, which can be used for migrating deprecated fields.
+//
+// Once all rules are applied any remaining fields that aren't recognised by the
+// target component prevent the migration.
+//
+// Experimental: This method is not intended for general use and could have its
+// signature and/or behaviour changed outside of major version bumps.
+func (c *ConfigSpec) Migration(to string, rules ...*MigrationRule) *ConfigSpec {
+	m := &docs.ComponentMigration{To: to}
+	for _, r := range rules {
+		m.Rules = append(m.Rules, r.rule)
+	}
+	c.component.Migration = m
+	return c
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/cache"
 )
 
 // CacheTestOpenClose checks that the cache can be started, an item added, and
@@ -122,3 +123,107 @@ func CacheTestGetAndSet(n int) CacheTestDefinition {
 		},
 	)
 }
+
+// CacheTestGetMulti checks that we can set n items and then get them in a
+// single call.
+func CacheTestGetMulti(n int) CacheTestDefinition {
+	return namedCacheTest(
+		"can get multiple keys",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			keys := []string{"multimissing"}
+			exp := map[string][]byte{}
+			for i := 0; i < n; i++ {
+				key := fmt.Sprintf("multikey%v", i)
+				value := fmt.Sprintf("value%v", i)
+				require.NoError(t, c.Set(env.ctx, key, []byte(value), nil))
+
+				keys = append(keys, key)
+				exp[key] = []byte(value)
+			}
+
+			res, err := cache.GetMulti(env.ctx, c, keys...)
+			require.NoError(t, err)
+			assert.Equal(t, exp, res)
+		},
+	)
+}
+
+// CacheTestIncrement checks that counters can be incremented.
+func CacheTestIncrement() CacheTestDefinition {
+	return namedCacheTest(
+		"can increment counters",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			v, err := cache.Increment(env.ctx, c, "counter", 5, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), v)
+
+			v, err = cache.Increment(env.ctx, c, "counter", -2, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), v)
+
+			res, err := c.Get(env.ctx, "counter")
+			require.NoError(t, err)
+			assert.Equal(t, "3", string(res))
+		},
+	)
+}
+
+// CacheTestCompareAndSwap checks that values are only swapped when the current
+// value matches.
+func CacheTestCompareAndSwap() CacheTestDefinition {
+	return namedCacheTest(
+		"can compare and swap",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			require.NoError(t, cache.CompareAndSwap(env.ctx, c, "caskey", nil, []byte("first"), nil))
+			require.ErrorIs(t, cache.CompareAndSwap(env.ctx, c, "caskey", nil, []byte("second"), nil), component.ErrValueMismatch)
+			require.ErrorIs(t, cache.CompareAndSwap(env.ctx, c, "caskey", []byte("nope"), []byte("second"), nil), component.ErrValueMismatch)
+			require.NoError(t, cache.CompareAndSwap(env.ctx, c, "caskey", []byte("first"), []byte("second"), nil))
+
+			res, err := c.Get(env.ctx, "caskey")
+			require.NoError(t, err)
+			assert.Equal(t, "second", string(res))
+		},
+	)
+}
+
+// CacheTestScan checks that keys can be listed by prefix.
+func CacheTestScan(n int) CacheTestDefinition {
+	return namedCacheTest(
+		"can scan keys by prefix",
+		func(t *testing.T, env *cacheTestEnvironment) {
+			c := initCache(t, env)
+			t.Cleanup(func() {
+				closeCache(t, c)
+			})
+
+			prefix := env.configVars.ID + "scan"
+			exp := map[string][]byte{}
+			for i := 0; i < n; i++ {
+				key := fmt.Sprintf("%v%v", prefix, i)
+				value := fmt.Sprintf("value%v", i)
+				require.NoError(t, c.Set(env.ctx, key, []byte(value), nil))
+				exp[key] = []byte(value)
+			}
+			require.NoError(t, c.Set(env.ctx, env.configVars.ID+"other", []byte("nope"), nil))
+
+			res, err := cache.Scan(env.ctx, c, prefix)
+			require.NoError(t, err)
+			assert.Equal(t, exp, res)
+		},
+	)
+}
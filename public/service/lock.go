@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+)
+
+// Errors returned by lock types.
+var (
+	// ErrLockHeld is returned when attempting to acquire a lock that is
+	// currently held by another owner.
+	ErrLockHeld = errors.New("lock is held by another owner")
+
+	// ErrLeaseLost is returned when attempting to renew a lease that has
+	// expired or has been taken by another owner.
+	ErrLeaseLost = errors.New("lease has been lost")
+)
+
+// Lock is an interface implemented by Bento locks, which grant time limited
+// leases on named locks to a single owner at a time. Locks are usually backed
+// by a shared service so that multiple instances of Bento can coordinate work.
+type Lock interface {
+	// Acquire attempts to obtain a lease on a named lock without blocking.
+	// Returns ErrLockHeld if the lock is currently held by another owner.
+	Acquire(ctx context.Context, name string) (LockLease, error)
+
+	Closer
+}
+
+// LockLease represents ownership of a named lock, which expires unless it is
+// renewed within its TTL.
+type LockLease interface {
+	// TTL returns the duration that the lease remains valid for after being
+	// acquired or renewed.
+	TTL() time.Duration
+
+	// Renew extends the lease by its TTL. Returns ErrLeaseLost if the lease
+	// has expired or has been taken by another owner.
+	Renew(ctx context.Context) error
+
+	// Release the lease so that the lock may be acquired by another owner
+	// immediately.
+	Release(ctx context.Context) error
+}
+
+//------------------------------------------------------------------------------
+
+func lockErr(err error) error {
+	switch {
+	case errors.Is(err, ErrLockHeld):
+		return component.ErrLockHeld
+	case errors.Is(err, ErrLeaseLost):
+		return component.ErrLeaseLost
+	}
+	return err
+}
+
+func reverseLockErr(err error) error {
+	switch {
+	case errors.Is(err, component.ErrLockHeld):
+		return ErrLockHeld
+	case errors.Is(err, component.ErrLeaseLost):
+		return ErrLeaseLost
+	}
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+// Implements lock.V1.
+type airGapLock struct {
+	l Lock
+}
+
+func newAirGapLock(l Lock, stats metrics.Type) lock.V1 {
+	return lock.MetricsForLock(&airGapLock{l: l}, stats)
+}
+
+func (a *airGapLock) Acquire(ctx context.Context, name string) (lock.Lease, error) {
+	l, err := a.l.Acquire(ctx, name)
+	if err != nil {
+		return nil, lockErr(err)
+	}
+	return &airGapLease{l: l}, nil
+}
+
+func (a *airGapLock) Close(ctx context.Context) error {
+	return a.l.Close(ctx)
+}
+
+type airGapLease struct {
+	l LockLease
+}
+
+func (a *airGapLease) TTL() time.Duration {
+	return a.l.TTL()
+}
+
+func (a *airGapLease) Renew(ctx context.Context) error {
+	return lockErr(a.l.Renew(ctx))
+}
+
+func (a *airGapLease) Release(ctx context.Context) error {
+	return lockErr(a.l.Release(ctx))
+}
+
+//------------------------------------------------------------------------------
+
+// Implements Lock around a lock.V1.
+type reverseAirGapLock struct {
+	l lock.V1
+}
+
+func newReverseAirGapLock(l lock.V1) *reverseAirGapLock {
+	return &reverseAirGapLock{l: l}
+}
+
+func (r *reverseAirGapLock) Acquire(ctx context.Context, name string) (LockLease, error) {
+	l, err := r.l.Acquire(ctx, name)
+	if err != nil {
+		return nil, reverseLockErr(err)
+	}
+	return &reverseAirGapLease{l: l}, nil
+}
+
+func (r *reverseAirGapLock) Close(ctx context.Context) error {
+	return r.l.Close(ctx)
+}
+
+type reverseAirGapLease struct {
+	l lock.Lease
+}
+
+func (r *reverseAirGapLease) TTL() time.Duration {
+	return r.l.TTL()
+}
+
+func (r *reverseAirGapLease) Renew(ctx context.Context) error {
+	return reverseLockErr(r.l.Renew(ctx))
+}
+
+func (r *reverseAirGapLease) Release(ctx context.Context) error {
+	return reverseLockErr(r.l.Release(ctx))
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/warpstreamlabs/bento/internal/component"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/manager/mock"
+)
+
+type testLock struct {
+	held   bool
+	closed bool
+}
+
+func (t *testLock) Acquire(ctx context.Context, name string) (LockLease, error) {
+	if t.held {
+		return nil, ErrLockHeld
+	}
+	t.held = true
+	return &testLease{l: t}, nil
+}
+
+func (t *testLock) Close(ctx context.Context) error {
+	t.closed = true
+	return nil
+}
+
+type testLease struct {
+	l *testLock
+}
+
+func (t *testLease) TTL() time.Duration {
+	return time.Second
+}
+
+func (t *testLease) Renew(ctx context.Context) error {
+	if !t.l.held {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (t *testLease) Release(ctx context.Context) error {
+	t.l.held = false
+	return nil
+}
+
+func TestLockAirGap(t *testing.T) {
+	ctx := context.Background()
+	l := &testLock{}
+	agl := newAirGapLock(l, metrics.Noop())
+
+	lease, err := agl.Acquire(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, lease.TTL())
+
+	_, err = agl.Acquire(ctx, "foo")
+	assert.ErrorIs(t, err, component.ErrLockHeld)
+
+	require.NoError(t, lease.Renew(ctx))
+	require.NoError(t, lease.Release(ctx))
+	assert.ErrorIs(t, lease.Renew(ctx), component.ErrLeaseLost)
+
+	require.NoError(t, agl.Close(ctx))
+	assert.True(t, l.closed)
+}
+
+func TestLockReverseAirGap(t *testing.T) {
+	ctx := context.Background()
+	ragl := newReverseAirGapLock(mock.NewLock(time.Second))
+
+	lease, err := ragl.Acquire(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, lease.TTL())
+
+	_, err = ragl.Acquire(ctx, "foo")
+	assert.ErrorIs(t, err, ErrLockHeld)
+
+	require.NoError(t, lease.Renew(ctx))
+	require.NoError(t, lease.Release(ctx))
+	assert.ErrorIs(t, lease.Renew(ctx), ErrLeaseLost)
+}
@@ -153,6 +153,19 @@ func RegisterRateLimit(name string, spec *ConfigSpec, ctor RateLimitConstructor)
 	return globalEnvironment.RegisterRateLimit(name, spec, ctor)
 }
 
+// LockConstructor is a func that's provided a configuration type and access to
+// a service manager and must return an instantiation of a lock based on the
+// config, or an error.
+type LockConstructor func(conf *ParsedConfig, mgr *Resources) (Lock, error)
+
+// RegisterLock attempts to register a new lock plugin by providing a
+// description of the configuration for the plugin as well as a constructor for
+// the lock itself. The constructor will be called for each instantiation of the
+// component within a config.
+func RegisterLock(name string, spec *ConfigSpec, ctor LockConstructor) error {
+	return globalEnvironment.RegisterLock(name, spec, ctor)
+}
+
 // MetricsExporterConstructor is a func that's provided a configuration type and
 // access to a service manager and must return an instantiation of a metrics
 // exporter based on the config, or an error.
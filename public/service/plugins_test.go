@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -9,9 +10,11 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/warpstreamlabs/bento/internal/bundle"
+	"github.com/warpstreamlabs/bento/internal/component/metrics"
 	"github.com/warpstreamlabs/bento/internal/component/testutil"
 	"github.com/warpstreamlabs/bento/internal/docs"
 	"github.com/warpstreamlabs/bento/internal/manager"
+	"github.com/warpstreamlabs/bento/internal/message"
 	"github.com/warpstreamlabs/bento/public/service"
 )
 
@@ -304,6 +307,56 @@ test_batch_output_plugin_with_config:
 	assert.Equal(t, "foo", initLabel)
 }
 
+type noopBatchOutput struct{}
+
+func (n noopBatchOutput) Connect(context.Context) error { return nil }
+
+func (n noopBatchOutput) WriteBatch(context.Context, service.MessageBatch) error { return nil }
+
+func (n noopBatchOutput) Close(context.Context) error { return nil }
+
+func TestBatchOutputPluginAdaptiveConcurrency(t *testing.T) {
+	configSpec := service.NewConfigSpec().
+		Field(service.NewOutputMaxInFlightField()).
+		Field(service.NewOutputAdaptiveConcurrencyField())
+
+	require.NoError(t, service.RegisterBatchOutput("test_batch_output_plugin_adaptive", configSpec,
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchOutput, service.BatchPolicy, int, error) {
+			maxInFlight, err := conf.FieldMaxInFlight()
+			return noopBatchOutput{}, service.BatchPolicy{Count: 10}, maxInFlight, err
+		}))
+
+	inConf, err := testutil.OutputFromYAML(`
+test_batch_output_plugin_adaptive:
+  max_in_flight: 4
+  adaptive_concurrency:
+    enabled: true
+`)
+	require.NoError(t, err)
+
+	stats := metrics.NewLocal()
+	mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetMetrics(metrics.NewNamespaced(stats)))
+	require.NoError(t, err)
+
+	out, err := mgr.NewOutput(inConf)
+	require.NoError(t, err)
+
+	tChan := make(chan message.Transaction)
+	require.NoError(t, out.Consume(tChan))
+
+	// Batched outputs share the adaptive limit of the underlying writer.
+	var limit int64
+	for k, v := range stats.GetCounters() {
+		if name, _, _ := metrics.ReverseLabelledPath(k); name == "output_concurrency_limit" {
+			limit = v
+		}
+	}
+	assert.Equal(t, int64(4), limit)
+
+	close(tChan)
+	require.NoError(t, out.WaitForClose(context.Background()))
+}
+
 func TestBatchOutputPluginWithoutConfig(t *testing.T) {
 	configSpec := service.NewConfigSpec()
 
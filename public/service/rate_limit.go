@@ -20,6 +20,31 @@ type RateLimit interface {
 	Closer
 }
 
+// KeyedRateLimit is an interface implemented by Bento rate limits that track a
+// separate budget for each key, such as a tenant or customer ID, allowing a
+// single rate limit resource to limit each of them independently.
+type KeyedRateLimit interface {
+	// AccessKey accesses the rate limited resource on behalf of a key. Returns
+	// a duration or an error if the rate limit check fails. The returned
+	// duration is either zero (meaning the resource may be accessed) or a
+	// reasonable length of time to wait before requesting again.
+	AccessKey(ctx context.Context, key string) (time.Duration, error)
+
+	RateLimit
+}
+
+// ErrRateLimitKeysNotSupported is returned by the AccessKey method of a rate
+// limit when the underlying rate limit does not support keys.
+var ErrRateLimitKeysNotSupported = ratelimit.ErrKeyedNotSupported
+
+// RateLimitSupportsKeys returns true if a rate limit provided by
+// Resources.AccessRateLimit can be accessed with a key. This is useful during
+// component initialisation in order to reject configs that target a rate limit
+// without key support.
+func RateLimitSupportsKeys(r RateLimit) bool {
+	return ratelimit.SupportsKeys(r)
+}
+
 // MessageAwareRateLimit is an interface implemented by Bento rate limits that require message awareness
 type MessageAwareRateLimit interface {
 	// Add a new *message.Part to the rate limited resource. Returns true when an
@@ -66,6 +91,14 @@ func (a *airGapMessageAwareRateLimit) Access(ctx context.Context) (time.Duration
 	return a.r.Access(ctx)
 }
 
+func (a *airGapMessageAwareRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return ratelimit.AccessKey(ctx, a.r, key)
+}
+
+func (a *airGapMessageAwareRateLimit) SupportsKeys() bool {
+	return ratelimit.SupportsKeys(a.r)
+}
+
 func (a *airGapMessageAwareRateLimit) Close(ctx context.Context) error {
 	return a.r.Close(ctx)
 }
@@ -85,6 +118,14 @@ func (a *reverseAirGapRateLimit) Access(ctx context.Context) (time.Duration, err
 	return a.r.Access(ctx)
 }
 
+func (a *reverseAirGapRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return ratelimit.AccessKey(ctx, a.r, key)
+}
+
+func (a *reverseAirGapRateLimit) SupportsKeys() bool {
+	return ratelimit.SupportsKeys(a.r)
+}
+
 func (a *reverseAirGapRateLimit) Close(ctx context.Context) error {
 	return a.r.Close(ctx)
 }
@@ -111,6 +152,14 @@ func (a *reverseAirGapMessageAwareRateLimit) Access(ctx context.Context) (time.D
 	return a.r.Access(ctx)
 }
 
+func (a *reverseAirGapMessageAwareRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	return ratelimit.AccessKey(ctx, a.r, key)
+}
+
+func (a *reverseAirGapMessageAwareRateLimit) SupportsKeys() bool {
+	return ratelimit.SupportsKeys(a.r)
+}
+
 func (a *reverseAirGapMessageAwareRateLimit) Close(ctx context.Context) error {
 	return a.r.Close(ctx)
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/warpstreamlabs/bento/internal/component/metrics"
+	"github.com/warpstreamlabs/bento/internal/component/ratelimit"
 )
 
 type closableRateLimit struct {
@@ -86,3 +87,40 @@ func TestRateLimitReverseAirGapShutdown(t *testing.T) {
 	assert.NoError(t, agrl.Close(context.Background()))
 	assert.True(t, rl.closed)
 }
+
+//------------------------------------------------------------------------------
+
+type keyedRateLimit struct {
+	closableRateLimit
+	keys []string
+}
+
+func (k *keyedRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	k.keys = append(k.keys, key)
+	return k.next, k.err
+}
+
+func TestRateLimitAirGapKeyed(t *testing.T) {
+	ctx := context.Background()
+
+	rl := &keyedRateLimit{}
+	agrl := newAirGapRateLimit(rl, metrics.Noop())
+
+	tout, err := ratelimit.AccessKey(ctx, agrl, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), tout)
+	assert.Equal(t, []string{"foo"}, rl.keys)
+
+	// Keys survive a round trip through the reverse air gap.
+	krl, ok := RateLimit(newReverseAirGapRateLimit(agrl)).(KeyedRateLimit)
+	assert.True(t, ok)
+
+	_, err = krl.AccessKey(ctx, "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, rl.keys)
+
+	// Rate limits without key support return an error.
+	krl = newReverseAirGapRateLimit(newAirGapRateLimit(&closableRateLimit{}, metrics.Noop()))
+	_, err = krl.AccessKey(ctx, "bar")
+	assert.ErrorIs(t, err, ratelimit.ErrKeyedNotSupported)
+}
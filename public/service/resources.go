@@ -11,6 +11,7 @@ import (
 	"github.com/warpstreamlabs/bento/internal/bundle"
 	"github.com/warpstreamlabs/bento/internal/component/cache"
 	"github.com/warpstreamlabs/bento/internal/component/input"
+	"github.com/warpstreamlabs/bento/internal/component/lock"
 	"github.com/warpstreamlabs/bento/internal/component/output"
 	"github.com/warpstreamlabs/bento/internal/component/ratelimit"
 	"github.com/warpstreamlabs/bento/internal/filepath/ifs"
@@ -79,6 +80,15 @@ func MockResourcesOptAddRateLimit(name string, fn func(context.Context) (time.Du
 	}
 }
 
+// MockResourcesOptAddLock instantiates the resources type with a mock lock
+// with a given name. Leases are held in memory and never expire unless they
+// are released.
+func MockResourcesOptAddLock(name string) MockResourcesOptFn {
+	return func(m *mock.Manager) {
+		m.Locks[name] = mock.NewLock(time.Second * 5)
+	}
+}
+
 // EngineVersion returns the version stamp associated with the underlying
 // bento engine. The version string is not guaranteed to match any particular
 // scheme.
@@ -249,6 +259,10 @@ func (r *Resources) HasOutput(name string) bool {
 
 // AccessRateLimit attempts to access a rate limit resource by name. This action
 // can block if CRUD operations are being actively performed on the resource.
+//
+// The rate limit provided to fn can be cast to a KeyedRateLimit, where calls
+// to AccessKey return ErrRateLimitKeysNotSupported if the underlying rate limit
+// does not support keys, which can be checked with RateLimitSupportsKeys.
 func (r *Resources) AccessRateLimit(ctx context.Context, name string, fn func(r RateLimit)) error {
 	return r.mgr.AccessRateLimit(ctx, name, func(r ratelimit.V1) {
 		// TODO: This MessageAwareRateLimit shoud eventually replace V1
@@ -268,6 +282,21 @@ func (r *Resources) HasRateLimit(name string) bool {
 	return r.mgr.ProbeRateLimit(name)
 }
 
+// AccessLock attempts to access a lock resource by name. This action can block
+// if CRUD operations are being actively performed on the resource.
+func (r *Resources) AccessLock(ctx context.Context, name string, fn func(l Lock)) error {
+	return r.mgr.AccessLock(ctx, name, func(l lock.V1) {
+		fn(newReverseAirGapLock(l))
+	})
+}
+
+// HasLock confirms whether a lock with a given name has been registered as a
+// resource. This method is useful during component initialisation as it is
+// defensive against ordering.
+func (r *Resources) HasLock(name string) bool {
+	return r.mgr.ProbeLock(name)
+}
+
 // GetGeneric queries the resources for a generic key value, potentially set by
 // another plugin or instantiation of this plugin.
 func (r *Resources) GetGeneric(key any) (any, bool) {
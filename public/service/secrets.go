@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/warpstreamlabs/bento/internal/config"
+)
+
+// SecretLookupFunc is a func that obtains the value of a secret identified by
+// a path, the format of which is specific to the provider.
+type SecretLookupFunc func(ctx context.Context, path string) (string, error)
+
+// RegisterSecretProvider attempts to register a new secret provider, which can
+// then be referenced within configs with the interpolation
+// `${secret:<name>:<path>}`. The path may optionally be suffixed with `#<key>`,
+// in which case the value returned by the provider is parsed as a JSON object
+// and the value of the key is extracted.
+//
+// Resolved values are cached for a period of time and are redacted from
+// configs that are echoed back or exposed via the HTTP API.
+func RegisterSecretProvider(name string, fn SecretLookupFunc) error {
+	return config.RegisterSecretProvider(name, config.SecretLookupFunc(fn))
+}
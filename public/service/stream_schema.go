@@ -114,6 +114,7 @@ func (s *ConfigSchema) MarshalJSONV0() ([]byte, error) {
 		Outputs:           s.env.internal.OutputDocs(),
 		Processors:        s.env.internal.ProcessorDocs(),
 		RateLimits:        s.env.internal.RateLimitDocs(),
+		Locks:             s.env.internal.LockDocs(),
 		Metrics:           s.env.internal.MetricsDocs(),
 		Tracers:           s.env.internal.TracersDocs(),
 		Scanners:          s.env.internal.ScannerDocs(),
@@ -124,51 +125,11 @@ func (s *ConfigSchema) MarshalJSONV0() ([]byte, error) {
 	return json.Marshal(iSchema)
 }
 
-func compSpecsToDefinition(specs []docs.ComponentSpec, typeFields map[string]docs.FieldSpec) map[string]any {
-	generalFields := map[string]any{}
-	for k, v := range typeFields {
-		generalFields[k] = v.JSONSchema()
-	}
-
-	var componentDefs []any
-	for _, s := range specs {
-		componentDefs = append(componentDefs, map[string]any{
-			"type": "object",
-			"properties": map[string]any{
-				s.Name: s.Config.JSONSchema(),
-			},
-		})
-	}
-
-	return map[string]any{
-		"allOf": []any{
-			map[string]any{
-				"anyOf": componentDefs, // TODO: Convert this to oneOf once issues are resolved.
-			},
-			map[string]any{
-				"type":       "object",
-				"properties": generalFields,
-			},
-		},
-	}
-}
-
 // MarshalJSONSchema attempts to marshal a JSON Schema definition containing the
 // entire config and plugin ecosystem such that other applications can
 // potentially execute their own linting and generation tools with it.
 func (s *ConfigSchema) MarshalJSONSchema() ([]byte, error) {
-	s.env.internal.BufferDocs()
-	defs := map[string]any{
-		"input":      compSpecsToDefinition(s.env.internal.InputDocs(), docs.ReservedFieldsByType(docs.TypeInput)),
-		"buffer":     compSpecsToDefinition(s.env.internal.BufferDocs(), docs.ReservedFieldsByType(docs.TypeBuffer)),
-		"cache":      compSpecsToDefinition(s.env.internal.CacheDocs(), docs.ReservedFieldsByType(docs.TypeCache)),
-		"processor":  compSpecsToDefinition(s.env.internal.ProcessorDocs(), docs.ReservedFieldsByType(docs.TypeProcessor)),
-		"rate_limit": compSpecsToDefinition(s.env.internal.RateLimitDocs(), docs.ReservedFieldsByType(docs.TypeRateLimit)),
-		"output":     compSpecsToDefinition(s.env.internal.OutputDocs(), docs.ReservedFieldsByType(docs.TypeOutput)),
-		"metrics":    compSpecsToDefinition(s.env.internal.MetricsDocs(), docs.ReservedFieldsByType(docs.TypeMetrics)),
-		"tracer":     compSpecsToDefinition(s.env.internal.TracersDocs(), docs.ReservedFieldsByType(docs.TypeTracer)),
-		"scanner":    compSpecsToDefinition(s.env.internal.ScannerDocs(), docs.ReservedFieldsByType(docs.TypeScanner)),
-	}
+	defs := s.env.internal.JSONSchemaDefinitions(docs.JSONSchemaDefinitionsRef)
 
 	schemaObj := map[string]any{
 		"properties":  s.fields.JSONSchema(),
@@ -221,6 +182,7 @@ type rawMessageSchema struct {
 	Outputs           []json.RawMessage `json:"outputs,omitempty"`
 	Processors        []json.RawMessage `json:"processors,omitempty"`
 	RateLimits        []json.RawMessage `json:"rate-limits,omitempty"`
+	Locks             []json.RawMessage `json:"locks,omitempty"`
 	Metrics           []json.RawMessage `json:"metrics,omitempty"`
 	Tracers           []json.RawMessage `json:"tracers,omitempty"`
 	Scanners          []json.RawMessage `json:"scanners,omitempty"`
@@ -380,6 +342,21 @@ func expandEnvWithSchema(schema *rawMessageSchema, env *Environment) error {
 			})
 	}
 
+	for _, spec := range schema.Locks {
+		pluginSpec := NewConfigSpec()
+		if err := pluginSpec.EncodeJSON(spec); err != nil {
+			return err
+		}
+		if _, exists := env.internal.GetDocs(pluginSpec.component.Name, docs.TypeLock); exists {
+			continue
+		}
+		_ = env.RegisterLock(
+			pluginSpec.component.Name, pluginSpec,
+			func(conf *ParsedConfig, mgr *Resources) (Lock, error) {
+				return nil, errComponentDisabled
+			})
+	}
+
 	for _, spec := range schema.Metrics {
 		pluginSpec := NewConfigSpec()
 		if err := pluginSpec.EncodeJSON(spec); err != nil {
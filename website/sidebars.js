@@ -5,6 +5,7 @@ let processors_docs = listPaths("processors");
 let outputs_docs = listPaths("outputs");
 let caches_docs = listPaths("caches");
 let rate_limits_docs = listPaths("rate_limits");
+let locks_docs = listPaths("locks");
 let buffers_docs = listPaths("buffers");
 let metrics_docs = listPaths("metrics");
 let tracers_docs = listPaths("tracers");
@@ -72,6 +73,11 @@ module.exports = {
           label: 'Rate Limits',
           items: rate_limits_docs,
         },
+        {
+          type: 'category',
+          label: 'Locks',
+          items: locks_docs,
+        },
         {
           type: 'category',
           label: 'Buffers',